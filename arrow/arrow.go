@@ -0,0 +1,669 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package arrow implements converting ion
+// rows to the Apache Arrow IPC streaming format.
+//
+// Each row must be an ion structure; the fields of
+// the rows become the columns of the record batches.
+// Ion types are mapped as follows:
+//
+//   - integers are written as Int64
+//   - floats are written as Float64
+//   - strings and symbols are written as Utf8
+//   - booleans are written as Bool
+//   - timestamps are written as Timestamp
+//     with microsecond precision in UTC
+//   - structures are written as Struct and
+//     lists are written as List
+//
+// Nulls and missing fields are written as nulls.
+//
+// Unless Writer.Schema is set, the schema of the
+// stream is inferred from the rows of the first
+// record batch only (see Writer.BatchRows), and
+// it is not widened afterwards: a field that first
+// appears in a later batch, or a value that cannot
+// be converted to the type inferred for its field,
+// causes the Writer to fail. Set Writer.Schema when
+// the rows are not known to have a uniform shape.
+package arrow
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// Type is an Arrow data type.
+type Type int
+
+const (
+	// Null is the type of a column
+	// in which every value is null.
+	Null Type = iota
+	Bool
+	Int64
+	Float64
+	Utf8
+	// Timestamp is a timestamp with
+	// microsecond precision in UTC.
+	Timestamp
+	// List is a list of values of
+	// the type of its single child.
+	List
+	// Struct is a structure with
+	// one field per child.
+	Struct
+)
+
+func (t Type) String() string {
+	switch t {
+	case Null:
+		return "null"
+	case Bool:
+		return "bool"
+	case Int64:
+		return "int64"
+	case Float64:
+		return "float64"
+	case Utf8:
+		return "utf8"
+	case Timestamp:
+		return "timestamp"
+	case List:
+		return "list"
+	case Struct:
+		return "struct"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// Field is a column of a schema
+// or a child of another Field.
+// Every field is nullable.
+type Field struct {
+	Name string
+	Type Type
+	// Children are the fields of a Struct,
+	// or the single element field of a List.
+	Children []Field
+}
+
+// DefaultBatchRows is the default
+// number of rows in each record batch.
+const DefaultBatchRows = 64 * 1024
+
+var errNotStruct = errors.New("arrow: row is not a structure")
+
+// Writer is an io.Writer that converts chunks of
+// ion data (such as the output of a query) into an
+// Arrow IPC stream. Each call to Write must begin
+// at a chunk boundary, i.e. with an ion BVM and
+// a symbol table.
+//
+// A Writer can be used as a vm.QuerySink
+// by wrapping it with vm.LockedSink.
+type Writer struct {
+	// Schema, if non-nil, is the schema of the
+	// output. Fields of the rows that are not
+	// part of Schema are ignored. If Schema is nil,
+	// it is inferred from the rows of the first
+	// record batch, and it is an error for rows in
+	// subsequent batches to contain fields that are
+	// not part of the inferred schema (including the
+	// fields of nested structures).
+	//
+	// In either case, it is an error for a value to
+	// have a type that does not match its field;
+	// in particular, a field of type Null (such as
+	// a field that is always null in the first
+	// record batch) may not have any other values.
+	Schema []Field
+	// BatchRows is the maximum number of rows
+	// in each record batch. If BatchRows is zero,
+	// DefaultBatchRows is used instead.
+	BatchRows int
+
+	dst     io.Writer
+	st      ion.Symtab
+	pending []ion.Datum // rows before the schema is known
+	cols    []column
+	rows    int
+	started bool
+	strict  bool // the schema was inferred
+	err     error
+}
+
+// NewWriter constructs a Writer
+// that writes an IPC stream to dst.
+func NewWriter(dst io.Writer) *Writer {
+	return &Writer{dst: dst}
+}
+
+func (w *Writer) batchRows() int {
+	if w.BatchRows > 0 {
+		return w.BatchRows
+	}
+	return DefaultBatchRows
+}
+
+// Write implements io.Writer.Write
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	body := p
+	for len(body) > 0 {
+		if ion.TypeOf(body) == ion.NullType && ion.SizeOf(body) > 1 {
+			// nop pad
+			body = body[ion.SizeOf(body):]
+			continue
+		}
+		var d ion.Datum
+		var err error
+		d, body, err = ion.ReadDatum(&w.st, body)
+		if err != nil {
+			w.err = err
+			return len(p) - len(body), err
+		}
+		if d.IsEmpty() || d.IsNull() {
+			continue // symbol table or nop pad
+		}
+		if !d.IsStruct() {
+			w.err = errNotStruct
+			return len(p) - len(body), w.err
+		}
+		if err := w.add(d); err != nil {
+			w.err = err
+			return len(p) - len(body), err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *Writer) add(d ion.Datum) error {
+	if !w.started {
+		w.pending = append(w.pending, d.Clone())
+		if len(w.pending) < w.batchRows() {
+			return nil
+		}
+		return w.flush()
+	}
+	if err := w.append(d); err != nil {
+		return err
+	}
+	if w.rows >= w.batchRows() {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *Writer) append(d ion.Datum) error {
+	s, _ := d.Struct()
+	if w.strict {
+		if err := checkFields(s, w.Schema, ""); err != nil {
+			return err
+		}
+	}
+	for i := range w.cols {
+		f, _ := s.FieldByName(w.cols[i].field.Name)
+		if err := w.cols[i].append(f.Datum); err != nil {
+			return err
+		}
+	}
+	w.rows++
+	return nil
+}
+
+// start writes the schema message
+// and appends the pending rows
+func (w *Writer) start() error {
+	if w.Schema == nil {
+		var err error
+		w.Schema, err = infer(w.pending)
+		if err != nil {
+			return err
+		}
+		w.strict = true
+	}
+	if err := w.message(headerSchema, schemaTable(w.Schema), nil); err != nil {
+		return err
+	}
+	w.cols = make([]column, len(w.Schema))
+	for i := range w.Schema {
+		w.cols[i] = newColumn(&w.Schema[i], w.strict)
+	}
+	w.started = true
+	for i := range w.pending {
+		if err := w.append(w.pending[i]); err != nil {
+			return err
+		}
+	}
+	w.pending = nil
+	return nil
+}
+
+// flush writes the buffered rows as a record batch
+func (w *Writer) flush() error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	if w.rows == 0 {
+		return nil
+	}
+	var nodes, buffers fbPairs
+	var body []byte
+	for i := range w.cols {
+		w.cols[i].flush(&nodes, &buffers, &body)
+	}
+	batch := fbTable{
+		scalar(8, uint64(w.rows)), // length
+		ref(nodes),
+		ref(buffers),
+	}
+	err := w.message(headerRecordBatch, batch, body)
+	for i := range w.cols {
+		w.cols[i].reset()
+	}
+	w.rows = 0
+	return err
+}
+
+// Close writes any buffered rows
+// and the end of the stream. It does
+// not close the underlying io.Writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.flush(); err != nil {
+		w.err = err
+		return err
+	}
+	// end-of-stream marker
+	_, err := w.dst.Write([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0})
+	w.err = errors.New("arrow: Writer closed")
+	return err
+}
+
+// message header types
+const (
+	headerSchema      = 1
+	headerRecordBatch = 3
+)
+
+// metadataV5 is MetadataVersion.V5
+const metadataV5 = 4
+
+// message writes an encapsulated message
+// with the given header and body
+func (w *Writer) message(kind byte, header fbTable, body []byte) error {
+	meta := finish(fbTable{
+		scalar(2, metadataV5),   // version
+		scalar(1, uint64(kind)), // header_type
+		ref(header),
+		scalar(8, uint64(len(body))), // bodyLength
+	})
+	var prefix [8]byte
+	binary.LittleEndian.PutUint32(prefix[:], 0xffffffff)
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(len(meta)))
+	for _, buf := range [][]byte{prefix[:], meta, body} {
+		if len(buf) == 0 {
+			continue
+		}
+		if _, err := w.dst.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Type union members
+const (
+	typeNull          = 1
+	typeInt           = 2
+	typeFloatingPoint = 3
+	typeUtf8          = 5
+	typeBool          = 6
+	typeTimestamp     = 10
+	typeList          = 12
+	typeStruct        = 13
+)
+
+func schemaTable(fields []Field) fbTable {
+	return fbTable{
+		scalar(2, 0), // endianness: little
+		ref(fieldVector(fields)),
+	}
+}
+
+func fieldVector(fields []Field) fbVector {
+	v := make(fbVector, len(fields))
+	for i := range fields {
+		v[i] = fieldTable(&fields[i])
+	}
+	return v
+}
+
+func fieldTable(f *Field) fbTable {
+	var kind byte
+	var typ fbTable
+	switch f.Type {
+	case Null:
+		kind = typeNull
+	case Bool:
+		kind = typeBool
+	case Int64:
+		kind = typeInt
+		typ = fbTable{scalar(4, 64), scalar(1, 1)} // bitWidth, is_signed
+	case Float64:
+		kind = typeFloatingPoint
+		typ = fbTable{scalar(2, 2)} // precision: double
+	case Utf8:
+		kind = typeUtf8
+	case Timestamp:
+		kind = typeTimestamp
+		typ = fbTable{scalar(2, 2), ref(fbString("UTC"))} // unit: microsecond, timezone
+	case List:
+		kind = typeList
+	case Struct:
+		kind = typeStruct
+	}
+	return fbTable{
+		ref(fbString(f.Name)),
+		scalar(1, 1), // nullable
+		scalar(1, uint64(kind)),
+		ref(typ),
+		{}, // dictionary
+		ref(fieldVector(f.Children)),
+	}
+}
+
+// infer returns the schema of rows
+func infer(rows []ion.Datum) ([]Field, error) {
+	root := Field{Type: Struct}
+	for i := range rows {
+		if err := root.merge(rows[i]); err != nil {
+			return nil, err
+		}
+	}
+	return root.Children, nil
+}
+
+// merge merges the type of d into f
+func (f *Field) merge(d ion.Datum) error {
+	var t Type
+	switch d.Type() {
+	case ion.NullType:
+		return nil
+	case ion.BoolType:
+		t = Bool
+	case ion.IntType, ion.UintType:
+		t = Int64
+	case ion.FloatType:
+		t = Float64
+	case ion.StringType, ion.SymbolType:
+		t = Utf8
+	case ion.TimestampType:
+		t = Timestamp
+	case ion.ListType:
+		t = List
+	case ion.StructType:
+		t = Struct
+	default:
+		return fmt.Errorf("arrow: field %q: unsupported ion type %s", f.Name, d.Type())
+	}
+	switch {
+	case f.Type == Null:
+		f.Type = t
+		if t == List {
+			f.Children = []Field{{Name: "item"}}
+		}
+	case f.Type == t:
+	case f.Type == Int64 && t == Float64:
+		f.Type = Float64
+	case f.Type == Float64 && t == Int64:
+	default:
+		return fmt.Errorf("arrow: field %q has both %s and %s values", f.Name, f.Type, t)
+	}
+	switch t {
+	case List:
+		l, _ := d.List()
+		return l.Each(func(d ion.Datum) error {
+			return f.Children[0].merge(d)
+		})
+	case Struct:
+		s, _ := d.Struct()
+		return s.Each(func(sf ion.Field) error {
+			for i := range f.Children {
+				if f.Children[i].Name == sf.Label {
+					return f.Children[i].merge(sf.Datum)
+				}
+			}
+			f.Children = append(f.Children, Field{Name: sf.Label})
+			return f.Children[len(f.Children)-1].merge(sf.Datum)
+		})
+	}
+	return nil
+}
+
+// checkFields returns an error if s has a field
+// that is not in fields; parent is the name of the
+// field that s belongs to, or empty for a row
+func checkFields(s ion.Struct, fields []Field, parent string) error {
+	return s.Each(func(sf ion.Field) error {
+		for i := range fields {
+			if fields[i].Name == sf.Label {
+				return nil
+			}
+		}
+		if parent != "" {
+			return fmt.Errorf("arrow: field %q of %q is not part of the inferred schema", sf.Label, parent)
+		}
+		return fmt.Errorf("arrow: field %q is not part of the inferred schema", sf.Label)
+	})
+}
+
+// column accumulates the values
+// of a field for a record batch
+type column struct {
+	field    *Field
+	strict   bool // see Writer.strict
+	n, nulls int
+	valid    []byte  // validity bitmap
+	data     []byte  // values, or the bitmap of a Bool
+	offsets  []int32 // offsets of a Utf8 or List
+	children []column
+}
+
+func newColumn(f *Field, strict bool) column {
+	c := column{field: f, strict: strict}
+	if f.Type == Utf8 || f.Type == List {
+		c.offsets = []int32{0}
+	}
+	c.children = make([]column, len(f.Children))
+	for i := range f.Children {
+		c.children[i] = newColumn(&f.Children[i], strict)
+	}
+	return c
+}
+
+func (c *column) reset() {
+	c.n, c.nulls = 0, 0
+	c.valid = c.valid[:0]
+	c.data = c.data[:0]
+	if c.offsets != nil {
+		c.offsets = c.offsets[:1]
+	}
+	for i := range c.children {
+		c.children[i].reset()
+	}
+}
+
+// setBit sets bit i of bm to v;
+// bits must be set in order
+func setBit(bm []byte, i int, v bool) []byte {
+	if i%8 == 0 {
+		bm = append(bm, 0)
+	}
+	if v {
+		bm[i/8] |= 1 << (i % 8)
+	}
+	return bm
+}
+
+func (c *column) appendNull() {
+	switch c.field.Type {
+	case Bool:
+		c.data = setBit(c.data, c.n, false)
+	case Int64, Float64, Timestamp:
+		c.data = append(c.data, make([]byte, 8)...)
+	case Utf8, List:
+		c.offsets = append(c.offsets, c.offsets[len(c.offsets)-1])
+	case Struct:
+		for i := range c.children {
+			c.children[i].appendNull()
+		}
+	}
+	c.valid = setBit(c.valid, c.n, false)
+	c.nulls++
+	c.n++
+}
+
+func (c *column) bad(d ion.Datum) error {
+	return fmt.Errorf("arrow: field %q: cannot convert ion %s to %s", c.field.Name, d.Type(), c.field.Type)
+}
+
+// append appends d, which may be
+// empty if the field is missing
+func (c *column) append(d ion.Datum) error {
+	if d.IsEmpty() || d.IsNull() {
+		c.appendNull()
+		return nil
+	}
+	switch c.field.Type {
+	case Null:
+		return c.bad(d)
+	case Bool:
+		b, err := d.Bool()
+		if err != nil {
+			return c.bad(d)
+		}
+		c.data = setBit(c.data, c.n, b)
+	case Int64:
+		i, err := d.Int()
+		if err != nil {
+			return c.bad(d)
+		}
+		c.data = binary.LittleEndian.AppendUint64(c.data, uint64(i))
+	case Float64:
+		f, err := d.CoerceFloat()
+		if err != nil {
+			return c.bad(d)
+		}
+		c.data = binary.LittleEndian.AppendUint64(c.data, math.Float64bits(f))
+	case Timestamp:
+		t, err := d.Timestamp()
+		if err != nil {
+			return c.bad(d)
+		}
+		c.data = binary.LittleEndian.AppendUint64(c.data, uint64(t.UnixMicro()))
+	case Utf8:
+		if !d.IsString() && !d.IsSymbol() {
+			return c.bad(d)
+		}
+		s, err := d.String()
+		if err != nil {
+			return err
+		}
+		c.data = append(c.data, s...)
+		c.offsets = append(c.offsets, int32(len(c.data)))
+	case List:
+		l, err := d.List()
+		if err != nil {
+			return c.bad(d)
+		}
+		items := &c.children[0]
+		err = l.Each(func(d ion.Datum) error { return items.append(d) })
+		if err != nil {
+			return err
+		}
+		c.offsets = append(c.offsets, int32(items.n))
+	case Struct:
+		s, err := d.Struct()
+		if err != nil {
+			return c.bad(d)
+		}
+		if c.strict {
+			if err := checkFields(s, c.field.Children, c.field.Name); err != nil {
+				return err
+			}
+		}
+		for i := range c.children {
+			f, _ := s.FieldByName(c.children[i].field.Name)
+			if err := c.children[i].append(f.Datum); err != nil {
+				return err
+			}
+		}
+	}
+	c.valid = setBit(c.valid, c.n, true)
+	c.n++
+	return nil
+}
+
+// flush appends the field nodes and buffers
+// of c and its children (in pre-order) and
+// appends the contents of the buffers to body
+func (c *column) flush(nodes, buffers *fbPairs, body *[]byte) {
+	*nodes = append(*nodes, uint64(c.n), uint64(c.nulls))
+	add := func(buf []byte) {
+		*buffers = append(*buffers, uint64(len(*body)), uint64(len(buf)))
+		*body = append(*body, buf...)
+		for len(*body)%8 != 0 {
+			*body = append(*body, 0)
+		}
+	}
+	if c.field.Type == Null {
+		return // no buffers
+	}
+	add(c.valid)
+	switch c.field.Type {
+	case Bool, Int64, Float64, Timestamp:
+		add(c.data)
+	case Utf8:
+		add(int32s(c.offsets))
+		add(c.data)
+	case List:
+		add(int32s(c.offsets))
+	}
+	for i := range c.children {
+		c.children[i].flush(nodes, buffers, body)
+	}
+}
+
+func int32s(lst []int32) []byte {
+	buf := make([]byte, 0, 4*len(lst))
+	for _, v := range lst {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(v))
+	}
+	return buf
+}
@@ -0,0 +1,457 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package arrow
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SnellerInc/sneller/date"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// fbTab is a flatbuffer table being decoded
+type fbTab struct {
+	buf []byte
+	pos int
+}
+
+func u16(buf []byte, pos int) int { return int(binary.LittleEndian.Uint16(buf[pos:])) }
+func u32(buf []byte, pos int) int { return int(binary.LittleEndian.Uint32(buf[pos:])) }
+
+func fbRoot(buf []byte) fbTab { return fbTab{buf, u32(buf, 0)} }
+
+// field returns the position of field id, or 0
+func (t fbTab) field(id int) int {
+	vt := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	if 4+2*id >= u16(t.buf, vt) {
+		return 0
+	}
+	if off := u16(t.buf, vt+4+2*id); off != 0 {
+		return t.pos + off
+	}
+	return 0
+}
+
+func (t fbTab) uint(id, size int) uint64 {
+	p := t.field(id)
+	if p == 0 {
+		return 0
+	}
+	switch size {
+	case 1:
+		return uint64(t.buf[p])
+	case 2:
+		return uint64(u16(t.buf, p))
+	case 4:
+		return uint64(u32(t.buf, p))
+	}
+	return binary.LittleEndian.Uint64(t.buf[p:])
+}
+
+func (t fbTab) ref(id int) int {
+	p := t.field(id)
+	return p + u32(t.buf, p)
+}
+
+func (t fbTab) table(id int) fbTab { return fbTab{t.buf, t.ref(id)} }
+
+func (t fbTab) str(id int) string {
+	p := t.ref(id)
+	return string(t.buf[p+4 : p+4+u32(t.buf, p)])
+}
+
+// tables returns the elements of a vector of tables
+func (t fbTab) tables(id int) []fbTab {
+	if t.field(id) == 0 {
+		return nil
+	}
+	p := t.ref(id)
+	out := make([]fbTab, u32(t.buf, p))
+	for i := range out {
+		e := p + 4 + 4*i
+		out[i] = fbTab{t.buf, e + u32(t.buf, e)}
+	}
+	return out
+}
+
+// words returns the fields of a vector
+// of structs with 8-byte fields
+func (t fbTab) words(id, fields int) []uint64 {
+	p := t.ref(id)
+	out := make([]uint64, u32(t.buf, p)*fields)
+	for i := range out {
+		out[i] = binary.LittleEndian.Uint64(t.buf[p+4+8*i:])
+	}
+	return out
+}
+
+var fbTypes = map[uint64]Type{
+	typeNull:          Null,
+	typeBool:          Bool,
+	typeInt:           Int64,
+	typeFloatingPoint: Float64,
+	typeUtf8:          Utf8,
+	typeTimestamp:     Timestamp,
+	typeList:          List,
+	typeStruct:        Struct,
+}
+
+func decodeFields(t *testing.T, lst []fbTab) []Field {
+	var out []Field
+	for _, f := range lst {
+		typ, ok := fbTypes[f.uint(2, 1)]
+		if !ok {
+			t.Fatalf("unexpected type %d", f.uint(2, 1))
+		}
+		out = append(out, Field{Name: f.str(0), Type: typ, Children: decodeFields(t, f.tables(5))})
+	}
+	return out
+}
+
+// batchReader reconstructs values from
+// the field nodes and buffers of a batch
+type batchReader struct {
+	t              *testing.T
+	nodes, buffers []uint64
+	body           []byte
+}
+
+func (r *batchReader) buffer() []byte {
+	off, size := r.buffers[0], r.buffers[1]
+	r.buffers = r.buffers[2:]
+	return r.body[off : off+size]
+}
+
+func (r *batchReader) column(f *Field) []any {
+	n, nulls := int(r.nodes[0]), int(r.nodes[1])
+	r.nodes = r.nodes[2:]
+	out := make([]any, n)
+	if f.Type == Null {
+		return out
+	}
+	bit := func(bm []byte, i int) bool { return bm[i/8]&(1<<(i%8)) != 0 }
+	valid := r.buffer()
+	if len(valid) == 0 && nulls == 0 {
+		// the validity bitmap may be
+		// omitted if there are no nulls
+		valid = make([]byte, (n+7)/8)
+		for i := range valid {
+			valid[i] = 0xff
+		}
+	}
+	count := 0
+	for i := 0; i < n; i++ {
+		if !bit(valid, i) {
+			count++
+		}
+	}
+	if count != nulls {
+		r.t.Errorf("field %q: null count is %d, but %d values are null", f.Name, nulls, count)
+	}
+	var data, offsets []byte
+	switch f.Type {
+	case Bool, Int64, Float64, Timestamp:
+		data = r.buffer()
+	case Utf8:
+		offsets, data = r.buffer(), r.buffer()
+	case List:
+		offsets = r.buffer()
+	}
+	var children [][]any
+	for i := range f.Children {
+		children = append(children, r.column(&f.Children[i]))
+	}
+	for i := range out {
+		if !bit(valid, i) {
+			continue
+		}
+		switch f.Type {
+		case Bool:
+			out[i] = bit(data, i)
+		case Int64:
+			out[i] = int64(binary.LittleEndian.Uint64(data[8*i:]))
+		case Float64:
+			out[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+		case Timestamp:
+			out[i] = date.UnixMicro(int64(binary.LittleEndian.Uint64(data[8*i:])))
+		case Utf8:
+			out[i] = string(data[u32(offsets, 4*i):u32(offsets, 4*i+4)])
+		case List:
+			out[i] = children[0][u32(offsets, 4*i):u32(offsets, 4*i+4)]
+		case Struct:
+			m := make(map[string]any)
+			for j := range f.Children {
+				m[f.Children[j].Name] = children[j][i]
+			}
+			out[i] = m
+		}
+	}
+	return out
+}
+
+// readStream decodes an IPC stream into
+// its schema and the rows of each batch
+func readStream(t *testing.T, buf []byte) ([]Field, [][]map[string]any) {
+	var schema []Field
+	var batches [][]map[string]any
+	for {
+		if u32(buf, 0) != 0xffffffff {
+			t.Fatal("missing continuation marker")
+		}
+		size := u32(buf, 4)
+		if size == 0 {
+			if len(buf) != 8 {
+				t.Fatalf("%d bytes after end of stream", len(buf)-8)
+			}
+			return schema, batches
+		}
+		if size%8 != 0 {
+			t.Fatalf("metadata size %d not padded", size)
+		}
+		msg := fbRoot(buf[8 : 8+size])
+		if v := msg.uint(0, 2); v != metadataV5 {
+			t.Fatalf("metadata version %d", v)
+		}
+		header := msg.table(2)
+		body := buf[8+size : 8+size+int(msg.uint(3, 8))]
+		buf = buf[8+size+len(body):]
+		switch msg.uint(1, 1) {
+		case headerSchema:
+			schema = decodeFields(t, header.tables(1))
+		case headerRecordBatch:
+			r := batchReader{t: t, nodes: header.words(1, 2), buffers: header.words(2, 2), body: body}
+			rows := make([]map[string]any, header.uint(0, 8))
+			for i := range rows {
+				rows[i] = make(map[string]any)
+			}
+			for i := range schema {
+				for j, v := range r.column(&schema[i]) {
+					rows[j][schema[i].Name] = v
+				}
+			}
+			batches = append(batches, rows)
+		default:
+			t.Fatalf("unexpected message type %d", msg.uint(1, 1))
+		}
+	}
+}
+
+// chunk encodes rows as an ion chunk
+// using the symbol table st
+func chunk(st *ion.Symtab, rows ...[]ion.Field) []byte {
+	var body, buf ion.Buffer
+	for i := range rows {
+		ion.NewStruct(st, rows[i]).Encode(&body, st)
+	}
+	st.Marshal(&buf, true)
+	buf.UnsafeAppend(body.Bytes())
+	return buf.Bytes()
+}
+
+// t0 is the timestamp in testRows
+var t0 = date.Date(2023, 1, 2, 3, 4, 5, 6000)
+
+// testRows returns the rows written by TestWriter
+// (and by testdata/generate to testdata/golden.arrows)
+func testRows(st *ion.Symtab) []byte {
+	return chunk(st,
+		[]ion.Field{
+			{Label: "i", Datum: ion.Int(1)},
+			{Label: "f", Datum: ion.Float(1.5)},
+			{Label: "s", Datum: ion.String("hello")},
+			{Label: "b", Datum: ion.Bool(true)},
+			{Label: "t", Datum: ion.Timestamp(t0)},
+			{Label: "l", Datum: ion.NewList(nil, []ion.Datum{ion.Int(1), ion.Int(2)}).Datum()},
+			{Label: "st", Datum: ion.NewStruct(nil, []ion.Field{{Label: "x", Datum: ion.String("y")}}).Datum()},
+			{Label: "n", Datum: ion.Null},
+		},
+		[]ion.Field{
+			{Label: "i", Datum: ion.Int(-7)},
+			{Label: "f", Datum: ion.Int(3)},
+			{Label: "l", Datum: ion.NewList(nil, nil).Datum()},
+		},
+		// the schema is inferred from the first batch,
+		// so later rows may omit fields
+		[]ion.Field{
+			{Label: "s", Datum: ion.String("world")},
+			{Label: "st", Datum: ion.NewStruct(nil, nil).Datum()},
+		},
+	)
+}
+
+func TestWriter(t *testing.T) {
+	var st ion.Symtab
+	src := testRows(&st)
+	var out strings.Builder
+	w := NewWriter(&out)
+	w.BatchRows = 2
+	if _, err := w.Write(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	schema, batches := readStream(t, []byte(out.String()))
+	wantSchema := []Field{
+		{Name: "i", Type: Int64},
+		{Name: "f", Type: Float64},
+		{Name: "s", Type: Utf8},
+		{Name: "b", Type: Bool},
+		{Name: "t", Type: Timestamp},
+		{Name: "l", Type: List, Children: []Field{{Name: "item", Type: Int64}}},
+		{Name: "st", Type: Struct, Children: []Field{{Name: "x", Type: Utf8}}},
+		{Name: "n", Type: Null},
+	}
+	if !reflect.DeepEqual(schema, wantSchema) {
+		t.Fatalf("got schema %v, want %v", schema, wantSchema)
+	}
+	want := [][]map[string]any{
+		{
+			{"i": int64(1), "f": 1.5, "s": "hello", "b": true, "t": t0,
+				"l": []any{int64(1), int64(2)}, "st": map[string]any{"x": "y"}, "n": nil},
+			{"i": int64(-7), "f": 3.0, "s": nil, "b": nil, "t": nil,
+				"l": []any{}, "st": nil, "n": nil},
+		},
+		{
+			{"i": nil, "f": nil, "s": "world", "b": nil, "t": nil,
+				"l": nil, "st": map[string]any{"x": nil}, "n": nil},
+		},
+	}
+	if !reflect.DeepEqual(batches, want) {
+		t.Errorf("got %v\nwant %v", batches, want)
+	}
+}
+
+// TestGolden checks that a stream written by
+// the Apache Arrow Go IPC writer decodes to the
+// same schema and record batches as the stream
+// we write for the same rows
+func TestGolden(t *testing.T) {
+	golden, err := os.ReadFile("testdata/golden.arrows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSchema, want := readStream(t, golden)
+
+	var st ion.Symtab
+	var out strings.Builder
+	w := NewWriter(&out)
+	w.BatchRows = 2
+	if _, err := w.Write(testRows(&st)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	schema, batches := readStream(t, []byte(out.String()))
+	if !reflect.DeepEqual(schema, wantSchema) {
+		t.Fatalf("got schema %v, want %v", schema, wantSchema)
+	}
+	if !reflect.DeepEqual(batches, want) {
+		t.Errorf("got %v\nwant %v", batches, want)
+	}
+}
+
+func TestWriterSchema(t *testing.T) {
+	var out strings.Builder
+	w := NewWriter(&out)
+	w.Schema = []Field{{Name: "s", Type: Utf8}, {Name: "x", Type: Float64}}
+	var st ion.Symtab
+	src := chunk(&st,
+		[]ion.Field{{Label: "x", Datum: ion.Int(2)}, {Label: "other", Datum: ion.Int(1)}},
+		[]ion.Field{{Label: "s", Datum: ion.Interned(&st, "sym")}},
+	)
+	if _, err := w.Write(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	schema, batches := readStream(t, []byte(out.String()))
+	if !reflect.DeepEqual(schema, w.Schema) {
+		t.Errorf("got schema %v", schema)
+	}
+	want := [][]map[string]any{{
+		{"s": nil, "x": 2.0},
+		{"s": "sym", "x": nil},
+	}}
+	if !reflect.DeepEqual(batches, want) {
+		t.Errorf("got %v, want %v", batches, want)
+	}
+
+	// values that don't match the schema are rejected
+	w = NewWriter(&out)
+	w.Schema = []Field{{Name: "x", Type: Int64}}
+	_, err := w.Write(chunk(&st, []ion.Field{{Label: "x", Datum: ion.String("no")}}))
+	if err == nil {
+		err = w.Close()
+	}
+	if err == nil || !strings.Contains(err.Error(), `field "x"`) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestWriterInferredSchema(t *testing.T) {
+	st := func(fields ...ion.Field) ion.Datum {
+		return ion.NewStruct(nil, fields).Datum()
+	}
+	tcs := []struct {
+		name string
+		rows [][]ion.Field
+		err  string
+	}{{
+		name: "new field",
+		rows: [][]ion.Field{
+			{{Label: "a", Datum: ion.Int(1)}},
+			{{Label: "a", Datum: ion.Int(2)}, {Label: "b", Datum: ion.Int(3)}},
+		},
+		err: `field "b" is not part of the inferred schema`,
+	}, {
+		name: "new nested field",
+		rows: [][]ion.Field{
+			{{Label: "st", Datum: st(ion.Field{Label: "x", Datum: ion.Int(1)})}},
+			{{Label: "st", Datum: st(ion.Field{Label: "x", Datum: ion.Int(2)}, ion.Field{Label: "z", Datum: ion.Int(3)})}},
+		},
+		err: `field "z" of "st" is not part of the inferred schema`,
+	}, {
+		name: "null field",
+		rows: [][]ion.Field{
+			{{Label: "a", Datum: ion.Int(1)}, {Label: "n", Datum: ion.Null}},
+			{{Label: "a", Datum: ion.Int(2)}, {Label: "n", Datum: ion.Int(5)}},
+		},
+		err: `field "n": cannot convert ion uint to null`,
+	}}
+	for i := range tcs {
+		tc := &tcs[i]
+		t.Run(tc.name, func(t *testing.T) {
+			var out strings.Builder
+			w := NewWriter(&out)
+			w.BatchRows = 1
+			var st ion.Symtab
+			_, err := w.Write(chunk(&st, tc.rows...))
+			if err == nil {
+				err = w.Close()
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("got error %v, want %q", err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package arrow
+
+import (
+	"encoding/binary"
+)
+
+// This file implements just enough of the
+// flatbuffer encoding to produce the metadata
+// of an Arrow IPC stream.
+//
+// Unlike the reference implementation, which builds
+// buffers back-to-front, fbuf writes each object before
+// the objects that it refers to and then patches the
+// (always forward) offsets once those have been written.
+
+// fbuf is a flatbuffer being encoded
+type fbuf struct {
+	buf []byte
+}
+
+// fbValue is an object that can be referred to
+// by an offset; encode returns the position of
+// the object within the buffer
+type fbValue interface {
+	encode(b *fbuf) int
+}
+
+// fbField is a field of a table; a field
+// with a zero size is not present
+type fbField struct {
+	size int    // inline size: 1, 2, 4 or 8
+	bits uint64 // scalar value
+	ref  fbValue
+}
+
+func scalar(size int, v uint64) fbField { return fbField{size: size, bits: v} }
+func ref(v fbValue) fbField             { return fbField{size: 4, ref: v} }
+
+// fbTable is a table; the position
+// of each field is its field ID
+type fbTable []fbField
+
+// fbString is a string
+type fbString string
+
+// fbVector is a vector of tables
+type fbVector []fbValue
+
+// fbPairs is a vector of structs that each
+// have two 8-byte fields (FieldNode and Buffer
+// are both of this form), stored in order
+type fbPairs []uint64
+
+func (b *fbuf) align(n int) {
+	for len(b.buf)%n != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func (b *fbuf) put(pos, size int, v uint64) {
+	switch size {
+	case 1:
+		b.buf[pos] = byte(v)
+	case 2:
+		binary.LittleEndian.PutUint16(b.buf[pos:], uint16(v))
+	case 4:
+		binary.LittleEndian.PutUint32(b.buf[pos:], uint32(v))
+	case 8:
+		binary.LittleEndian.PutUint64(b.buf[pos:], v)
+	}
+}
+
+func (b *fbuf) u32(v uint32) {
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, v)
+}
+
+// patch sets the offset at pos to refer to target
+func (b *fbuf) patch(pos, target int) {
+	b.put(pos, 4, uint64(target-pos))
+}
+
+func (t fbTable) encode(b *fbuf) int {
+	// fields are laid out in order after the
+	// offset of the vtable, each aligned to its size
+	offs := make([]int, len(t))
+	size := 4
+	for i := range t {
+		if t[i].size == 0 {
+			continue
+		}
+		size = (size + t[i].size - 1) &^ (t[i].size - 1)
+		offs[i] = size
+		size += t[i].size
+	}
+	b.align(2)
+	vt := len(b.buf)
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(4+2*len(t)))
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(size))
+	for i := range offs {
+		b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(offs[i]))
+	}
+	// the table itself is 8-byte aligned so that
+	// the alignment of each field relative to the
+	// table is also its absolute alignment
+	b.align(8)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	b.put(pos, 4, uint64(uint32(int32(pos-vt))))
+	for i := range t {
+		if t[i].size > 0 && t[i].ref == nil {
+			b.put(pos+offs[i], t[i].size, t[i].bits)
+		}
+	}
+	for i := range t {
+		if t[i].ref != nil {
+			b.patch(pos+offs[i], t[i].ref.encode(b))
+		}
+	}
+	return pos
+}
+
+func (s fbString) encode(b *fbuf) int {
+	b.align(4)
+	pos := len(b.buf)
+	b.u32(uint32(len(s)))
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+	return pos
+}
+
+func (v fbVector) encode(b *fbuf) int {
+	b.align(4)
+	pos := len(b.buf)
+	b.u32(uint32(len(v)))
+	b.buf = append(b.buf, make([]byte, 4*len(v))...)
+	for i := range v {
+		b.patch(pos+4+4*i, v[i].encode(b))
+	}
+	return pos
+}
+
+func (p fbPairs) encode(b *fbuf) int {
+	// the elements must be 8-byte aligned
+	for len(b.buf)%8 != 4 {
+		b.buf = append(b.buf, 0)
+	}
+	pos := len(b.buf)
+	b.u32(uint32(len(p) / 2))
+	for _, w := range p {
+		b.buf = binary.LittleEndian.AppendUint64(b.buf, w)
+	}
+	return pos
+}
+
+// finish returns the encoding
+// of a flatbuffer with the given root,
+// padded to a multiple of 8 bytes
+func finish(root fbValue) []byte {
+	b := &fbuf{buf: make([]byte, 4, 256)}
+	b.patch(0, root.encode(b))
+	b.align(8)
+	return b.buf
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Command generate writes golden.arrows in the
+// parent directory using the Apache Arrow IPC
+// stream writer. The stream holds the same rows,
+// in the same record batches, as the stream
+// written by TestGolden in the arrow package.
+//
+// It is a separate module so that the arrow
+// package does not depend on Apache Arrow:
+//
+//	cd arrow/testdata/generate && go run . ..
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/ipc"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+)
+
+var schema = arrow.NewSchema([]arrow.Field{
+	{Name: "i", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	{Name: "f", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	{Name: "s", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "b", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+	{Name: "t", Type: &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}, Nullable: true},
+	{Name: "l", Type: arrow.ListOfField(arrow.Field{Name: "item", Type: arrow.PrimitiveTypes.Int64, Nullable: true}), Nullable: true},
+	{Name: "st", Type: arrow.StructOf(
+		arrow.Field{Name: "x", Type: arrow.BinaryTypes.String, Nullable: true},
+	), Nullable: true},
+	{Name: "n", Type: arrow.Null, Nullable: true},
+}, nil)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: generate <dir>")
+		os.Exit(1)
+	}
+	f, err := os.Create(filepath.Join(os.Args[1], "golden.arrows"))
+	if err != nil {
+		fatal(err)
+	}
+	w := ipc.NewWriter(f, ipc.WithSchema(schema))
+	t0 := time.Date(2023, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	i := b.Field(0).(*array.Int64Builder)
+	fl := b.Field(1).(*array.Float64Builder)
+	s := b.Field(2).(*array.StringBuilder)
+	bo := b.Field(3).(*array.BooleanBuilder)
+	ts := b.Field(4).(*array.TimestampBuilder)
+	l := b.Field(5).(*array.ListBuilder)
+	li := l.ValueBuilder().(*array.Int64Builder)
+	st := b.Field(6).(*array.StructBuilder)
+	x := st.FieldBuilder(0).(*array.StringBuilder)
+	n := b.Field(7).(*array.NullBuilder)
+
+	// first batch
+	i.Append(1)
+	fl.Append(1.5)
+	s.Append("hello")
+	bo.Append(true)
+	ts.Append(arrow.Timestamp(t0.UnixMicro()))
+	l.Append(true)
+	li.AppendValues([]int64{1, 2}, nil)
+	st.Append(true)
+	x.Append("y")
+	n.AppendNull()
+
+	i.Append(-7)
+	fl.Append(3)
+	s.AppendNull()
+	bo.AppendNull()
+	ts.AppendNull()
+	l.Append(true) // empty list
+	st.AppendNull()
+	x.AppendNull()
+	n.AppendNull()
+	write(w, b)
+
+	// second batch
+	i.AppendNull()
+	fl.AppendNull()
+	s.Append("world")
+	bo.AppendNull()
+	ts.AppendNull()
+	l.AppendNull()
+	st.Append(true)
+	x.AppendNull()
+	n.AppendNull()
+	write(w, b)
+
+	if err := w.Close(); err != nil {
+		fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		fatal(err)
+	}
+}
+
+func write(w *ipc.Writer, b *array.RecordBuilder) {
+	rec := b.NewRecord()
+	defer rec.Release()
+	if err := w.Write(rec); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
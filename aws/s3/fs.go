@@ -92,11 +92,39 @@ func (b *BucketFS) Put(where string, contents []byte) (string, error) {
 	return b.put(where, contents)
 }
 
+// PutIfMatch is like Put, but the object is only
+// written if its current ETag is equal to etag.
+// If etag is the empty string, the object is only
+// written if it does not already exist.
+// If the precondition does not hold, PutIfMatch
+// returns an error wrapping ErrPreconditionFailed.
+func (b *BucketFS) PutIfMatch(where string, contents []byte, etag string) (string, error) {
+	where = path.Clean(where)
+	if !fs.ValidPath(where) {
+		return "", badpath("s3 PUT", where)
+	}
+	_, base := path.Split(where)
+	if base == "." {
+		return "", badpath("s3 PUT", where)
+	}
+	if etag == "" {
+		return b.putHeader(where, contents, "If-None-Match", "*")
+	}
+	return b.putHeader(where, contents, "If-Match", etag)
+}
+
 func (b *BucketFS) put(where string, contents []byte) (string, error) {
+	return b.putHeader(where, contents, "", "")
+}
+
+func (b *BucketFS) putHeader(where string, contents []byte, hdr, value string) (string, error) {
 	req, err := http.NewRequestWithContext(b.Ctx, http.MethodPut, uri(b.Key, b.Bucket, where), nil)
 	if err != nil {
 		return "", err
 	}
+	if hdr != "" {
+		req.Header.Set(hdr, value)
+	}
 	b.Key.SignV4(req, contents)
 	client := b.Client
 	if client == nil {
@@ -107,7 +135,16 @@ func (b *BucketFS) put(where string, contents []byte) (string, error) {
 		return "", err
 	}
 	defer res.Body.Close()
-	if res.StatusCode != 200 {
+	switch res.StatusCode {
+	case 200:
+	case http.StatusNotFound, http.StatusPreconditionFailed, http.StatusConflict:
+		// a conditional write can fail with 409
+		// if it races with another write
+		if hdr != "" {
+			return "", fmt.Errorf("s3 PUT %s: %w", where, ErrPreconditionFailed)
+		}
+		fallthrough
+	default:
 		return "", fmt.Errorf("s3 PUT: %s %s", res.Status, extractMessage(res.Body))
 	}
 	etag := res.Header.Get("ETag")
@@ -69,6 +69,10 @@ var (
 	// that file read operations are always consistent with respect
 	// to the ETag originally associated with the file handle.)
 	ErrETagChanged = errors.New("file ETag changed")
+	// ErrPreconditionFailed is returned from conditional
+	// write operations where the precondition on the
+	// existing object did not hold.
+	ErrPreconditionFailed = errors.New("precondition failed")
 )
 
 func badBucket(name string) error {
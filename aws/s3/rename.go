@@ -0,0 +1,79 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package s3
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+)
+
+// Rename moves the object at oldpath to newpath
+// by performing a server-side copy of the object
+// followed by a deletion of the original.
+//
+// Rename is not atomic: if the deletion fails,
+// the object remains visible at both paths.
+// An existing object at newpath is replaced
+// atomically by the copy. Objects larger than
+// 5GiB cannot be renamed with a single copy
+// and will produce an error.
+func (b *BucketFS) Rename(oldpath, newpath string) error {
+	oldpath = path.Clean(oldpath)
+	if !fs.ValidPath(oldpath) || oldpath == "." {
+		return badpath("s3 rename", oldpath)
+	}
+	newpath = path.Clean(newpath)
+	if !fs.ValidPath(newpath) || newpath == "." {
+		return badpath("s3 rename", newpath)
+	}
+	if oldpath == newpath {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(b.Ctx, http.MethodPut, uri(b.Key, b.Bucket, newpath), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-amz-copy-source", "/"+b.Bucket+"/"+almostPathEscape(oldpath))
+	b.Key.SignV4(req, nil)
+	client := b.Client
+	if client == nil {
+		client = &DefaultClient
+	}
+	res, err := flakyDo(client, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return fmt.Errorf("s3 CopyObject: %s %s", res.Status, extractMessage(res.Body))
+	}
+	// CopyObject can fail after returning
+	// 200 OK, in which case the body holds
+	// an <Error> rather than a result
+	rt := struct {
+		XMLName xml.Name
+		ETag    string `xml:"ETag"`
+	}{}
+	if err := xml.NewDecoder(res.Body).Decode(&rt); err != nil {
+		return fmt.Errorf("s3 CopyObject: decoding response: %w", err)
+	}
+	if rt.XMLName.Local != "CopyObjectResult" || rt.ETag == "" {
+		return fmt.Errorf("s3 CopyObject: unexpected response %q", rt.XMLName.Local)
+	}
+	return b.Remove(oldpath)
+}
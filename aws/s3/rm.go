@@ -15,6 +15,9 @@
 package s3
 
 import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
 	"fmt"
 	"io/fs"
 	"net/http"
@@ -46,3 +49,134 @@ func (b *BucketFS) Remove(fullpath string) error {
 	}
 	return nil
 }
+
+// MaxDeleteKeys is the maximum number of keys
+// that can be deleted in a single DeleteObjects request.
+const MaxDeleteKeys = 1000
+
+type deleteObject struct {
+	Key string `xml:"Key"`
+}
+
+type deleteRequest struct {
+	XMLName xml.Name       `xml:"Delete"`
+	NS      string         `xml:"xmlns,attr"`
+	Quiet   bool           `xml:"Quiet"`
+	Objects []deleteObject `xml:"Object"`
+}
+
+type deleteError struct {
+	Key     string `xml:"Key"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+type deleteResult struct {
+	XMLName xml.Name
+	Errors  []deleteError `xml:"Error"`
+
+	// error fields:
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// RemoveAll removes each of the objects in paths
+// using DeleteObjects requests of up to MaxDeleteKeys
+// keys each. It returns the list of paths that could not
+// be removed along with the first error encountered.
+// Removing an object that does not exist is not an error.
+//
+// Unlike os.RemoveAll, RemoveAll does not
+// remove objects that merely share a prefix
+// with any of the given paths.
+func (b *BucketFS) RemoveAll(paths []string) ([]string, error) {
+	var failed []string
+	var first error
+	fail := func(p string, err error) {
+		failed = append(failed, p)
+		if first == nil {
+			first = err
+		}
+	}
+	keys := make([]string, 0, min(len(paths), MaxDeleteKeys))
+	flush := func() {
+		if len(keys) == 0 {
+			return
+		}
+		bad, err := b.deleteObjects(keys)
+		if err != nil {
+			for i := range keys {
+				fail(keys[i], err)
+			}
+		} else {
+			for i := range bad {
+				fail(bad[i].Key, fmt.Errorf("s3 DeleteObjects: %s: %s %s", bad[i].Key, bad[i].Code, bad[i].Message))
+			}
+		}
+		keys = keys[:0]
+	}
+	for _, p := range paths {
+		clean := path.Clean(p)
+		if !fs.ValidPath(clean) || clean == "." {
+			fail(p, badpath("s3 DeleteObjects", p))
+			continue
+		}
+		keys = append(keys, clean)
+		if len(keys) == MaxDeleteKeys {
+			flush()
+		}
+	}
+	flush()
+	return failed, first
+}
+
+// deleteObjects performs a single DeleteObjects
+// request and returns the per-key errors
+func (b *BucketFS) deleteObjects(keys []string) ([]deleteError, error) {
+	body := deleteRequest{
+		NS:      "http://s3.amazonaws.com/doc/2006-03-01/",
+		Quiet:   true,
+		Objects: make([]deleteObject, len(keys)),
+	}
+	for i := range keys {
+		body.Objects[i].Key = keys[i]
+	}
+	buf, err := xml.Marshal(&body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(b.Ctx, http.MethodPost, rawURI(b.Key, b.Bucket, "?delete="), nil)
+	if err != nil {
+		return nil, err
+	}
+	// DeleteObjects requires a Content-MD5 header
+	sum := md5.Sum(buf)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	req.Header.Set("Content-Type", "application/xml")
+	b.Key.SignV4(req, buf)
+	client := b.Client
+	if client == nil {
+		client = &DefaultClient
+	}
+	res, err := flakyDo(client, req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("s3 DeleteObjects: %s %s", res.Status, extractMessage(res.Body))
+	}
+	var rt deleteResult
+	err = xml.NewDecoder(res.Body).Decode(&rt)
+	if err != nil {
+		return nil, fmt.Errorf("s3 DeleteObjects: decoding response: %w", err)
+	}
+	switch rt.XMLName.Local {
+	case "DeleteResult":
+		return rt.Errors, nil
+	case "Error":
+		return nil, fmt.Errorf("s3 DeleteObjects: %s %s", rt.Code, rt.Message)
+	default:
+		return nil, fmt.Errorf("s3 DeleteObjects: unexpected object %s", rt.XMLName.Local)
+	}
+}
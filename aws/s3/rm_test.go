@@ -0,0 +1,142 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/SnellerInc/sneller/aws"
+)
+
+// deleteRoundTripper is a fake DeleteObjects endpoint
+// that fails to delete any key containing "bad"
+// and fails every request in which fail(n) is true,
+// where n is the index of the request
+type deleteRoundTripper struct {
+	t       *testing.T
+	fail    func(n int) bool
+	batches [][]string
+}
+
+func (d *deleteRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	defer req.Body.Close()
+	if req.Method != "POST" || req.URL.RequestURI() != "/?delete=" {
+		d.t.Errorf("unexpected request %s %s", req.Method, req.URL.RequestURI())
+		return nil, errUnexpected
+	}
+	for _, h := range []string{"Authorization", "Content-MD5"} {
+		if req.Header.Get(h) == "" {
+			d.t.Errorf("header %q missing", h)
+			return nil, errUnexpected
+		}
+	}
+	var body deleteRequest
+	if err := xml.NewDecoder(req.Body).Decode(&body); err != nil {
+		d.t.Errorf("decoding request: %s", err)
+		return nil, errUnexpected
+	}
+	var keys []string
+	var out strings.Builder
+	out.WriteString("<DeleteResult>")
+	for i := range body.Objects {
+		key := body.Objects[i].Key
+		keys = append(keys, key)
+		if strings.Contains(key, "bad") {
+			fmt.Fprintf(&out, "<Error><Key>%s</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error>", key)
+		}
+	}
+	out.WriteString("</DeleteResult>")
+	n := len(d.batches)
+	d.batches = append(d.batches, keys)
+	code, text := 200, out.String()
+	if d.fail != nil && d.fail(n) {
+		code, text = 403, "<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>"
+	}
+	return &http.Response{
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Body:          io.NopCloser(strings.NewReader(text)),
+		ContentLength: int64(len(text)),
+		Header:        make(http.Header),
+	}, nil
+}
+
+func TestRemoveAll(t *testing.T) {
+	drt := &deleteRoundTripper{t: t}
+	b := &BucketFS{
+		Key:    aws.DeriveKey("", "fake-access-key", "fake-secret-key", "us-east-1", "s3"),
+		Client: &http.Client{Transport: drt},
+		Bucket: "the-bucket",
+		Ctx:    context.Background(),
+	}
+	var paths []string
+	for i := 0; i < 2*MaxDeleteKeys+3; i++ {
+		paths = append(paths, fmt.Sprintf("dir/obj-%d", i))
+	}
+	paths[5] = "dir/bad-5"
+	paths[MaxDeleteKeys+7] = "dir/bad-1007"
+	paths[9] = "../invalid"
+	failed, err := b.RemoveAll(paths)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	want := []string{"../invalid", "dir/bad-5", "dir/bad-1007"}
+	if !slices.Equal(failed, want) {
+		t.Errorf("failed = %v, want %v", failed[:min(len(failed), 10)], want)
+	}
+	// the invalid path was never sent,
+	// so we get [1000, 1000, 2]
+	if len(drt.batches) != 3 {
+		t.Fatalf("got %d batches", len(drt.batches))
+	}
+	for i, n := range []int{MaxDeleteKeys, MaxDeleteKeys, 2} {
+		if len(drt.batches[i]) != n {
+			t.Errorf("batch %d: %d keys, want %d", i, len(drt.batches[i]), n)
+		}
+	}
+
+	// a failed request fails every key in it
+	drt.batches = nil
+	drt.fail = func(n int) bool { return n == 1 }
+	failed, err = b.RemoveAll(paths[:MaxDeleteKeys+2])
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	// the invalid path, bad-5, and the one
+	// key left over for the second request
+	if len(failed) != 3 {
+		t.Errorf("got %d failed paths", len(failed))
+	}
+
+	// no failures
+	drt.batches = nil
+	drt.fail = nil
+	failed, err = b.RemoveAll([]string{"a", "b/c"})
+	if err != nil || len(failed) != 0 {
+		t.Errorf("got %v, %v", failed, err)
+	}
+	if len(drt.batches) != 1 || len(drt.batches[0]) != 2 {
+		t.Errorf("unexpected batches %v", drt.batches)
+	}
+}
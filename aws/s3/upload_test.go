@@ -15,6 +15,7 @@
 package s3
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -198,3 +199,55 @@ func TestUpload(t *testing.T) {
 		t.Errorf("abort: %s", err)
 	}
 }
+
+func TestPutIfMatch(t *testing.T) {
+	trt := &testRoundTripper{t: t}
+	b := &BucketFS{
+		Key:    aws.DeriveKey("", "fake-access-key", "fake-secret-key", "us-east-1", "s3"),
+		Client: &http.Client{Transport: trt},
+		Bucket: "the-bucket",
+		Ctx:    context.Background(),
+	}
+	trt.expect.method = "PUT"
+	trt.expect.uri = "/the-object"
+	trt.expect.body = "contents"
+
+	// not-exists
+	trt.expect.headers = []string{"Authorization", "If-None-Match"}
+	trt.response.code = 200
+	trt.response.headers = make(http.Header)
+	trt.response.headers.Set("ETag", `"etag-0"`)
+	etag, err := b.PutIfMatch("the-object", []byte("contents"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if etag != `"etag-0"` {
+		t.Errorf("got ETag %s", etag)
+	}
+
+	// match
+	trt.expect.headers = []string{"Authorization", "If-Match"}
+	trt.response.headers.Set("ETag", `"etag-1"`)
+	etag, err = b.PutIfMatch("the-object", []byte("contents"), `"etag-0"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if etag != `"etag-1"` {
+		t.Errorf("got ETag %s", etag)
+	}
+
+	// mismatch
+	trt.response.code = 412
+	trt.response.headers = make(http.Header)
+	trt.response.body = "<Error><Code>PreconditionFailed</Code></Error>"
+	_, err = b.PutIfMatch("the-object", []byte("contents"), `"etag-0"`)
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("expected ErrPreconditionFailed; got %v", err)
+	}
+	// an unconditional Put can't fail that way
+	trt.expect.headers = []string{"Authorization"}
+	_, err = b.Put("the-object", []byte("contents"))
+	if err == nil || errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -17,7 +17,6 @@ package main
 import (
 	"flag"
 	"io/fs"
-	"strings"
 
 	"github.com/SnellerInc/sneller/ion/blockfmt"
 )
@@ -36,11 +35,8 @@ func openinput(rootfs fs.FS, name, format string) blockfmt.Input {
 	if format != "" {
 		rf = blockfmt.MustSuffixToFormat(format)
 	} else {
-		for suff, cons := range blockfmt.SuffixToFormat {
-			if strings.HasSuffix(name, suff) {
-				rf, _ = cons(nil)
-				break
-			}
+		if cons := blockfmt.FormatForName(name); cons != nil {
+			rf, _ = cons(nil)
 		}
 		if rf == nil {
 			exitf("couldn't infer format of %s", name)
@@ -39,6 +39,7 @@ import (
 	"github.com/SnellerInc/sneller/plan"
 	"github.com/SnellerInc/sneller/tenant/dcache"
 	"github.com/SnellerInc/sneller/vm"
+	"github.com/SnellerInc/sneller/xsv"
 
 	"golang.org/x/sys/cpu"
 )
@@ -113,9 +114,12 @@ func (c *cmdlineEnv) Stat(tbl expr.Node, h *plan.Hints) (*plan.Input, error) {
 }
 
 func runner(cachedir string, root fs.FS) plan.Runner {
-	switch root.(type) {
+	switch root := root.(type) {
 	case *db.DirFS:
-		return &plan.FSRunner{root}
+		// local files are mmapped by FSRunner
+		// and each of them is scanned in order
+		root.SequentialMmap = true
+		return &plan.FSRunner{FS: root.DirFS}
 	case *db.S3FS:
 		cachedir = filepath.Join(cachedir, "sneller-sdb")
 		cache := dcache.New(cachedir, func() {})
@@ -181,7 +185,7 @@ func query(args []string) bool {
 	flags.BoolVar(&dashv, "v", false, "verbose diagnostics")
 	flags.StringVar(&dashtrace, "trace", "", "trace output file (\"-\" implies stderr)")
 	flags.StringVar(&dashtracefmt, "tracefmt", "text", "trace output (text, graphviz)")
-	flags.StringVar(&dashfmt, "fmt", "ion", "output format (json, ion, ...)")
+	flags.StringVar(&dashfmt, "fmt", "ion", "output format (json, ion, ion-records, ndtext, csv)")
 	flags.StringVar(&dashtmp, "tmp", os.TempDir(), "cache directory")
 	flags.Parse(args[1:])
 	args = flags.Args()
@@ -220,6 +224,14 @@ func query(args []string) bool {
 		// leave as-is
 	case "json":
 		stdout = ion.NewJSONWriter(stdout, '\n')
+	case "ion-records", "ndtext":
+		w := ion.NewRecordWriter(stdout)
+		w.Text = dashfmt == "ndtext"
+		stdout = w
+	case "csv":
+		w := xsv.NewWriter(stdout)
+		defer w.Close()
+		stdout = w
 	default:
 		exitf("unsupported output format %q", dashfmt)
 	}
@@ -315,7 +327,7 @@ func init() {
 	addApplet(applet{
 		run:  query,
 		name: "query",
-		help: "[-v] [-o output] [-fmt json|ion] [-f query.sql]",
+		help: "[-v] [-o output] [-fmt json|ion|ion-records|ndtext|csv] [-f query.sql]",
 		desc: `run a query locally
 The command
   $ sdb query <sql-text>
@@ -328,7 +340,12 @@ by -root. (Note that read_file also reads files relative to -root.)
 
 The -fmt flag can be used to change the output of the query engine.
 The default behavior is to produce binary ion data, but -fmt=json can
-be specified in order to produce JSON data.
+be specified in order to produce JSON data, and -fmt=csv can be
+specified in order to produce CSV data with a header row
+taken from the fields of the first row. The -fmt=ion-records
+format writes each row as a self-contained binary ion value
+(each beginning with its own symbol table), and the -fmt=ndtext
+format writes each row as a text ion value followed by a newline.
 `,
 	})
 }
@@ -0,0 +1,66 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/SnellerInc/sneller/db"
+)
+
+func verify(creds db.Tenant, dbname, table string) {
+	ofs := root(creds)
+	idx, err := db.OpenIndex(ofs, dbname, table, creds.Key())
+	if err != nil {
+		exitf("opening index: %s", err)
+	}
+	errs := idx.Verify(ofs)
+	for i := range errs {
+		fmt.Fprintln(os.Stderr, errs[i])
+	}
+	if len(errs) > 0 {
+		exitf("%d objects missing or modified", len(errs))
+	}
+}
+
+func init() {
+	addApplet(applet{
+		name: "verify",
+		help: "<db> <table>",
+		desc: `verify that every object in an index is present
+The command
+  $ sdb verify <db> <table>
+loads the index file associated with the
+provided db and table and checks that each
+of the packed files and indirect references
+comprising the index still exists with the
+ETag recorded in the index.
+Any missing or modified objects are reported on stderr.
+
+Unlike validate, verify does not read the contents
+of the packed files.
+
+See also: validate
+`,
+		run: func(args []string) bool {
+			if len(args) != 3 {
+				return false
+			}
+			verify(creds(), args[1], args[2])
+			return true
+		},
+	})
+}
@@ -140,8 +140,9 @@ func runWorker(args []string) {
 	}
 	srv := tnproto.Server{
 		Server: plan.Server{
-			Runner: &run,
-			InitFS: initfs,
+			Runner:   &run,
+			InitFS:   initfs,
+			Programs: &vm.ProgramCache{Limit: 1024},
 		},
 		Logf: logger.Printf,
 	}
@@ -17,8 +17,13 @@
 package compr
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"runtime"
+	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/klauspost/compress/s2"
@@ -107,11 +112,58 @@ func DecodeZstd(src, dst []byte) ([]byte, error) {
 	return zstdDecoder.DecodeAll(src, dst)
 }
 
+// DecodeReader returns a reader that incrementally
+// decompresses the zstd stream read from r.
+// Unlike DecodeZstd, neither the compressed
+// nor the decompressed data is buffered in full.
+// The returned reader should be closed to
+// release its resources.
+func DecodeReader(r io.Reader) (io.ReadCloser, error) {
+	// a single goroutine and low-memory mode keep
+	// the memory use bounded by the window size
+	z, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true))
+	if err != nil {
+		return nil, err
+	}
+	return z.IOReadCloser(), nil
+}
+
+// Sizer is implemented by the Decompressors
+// whose compressed data records its decompressed
+// size, so that the size can be checked before
+// any memory is allocated for the output.
+type Sizer interface {
+	// DecodedLen returns the decompressed size
+	// recorded in src, or an error if src does
+	// not record it.
+	DecodedLen(src []byte) (int, error)
+}
+
+var errNoSize = errors.New("decompressed size not recorded")
+
 type zstdDecompressor zstd.Decoder
 
 func (z *zstdDecompressor) Name() string { return "zstd" }
 
+// DecodedLen implements Sizer.DecodedLen
+// using the size in the header of the first frame
+func (z *zstdDecompressor) DecodedLen(src []byte) (int, error) {
+	var h zstd.Header
+	if err := h.Decode(src); err != nil {
+		return 0, err
+	}
+	if !h.HasFCS || h.FrameContentSize > math.MaxInt32 {
+		return 0, errNoSize
+	}
+	return int(h.FrameContentSize), nil
+}
+
 func (z *zstdDecompressor) Decompress(src, dst []byte) error {
+	// the decoder would grow dst to fit
+	// the whole frame before failing
+	if n, err := z.DecodedLen(src); err == nil && n > len(dst) {
+		return fmt.Errorf("expected %d bytes decompressed; got %d", len(dst), n)
+	}
 	into := dst[:0:len(dst)]
 	ret, err := (*zstd.Decoder)(z).DecodeAll(src, into)
 	if err != nil {
@@ -146,7 +198,17 @@ func (s2Compressor) Compress(src, dst []byte) []byte {
 	return append(dst, got...)
 }
 
+// DecodedLen implements Sizer.DecodedLen
+func (s2Compressor) DecodedLen(src []byte) (int, error) {
+	return s2.DecodedLen(src)
+}
+
 func (s2Compressor) Decompress(src, dst []byte) error {
+	// s2.Decode would allocate a
+	// new buffer that fits the output
+	if n, err := s2.DecodedLen(src); err == nil && n > len(dst) {
+		return fmt.Errorf("expected %d bytes decompressed; got %d", len(dst), n)
+	}
 	into := dst[:0:len(dst)]
 	ret, err := s2.Decode(into, src)
 	if err != nil {
@@ -165,9 +227,100 @@ func (s2Compressor) Decompress(src, dst []byte) error {
 
 func (s2Compressor) Name() string { return "s2" }
 
+// CompressStats describes the result
+// of one or more calls to Compress.
+type CompressStats struct {
+	// Calls is the number of calls to Compress.
+	Calls int
+	// In and Out are the total number of bytes
+	// compressed and the number of compressed
+	// bytes they produced.
+	In, Out int64
+	// Elapsed is the total time spent compressing.
+	Elapsed time.Duration
+}
+
+// Add adds the stats from o to s.
+func (s *CompressStats) Add(o *CompressStats) {
+	s.Calls += o.Calls
+	s.In += o.In
+	s.Out += o.Out
+	s.Elapsed += o.Elapsed
+}
+
+// Ratio returns the compression ratio
+// (In/Out), or 0 if nothing was compressed.
+func (s *CompressStats) Ratio() float64 {
+	if s.Out == 0 {
+		return 0
+	}
+	return float64(s.In) / float64(s.Out)
+}
+
+// CompressWithStats calls c.Compress(src, dst)
+// and returns the result along with the
+// statistics of the call.
+func CompressWithStats(c Compressor, src, dst []byte) ([]byte, CompressStats) {
+	start := time.Now()
+	ret := c.Compress(src, dst)
+	return ret, CompressStats{
+		Calls:   1,
+		In:      int64(len(src)),
+		Out:     int64(len(ret) - len(dst)),
+		Elapsed: time.Since(start),
+	}
+}
+
+var (
+	registryLock  sync.RWMutex
+	compressors   = make(map[string]Compressor)
+	decompressors = make(map[string]Decompressor)
+)
+
+func builtin(name string) bool {
+	switch name {
+	case "zstd", "zstd-better", "zstd-nocrc", "s2", "lz4":
+		return true
+	}
+	return false
+}
+
+// Register makes a compression algorithm available
+// by name to Compression and Decompression.
+// Either c or d may be nil if the algorithm
+// is only used for compression or decompression.
+// The names of c and d should equal name.
+//
+// Register panics if name is the name of a built-in
+// algorithm or if it is registered more than once.
+func Register(name string, c Compressor, d Decompressor) {
+	if builtin(name) {
+		panic("compr: cannot replace built-in algorithm " + name)
+	}
+	if c == nil && d == nil {
+		panic("compr: Register called with nil compressor and decompressor")
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if _, ok := compressors[name]; ok {
+		panic("compr: Register called twice for " + name)
+	}
+	if _, ok := decompressors[name]; ok {
+		panic("compr: Register called twice for " + name)
+	}
+	if c != nil {
+		compressors[name] = c
+	}
+	if d != nil {
+		decompressors[name] = d
+	}
+}
+
 // Compression selects a compression algorithm by name.
 // The returned Compressor will return the same value
 // for Compressor.Name as the specified name.
+// Algorithms that are not built in are looked up
+// among those added with Register.
 func Compression(name string) Compressor {
 	switch name {
 	case "zstd-better":
@@ -176,11 +329,18 @@ func Compression(name string) Compressor {
 		return zstdCompressor{zstdEncoder}
 	case "s2":
 		return s2Compressor{}
+	case "lz4":
+		return lz4Compressor{}
 	default:
-		return nil
+		registryLock.RLock()
+		defer registryLock.RUnlock()
+		return compressors[name]
 	}
 }
 
+// Decompression selects a decompression algorithm
+// by name. Algorithms that are not built in are
+// looked up among those added with Register.
 func Decompression(name string) Decompressor {
 	switch name {
 	case "zstd":
@@ -189,8 +349,12 @@ func Decompression(name string) Decompressor {
 		return (*zstdDecompressor)(zstdFastDecoder)
 	case "s2":
 		return s2Compressor{}
+	case "lz4":
+		return lz4Compressor{}
 	default:
-		return nil
+		registryLock.RLock()
+		defer registryLock.RUnlock()
+		return decompressors[name]
 	}
 }
 
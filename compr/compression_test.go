@@ -16,6 +16,13 @@ package compr
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -51,6 +58,28 @@ func TestS2(t *testing.T) {
 	}
 }
 
+func TestDecodedLen(t *testing.T) {
+	src := bytes.Repeat([]byte("foo"), 1000)
+	for _, algo := range []string{"zstd", "zstd-nocrc", "s2"} {
+		dec, ok := Decompression(algo).(Sizer)
+		if !ok {
+			t.Fatalf("%s: %T is not a Sizer", algo, Decompression(algo))
+		}
+		cmp := Compression(strings.TrimSuffix(algo, "-nocrc")).Compress(src, nil)
+		n, err := dec.DecodedLen(cmp)
+		if err != nil {
+			t.Fatalf("%s: %v", algo, err)
+		}
+		if n != len(src) {
+			t.Errorf("%s: DecodedLen returned %d; want %d", algo, n, len(src))
+		}
+		// a short output buffer is an error
+		if err := Decompression(algo).Decompress(cmp, make([]byte, len(src)-1)); err == nil {
+			t.Errorf("%s: expected an error", algo)
+		}
+	}
+}
+
 func TestOverlaps(t *testing.T) {
 	// trivial case
 	a := make([]byte, 10)
@@ -81,3 +110,136 @@ func TestOverlaps(t *testing.T) {
 		t.Error("overlaps(b, a) should be true")
 	}
 }
+
+// rawCodec is a trivial codec that
+// stores the data uncompressed
+type rawCodec struct{}
+
+func (rawCodec) Name() string { return "test-raw" }
+
+func (rawCodec) Compress(src, dst []byte) []byte { return append(dst, src...) }
+
+func (rawCodec) Decompress(src, dst []byte) error {
+	if len(src) != len(dst) {
+		return fmt.Errorf("expected %d bytes decompressed; got %d", len(dst), len(src))
+	}
+	copy(dst, src)
+	return nil
+}
+
+var registerOnce sync.Once
+
+func TestRegister(t *testing.T) {
+	registerOnce.Do(func() {
+		Register("test-raw", rawCodec{}, rawCodec{})
+	})
+	comp := Compression("test-raw")
+	if comp == nil {
+		t.Fatal("no compressor for registered algorithm")
+	}
+	dec := Decompression("test-raw")
+	if dec == nil {
+		t.Fatal("no decompressor for registered algorithm")
+	}
+	src := bytes.Repeat([]byte("foo"), 100)
+	dst := make([]byte, len(src))
+	if err := dec.Decompress(comp.Compress(src, nil), dst); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(src, dst) {
+		t.Fatal("mismatch")
+	}
+	if Compression("test-unknown") != nil || Decompression("test-unknown") != nil {
+		t.Error("unknown algorithm should return nil")
+	}
+	mustPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: expected a panic", name)
+			}
+		}()
+		fn()
+	}
+	mustPanic("duplicate", func() { Register("test-raw", rawCodec{}, nil) })
+	mustPanic("built-in", func() { Register("zstd", rawCodec{}, rawCodec{}) })
+}
+
+func TestDecodeReader(t *testing.T) {
+	// a large, compressible object
+	size := 64 << 20
+	if testing.Short() {
+		size = 4 << 20
+	}
+	src := make([]byte, 0, size)
+	rng := rand.New(rand.NewSource(0))
+	for len(src) < size {
+		src = fmt.Appendf(src, "{\"id\": %d, \"name\": \"item-%d\"}\n", rng.Intn(1<<20), rng.Intn(100))
+	}
+	comp := Compression("zstd").Compress(src, nil)
+	srclen := int64(len(src))
+	src = nil
+
+	want, err := DecodeZstd(comp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantsum := sha256.Sum256(want)
+	want = nil
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	r, err := DecodeReader(bytes.NewReader(comp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	runtime.ReadMemStats(&after)
+
+	if n != srclen {
+		t.Fatalf("decompressed %d bytes; expected %d", n, srclen)
+	}
+	if !bytes.Equal(h.Sum(nil), wantsum[:]) {
+		t.Fatal("streamed output does not match the buffered output")
+	}
+	// streaming should not allocate anywhere
+	// near the size of the decompressed data
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > uint64(n)/4 {
+		t.Errorf("allocated %d bytes while streaming %d bytes", alloc, n)
+	} else {
+		t.Logf("allocated %d bytes while streaming %d bytes", alloc, n)
+	}
+}
+
+func TestCompressWithStats(t *testing.T) {
+	src := bytes.Repeat([]byte("foo bar baz "), 1000)
+	for _, name := range []string{"zstd", "zstd-better", "s2"} {
+		prefix := []byte("prefix")
+		dst, stats := CompressWithStats(Compression(name), src, prefix)
+		if stats.Calls != 1 {
+			t.Errorf("%s: %d calls", name, stats.Calls)
+		}
+		if stats.In != int64(len(src)) {
+			t.Errorf("%s: got input size %d; expected %d", name, stats.In, len(src))
+		}
+		if want := int64(len(dst) - len(prefix)); stats.Out != want {
+			t.Errorf("%s: got output size %d; expected %d", name, stats.Out, want)
+		}
+		if r := stats.Ratio(); r <= 1 {
+			t.Errorf("%s: unexpected ratio %g", name, r)
+		}
+		var total CompressStats
+		total.Add(&stats)
+		total.Add(&stats)
+		if total.Calls != 2 || total.In != 2*stats.In || total.Out != 2*stats.Out {
+			t.Errorf("%s: bad aggregate %+v", name, total)
+		}
+	}
+}
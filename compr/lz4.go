@@ -0,0 +1,196 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package compr
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// lz4 block format constants;
+// see https://github.com/lz4/lz4/blob/dev/doc/lz4_Block_format.md
+const (
+	lz4MinMatch     = 4
+	lz4LastLiterals = 5  // the last 5 bytes are always literals
+	lz4MFLimit      = 12 // the last match starts at least 12 bytes before the end
+	lz4MaxOffset    = 65535
+	lz4HashLog      = 14
+)
+
+var errLZ4Corrupt = errors.New("lz4: corrupt input")
+
+// lz4Compressor implements the lz4 block format
+// (without the frame format), which trades
+// compression ratio for compression speed.
+type lz4Compressor struct{}
+
+func (lz4Compressor) Name() string { return "lz4" }
+
+func lz4Hash(u uint32) uint32 {
+	return (u * 2654435761) >> (32 - lz4HashLog)
+}
+
+func (lz4Compressor) Compress(src, dst []byte) []byte {
+	// appending must not clobber src
+	if overlaps(src, dst[len(dst):cap(dst)]) {
+		dst = append(make([]byte, 0, len(dst)+len(src)/2), dst...)
+	}
+
+	// table holds the position+1 of the
+	// last occurrence of each hashed 4-byte sequence
+	var table [1 << lz4HashLog]int32
+	anchor := 0
+	i := 0
+	for i <= len(src)-lz4MFLimit {
+		seq := binary.LittleEndian.Uint32(src[i:])
+		h := lz4Hash(seq)
+		ref := int(table[h]) - 1
+		table[h] = int32(i + 1)
+		if ref < 0 || i-ref > lz4MaxOffset || binary.LittleEndian.Uint32(src[ref:]) != seq {
+			// skip faster through incompressible data
+			i += 1 + (i-anchor)>>6
+			continue
+		}
+		// extend the match backwards and forwards
+		for i > anchor && ref > 0 && src[i-1] == src[ref-1] {
+			i--
+			ref--
+		}
+		mlen := lz4MinMatch
+		for i+mlen < len(src)-lz4LastLiterals && src[i+mlen] == src[ref+mlen] {
+			mlen++
+		}
+		dst = lz4Sequence(dst, src[anchor:i], i-ref, mlen)
+		i += mlen
+		anchor = i
+	}
+	return lz4Sequence(dst, src[anchor:], 0, 0)
+}
+
+// lz4Sequence appends a sequence to dst;
+// the last sequence has mlen == 0 and no offset
+func lz4Sequence(dst, lit []byte, offset, mlen int) []byte {
+	ll := len(lit)
+	ml := mlen - lz4MinMatch
+	token := byte(min(ll, 15)) << 4
+	if mlen > 0 {
+		token |= byte(min(ml, 15))
+	}
+	dst = append(dst, token)
+	if ll >= 15 {
+		dst = lz4Length(dst, ll-15)
+	}
+	dst = append(dst, lit...)
+	if mlen == 0 {
+		return dst
+	}
+	dst = append(dst, byte(offset), byte(offset>>8))
+	if ml >= 15 {
+		dst = lz4Length(dst, ml-15)
+	}
+	return dst
+}
+
+func lz4Length(dst []byte, n int) []byte {
+	for n >= 255 {
+		dst = append(dst, 255)
+		n -= 255
+	}
+	return append(dst, byte(n))
+}
+
+// lz4ReadLength reads the extension of a
+// length that is 15 in the token
+func lz4ReadLength(src []byte, si, n int) (int, int, error) {
+	for {
+		if si >= len(src) {
+			return 0, 0, errLZ4Corrupt
+		}
+		b := src[si]
+		si++
+		n += int(b)
+		if b != 255 {
+			return n, si, nil
+		}
+		if n > len(src)*255 {
+			return 0, 0, errLZ4Corrupt
+		}
+	}
+}
+
+func (lz4Compressor) Decompress(src, dst []byte) error {
+	si, di := 0, 0
+	var err error
+	for {
+		if si >= len(src) {
+			return errLZ4Corrupt
+		}
+		token := src[si]
+		si++
+		ll := int(token >> 4)
+		if ll == 15 {
+			ll, si, err = lz4ReadLength(src, si, ll)
+			if err != nil {
+				return err
+			}
+		}
+		if ll > len(src)-si || ll > len(dst)-di {
+			return errLZ4Corrupt
+		}
+		copy(dst[di:], src[si:si+ll])
+		si += ll
+		di += ll
+		if si == len(src) {
+			// the last sequence has no match
+			break
+		}
+
+		if len(src)-si < 2 {
+			return errLZ4Corrupt
+		}
+		offset := int(binary.LittleEndian.Uint16(src[si:]))
+		si += 2
+		if offset == 0 || offset > di {
+			return errLZ4Corrupt
+		}
+		ml := int(token & 15)
+		if ml == 15 {
+			ml, si, err = lz4ReadLength(src, si, ml)
+			if err != nil {
+				return err
+			}
+		}
+		ml += lz4MinMatch
+		if ml > len(dst)-di {
+			return errLZ4Corrupt
+		}
+		m := di - offset
+		if offset >= ml {
+			copy(dst[di:di+ml], dst[m:m+ml])
+		} else {
+			// overlapping matches repeat the
+			// last offset bytes
+			for k := 0; k < ml; k++ {
+				dst[di+k] = dst[m+k]
+			}
+		}
+		di += ml
+	}
+	if di != len(dst) {
+		return fmt.Errorf("expected %d bytes decompressed; got %d", len(dst), di)
+	}
+	return nil
+}
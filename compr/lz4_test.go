@@ -0,0 +1,149 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package compr
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func lz4Inputs() [][]byte {
+	rng := rand.New(rand.NewSource(1))
+	random := make([]byte, 100000)
+	rng.Read(random)
+	var text []byte
+	for len(text) < 200000 {
+		text = fmt.Appendf(text, `{"path": "db/foo/bar/packed-%d.ion.zst", "size": %d}`, rng.Intn(1000), rng.Intn(1<<20))
+	}
+	return [][]byte{
+		nil,
+		[]byte("a"),
+		[]byte("abcdefghijk"),
+		[]byte("abcdefghijkl"),
+		bytes.Repeat([]byte("a"), 13),
+		bytes.Repeat([]byte("a"), 100000),
+		bytes.Repeat([]byte("abc"), 1000),
+		bytes.Repeat([]byte("0123456789abcdefghij"), 20000),
+		random,
+		text,
+	}
+}
+
+func TestLZ4(t *testing.T) {
+	comp := Compression("lz4")
+	dec := Decompression("lz4")
+	if comp == nil || dec == nil {
+		t.Fatal("lz4 is not available")
+	}
+	if comp.Name() != "lz4" || dec.Name() != "lz4" {
+		t.Fatalf("bad names %q, %q", comp.Name(), dec.Name())
+	}
+	for i, src := range lz4Inputs() {
+		prefix := []byte("prefix")
+		cmp := comp.Compress(src, prefix)
+		if !bytes.HasPrefix(cmp, []byte("prefix")) {
+			t.Fatalf("input %d: prefix clobbered", i)
+		}
+		dst := make([]byte, len(src))
+		if err := dec.Decompress(cmp[len(prefix):], dst); err != nil {
+			t.Fatalf("input %d: %v", i, err)
+		}
+		if !bytes.Equal(src, dst) {
+			t.Fatalf("input %d: mismatch", i)
+		}
+		if len(src) > 1000 && bytes.Count(src, src[:3]) > 100 && len(cmp) > len(src)/2 {
+			t.Errorf("input %d: compressed %d bytes to %d bytes", i, len(src), len(cmp))
+		}
+		// the destination size must match
+		if len(src) > 0 {
+			if err := dec.Decompress(cmp[len(prefix):], make([]byte, len(src)-1)); err == nil {
+				t.Errorf("input %d: expected an error with a short buffer", i)
+			}
+			if err := dec.Decompress(cmp[len(prefix):], make([]byte, len(src)+1)); err == nil {
+				t.Errorf("input %d: expected an error with a long buffer", i)
+			}
+		}
+	}
+}
+
+func TestLZ4Block(t *testing.T) {
+	// a hand-encoded block: literals "abc",
+	// then a match of 9 bytes at offset 3,
+	// then the literals "defgh"
+	block := []byte{
+		0x35, 'a', 'b', 'c', 3, 0,
+		0x50, 'd', 'e', 'f', 'g', 'h',
+	}
+	want := "abcabcabcabcdefgh"
+	dst := make([]byte, len(want))
+	if err := (lz4Compressor{}).Decompress(block, dst); err != nil {
+		t.Fatal(err)
+	}
+	if string(dst) != want {
+		t.Fatalf("got %q; want %q", dst, want)
+	}
+
+	// corrupt blocks must not panic
+	for _, b := range [][]byte{
+		{},
+		{0xf0},
+		{0xf0, 255},
+		{0x10},
+		{0x1f, 'a', 0, 0},
+		{0x1f, 'a', 2, 0, 0},
+		{0x10, 'a', 1},
+	} {
+		if err := (lz4Compressor{}).Decompress(b, make([]byte, 20)); err == nil {
+			t.Errorf("%x: expected an error", b)
+		}
+	}
+}
+
+func TestLZ4Overlap(t *testing.T) {
+	src := bytes.Repeat([]byte("foo"), 1000)
+	ctl := append([]byte(nil), src...)
+	// compress into the memory just past the input
+	buf := append(src, make([]byte, 8192)...)
+	cmp := (lz4Compressor{}).Compress(buf[10:len(src)], buf[:10])
+	dst := make([]byte, len(src)-10)
+	if err := (lz4Compressor{}).Decompress(cmp[10:], dst); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dst, ctl[10:]) {
+		t.Fatal("mismatch")
+	}
+}
+
+func BenchmarkCompress(b *testing.B) {
+	var text []byte
+	rng := rand.New(rand.NewSource(1))
+	for len(text) < 1<<20 {
+		text = fmt.Appendf(text, `{"path": "db/foo/bar/packed-%d.ion.zst", "size": %d}`, rng.Intn(1000), rng.Intn(1<<20))
+	}
+	for _, name := range []string{"lz4", "s2", "zstd", "zstd-better"} {
+		b.Run(name, func(b *testing.B) {
+			comp := Compression(name)
+			var dst []byte
+			b.SetBytes(int64(len(text)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				dst = comp.Compress(text, dst[:0])
+			}
+			b.ReportMetric(float64(len(text))/float64(len(dst)), "ratio")
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package date
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// strptime directives and their equivalent
+// elements of a time.Parse layout
+var strptimeLayout = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'e': "_2",
+	'j': "002",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'f': "000000",
+	'p': "PM",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'a': "Mon",
+	'A': "Monday",
+	'z': "-0700",
+	'Z': "MST",
+	'F': "2006-01-02",
+	'T': "15:04:05",
+	'%': "%",
+}
+
+// Go layout elements that must not
+// appear in the literal text of a strptime format
+var layoutElements = []string{
+	"Jan", "Mon", "MST", "PM", "pm", "Z07", "_2",
+}
+
+// Layout returns the time.Parse and Time.Format
+// layout corresponding to format, which is either
+// a layout already or, if it contains a '%',
+// a strptime/strftime format using the directives
+// %Y %y %m %d %e %j %H %I %M %S %f %p %b %h %B %a %A %z %Z %F %T and %%.
+func Layout(format string) (string, error) {
+	if !strings.Contains(format, "%") {
+		ref := time.Date(2009, 11, 10, 23, 1, 7, 0, time.UTC)
+		if ref.Format(format) == format {
+			return "", fmt.Errorf("format %q does not contain any layout elements", format)
+		}
+		return format, nil
+	}
+	var out strings.Builder
+	literal := func(s string) error {
+		for _, elem := range layoutElements {
+			if strings.Contains(s, elem) {
+				return fmt.Errorf("format %q: literal text %q is ambiguous", format, s)
+			}
+		}
+		if strings.ContainsAny(s, "0123456789") {
+			return fmt.Errorf("format %q: literal text %q contains digits", format, s)
+		}
+		out.WriteString(s)
+		return nil
+	}
+	for format != "" {
+		i := strings.IndexByte(format, '%')
+		if i < 0 {
+			i = len(format)
+		}
+		if err := literal(format[:i]); err != nil {
+			return "", err
+		}
+		format = format[i:]
+		if format == "" {
+			break
+		}
+		if len(format) < 2 {
+			return "", fmt.Errorf("format ends with a bare '%%'")
+		}
+		elem, ok := strptimeLayout[format[1]]
+		if !ok {
+			return "", fmt.Errorf("unsupported format directive %%%c", format[1])
+		}
+		out.WriteString(elem)
+		format = format[2:]
+	}
+	return out.String(), nil
+}
+
+// ParseLayout parses data according to a layout
+// produced by Layout. Times without a timezone or
+// offset are assumed to be in UTC.
+func ParseLayout(layout string, data []byte) (Time, bool) {
+	t, err := time.Parse(layout, string(data))
+	if err != nil {
+		return Time{}, false
+	}
+	return FromTime(t), true
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package date
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLayout(t *testing.T) {
+	tcs := []struct {
+		format, layout string
+	}{
+		{time.RFC3339, time.RFC3339},
+		{"02/01/2006", "02/01/2006"},
+		{"%Y-%m-%d %H:%M:%S", "2006-01-02 15:04:05"},
+		{"%FT%T.%f%z", "2006-01-02T15:04:05.000000-0700"},
+		{"%d %b %Y %I:%M %p", "02 Jan 2006 03:04 PM"},
+		{"%%%Y", "%2006"},
+	}
+	for i := range tcs {
+		layout, err := Layout(tcs[i].format)
+		if err != nil {
+			t.Errorf("Layout(%q): %s", tcs[i].format, err)
+		} else if layout != tcs[i].layout {
+			t.Errorf("Layout(%q) = %q, want %q", tcs[i].format, layout, tcs[i].layout)
+		}
+	}
+	bad := []string{
+		"yyyy-mm-dd",
+		"%Y-%m-%d %",
+		"%Y-%q",
+		"%Y day 1",
+		"%H:%M Mon",
+	}
+	for _, format := range bad {
+		if layout, err := Layout(format); err == nil {
+			t.Errorf("Layout(%q) = %q, expected an error", format, layout)
+		}
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	tcs := []struct {
+		format, input string
+		want          Time
+	}{
+		{time.RFC3339, "2023-04-05T06:07:08Z", Date(2023, 4, 5, 6, 7, 8, 0)},
+		{time.RFC3339, "2023-04-05T06:07:08+02:00", Date(2023, 4, 5, 4, 7, 8, 0)},
+		{"%Y-%m-%d", "2021-12-31", Date(2021, 12, 31, 0, 0, 0, 0)},
+		{"%d/%m/%Y %H:%M:%S.%f", "01/02/2020 10:11:12.000500", Date(2020, 2, 1, 10, 11, 12, 500000)},
+		{"%Y-%m-%d %H:%M %z", "2020-01-01 00:30 +0100", Date(2019, 12, 31, 23, 30, 0, 0)},
+	}
+	for i := range tcs {
+		layout, err := Layout(tcs[i].format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := ParseLayout(layout, []byte(tcs[i].input))
+		if !ok {
+			t.Errorf("case %d: couldn't parse %q", i, tcs[i].input)
+		} else if !got.Equal(tcs[i].want) {
+			t.Errorf("case %d: got %s, want %s", i, got, tcs[i].want)
+		}
+	}
+	layout, _ := Layout("%Y-%m-%d")
+	if _, ok := ParseLayout(layout, []byte("2021-13-01")); ok {
+		t.Error("parsed an invalid month")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	ts := Date(2023, 4, 5, 6, 7, 8, 900000000)
+	tcs := []struct {
+		format, want string
+	}{
+		{time.RFC3339Nano, "2023-04-05T06:07:08.9Z"},
+		{"%Y/%m/%d %H:%M:%S", "2023/04/05 06:07:08"},
+		{"%a, %d %b %y %I:%M %p", "Wed, 05 Apr 23 06:07 AM"},
+		{"%FT%T.%f%z", "2023-04-05T06:07:08.900000+0000"},
+	}
+	for i := range tcs {
+		layout, err := Layout(tcs[i].format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ts.Format(layout); got != tcs[i].want {
+			t.Errorf("case %d: got %q, want %q", i, got, tcs[i].want)
+		}
+		if got := string(ts.AppendFormat([]byte("x"), layout)); got != "x"+tcs[i].want {
+			t.Errorf("case %d: AppendFormat: got %q", i, got)
+		}
+	}
+}
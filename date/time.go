@@ -230,6 +230,20 @@ func (t Time) AppendRFC3339Nano(b []byte) []byte {
 
 }
 
+// Format returns t in UTC formatted according to
+// layout, which uses the same syntax as time.Time.Format;
+// see Layout for converting strftime-style formats.
+func (t Time) Format(layout string) string {
+	return t.Time().Format(layout)
+}
+
+// AppendFormat is like Format but appends
+// the formatted time to b and returns the
+// extended buffer.
+func (t Time) AppendFormat(b []byte, layout string) []byte {
+	return t.Time().AppendFormat(b, layout)
+}
+
 // Add adds d to t.
 func (t Time) Add(d time.Duration) Time {
 	return FromTime(t.Time().Add(d))
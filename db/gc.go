@@ -296,6 +296,9 @@ func (c *GCConfig) preciseGC(rfs RemoveFS, idx *blockfmt.Index) bool {
 	if len(idx.ToDelete) == 0 {
 		return false
 	}
+	if br, ok := rfs.(blockfmt.BatchRemover); ok {
+		return c.preciseGCBatch(br, idx)
+	}
 	saved := idx.ToDelete[:0]
 	now := date.Now()
 	var failed chan blockfmt.Quarantined
@@ -333,3 +336,44 @@ func (c *GCConfig) preciseGC(rfs RemoveFS, idx *blockfmt.Index) bool {
 	idx.ToDelete = saved
 	return true
 }
+
+// preciseGCBatch is preciseGC for a filesystem
+// that can remove many files in one operation
+func (c *GCConfig) preciseGCBatch(br blockfmt.BatchRemover, idx *blockfmt.Index) bool {
+	saved := idx.ToDelete[:0]
+	now := date.Now()
+	var expired []blockfmt.Quarantined
+	for i := range idx.ToDelete {
+		if idx.ToDelete[i].Expiry.After(now) {
+			saved = append(saved, idx.ToDelete[i])
+			continue
+		}
+		expired = append(expired, idx.ToDelete[i])
+	}
+	if len(expired) == 0 {
+		return false
+	}
+	paths := make([]string, len(expired))
+	for i := range expired {
+		paths[i] = expired[i].Path
+	}
+	failed, err := br.RemoveAll(paths)
+	if err != nil {
+		c.logf("deleting %d of %d ToDelete items: %s", len(failed), len(paths), err)
+	}
+	if len(failed) > 0 {
+		// implementations may report the failed
+		// paths in canonical form (see s3.BucketFS.RemoveAll)
+		bad := make(map[string]struct{}, len(failed))
+		for _, p := range failed {
+			bad[path.Clean(p)] = struct{}{}
+		}
+		for i := range expired {
+			if _, ok := bad[path.Clean(expired[i].Path)]; ok {
+				saved = append(saved, expired[i])
+			}
+		}
+	}
+	idx.ToDelete = saved
+	return true
+}
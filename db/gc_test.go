@@ -18,9 +18,13 @@ import (
 	"errors"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/SnellerInc/sneller/date"
 	"github.com/SnellerInc/sneller/ion/blockfmt"
 )
 
@@ -153,3 +157,79 @@ func TestGC(t *testing.T) {
 		}
 	}
 }
+
+// batchRemoveFS counts calls to RemoveAll
+// and reports failed paths in canonical form
+// like s3.BucketFS does
+type batchRemoveFS struct {
+	*DirFS
+	calls int
+}
+
+func (b *batchRemoveFS) RemoveAll(paths []string) ([]string, error) {
+	b.calls++
+	failed, err := b.DirFS.RemoveAll(paths)
+	for i := range failed {
+		failed[i] = path.Clean(failed[i])
+	}
+	return failed, err
+}
+
+func TestPreciseGCBatch(t *testing.T) {
+	tmpdir := t.TempDir()
+	dfs := NewDirFS(tmpdir)
+	defer dfs.Close()
+	for _, x := range []string{
+		"db/default/t/packed-0.ion.zst",
+		"db/default/t/packed-1.ion.zst",
+		// makes packed-2.ion.zst a non-empty directory,
+		// so removing it fails
+		"db/default/t/packed-2.ion.zst/x",
+		"db/default/t/packed-4.ion.zst/x",
+	} {
+		_, err := dfs.WriteFile(x, []byte{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := date.Now().Add(-time.Minute)
+	future := date.Now().Add(time.Hour)
+	idx := &blockfmt.Index{
+		ToDelete: []blockfmt.Quarantined{
+			{Path: "db/default/t/packed-0.ion.zst", Expiry: past},
+			{Path: "db/default/t/packed-1.ion.zst", Expiry: future},
+			{Path: "db/default/t/packed-2.ion.zst", Expiry: past},
+			{Path: "db/default/t/packed-3.ion.zst", Expiry: past},
+			// a non-canonical path whose removal fails
+			{Path: "db/default/./t//packed-4.ion.zst", Expiry: past},
+		},
+	}
+	rfs := &batchRemoveFS{DirFS: dfs}
+	conf := GCConfig{Logf: t.Logf}
+	if !conf.preciseGC(rfs, idx) {
+		t.Fatal("expected preciseGC to remove something")
+	}
+	if rfs.calls != 1 {
+		t.Errorf("RemoveAll called %d times", rfs.calls)
+	}
+	// the unexpired and failed entries remain;
+	// the missing file counts as removed
+	var got []string
+	for i := range idx.ToDelete {
+		got = append(got, idx.ToDelete[i].Path)
+	}
+	want := []string{
+		"db/default/t/packed-1.ion.zst",
+		"db/default/t/packed-2.ion.zst",
+		"db/default/./t//packed-4.ion.zst",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got ToDelete %v, want %v", got, want)
+	}
+	if _, err := fs.Stat(dfs, "db/default/t/packed-0.ion.zst"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("packed-0: expected ErrNotExist; got %v", err)
+	}
+	if _, err := fs.Stat(dfs, "db/default/t/packed-1.ion.zst"); err != nil {
+		t.Errorf("packed-1: %v", err)
+	}
+}
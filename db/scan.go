@@ -28,7 +28,10 @@ import (
 	"github.com/SnellerInc/sneller/ion/blockfmt"
 )
 
-var errStop = errors.New("stop walking")
+var (
+	errStop       = errors.New("stop walking")
+	errCheckpoint = errors.New("checkpoint")
+)
 
 // Scan performs an incremental append operation
 // on a table by listing input objects and adding them
@@ -109,6 +112,7 @@ func (st *tableState) scan(idx *blockfmt.Index, flushOnComplete bool) (int, erro
 
 	total := 0
 	size := int64(0)
+	batched := 0
 	complete := true
 	ids := make(map[string]int)
 	nextID := idx.Objects()
@@ -199,6 +203,7 @@ func (st *tableState) scan(idx *blockfmt.Index, flushOnComplete bool) (int, erro
 			}
 			ids[string(pname)] = id
 			total++
+			batched++
 			size += info.Size()
 			part, err := c.add(fullpat, blockfmt.Input{
 				Path: full,
@@ -217,6 +222,9 @@ func (st *tableState) scan(idx *blockfmt.Index, flushOnComplete bool) (int, erro
 			if total >= maxInputs || size >= maxSize || time.Since(start) >= maxDuration {
 				return errStop
 			}
+			if st.conf.ScanCheckpointObjects > 0 && batched >= st.conf.ScanCheckpointObjects {
+				return errCheckpoint
+			}
 			return nil
 		}
 		pat, err = fsutil.ToGlob(pat)
@@ -227,8 +235,20 @@ func (st *tableState) scan(idx *blockfmt.Index, flushOnComplete bool) (int, erro
 			st.conf.logf("fixing bad cursor %q", seek)
 			seek = strings.TrimSuffix(seek, "/")
 		}
-		err = fsutil.WalkGlob(walkfs, seek, pat, walk)
-		idx.Cursors[i] = seek
+		for {
+			err = fsutil.WalkGlob(walkfs, seek, pat, walk)
+			idx.Cursors[i] = seek
+			if err != errCheckpoint {
+				break
+			}
+			err = st.checkpoint(context.Background(), idx, c.parts, i, seek)
+			if err != nil {
+				return 0, err
+			}
+			c.init(st.def.Partitions)
+			clear(ids)
+			batched = 0
+		}
 		if err == errStop || errors.Is(err, context.DeadlineExceeded) {
 			if pe, ok := err.(*fs.PathError); ok {
 				// we aborted early and know the path we
@@ -243,13 +263,17 @@ func (st *tableState) scan(idx *blockfmt.Index, flushOnComplete bool) (int, erro
 		}
 	}
 	idx.Scanning = !complete
-	if total == 0 {
-		// either we are complete
-		// or we updated the seek position
-		if flushOnComplete {
-			return 0, st.flush(context.Background(), idx)
+	if batched == 0 {
+		// either we are complete, we updated
+		// the seek position, or every new input
+		// has already been checkpointed
+		if flushOnComplete || total > 0 {
+			err = st.flush(context.Background(), idx)
+			if err != nil {
+				return 0, err
+			}
 		}
-		return 0, nil
+		return total, nil
 	}
 	for i := range c.parts {
 		if c.parts[i].prepend >= 0 {
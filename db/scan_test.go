@@ -21,6 +21,7 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"testing"
 	"time"
@@ -495,3 +496,107 @@ func TestScanFail(t *testing.T) {
 	fullScan(t, &c, owner, "default", "files", good0+good1)
 	noScan(t, &c, owner, "default", "files")
 }
+
+// crashFS is an OutputFS that refuses to create
+// new objects once an index has been written
+// while crash is set
+type crashFS struct {
+	OutputFS
+	crash   bool
+	crashed bool
+	indexes int
+}
+
+func (c *crashFS) WriteFile(p string, buf []byte) (string, error) {
+	etag, err := c.OutputFS.WriteFile(p, buf)
+	if err == nil && path.Base(p) == "index" {
+		c.indexes++
+		c.crashed = c.crash
+	}
+	return etag, err
+}
+
+func (c *crashFS) Create(p string) (blockfmt.Uploader, error) {
+	if c.crashed {
+		return nil, fmt.Errorf("simulated crash creating %q", p)
+	}
+	return c.OutputFS.Create(p)
+}
+
+func TestScanCheckpoint(t *testing.T) {
+	checkFiles(t)
+	dfs := newDirFS(t, t.TempDir())
+	err := WriteDefinition(dfs, "default", "files", &Definition{
+		Inputs: []Input{
+			{Pattern: "file://b-prefix/*.json"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const objects = 7
+	for i := 0; i < objects; i++ {
+		name := fmt.Sprintf("b-prefix/file%d.json", i)
+		value := fmt.Sprintf(`{"filenum": %d}`, i)
+		_, err := dfs.WriteFile(name, []byte(value))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// crash after the first checkpoint
+	// has been written out
+	cfs := &crashFS{OutputFS: dfs, crash: true}
+	owner := newTenant(cfs)
+	c := Config{
+		Align:                 1024,
+		Logf:                  t.Logf,
+		ScanCheckpointObjects: 2,
+	}
+	_, err = c.Scan(owner, "default", "files")
+	if err == nil {
+		t.Fatal("expected the scan to fail")
+	}
+	if cfs.indexes != 1 {
+		t.Fatalf("%d indexes written before the crash", cfs.indexes)
+	}
+	idx, err := OpenIndex(dfs, "default", "files", owner.Key())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !idx.Scanning {
+		t.Fatal("checkpointed index is not scanning")
+	}
+	if want := "b-prefix/file1.json"; idx.Cursors[0] != want {
+		t.Fatalf("checkpointed cursor %q; want %q", idx.Cursors[0], want)
+	}
+	for i := 0; i < objects; i++ {
+		name := fmt.Sprintf("file://b-prefix/file%d.json", i)
+		if got := contains(t, idx, name); got != (i < 2) {
+			t.Errorf("checkpointed index contains %s: %v", name, got)
+		}
+	}
+	if n := idx.Indirect.OrigObjects(); n != 1 {
+		t.Errorf("%d checkpointed packfiles; want 1", n)
+	}
+
+	// the resumed scan should only
+	// ingest the remaining objects
+	cfs.crash, cfs.crashed = false, false
+	fullScan(t, &c, owner, "default", "files", objects-2)
+	noScan(t, &c, owner, "default", "files")
+	idx, err = OpenIndex(dfs, "default", "files", owner.Key())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < objects; i++ {
+		name := fmt.Sprintf("file://b-prefix/file%d.json", i)
+		if !contains(t, idx, name) {
+			t.Errorf("index does not contain %s", name)
+		}
+	}
+	rows, ok := idx.Rows()
+	if !ok || rows != objects {
+		t.Errorf("index has %d rows (ok=%v); want %d", rows, ok, objects)
+	}
+}
@@ -129,6 +129,18 @@ type Config struct {
 	// to spend listing objects before deciding
 	// to bail out of a scan.
 	MaxScanTime time.Duration
+	// ScanCheckpointObjects, if positive, is the
+	// number of new objects after which Scan converts
+	// the objects listed so far, appends the resulting
+	// packfiles to the indirect tree of the index,
+	// and writes out the index along with the listing
+	// cursor. An interrupted Scan then resumes listing
+	// after the last checkpoint rather than
+	// re-ingesting every object it had listed.
+	// If ScanCheckpointObjects is less than or equal
+	// to zero, Scan writes out the index only once
+	// it has finished listing.
+	ScanCheckpointObjects int
 
 	// NewIndexScan, if true, enables scanning
 	// for newly-created index objects.
@@ -198,14 +210,12 @@ func (c *Config) targetMerge() int {
 // Otherwise, Format returns nil.
 func (c *Config) Format(chosen, name string, hints []byte) (blockfmt.RowFormat, error) {
 	if chosen != "" {
-		if f := blockfmt.SuffixToFormat["."+chosen]; f != nil {
+		if f := blockfmt.LookupFormat("." + chosen); f != nil {
 			return f(hints)
 		}
 	}
-	for suff, f := range blockfmt.SuffixToFormat {
-		if strings.HasSuffix(name, suff) {
-			return f(hints)
-		}
+	if f := blockfmt.FormatForName(name); f != nil {
+		return f(hints)
 	}
 	if c.Fallback != nil {
 		return c.Fallback(name), nil
@@ -880,16 +890,11 @@ func (st *tableState) flush(ctx context.Context, idx *blockfmt.Index) (err error
 		}
 	}()
 
-	idx.Name = st.table
-	idx.UserData = st.addDefHash(idx.UserData)
-	idx.Inputs.Backing = st.ofs
-	dir := path.Join("db", st.db, st.table)
-	trace.WithRegion(ctx, "flush-inputs", func() {
-		err = idx.SyncInputs(dir, st.conf.inputMinAge())
-	})
+	err = st.syncInputs(ctx, idx)
 	if err != nil {
 		return err
 	}
+	dir := path.Join("db", st.db, st.table)
 	c := blockfmt.IndexConfig{
 		MaxInlined:    st.conf.maxInlineBytes(),
 		TargetSize:    int64(st.conf.targetMerge()),
@@ -906,6 +911,66 @@ func (st *tableState) flush(ctx context.Context, idx *blockfmt.Index) (err error
 	return err
 }
 
+// syncInputs prepares idx to be written out
+// and synchronizes idx.Inputs to the table directory
+func (st *tableState) syncInputs(ctx context.Context, idx *blockfmt.Index) (err error) {
+	idx.Name = st.table
+	idx.UserData = st.addDefHash(idx.UserData)
+	idx.Inputs.Backing = st.ofs
+	dir := path.Join("db", st.db, st.table)
+	trace.WithRegion(ctx, "flush-inputs", func() {
+		err = idx.SyncInputs(dir, st.conf.inputMinAge())
+	})
+	return err
+}
+
+// checkpoint converts parts and appends the
+// resulting packfiles to the indirect tree of idx,
+// then writes out idx with the cursor for input i
+// set to last. The inputs in parts must be exactly
+// the inputs that have been added to idx.Inputs
+// since the previous checkpoint.
+func (st *tableState) checkpoint(ctx context.Context, idx *blockfmt.Index, parts []partition, i int, last string) (err error) {
+	defer func() {
+		if err != nil {
+			st.invalidate()
+		}
+	}()
+	for j := range parts {
+		if parts[j].prepend >= 0 {
+			st.deleteInline(idx, parts[j].prepend)
+		}
+	}
+	descs, err := st.convert(ctx, idx, parts)
+	if err != nil {
+		return err
+	}
+	lst := make([]blockfmt.Ingested, len(descs))
+	for j := range descs {
+		lst[j] = blockfmt.Ingested{Descriptor: descs[j], Input: last}
+	}
+	idx.Algo = "zstd"
+	idx.Created = date.Now().Truncate(time.Microsecond)
+	c := blockfmt.IndexConfig{
+		TargetRefSize: st.conf.TargetRefSize,
+		Expiry:        st.conf.GCMinimumAge,
+		Checkpoint: func(idx *blockfmt.Index, last string) error {
+			idx.Cursors[i] = last
+			err := st.syncInputs(ctx, idx)
+			if err != nil {
+				return err
+			}
+			return st.writeIndex(idx)
+		},
+	}
+	if len(lst) == 0 {
+		// every partition was prepended
+		// to an existing inline object
+		return c.Checkpoint(idx, last)
+	}
+	return c.Append(idx, st.ofs, path.Join("db", st.db, st.table), lst, "")
+}
+
 func suffixForComp(c string) string {
 	if c == "zstd" {
 		return ".ion.zst"
@@ -934,6 +999,30 @@ func (e *errUpdateFailed) Unwrap() error {
 }
 
 func (st *tableState) force(ctx context.Context, idx *blockfmt.Index, parts []partition) error {
+	extra, err := st.convert(ctx, idx, parts)
+	if err != nil {
+		return err
+	}
+	if idx == nil {
+		idx = new(blockfmt.Index)
+		for i := range parts {
+			for j := range parts[i].lst {
+				idx.Inputs.Append(parts[i].lst[j].Path, parts[i].lst[j].ETag, 1)
+			}
+		}
+	}
+	idx.Algo = "zstd"
+	idx.Created = date.Now().Truncate(time.Microsecond)
+	idx.Inline = append(idx.Inline, extra...)
+	return st.flush(ctx, idx)
+}
+
+// convert converts each of parts into a packfile.
+// Partitions that are prepended to an existing
+// inline object replace that object in idx.Inline;
+// the descriptors for the remaining partitions
+// are returned in order.
+func (st *tableState) convert(ctx context.Context, idx *blockfmt.Index, parts []partition) ([]blockfmt.Descriptor, error) {
 	extra := make([]blockfmt.Descriptor, 0, len(parts))
 	errs := make([]error, len(parts))
 	var wg sync.WaitGroup
@@ -956,25 +1045,14 @@ func (st *tableState) force(ctx context.Context, idx *blockfmt.Index, parts []pa
 	var ferr *errUpdateFailed
 	for i := range errs {
 		if errs[i] != nil && !errors.As(errs[i], &ferr) {
-			return errs[i]
+			return nil, errs[i]
 		}
 	}
 	if ferr != nil {
 		st.updateFailed(ctx, idx == nil, parts)
-		return ferr
-	}
-	if idx == nil {
-		idx = new(blockfmt.Index)
-		for i := range parts {
-			for j := range parts[i].lst {
-				idx.Inputs.Append(parts[i].lst[j].Path, parts[i].lst[j].ETag, 1)
-			}
-		}
+		return nil, ferr
 	}
-	idx.Algo = "zstd"
-	idx.Created = date.Now().Truncate(time.Microsecond)
-	idx.Inline = append(idx.Inline, extra...)
-	return st.flush(ctx, idx)
+	return extra, nil
 }
 
 func (st *tableState) forcePart(ctx context.Context, prepend, dst *blockfmt.Descriptor, part *partition) error {
@@ -1004,6 +1082,11 @@ func (st *tableState) forcePart(ctx context.Context, prepend, dst *blockfmt.Desc
 	fp := path.Join("db", st.db, st.table, part.name, name)
 	out, err := st.ofs.Create(fp)
 	if err != nil {
+		// the converter never ran,
+		// so it didn't close the inputs
+		for i := range part.lst {
+			part.lst[i].R.Close()
+		}
 		return err
 	}
 	c.Output = out
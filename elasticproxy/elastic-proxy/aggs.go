@@ -116,6 +116,12 @@ type bucketAggregation interface {
 	process(c *aggsProcessContext) (any, error)
 }
 
+// keyFieldsAggregation is implemented by the bucket
+// aggregations that group on document fields
+type keyFieldsAggregation interface {
+	keyFields() []string
+}
+
 type pipelineAggregation interface {
 	process(aggName string, data any) error
 }
@@ -173,87 +173,14 @@ func (c *aggsGenerateContext) transform() ([]projectAliasExpr, error) {
 
 	where := c.query
 	if c.nestingLevel > 1 {
-		parentGroups := c.parent.allGroupExprs()
-
-		// generate SELECT of the parent nodes
-		const SelectionSource = "$selection"
-		parentBucket := fmt.Sprintf("%s:%s%%%d", BucketPrefix, c.parent.bucket, 0)
-		parentSelect := exprSelect{
-			Context: c.context,
-			From:    []expression{ParseExprSourceNameWithAlias(c.context, parentBucket, SelectionSource)},
-		}
-		for _, pg := range parentGroups {
-			parentSelect.Projection = append(parentSelect.Projection, projectAliasExpr{
-				Context: c.context,
-				expression: &exprFieldName{
-					Context: c.context,
-					Source:  SelectionSource,
-					Fields:  []string{pg.Alias},
-				},
-			})
-		}
-
-		var sourceExpr expression
-		if len(parentGroups) > 1 {
-			// Use the { '$key1': .., '$key2': .. } IN PARENT format
-			sourceFields := make([]exprObjectField, len(parentGroups))
-			selectFields := make([]exprObjectField, len(parentGroups))
-			for i, pg := range parentGroups {
-				sourceFields[i] = exprObjectField{
-					Name: pg.Alias,
-					Expr: pg.expression,
-				}
-				selectFields[i] = exprObjectField{
-					Name: pg.Alias,
-					Expr: &exprFieldName{
-						Context: c.context,
-						Source:  SelectionSource,
-						Fields:  []string{pg.Alias},
-					},
-				}
-			}
-			sourceExpr = &exprObject{
-				Context: c.context,
-				Fields:  sourceFields,
-			}
-			parentSelect.Projection = []projectAliasExpr{
-				{
-					Context: c.context,
-					expression: &exprObject{
-						Context: c.context,
-						Fields:  selectFields,
-					},
-				},
-			}
-		} else {
-			// Use the "$key1" IN PARENT format
-			parentSelect.Projection = []projectAliasExpr{
-				{
-					Context: c.context,
-					expression: &exprFieldName{
-						Context: c.context,
-						Source:  SelectionSource,
-						Fields:  []string{parentGroups[0].Alias},
-					},
-				},
-			}
-			sourceExpr = parentGroups[0].expression
-		}
-
-		inExpr := exprOperator2{
-			Context:  c.context,
-			Operator: "IN",
-			Expr1:    sourceExpr,
-			Expr2:    &parentSelect,
-		}
-
+		inExpr := c.inParentBuckets()
 		if where == nil {
-			where = &inExpr
+			where = inExpr
 		} else {
 			where = &exprOperator2{
 				Context:  c.context,
 				Operator: "AND",
-				Expr1:    &inExpr,
+				Expr1:    inExpr,
 				Expr2:    where,
 			}
 		}
@@ -341,3 +268,81 @@ func (c *aggsGenerateContext) transform() ([]projectAliasExpr, error) {
 
 	return queries, nil
 }
+
+// inParentBuckets returns the predicate that only selects
+// the rows that belong to the buckets of the parent aggregation
+func (c *aggsGenerateContext) inParentBuckets() expression {
+	parentGroups := c.parent.allGroupExprs()
+
+	// generate SELECT of the parent nodes
+	const SelectionSource = "$selection"
+	parentBucket := fmt.Sprintf("%s:%s%%%d", BucketPrefix, c.parent.bucket, 0)
+	parentSelect := exprSelect{
+		Context: c.context,
+		From:    []expression{ParseExprSourceNameWithAlias(c.context, parentBucket, SelectionSource)},
+	}
+	for _, pg := range parentGroups {
+		parentSelect.Projection = append(parentSelect.Projection, projectAliasExpr{
+			Context: c.context,
+			expression: &exprFieldName{
+				Context: c.context,
+				Source:  SelectionSource,
+				Fields:  []string{pg.Alias},
+			},
+		})
+	}
+
+	var sourceExpr expression
+	if len(parentGroups) > 1 {
+		// Use the { '$key1': .., '$key2': .. } IN PARENT format
+		sourceFields := make([]exprObjectField, len(parentGroups))
+		selectFields := make([]exprObjectField, len(parentGroups))
+		for i, pg := range parentGroups {
+			sourceFields[i] = exprObjectField{
+				Name: pg.Alias,
+				Expr: pg.expression,
+			}
+			selectFields[i] = exprObjectField{
+				Name: pg.Alias,
+				Expr: &exprFieldName{
+					Context: c.context,
+					Source:  SelectionSource,
+					Fields:  []string{pg.Alias},
+				},
+			}
+		}
+		sourceExpr = &exprObject{
+			Context: c.context,
+			Fields:  sourceFields,
+		}
+		parentSelect.Projection = []projectAliasExpr{
+			{
+				Context: c.context,
+				expression: &exprObject{
+					Context: c.context,
+					Fields:  selectFields,
+				},
+			},
+		}
+	} else {
+		// Use the "$key1" IN PARENT format
+		parentSelect.Projection = []projectAliasExpr{
+			{
+				Context: c.context,
+				expression: &exprFieldName{
+					Context: c.context,
+					Source:  SelectionSource,
+					Fields:  []string{parentGroups[0].Alias},
+				},
+			},
+		}
+		sourceExpr = parentGroups[0].expression
+	}
+
+	return &exprOperator2{
+		Context:  c.context,
+		Operator: "IN",
+		Expr1:    sourceExpr,
+		Expr2:    &parentSelect,
+	}
+}
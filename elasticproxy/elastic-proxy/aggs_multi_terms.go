@@ -25,6 +25,14 @@ type aggMultiTermField struct {
 	MissingValue *string `json:"missing"` // TODO
 }
 
+func (f *aggsMultiTerms) keyFields() []string {
+	fields := make([]string, len(f.Terms))
+	for i, mt := range f.Terms {
+		fields[i] = mt.Field
+	}
+	return fields
+}
+
 func (f *aggsMultiTerms) transform(c *aggsGenerateContext) ([]projectAliasExpr, error) {
 	var keyExprs []*exprFieldName
 	for _, mt := range f.Terms {
@@ -26,6 +26,10 @@ type aggsTerms struct {
 	MissingValue          *string `json:"missing"` // TODO
 }
 
+func (f *aggsTerms) keyFields() []string {
+	return []string{f.Field}
+}
+
 func (f *aggsTerms) transform(c *aggsGenerateContext) ([]projectAliasExpr, error) {
 	keyExpr := ParseExprFieldName(c.context, f.Field)
 	c.addGroupExpr(keyExpr)
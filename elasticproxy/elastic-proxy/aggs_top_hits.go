@@ -15,11 +15,37 @@
 package elastic_proxy
 
 import (
-	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// TopHitsSuffix is the suffix of the bucket
+// that holds the documents of a top_hits aggregation.
+const TopHitsSuffix = "%hits"
+
+// sortAliasPrefix is the prefix of the aliases
+// of the sort values of a top_hits aggregation
+const sortAliasPrefix = "$sort%"
+
+// maxBucketHits is the maximum number of hits of
+// a top_hits aggregation inside a bucket aggregation
+// (for all buckets), since the result of a sub-query
+// needs to be smaller than groupByLimit
+var maxBucketHits = groupByLimit - 1
+
 // see https://github.com/SnellerInc/elastic-proxy/issues/25
+//
+// The top_hits aggregation selects the first documents
+// of each bucket with the given sort order. Within a
+// bucket aggregation, the documents are restricted to the
+// buckets of the parent aggregation and to the first
+// (distinct) sort values of each bucket, and they are
+// assigned to their buckets when the result is processed.
+// Without _source.includes the keys of the buckets are
+// taken from the documents, so the parent aggregations
+// need to group on document fields.
 type aggsTopHits struct {
 	Sort   []SortField `json:"sort"`
 	Source struct {
@@ -28,70 +54,314 @@ type aggsTopHits struct {
 	Size *int `json:"size"`
 }
 
-func (f *aggsTopHits) transform(c *aggsGenerateContext) ([]projectAliasExpr, error) {
-	effectiveSize := 3
+func (f *aggsTopHits) size() int {
 	if f.Size != nil {
-		effectiveSize = *f.Size
+		return *f.Size
 	}
-	if effectiveSize == 0 {
-		return nil, nil
+	return 3
+}
+
+func (f *aggsTopHits) includes(field string) bool {
+	for _, incl := range f.Source.Includes {
+		if incl == field {
+			return true
+		}
 	}
+	return false
+}
 
-	var projection []projectAliasExpr
+func (f *aggsTopHits) transform(c *aggsGenerateContext) ([]projectAliasExpr, error) {
+	size := f.size()
+	if size <= 0 {
+		return nil, nil
+	}
 
-	// include the group-by keys to allow moving the hits to the proper groups later
-	projection = append(projection, c.groupExprs...)
+	parentGroups := c.allGroupExprs()
+	wholeDocs := len(f.Source.Includes) == 0
+	if wholeDocs {
+		// '*' cannot be combined with the group-by keys
+		for _, pg := range parentGroups {
+			if e, ok := pg.expression.(*exprFieldName); !ok || e.Source != "" {
+				return nil, fmt.Errorf("'top_hits' aggregation %q requires '_source.includes' inside a bucket aggregation that doesn't group on a field", c.bucket)
+			}
+		}
+	}
 
-	// include the fields (or all data if no fields are specified)
-	includes := f.Source.Includes
-	if len(includes) > 0 {
-		for _, incl := range includes {
+	var projection []projectAliasExpr
+	if wholeDocs {
+		projection = append(projection, projectAliasExpr{
+			Context:    c.context,
+			expression: &exprFieldName{Context: c.context},
+		})
+	} else {
+		// include the group-by keys to allow moving the hits to the proper groups later
+		projection = append(projection, parentGroups...)
+		for _, incl := range f.Source.Includes {
 			projection = append(projection, projectAliasExpr{
 				Context:    c.context,
+				Alias:      incl,
 				expression: ParseExprFieldName(c.context, incl),
 			})
 		}
-	} else {
-		projection = append(projection, projectAliasExpr{
+	}
+
+	var orderBy []orderByExpr
+	var sortExprs []expression
+	for i, sortField := range f.Sort {
+		e := ParseExprFieldName(c.context, sortField.Field)
+		orderBy = append(orderBy, orderByExpr{
 			Context:    c.context,
-			expression: &exprFieldName{Context: c.context},
+			expression: e,
+			Order:      sortField.Order,
 		})
+		sortExprs = append(sortExprs, e)
+		if !wholeDocs && !f.includes(sortField.Field) {
+			projection = append(projection, projectAliasExpr{
+				Context:    c.context,
+				Alias:      fmt.Sprintf("%s%d", sortAliasPrefix, i),
+				expression: e,
+			})
+		}
+	}
+
+	// filters of the enclosing aggregations
+	var queries []expression
+	for p := c; p != nil; p = p.parent {
+		queries = append(queries, p.query)
+	}
+
+	sel := &exprSelect{
+		Context:    c.context,
+		Projection: projection,
+		From:       c.context.Sources,
+		OrderBy:    orderBy,
+		Limit:      size,
+	}
+	if len(parentGroups) > 0 {
+		queries = append(queries, c.inParentBuckets())
+		if len(sortExprs) > 0 {
+			queries = append(queries, f.inTopSortValues(c, parentGroups, sortExprs, orderBy, andExpressions(queries)))
+		}
+		// the hits of all buckets are returned at once
+		// and they are truncated to the size per bucket
+		// when the result is processed
+		sel.Limit = maxBucketHits
+	}
+	sel.Where = andExpressions(queries)
+
+	projectExpr := projectAliasExpr{
+		Context:    c.context,
+		Alias:      fmt.Sprintf("%s:%s%s", BucketPrefix, c.bucket, TopHitsSuffix),
+		expression: sel,
 	}
 
+	return []projectAliasExpr{projectExpr}, nil
+}
+
+// inTopSortValues returns the predicate that only selects
+// the documents that have one of the first (distinct) sort
+// values of their bucket. Window functions can only be used
+// with GROUP BY, so the sort values are ranked separately
+// instead of the documents themselves.
+func (f *aggsTopHits) inTopSortValues(c *aggsGenerateContext, parentGroups []projectAliasExpr, sortExprs []expression, orderBy []orderByExpr, where expression) expression {
+	const rankSource = "$rank"
+	rank := &exprSelect{
+		Context: c.context,
+		From:    c.context.Sources,
+		Where:   where,
+	}
 	rowNumExpr := exprOperatorOver{
 		Context:  c.context,
-		Function: exprFunction{Name: "ROW_NUMBER"},
-	}
-	for _, groupExpr := range c.groupExprs {
-		rowNumExpr.PartitionBy = append(rowNumExpr.PartitionBy, groupExpr.expression)
+		Function: exprFunction{Context: c.context, Name: "ROW_NUMBER"},
+		OrderBy:  orderBy,
 	}
-	for _, sortField := range f.Sort {
-		rowNumExpr.OrderBy = append(rowNumExpr.OrderBy, orderByExpr{
+	var docFields, rankFields []exprObjectField
+	add := func(alias string, e expression) {
+		rank.Projection = append(rank.Projection, projectAliasExpr{
 			Context:    c.context,
-			expression: ParseExprFieldName(c.context, sortField.Field),
-			Order:      sortField.Order,
+			Alias:      alias,
+			expression: e,
+		})
+		rank.GroupBy = append(rank.GroupBy, e)
+		docFields = append(docFields, exprObjectField{Name: alias, Expr: e})
+		rankFields = append(rankFields, exprObjectField{
+			Name: alias,
+			Expr: &exprFieldName{
+				Context: c.context,
+				Source:  rankSource,
+				Fields:  []string{alias},
+			},
 		})
 	}
+	for _, pg := range parentGroups {
+		add(pg.Alias, pg.expression)
+		rowNumExpr.PartitionBy = append(rowNumExpr.PartitionBy, pg.expression)
+	}
+	for i, e := range sortExprs {
+		add(fmt.Sprintf("%s%d", sortAliasPrefix, i), e)
+	}
 
-	rowNumConditionExpr := exprOperator2{
-		Operator: "<",
+	// the window function requires an aggregate,
+	// which is only kept if it is referenced
+	rank.Projection = append(rank.Projection, projectAliasExpr{
+		Context:    c.context,
+		Alias:      DocCount,
+		expression: c.makeCountStar(),
+	})
+	rank.Having = &exprOperator2{
+		Context:  c.context,
+		Operator: "<=",
 		Expr1:    &rowNumExpr,
-		Expr2:    &exprJSONLiteral{Context: c.context, Value: JSONLiteral{effectiveSize}},
+		Expr2:    &exprJSONLiteral{Context: c.context, Value: JSONLiteral{f.size()}},
 	}
 
-	projectExpr := projectAliasExpr{
-		Alias: fmt.Sprintf("%s:%s%%hits", BucketPrefix, c.bucket),
-		expression: &exprSelect{
-			Context:    c.context,
-			Projection: projection,
-			From:       c.context.Sources,
-			Where:      andExpressions([]expression{c.query, &rowNumConditionExpr}),
+	return &exprOperator2{
+		Context:  c.context,
+		Operator: "IN",
+		Expr1:    &exprObject{Context: c.context, Fields: docFields},
+		Expr2: &exprSelect{
+			Context: c.context,
+			Projection: []projectAliasExpr{{
+				Context:    c.context,
+				expression: &exprObject{Context: c.context, Fields: rankFields},
+			}},
+			From: []expression{&projectAliasExpr{
+				Context:    c.context,
+				Alias:      rankSource,
+				expression: rank,
+			}},
+			Where: &exprOperator2{
+				Context:  c.context,
+				Operator: ">",
+				Expr1: &exprFieldName{
+					Context: c.context,
+					Source:  rankSource,
+					Fields:  []string{DocCount},
+				},
+				Expr2: &exprJSONLiteral{Context: c.context, Value: JSONLiteral{0}},
+			},
+			Limit: maxBucketHits,
 		},
 	}
+}
 
-	return []projectAliasExpr{projectExpr}, nil
+func (f *aggsTopHits) process(c *aggsProcessContext) (any, error) {
+	rows, _ := c.data.([]any)
+	if size := f.size(); len(rows) > size {
+		// documents with the same sort values
+		// are all returned by the query
+		rows = rows[:size]
+	}
+	hits := &elasticResultHits{
+		Hits: make([]elasticResultHitRecord, 0, len(rows)),
+		Total: &elasticResultHitsTotal{
+			Relation: "eq",
+			Value:    c.docCount,
+		},
+	}
+	for _, row := range rows {
+		doc, ok := row.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("'top_hits' aggregation %q should contain records", c.bucket)
+		}
+		source := make(map[string]any, len(doc))
+		sortValues := make([]any, len(f.Sort))
+		for k, v := range doc {
+			if strings.HasPrefix(k, KeyPrefix+":") || strings.HasPrefix(k, SourceAliasPrefix) {
+				continue
+			}
+			if n, ok := strings.CutPrefix(k, sortAliasPrefix); ok {
+				if i, err := strconv.Atoi(n); err == nil && i < len(sortValues) {
+					sortValues[i] = v
+				}
+				continue
+			}
+			v, err := formatOut(k, v, c.context.TypeMapping)
+			if err != nil {
+				return nil, err
+			}
+			setPath(source, k, v)
+		}
+		for i, sf := range f.Sort {
+			if _, ok := doc[fmt.Sprintf("%s%d", sortAliasPrefix, i)]; !ok {
+				// the sort value is part of the document
+				sortValues[i] = lookupPath(doc, sf.Field)
+			}
+		}
+		for i, v := range sortValues {
+			// timestamp are written as unix-milli in sort orders
+			if t, ok := v.(time.Time); ok {
+				sortValues[i] = t.UnixMilli()
+			}
+		}
+		hits.Hits = append(hits.Hits, elasticResultHitRecord{
+			Type:   "_doc",
+			Id:     hashItem(source),
+			Index:  c.context.Index,
+			Source: source,
+			Sort:   sortValues,
+		})
+	}
+	return &struct {
+		Hits *elasticResultHits `json:"hits"`
+	}{Hits: hits}, nil
+}
+
+// topHitsKeyPaths returns the (dotted) paths of the
+// document fields that hold the keys of the parent
+// buckets, indexed by the key columns of the buckets
+func topHitsKeyPaths(qc *QueryContext, parents []string) map[string]string {
+	paths := make(map[string]string)
+	aggs := qc.Query.Aggregations
+	for i, name := range parents {
+		agg, ok := aggs[name]
+		if !ok {
+			break
+		}
+		if kf, ok := agg.Aggregation.(keyFieldsAggregation); ok {
+			bucket := strings.Join(parents[:i+1], ":")
+			for j, field := range kf.keyFields() {
+				e := ParseExprFieldName(qc, field)
+				paths[fmt.Sprintf("%s:%s%%%d", KeyPrefix, bucket, j)] = strings.Join(e.Fields, ".")
+			}
+		}
+		aggs = agg.SubAggregations
+	}
+	return paths
+}
+
+// setPath sets the (dotted) path in m to v
+func setPath(m map[string]any, path string, v any) {
+	for {
+		first, rest, ok := strings.Cut(path, ".")
+		if !ok {
+			m[path] = v
+			return
+		}
+		sub, ok := m[first].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			m[first] = sub
+		}
+		m, path = sub, rest
+	}
 }
 
-func (f *aggsTopHits) process(c *aggsProcessContext) (interface{}, error) {
-	return nil, errors.New("'top_hits' not supported")
+// lookupPath returns the value at the
+// (dotted) path in m or nil if it is absent
+func lookupPath(m map[string]any, path string) any {
+	for {
+		if v, ok := m[path]; ok {
+			return v
+		}
+		first, rest, ok := strings.Cut(path, ".")
+		if !ok {
+			return nil
+		}
+		m, ok = m[first].(map[string]any)
+		if !ok {
+			return nil
+		}
+		path = rest
+	}
 }
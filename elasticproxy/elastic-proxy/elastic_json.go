@@ -44,6 +44,7 @@ type ElasticJSON struct {
 	Size           *int                   `json:"size"`
 	Aggregations   map[string]aggregation `json:"aggs"`
 	Sort           []SortField            `json:"sort"`
+	SearchAfter    []any                  `json:"search_after"`
 	Query          *Query                 `json:"query"`
 	Version        *bool                  `json:"version"` // indicates it the version should be included in the hit
 	Source         *source                `json:"_source"` // indicates if source record should be included in the hit
@@ -426,12 +427,17 @@ func (ej *ElasticJSON) SQL(qc *QueryContext) (*exprSelect, error) {
 				})
 			}
 		}
+		searchAfter, err := ej.searchAfter(qc)
+		if err != nil {
+			return nil, err
+		}
 		projectExprs = append(projectExprs, projectAliasExpr{
 			Alias: HitsBucket,
 			expression: &exprSelect{
 				Context:    qc,
 				Projection: []projectAliasExpr{{Context: qc, expression: &exprFieldName{Context: qc}}},
 				From:       fromSources,
+				Where:      searchAfter,
 				Offset:     effectiveOffset,
 				Limit:      effectiveSize,
 				OrderBy:    orderBy,
@@ -473,6 +479,62 @@ func (ej *ElasticJSON) SQL(qc *QueryContext) (*exprSelect, error) {
 	}, nil
 }
 
+// searchAfter returns the predicate that only selects
+// the hits that follow the search_after cursor in the
+// sort order. The cursor holds the sort values of the
+// last hit of the previous page, so the last sort field
+// acts as the tiebreaker and should be unique to avoid
+// skipping hits with identical sort values.
+func (ej *ElasticJSON) searchAfter(qc *QueryContext) (expression, error) {
+	if len(ej.SearchAfter) == 0 {
+		return nil, nil
+	}
+	if len(ej.SearchAfter) != len(ej.Sort) {
+		return nil, fmt.Errorf("'search_after' has %d value(s), but there are %d sort field(s)", len(ej.SearchAfter), len(ej.Sort))
+	}
+	if ej.From != nil && *ej.From > 0 {
+		return nil, errors.New("'from' should be 0 when 'search_after' is used")
+	}
+
+	// (a, b) > (x, y) is translated into a > x OR (a = x AND b > y)
+	var e expression
+	for i := len(ej.Sort) - 1; i >= 0; i-- {
+		sf := ej.Sort[i]
+		value, err := NewJSONLiteral(ej.SearchAfter[i])
+		if err != nil {
+			return nil, fmt.Errorf("'search_after' has invalid value for %q: %w", sf.Field, err)
+		}
+		op := ">"
+		if sf.Order == OrderDescending {
+			op = "<"
+		}
+		cmp := func(op string) expression {
+			return &exprOperator2{
+				Context:  qc,
+				Operator: op,
+				Expr1:    ParseExprFieldName(qc, sf.Field),
+				Expr2:    &exprJSONLiteral{Context: qc, Value: value},
+			}
+		}
+		if e == nil {
+			e = cmp(op)
+			continue
+		}
+		e = &exprOperator2{
+			Context:  qc,
+			Operator: "OR",
+			Expr1:    cmp(op),
+			Expr2: &exprOperator2{
+				Context:  qc,
+				Operator: "AND",
+				Expr1:    cmp("="),
+				Expr2:    e,
+			},
+		}
+	}
+	return e, nil
+}
+
 func (ej *ElasticJSON) ConvertResult(qc *QueryContext, snellerResult map[string]any) (*ElasticResult, map[string]any, error) {
 	totalCountBucket := snellerResult[TotalCountBucket]
 	totalCount := int64(totalCountBucket.(int))
@@ -623,7 +685,7 @@ func (ej *ElasticJSON) ConvertResult(qc *QueryContext, snellerResult map[string]
 	var preProcessedData map[string]any
 	if len(ej.Aggregations) > 0 {
 		var err error
-		preProcessedData, err = preProcess(snellerResult)
+		preProcessedData, err = preProcess(qc, snellerResult)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -838,7 +900,7 @@ func (c *groupResults) docCount() (int64, error) {
 // it also combines the split SQL results that
 // are caused, because some aggragations cannot
 // be combined.
-func preProcess(snellerResult map[string]any) (map[string]any, error) {
+func preProcess(qc *QueryContext, snellerResult map[string]any) (map[string]any, error) {
 	preProcessed := make(map[string]any)
 
 	preProcessed[DocCount] = snellerResult[TotalCountBucket]
@@ -846,9 +908,17 @@ func preProcess(snellerResult map[string]any) (map[string]any, error) {
 	// make sure the buckets are processed in fixed order
 	// sorting the buckets makes sure that the outer aggregations
 	// are processed before the inner aggregations
+	var topHitsBuckets []string
 	for _, combinedBucketName := range sortedKeys(snellerResult) {
 		bucket := snellerResult[combinedBucketName]
 
+		// top-hits are assigned to the groups
+		// after all groups have been created
+		if strings.HasPrefix(combinedBucketName, BucketPrefix+":") && strings.HasSuffix(combinedBucketName, TopHitsSuffix) {
+			topHitsBuckets = append(topHitsBuckets, combinedBucketName)
+			continue
+		}
+
 		// only process buckets
 		bucketName, bucketIndex := splitWithPrefix(BucketPrefix, combinedBucketName)
 		if bucketIndex < 0 {
@@ -1015,9 +1085,74 @@ func preProcess(snellerResult map[string]any) (map[string]any, error) {
 		}
 	}
 
+	for _, combinedBucketName := range topHitsBuckets {
+		rows, ok := snellerResult[combinedBucketName].([]any)
+		if !ok {
+			return nil, fmt.Errorf("bucket %q should hold a list of records", combinedBucketName)
+		}
+		bucketName := strings.TrimSuffix(strings.TrimPrefix(combinedBucketName, BucketPrefix+":"), TopHitsSuffix)
+		bucketNameParts := strings.Split(bucketName, ":")
+		aggName := bucketNameParts[len(bucketNameParts)-1]
+		if len(bucketNameParts) == 1 {
+			preProcessed[aggName] = rows
+			continue
+		}
+
+		// hits that hold whole documents don't
+		// include the keys of the parent buckets
+		keyPaths := topHitsKeyPaths(qc, bucketNameParts[:len(bucketNameParts)-1])
+		for _, item := range rows {
+			row, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("bucket %q should only hold records", combinedBucketName)
+			}
+			for col, path := range keyPaths {
+				if _, ok := row[col]; !ok {
+					row[col] = lookupPath(row, path)
+				}
+			}
+
+			// hits of groups that didn't make it
+			// in the parent buckets are dropped
+			group := lookupGroup(preProcessed[bucketNameParts[0]], bucketNameParts[1:len(bucketNameParts)-1], row)
+			if group == nil {
+				continue
+			}
+			if group.Nested == nil {
+				group.Nested = make(map[string]any)
+			}
+			hits, _ := group.Nested[aggName].([]any)
+			group.Nested[aggName] = append(hits, row)
+		}
+	}
+
 	return preProcessed, nil
 }
 
+// lookupGroup returns the (nested) group
+// that matches the key values in the row
+// or nil if there is no such group.
+func lookupGroup(v any, bucketNameParts []string, row map[string]any) *groupResults {
+	for {
+		var group *groupResults
+		switch g := v.(type) {
+		case *groupResultMap:
+			h := sha256.New()
+			for _, col := range g.keyColumns {
+				hashAny(h, row[col])
+			}
+			group = g.groups[base64.RawURLEncoding.EncodeToString(h.Sum(nil))]
+		case *groupResults:
+			group = g
+		}
+		if group == nil || len(bucketNameParts) == 0 {
+			return group
+		}
+		v = group.Nested[bucketNameParts[0]]
+		bucketNameParts = bucketNameParts[1:]
+	}
+}
+
 func splitWithPrefix(prefix string, text string) (string, int) {
 	if !strings.HasPrefix(text, prefix+":") {
 		return "", -1
@@ -28,6 +28,7 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -162,7 +163,7 @@ func TestPreProcess(t *testing.T) {
 				t.Fatalf("can't unmarshal %q: %v", rawJSON, err)
 			}
 
-			preProcessData, err := preProcess(rawData)
+			preProcessData, err := preProcess(&QueryContext{}, rawData)
 			if err != nil {
 				t.Fatalf("can't pre-process %q: %v", test+input, err)
 			}
@@ -769,3 +770,279 @@ func coerceData(v any) any {
 		return vv
 	}
 }
+
+func TestTopHits(t *testing.T) {
+	rawJSON, err := os.ReadFile("testaggs/top-hits.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ej ElasticJSON
+	if err := json.Unmarshal(rawJSON, &ej); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := func(s string) time.Time {
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	snellerResult := map[string]any{
+		TotalCountBucket: 5,
+		"$bucket:region%0": []any{
+			map[string]any{"$key:region%0": "eu", DocCount: 3},
+			map[string]any{"$key:region%0": "us", DocCount: 2},
+		},
+		"$bucket:region:latest%hits": []any{
+			map[string]any{"$key:region%0": "eu", "source_ip": "10.0.0.3", "timestamp": ts("2022-06-05T03:00:00Z")},
+			map[string]any{"$key:region%0": "us", "source_ip": "10.0.1.2", "timestamp": ts("2022-06-05T02:30:00Z")},
+			map[string]any{"$key:region%0": "eu", "source_ip": "10.0.0.2", "timestamp": ts("2022-06-05T02:00:00Z")},
+			map[string]any{"$key:region%0": "us", "source_ip": "10.0.1.1", "timestamp": ts("2022-06-05T01:30:00Z")},
+			// hits of groups that aren't in the buckets are ignored
+			map[string]any{"$key:region%0": "ap", "source_ip": "10.0.2.1", "timestamp": ts("2022-06-05T01:00:00Z")},
+		},
+	}
+
+	qc := QueryContext{Query: ej, Index: "test"}
+	er, _, err := ej.ConvertResult(&qc, snellerResult)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := json.Marshal((*er.Aggregations)["region"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		Buckets []struct {
+			Key    string `json:"key"`
+			Latest struct {
+				Hits struct {
+					Total struct {
+						Value int64 `json:"value"`
+					} `json:"total"`
+					Hits []struct {
+						Index  string         `json:"_index"`
+						Source map[string]any `json:"_source"`
+						Sort   []int64        `json:"sort"`
+					} `json:"hits"`
+				} `json:"hits"`
+			} `json:"latest"`
+		} `json:"buckets"`
+	}
+	if err := json.Unmarshal(buf, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"eu": {"10.0.0.3", "10.0.0.2"},
+		"us": {"10.0.1.2", "10.0.1.1"},
+	}
+	if len(result.Buckets) != len(want) {
+		t.Fatalf("got %d buckets, want %d", len(result.Buckets), len(want))
+	}
+	for _, b := range result.Buckets {
+		hits := b.Latest.Hits.Hits
+		if len(hits) != len(want[b.Key]) {
+			t.Fatalf("bucket %q: got %d hits, want %d", b.Key, len(hits), len(want[b.Key]))
+		}
+		for i, h := range hits {
+			if got := h.Source["source_ip"]; got != want[b.Key][i] {
+				t.Errorf("bucket %q, hit %d: got source_ip %v, want %s", b.Key, i, got, want[b.Key][i])
+			}
+			if h.Index != "test" {
+				t.Errorf("bucket %q, hit %d: got index %q", b.Key, i, h.Index)
+			}
+			if len(h.Sort) != 1 || h.Sort[0] == 0 {
+				t.Errorf("bucket %q, hit %d: unexpected sort values %v", b.Key, i, h.Sort)
+			}
+		}
+		if i := len(hits) - 1; hits[0].Sort[0] < hits[i].Sort[0] {
+			t.Errorf("bucket %q: hits are not sorted in descending order", b.Key)
+		}
+	}
+	if n := result.Buckets[0].Latest.Hits.Total.Value; n != 3 {
+		t.Errorf("got total of %d hits in the first bucket, want 3", n)
+	}
+}
+
+func TestSearchAfter(t *testing.T) {
+	// timestamps (as unix-milli) with duplicates to
+	// ensure the tiebreaker is taken into account
+	var docs []map[string]any
+	for i, ts := range []float64{5, 7, 5, 7, 7, 3, 5, 9, 3} {
+		docs = append(docs, map[string]any{"timestamp": ts, "id": fmt.Sprintf("doc%d", i)})
+	}
+	sortFields := []SortField{
+		{Field: "timestamp", Order: OrderDescending},
+		{Field: "id", Order: OrderAscending},
+	}
+	less := func(a, b map[string]any) bool {
+		if a["timestamp"] != b["timestamp"] {
+			return a["timestamp"].(float64) > b["timestamp"].(float64)
+		}
+		return a["id"].(string) < b["id"].(string)
+	}
+
+	// eval evaluates the search_after predicate for the document
+	var eval func(e expression, doc map[string]any) any
+	eval = func(e expression, doc map[string]any) any {
+		switch e := e.(type) {
+		case *exprFieldName:
+			return doc[strings.Join(e.Fields, ".")]
+		case *exprJSONLiteral:
+			return e.Value.Value
+		case *exprOperator2:
+			v1, v2 := eval(e.Expr1, doc), eval(e.Expr2, doc)
+			switch e.Operator {
+			case "AND":
+				return v1.(bool) && v2.(bool)
+			case "OR":
+				return v1.(bool) || v2.(bool)
+			case "=":
+				return v1 == v2
+			}
+			var lt, gt bool
+			switch v1 := v1.(type) {
+			case float64:
+				lt, gt = v1 < v2.(float64), v1 > v2.(float64)
+			case string:
+				lt, gt = v1 < v2.(string), v1 > v2.(string)
+			}
+			if e.Operator == "<" {
+				return lt
+			}
+			return gt
+		}
+		t.Fatalf("unexpected expression %T", e)
+		return nil
+	}
+
+	const pageSize = 2
+	var cursor []any
+	seen := make(map[string]bool)
+	var prev map[string]any
+	for page := 0; len(seen) < len(docs); page++ {
+		if page > len(docs) {
+			t.Fatal("paging doesn't complete")
+		}
+		ej := ElasticJSON{Sort: sortFields, SearchAfter: cursor}
+		pred, err := ej.searchAfter(&QueryContext{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var hits []map[string]any
+		for _, doc := range docs {
+			if pred == nil || eval(pred, doc).(bool) {
+				hits = append(hits, doc)
+			}
+		}
+		if len(hits) == 0 {
+			t.Fatalf("page %d is empty with %d document(s) remaining", page, len(docs)-len(seen))
+		}
+		sort.Slice(hits, func(i, j int) bool { return less(hits[i], hits[j]) })
+		if len(hits) > pageSize {
+			hits = hits[:pageSize]
+		}
+
+		for _, hit := range hits {
+			id := hit["id"].(string)
+			if seen[id] {
+				t.Fatalf("page %d: document %q was already returned", page, id)
+			}
+			if prev != nil && !less(prev, hit) {
+				t.Fatalf("page %d: document %q is out of order", page, id)
+			}
+			seen[id] = true
+			prev = hit
+		}
+		last := hits[len(hits)-1]
+		cursor = []any{last["timestamp"], last["id"]}
+	}
+
+	// the cursor should match the sort fields
+	ej := ElasticJSON{Sort: sortFields, SearchAfter: []any{float64(1)}}
+	if _, err := ej.searchAfter(&QueryContext{}); err == nil {
+		t.Error("expected an error for a cursor with a missing tiebreaker")
+	}
+}
+
+func TestTopHitsDocuments(t *testing.T) {
+	rawJSON, err := os.ReadFile("testaggs/top-hits-docs.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ej ElasticJSON
+	if err := json.Unmarshal(rawJSON, &ej); err != nil {
+		t.Fatal(err)
+	}
+
+	// the hits hold the whole documents, so the
+	// keys of the buckets are taken from them
+	snellerResult := map[string]any{
+		TotalCountBucket: 7,
+		"$bucket:region%0": []any{
+			map[string]any{"$key:region%0": "eu", DocCount: 4},
+			map[string]any{"$key:region%0": "us", DocCount: 3},
+		},
+		"$bucket:region:latest%hits": []any{
+			map[string]any{"region": "eu", "source_ip": "10.0.0.3", "timestamp": 3},
+			map[string]any{"region": "us", "source_ip": "10.0.1.2", "timestamp": 2},
+			// identical documents are kept and documents
+			// with the same sort values are truncated
+			map[string]any{"region": "eu", "source_ip": "10.0.0.2", "timestamp": 2},
+			map[string]any{"region": "eu", "source_ip": "10.0.0.2", "timestamp": 2},
+			map[string]any{"region": "us", "source_ip": "10.0.1.1", "timestamp": 1},
+		},
+	}
+
+	qc := QueryContext{Query: ej, Index: "test"}
+	er, _, err := ej.ConvertResult(&qc, snellerResult)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := json.Marshal((*er.Aggregations)["region"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		Buckets []struct {
+			Key    string `json:"key"`
+			Latest struct {
+				Hits struct {
+					Hits []struct {
+						Source map[string]any `json:"_source"`
+					} `json:"hits"`
+				} `json:"hits"`
+			} `json:"latest"`
+		} `json:"buckets"`
+	}
+	if err := json.Unmarshal(buf, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"eu": {"10.0.0.3", "10.0.0.2"},
+		"us": {"10.0.1.2", "10.0.1.1"},
+	}
+	if len(result.Buckets) != len(want) {
+		t.Fatalf("got %d buckets, want %d", len(result.Buckets), len(want))
+	}
+	for _, b := range result.Buckets {
+		hits := b.Latest.Hits.Hits
+		if len(hits) != len(want[b.Key]) {
+			t.Fatalf("bucket %q: got %d hits, want %d", b.Key, len(hits), len(want[b.Key]))
+		}
+		for i, h := range hits {
+			if got := h.Source["source_ip"]; got != want[b.Key][i] {
+				t.Errorf("bucket %q, hit %d: got source_ip %v, want %s", b.Key, i, got, want[b.Key][i])
+			}
+			if got := h.Source["region"]; got != b.Key {
+				t.Errorf("bucket %q, hit %d: got region %v", b.Key, i, got)
+			}
+		}
+	}
+}
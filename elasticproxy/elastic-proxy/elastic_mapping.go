@@ -16,6 +16,7 @@ package elastic_proxy
 
 import (
 	"math/bits"
+	"sort"
 	"strings"
 )
 
@@ -27,9 +28,10 @@ type ElasticMapping struct {
 type Properties map[string]MappingValue
 
 type MappingValue struct {
-	Type       string     `json:"type"`
-	Indexed    bool       `json:"indexed,omitempty"`
-	Properties Properties `json:"properties,omitempty"`
+	Type       string            `json:"type"`
+	Indexed    bool              `json:"indexed,omitempty"`
+	Properties Properties        `json:"properties,omitempty"`
+	Meta       map[string]string `json:"meta,omitempty"`
 }
 
 const (
@@ -67,9 +69,59 @@ const (
 	annotationType
 )
 
+// MappingOptions controls the translation
+// performed by DataShapeToElasticMappingWith.
+type MappingOptions struct {
+	// LegacyLists, if true, maps every list to
+	// the "list" type and ignores the list items.
+	// Otherwise, lists are mapped like Elasticsearch
+	// maps arrays: a list of scalars has the type of
+	// the scalars and a list of objects is "nested".
+	LegacyLists bool
+	// SanitizeName, if non-nil, is used to rename
+	// each field. Dots in a field name that remain
+	// after the object hierarchy has been rebuilt are
+	// part of the field name, so a sanitizer such as
+	// ReplaceDots can be used to avoid Elasticsearch
+	// interpreting the dots as nesting. A field is not
+	// renamed if its new name is already in use.
+	SanitizeName func(name string) string
+	// KeepOriginalNames, if true, records the original
+	// name of each renamed field in its "meta" mapping
+	// parameter under the key OriginalNameMeta.
+	KeepOriginalNames bool
+}
+
+// OriginalNameMeta is the key of the "meta" mapping
+// parameter that holds the original name of a field
+// renamed by MappingOptions.SanitizeName.
+const OriginalNameMeta = "original_name"
+
+// ReplaceDots returns a MappingOptions.SanitizeName
+// function that replaces each dot in a name with repl.
+func ReplaceDots(repl string) func(string) string {
+	return func(name string) string {
+		return strings.ReplaceAll(name, ".", repl)
+	}
+}
+
 // DataShapeToElasticMapping translates raw 'fields' output from
 // SNELLER_DATASHAPE into Elastic's Mapping structure.
 func DataShapeToElasticMapping(fields map[string]any) *ElasticMapping {
+	return DataShapeToElasticMappingWith(fields, MappingOptions{})
+}
+
+// DataShapeToElasticMappingWith is like
+// DataShapeToElasticMapping, but with options.
+func DataShapeToElasticMappingWith(fields map[string]any, opts MappingOptions) *ElasticMapping {
+	m := &ElasticMapping{Properties: dataShapeProperties(fields, opts)}
+	if opts.SanitizeName != nil {
+		sanitizeNames(m.Properties, &opts)
+	}
+	return m
+}
+
+func dataShapeProperties(fields map[string]any, opts MappingOptions) Properties {
 	m := &ElasticMapping{Properties: make(map[string]MappingValue)}
 
 	for field, val := range fields {
@@ -85,6 +137,19 @@ func DataShapeToElasticMapping(fields map[string]any) *ElasticMapping {
 		}
 
 		typ := parseSnellerType(details)
+		if !opts.LegacyLists && typ&listType != 0 {
+			// an array has the type of its items
+			items, prefixes := listItemsType(fields, field)
+			if typ&^(nullType|listType) == 0 && items&^nullType == structType {
+				m.Properties[field] = MappingValue{
+					Type:       elasticTypeNested,
+					Properties: nestedProperties(fields, prefixes, opts),
+				}
+				continue
+			}
+			typ = (typ &^ listType) | items
+		}
+
 		elasticType := obtainElasticType(typ)
 		if elasticType == "" {
 			// just fallback to safe default
@@ -96,7 +161,95 @@ func DataShapeToElasticMapping(fields map[string]any) *ElasticMapping {
 
 	rebuildObjectsHierarchy(&m.Properties)
 
-	return m
+	return m.Properties
+}
+
+// sanitizeNames renames the fields in p
+// (recursively) using opts.SanitizeName
+func sanitizeNames(p Properties, opts *MappingOptions) {
+	var renamed []string
+	for name, mv := range p {
+		if mv.Properties != nil {
+			sanitizeNames(mv.Properties, opts)
+		}
+		if opts.SanitizeName(name) != name {
+			renamed = append(renamed, name)
+		}
+	}
+	// rename in a deterministic order
+	// so that collisions are resolved
+	// the same way every time
+	sort.Strings(renamed)
+	for _, name := range renamed {
+		newname := opts.SanitizeName(name)
+		if _, ok := p[newname]; ok {
+			continue
+		}
+		mv := p[name]
+		if opts.KeepOriginalNames {
+			mv.Meta = map[string]string{OriginalNameMeta: name}
+		}
+		delete(p, name)
+		p[newname] = mv
+	}
+}
+
+// listItemsType returns the union of the types of the
+// items of the list at path, looking through nested
+// lists, and the paths of the items that are objects
+func listItemsType(fields map[string]any, path string) (snellerType, []string) {
+	path += "." + listItems
+	details, _ := fields[path].(map[string]any)
+	typ := parseSnellerType(details)
+	var prefixes []string
+	if typ&structType != 0 {
+		prefixes = append(prefixes, path+".")
+	}
+	if typ&listType != 0 {
+		items, more := listItemsType(fields, path)
+		typ = (typ &^ listType) | items
+		prefixes = append(prefixes, more...)
+	}
+	return typ, prefixes
+}
+
+// nestedProperties builds the properties of the objects
+// in a list from the fields under each of the prefixes
+func nestedProperties(fields map[string]any, prefixes []string, opts MappingOptions) Properties {
+	sub := make(map[string]any)
+	for field, val := range fields {
+		for _, prefix := range prefixes {
+			rest, ok := strings.CutPrefix(field, prefix)
+			if !ok {
+				continue
+			}
+			sub[rest] = mergeDetails(sub[rest], val)
+		}
+	}
+	return dataShapeProperties(sub, opts)
+}
+
+// mergeDetails merges two sets of type counts
+func mergeDetails(a, b any) any {
+	am, ok := a.(map[string]any)
+	if !ok {
+		return b
+	}
+	bm, ok := b.(map[string]any)
+	if !ok {
+		return a
+	}
+	res := make(map[string]any, len(am)+len(bm))
+	for k, v := range am {
+		res[k] = v
+	}
+	for k, v := range bm {
+		x, _ := res[k].(int)
+		if y, ok := v.(int); ok {
+			res[k] = x + y
+		}
+	}
+	return res
 }
 
 var typeLookup = map[string]snellerType{
@@ -150,6 +303,9 @@ const (
 
 	elasticTypeList   = "list"
 	elasticTypeStruct = "object"
+
+	// https://www.elastic.co/guide/en/elasticsearch/reference/current/nested.html
+	elasticTypeNested = "nested"
 )
 
 const defaultElasticType = elasticTypeString
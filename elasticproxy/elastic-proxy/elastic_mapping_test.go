@@ -15,6 +15,7 @@
 package elastic_proxy
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -68,7 +69,7 @@ func TestDataShapeToElasticMapping(t *testing.T) {
 	assertType("enabled", "boolean")
 	assertType("test", "keyword")
 	assertType("user", "object")
-	assertType("tags", "list")
+	assertType("tags", "keyword")
 	assertType("bag", "keyword")
 	assertType("avatar", "object")
 
@@ -106,6 +107,124 @@ func TestDataShapeToElasticMapping(t *testing.T) {
 	assertType("url", "keyword")
 }
 
+func TestDataShapeToElasticMappingLists(t *testing.T) {
+	datashape := map[string]any{
+		"tags":        map[string]any{listField: 3, nullField: 1},
+		"tags.$items": map[string]any{stringField: 7},
+		"comments": map[string]any{
+			listField: 2,
+		},
+		"comments.$items": map[string]any{
+			structField: 4,
+		},
+		"comments.$items.author": map[string]any{
+			stringField: 4,
+		},
+		"comments.$items.likes": map[string]any{
+			intField: 3,
+		},
+		"comments.$items.replies": map[string]any{
+			listField: 1,
+		},
+		"comments.$items.replies.$items": map[string]any{
+			structField: 1,
+		},
+		"comments.$items.replies.$items.text": map[string]any{
+			stringField: 1,
+		},
+		"matrix":               map[string]any{listField: 1},
+		"matrix.$items":        map[string]any{listField: 2},
+		"matrix.$items.$items": map[string]any{floatField: 4, intField: 2},
+		"empty":                map[string]any{listField: 1},
+		"mixed":                map[string]any{listField: 1},
+		"mixed.$items":         map[string]any{structField: 1, intField: 1},
+		"mixed.$items.x":       map[string]any{intField: 1},
+		"user":                 map[string]any{structField: 1},
+		"user.ids":             map[string]any{listField: 1},
+		"user.ids.$items":      map[string]any{intField: 5},
+	}
+
+	m := DataShapeToElasticMapping(datashape)
+	check := func(p Properties, field, want string) MappingValue {
+		t.Helper()
+		mv, ok := p[field]
+		if !ok {
+			t.Fatalf("field %q not found", field)
+		}
+		if mv.Type != want {
+			t.Errorf("field %q: got type %q, want %q", field, mv.Type, want)
+		}
+		return mv
+	}
+
+	if len(m.Properties) != 6 {
+		t.Errorf("got %d top-level fields: %v", len(m.Properties), m.Properties)
+	}
+	check(m.Properties, "tags", "keyword")
+	check(m.Properties, "matrix", "double")
+	check(m.Properties, "empty", "keyword")
+	check(m.Properties, "mixed", "keyword")
+	user := check(m.Properties, "user", "object")
+	check(user.Properties, "ids", "long")
+
+	comments := check(m.Properties, "comments", "nested")
+	if len(comments.Properties) != 3 {
+		t.Errorf("got %d nested fields: %v", len(comments.Properties), comments.Properties)
+	}
+	check(comments.Properties, "author", "keyword")
+	check(comments.Properties, "likes", "long")
+	replies := check(comments.Properties, "replies", "nested")
+	check(replies.Properties, "text", "keyword")
+
+	// the legacy behavior ignores the items
+	m = DataShapeToElasticMappingWith(datashape, MappingOptions{LegacyLists: true})
+	check(m.Properties, "tags", "list")
+	check(m.Properties, "comments", "list")
+}
+
+func TestDataShapeToElasticMappingSanitize(t *testing.T) {
+	datashape := map[string]any{
+		"app.version":     map[string]any{intField: 3}, // there is no "app" struct
+		"alt-text":        map[string]any{stringField: 1},
+		"user":            map[string]any{structField: 2},
+		"user.first.name": map[string]any{stringField: 2},
+		"user.first_name": map[string]any{intField: 1}, // collides once sanitized
+		"user.id":         map[string]any{intField: 2},
+	}
+
+	// the default is to keep names verbatim
+	m := DataShapeToElasticMapping(datashape)
+	if _, ok := m.Properties["app.version"]; !ok {
+		t.Errorf("field %q not found: %v", "app.version", m.Properties)
+	}
+
+	m = DataShapeToElasticMappingWith(datashape, MappingOptions{
+		SanitizeName:      ReplaceDots("_"),
+		KeepOriginalNames: true,
+	})
+	want := Properties{
+		"app_version": {Type: "long", Meta: map[string]string{OriginalNameMeta: "app.version"}},
+		"alt-text":    {Type: "keyword"},
+		"user": {Type: "object", Properties: Properties{
+			"first.name": {Type: "keyword"},
+			"first_name": {Type: "long"},
+			"id":         {Type: "long"},
+		}},
+	}
+	if !reflect.DeepEqual(m.Properties, want) {
+		t.Errorf("got  %v", m.Properties)
+		t.Errorf("want %v", want)
+	}
+
+	// no metadata unless requested
+	m = DataShapeToElasticMappingWith(datashape, MappingOptions{
+		SanitizeName: ReplaceDots("_"),
+	})
+	if mv := m.Properties["app_version"]; mv.Type != "long" || mv.Meta != nil {
+		t.Errorf("unexpected mapping %v", mv)
+	}
+}
+
 func testDatashape() map[string]any {
 	return map[string]any{
 		"enabled": map[string]any{ // nulls + bool -> bool
@@ -115,7 +234,7 @@ func testDatashape() map[string]any {
 		"tags": map[string]any{
 			listField: 1,
 		},
-		"tags.$items": map[string]any{ // content of list values should be skipped
+		"tags.$items": map[string]any{ // list items give the type of the list
 			stringField: 20,
 		},
 		"user.display_name": map[string]any{ // untyped null -> default
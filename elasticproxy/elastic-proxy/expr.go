@@ -509,6 +509,29 @@ func (e *exprOperator2) Print(pc *printContext) {
 	pc.WriteRune(')')
 }
 
+// exprCase represents a CASE expression
+// with a single WHEN clause.
+type exprCase struct {
+	Context *QueryContext
+	When    expression
+	Then    expression
+	Else    expression
+}
+
+func (e *exprCase) QueryContext() *QueryContext {
+	return e.Context
+}
+
+func (e *exprCase) Print(pc *printContext) {
+	pc.WriteString("(CASE WHEN ")
+	e.When.Print(pc)
+	pc.WriteString(" THEN ")
+	e.Then.Print(pc)
+	pc.WriteString(" ELSE ")
+	e.Else.Print(pc)
+	pc.WriteString(" END)")
+}
+
 // exprFieldName represents a table (or
 // alias) that references a data-source.
 type exprSourceName struct {
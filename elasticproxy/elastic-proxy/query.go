@@ -19,6 +19,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 var (
@@ -198,14 +200,17 @@ func (q *orQueries) Expression(qc *QueryContext) (expression, error) {
 }
 
 type boolean struct {
-	Must               *andQueries `json:"must"`
-	Filter             *andQueries `json:"filter"`
-	Should             *orQueries  `json:"should"`
-	MustNot            *andQueries `json:"must_not"`
-	MinimumShouldMatch *boostValue `json:"minimum_should_match"`
-	Boost              *boostValue `json:"boost"`
+	Must               *andQueries         `json:"must"`
+	Filter             *andQueries         `json:"filter"`
+	Should             *orQueries          `json:"should"`
+	MustNot            *orQueries          `json:"must_not"`
+	MinimumShouldMatch *minimumShouldMatch `json:"minimum_should_match"`
+	Boost              *boostValue         `json:"boost"`
 }
 
+// Expression returns the expression for the boolean query.
+// Scoring is not supported, so "must" and "filter"
+// clauses are translated the same way.
 func (b *boolean) Expression(qc *QueryContext) (expression, error) {
 	var exprs []expression
 
@@ -230,7 +235,7 @@ func (b *boolean) Expression(qc *QueryContext) (expression, error) {
 	}
 
 	if b.Should != nil {
-		e, err := b.Should.Expression(qc)
+		e, err := b.shouldExpression(qc)
 		if err != nil {
 			return nil, err
 		}
@@ -239,6 +244,7 @@ func (b *boolean) Expression(qc *QueryContext) (expression, error) {
 		}
 	}
 
+	// none of the "must_not" clauses may match
 	if b.MustNot != nil {
 		e, err := b.MustNot.Expression(qc)
 		if err != nil {
@@ -256,6 +262,116 @@ func (b *boolean) Expression(qc *QueryContext) (expression, error) {
 	return andExpressions(exprs), nil
 }
 
+// shouldExpression returns the expression that requires
+// minimum_should_match of the "should" clauses to match,
+// or nil if they do not restrict the results.
+// minimum_should_match defaults to 1 if there are no
+// "must" or "filter" clauses and to 0 otherwise.
+func (b *boolean) shouldExpression(qc *QueryContext) (expression, error) {
+	clauses := *b.Should
+	required := 0
+	if b.MinimumShouldMatch != nil {
+		n, err := b.MinimumShouldMatch.required(len(clauses))
+		if err != nil {
+			return nil, err
+		}
+		required = n
+	} else if b.Must == nil && b.Filter == nil {
+		required = 1
+	}
+
+	switch {
+	case required <= 0:
+		return nil, nil
+	case required == 1:
+		return b.Should.Expression(qc)
+	case required == len(clauses):
+		return ((*queries)(b.Should)).Expression(qc, "AND")
+	case required > len(clauses):
+		// no document can match
+		v, _ := NewJSONLiteral(false)
+		return &exprJSONLiteral{Context: qc, Value: v}, nil
+	}
+
+	// count the clauses that match:
+	//   (CASE WHEN c1 THEN 1 ELSE 0 END + ...) >= required
+	one, _ := NewJSONLiteral(1)
+	zero, _ := NewJSONLiteral(0)
+	var count expression
+	for _, sq := range clauses {
+		e, err := sq.Expression(qc)
+		if err != nil {
+			return nil, err
+		}
+		var term expression = &exprJSONLiteral{Context: qc, Value: one}
+		if e != nil {
+			term = &exprCase{
+				Context: qc,
+				When:    e,
+				Then:    term,
+				Else:    &exprJSONLiteral{Context: qc, Value: zero},
+			}
+		}
+		if count == nil {
+			count = term
+		} else {
+			count = &exprOperator2{Context: qc, Operator: "+", Expr1: count, Expr2: term}
+		}
+	}
+	n, _ := NewJSONLiteral(required)
+	return &exprOperator2{
+		Context:  qc,
+		Operator: ">=",
+		Expr1:    count,
+		Expr2:    &exprJSONLiteral{Context: qc, Value: n},
+	}, nil
+}
+
+// minimumShouldMatch is the minimum_should_match
+// parameter of a boolean query: an integer or a
+// percentage, either of which may be negative to
+// specify the number of clauses that may not match
+type minimumShouldMatch string
+
+func (m *minimumShouldMatch) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case float64:
+		*m = minimumShouldMatch(strconv.FormatFloat(v, 'f', -1, 64))
+	case string:
+		*m = minimumShouldMatch(v)
+	default:
+		return fmt.Errorf("invalid minimum_should_match %s", data)
+	}
+	return nil
+}
+
+// required returns the number of clauses
+// out of n that have to match
+func (m *minimumShouldMatch) required(n int) (int, error) {
+	s := strings.TrimSpace(string(*m))
+	pct := strings.HasSuffix(s, "%")
+	s = strings.TrimSuffix(s, "%")
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 0 {
+		// combinations such as "3<90%" are not supported
+		return 0, fmt.Errorf("%w: minimum_should_match %q", ErrNotSupported, string(*m))
+	}
+	if pct {
+		// percentages are rounded down
+		v = n * v / 100
+	}
+	if neg {
+		v = n - v
+	}
+	return v, nil
+}
+
 type constantScore struct {
 	Filter *andQueries `json:"filter"`
 	Boost  *boostValue `json:"boost"`
@@ -16,6 +16,7 @@ package elastic_proxy
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 )
 
@@ -225,3 +226,48 @@ func TestUnmarshalTerms(t *testing.T) {
 		t.Fatal("expected boost 2.2")
 	}
 }
+
+func TestMinimumShouldMatch(t *testing.T) {
+	const should = `[{"term": {"a": 1}}, {"term": {"b": 2}}, {"term": {"c": 3}}]`
+	testcases := []struct {
+		min  string
+		want string
+	}{
+		{`0`, ``},
+		{`1`, `((("$source"."a" = 1) OR ("$source"."b" = 2)) OR ("$source"."c" = 3))`},
+		{`"-2"`, `((("$source"."a" = 1) OR ("$source"."b" = 2)) OR ("$source"."c" = 3))`},
+		{`"50%"`, `((("$source"."a" = 1) OR ("$source"."b" = 2)) OR ("$source"."c" = 3))`},
+		{`3`, `((("$source"."a" = 1) AND ("$source"."b" = 2)) AND ("$source"."c" = 3))`},
+		{`"100%"`, `((("$source"."a" = 1) AND ("$source"."b" = 2)) AND ("$source"."c" = 3))`},
+		{`"-3"`, ``},
+		{`4`, `FALSE`},
+	}
+	for _, tc := range testcases {
+		var q Query
+		err := json.Unmarshal([]byte(`{"bool": {"should": `+should+`, "minimum_should_match": `+tc.min+`}}`), &q)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.min, err)
+		}
+		e, err := q.Expression(&QueryContext{})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.min, err)
+		}
+		got := ""
+		if e != nil {
+			got = PrintExpr(e)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.min, got, tc.want)
+		}
+	}
+
+	// combinations are not supported
+	var q Query
+	err := json.Unmarshal([]byte(`{"bool": {"should": `+should+`, "minimum_should_match": "2<75%"}}`), &q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Expression(&QueryContext{}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("got error %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+WITH
+  "$source" AS
+    (SELECT *
+     FROM "table" AS "$source"
+    )
+
+SELECT
+  (SELECT COUNT(*)
+   FROM "$source"
+  ) AS "$total_count",
+
+  (SELECT *
+   FROM "$source"
+   WHERE (("$source"."timestamp" < `2022-06-05T03:00:00Z`) OR (("$source"."timestamp" = `2022-06-05T03:00:00Z`) AND ("$source"."id" > 'b')))
+   ORDER BY "$source"."timestamp" DESC, "$source"."id" ASC
+   LIMIT 2
+  ) AS "$hits"
@@ -0,0 +1,48 @@
+WITH
+  "$source" AS
+    (SELECT *
+     FROM "table" AS "$source"
+    ),
+
+  "$bucket:region%0" AS
+    (SELECT "$source"."region" AS "$key:region%0",
+            COUNT(*) AS "$doc_count"
+     FROM "$source"
+     GROUP BY "$source"."region"
+     ORDER BY "$doc_count" DESC
+     LIMIT 2
+    ),
+
+  "$bucket:region:latest%hits" AS
+    (SELECT *
+     FROM "$source"
+     WHERE (("$source"."region" IN (SELECT "$selection"."$key:region%0"
+     FROM "$bucket:region%0" AS "$selection")) AND ({'$key:region%0':"$source"."region",'$sort%0':"$source"."timestamp"} IN (SELECT {'$key:region%0':"$rank"."$key:region%0",'$sort%0':"$rank"."$sort%0"}
+     FROM (SELECT "$source"."region" AS "$key:region%0",
+                  "$source"."timestamp" AS "$sort%0",
+                  COUNT(*) AS "$doc_count"
+           FROM "$source"
+           WHERE ("$source"."region" IN (SELECT "$selection"."$key:region%0"
+           FROM "$bucket:region%0" AS "$selection"))
+           GROUP BY "$source"."region",
+                    "$source"."timestamp"
+           HAVING (ROW_NUMBER() OVER (PARTITION BY "$source"."region" ORDER BY "$source"."timestamp" DESC) <= 2)
+          ) AS "$rank"
+     WHERE ("$rank"."$doc_count" > 0)
+     LIMIT 9999)))
+     ORDER BY "$source"."timestamp" DESC
+     LIMIT 9999
+    )
+
+SELECT
+  (SELECT COUNT(*)
+   FROM "$source"
+  ) AS "$total_count",
+
+  (SELECT *
+   FROM "$bucket:region%0"
+  ) AS "$bucket:region%0",
+
+  (SELECT *
+   FROM "$bucket:region:latest%hits"
+  ) AS "$bucket:region:latest%hits"
\ No newline at end of file
@@ -0,0 +1 @@
+(((("$source"."age" >= 18) AND ("$source"."age" < 65)) AND (("$source"."tags" IN ('env1','env2')) OR ("$source"."owner" IS NOT MISSING))) AND (NOT (("$source"."status" = 'deleted') OR ("$source"."status" = 'hidden'))))
\ No newline at end of file
@@ -0,0 +1 @@
+("$source"."user"."id" = 'kimchy')
\ No newline at end of file
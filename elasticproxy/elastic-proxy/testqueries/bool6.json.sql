@@ -0,0 +1 @@
+((((CASE WHEN ("$source"."tags" = 'env1') THEN 1 ELSE 0 END) + (CASE WHEN ("$source"."owner" = 'alice') THEN 1 ELSE 0 END)) + (CASE WHEN ("$source"."age" >= 18) THEN 1 ELSE 0 END)) >= 2)
\ No newline at end of file
@@ -52,6 +52,11 @@ func formatIn(key string, value any, mapping map[string]TypeMapping) (any, error
 				}
 			}
 			return t.UTC(), nil
+		case float64:
+			// sort values of timestamps are written as unix-milli
+			return time.UnixMilli(int64(v)).UTC(), nil
+		case int64:
+			return time.UnixMilli(v).UTC(), nil
 		}
 
 	case "unix_seconds":
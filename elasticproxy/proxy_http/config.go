@@ -80,6 +80,9 @@ type mappingEntry struct {
 	IgnoreTotalHits        bool                                 `json:"ignoreTotalHits"`
 	IgnoreSumOtherDocCount bool                                 `json:"ignoreSumOtherDocCount"`
 	TypeMapping            map[string]elastic_proxy.TypeMapping `json:"typeMapping,omitempty"`
+	// LegacyListMapping maps lists to the "list" type
+	// in the Mapping API instead of to the item type
+	LegacyListMapping bool `json:"legacyListMapping,omitempty"`
 }
 
 type mappingEntrySource struct {
@@ -107,7 +107,9 @@ func obtainElasticMapping(c *HandlerContext) *elastic_proxy.ElasticMapping {
 		return nil
 	}
 
-	return elastic_proxy.DataShapeToElasticMapping(fields)
+	return elastic_proxy.DataShapeToElasticMappingWith(fields, elastic_proxy.MappingOptions{
+		LegacyLists: c.Mapping.LegacyListMapping,
+	})
 }
 
 func executeQuery(c *HandlerContext, SQL string) bool {
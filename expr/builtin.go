@@ -27,6 +27,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/SnellerInc/sneller/date"
+	"github.com/SnellerInc/sneller/internal/stringext"
 	"github.com/SnellerInc/sneller/ion"
 )
 
@@ -80,6 +81,10 @@ type binfo struct {
 	// ret, if non-zero, specifies the return type
 	// of the expression
 	ret TypeSet
+	// typeof, if non-nil, computes the return type
+	// of the expression from its arguments; it takes
+	// precedence over ret
+	typeof func(Hint, []Node) TypeSet
 
 	// if a builtin is private, it cannot
 	// be created during parsing; it can
@@ -115,14 +120,18 @@ const (
 	ContainsFuzzyUnicode
 	OctetLength
 	CharLength // sql:CHAR_LENGTH sql:CHARACTER_LENGTH
+	CodePoint  // sql:ASCII sql:UNICODE
+	Chr
 	IsSubnetOf
 	Substring
 	SplitPart
+	Translate
 
 	BitCount
 
 	Abs
 	Sign
+	IsNaN // sql:IS_NAN
 
 	Round
 	RoundEven
@@ -157,6 +166,7 @@ const (
 	Atan2
 
 	Pmod
+	Random
 
 	Least
 	Greatest
@@ -211,6 +221,8 @@ const (
 
 	ToUnixEpoch
 	ToUnixMicro
+	ParseTimestamp
+	FormatTimestamp
 
 	GeoHash
 	GeoTileX
@@ -224,6 +236,8 @@ const (
 	ArrayPosition
 	ArraySum
 
+	JSONExtract // sql:JSON_EXTRACT
+
 	VectorInnerProduct   // sql:INNER_PRODUCT
 	VectorL1Distance     // sql:L1_DISTANCE
 	VectorL2Distance     // sql:L2_DISTANCE
@@ -232,6 +246,8 @@ const (
 	TableGlob
 	TablePattern
 
+	Unnest // UNNEST(list) is only valid as the right-hand side of a join
+
 	// used by query planner:
 	InSubquery        // matches IN (SELECT ...)
 	InReplacement     // IN_REPLACEMENT(x, id)
@@ -249,6 +265,9 @@ const (
 	AssertIonType
 
 	PartitionValue // PARTITION_VALUE(int) is used as a placeholder during query planning
+	HashBucket     // HASH_BUCKET(x, n) produces the number of the hash bucket of x among n buckets
+	BucketValue    // BUCKET_VALUE() is used as a placeholder for a hash bucket number during query planning
+	UnseededRandom // UNSEEDED_RANDOM(n) is used as a placeholder for the n-th RANDOM() without a seed during query planning
 
 	Unspecified // catch-all for opaque built-ins; sql:UNKNOWN
 	maxBuiltin
@@ -567,6 +586,169 @@ func checkSplitPart(h Hint, args []Node) error {
 	return nil
 }
 
+func checkRandom(h Hint, args []Node) error {
+	if len(args) > 1 {
+		return errsyntaxf("RANDOM expects at most 1 argument, but found %d", len(args))
+	}
+	if len(args) == 1 {
+		if _, ok := args[0].(Integer); !ok {
+			return errsyntaxf("RANDOM seed must be an integer literal")
+		}
+	}
+	return nil
+}
+
+func checkUnseededRandom(h Hint, args []Node) error {
+	if len(args) != 1 {
+		return mismatch(1, len(args))
+	}
+	if _, ok := args[0].(Integer); !ok {
+		return errsyntaxf("UNSEEDED_RANDOM expects an integer literal")
+	}
+	return nil
+}
+
+func checkTranslate(h Hint, args []Node) error {
+	nArgs := len(args)
+	if nArgs != 3 {
+		return errsyntaxf("TRANSLATE expects 3 arguments, but found %d", nArgs)
+	}
+	if !TypeOf(args[0], h).AnyOf(StringType) {
+		return errtype(args[0], "not a string")
+	}
+	if _, ok := args[1].(String); !ok {
+		return errsyntaxf("TRANSLATE argument 1 is not a string literal")
+	}
+	if _, ok := args[2].(String); !ok {
+		return errsyntaxf("TRANSLATE argument 2 is not a string literal")
+	}
+	return nil
+}
+
+func checkParseTimestamp(h Hint, args []Node) error {
+	if len(args) != 2 {
+		return mismatch(2, len(args))
+	}
+	if !TypeOf(args[0], h).AnyOf(StringType) {
+		return errtype(args[0], "not a string")
+	}
+	format, ok := args[1].(String)
+	if !ok {
+		return errsyntaxf("PARSE_TIMESTAMP requires a constant string argument for format")
+	}
+	if _, err := date.Layout(string(format)); err != nil {
+		return errsyntaxf("PARSE_TIMESTAMP: %s", err)
+	}
+	return nil
+}
+
+func checkFormatTimestamp(h Hint, args []Node) error {
+	if len(args) != 2 {
+		return mismatch(2, len(args))
+	}
+	if !TypeOf(args[0], h).AnyOf(TimeType) {
+		return errtype(args[0], "not a timestamp")
+	}
+	format, ok := args[1].(String)
+	if !ok {
+		return errsyntaxf("FORMAT_TIMESTAMP requires a constant string argument for format")
+	}
+	if _, err := date.Layout(string(format)); err != nil {
+		return errsyntaxf("FORMAT_TIMESTAMP: %s", err)
+	}
+	return nil
+}
+
+func simplifyTranslate(h Hint, args []Node) Node {
+	if len(args) != 3 {
+		return nil
+	}
+	from, ok := args[1].(String)
+	if !ok {
+		return nil
+	}
+	to, ok := args[2].(String)
+	if !ok {
+		return nil
+	}
+	if str, ok := args[0].(String); ok {
+		return String(translate(string(str), string(from), string(to)))
+	}
+	if from == "" {
+		// nothing to translate
+		return missingUnless(args[0], h, StringType)
+	}
+	return nil
+}
+
+// translate implements TRANSLATE(str, from, to):
+// each character of str that occurs in from is
+// replaced by the character at the same position in to,
+// or deleted if to is shorter than from
+func translate(str, from, to string) string {
+	fr := []rune(from)
+	tr := []rune(to)
+	var out strings.Builder
+	for _, c := range str {
+		i := 0
+		for i < len(fr) && fr[i] != c {
+			i++
+		}
+		if i == len(fr) {
+			out.WriteRune(c)
+		} else if i < len(tr) {
+			out.WriteRune(tr[i])
+		}
+	}
+	return out.String()
+}
+
+func simplifyCodePoint(h Hint, args []Node) Node {
+	if len(args) != 1 {
+		return nil
+	}
+	str, ok := args[0].(String)
+	if !ok {
+		return nil
+	}
+	r, ok := stringext.DecodeCodePoint([]byte(str))
+	if !ok {
+		return Missing{}
+	}
+	return Integer(r)
+}
+
+func simplifyChr(h Hint, args []Node) Node {
+	if len(args) != 1 {
+		return nil
+	}
+	if _, ok := args[0].(Float); ok {
+		// floats are not truncated to code points
+		return Missing{}
+	}
+	i, ok := args[0].(Integer)
+	if !ok {
+		return nil
+	}
+	if i < 0 || i > utf8.MaxRune || !utf8.ValidRune(rune(i)) {
+		return Missing{}
+	}
+	return String(string(rune(i)))
+}
+
+func simplifyIsNaN(h Hint, args []Node) Node {
+	if len(args) != 1 {
+		return nil
+	}
+	switch n := args[0].(type) {
+	case Float:
+		return Bool(math.IsNaN(float64(n)))
+	case Integer, *Rational:
+		return Bool(false)
+	}
+	return nil
+}
+
 var unaryStringArgs = fixedArgs(StringType)
 var variadicNumeric = variadicArgs(NumericType)
 var fixedTime = fixedArgs(TimeType)
@@ -654,6 +836,17 @@ func checkScalarReplacement(h Hint, args []Node) error {
 	return nil
 }
 
+func checkHashBucket(h Hint, args []Node) error {
+	if len(args) != 2 {
+		return mismatch(2, len(args))
+	}
+	n, ok := args[1].(Integer)
+	if !ok || n <= 0 || n&(n-1) != 0 {
+		return errsyntaxf("second argument to HASH_BUCKET must be a power of two, not %q", ToString(args[1]))
+	}
+	return nil
+}
+
 func checkObjectSize(h Hint, args []Node) error {
 	if len(args) != 1 {
 		return errsyntaxf("SIZE expects one argument, but found %d", len(args))
@@ -739,6 +932,13 @@ func checkTablePattern(h Hint, args []Node) error {
 	return nil
 }
 
+// checkUnnest rejects UNNEST in every position
+// except the right-hand side of a join, which is
+// checked by checkJoinUnnest instead
+func checkUnnest(h Hint, args []Node) error {
+	return errsyntaxf("UNNEST can only be used on the right-hand side of a join")
+}
+
 func checkAssertIonType(h Hint, args []Node) error {
 	if len(args) < 2 {
 		return errsyntaxf("requires at least 2 arguments")
@@ -777,6 +977,20 @@ func checkAssertIonType(h Hint, args []Node) error {
 	return nil
 }
 
+// typeofAssertIonType returns the union of the
+// types passed to ASSERT_ION_TYPE and MISSING
+func typeofAssertIonType(h Hint, args []Node) TypeSet {
+	t := MissingType
+	for i := 1; i < len(args); i++ {
+		if v, ok := args[i].(Integer); ok && v >= 0 && v < 16 {
+			t |= TypeSet(1 << v)
+		} else {
+			return AnyType
+		}
+	}
+	return t & TypeOf(args[0], h)
+}
+
 func simplifyAssertIonType(h Hint, args []Node) Node {
 
 	hasType := func(t ion.Type) bool {
@@ -1089,9 +1303,12 @@ var builtinInfo = [maxBuiltin]binfo{
 	ContainsCI:           {check: checkContains, private: true, ret: LogicalType},
 	CharLength:           {check: unaryStringArgs, ret: UnsignedType | MissingType},
 	OctetLength:          {check: unaryStringArgs, ret: UnsignedType | MissingType},
+	CodePoint:            {check: unaryStringArgs, ret: UnsignedType | MissingType, simplify: simplifyCodePoint},
+	Chr:                  {check: fixedArgs(NumericType), ret: StringType | MissingType, simplify: simplifyChr},
 	IsSubnetOf:           {check: checkIsSubnetOf, ret: LogicalType, simplify: simplifyIsSubnetOf},
 	Substring:            {check: checkSubstring, ret: StringType | MissingType},
 	SplitPart:            {check: checkSplitPart, ret: StringType | MissingType},
+	Translate:            {check: checkTranslate, ret: StringType | MissingType, simplify: simplifyTranslate},
 	EqualsCI:             {ret: LogicalType, private: true},
 	EqualsFuzzy:          {check: checkEqualsContainsFuzzy, ret: LogicalType},
 	EqualsFuzzyUnicode:   {check: checkEqualsContainsFuzzy, ret: LogicalType},
@@ -1101,6 +1318,7 @@ var builtinInfo = [maxBuiltin]binfo{
 	BitCount:  {check: fixedArgs(NumericType), ret: IntegerType | MissingType},
 	Abs:       {check: fixedArgs(NumericType), ret: NumericType},
 	Sign:      {check: fixedArgs(NumericType), ret: NumericType},
+	IsNaN:     {check: fixedArgs(NumericType), ret: LogicalType, simplify: simplifyIsNaN},
 	Round:     {check: fixedArgs(NumericType), ret: FloatType | MissingType, simplify: simplifyRound},
 	RoundEven: {check: fixedArgs(NumericType), ret: FloatType | MissingType, simplify: simplifyRoundEven},
 	Trunc:     {check: fixedArgs(NumericType), ret: FloatType | MissingType, simplify: simplifyTrunc},
@@ -1130,6 +1348,7 @@ var builtinInfo = [maxBuiltin]binfo{
 	Atan:      {check: fixedArgs(NumericType), ret: FloatType | MissingType, simplify: mathfunc(math.Atan)},
 	Atan2:     {check: fixedArgs(NumericType, NumericType), ret: FloatType | MissingType, simplify: mathfunc2(math.Atan2)},
 	Pmod:      {check: fixedArgs(NumericType, NumericType), ret: NumericType | MissingType, simplify: simplifyPmod},
+	Random:    {check: checkRandom, ret: FloatType},
 
 	Least:       {check: variadicNumeric, ret: NumericType | MissingType, simplify: mathfuncreduce(math.Min)},
 	Greatest:    {check: variadicNumeric, ret: NumericType | MissingType, simplify: mathfuncreduce(math.Max)},
@@ -1179,6 +1398,8 @@ var builtinInfo = [maxBuiltin]binfo{
 	DateTruncYear:          {check: fixedTime, private: true, ret: TimeType | MissingType, simplify: simplifyDateTrunc(Year)},
 	ToUnixEpoch:            {check: fixedTime, ret: IntegerType | MissingType},
 	ToUnixMicro:            {check: fixedTime, ret: IntegerType | MissingType},
+	ParseTimestamp:         {check: checkParseTimestamp, ret: TimeType | MissingType},
+	FormatTimestamp:        {check: checkFormatTimestamp, ret: StringType | MissingType},
 
 	GeoHash:     {check: fixedArgs(NumericType, NumericType, IntegerType), ret: StringType | MissingType},
 	GeoTileX:    {check: fixedArgs(NumericType, IntegerType), ret: StringType | MissingType},
@@ -1192,6 +1413,8 @@ var builtinInfo = [maxBuiltin]binfo{
 	ArrayPosition: {check: checkArrayPosition, ret: UnsignedType | MissingType},
 	ArraySum:      {check: checkArraySum, ret: FloatType | MissingType},
 
+	JSONExtract: {check: checkJSONExtract, ret: StringType | NumericType | BoolType | NullType | MissingType},
+
 	VectorInnerProduct:   {check: checkVectorOp("INNER_PRODUCT"), ret: FloatType | MissingType},
 	VectorL1Distance:     {check: checkVectorOp("L1_DISTANCE"), ret: FloatType | MissingType},
 	VectorL2Distance:     {check: checkVectorOp("L2_DISTANCE"), ret: FloatType | MissingType},
@@ -1210,10 +1433,14 @@ var builtinInfo = [maxBuiltin]binfo{
 	MakeStruct: {ret: StructType, private: true, text: makeStructText, simplify: simplifyMakeStruct},
 
 	TypeBit:        {check: fixedArgs(AnyType), ret: UnsignedType, simplify: simplifyTypeBit},
-	AssertIonType:  {check: checkAssertIonType, ret: AnyType, simplify: simplifyAssertIonType, private: true},
+	AssertIonType:  {check: checkAssertIonType, ret: AnyType, typeof: typeofAssertIonType, simplify: simplifyAssertIonType, private: true},
 	TableGlob:      {check: checkTableGlob, ret: AnyType, isTable: true},
 	TablePattern:   {check: checkTablePattern, ret: AnyType, isTable: true},
+	Unnest:         {check: checkUnnest, ret: AnyType},
 	PartitionValue: {ret: AnyType, private: true},
+	HashBucket:     {check: checkHashBucket, ret: IntegerType | MissingType, private: true},
+	BucketValue:    {check: fixedArgs(), ret: IntegerType, private: true},
+	UnseededRandom: {check: checkUnseededRandom, ret: FloatType, private: true},
 }
 
 // JSONTypeBits returns a unique bit pattern
@@ -1286,6 +1513,9 @@ func (b *Builtin) typeof(h Hint) TypeSet {
 	if bi == nil {
 		return AnyType
 	}
+	if bi.typeof != nil {
+		return bi.typeof(h, b.Args)
+	}
 	return bi.ret
 }
 
@@ -2,7 +2,7 @@ package expr
 
 // Code generated automatically; DO NOT EDIT
 
-var builtin2Name = [126]string{
+var builtin2Name = [138]string{
 	"CONCAT",                   // Concat
 	"TRIM",                     // Trim
 	"LTRIM",                    // Ltrim
@@ -18,12 +18,16 @@ var builtin2Name = [126]string{
 	"CONTAINS_FUZZY_UNICODE",   // ContainsFuzzyUnicode
 	"OCTET_LENGTH",             // OctetLength
 	"CHAR_LENGTH",              // CharLength
+	"ASCII",                    // CodePoint
+	"CHR",                      // Chr
 	"IS_SUBNET_OF",             // IsSubnetOf
 	"SUBSTRING",                // Substring
 	"SPLIT_PART",               // SplitPart
+	"TRANSLATE",                // Translate
 	"BIT_COUNT",                // BitCount
 	"ABS",                      // Abs
 	"SIGN",                     // Sign
+	"IS_NAN",                   // IsNaN
 	"ROUND",                    // Round
 	"ROUND_EVEN",               // RoundEven
 	"TRUNC",                    // Trunc
@@ -54,6 +58,7 @@ var builtin2Name = [126]string{
 	"ATAN",                     // Atan
 	"ATAN2",                    // Atan2
 	"PMOD",                     // Pmod
+	"RANDOM",                   // Random
 	"LEAST",                    // Least
 	"GREATEST",                 // Greatest
 	"WIDTH_BUCKET",             // WidthBucket
@@ -101,6 +106,8 @@ var builtin2Name = [126]string{
 	"DATE_TRUNC_YEAR",          // DateTruncYear
 	"TO_UNIX_EPOCH",            // ToUnixEpoch
 	"TO_UNIX_MICRO",            // ToUnixMicro
+	"PARSE_TIMESTAMP",          // ParseTimestamp
+	"FORMAT_TIMESTAMP",         // FormatTimestamp
 	"GEO_HASH",                 // GeoHash
 	"GEO_TILE_X",               // GeoTileX
 	"GEO_TILE_Y",               // GeoTileY
@@ -111,12 +118,14 @@ var builtin2Name = [126]string{
 	"ARRAY_SIZE",               // ArraySize
 	"ARRAY_POSITION",           // ArrayPosition
 	"ARRAY_SUM",                // ArraySum
+	"JSON_EXTRACT",             // JSONExtract
 	"INNER_PRODUCT",            // VectorInnerProduct
 	"L1_DISTANCE",              // VectorL1Distance
 	"L2_DISTANCE",              // VectorL2Distance
 	"COSINE_DISTANCE",          // VectorCosineDistance
 	"TABLE_GLOB",               // TableGlob
 	"TABLE_PATTERN",            // TablePattern
+	"UNNEST",                   // Unnest
 	"IN_SUBQUERY",              // InSubquery
 	"IN_REPLACEMENT",           // InReplacement
 	"HASH_REPLACEMENT",         // HashReplacement
@@ -129,6 +138,9 @@ var builtin2Name = [126]string{
 	"TYPE_BIT",                 // TypeBit
 	"ASSERT_ION_TYPE",          // AssertIonType
 	"PARTITION_VALUE",          // PartitionValue
+	"HASH_BUCKET",              // HashBucket
+	"BUCKET_VALUE",             // BucketValue
+	"UNSEEDED_RANDOM",          // UnseededRandom
 }
 
 func name2Builtin(s string) BuiltinOp {
@@ -165,18 +177,28 @@ func name2Builtin(s string) BuiltinOp {
 		return CharLength
 	case "CHARACTER_LENGTH":
 		return CharLength
+	case "ASCII":
+		return CodePoint
+	case "UNICODE":
+		return CodePoint
+	case "CHR":
+		return Chr
 	case "IS_SUBNET_OF":
 		return IsSubnetOf
 	case "SUBSTRING":
 		return Substring
 	case "SPLIT_PART":
 		return SplitPart
+	case "TRANSLATE":
+		return Translate
 	case "BIT_COUNT":
 		return BitCount
 	case "ABS":
 		return Abs
 	case "SIGN":
 		return Sign
+	case "IS_NAN":
+		return IsNaN
 	case "ROUND":
 		return Round
 	case "ROUND_EVEN":
@@ -241,6 +263,8 @@ func name2Builtin(s string) BuiltinOp {
 		return Atan2
 	case "PMOD":
 		return Pmod
+	case "RANDOM":
+		return Random
 	case "LEAST":
 		return Least
 	case "GREATEST":
@@ -335,6 +359,10 @@ func name2Builtin(s string) BuiltinOp {
 		return ToUnixEpoch
 	case "TO_UNIX_MICRO":
 		return ToUnixMicro
+	case "PARSE_TIMESTAMP":
+		return ParseTimestamp
+	case "FORMAT_TIMESTAMP":
+		return FormatTimestamp
 	case "GEO_HASH":
 		return GeoHash
 	case "GEO_TILE_X":
@@ -355,6 +383,8 @@ func name2Builtin(s string) BuiltinOp {
 		return ArrayPosition
 	case "ARRAY_SUM":
 		return ArraySum
+	case "JSON_EXTRACT":
+		return JSONExtract
 	case "INNER_PRODUCT":
 		return VectorInnerProduct
 	case "L1_DISTANCE":
@@ -367,6 +397,8 @@ func name2Builtin(s string) BuiltinOp {
 		return TableGlob
 	case "TABLE_PATTERN":
 		return TablePattern
+	case "UNNEST":
+		return Unnest
 	case "IN_SUBQUERY":
 		return InSubquery
 	case "IN_REPLACEMENT":
@@ -391,8 +423,14 @@ func name2Builtin(s string) BuiltinOp {
 		return AssertIonType
 	case "PARTITION_VALUE":
 		return PartitionValue
+	case "HASH_BUCKET":
+		return HashBucket
+	case "BUCKET_VALUE":
+		return BucketValue
+	case "UNSEEDED_RANDOM":
+		return UnseededRandom
 	}
 	return Unspecified
 }
 
-// checksum: 5ab82de4e9716a9be1d64c0692e6674b
+// checksum: 2276816164a2421f015a6d64055e0540
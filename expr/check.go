@@ -173,10 +173,42 @@ func (c *checkwalk) Visit(n Node) Visitor {
 
 	case *Table:
 		return &checktable{parent: c}
+	case *Join:
+		if t.On != nil {
+			Walk(c, t.On)
+		}
+		Walk(c, t.Left)
+		if !c.checkJoinUnnest(t) {
+			Walk(c, t.Right.Expr)
+		}
+		return nil
 	}
 	return c
 }
 
+// checkJoinUnnest checks the right-hand side of j
+// if it is UNNEST(list) and returns false otherwise
+func (c *checkwalk) checkJoinUnnest(j *Join) bool {
+	u, ok := j.Right.Expr.(*Builtin)
+	if !ok || u.Func != Unnest {
+		return false
+	}
+	if len(u.Args) != 1 {
+		c.adderror(mismatch(1, len(u.Args)))
+		return true
+	}
+	if j.Kind != CrossJoin && j.Kind != LeftJoin {
+		c.errorf("UNNEST cannot be used with %s", j.Kind)
+		return true
+	}
+	if j.Kind == LeftJoin && j.On != Bool(true) {
+		c.errorf("LEFT JOIN UNNEST requires ON TRUE")
+		return true
+	}
+	Walk(c, u.Args[0])
+	return true
+}
+
 func combine(err []error) error {
 	if len(err) == 1 {
 		return err[0]
@@ -225,8 +257,10 @@ func (l *Logical) check(h Hint) error {
 }
 
 func (c *Comparison) check(h Hint) error {
-	lt := TypeOf(c.Left, h)
-	rt := TypeOf(c.Right, h)
+	// string literals are compared with timestamps
+	// as timestamps; see Comparison.simplify
+	lt := TypeOf(timestampLiteral(c.Left, c.Right, h), h)
+	rt := TypeOf(timestampLiteral(c.Right, c.Left, h), h)
 
 	oktypes := AnyType &^ MissingType
 	if c.Op.Ordinal() {
@@ -325,6 +359,16 @@ func (a *Aggregate) check(h Hint) error {
 	} else if a.Inner == nil {
 		return errsyntax(a, "aggregate needs an argument")
 	}
+	switch a.Op {
+	case OpBitAnd, OpBitOr, OpBitXor:
+		if TypeOf(a.Inner, h)&IntegerType == 0 {
+			return errtype(a, "%s requires an integer argument", a.Op)
+		}
+	case OpCountIf:
+		if !TypeOf(a.Inner, h).Logical() {
+			return errtype(a, "COUNT_IF requires a boolean argument")
+		}
+	}
 	return nil
 }
 
@@ -343,7 +387,7 @@ func (c *Cast) check(h Hint) error {
 	case SymbolType, DecimalType:
 		return errsyntaxf("unsupported cast %q", c)
 	case StringType:
-		if ft&(StringType|IntegerType) == 0 {
+		if ft&(StringType|IntegerType|BoolType) == 0 {
 			return errtype(c, "unsupported cast will never succeed")
 		}
 	case StructType, ListType, TimeType:
@@ -431,6 +475,35 @@ func (d *Dot) check(h Hint) error {
 	return nil
 }
 
+func (t *Transform) check(h Hint) error {
+	if TypeOf(t.List, h)&ListType == 0 {
+		return errtype(t.List, "cannot transform non-list value")
+	}
+	var err error
+	visit := WalkFunc(func(e Node) bool {
+		if err != nil {
+			return false
+		}
+		if id, ok := e.(Ident); ok && string(id) != t.Param {
+			err = errsyntaxf("TRANSFORM: %s is not bound; the lambda may only reference %s",
+				QuoteID(string(id)), QuoteID(t.Param))
+		}
+		return true
+	})
+	Walk(visit, t.Body)
+	if err != nil {
+		return err
+	}
+	return Check(t.Body)
+}
+
+func (s *Slice) check(h Hint) error {
+	if TypeOf(s.Inner, h)&ListType == 0 {
+		return errtype(s.Inner, "cannot slice non-list value")
+	}
+	return nil
+}
+
 func (i *Index) check(h Hint) error {
 	listLen := func(e Node) (int, bool) {
 		switch t := e.(type) {
@@ -442,14 +515,11 @@ func (i *Index) check(h Hint) error {
 			return 0, false
 		}
 	}
-	if i.Offset < 0 {
-		return errtype(i, "cannot perform negative index operation")
-	}
 	t := TypeOf(i.Inner, h)
 	if t&ListType == 0 {
 		return errtype(i.Inner, "cannot index non-list value")
 	}
-	if llen, ok := listLen(i.Inner); ok && i.Offset >= llen {
+	if llen, ok := listLen(i.Inner); ok && (i.Offset >= llen || i.Offset < -llen) {
 		return errtype(i, "cannot index a list of length %d at offset %d", llen, i.Offset)
 	}
 	return nil
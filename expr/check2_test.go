@@ -193,6 +193,42 @@ func TestCheckInvalidQuery(t *testing.T) {
 			`SELECT 'test'.test`,
 			`cannot use '.' operator on non-struct type`,
 		},
+		{
+			`SELECT JSON_EXTRACT(x, y) FROM table`,
+			`literal string path`,
+		},
+		{
+			`SELECT JSON_EXTRACT(x, 'a.b') FROM table`,
+			`does not start with`,
+		},
+		{
+			`SELECT JSON_EXTRACT(x, '$.a[b]') FROM table`,
+			`bad array index`,
+		},
+		{
+			`SELECT JSON_EXTRACT(CAST(x AS INTEGER), '$.a') FROM table`,
+			`not a string`,
+		},
+		{
+			`SELECT PARSE_TIMESTAMP(x, y) FROM table`,
+			`constant string argument for format`,
+		},
+		{
+			`SELECT PARSE_TIMESTAMP(x, '%Y-%Q') FROM table`,
+			`unsupported format directive`,
+		},
+		{
+			`SELECT FORMAT_TIMESTAMP(x, 'yyyy') FROM table`,
+			`does not contain any layout elements`,
+		},
+		{
+			`SELECT FORMAT_TIMESTAMP('x', '%Y') FROM table`,
+			`not a timestamp`,
+		},
+		{
+			`SELECT * FROM table WHERE CAST(x AS TIMESTAMP) < 'yesterday'`,
+			"lhs and rhs.*never comparable",
+		},
 	}
 	for i := range testcases {
 		i := i
@@ -206,6 +242,8 @@ func TestCheckValidQuery(t *testing.T) {
 	testcases := []testcaseError{
 		{query: `SELECT * FROM TABLE_GLOB(a) ++ TABLE_GLOB(b)`},
 		{query: `SELECT OCTET_LENGTH('foo') = 3`},
+		{query: `SELECT JSON_EXTRACT(x, '$.a["b c"][0]') FROM table`},
+		{query: `SELECT * FROM table WHERE CAST(x AS TIMESTAMP) >= '2023-01-01T00:00:00Z'`},
 	}
 
 	for i := range testcases {
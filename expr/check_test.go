@@ -57,6 +57,28 @@ func TestCheckExpressions(t *testing.T) {
 			expr: &Cast{From: path("x"), To: DecimalType},
 			kind: &SyntaxError{},
 		},
+		{
+			// BIT_AND(CAST(x AS FLOAT))
+			expr: AggregateAnd(&Cast{From: path("x"), To: FloatType}),
+			kind: &TypeError{},
+			msg:  "requires an integer argument",
+		},
+		{
+			// BIT_XOR('xyz')
+			expr: AggregateXor(String("xyz")),
+			kind: &TypeError{},
+		},
+		{
+			// COUNT_IF(x + 1)
+			expr: &Aggregate{Op: OpCountIf, Inner: Add(path("x"), Integer(1))},
+			kind: &TypeError{},
+			msg:  "requires a boolean argument",
+		},
+		{
+			// CHR('A')
+			expr: Call(Chr, String("A")),
+			kind: &TypeError{},
+		},
 		{
 			expr: &Cast{From: path("y"), To: SymbolType},
 			kind: &SyntaxError{},
@@ -134,9 +156,9 @@ func TestCheckExpressions(t *testing.T) {
 			"SIZE expects",
 		},
 		{
-			&Index{Inner: Ident("z"), Offset: -1},
+			&Index{Inner: &List{Values: []Constant{Null{}, Null{}}}, Offset: -3},
 			&TypeError{},
-			"negative",
+			"index",
 		},
 		{
 			&Index{Inner: &List{Values: []Constant{Null{}, Null{}}}, Offset: 3},
@@ -148,6 +170,40 @@ func TestCheckExpressions(t *testing.T) {
 			&TypeError{},
 			"index",
 		},
+		{
+			&Slice{Inner: String("xyz"), Start: 1},
+			&TypeError{},
+			"cannot slice",
+		},
+		{
+			// TRANSFORM(x, e -> e + y)
+			&Transform{List: path("x"), Param: "e", Body: Add(Ident("e"), Ident("y"))},
+			&SyntaxError{},
+			"y is not bound",
+		},
+		{
+			&Transform{List: String("xyz"), Param: "e", Body: Ident("e")},
+			&TypeError{},
+			"cannot transform",
+		},
+		{
+			// SELECT TRANSLATE(x, y, 'abc')
+			Call(Translate, path("x"), path("y"), String("abc")),
+			&SyntaxError{},
+			"argument 1 is not a string literal",
+		},
+		{
+			// SELECT RANDOM(x)
+			Call(Random, path("x")),
+			&SyntaxError{},
+			"seed must be an integer literal",
+		},
+		{
+			// SELECT RANDOM(1, 2)
+			Call(Random, Integer(1), Integer(2)),
+			&SyntaxError{},
+			"at most 1 argument",
+		},
 		{
 			// SELECT ASSERT_ION_TYPE()
 			Call(AssertIonType),
@@ -205,6 +261,12 @@ func TestCheckValidExpressions(t *testing.T) {
 			// regression test: nullptr dereference on NaN
 			expr: Div(path("x"), NaN),
 		},
+		{
+			expr: AggregateOr(path("x")),
+		},
+		{
+			expr: AggregateAnd(&Cast{From: path("x"), To: IntegerType}),
+		},
 	}
 	for i := range testcases {
 		tc := &testcases[i]
@@ -90,6 +90,10 @@ func getEmpty(name string) (composite, bool) {
 		return &Dot{}, true
 	case "index":
 		return &Index{}, true
+	case "slice":
+		return &Slice{}, true
+	case "transform":
+		return &Transform{}, true
 	case "cmp":
 		return &Comparison{}, true
 	case "stringmatch":
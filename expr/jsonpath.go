@@ -0,0 +1,95 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPathStep is one component of a JSONPath
+// expression accepted by JSON_EXTRACT.
+type JSONPathStep struct {
+	// Field is the name of the object member
+	// selected by this step, or the empty string
+	// if this step selects an array element.
+	Field string
+	// Index is the array element selected
+	// by this step when Field is empty.
+	Index int
+}
+
+// SplitJSONPath splits a JSONPath expression
+// into its components. The accepted syntax is
+// a leading '$' followed by any number of
+// .name, ['name'], or [index] steps.
+func SplitJSONPath(path string) ([]JSONPathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json path %q does not start with '$'", path)
+	}
+	rest := path[1:]
+	var steps []JSONPathStep
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			end := 1
+			for end < len(rest) && rest[end] != '.' && rest[end] != '[' {
+				end++
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("json path %q: empty field name", path)
+			}
+			steps = append(steps, JSONPathStep{Field: rest[1:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("json path %q: unterminated '['", path)
+			}
+			inner := rest[1:end]
+			if len(inner) >= 3 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, JSONPathStep{Field: inner[1 : len(inner)-1]})
+			} else {
+				i, err := strconv.Atoi(inner)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("json path %q: bad array index %q", path, inner)
+				}
+				steps = append(steps, JSONPathStep{Index: i})
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("json path %q: unexpected character %q", path, rest[0])
+		}
+	}
+	return steps, nil
+}
+
+func checkJSONExtract(h Hint, args []Node) error {
+	if len(args) != 2 {
+		return mismatch(2, len(args))
+	}
+	if !TypeOf(args[0], h).AnyOf(StringType) {
+		return errtype(args[0], "not a string")
+	}
+	path, ok := args[1].(String)
+	if !ok {
+		return errsyntaxf("JSON_EXTRACT requires a literal string path, not %v (%T)", args[1], args[1])
+	}
+	if _, err := SplitJSONPath(string(path)); err != nil {
+		return errsyntaxf("JSON_EXTRACT: %s", err)
+	}
+	return nil
+}
@@ -201,6 +201,14 @@ const (
 	// aggregates.
 	OpSystemDatashapeMerge
 
+	// OpMode corresponds to MODE(), the most
+	// frequently occurring number or string
+	OpMode
+
+	// OpCountIf corresponds to COUNT_IF(), which counts
+	// the rows for which a predicate is true
+	OpCountIf
+
 	// anchor for the last aggregate operator
 	maxAggregateOp
 )
@@ -231,6 +239,10 @@ func (a AggregateOp) defaultResult() string {
 		return "max"
 	case OpSystemDatashape:
 		return "datashape"
+	case OpMode:
+		return "mode"
+	case OpCountIf:
+		return "count_if"
 	case OpRowNumber:
 		return "row_number"
 	case OpRank:
@@ -294,6 +306,10 @@ func (a AggregateOp) String() string {
 		return "SNELLER_DATASHAPE"
 	case OpSystemDatashapeMerge:
 		return "SNELLER_DATASHAPE_MERGE"
+	case OpMode:
+		return "MODE"
+	case OpCountIf:
+		return "COUNT_IF"
 	default:
 		return fmt.Sprintf("<AggregateOp=%d>", int(a))
 	}
@@ -305,7 +321,8 @@ func (a AggregateOp) private() bool {
 		OpApproxMedian, OpApproxPercentile,
 		OpMin, OpMax, OpEarliest, OpLatest,
 		OpBitAnd, OpBitOr, OpBitXor, OpBoolAnd, OpBoolOr,
-		OpApproxCountDistinct, OpSystemDatashape, OpRowNumber, OpRank, OpDenseRank:
+		OpApproxCountDistinct, OpSystemDatashape, OpRowNumber, OpRank, OpDenseRank,
+		OpMode, OpCountIf:
 		return false
 	}
 
@@ -643,17 +660,21 @@ func (a *Aggregate) rewrite(r Rewriter) Node {
 
 func (a *Aggregate) typeof(h Hint) TypeSet {
 	switch a.Op {
-	case OpCount, OpCountDistinct, OpSumCount, OpApproxCountDistinct, OpRowNumber, OpRank, OpDenseRank:
+	case OpCount, OpCountDistinct, OpSumCount, OpApproxCountDistinct, OpCountIf, OpRowNumber, OpRank, OpDenseRank:
 		return UnsignedType
 	case OpSumInt:
 		// if the inner type is only ever unsigned,
 		// then the result is only ever unsigned,
 		// etc.
 		return TypeOf(a.Inner, h)
+	case OpBitAnd, OpBitOr, OpBitXor:
+		return IntegerType | NullType
 	case OpLatest, OpEarliest:
 		return TimeType | NullType
 	case OpSystemDatashape:
 		return StructType
+	case OpMode:
+		return NumericType | StringType | NullType
 	default:
 		return NumericType | NullType
 	}
@@ -1314,17 +1335,29 @@ func (i *Index) SetField(f ion.Field) (err error) {
 	return err
 }
 
+// offset returns the position of the indexed
+// element in a list of length n, or false if the
+// index is out of range; negative offsets count
+// backwards from the end of the list
+func (i *Index) offset(n int) (int, bool) {
+	off := i.Offset
+	if off < 0 {
+		off += n
+	}
+	return off, off >= 0 && off < n
+}
+
 // [ v ][0] -> v
 func (i *Index) simplify(h Hint) Node {
 	if b, ok := i.Inner.(*Builtin); ok && b.Func == MakeList {
-		if i.Offset < len(b.Args) {
-			return b.Args[i.Offset]
+		if off, ok := i.offset(len(b.Args)); ok {
+			return b.Args[off]
 		}
 		return Missing{}
 	}
 	if l, ok := i.Inner.(*List); ok {
-		if i.Offset < len(l.Values) {
-			return l.Values[i.Offset]
+		if off, ok := i.offset(len(l.Values)); ok {
+			return l.Values[off]
 		}
 		return Missing{}
 	}
@@ -1346,6 +1379,198 @@ func (i *Index) rewrite(r Rewriter) Node {
 	return i
 }
 
+// Slice is a list slice expression
+//
+//	Inner[Start:End]
+//
+// that selects the elements of a list in the
+// half-open range [Start, End); negative bounds
+// count backwards from the end of the list and
+// out-of-range bounds are clamped to the list
+type Slice struct {
+	Inner Node
+	Start int
+	End   *int // nil means the end of the list
+}
+
+func (s *Slice) text(dst *strings.Builder, redact bool) {
+	s.Inner.text(dst, redact)
+	if s.End == nil {
+		fmt.Fprintf(dst, "[%d:]", s.Start)
+	} else {
+		fmt.Fprintf(dst, "[%d:%d]", s.Start, *s.End)
+	}
+}
+
+func (s *Slice) Encode(dst *ion.Buffer, st *ion.Symtab) {
+	dst.BeginStruct(-1)
+	settype(dst, st, "slice")
+	dst.BeginField(st.Intern("inner"))
+	s.Inner.Encode(dst, st)
+	dst.BeginField(st.Intern("start"))
+	dst.WriteInt(int64(s.Start))
+	if s.End != nil {
+		dst.BeginField(st.Intern("end"))
+		dst.WriteInt(int64(*s.End))
+	}
+	dst.EndStruct()
+}
+
+func (s *Slice) SetField(f ion.Field) (err error) {
+	switch f.Label {
+	case "inner":
+		s.Inner, err = Decode(f.Datum)
+	case "start":
+		var v int64
+		v, err = f.Int()
+		if err == nil {
+			s.Start = int(v)
+		}
+	case "end":
+		var v int64
+		v, err = f.Int()
+		if err == nil {
+			end := int(v)
+			s.End = &end
+		}
+	default:
+		return errUnexpectedField
+	}
+	return err
+}
+
+// bounds returns the half-open range of the
+// elements selected from a list of length n
+func (s *Slice) bounds(n int) (int, int) {
+	clamp := func(off int) int {
+		if off < 0 {
+			off += n
+		}
+		return min(max(off, 0), n)
+	}
+	start, end := clamp(s.Start), n
+	if s.End != nil {
+		end = clamp(*s.End)
+	}
+	return start, max(start, end)
+}
+
+// [a, b, c][1:] -> [b, c]
+func (s *Slice) simplify(h Hint) Node {
+	if b, ok := s.Inner.(*Builtin); ok && b.Func == MakeList {
+		start, end := s.bounds(len(b.Args))
+		return Call(MakeList, b.Args[start:end]...)
+	}
+	if l, ok := s.Inner.(*List); ok {
+		start, end := s.bounds(len(l.Values))
+		return &List{Values: l.Values[start:end]}
+	}
+	return s
+}
+
+func (s *Slice) typeof(h Hint) TypeSet {
+	return ListType | MissingType
+}
+
+func (s *Slice) Equals(x Node) bool {
+	s2, ok := x.(*Slice)
+	if !ok || s.Start != s2.Start || (s.End == nil) != (s2.End == nil) {
+		return false
+	}
+	if s.End != nil && *s.End != *s2.End {
+		return false
+	}
+	return s.Inner.Equals(s2.Inner)
+}
+
+func (s *Slice) walk(v Visitor) {
+	Walk(v, s.Inner)
+}
+
+func (s *Slice) rewrite(r Rewriter) Node {
+	s.Inner = Rewrite(r, s.Inner)
+	return s
+}
+
+// Transform is a list transformation
+//
+//	TRANSFORM(List, Param -> Body)
+//
+// that evaluates Body for each element of List
+// with Param bound to the element and collects
+// the results into a new list; results that are
+// MISSING are omitted from the output list
+//
+// Body may only reference Param; it is not
+// visited by Walk or Rewrite, since Param shadows
+// any binding of the same name in the enclosing query
+type Transform struct {
+	List  Node
+	Param string
+	Body  Node
+}
+
+func (t *Transform) text(dst *strings.Builder, redact bool) {
+	dst.WriteString("TRANSFORM(")
+	t.List.text(dst, redact)
+	dst.WriteString(", ")
+	dst.WriteString(QuoteID(t.Param))
+	dst.WriteString(" -> ")
+	t.Body.text(dst, redact)
+	dst.WriteString(")")
+}
+
+func (t *Transform) Encode(dst *ion.Buffer, st *ion.Symtab) {
+	dst.BeginStruct(-1)
+	settype(dst, st, "transform")
+	dst.BeginField(st.Intern("list"))
+	t.List.Encode(dst, st)
+	dst.BeginField(st.Intern("param"))
+	dst.WriteString(t.Param)
+	dst.BeginField(st.Intern("body"))
+	t.Body.Encode(dst, st)
+	dst.EndStruct()
+}
+
+func (t *Transform) SetField(f ion.Field) (err error) {
+	switch f.Label {
+	case "list":
+		t.List, err = Decode(f.Datum)
+	case "param":
+		t.Param, err = f.String()
+	case "body":
+		t.Body, err = Decode(f.Datum)
+	default:
+		return errUnexpectedField
+	}
+	return err
+}
+
+func (t *Transform) simplify(h Hint) Node {
+	t.Body = Simplify(t.Body, NoHint)
+	return t
+}
+
+func (t *Transform) typeof(h Hint) TypeSet {
+	return ListType | MissingType
+}
+
+func (t *Transform) Equals(x Node) bool {
+	t2, ok := x.(*Transform)
+	return ok && t.Param == t2.Param &&
+		t.List.Equals(t2.List) &&
+		t.Body.Equals(t2.Body)
+}
+
+func (t *Transform) walk(v Visitor) {
+	Walk(v, t.List)
+}
+
+func (t *Transform) rewrite(r Rewriter) Node {
+	t.List = Rewrite(r, t.List)
+	return t
+}
+
 // Star represents the '*' path component
 type Star struct{}
 
@@ -1634,8 +1859,88 @@ func allConst(lst []Node) bool {
 // In yields an expression equivalent to
 //
 //	<val> IN (cmp ...)
+//
+// If cmp contains NULL, then the result
+// is NULL rather than FALSE when val
+// does not match any other element of cmp.
 func In(val Node, cmp ...Node) Node {
-	if len(cmp) >= 1 && allConst(cmp) {
+	cmp, null := withoutNull(cmp)
+	if len(cmp) == 0 {
+		return Null{}
+	}
+	ret := in(val, cmp)
+	if null {
+		return unknownUnless(ret, true)
+	}
+	return ret
+}
+
+// NotIn yields an expression equivalent to
+//
+//	<val> NOT IN (cmp ...)
+//
+// If cmp contains NULL, then the result
+// is NULL rather than TRUE when val
+// does not match any other element of cmp.
+func NotIn(val Node, cmp ...Node) Node {
+	cmp, null := withoutNull(cmp)
+	if len(cmp) == 0 {
+		return Null{}
+	}
+	ret := in(val, cmp)
+	if null {
+		return unknownUnless(ret, false)
+	}
+	return &Not{Expr: ret}
+}
+
+// InSet yields an expression equivalent to
+//
+//	<val> IN (set ...)
+//
+// where set may contain NULL.
+func InSet(val Node, set *ion.Bag) Node {
+	mem := &Member{Arg: val}
+	null := false
+	set.Each(func(d ion.Datum) bool {
+		if d.IsNull() {
+			null = true
+		} else {
+			mem.Set.AddDatum(d)
+		}
+		return true
+	})
+	if !null {
+		return mem
+	}
+	if mem.Set.Len() == 0 {
+		return Null{}
+	}
+	return unknownUnless(mem, true)
+}
+
+func withoutNull(cmp []Node) ([]Node, bool) {
+	null := false
+	for i := 0; i < len(cmp); i++ {
+		if _, ok := cmp[i].(Null); ok {
+			null = true
+			break
+		}
+	}
+	if !null {
+		return cmp, false
+	}
+	ret := make([]Node, 0, len(cmp)-1)
+	for i := range cmp {
+		if _, ok := cmp[i].(Null); !ok {
+			ret = append(ret, cmp[i])
+		}
+	}
+	return ret, true
+}
+
+func in(val Node, cmp []Node) Node {
+	if allConst(cmp) {
 		mem := &Member{Arg: val}
 		for i := range cmp {
 			mem.Set.AddDatum(cmp[i].(Constant).Datum())
@@ -1651,6 +1956,19 @@ func In(val Node, cmp ...Node) Node {
 	return top
 }
 
+// unknownUnless yields
+//
+//	CASE WHEN <match> THEN <result> ELSE NULL END
+//
+// which is the result of a membership test
+// against a set containing NULL
+func unknownUnless(match Node, result Bool) Node {
+	return &Case{
+		Limbs: []CaseLimb{{When: match, Then: result}},
+		Else:  Null{},
+	}
+}
+
 // Lookup is an associative array lookup operation
 // against a constant table.
 type Lookup struct {
@@ -3791,6 +4109,10 @@ const (
 
 	// ExplainGraphviz returns plan in graphviz format
 	ExplainGraphviz
+
+	// ExplainAnalyze executes the query and returns
+	// the plan annotated with per-operator statistics
+	ExplainAnalyze
 )
 
 // UnionType describes type of union expression
@@ -210,6 +210,28 @@ func (s *scanner) peek() byte {
 	return s.from[s.pos]
 }
 
+// parenfollows returns whether the next
+// non-whitespace character is '('
+func (s *scanner) parenfollows() bool {
+	for i := s.pos; i < len(s.from); i++ {
+		if !isspace(s.from[i]) {
+			return s.from[i] == '('
+		}
+	}
+	return false
+}
+
+// outerfollows returns true if the
+// last symbol may be followed by OUTER
+func (s *scanner) outerfollows() bool {
+	switch s.lastsym {
+	case LEFT, RIGHT, FULL:
+		return true
+	default:
+		return false
+	}
+}
+
 func (s *scanner) peekat(i int) byte {
 	if s.pos+i < len(s.from) {
 		return s.from[s.pos+i]
@@ -343,7 +365,15 @@ func (s *scanner) lex(l *yySymType) int {
 		s.notkw = false
 		s.pos++
 		return int(b)
-	case ',', '*', '-', '/', '%', ':', '&', '^', '[', ']', '(', ')', '{', '}':
+	case '-':
+		if s.peekat(1) == '>' {
+			s.pos += 2
+			return ARROW
+		}
+		s.notkw = false
+		s.pos++
+		return int(b)
+	case ',', '*', '/', '%', ':', '&', '^', '[', ']', '(', ')', '{', '}':
 		// literal operators
 		s.notkw = false
 		s.pos++
@@ -401,8 +431,13 @@ func (s *scanner) lexIdent(l *yySymType) int {
 		// don't perform string allocation if we have a keyword
 		term, enum := lookupKeyword(s.from[startpos:s.pos])
 		if term == AGGREGATE {
-			l.integer = enum
-			return AGGREGATE
+			// an aggregate name that isn't followed by
+			// an argument list is an ordinary identifier
+			// (so that a column can be named 'mode' or 'count')
+			if s.parenfollows() {
+				l.integer = enum
+				return AGGREGATE
+			}
 		} else if term != -1 {
 			// SQL keyword following AS or BY, interpret the
 			// next word as a case-sensitive identifier
@@ -411,6 +446,10 @@ func (s *scanner) lexIdent(l *yySymType) int {
 				s.notkw = true
 			}
 			return term
+		} else if s.outerfollows() && equalASCII(s.from[startpos:s.pos], []byte("OUTER")) {
+			// OUTER is only a keyword in LEFT/RIGHT/FULL OUTER JOIN
+			// (so that a table can still be bound as 'outer')
+			return OUTER
 		}
 	}
 	s.notkw = s.notkw || !wordend
@@ -434,7 +473,7 @@ func (s *scanner) lexNumber(l *yySymType) int {
 			return false
 
 		// operators
-		case '(', ')', '[', ']', '{', '}', '*', '/', '%', '&', '!', '^', '~', '|', ',':
+		case '(', ')', '[', ']', '{', '}', '*', '/', '%', '&', '!', '^', '~', '|', ',', ':':
 			return false
 
 		case '-', '+':
@@ -763,6 +802,8 @@ func parseExplain(s string) (expr.ExplainFormat, error) {
 		return expr.ExplainList, nil
 	case "gv", "graphviz":
 		return expr.ExplainGraphviz, nil
+	case "analyze":
+		return expr.ExplainAnalyze, nil
 	}
 
 	return expr.ExplainNone, fmt.Errorf("%q is a wrong explain type", s)
@@ -108,6 +108,9 @@ func lookupKeyword(word []byte) (int, int) {
 			if equalASCIILetters4([4]byte(word), [4]byte{'F', 'R', 'O', 'M'}) {
 				return FROM, -1
 			}
+			if equalASCIILetters4([4]byte(word), [4]byte{'F', 'U', 'L', 'L'}) {
+				return FULL, -1
+			}
 		case 'I':
 			if equalASCIILetters4([4]byte(word), [4]byte{'I', 'N', 'T', 'O'}) {
 				return INTO, -1
@@ -126,6 +129,10 @@ func lookupKeyword(word []byte) (int, int) {
 			if equalASCIILetters4([4]byte(word), [4]byte{'L', 'A', 'S', 'T'}) {
 				return LAST, -1
 			}
+		case 'M':
+			if equalASCIILetters4([4]byte(word), [4]byte{'M', 'O', 'D', 'E'}) {
+				return AGGREGATE, int(expr.OpMode)
+			}
 		case 'N':
 			if equalASCIILetters4([4]byte(word), [4]byte{'N', 'U', 'L', 'L'}) {
 				return NULL, -1
@@ -306,37 +313,41 @@ func lookupKeyword(word []byte) (int, int) {
 			}
 		}
 	case 8:
-		switch asciiUpper(word[0]) {
-		case 'B':
-			if equalASCII(word, []byte("BOOL_AND")) {
-				return AGGREGATE, int(expr.OpBoolAnd)
-			}
-		case 'C':
-			if equalASCIILetters8([8]byte(word), [8]byte{'C', 'O', 'A', 'L', 'E', 'S', 'C', 'E'}) {
-				return COALESCE, -1
-			}
-		case 'D':
+		switch asciiUpper(word[5]) {
+		case 'A':
 			if equalASCII(word, []byte("DATE_ADD")) {
 				return DATE_ADD, -1
 			}
+			if equalASCII(word, []byte("BOOL_AND")) {
+				return AGGREGATE, int(expr.OpBoolAnd)
+			}
+		case 'B':
 			if equalASCII(word, []byte("DATE_BIN")) {
 				return DATE_BIN, -1
 			}
-			if equalASCIILetters8([8]byte(word), [8]byte{'D', 'I', 'S', 'T', 'I', 'N', 'C', 'T'}) {
-				return DISTINCT, -1
-			}
 		case 'E':
 			if equalASCIILetters8([8]byte(word), [8]byte{'E', 'A', 'R', 'L', 'I', 'E', 'S', 'T'}) {
 				return AGGREGATE, int(expr.OpEarliest)
 			}
-		case 'T':
+		case 'I':
 			if equalASCIILetters8([8]byte(word), [8]byte{'T', 'R', 'A', 'I', 'L', 'I', 'N', 'G'}) {
 				return TRAILING, -1
 			}
-		case 'V':
+		case 'N':
+			if equalASCIILetters8([8]byte(word), [8]byte{'D', 'I', 'S', 'T', 'I', 'N', 'C', 'T'}) {
+				return DISTINCT, -1
+			}
 			if equalASCIILetters8([8]byte(word), [8]byte{'V', 'A', 'R', 'I', 'A', 'N', 'C', 'E'}) {
 				return AGGREGATE, int(expr.OpVariancePop)
 			}
+		case 'S':
+			if equalASCIILetters8([8]byte(word), [8]byte{'C', 'O', 'A', 'L', 'E', 'S', 'C', 'E'}) {
+				return COALESCE, -1
+			}
+		case '_':
+			if equalASCII(word, []byte("COUNT_IF")) {
+				return AGGREGATE, int(expr.OpCountIf)
+			}
 		}
 	case 9:
 		if equalASCII(word, []byte("DATE_DIFF")) {
@@ -441,4 +452,4 @@ func equalASCIILetters9(anyCase [9]byte, upperCaseLetters [9]byte) bool {
 	return true
 }
 
-// checksum: e31bf3b2a31f75afe8eebbceb182f14b
+// checksum: 2fefb57b0d3e830e5ee49b247fb6ad5f
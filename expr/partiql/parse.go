@@ -81,7 +81,7 @@ func buildCast(inner expr.Node, id string) (expr.Node, bool) {
 		ts = expr.MissingType
 	case "TIMESTAMP":
 		ts = expr.TimeType
-	case "STRING":
+	case "STRING", "VARCHAR":
 		ts = expr.StringType
 	case "DECIMAL":
 		ts = expr.DecimalType
@@ -283,6 +283,19 @@ const (
 	trimBoth
 )
 
+// createLambdaInvocation creates the invocation
+// of a function that accepts a lambda argument
+// (currently only TRANSFORM(list, x -> body)).
+func createLambdaInvocation(name string, args []expr.Node, param string, body expr.Node) (expr.Node, error) {
+	if !strings.EqualFold(name, "TRANSFORM") {
+		return nil, fmt.Errorf("%s does not accept a lambda argument", name)
+	}
+	if len(args) != 1 {
+		return nil, fmt.Errorf("TRANSFORM expects 2 arguments, got %d", len(args)+1)
+	}
+	return &expr.Transform{List: args[0], Param: param, Body: body}, nil
+}
+
 // createTrimInvocation creates trim/ltrim/rtrim invocation from an SQL query.
 func createTrimInvocation(trimType int, str, charset expr.Node) (expr.Node, error) {
 	op := expr.Unspecified
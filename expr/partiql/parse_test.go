@@ -32,6 +32,8 @@ var sameq = []string{
 	"SELECT x FROM table WHERE x.y.z = 'foo'",
 	"SELECT x FROM table WHERE x[0] = 'foo'",
 	"SELECT x FROM table WHERE x[0][1] = 'foo'",
+	"SELECT x[-1], x[1:3], x[-2:], x[0:-1][0] FROM table",
+	"SELECT TRANSFORM(t.lst, x -> x * 2) FROM table AS t",
 	"SELECT x FROM 'string' WHERE x[0].y[3] = 'foo'",
 	"SELECT x FROM table AS t WHERE 'foo' = 'bar'",
 	`SELECT * FROM NDJSON('{"foo": 1, "bar": 2}')`,
@@ -53,6 +55,8 @@ var sameq = []string{
 	"SELECT MIN(lo), MAX(hi) AS \"limit\" FROM table WHERE x <> 3 GROUP BY x LIMIT 100",
 	"SELECT l.x, r.y FROM 'first' AS l JOIN second AS r ON l.id = r.id",
 	"SELECT o.field, i.other FROM 'outer' AS o CROSS JOIN 'inner' AS i WHERE o.foo = i.bar",
+	"SELECT t.x, v FROM table AS t CROSS JOIN UNNEST(t.lst) AS v",
+	"SELECT t.x, v FROM table AS t LEFT JOIN UNNEST(t.lst) AS v ON TRUE",
 	"SELECT DISTINCT x, y, z FROM table ORDER BY x ASC NULLS FIRST",
 	"SELECT x, MIN(y) FROM table GROUP BY x ORDER BY MIN(y) DESC NULLS FIRST LIMIT 1",
 	"SELECT t.x, t.y IS MISSING <> t.x IS MISSING FROM table AS t",
@@ -87,10 +91,13 @@ var sameq = []string{
 	"SELECT TRIM(x, y) FROM table",
 	`SELECT APPROX_COUNT_DISTINCT(x) FROM table`,
 	`SELECT APPROX_COUNT_DISTINCT(x, 5) FROM table`,
+	`SELECT MODE(x) FROM table`,
+	`SELECT COUNT_IF(x > 0) AS "count_if" FROM table`,
 	`EXPLAIN SELECT * FROM table`,
 	`EXPLAIN AS text SELECT * FROM table`,
 	`EXPLAIN AS list SELECT * FROM table`,
 	`EXPLAIN AS graphviz SELECT * FROM table`,
+	`EXPLAIN AS analyze SELECT * FROM table`,
 	`SELECT SNELLER_DATASHAPE(*) FROM table`,
 	`SELECT * FROM table1 UNION SELECT * FROM table2`,
 	`SELECT * FROM table1 UNION ALL SELECT * FROM table2`,
@@ -202,6 +209,16 @@ func TestParseNormalization(t *testing.T) {
 			"select {'x': 2}.x",
 			"SELECT 2",
 		},
+		{
+			// aggregate names without arguments are identifiers
+			"select mode, count (x), count from foo order by mode",
+			`SELECT "mode", COUNT(x), "count" FROM foo ORDER BY "mode" ASC NULLS FIRST`,
+		},
+		{
+			// OUTER is a keyword only after LEFT, RIGHT or FULL
+			"select outer.x, b.y from t as outer left outer join u as b on outer.x = b.x full outer join v as c on outer.x = c.x",
+			`SELECT outer.x, b.y FROM t AS outer LEFT JOIN u AS b ON outer.x = b.x FULL JOIN v AS c ON outer.x = c.x`,
+		},
 		{
 			// test parens
 			"select * from foo where ((a IS NULL) AND b IS NULL) OR c IS NULL",
@@ -217,6 +234,15 @@ func TestParseNormalization(t *testing.T) {
 			`select * from table where x IN (1)`,
 			`SELECT * FROM table WHERE x = 1`,
 		},
+		{
+			`select * from table where x NOT IN (1, 2)`,
+			`SELECT * FROM table WHERE !(x = 1 OR x = 2)`,
+		},
+		{
+			// NOT IN is never TRUE if the list contains NULL
+			`select * from table where x NOT IN (1, NULL)`,
+			`SELECT * FROM table WHERE CASE WHEN x = 1 THEN FALSE ELSE NULL END`,
+		},
 		{
 			// test COALESCE -> CASE
 			`SELECT COALESCE(x, y) FROM foo`,
@@ -246,6 +272,10 @@ func TestParseNormalization(t *testing.T) {
 			"SELECT * FROM foo WHERE x IN (SELECT COUNT(x) FROM foo ORDER BY COUNT(x) DESC NULLS FIRST LIMIT 5)",
 			"SELECT * FROM foo WHERE IN_SUBQUERY(x, (SELECT COUNT(x) FROM foo ORDER BY COUNT(x) DESC NULLS FIRST LIMIT 5))",
 		},
+		{
+			"SELECT * FROM foo WHERE x NOT IN (SELECT y FROM bar LIMIT 5)",
+			"SELECT * FROM foo WHERE !(IN_SUBQUERY(x, (SELECT y FROM bar LIMIT 5)))",
+		},
 		{
 			"SELECT * FROM t1 ++ t2 ++ t3 WHERE foo = bar",
 			"SELECT * FROM (t1 ++ t2 ++ t3) WHERE foo = bar",
@@ -74,6 +74,7 @@ import (
 %token <str> ID
 %token <empty> '(' ',' ')' '[' ']' '{' '}'
 %token <empty> NULL TRUE FALSE MISSING
+%token <empty> ARROW
 
 %left OR
 %left AND
@@ -199,6 +200,9 @@ ION { $$ = $1 } |
 '[' any_value_list ']' { $$ = expr.Call(expr.MakeList, $2...) } |
 datum '.' identifier { $$ = &expr.Dot{Inner: $1, Field: $3} } |
 datum '[' literal_int ']' { $$ = &expr.Index{Inner: $1, Offset: $3} } |
+datum '[' literal_int ':' literal_int ']' { end := $5; $$ = &expr.Slice{Inner: $1, Start: $3, End: &end} } |
+datum '[' literal_int ':' ']' { $$ = &expr.Slice{Inner: $1, Start: $3} } |
+datum '[' ':' literal_int ']' { end := $4; $$ = &expr.Slice{Inner: $1, End: &end} } |
 datum '[' STRING ']' { $$ = &expr.Dot{Inner: $1, Field: $3} }
 
 // datum_or_parens is guaranteed to
@@ -369,6 +373,14 @@ datum_or_parens
   }
   $$ = op
 }
+| identifier '(' value_list ',' identifier ARROW expr ')'
+{
+  node, err := createLambdaInvocation($1, $3, $5, $7)
+  if err != nil {
+    yylex.Error(err.Error())
+  }
+  $$ = node
+}
 | expr IN '(' select_stmt ')'
 {
   $$ = expr.Call(expr.InSubquery, $1, $4)
@@ -377,6 +389,14 @@ datum_or_parens
 {
   $$ = expr.In($1, $4...)
 }
+| expr NOT IN '(' select_stmt ')'
+{
+  $$ = &expr.Not{Expr: expr.Call(expr.InSubquery, $1, $5)}
+}
+| expr NOT IN '(' value_list ')'
+{
+  $$ = expr.NotIn($1, $5...)
+}
 | EXISTS '(' select_stmt ')'
 {
   $$ = exists($3)
@@ -623,7 +643,8 @@ LEFT JOIN { $$  = expr.LeftJoin } |
 LEFT OUTER JOIN { $$ = expr.LeftJoin } |
 RIGHT JOIN { $$ = expr.RightJoin } |
 RIGHT OUTER JOIN { $$ = expr.RightJoin } |
-FULL JOIN { $$ = expr.FullJoin }
+FULL JOIN { $$ = expr.FullJoin } |
+FULL OUTER JOIN { $$ = expr.FullJoin }
 
 cross_symbol: ',' | CROSS JOIN
 
@@ -105,41 +105,42 @@ const NULL = 57399
 const TRUE = 57400
 const FALSE = 57401
 const MISSING = 57402
-const OR = 57403
-const AND = 57404
-const NOT = 57405
-const BETWEEN = 57406
-const CASE = 57407
-const WHEN = 57408
-const THEN = 57409
-const ELSE = 57410
-const END = 57411
-const TO = 57412
-const TRIM = 57413
-const EQ = 57414
-const NE = 57415
-const LT = 57416
-const LE = 57417
-const GT = 57418
-const GE = 57419
-const SIMILAR = 57420
-const REGEXP_MATCH_CI = 57421
-const ILIKE = 57422
-const LIKE = 57423
-const IN = 57424
-const IS = 57425
-const OVER = 57426
-const FILTER = 57427
-const ESCAPE = 57428
-const SHIFT_LEFT_LOGICAL = 57429
-const SHIFT_RIGHT_ARITHMETIC = 57430
-const SHIFT_RIGHT_LOGICAL = 57431
-const CONCAT = 57432
-const APPEND = 57433
-const NEGATION_PRECEDENCE = 57434
-const NUMBER = 57435
-const ION = 57436
-const STRING = 57437
+const ARROW = 57403
+const OR = 57404
+const AND = 57405
+const NOT = 57406
+const BETWEEN = 57407
+const CASE = 57408
+const WHEN = 57409
+const THEN = 57410
+const ELSE = 57411
+const END = 57412
+const TO = 57413
+const TRIM = 57414
+const EQ = 57415
+const NE = 57416
+const LT = 57417
+const LE = 57418
+const GT = 57419
+const GE = 57420
+const SIMILAR = 57421
+const REGEXP_MATCH_CI = 57422
+const ILIKE = 57423
+const LIKE = 57424
+const IN = 57425
+const IS = 57426
+const OVER = 57427
+const FILTER = 57428
+const ESCAPE = 57429
+const SHIFT_LEFT_LOGICAL = 57430
+const SHIFT_RIGHT_ARITHMETIC = 57431
+const SHIFT_RIGHT_LOGICAL = 57432
+const CONCAT = 57433
+const APPEND = 57434
+const NEGATION_PRECEDENCE = 57435
+const NUMBER = 57436
+const ION = 57437
+const STRING = 57438
 
 var yyToknames = [...]string{
 	"$end",
@@ -209,6 +210,7 @@ var yyToknames = [...]string{
 	"TRUE",
 	"FALSE",
 	"MISSING",
+	"ARROW",
 	"OR",
 	"AND",
 	"'!'",
@@ -273,407 +275,418 @@ var yyExca = [...]int8{
 
 const yyPrivate = 57344
 
-const yyLast = 2001
+const yyLast = 2036
 
 var yyAct = [...]int16{
-	25, 383, 205, 379, 184, 352, 368, 324, 245, 300,
-	280, 28, 218, 125, 134, 211, 207, 331, 206, 330,
-	23, 24, 76, 77, 78, 79, 80, 81, 82, 299,
-	295, 101, 71, 72, 73, 75, 74, 76, 77, 78,
-	79, 80, 81, 82, 114, 115, 116, 118, 298, 123,
-	294, 126, 240, 239, 237, 236, 234, 189, 128, 72,
-	73, 75, 74, 76, 77, 78, 79, 80, 81, 82,
-	159, 142, 143, 144, 145, 146, 147, 148, 149, 150,
-	151, 152, 153, 154, 133, 158, 20, 137, 41, 160,
-	161, 162, 163, 164, 165, 11, 13, 172, 173, 18,
-	156, 155, 207, 185, 186, 187, 166, 120, 62, 81,
-	82, 297, 194, 185, 68, 233, 232, 200, 73, 75,
-	74, 76, 77, 78, 79, 80, 81, 82, 246, 301,
-	185, 238, 157, 170, 214, 122, 78, 79, 80, 81,
-	82, 305, 185, 251, 183, 252, 231, 131, 217, 169,
-	171, 168, 167, 235, 47, 139, 140, 119, 229, 85,
-	87, 83, 84, 69, 98, 273, 272, 181, 70, 71,
-	72, 73, 75, 74, 76, 77, 78, 79, 80, 81,
-	82, 248, 213, 139, 253, 212, 210, 174, 177, 178,
-	176, 209, 14, 12, 48, 175, 267, 57, 385, 56,
-	201, 52, 50, 51, 53, 304, 303, 179, 204, 255,
-	293, 343, 275, 61, 276, 255, 277, 215, 255, 268,
-	282, 138, 339, 12, 274, 292, 136, 57, 230, 56,
-	279, 52, 50, 51, 53, 241, 243, 244, 242, 255,
-	254, 278, 283, 284, 269, 261, 262, 296, 49, 55,
-	54, 216, 306, 307, 208, 132, 309, 310, 193, 312,
-	313, 314, 66, 316, 317, 255, 318, 319, 390, 224,
-	226, 227, 223, 225, 65, 228, 65, 365, 49, 55,
-	54, 222, 260, 259, 258, 10, 332, 302, 141, 130,
-	323, 270, 271, 129, 113, 112, 111, 110, 109, 108,
-	107, 106, 12, 65, 105, 335, 104, 103, 102, 337,
-	99, 60, 334, 315, 311, 192, 191, 190, 188, 327,
-	58, 348, 289, 329, 139, 287, 354, 290, 356, 328,
-	288, 351, 291, 286, 359, 285, 358, 361, 202, 321,
-	322, 362, 363, 364, 360, 396, 203, 355, 397, 398,
-	59, 22, 16, 19, 7, 17, 3, 367, 6, 325,
-	380, 369, 372, 371, 21, 370, 377, 326, 353, 281,
-	333, 384, 381, 185, 378, 63, 219, 386, 263, 136,
-	22, 9, 388, 389, 42, 15, 220, 2, 195, 182,
-	221, 384, 394, 382, 196, 197, 198, 31, 32, 38,
-	37, 33, 39, 34, 35, 36, 247, 124, 127, 357,
-	349, 350, 135, 8, 180, 395, 391, 29, 12, 48,
-	5, 4, 57, 117, 56, 27, 52, 50, 51, 53,
-	121, 250, 100, 45, 44, 64, 30, 1, 0, 0,
-	0, 0, 40, 42, 0, 0, 0, 0, 0, 46,
-	0, 0, 0, 0, 0, 0, 31, 32, 38, 37,
-	33, 39, 34, 35, 36, 43, 266, 0, 0, 0,
-	0, 0, 0, 49, 55, 54, 29, 12, 48, 0,
-	0, 57, 0, 56, 0, 52, 50, 51, 53, 0,
-	0, 0, 45, 44, 0, 30, 0, 0, 0, 0,
-	0, 40, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 265, 264, 0, 0,
-	0, 0, 0, 0, 43, 26, 97, 96, 0, 86,
-	95, 94, 49, 55, 54, 0, 0, 0, 0, 88,
-	89, 90, 91, 92, 93, 85, 87, 83, 84, 69,
-	98, 0, 0, 0, 70, 71, 72, 73, 75, 74,
-	76, 77, 78, 79, 80, 81, 82, 42, 0, 0,
+	25, 403, 206, 399, 185, 370, 387, 23, 337, 309,
+	248, 286, 220, 28, 125, 134, 276, 213, 347, 24,
+	73, 74, 76, 75, 77, 78, 79, 80, 81, 82,
+	83, 101, 209, 346, 208, 207, 307, 306, 305, 20,
+	126, 242, 239, 238, 114, 115, 116, 118, 236, 123,
+	77, 78, 79, 80, 81, 82, 83, 235, 128, 190,
+	165, 62, 164, 162, 161, 334, 209, 82, 83, 304,
+	277, 133, 148, 149, 150, 151, 152, 153, 154, 155,
+	156, 157, 158, 159, 160, 137, 120, 303, 122, 241,
+	166, 167, 168, 169, 170, 171, 240, 173, 174, 41,
+	131, 249, 310, 186, 187, 188, 11, 13, 237, 172,
+	18, 163, 195, 186, 314, 184, 209, 201, 79, 80,
+	81, 82, 83, 243, 254, 68, 255, 47, 368, 335,
+	186, 212, 348, 112, 216, 258, 211, 119, 175, 178,
+	179, 177, 186, 146, 365, 215, 233, 176, 214, 219,
+	279, 258, 345, 202, 313, 312, 231, 405, 42, 145,
+	147, 144, 143, 142, 46, 359, 139, 140, 258, 300,
+	217, 31, 32, 38, 37, 33, 39, 34, 35, 36,
+	355, 232, 251, 14, 344, 256, 244, 246, 247, 245,
+	299, 29, 12, 48, 138, 139, 57, 270, 56, 284,
+	52, 50, 51, 53, 61, 258, 283, 182, 45, 44,
+	278, 30, 272, 271, 281, 273, 282, 40, 136, 205,
+	12, 48, 288, 410, 57, 218, 56, 280, 52, 50,
+	51, 53, 285, 258, 257, 186, 264, 265, 65, 302,
+	43, 26, 289, 290, 210, 194, 132, 180, 49, 55,
+	54, 383, 263, 262, 261, 315, 316, 308, 66, 318,
+	319, 10, 321, 322, 323, 311, 325, 326, 65, 327,
+	328, 234, 141, 318, 301, 12, 49, 55, 54, 57,
+	333, 56, 130, 52, 50, 51, 53, 74, 76, 75,
+	77, 78, 79, 80, 81, 82, 83, 336, 12, 65,
+	129, 113, 112, 274, 275, 226, 228, 229, 225, 227,
+	111, 230, 110, 109, 351, 108, 324, 224, 353, 107,
+	106, 105, 104, 350, 103, 102, 99, 60, 320, 193,
+	364, 49, 55, 54, 192, 191, 189, 340, 58, 372,
+	343, 374, 342, 139, 297, 369, 295, 373, 341, 298,
+	377, 296, 292, 379, 291, 376, 293, 380, 381, 382,
+	378, 294, 203, 331, 417, 418, 385, 416, 332, 59,
+	204, 16, 330, 22, 19, 386, 7, 17, 3, 6,
+	400, 390, 388, 338, 396, 391, 21, 389, 339, 371,
+	404, 401, 186, 398, 63, 287, 406, 349, 221, 266,
+	136, 22, 408, 409, 42, 9, 15, 222, 2, 196,
+	183, 404, 414, 223, 197, 198, 199, 31, 32, 38,
+	37, 33, 39, 34, 35, 36, 402, 250, 124, 127,
+	375, 366, 367, 135, 8, 181, 415, 29, 12, 48,
+	411, 5, 57, 4, 56, 117, 52, 50, 51, 53,
+	27, 121, 253, 100, 45, 44, 64, 30, 1, 0,
+	0, 0, 0, 40, 0, 0, 0, 0, 0, 22,
+	72, 73, 74, 76, 75, 77, 78, 79, 80, 81,
+	82, 83, 0, 0, 42, 0, 43, 0, 0, 0,
+	0, 0, 0, 0, 49, 55, 54, 31, 32, 38,
+	37, 33, 39, 34, 35, 36, 269, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 29, 12, 48,
+	0, 0, 57, 0, 56, 0, 52, 50, 51, 53,
+	0, 0, 0, 0, 45, 44, 0, 30, 0, 0,
+	0, 0, 0, 40, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 268, 267, 0, 0,
+	0, 0, 0, 0, 0, 0, 43, 97, 96, 0,
+	87, 70, 95, 0, 49, 55, 54, 0, 0, 0,
+	89, 90, 91, 92, 93, 94, 86, 88, 84, 85,
+	69, 98, 0, 0, 0, 71, 72, 73, 74, 76,
+	75, 77, 78, 79, 80, 81, 82, 83, 42, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	31, 32, 38, 37, 33, 39, 34, 35, 36, 0,
+	0, 31, 32, 38, 37, 33, 39, 34, 35, 36,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	29, 12, 48, 0, 0, 57, 0, 56, 0, 52,
-	50, 51, 53, 0, 0, 0, 45, 44, 0, 30,
-	0, 0, 0, 0, 0, 40, 0, 0, 0, 0,
-	0, 22, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 42, 0, 43, 249,
-	0, 0, 0, 0, 0, 0, 49, 55, 54, 31,
-	32, 38, 37, 33, 39, 34, 35, 36, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 0, 29,
-	12, 48, 0, 0, 57, 0, 56, 0, 52, 50,
-	51, 53, 0, 0, 0, 45, 44, 0, 30, 0,
-	0, 0, 0, 0, 40, 42, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 31, 32,
-	38, 37, 33, 39, 34, 35, 36, 43, 0, 0,
-	0, 0, 0, 0, 0, 49, 55, 54, 29, 12,
-	48, 0, 199, 57, 0, 56, 0, 52, 50, 51,
-	53, 0, 0, 0, 45, 44, 0, 30, 0, 0,
-	0, 0, 0, 40, 42, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 31, 32, 38,
-	37, 33, 39, 34, 35, 36, 43, 0, 0, 0,
-	0, 0, 0, 0, 49, 55, 54, 29, 12, 48,
-	0, 0, 57, 0, 56, 0, 52, 50, 51, 53,
-	0, 0, 0, 45, 44, 0, 30, 392, 393, 0,
-	0, 0, 40, 0, 0, 0, 0, 0, 0, 0,
+	0, 29, 12, 48, 0, 0, 57, 0, 56, 0,
+	52, 50, 51, 53, 0, 0, 0, 0, 45, 44,
+	0, 30, 0, 0, 0, 0, 0, 40, 42, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 43, 0, 0, 0, 0,
-	0, 0, 0, 49, 55, 54, 0, 0, 0, 97,
-	96, 0, 86, 95, 94, 67, 0, 0, 0, 0,
-	0, 0, 88, 89, 90, 91, 92, 93, 85, 87,
-	83, 84, 69, 98, 0, 0, 0, 70, 71, 72,
-	73, 75, 74, 76, 77, 78, 79, 80, 81, 82,
-	12, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 97, 96, 0, 86, 95, 94, 0, 0,
-	0, 0, 0, 0, 0, 88, 89, 90, 91, 92,
-	93, 85, 87, 83, 84, 69, 98, 0, 0, 0,
-	70, 71, 72, 73, 75, 74, 76, 77, 78, 79,
-	80, 81, 82, 387, 0, 0, 0, 0, 0, 0,
-	0, 0, 97, 96, 0, 86, 95, 94, 0, 0,
-	0, 0, 0, 0, 0, 88, 89, 90, 91, 92,
-	93, 85, 87, 83, 84, 69, 98, 0, 0, 0,
-	70, 71, 72, 73, 75, 74, 76, 77, 78, 79,
-	80, 81, 82, 376, 0, 0, 0, 0, 0, 0,
-	0, 0, 97, 96, 0, 86, 95, 94, 0, 0,
-	0, 0, 0, 0, 0, 88, 89, 90, 91, 92,
-	93, 85, 87, 83, 84, 69, 98, 0, 0, 0,
-	70, 71, 72, 73, 75, 74, 76, 77, 78, 79,
-	80, 81, 82, 375, 0, 0, 0, 0, 0, 0,
-	0, 0, 97, 96, 0, 86, 95, 94, 0, 0,
-	0, 0, 0, 0, 0, 88, 89, 90, 91, 92,
-	93, 85, 87, 83, 84, 69, 98, 0, 0, 0,
-	70, 71, 72, 73, 75, 74, 76, 77, 78, 79,
-	80, 81, 82, 374, 0, 0, 0, 0, 0, 0,
-	0, 0, 97, 96, 0, 86, 95, 94, 0, 0,
-	0, 0, 0, 0, 0, 88, 89, 90, 91, 92,
-	93, 85, 87, 83, 84, 69, 98, 0, 0, 0,
-	70, 71, 72, 73, 75, 74, 76, 77, 78, 79,
-	80, 81, 82, 373, 0, 0, 0, 0, 0, 0,
-	0, 0, 97, 96, 0, 86, 95, 94, 0, 0,
-	0, 0, 0, 0, 0, 88, 89, 90, 91, 92,
-	93, 85, 87, 83, 84, 69, 98, 0, 0, 0,
-	70, 71, 72, 73, 75, 74, 76, 77, 78, 79,
-	80, 81, 82, 366, 0, 0, 0, 0, 0, 0,
-	0, 0, 97, 96, 0, 86, 95, 94, 0, 0,
-	0, 0, 0, 0, 0, 88, 89, 90, 91, 92,
-	93, 85, 87, 83, 84, 69, 98, 0, 0, 0,
-	70, 71, 72, 73, 75, 74, 76, 77, 78, 79,
-	80, 81, 82, 347, 0, 0, 0, 0, 0, 0,
-	0, 0, 97, 96, 0, 86, 95, 94, 0, 0,
-	0, 0, 0, 0, 0, 88, 89, 90, 91, 92,
-	93, 85, 87, 83, 84, 69, 98, 0, 0, 0,
-	70, 71, 72, 73, 75, 74, 76, 77, 78, 79,
-	80, 81, 82, 346, 0, 0, 0, 0, 0, 0,
-	0, 0, 97, 96, 0, 86, 95, 94, 0, 0,
-	0, 0, 0, 0, 0, 88, 89, 90, 91, 92,
-	93, 85, 87, 83, 84, 69, 98, 0, 0, 0,
-	70, 71, 72, 73, 75, 74, 76, 77, 78, 79,
-	80, 81, 82, 345, 0, 0, 0, 0, 0, 0,
-	0, 0, 97, 96, 0, 86, 95, 94, 0, 0,
-	0, 0, 0, 0, 0, 88, 89, 90, 91, 92,
-	93, 85, 87, 83, 84, 69, 98, 0, 0, 0,
-	70, 71, 72, 73, 75, 74, 76, 77, 78, 79,
-	80, 81, 82, 344, 0, 0, 0, 0, 0, 0,
-	0, 0, 97, 96, 0, 86, 95, 94, 0, 0,
-	0, 0, 0, 0, 0, 88, 89, 90, 91, 92,
-	93, 85, 87, 83, 84, 69, 98, 0, 0, 0,
-	70, 71, 72, 73, 75, 74, 76, 77, 78, 79,
-	80, 81, 82, 342, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 97, 96, 0, 86, 95, 94, 0,
-	0, 0, 0, 0, 0, 0, 88, 89, 90, 91,
-	92, 93, 85, 87, 83, 84, 69, 98, 0, 0,
-	0, 70, 71, 72, 73, 75, 74, 76, 77, 78,
-	79, 80, 81, 82, 341, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 97, 96, 0, 86, 95, 94,
-	0, 0, 0, 0, 0, 0, 0, 88, 89, 90,
-	91, 92, 93, 85, 87, 83, 84, 69, 98, 0,
-	0, 0, 70, 71, 72, 73, 75, 74, 76, 77,
-	78, 79, 80, 81, 82, 340, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 97, 96, 0, 86, 95,
-	94, 0, 0, 0, 0, 0, 0, 0, 88, 89,
-	90, 91, 92, 93, 85, 87, 83, 84, 69, 98,
-	0, 0, 0, 70, 71, 72, 73, 75, 74, 76,
-	77, 78, 79, 80, 81, 82, 338, 0, 0, 0,
-	0, 0, 0, 0, 0, 97, 96, 0, 86, 95,
-	94, 0, 0, 0, 0, 0, 0, 0, 88, 89,
-	90, 91, 92, 93, 85, 87, 83, 84, 69, 98,
-	320, 0, 0, 70, 71, 72, 73, 75, 74, 76,
-	77, 78, 79, 80, 81, 82, 97, 96, 0, 86,
-	95, 94, 0, 0, 336, 0, 0, 0, 0, 88,
-	89, 90, 91, 92, 93, 85, 87, 83, 84, 69,
-	98, 0, 0, 0, 70, 71, 72, 73, 75, 74,
-	76, 77, 78, 79, 80, 81, 82, 0, 0, 0,
-	97, 96, 0, 86, 95, 94, 0, 0, 0, 0,
-	0, 0, 0, 88, 89, 90, 91, 92, 93, 85,
-	87, 83, 84, 69, 98, 0, 0, 0, 70, 71,
-	72, 73, 75, 74, 76, 77, 78, 79, 80, 81,
-	82, 97, 96, 257, 86, 95, 94, 0, 0, 308,
-	0, 0, 0, 0, 88, 89, 90, 91, 92, 93,
-	85, 87, 83, 84, 69, 98, 0, 0, 0, 70,
-	71, 72, 73, 75, 74, 76, 77, 78, 79, 80,
-	81, 82, 0, 0, 0, 0, 0, 0, 0, 0,
-	97, 96, 0, 86, 95, 94, 0, 0, 0, 0,
-	0, 0, 0, 88, 89, 90, 91, 92, 93, 85,
-	87, 83, 84, 69, 98, 0, 0, 0, 70, 71,
-	72, 73, 75, 74, 76, 77, 78, 79, 80, 81,
-	82, 256, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 97, 96, 0, 86, 95, 94, 0, 0, 0,
-	0, 0, 0, 0, 88, 89, 90, 91, 92, 93,
-	85, 87, 83, 84, 69, 98, 0, 0, 0, 70,
-	71, 72, 73, 75, 74, 76, 77, 78, 79, 80,
-	81, 82, 97, 96, 0, 86, 95, 94, 0, 0,
-	0, 0, 0, 0, 0, 88, 89, 90, 91, 92,
-	93, 85, 87, 83, 84, 69, 98, 0, 0, 0,
-	70, 71, 72, 73, 75, 74, 76, 77, 78, 79,
-	80, 81, 82, 96, 0, 86, 95, 94, 0, 0,
-	0, 0, 0, 0, 0, 88, 89, 90, 91, 92,
-	93, 85, 87, 83, 84, 69, 98, 0, 0, 0,
-	70, 71, 72, 73, 75, 74, 76, 77, 78, 79,
-	80, 81, 82, 86, 95, 94, 0, 0, 0, 0,
-	0, 0, 0, 88, 89, 90, 91, 92, 93, 85,
-	87, 83, 84, 69, 98, 0, 0, 0, 70, 71,
-	72, 73, 75, 74, 76, 77, 78, 79, 80, 81,
-	82,
+	0, 31, 32, 38, 37, 33, 39, 34, 35, 36,
+	43, 252, 0, 0, 0, 0, 0, 0, 49, 55,
+	54, 29, 12, 48, 0, 200, 57, 0, 56, 0,
+	52, 50, 51, 53, 0, 0, 0, 0, 45, 44,
+	0, 30, 0, 0, 0, 0, 0, 40, 42, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 31, 32, 38, 37, 33, 39, 34, 35, 36,
+	43, 0, 0, 0, 0, 0, 0, 0, 49, 55,
+	54, 29, 12, 48, 0, 0, 57, 0, 56, 0,
+	52, 50, 51, 53, 0, 0, 0, 0, 45, 44,
+	0, 30, 412, 413, 0, 0, 0, 40, 86, 88,
+	84, 85, 69, 98, 0, 0, 0, 71, 72, 73,
+	74, 76, 75, 77, 78, 79, 80, 81, 82, 83,
+	43, 0, 0, 0, 0, 0, 0, 0, 49, 55,
+	54, 0, 0, 0, 0, 97, 96, 0, 87, 70,
+	95, 67, 0, 0, 0, 0, 0, 0, 89, 90,
+	91, 92, 93, 94, 86, 88, 84, 85, 69, 98,
+	0, 0, 0, 71, 72, 73, 74, 76, 75, 77,
+	78, 79, 80, 81, 82, 83, 12, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 97,
+	96, 0, 87, 70, 95, 0, 0, 0, 0, 0,
+	0, 0, 89, 90, 91, 92, 93, 94, 86, 88,
+	84, 85, 69, 98, 0, 0, 0, 71, 72, 73,
+	74, 76, 75, 77, 78, 79, 80, 81, 82, 83,
+	407, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	97, 96, 0, 87, 70, 95, 0, 0, 0, 0,
+	0, 0, 0, 89, 90, 91, 92, 93, 94, 86,
+	88, 84, 85, 69, 98, 0, 0, 0, 71, 72,
+	73, 74, 76, 75, 77, 78, 79, 80, 81, 82,
+	83, 397, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 97, 96, 0, 87, 70, 95, 0, 0, 0,
+	0, 0, 0, 0, 89, 90, 91, 92, 93, 94,
+	86, 88, 84, 85, 69, 98, 0, 0, 0, 71,
+	72, 73, 74, 76, 75, 77, 78, 79, 80, 81,
+	82, 83, 395, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 97, 96, 0, 87, 70, 95, 0, 0,
+	0, 0, 0, 0, 0, 89, 90, 91, 92, 93,
+	94, 86, 88, 84, 85, 69, 98, 0, 0, 0,
+	71, 72, 73, 74, 76, 75, 77, 78, 79, 80,
+	81, 82, 83, 394, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 97, 96, 0, 87, 70, 95, 0,
+	0, 0, 0, 0, 0, 0, 89, 90, 91, 92,
+	93, 94, 86, 88, 84, 85, 69, 98, 0, 0,
+	0, 71, 72, 73, 74, 76, 75, 77, 78, 79,
+	80, 81, 82, 83, 393, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 97, 96, 0, 87, 70, 95,
+	0, 0, 0, 0, 0, 0, 0, 89, 90, 91,
+	92, 93, 94, 86, 88, 84, 85, 69, 98, 0,
+	0, 0, 71, 72, 73, 74, 76, 75, 77, 78,
+	79, 80, 81, 82, 83, 392, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 97, 96, 0, 87, 70,
+	95, 0, 0, 0, 0, 0, 0, 0, 89, 90,
+	91, 92, 93, 94, 86, 88, 84, 85, 69, 98,
+	0, 0, 0, 71, 72, 73, 74, 76, 75, 77,
+	78, 79, 80, 81, 82, 83, 384, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 97, 96, 0, 87,
+	70, 95, 0, 0, 0, 0, 0, 0, 0, 89,
+	90, 91, 92, 93, 94, 86, 88, 84, 85, 69,
+	98, 0, 0, 0, 71, 72, 73, 74, 76, 75,
+	77, 78, 79, 80, 81, 82, 83, 363, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 97, 96, 0,
+	87, 70, 95, 0, 0, 0, 0, 0, 0, 0,
+	89, 90, 91, 92, 93, 94, 86, 88, 84, 85,
+	69, 98, 0, 0, 0, 71, 72, 73, 74, 76,
+	75, 77, 78, 79, 80, 81, 82, 83, 362, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 97, 96,
+	0, 87, 70, 95, 0, 0, 0, 0, 0, 0,
+	0, 89, 90, 91, 92, 93, 94, 86, 88, 84,
+	85, 69, 98, 0, 0, 0, 71, 72, 73, 74,
+	76, 75, 77, 78, 79, 80, 81, 82, 83, 361,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 97,
+	96, 0, 87, 70, 95, 0, 0, 0, 0, 0,
+	0, 0, 89, 90, 91, 92, 93, 94, 86, 88,
+	84, 85, 69, 98, 0, 0, 0, 71, 72, 73,
+	74, 76, 75, 77, 78, 79, 80, 81, 82, 83,
+	360, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	97, 96, 0, 87, 70, 95, 0, 0, 0, 0,
+	0, 0, 0, 89, 90, 91, 92, 93, 94, 86,
+	88, 84, 85, 69, 98, 0, 0, 0, 71, 72,
+	73, 74, 76, 75, 77, 78, 79, 80, 81, 82,
+	83, 358, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 97, 96, 0, 87, 70, 95, 0, 0,
+	0, 0, 0, 0, 0, 89, 90, 91, 92, 93,
+	94, 86, 88, 84, 85, 69, 98, 0, 0, 0,
+	71, 72, 73, 74, 76, 75, 77, 78, 79, 80,
+	81, 82, 83, 357, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 97, 96, 0, 87, 70, 95,
+	0, 0, 0, 0, 0, 0, 0, 89, 90, 91,
+	92, 93, 94, 86, 88, 84, 85, 69, 98, 0,
+	0, 0, 71, 72, 73, 74, 76, 75, 77, 78,
+	79, 80, 81, 82, 83, 356, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 97, 96, 0, 87,
+	70, 95, 0, 0, 0, 0, 0, 0, 0, 89,
+	90, 91, 92, 93, 94, 86, 88, 84, 85, 69,
+	98, 0, 0, 0, 71, 72, 73, 74, 76, 75,
+	77, 78, 79, 80, 81, 82, 83, 354, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 97, 96, 0,
+	87, 70, 95, 0, 0, 0, 0, 0, 0, 0,
+	89, 90, 91, 92, 93, 94, 86, 88, 84, 85,
+	69, 98, 329, 0, 0, 71, 72, 73, 74, 76,
+	75, 77, 78, 79, 80, 81, 82, 83, 97, 96,
+	0, 87, 70, 95, 0, 0, 352, 0, 0, 0,
+	0, 89, 90, 91, 92, 93, 94, 86, 88, 84,
+	85, 69, 98, 0, 0, 0, 71, 72, 73, 74,
+	76, 75, 77, 78, 79, 80, 81, 82, 83, 0,
+	0, 0, 0, 97, 96, 0, 87, 70, 95, 0,
+	0, 0, 0, 0, 0, 0, 89, 90, 91, 92,
+	93, 94, 86, 88, 84, 85, 69, 98, 0, 0,
+	0, 71, 72, 73, 74, 76, 75, 77, 78, 79,
+	80, 81, 82, 83, 97, 96, 260, 87, 70, 95,
+	0, 0, 317, 0, 0, 0, 0, 89, 90, 91,
+	92, 93, 94, 86, 88, 84, 85, 69, 98, 0,
+	0, 0, 71, 72, 73, 74, 76, 75, 77, 78,
+	79, 80, 81, 82, 83, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 97, 96, 0, 87, 70, 95,
+	0, 0, 0, 0, 0, 0, 0, 89, 90, 91,
+	92, 93, 94, 86, 88, 84, 85, 69, 98, 0,
+	0, 0, 71, 72, 73, 74, 76, 75, 77, 78,
+	79, 80, 81, 82, 83, 259, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 97, 96, 0, 87,
+	70, 95, 0, 0, 0, 0, 0, 0, 0, 89,
+	90, 91, 92, 93, 94, 86, 88, 84, 85, 69,
+	98, 0, 0, 0, 71, 72, 73, 74, 76, 75,
+	77, 78, 79, 80, 81, 82, 83, 97, 96, 0,
+	87, 70, 95, 0, 0, 0, 0, 0, 0, 0,
+	89, 90, 91, 92, 93, 94, 86, 88, 84, 85,
+	69, 98, 0, 0, 0, 71, 72, 73, 74, 76,
+	75, 77, 78, 79, 80, 81, 82, 83, 96, 0,
+	87, 70, 95, 0, 0, 0, 0, 0, 0, 0,
+	89, 90, 91, 92, 93, 94, 86, 88, 84, 85,
+	69, 98, 0, 0, 0, 71, 72, 73, 74, 76,
+	75, 77, 78, 79, 80, 81, 82, 83, 87, 70,
+	95, 0, 0, 0, 0, 0, 0, 0, 89, 90,
+	91, 92, 93, 94, 86, 88, 84, 85, 69, 98,
+	0, 0, 0, 71, 72, 73, 74, 76, 75, 77,
+	78, 79, 80, 81, 82, 83,
 }
 
 var yyPact = [...]int16{
-	338, -1000, 342, 333, 374, 227, 246, 246, 379, 336,
-	246, 332, -1000, -1000, -1000, 344, 421, 267, 329, 254,
-	379, 373, 336, 245, -1000, 844, -1000, -1000, -1000, 253,
-	742, 251, 250, 249, 247, 244, 243, 242, 241, 240,
-	239, 238, 237, 742, 742, 742, 742, 47, 624, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -62, 742, 236, 232,
-	373, -1000, 379, 421, 371, 421, 167, 246, -1000, 231,
-	742, 742, 742, 742, 742, 742, 742, 742, 742, 742,
-	742, 742, 742, -12, -13, 53, -28, -43, 742, 742,
-	742, 742, 742, 742, 137, 62, 742, 742, 123, 148,
-	69, 1814, 742, 742, 742, 262, -56, 261, 260, 259,
-	199, 362, 683, 373, -1000, 1892, 1892, 317, 1814, 246,
-	-95, 195, -1000, 1814, 128, -1000, -99, 124, 1814, 742,
-	373, 192, -1000, 218, 367, 223, 421, -1000, 47, -1000,
-	-1000, 624, -65, -39, 19, -80, -80, -80, 32, 32,
-	2, 2, 2, -1000, -1000, 21, 20, -57, -1000, -1000,
-	72, 72, 72, 72, 72, 72, 84, -58, -59, 52,
-	-60, -61, 1892, 1854, -1000, 171, -1000, -1000, -1000, 34,
-	545, -1000, 68, 742, 181, 1814, 1773, 1722, 226, 225,
-	224, 188, 370, -1000, 458, 742, -1000, -1000, -1000, -1000,
-	160, 185, 246, 246, -1000, 105, 104, -1000, -1000, -1000,
-	-62, 742, -1000, 742, 157, 182, -1000, 367, 359, 742,
-	421, 421, -1000, 289, -1000, 287, 279, 276, 286, -1000,
-	166, 151, -63, -83, -1000, 137, 16, -47, -84, -1000,
-	-1000, -1000, -1000, -1000, -1000, 36, 230, 147, 1814, -1000,
-	63, 742, 742, 1673, -1000, 742, 742, 258, 742, 742,
-	742, 257, 742, 742, -1000, 742, 742, 1632, -1000, -1000,
-	310, 319, -1000, -1000, -1000, 1814, 1814, -1000, -1000, 359,
-	346, 355, 1814, -1000, 266, -1000, -1000, -1000, 283, -1000,
-	277, -1000, -1000, -1000, -1000, -1000, -1000, -94, -96, -1000,
-	-1000, 229, 361, 34, 742, -1000, 1588, 1814, 742, 1814,
-	1547, 163, 1497, 1446, 1395, 152, 1344, 1294, 1244, 1194,
-	742, 246, 246, 346, 357, 742, 421, 742, -1000, -1000,
-	-1000, -1000, 306, 742, 36, 1814, 742, 1814, -1000, -1000,
-	742, 742, 742, 219, -1000, -1000, -1000, -1000, 1144, -1000,
-	-1000, 357, 347, 353, 1814, 216, 1814, 357, 350, 1094,
-	-1000, 1814, 1044, 994, 944, 742, -1000, 347, 345, -9,
-	742, 139, 742, -1000, -1000, -1000, -1000, 894, 345, -1000,
-	-9, -1000, 210, -1000, 791, -1000, 207, -1000, -1000, -1000,
-	742, 322, -1000, -1000, -1000, -1000, 324, -1000, -1000,
+	360, -1000, 363, 355, 398, 203, 242, 242, 400, 358,
+	242, 353, -1000, -1000, -1000, 366, 136, 285, 348, 270,
+	400, 394, 358, 241, -1000, 810, -1000, -1000, -1000, 269,
+	706, 268, 267, 265, 264, 263, 262, 258, 256, 255,
+	253, 245, 244, 706, 706, 706, 706, 26, 462, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -74, 706, 243, 225,
+	394, -1000, 400, 136, 392, 136, 219, 242, -1000, 215,
+	71, 706, 706, 706, 706, 706, 706, 706, 706, 706,
+	706, 706, 706, 706, -50, -51, 31, -52, -54, 706,
+	706, 706, 706, 706, 706, 164, 706, 706, 74, 188,
+	39, 1848, 706, 706, 706, 280, -55, 279, 278, 273,
+	186, 382, 646, 394, -1000, 1926, 1926, 341, 1848, 242,
+	-80, 185, -1000, 1848, 73, -1000, -98, 87, 1848, 706,
+	394, 166, -1000, 210, 389, 259, 136, -1000, 26, -1000,
+	-1000, 462, 214, -57, -66, 28, -71, -72, 372, -79,
+	187, -53, -53, -53, 13, 13, -41, -41, -41, -1000,
+	-1000, 0, -7, -73, -1000, -1000, 700, 700, 700, 700,
+	700, 700, 53, 1926, 1888, -1000, 122, -1000, -1000, -1000,
+	6, 586, -1000, 48, 706, 175, 1848, 1807, 1755, 196,
+	195, 194, 179, 391, -1000, 498, 706, -1000, -1000, -1000,
+	-1000, 154, 156, 242, 242, -1000, -45, -46, 89, -1000,
+	-1000, -1000, -74, 706, -1000, 706, 147, 140, -1000, 389,
+	385, 706, 136, 136, -1000, 308, -1000, 306, 310, 300,
+	298, -1000, 131, 110, 462, -9, -27, -76, -1000, -1000,
+	-77, -78, -1000, 164, -1000, -1000, -1000, -1000, 8, 208,
+	96, 1848, -1000, 35, 706, 706, 1705, -1000, 706, 706,
+	272, 706, 706, 706, 260, 706, 706, -1000, 706, 706,
+	1664, -1000, 706, -1000, 334, 347, -1000, 4, 68, -1000,
+	-1000, 1848, 1848, -1000, -1000, 385, 370, 376, 1848, -1000,
+	284, -1000, -1000, -1000, 302, -1000, 296, -1000, 294, -1000,
+	-1000, 125, 93, -81, -96, -1000, -1000, -1000, -1000, -1000,
+	75, 388, 6, 706, -1000, 1619, 1848, 706, 1848, 1578,
+	121, 1527, 1475, 1423, 106, 1371, 1320, 1269, 1218, 706,
+	76, 242, 242, 67, -1000, -1000, 370, 378, 706, 136,
+	706, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 325, 706,
+	8, 1848, 706, 1848, -1000, -1000, 706, 706, 706, 193,
+	-1000, -1000, -1000, -1000, 1167, 706, -1000, -1000, -1000, 378,
+	368, 375, 1848, 180, 1848, 378, 373, 1116, -1000, 1848,
+	1065, 1014, 963, 706, -1000, 912, 368, 365, -46, 706,
+	98, 706, -1000, -1000, -1000, -1000, 861, -1000, 365, -1000,
+	-46, -1000, 165, -1000, 756, -1000, 77, -1000, -1000, -1000,
+	706, 344, -1000, -1000, -1000, -1000, 340, -1000, -1000,
 }
 
 var yyPgo = [...]int16{
-	0, 437, 0, 154, 11, 435, 12, 7, 432, 431,
-	430, 8, 425, 423, 421, 420, 416, 415, 414, 88,
-	2, 86, 413, 10, 20, 21, 14, 412, 409, 4,
-	408, 407, 13, 406, 352, 1, 5, 393, 390, 6,
-	3, 389, 9, 388, 387, 192, 386,
+	0, 458, 0, 127, 13, 456, 12, 8, 453, 452,
+	451, 10, 450, 445, 443, 441, 440, 436, 435, 99,
+	2, 39, 434, 11, 7, 19, 15, 433, 430, 4,
+	429, 428, 14, 427, 371, 1, 5, 426, 413, 6,
+	3, 410, 9, 409, 408, 183, 407,
 }
 
 var yyR1 = [...]int8{
 	0, 1, 22, 21, 44, 44, 44, 5, 5, 14,
 	14, 45, 45, 45, 15, 15, 25, 25, 25, 25,
 	25, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 4, 4, 10, 10, 18, 18,
-	34, 34, 34, 2, 2, 2, 2, 2, 2, 2,
+	3, 3, 3, 3, 3, 3, 3, 4, 4, 10,
+	10, 18, 18, 34, 34, 34, 2, 2, 2, 2,
 	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
 	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
 	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
 	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
 	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
 	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
-	2, 2, 2, 24, 24, 29, 29, 33, 33, 33,
-	30, 30, 30, 31, 31, 31, 32, 28, 28, 42,
-	42, 38, 38, 38, 38, 38, 38, 38, 46, 46,
-	26, 26, 27, 27, 27, 20, 19, 9, 9, 41,
-	41, 8, 8, 11, 11, 6, 6, 7, 7, 23,
-	23, 17, 17, 17, 16, 16, 16, 35, 37, 37,
-	36, 36, 39, 39, 40, 40, 12, 12, 12, 12,
-	13, 43, 43, 43,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 24,
+	24, 29, 29, 33, 33, 33, 30, 30, 30, 31,
+	31, 31, 32, 28, 28, 42, 42, 38, 38, 38,
+	38, 38, 38, 38, 38, 46, 46, 26, 26, 27,
+	27, 27, 20, 19, 9, 9, 41, 41, 8, 8,
+	11, 11, 6, 6, 7, 7, 23, 23, 17, 17,
+	17, 16, 16, 16, 35, 37, 37, 36, 36, 39,
+	39, 40, 40, 12, 12, 12, 12, 13, 43, 43,
+	43,
 }
 
 var yyR2 = [...]int8{
 	0, 4, 11, 10, 1, 3, 0, 2, 0, 1,
 	0, 0, 3, 4, 6, 7, 3, 2, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 3,
-	3, 3, 4, 4, 1, 3, 1, 1, 1, 0,
-	5, 1, 0, 1, 5, 7, 5, 4, 6, 6,
-	8, 8, 8, 9, 6, 6, 3, 4, 6, 6,
-	7, 3, 4, 5, 5, 4, 3, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 3, 2,
-	5, 3, 5, 3, 4, 3, 3, 3, 3, 3,
-	3, 3, 3, 5, 4, 6, 4, 6, 5, 4,
-	4, 2, 2, 3, 3, 3, 4, 3, 4, 3,
-	4, 3, 4, 1, 3, 1, 3, 1, 1, 3,
-	1, 3, 0, 1, 3, 0, 3, 3, 0, 5,
-	0, 1, 2, 2, 3, 2, 3, 2, 1, 2,
-	1, 0, 2, 3, 5, 1, 1, 0, 2, 4,
-	5, 0, 1, 0, 5, 0, 2, 0, 2, 0,
-	3, 0, 2, 2, 0, 1, 1, 3, 3, 1,
-	0, 3, 0, 2, 0, 2, 6, 6, 4, 4,
-	1, 1, 1, 1,
+	3, 3, 4, 6, 5, 5, 4, 1, 3, 1,
+	1, 1, 0, 5, 1, 0, 1, 5, 7, 5,
+	4, 6, 6, 8, 8, 8, 9, 6, 6, 3,
+	4, 6, 6, 7, 3, 4, 8, 5, 5, 6,
+	6, 4, 3, 3, 3, 3, 3, 3, 3, 3,
+	3, 3, 3, 3, 3, 2, 5, 3, 5, 3,
+	4, 3, 3, 3, 3, 3, 3, 3, 3, 5,
+	4, 6, 4, 6, 5, 4, 4, 2, 2, 3,
+	3, 3, 4, 3, 4, 3, 4, 3, 4, 1,
+	3, 1, 3, 1, 1, 3, 1, 3, 0, 1,
+	3, 0, 3, 3, 0, 5, 0, 1, 2, 2,
+	3, 2, 3, 2, 3, 1, 2, 1, 0, 2,
+	3, 5, 1, 1, 0, 2, 4, 5, 0, 1,
+	0, 5, 0, 2, 0, 2, 0, 3, 0, 2,
+	2, 0, 1, 1, 3, 3, 1, 0, 3, 0,
+	2, 0, 2, 6, 6, 4, 4, 1, 1, 1,
+	1,
 }
 
 var yyChk = [...]int16{
 	-1000, -1, -44, 18, -14, -15, 16, 21, -22, 7,
 	58, -19, 56, -19, -45, 6, -34, 19, -19, 21,
-	-21, 20, 7, -24, -25, -2, 104, -12, -4, 55,
-	74, 35, 36, 39, 41, 42, 43, 38, 37, 40,
-	80, -19, 22, 103, 72, 71, 28, -3, 57, 111,
-	65, 66, 64, 67, 113, 112, 62, 60, 53, 21,
-	57, -45, -21, -34, -5, 58, 17, 21, -19, 91,
-	96, 97, 98, 99, 101, 100, 102, 103, 104, 105,
-	106, 107, 108, 89, 90, 87, 71, 88, 81, 82,
-	83, 84, 85, 86, 73, 72, 69, 68, 92, 57,
+	-21, 20, 7, -24, -25, -2, 105, -12, -4, 55,
+	75, 35, 36, 39, 41, 42, 43, 38, 37, 40,
+	81, -19, 22, 104, 73, 72, 28, -3, 57, 112,
+	65, 66, 64, 67, 114, 113, 62, 60, 53, 21,
+	57, -45, -21, -34, -5, 58, 17, 21, -19, 92,
+	73, 97, 98, 99, 100, 102, 101, 103, 104, 105,
+	106, 107, 108, 109, 90, 91, 88, 72, 89, 82,
+	83, 84, 85, 86, 87, 74, 70, 69, 93, 57,
 	-8, -2, 57, 57, 57, 57, 57, 57, 57, 57,
-	57, 57, 57, 57, -2, -2, -2, -13, -2, 110,
-	60, -10, -21, -2, -31, -32, 113, -30, -2, 57,
+	57, 57, 57, 57, -2, -2, -2, -13, -2, 111,
+	60, -10, -21, -2, -31, -32, 114, -30, -2, 57,
 	57, -21, -45, -24, -26, -27, 8, -25, -3, -19,
-	-19, 57, -2, -2, -2, -2, -2, -2, -2, -2,
-	-2, -2, -2, -2, -2, 113, 113, 79, 113, 113,
-	-2, -2, -2, -2, -2, -2, -4, 90, 89, 87,
-	71, 88, -2, -2, 64, 72, 67, 65, 66, 59,
-	-18, 19, -41, 75, -29, -2, -2, -2, 56, 113,
-	56, 56, 56, 59, -2, -43, 32, 33, 34, 59,
-	-29, -21, 21, 29, -19, -20, 113, 111, 59, 63,
-	58, 114, 61, 58, -29, -21, 59, -26, -6, 9,
-	-46, -38, 58, 49, 46, 50, 47, 48, 52, -25,
-	-21, -29, 95, 95, 113, 69, 113, 113, 79, 113,
-	113, 64, 67, 65, 66, -11, 94, -33, -2, 104,
-	-9, 75, 77, -2, 59, 58, 58, 21, 58, 58,
-	58, 57, 58, 8, 59, 58, 8, -2, 59, 59,
-	-19, -19, 61, 61, -32, -2, -2, 59, 59, -6,
-	-23, 10, -2, -25, -25, 46, 46, 46, 51, 46,
-	51, 46, 59, 59, 113, 113, -4, 95, 95, 113,
-	-42, 93, 57, 59, 58, 78, -2, -2, 76, -2,
-	-2, 56, -2, -2, -2, 56, -2, -2, -2, -2,
-	8, 29, 21, -23, -7, 13, 12, 53, 46, 46,
-	113, 113, 57, 9, -11, -2, 76, -2, 59, 59,
-	58, 58, 58, 59, 59, 59, 59, 59, -2, -19,
-	-19, -7, -36, 11, -2, -24, -2, -28, 30, -2,
-	-42, -2, -2, -2, -2, 58, 59, -36, -39, 14,
-	12, -36, 12, 59, 59, 59, 59, -2, -39, -40,
+	-19, 57, 92, 91, 90, 88, 72, 89, -2, -2,
+	-2, -2, -2, -2, -2, -2, -2, -2, -2, -2,
+	-2, 114, 114, 80, 114, 114, -2, -2, -2, -2,
+	-2, -2, -4, -2, -2, 64, 73, 67, 65, 66,
+	59, -18, 19, -41, 76, -29, -2, -2, -2, 56,
+	114, 56, 56, 56, 59, -2, -43, 32, 33, 34,
+	59, -29, -21, 21, 29, -19, -20, 115, 114, 112,
+	59, 63, 58, 115, 61, 58, -29, -21, 59, -26,
+	-6, 9, -46, -38, 58, 49, 46, 50, 47, 48,
+	52, -25, -21, -29, 57, 114, 114, 80, 114, 114,
+	96, 96, 114, 70, 64, 67, 65, 66, -11, 95,
+	-33, -2, 105, -9, 76, 78, -2, 59, 58, 58,
+	21, 58, 58, 58, 57, 58, 8, 59, 58, 8,
+	-2, 59, 58, 59, -19, -19, 61, 115, -20, 61,
+	-32, -2, -2, 59, 59, -6, -23, 10, -2, -25,
+	-25, 46, 46, 46, 51, 46, 51, 46, 51, 59,
+	59, -21, -29, 96, 96, 114, 114, 114, -4, -42,
+	94, 57, 59, 58, 79, -2, -2, 77, -2, -2,
+	56, -2, -2, -2, 56, -2, -2, -2, -2, 8,
+	-19, 29, 21, -20, 61, 61, -23, -7, 13, 12,
+	53, 46, 46, 46, 59, 59, 114, 114, 57, 9,
+	-11, -2, 77, -2, 59, 59, 58, 58, 58, 59,
+	59, 59, 59, 59, -2, 68, -19, -19, 61, -7,
+	-36, 11, -2, -24, -2, -28, 30, -2, -42, -2,
+	-2, -2, -2, 58, 59, -2, -36, -39, 14, 12,
+	-36, 12, 59, 59, 59, 59, -2, 59, -39, -40,
 	15, -20, -37, -35, -2, 59, -29, 59, -40, -20,
 	58, -16, 26, 27, -35, -17, 23, 24, 25,
 }
 
 var yyDef = [...]int16{
-	6, -2, 10, 4, 0, 9, 0, 0, 11, 42,
-	0, 0, 146, 5, 1, 0, 0, 41, 0, 0,
-	11, 0, 42, 8, 113, 18, 19, 20, 43, 0,
-	151, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 21, 0, 0, 0, 0, 0, 34, 0, 22,
-	23, 24, 25, 26, 27, 28, 125, 122, 0, 0,
-	0, 12, 11, 0, 141, 0, 0, 0, 17, 0,
+	6, -2, 10, 4, 0, 9, 0, 0, 11, 45,
+	0, 0, 153, 5, 1, 0, 0, 44, 0, 0,
+	11, 0, 45, 8, 119, 18, 19, 20, 46, 0,
+	158, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 21, 0, 0, 0, 0, 0, 37, 0, 22,
+	23, 24, 25, 26, 27, 28, 131, 128, 0, 0,
+	0, 12, 11, 0, 148, 0, 0, 0, 17, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 0, 39,
-	0, 152, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 79, 101, 102, 0, 180, 0,
-	0, 0, 36, 37, 0, 123, 0, 0, 120, 0,
-	0, 0, 13, 141, 155, 140, 0, 114, 7, 21,
-	16, 0, 66, 67, 68, 69, 70, 71, 72, 73,
-	74, 75, 76, 77, 78, 81, 83, 0, 85, 86,
-	87, 88, 89, 90, 91, 92, 0, 0, 0, 0,
-	0, 0, 103, 104, 105, 0, 107, 109, 111, 153,
-	0, 38, 147, 0, 0, 115, 0, 0, 0, 0,
-	0, 0, 0, 56, 0, 0, 181, 182, 183, 61,
-	0, 0, 0, 0, 31, 0, 0, 145, 35, 29,
-	0, 0, 30, 0, 0, 0, 14, 155, 159, 0,
-	0, 0, 138, 0, 131, 0, 0, 0, 0, 142,
-	0, 0, 0, 0, 84, 0, 94, 96, 0, 99,
-	100, 106, 108, 110, 112, 130, 0, 0, 117, 118,
-	0, 0, 0, 0, 47, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 57, 0, 0, 0, 62, 65,
-	178, 179, 32, 33, 124, 126, 121, 40, 15, 159,
-	157, 0, 156, 143, 0, 139, 132, 133, 0, 135,
-	0, 137, 63, 64, 80, 82, 93, 0, 0, 98,
-	44, 0, 0, 153, 0, 46, 0, 148, 0, 116,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 42,
+	0, 159, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 85, 107, 108, 0, 187, 0,
+	0, 0, 39, 40, 0, 129, 0, 0, 126, 0,
+	0, 0, 13, 148, 162, 147, 0, 120, 7, 21,
+	16, 0, 0, 0, 0, 0, 0, 0, 72, 73,
+	74, 75, 76, 77, 78, 79, 80, 81, 82, 83,
+	84, 87, 89, 0, 91, 92, 93, 94, 95, 96,
+	97, 98, 0, 109, 110, 111, 0, 113, 115, 117,
+	160, 0, 41, 154, 0, 0, 121, 0, 0, 0,
+	0, 0, 0, 0, 59, 0, 0, 188, 189, 190,
+	64, 0, 0, 0, 0, 31, 0, 0, 0, 152,
+	38, 29, 0, 0, 30, 0, 0, 0, 14, 162,
+	166, 0, 0, 0, 145, 0, 137, 0, 0, 0,
+	0, 149, 0, 0, 0, 100, 102, 0, 105, 106,
+	0, 0, 90, 0, 112, 114, 116, 118, 136, 0,
+	0, 123, 124, 0, 0, 0, 0, 50, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 60, 0, 0,
+	0, 65, 0, 71, 185, 186, 32, 0, 0, 36,
+	130, 132, 127, 43, 15, 166, 164, 0, 163, 150,
+	0, 146, 138, 139, 0, 141, 0, 143, 0, 67,
+	68, 0, 0, 0, 0, 104, 86, 88, 99, 47,
+	0, 0, 160, 0, 49, 0, 155, 0, 122, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 157, 170, 0, 0, 0, 134, 136,
-	95, 97, 128, 0, 130, 119, 0, 149, 48, 49,
-	0, 0, 0, 0, 54, 55, 58, 59, 0, 176,
-	177, 170, 172, 0, 158, 160, 144, 170, 0, 0,
-	45, 150, 0, 0, 0, 0, 60, 172, 174, 0,
-	0, 0, 0, 154, 50, 51, 52, 0, 174, 2,
-	0, 173, 171, 169, 164, 129, 127, 53, 3, 175,
-	0, 161, 165, 166, 168, 167, 0, 162, 163,
+	21, 0, 0, 0, 34, 35, 164, 177, 0, 0,
+	0, 140, 142, 144, 69, 70, 101, 103, 134, 0,
+	136, 125, 0, 156, 51, 52, 0, 0, 0, 0,
+	57, 58, 61, 62, 0, 0, 183, 184, 33, 177,
+	179, 0, 165, 167, 151, 177, 0, 0, 48, 157,
+	0, 0, 0, 0, 63, 0, 179, 181, 0, 0,
+	0, 0, 161, 53, 54, 55, 0, 66, 181, 2,
+	0, 180, 178, 176, 171, 135, 133, 56, 3, 182,
+	0, 168, 172, 173, 175, 174, 0, 169, 170,
 }
 
 var yyTok1 = [...]int8{
 	1, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 70, 3, 3, 3, 106, 98, 3,
-	57, 59, 104, 102, 58, 103, 110, 105, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 114, 3,
+	3, 3, 3, 71, 3, 3, 3, 107, 99, 3,
+	57, 59, 105, 103, 58, 104, 111, 106, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 115, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 60, 3, 61, 97, 3, 3, 3, 3, 3,
+	3, 60, 3, 61, 98, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 62, 96, 63, 71,
+	3, 3, 3, 62, 97, 63, 72,
 }
 
 var yyTok2 = [...]int8{
@@ -683,10 +696,10 @@ var yyTok2 = [...]int8{
 	32, 33, 34, 35, 36, 37, 38, 39, 40, 41,
 	42, 43, 44, 45, 46, 47, 48, 49, 50, 51,
 	52, 53, 54, 55, 56, 64, 65, 66, 67, 68,
-	69, 72, 73, 74, 75, 76, 77, 78, 79, 80,
+	69, 70, 73, 74, 75, 76, 77, 78, 79, 80,
 	81, 82, 83, 84, 85, 86, 87, 88, 89, 90,
-	91, 92, 93, 94, 95, 99, 100, 101, 107, 108,
-	109, 111, 112, 113,
+	91, 92, 93, 94, 95, 96, 100, 101, 102, 108,
+	109, 110, 112, 113, 114,
 }
 
 var yyTok3 = [...]int8{
@@ -1032,7 +1045,7 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:128
+//line partiql.y:129
 		{
 			query, err := buildQuery(yyDollar[1].str, yyDollar[2].with, yyDollar[3].selinto, yyDollar[4].unions)
 			if err != nil {
@@ -1043,7 +1056,7 @@ yydefault:
 		}
 	case 2:
 		yyDollar = yyS[yypt-11 : yypt+1]
-//line partiql.y:139
+//line partiql.y:140
 		{
 			distinct, distinctExpr := decodeDistinct(yyDollar[2].values)
 			yyVAL.selinto.sel = &expr.Select{Distinct: distinct, DistinctExpr: distinctExpr, Columns: yyDollar[3].bindings, From: yyDollar[5].from, Where: yyDollar[6].expr, GroupBy: yyDollar[7].bindings, Having: yyDollar[8].expr, OrderBy: yyDollar[9].orders, Limit: yyDollar[10].exprint, Offset: yyDollar[11].exprint}
@@ -1051,256 +1064,276 @@ yydefault:
 		}
 	case 3:
 		yyDollar = yyS[yypt-10 : yypt+1]
-//line partiql.y:147
+//line partiql.y:148
 		{
 			distinct, distinctExpr := decodeDistinct(yyDollar[2].values)
 			yyVAL.sel = &expr.Select{Distinct: distinct, DistinctExpr: distinctExpr, Columns: yyDollar[3].bindings, From: yyDollar[4].from, Where: yyDollar[5].expr, GroupBy: yyDollar[6].bindings, Having: yyDollar[7].expr, OrderBy: yyDollar[8].orders, Limit: yyDollar[9].exprint, Offset: yyDollar[10].exprint}
 		}
 	case 4:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:153
+//line partiql.y:154
 		{
 			yyVAL.str = "default"
 		}
 	case 5:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:154
+//line partiql.y:155
 		{
 			yyVAL.str = yyDollar[3].str
 		}
 	case 6:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:155
+//line partiql.y:156
 		{
 			yyVAL.str = ""
 		}
 	case 7:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:158
+//line partiql.y:159
 		{
 			yyVAL.expr = yyDollar[2].expr
 		}
 	case 8:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:158
+//line partiql.y:159
 		{
 			yyVAL.expr = nil
 		}
 	case 9:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:161
+//line partiql.y:162
 		{
 			yyVAL.with = yyDollar[1].with
 		}
 	case 10:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:161
+//line partiql.y:162
 		{
 			yyVAL.with = nil
 		}
 	case 11:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:164
+//line partiql.y:165
 		{
 			yyVAL.unions = []unionItem{}
 		}
 	case 12:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:165
+//line partiql.y:166
 		{
 			yyVAL.unions = append(yyVAL.unions, unionItem{typ: expr.UnionDistinct, sel: yyDollar[2].sel})
 			yyVAL.unions = append(yyVAL.unions, yyDollar[3].unions...)
 		}
 	case 13:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:169
+//line partiql.y:170
 		{
 			yyVAL.unions = append(yyVAL.unions, unionItem{typ: expr.UnionAll, sel: yyDollar[3].sel})
 			yyVAL.unions = append(yyVAL.unions, yyDollar[4].unions...)
 		}
 	case 14:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line partiql.y:175
+//line partiql.y:176
 		{
 			yyVAL.with = []expr.CTE{{Table: yyDollar[2].str, As: yyDollar[5].sel}}
 		}
 	case 15:
 		yyDollar = yyS[yypt-7 : yypt+1]
-//line partiql.y:176
+//line partiql.y:177
 		{
 			yyVAL.with = append(yyDollar[1].with, expr.CTE{Table: yyDollar[3].str, As: yyDollar[6].sel})
 		}
 	case 16:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:182
+//line partiql.y:183
 		{
 			yyVAL.bind = expr.Bind(yyDollar[1].expr, yyDollar[3].str)
 		}
 	case 17:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:183
+//line partiql.y:184
 		{
 			yyVAL.bind = expr.Bind(yyDollar[1].expr, yyDollar[2].str)
 		}
 	case 18:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:184
+//line partiql.y:185
 		{
 			yyVAL.bind = expr.Bind(yyDollar[1].expr, "")
 		}
 	case 19:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:185
+//line partiql.y:186
 		{
 			yyVAL.bind = expr.Bind(expr.Star{}, "")
 		}
 	case 20:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:186
+//line partiql.y:187
 		{
 			yyVAL.bind = expr.Bind(yyDollar[1].expr, "")
 		}
 	case 21:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:190
+//line partiql.y:191
 		{
 			yyVAL.expr = expr.Ident(yyDollar[1].str)
 		}
 	case 22:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:191
+//line partiql.y:192
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 23:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:192
+//line partiql.y:193
 		{
 			yyVAL.expr = expr.Bool(true)
 		}
 	case 24:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:193
+//line partiql.y:194
 		{
 			yyVAL.expr = expr.Bool(false)
 		}
 	case 25:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:194
+//line partiql.y:195
 		{
 			yyVAL.expr = expr.Null{}
 		}
 	case 26:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:195
+//line partiql.y:196
 		{
 			yyVAL.expr = expr.Missing{}
 		}
 	case 27:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:196
+//line partiql.y:197
 		{
 			yyVAL.expr = expr.String(yyDollar[1].str)
 		}
 	case 28:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:197
+//line partiql.y:198
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 29:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:198
+//line partiql.y:199
 		{
 			yyVAL.expr = expr.Call(expr.MakeStruct, yyDollar[2].values...)
 		}
 	case 30:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:199
+//line partiql.y:200
 		{
 			yyVAL.expr = expr.Call(expr.MakeList, yyDollar[2].values...)
 		}
 	case 31:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:200
+//line partiql.y:201
 		{
 			yyVAL.expr = &expr.Dot{Inner: yyDollar[1].expr, Field: yyDollar[3].str}
 		}
 	case 32:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:201
+//line partiql.y:202
 		{
 			yyVAL.expr = &expr.Index{Inner: yyDollar[1].expr, Offset: yyDollar[3].integer}
 		}
 	case 33:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line partiql.y:203
+		{
+			end := yyDollar[5].integer
+			yyVAL.expr = &expr.Slice{Inner: yyDollar[1].expr, Start: yyDollar[3].integer, End: &end}
+		}
+	case 34:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line partiql.y:204
+		{
+			yyVAL.expr = &expr.Slice{Inner: yyDollar[1].expr, Start: yyDollar[3].integer}
+		}
+	case 35:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line partiql.y:205
+		{
+			end := yyDollar[4].integer
+			yyVAL.expr = &expr.Slice{Inner: yyDollar[1].expr, End: &end}
+		}
+	case 36:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:202
+//line partiql.y:206
 		{
 			yyVAL.expr = &expr.Dot{Inner: yyDollar[1].expr, Field: yyDollar[3].str}
 		}
-	case 34:
+	case 37:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:214
+//line partiql.y:218
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 35:
+	case 38:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:215
+//line partiql.y:219
 		{
 			yyVAL.expr = yyDollar[2].expr
 		}
-	case 36:
+	case 39:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:218
+//line partiql.y:222
 		{
 			yyVAL.expr = yyDollar[1].sel
 		}
-	case 37:
+	case 40:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:219
+//line partiql.y:223
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 38:
+	case 41:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:222
+//line partiql.y:226
 		{
 			yyVAL.yesno = true
 		}
-	case 39:
+	case 42:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:222
+//line partiql.y:226
 		{
 			yyVAL.yesno = false
 		}
-	case 40:
+	case 43:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line partiql.y:225
+//line partiql.y:229
 		{
 			yyVAL.values = yyDollar[4].values
 		}
-	case 41:
+	case 44:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:226
+//line partiql.y:230
 		{
 			yyVAL.values = []expr.Node{}
 		}
-	case 42:
+	case 45:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:227
+//line partiql.y:231
 		{
 			yyVAL.values = nil
 		}
-	case 43:
+	case 46:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:233
+//line partiql.y:237
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 44:
+	case 47:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line partiql.y:237
+//line partiql.y:241
 		{
 			agg, err := toAggregate(expr.AggregateOp(yyDollar[1].integer), false, nil, yyDollar[4].expr, yyDollar[5].wind)
 			if err != nil {
@@ -1308,9 +1341,9 @@ yydefault:
 			}
 			yyVAL.expr = agg
 		}
-	case 45:
+	case 48:
 		yyDollar = yyS[yypt-7 : yypt+1]
-//line partiql.y:245
+//line partiql.y:249
 		{
 			agg, err := toAggregate(expr.AggregateOp(yyDollar[1].integer), yyDollar[3].yesno, yyDollar[4].values, yyDollar[6].expr, yyDollar[7].wind)
 			if err != nil {
@@ -1318,27 +1351,27 @@ yydefault:
 			}
 			yyVAL.expr = agg
 		}
-	case 46:
+	case 49:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line partiql.y:253
+//line partiql.y:257
 		{
 			yyVAL.expr = createCase(yyDollar[2].expr, yyDollar[3].limbs, yyDollar[4].expr)
 		}
-	case 47:
+	case 50:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:257
+//line partiql.y:261
 		{
 			yyVAL.expr = expr.Coalesce(yyDollar[3].values)
 		}
-	case 48:
+	case 51:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line partiql.y:261
+//line partiql.y:265
 		{
 			yyVAL.expr = expr.NullIf(yyDollar[3].expr, yyDollar[5].expr)
 		}
-	case 49:
+	case 52:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line partiql.y:265
+//line partiql.y:269
 		{
 			nod, ok := buildCast(yyDollar[3].expr, yyDollar[5].str)
 			if !ok {
@@ -1346,9 +1379,9 @@ yydefault:
 			}
 			yyVAL.expr = nod
 		}
-	case 50:
+	case 53:
 		yyDollar = yyS[yypt-8 : yypt+1]
-//line partiql.y:273
+//line partiql.y:277
 		{
 			part, ok := timePartFor(yyDollar[3].str, "DATE_ADD")
 			if !ok {
@@ -1356,9 +1389,9 @@ yydefault:
 			}
 			yyVAL.expr = expr.DateAdd(part, yyDollar[5].expr, yyDollar[7].expr)
 		}
-	case 51:
+	case 54:
 		yyDollar = yyS[yypt-8 : yypt+1]
-//line partiql.y:281
+//line partiql.y:285
 		{
 			interval, err := parseInterval(yyDollar[3].str)
 			if err != nil {
@@ -1366,9 +1399,9 @@ yydefault:
 			}
 			yyVAL.expr = expr.DateBinWithInterval(interval, yyDollar[5].expr, yyDollar[7].expr)
 		}
-	case 52:
+	case 55:
 		yyDollar = yyS[yypt-8 : yypt+1]
-//line partiql.y:289
+//line partiql.y:293
 		{
 			part, ok := timePartFor(yyDollar[3].str, "DATE_DIFF")
 			if !ok {
@@ -1376,9 +1409,9 @@ yydefault:
 			}
 			yyVAL.expr = expr.DateDiff(part, yyDollar[5].expr, yyDollar[7].expr)
 		}
-	case 53:
+	case 56:
 		yyDollar = yyS[yypt-9 : yypt+1]
-//line partiql.y:297
+//line partiql.y:301
 		{
 			dow, ok := weekday(yyDollar[5].str)
 			if strings.ToUpper(yyDollar[3].str) != "WEEK" || !ok {
@@ -1386,9 +1419,9 @@ yydefault:
 			}
 			yyVAL.expr = expr.DateTruncWeekday(yyDollar[8].expr, dow)
 		}
-	case 54:
+	case 57:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line partiql.y:305
+//line partiql.y:309
 		{
 			part, ok := timePartFor(yyDollar[3].str, "DATE_TRUNC")
 			if !ok {
@@ -1396,9 +1429,9 @@ yydefault:
 			}
 			yyVAL.expr = expr.DateTrunc(part, yyDollar[5].expr)
 		}
-	case 55:
+	case 58:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line partiql.y:313
+//line partiql.y:317
 		{
 			part, ok := timePartFor(yyDollar[3].str, "EXTRACT")
 			if !ok {
@@ -1406,15 +1439,15 @@ yydefault:
 			}
 			yyVAL.expr = expr.DateExtract(part, yyDollar[5].expr)
 		}
-	case 56:
+	case 59:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:321
+//line partiql.y:325
 		{
 			yyVAL.expr = yylex.(*scanner).utcnow()
 		}
-	case 57:
+	case 60:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:325
+//line partiql.y:329
 		{
 			node, err := createTrimInvocation(trimBoth, yyDollar[3].expr, nil)
 			if err != nil {
@@ -1422,9 +1455,9 @@ yydefault:
 			}
 			yyVAL.expr = node
 		}
-	case 58:
+	case 61:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line partiql.y:333
+//line partiql.y:337
 		{
 			node, err := createTrimInvocation(trimBoth, yyDollar[3].expr, yyDollar[5].expr)
 			if err != nil {
@@ -1432,9 +1465,9 @@ yydefault:
 			}
 			yyVAL.expr = node
 		}
-	case 59:
+	case 62:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line partiql.y:341
+//line partiql.y:345
 		{
 			node, err := createTrimInvocation(trimBoth, yyDollar[5].expr, yyDollar[3].expr)
 			if err != nil {
@@ -1442,9 +1475,9 @@ yydefault:
 			}
 			yyVAL.expr = node
 		}
-	case 60:
+	case 63:
 		yyDollar = yyS[yypt-7 : yypt+1]
-//line partiql.y:349
+//line partiql.y:353
 		{
 			node, err := createTrimInvocation(yyDollar[3].integer, yyDollar[6].expr, yyDollar[4].expr)
 			if err != nil {
@@ -1452,9 +1485,9 @@ yydefault:
 			}
 			yyVAL.expr = node
 		}
-	case 61:
+	case 64:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:357
+//line partiql.y:361
 		{
 			op := expr.CallByName(yyDollar[1].str)
 			if op.Private() {
@@ -1462,9 +1495,9 @@ yydefault:
 			}
 			yyVAL.expr = op
 		}
-	case 62:
+	case 65:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:365
+//line partiql.y:369
 		{
 			op := expr.CallByName(yyDollar[1].str, yyDollar[3].values...)
 			if op.Private() {
@@ -1472,489 +1505,517 @@ yydefault:
 			}
 			yyVAL.expr = op
 		}
-	case 63:
+	case 66:
+		yyDollar = yyS[yypt-8 : yypt+1]
+//line partiql.y:377
+		{
+			node, err := createLambdaInvocation(yyDollar[1].str, yyDollar[3].values, yyDollar[5].str, yyDollar[7].expr)
+			if err != nil {
+				yylex.Error(err.Error())
+			}
+			yyVAL.expr = node
+		}
+	case 67:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line partiql.y:373
+//line partiql.y:385
 		{
 			yyVAL.expr = expr.Call(expr.InSubquery, yyDollar[1].expr, yyDollar[4].sel)
 		}
-	case 64:
+	case 68:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line partiql.y:377
+//line partiql.y:389
 		{
 			yyVAL.expr = expr.In(yyDollar[1].expr, yyDollar[4].values...)
 		}
-	case 65:
+	case 69:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line partiql.y:393
+		{
+			yyVAL.expr = &expr.Not{Expr: expr.Call(expr.InSubquery, yyDollar[1].expr, yyDollar[5].sel)}
+		}
+	case 70:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line partiql.y:397
+		{
+			yyVAL.expr = expr.NotIn(yyDollar[1].expr, yyDollar[5].values...)
+		}
+	case 71:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:381
+//line partiql.y:401
 		{
 			yyVAL.expr = exists(yyDollar[3].sel)
 		}
-	case 66:
+	case 72:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:385
+//line partiql.y:405
 		{
 			yyVAL.expr = expr.BitOr(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 67:
+	case 73:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:389
+//line partiql.y:409
 		{
 			yyVAL.expr = expr.BitXor(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 68:
+	case 74:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:393
+//line partiql.y:413
 		{
 			yyVAL.expr = expr.BitAnd(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 69:
+	case 75:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:397
+//line partiql.y:417
 		{
 			yyVAL.expr = expr.ShiftLeftLogical(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 70:
+	case 76:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:401
+//line partiql.y:421
 		{
 			yyVAL.expr = expr.ShiftRightLogical(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 71:
+	case 77:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:405
+//line partiql.y:425
 		{
 			yyVAL.expr = expr.ShiftRightArithmetic(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 72:
+	case 78:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:409
+//line partiql.y:429
 		{
 			yyVAL.expr = expr.Add(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 73:
+	case 79:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:413
+//line partiql.y:433
 		{
 			yyVAL.expr = expr.Sub(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 74:
+	case 80:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:417
+//line partiql.y:437
 		{
 			yyVAL.expr = expr.Mul(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 75:
+	case 81:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:421
+//line partiql.y:441
 		{
 			yyVAL.expr = expr.Div(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 76:
+	case 82:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:425
+//line partiql.y:445
 		{
 			yyVAL.expr = expr.Mod(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 77:
+	case 83:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:429
+//line partiql.y:449
 		{
 			yyVAL.expr = expr.Call(expr.Concat, yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 78:
+	case 84:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:433
+//line partiql.y:453
 		{
 			yyVAL.expr = expr.Append(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 79:
+	case 85:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:437
+//line partiql.y:457
 		{
 			yyVAL.expr = expr.Neg(yyDollar[2].expr)
 		}
-	case 80:
+	case 86:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line partiql.y:441
+//line partiql.y:461
 		{
 			yyVAL.expr = &expr.StringMatch{Op: expr.Ilike, Expr: yyDollar[1].expr, Pattern: yyDollar[3].str, Escape: yyDollar[5].str}
 		}
-	case 81:
+	case 87:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:445
+//line partiql.y:465
 		{
 			yyVAL.expr = &expr.StringMatch{Op: expr.Ilike, Expr: yyDollar[1].expr, Pattern: yyDollar[3].str}
 		}
-	case 82:
+	case 88:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line partiql.y:449
+//line partiql.y:469
 		{
 			yyVAL.expr = &expr.StringMatch{Op: expr.Like, Expr: yyDollar[1].expr, Pattern: yyDollar[3].str, Escape: yyDollar[5].str}
 		}
-	case 83:
+	case 89:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:453
+//line partiql.y:473
 		{
 			yyVAL.expr = &expr.StringMatch{Op: expr.Like, Expr: yyDollar[1].expr, Pattern: yyDollar[3].str}
 		}
-	case 84:
+	case 90:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:457
+//line partiql.y:477
 		{
 			yyVAL.expr = &expr.StringMatch{Op: expr.SimilarTo, Expr: yyDollar[1].expr, Pattern: yyDollar[4].str}
 		}
-	case 85:
+	case 91:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:461
+//line partiql.y:481
 		{
 			yyVAL.expr = &expr.StringMatch{Op: expr.RegexpMatch, Expr: yyDollar[1].expr, Pattern: yyDollar[3].str}
 		}
-	case 86:
+	case 92:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:465
+//line partiql.y:485
 		{
 			yyVAL.expr = &expr.StringMatch{Op: expr.RegexpMatchCi, Expr: yyDollar[1].expr, Pattern: yyDollar[3].str}
 		}
-	case 87:
+	case 93:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:469
+//line partiql.y:489
 		{
 			yyVAL.expr = expr.Compare(expr.Equals, yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 88:
+	case 94:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:473
+//line partiql.y:493
 		{
 			yyVAL.expr = expr.Compare(expr.NotEquals, yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 89:
+	case 95:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:477
+//line partiql.y:497
 		{
 			yyVAL.expr = expr.Compare(expr.Less, yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 90:
+	case 96:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:481
+//line partiql.y:501
 		{
 			yyVAL.expr = expr.Compare(expr.LessEquals, yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 91:
+	case 97:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:485
+//line partiql.y:505
 		{
 			yyVAL.expr = expr.Compare(expr.Greater, yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 92:
+	case 98:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:489
+//line partiql.y:509
 		{
 			yyVAL.expr = expr.Compare(expr.GreaterEquals, yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 93:
+	case 99:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line partiql.y:493
+//line partiql.y:513
 		{
 			yyVAL.expr = expr.Between(yyDollar[1].expr, yyDollar[3].expr, yyDollar[5].expr)
 		}
-	case 94:
+	case 100:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:497
+//line partiql.y:517
 		{
 			yyVAL.expr = &expr.Not{Expr: &expr.StringMatch{Op: expr.Like, Expr: yyDollar[1].expr, Pattern: yyDollar[4].str}}
 		}
-	case 95:
+	case 101:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line partiql.y:501
+//line partiql.y:521
 		{
 			yyVAL.expr = &expr.Not{Expr: &expr.StringMatch{Op: expr.Like, Expr: yyDollar[1].expr, Pattern: yyDollar[4].str, Escape: yyDollar[6].str}}
 		}
-	case 96:
+	case 102:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:505
+//line partiql.y:525
 		{
 			yyVAL.expr = &expr.Not{Expr: &expr.StringMatch{Op: expr.Like, Expr: yyDollar[1].expr, Pattern: yyDollar[4].str}}
 		}
-	case 97:
+	case 103:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line partiql.y:509
+//line partiql.y:529
 		{
 			yyVAL.expr = &expr.Not{Expr: &expr.StringMatch{Op: expr.Ilike, Expr: yyDollar[1].expr, Pattern: yyDollar[4].str, Escape: yyDollar[6].str}}
 		}
-	case 98:
+	case 104:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line partiql.y:513
+//line partiql.y:533
 		{
 			yyVAL.expr = &expr.Not{Expr: &expr.StringMatch{Op: expr.SimilarTo, Expr: yyDollar[1].expr, Pattern: yyDollar[5].str}}
 		}
-	case 99:
+	case 105:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:517
+//line partiql.y:537
 		{
 			yyVAL.expr = &expr.Not{Expr: &expr.StringMatch{Op: expr.RegexpMatch, Expr: yyDollar[1].expr, Pattern: yyDollar[4].str}}
 		}
-	case 100:
+	case 106:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:521
+//line partiql.y:541
 		{
 			yyVAL.expr = &expr.Not{Expr: &expr.StringMatch{Op: expr.RegexpMatchCi, Expr: yyDollar[1].expr, Pattern: yyDollar[4].str}}
 		}
-	case 101:
+	case 107:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:525
+//line partiql.y:545
 		{
 			yyVAL.expr = &expr.Not{Expr: yyDollar[2].expr}
 		}
-	case 102:
+	case 108:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:529
+//line partiql.y:549
 		{
 			yyVAL.expr = expr.BitNot(yyDollar[2].expr)
 		}
-	case 103:
+	case 109:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:533
+//line partiql.y:553
 		{
 			yyVAL.expr = expr.And(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 104:
+	case 110:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:537
+//line partiql.y:557
 		{
 			yyVAL.expr = expr.Or(yyDollar[1].expr, yyDollar[3].expr)
 		}
-	case 105:
+	case 111:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:541
+//line partiql.y:561
 		{
 			yyVAL.expr = &expr.IsKey{Key: expr.IsNull, Expr: yyDollar[1].expr}
 		}
-	case 106:
+	case 112:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:545
+//line partiql.y:565
 		{
 			yyVAL.expr = &expr.IsKey{Key: expr.IsNotNull, Expr: yyDollar[1].expr}
 		}
-	case 107:
+	case 113:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:549
+//line partiql.y:569
 		{
 			yyVAL.expr = &expr.IsKey{Key: expr.IsMissing, Expr: yyDollar[1].expr}
 		}
-	case 108:
+	case 114:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:553
+//line partiql.y:573
 		{
 			yyVAL.expr = &expr.IsKey{Key: expr.IsNotMissing, Expr: yyDollar[1].expr}
 		}
-	case 109:
+	case 115:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:557
+//line partiql.y:577
 		{
 			yyVAL.expr = &expr.IsKey{Key: expr.IsTrue, Expr: yyDollar[1].expr}
 		}
-	case 110:
+	case 116:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:561
+//line partiql.y:581
 		{
 			yyVAL.expr = &expr.IsKey{Key: expr.IsNotTrue, Expr: yyDollar[1].expr}
 		}
-	case 111:
+	case 117:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:565
+//line partiql.y:585
 		{
 			yyVAL.expr = &expr.IsKey{Key: expr.IsFalse, Expr: yyDollar[1].expr}
 		}
-	case 112:
+	case 118:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:569
+//line partiql.y:589
 		{
 			yyVAL.expr = &expr.IsKey{Key: expr.IsNotFalse, Expr: yyDollar[1].expr}
 		}
-	case 113:
+	case 119:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:575
+//line partiql.y:595
 		{
 			yyVAL.bindings = []expr.Binding{yyDollar[1].bind}
 		}
-	case 114:
+	case 120:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:576
+//line partiql.y:596
 		{
 			yyVAL.bindings = append(yyDollar[1].bindings, yyDollar[3].bind)
 		}
-	case 115:
+	case 121:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:580
+//line partiql.y:600
 		{
 			yyVAL.values = []expr.Node{yyDollar[1].expr}
 		}
-	case 116:
+	case 122:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:581
+//line partiql.y:601
 		{
 			yyVAL.values = append(yyDollar[1].values, yyDollar[3].expr)
 		}
-	case 117:
+	case 123:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:585
+//line partiql.y:605
 		{
 			yyVAL.values = []expr.Node{yyDollar[1].expr}
 		}
-	case 118:
+	case 124:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:586
+//line partiql.y:606
 		{
 			yyVAL.values = []expr.Node{expr.Star{}}
 		}
-	case 119:
+	case 125:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:587
+//line partiql.y:607
 		{
 			yyVAL.values = append(yyDollar[1].values, yyDollar[3].expr)
 		}
-	case 120:
+	case 126:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:591
+//line partiql.y:611
 		{
 			yyVAL.values = []expr.Node{yyDollar[1].expr}
 		}
-	case 121:
+	case 127:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:592
+//line partiql.y:612
 		{
 			yyVAL.values = append(yyDollar[1].values, yyDollar[3].expr)
 		}
-	case 122:
+	case 128:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:593
+//line partiql.y:613
 		{
 			yyVAL.values = nil
 		}
-	case 123:
+	case 129:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:597
+//line partiql.y:617
 		{
 			yyVAL.values = yyDollar[1].values
 		}
-	case 124:
+	case 130:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:598
+//line partiql.y:618
 		{
 			yyVAL.values = append(yyDollar[1].values, yyDollar[3].values...)
 		}
-	case 125:
+	case 131:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:599
+//line partiql.y:619
 		{
 			yyVAL.values = nil
 		}
-	case 126:
+	case 132:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:603
+//line partiql.y:623
 		{
 			yyVAL.values = []expr.Node{expr.String(yyDollar[1].str), yyDollar[3].expr}
 		}
-	case 127:
+	case 133:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:607
+//line partiql.y:627
 		{
 			yyVAL.values = yyDollar[3].values
 		}
-	case 128:
+	case 134:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:610
+//line partiql.y:630
 		{
 			yyVAL.values = nil
 		}
-	case 129:
+	case 135:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line partiql.y:614
+//line partiql.y:634
 		{
 			yyVAL.wind = &expr.Window{PartitionBy: yyDollar[3].values, OrderBy: yyDollar[4].orders}
 		}
-	case 130:
+	case 136:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:617
+//line partiql.y:637
 		{
 			yyVAL.wind = nil
 		}
-	case 131:
+	case 137:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:620
+//line partiql.y:640
 		{
 			yyVAL.jk = expr.InnerJoin
 		}
-	case 132:
+	case 138:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:621
+//line partiql.y:641
 		{
 			yyVAL.jk = expr.InnerJoin
 		}
-	case 133:
+	case 139:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:622
+//line partiql.y:642
 		{
 			yyVAL.jk = expr.LeftJoin
 		}
-	case 134:
+	case 140:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:623
+//line partiql.y:643
 		{
 			yyVAL.jk = expr.LeftJoin
 		}
-	case 135:
+	case 141:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:624
+//line partiql.y:644
 		{
 			yyVAL.jk = expr.RightJoin
 		}
-	case 136:
+	case 142:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:625
+//line partiql.y:645
 		{
 			yyVAL.jk = expr.RightJoin
 		}
-	case 137:
+	case 143:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:626
+//line partiql.y:646
 		{
 			yyVAL.jk = expr.FullJoin
 		}
-	case 140:
+	case 144:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line partiql.y:647
+		{
+			yyVAL.jk = expr.FullJoin
+		}
+	case 147:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:631
+//line partiql.y:652
 		{
 			yyVAL.from = yyDollar[1].from
 		}
-	case 141:
+	case 148:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:632
+//line partiql.y:653
 		{
 			yyVAL.from = nil
 		}
-	case 142:
+	case 149:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:635
+//line partiql.y:656
 		{
 			yyVAL.from = &expr.Table{Binding: yyDollar[2].bind}
 		}
-	case 143:
+	case 150:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:636
+//line partiql.y:657
 		{
 			yyVAL.from = &expr.Join{Kind: expr.CrossJoin, Left: yyDollar[1].from, Right: yyDollar[3].bind}
 		}
-	case 144:
+	case 151:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line partiql.y:638
+//line partiql.y:659
 		{
 			yyVAL.from = &expr.Join{Kind: yyDollar[2].jk, Left: yyDollar[1].from, Right: yyDollar[3].bind, On: yyDollar[5].expr}
 		}
-	case 145:
+	case 152:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:641
+//line partiql.y:662
 		{
 			var idxerr error
 			yyVAL.integer, idxerr = toint(yyDollar[1].expr)
@@ -1962,239 +2023,239 @@ yydefault:
 				yylex.Error(idxerr.Error())
 			}
 		}
-	case 146:
+	case 153:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:650
+//line partiql.y:671
 		{
 			yyVAL.str = yyDollar[1].str
 		}
-	case 147:
+	case 154:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:653
+//line partiql.y:674
 		{
 			yyVAL.expr = nil
 		}
-	case 148:
+	case 155:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:654
+//line partiql.y:675
 		{
 			yyVAL.expr = yyDollar[2].expr
 		}
-	case 149:
+	case 156:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:657
+//line partiql.y:678
 		{
 			yyVAL.limbs = []expr.CaseLimb{{When: yyDollar[2].expr, Then: yyDollar[4].expr}}
 		}
-	case 150:
+	case 157:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line partiql.y:658
+//line partiql.y:679
 		{
 			yyVAL.limbs = append(yyDollar[1].limbs, expr.CaseLimb{When: yyDollar[3].expr, Then: yyDollar[5].expr})
 		}
-	case 151:
+	case 158:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:661
+//line partiql.y:682
 		{
 			yyVAL.expr = nil
 		}
-	case 152:
+	case 159:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:662
+//line partiql.y:683
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 153:
+	case 160:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:665
+//line partiql.y:686
 		{
 			yyVAL.expr = nil
 		}
-	case 154:
+	case 161:
 		yyDollar = yyS[yypt-5 : yypt+1]
-//line partiql.y:666
+//line partiql.y:687
 		{
 			yyVAL.expr = yyDollar[4].expr
 		}
-	case 155:
+	case 162:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:669
+//line partiql.y:690
 		{
 			yyVAL.expr = nil
 		}
-	case 156:
+	case 163:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:670
+//line partiql.y:691
 		{
 			yyVAL.expr = yyDollar[2].expr
 		}
-	case 157:
+	case 164:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:673
+//line partiql.y:694
 		{
 			yyVAL.expr = nil
 		}
-	case 158:
+	case 165:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:674
+//line partiql.y:695
 		{
 			yyVAL.expr = yyDollar[2].expr
 		}
-	case 159:
+	case 166:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:677
+//line partiql.y:698
 		{
 			yyVAL.bindings = nil
 		}
-	case 160:
+	case 167:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:678
+//line partiql.y:699
 		{
 			yyVAL.bindings = yyDollar[3].bindings
 		}
-	case 161:
+	case 168:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:682
+//line partiql.y:703
 		{
 			yyVAL.yesno = false
 		}
-	case 162:
+	case 169:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:683
+//line partiql.y:704
 		{
 			yyVAL.yesno = false
 		}
-	case 163:
+	case 170:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:684
+//line partiql.y:705
 		{
 			yyVAL.yesno = true
 		}
-	case 164:
+	case 171:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:688
+//line partiql.y:709
 		{
 			yyVAL.yesno = false
 		}
-	case 165:
+	case 172:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:689
+//line partiql.y:710
 		{
 			yyVAL.yesno = false
 		}
-	case 166:
+	case 173:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:690
+//line partiql.y:711
 		{
 			yyVAL.yesno = true
 		}
-	case 167:
+	case 174:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:694
+//line partiql.y:715
 		{
 			yyVAL.order = expr.Order{Column: yyDollar[1].expr, Desc: yyDollar[2].yesno, NullsLast: yyDollar[3].yesno}
 		}
-	case 168:
+	case 175:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:697
+//line partiql.y:718
 		{
 			yyVAL.orders = append(yyDollar[1].orders, yyDollar[3].order)
 		}
-	case 169:
+	case 176:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:698
+//line partiql.y:719
 		{
 			yyVAL.orders = []expr.Order{yyDollar[1].order}
 		}
-	case 170:
+	case 177:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:701
+//line partiql.y:722
 		{
 			yyVAL.orders = nil
 		}
-	case 171:
+	case 178:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line partiql.y:702
+//line partiql.y:723
 		{
 			yyVAL.orders = yyDollar[3].orders
 		}
-	case 172:
+	case 179:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:705
+//line partiql.y:726
 		{
 			yyVAL.exprint = nil
 		}
-	case 173:
+	case 180:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:706
+//line partiql.y:727
 		{
 			n := expr.Integer(yyDollar[2].integer)
 			yyVAL.exprint = &n
 		}
-	case 174:
+	case 181:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line partiql.y:709
+//line partiql.y:730
 		{
 			yyVAL.exprint = nil
 		}
-	case 175:
+	case 182:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line partiql.y:710
+//line partiql.y:731
 		{
 			n := expr.Integer(yyDollar[2].integer)
 			yyVAL.exprint = &n
 		}
-	case 176:
+	case 183:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line partiql.y:713
+//line partiql.y:734
 		{ /*Cloning, as the buffer gets overwritten*/
 			as := yyDollar[4].str
 			at := yyDollar[6].str
 			yyVAL.expr = &expr.Unpivot{TupleRef: yyDollar[2].expr, As: &as, At: &at}
 		}
-	case 177:
+	case 184:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line partiql.y:714
+//line partiql.y:735
 		{ /*Cloning, as the buffer gets overwritten*/
 			as := yyDollar[6].str
 			at := yyDollar[4].str
 			yyVAL.expr = &expr.Unpivot{TupleRef: yyDollar[2].expr, As: &as, At: &at}
 		}
-	case 178:
+	case 185:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:715
+//line partiql.y:736
 		{ /*Cloning, as the buffer gets overwritten*/
 			as := yyDollar[4].str
 			yyVAL.expr = &expr.Unpivot{TupleRef: yyDollar[2].expr, As: &as, At: nil}
 		}
-	case 179:
+	case 186:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line partiql.y:716
+//line partiql.y:737
 		{ /*Cloning, as the buffer gets overwritten*/
 			at := yyDollar[4].str
 			yyVAL.expr = &expr.Unpivot{TupleRef: yyDollar[2].expr, As: nil, At: &at}
 		}
-	case 180:
+	case 187:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:719
+//line partiql.y:740
 		{
 			yyVAL.expr = &expr.Table{Binding: expr.Bind(yyDollar[1].expr, "")}
 		}
-	case 181:
+	case 188:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:723
+//line partiql.y:744
 		{
 			yyVAL.integer = trimLeading
 		}
-	case 182:
+	case 189:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:724
+//line partiql.y:745
 		{
 			yyVAL.integer = trimTrailing
 		}
-	case 183:
+	case 190:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line partiql.y:725
+//line partiql.y:746
 		{
 			yyVAL.integer = trimBoth
 		}
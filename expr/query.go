@@ -82,6 +82,8 @@ func (q *Query) text(dst *strings.Builder, redact bool) {
 		dst.WriteString("EXPLAIN AS list ")
 	case ExplainGraphviz:
 		dst.WriteString("EXPLAIN AS graphviz ")
+	case ExplainAnalyze:
+		dst.WriteString("EXPLAIN AS analyze ")
 	}
 
 	if len(q.With) > 0 {
@@ -18,6 +18,7 @@ import (
 	"math/big"
 	"strings"
 
+	"github.com/SnellerInc/sneller/date"
 	"github.com/SnellerInc/sneller/ion"
 )
 
@@ -387,6 +388,14 @@ func cmpCase(c *Case, rewrite func(when Node) Node) *Case {
 }
 
 func (c *Comparison) simplify(h Hint) Node {
+	// compare timestamps against string literals
+	// as timestamps, so that the comparison can use
+	// the timestamp path and the sparse index
+	c.Left, c.Right = timestampLiteral(c.Left, c.Right, h), timestampLiteral(c.Right, c.Left, h)
+	if n := c.timestampOrString(h); n != nil {
+		return n.(simplifier).simplify(h)
+	}
+
 	c.Left = missingUnless(c.Left, h, ^(MissingType | NullType))
 	c.Right = missingUnless(c.Right, h, ^(MissingType | NullType))
 
@@ -404,6 +413,13 @@ func (c *Comparison) simplify(h Hint) Node {
 	if miss(left, h) || miss(right, h) {
 		return Missing{}
 	}
+	// comparing against NULL is never TRUE
+	if _, ok := left.(Null); ok {
+		return Null{}
+	}
+	if _, ok := right.(Null); ok {
+		return Null{}
+	}
 
 	if l := asrational(left); l != nil {
 		if r := asrational(right); r != nil {
@@ -749,8 +765,15 @@ func (a *Arithmetic) simplify(h Hint) Node {
 }
 
 func (j *Join) simplify(h Hint) Node {
-	// <a> [LEFT] JOIN <b> ON TRUE -> <a> CROSS JOIN <b>
-	if j.On == Bool(true) && (j.Kind == InnerJoin || j.Kind == LeftJoin) {
+	if u, ok := j.Right.Expr.(*Builtin); ok && u.Func == Unnest {
+		// LEFT JOIN UNNEST(...) ON TRUE preserves
+		// the rows that CROSS JOIN UNNEST(...) drops
+		return j
+	}
+	// <a> JOIN <b> ON TRUE -> <a> CROSS JOIN <b>
+	// (but a LEFT JOIN still has to keep the rows of <a>
+	// if <b> is empty)
+	if j.On == Bool(true) && j.Kind == InnerJoin {
 		j.Kind = CrossJoin
 		j.On = nil
 		return j
@@ -777,6 +800,13 @@ func (n *Not) simplify(h Hint) Node {
 	if miss(n.Expr, h) || null(n.Expr, h) {
 		return Missing{}
 	}
+	// push the negation into CASE limbs,
+	// which are all logical at this point
+	if cs, ok := n.Expr.(*Case); ok {
+		return Simplify(cmpCase(cs, func(then Node) Node {
+			return &Not{Expr: then}
+		}), h)
+	}
 	return n
 }
 
@@ -833,7 +863,7 @@ func (a *Aggregate) simplify(h Hint) Node {
 	}
 
 	if a.Filter != nil {
-		iscount := (a.Op == OpCount || a.Op == OpCountDistinct || a.Op == OpApproxCountDistinct)
+		iscount := (a.Op == OpCount || a.Op == OpCountDistinct || a.Op == OpApproxCountDistinct || a.Op == OpCountIf)
 		switch v := a.Filter.(type) {
 		case Null, Missing:
 			if iscount {
@@ -909,6 +939,57 @@ func (c *Case) isTrue(h Hint) Node {
 	return c.simplify(h)
 }
 
+// timestampLiteral returns lit as a timestamp
+// if it is a string literal holding a valid timestamp
+// and other is an expression that produces timestamps;
+// otherwise it returns lit unchanged
+func timestampLiteral(lit, other Node, h Hint) Node {
+	s, ok := lit.(String)
+	if !ok || !TypeOf(other, h).Only(TimeType|MissingType) {
+		return lit
+	}
+	t, ok := date.Parse([]byte(s))
+	if !ok {
+		return lit
+	}
+	return &Timestamp{Value: t}
+}
+
+// timestampOrString handles a comparison between a string
+// literal holding a valid timestamp and an expression that
+// may produce timestamps or other values (such as a plain
+// path expression) by splitting it into a comparison with
+// the equivalent timestamp (which can use the sparse index)
+// and a comparison of strings with the original literal,
+// so that the result does not change for rows that hold
+// strings. It returns nil if the comparison does not have
+// that shape.
+func (c *Comparison) timestampOrString(h Hint) Node {
+	split := func(lit, other Node) (Node, Node, bool) {
+		s, ok := lit.(String)
+		if !ok {
+			return nil, nil, false
+		}
+		t := TypeOf(other, h)
+		if !t.AnyOf(TimeType) || t.Only(TimeType|MissingType) {
+			return nil, nil, false
+		}
+		ts, ok := date.Parse([]byte(s))
+		if !ok {
+			return nil, nil, false
+		}
+		str := Call(AssertIonType, other, Integer(ion.StringType), Integer(ion.SymbolType))
+		return &Timestamp{Value: ts}, str, true
+	}
+	if ts, str, ok := split(c.Right, c.Left); ok {
+		return Or(Compare(c.Op, c.Left, ts), Compare(c.Op, str, c.Right))
+	}
+	if ts, str, ok := split(c.Left, c.Right); ok {
+		return Or(Compare(c.Op, ts, c.Right), Compare(c.Op, c.Left, str))
+	}
+	return nil
+}
+
 // missingUnless simplifies a node
 // taking into account that the calling
 // expression will be MISSING unless
@@ -543,6 +543,29 @@ func TestSimplify(t *testing.T) {
 			In(Call(Concat, path("x"), String("suffix")), String("start-suffix"), Integer(3), Bool(false)),
 			Compare(Equals, Call(Concat, path("x"), String("suffix")), String("start-suffix")),
 		},
+		{
+			// comparing against NULL is NULL
+			Compare(Equals, path("x"), Null{}),
+			Null{},
+		},
+		{
+			In(path("x"), Integer(1), Null{}),
+			&Case{
+				Limbs: []CaseLimb{{When: Compare(Equals, path("x"), Integer(1)), Then: Bool(true)}},
+				Else:  Null{},
+			},
+		},
+		{
+			NotIn(path("x"), Integer(1), Null{}),
+			&Case{
+				Limbs: []CaseLimb{{When: Compare(Equals, path("x"), Integer(1)), Then: Bool(false)}},
+				Else:  Null{},
+			},
+		},
+		{
+			In(path("x"), Null{}),
+			Null{},
+		},
 		{
 			// SIZE(path) is unchanged
 			Call(ObjectSize, path("x")),
@@ -816,6 +839,66 @@ func TestSimplify(t *testing.T) {
 				Add(Call(CharLength, path("y")), Integer(10))),
 				Call(CharLength, path("z"))),
 		},
+		{
+			// ASCII('żółw') => 380
+			Call(CodePoint, String("żółw")),
+			Integer(380),
+		},
+		{
+			Call(CodePoint, String("")),
+			Missing{},
+		},
+		{
+			// overlong encodings are accepted, like in the VM
+			Call(CodePoint, String("\xc0\x80")),
+			Integer(0),
+		},
+		{
+			Call(CodePoint, String("\xe0\x81\x81z")),
+			Integer('A'),
+		},
+		{
+			Call(CodePoint, String("\xc4")),
+			Missing{},
+		},
+		{
+			Call(CodePoint, String("\xe2\x28\xa1")),
+			Missing{},
+		},
+		{
+			// CHR(380) => 'ż'
+			Call(Chr, Integer(380)),
+			String("ż"),
+		},
+		{
+			// CHR(0xd800) => MISSING
+			Call(Chr, Integer(0xd800)),
+			Missing{},
+		},
+		{
+			// CHR(65.7) => MISSING
+			Call(Chr, Float(65.7)),
+			Missing{},
+		},
+		{
+			// IS_NAN(1.5) => FALSE
+			Call(IsNaN, Float(1.5)),
+			Bool(false),
+		},
+		{
+			Call(IsNaN, Integer(3)),
+			Bool(false),
+		},
+		{
+			// TRANSLATE('12345', '143', 'ax') => 'a2x5'
+			Call(Translate, String("12345"), String("143"), String("ax")),
+			String("a2x5"),
+		},
+		{
+			// TRANSLATE(s, '', 'abc') => s
+			Call(Translate, path("s"), String(""), String("abc")),
+			path("s"),
+		},
 		{
 			Call(Concat, Call(Concat, path("x"), String("a")), String("b")),
 			Call(Concat, path("x"), String("ab")),
@@ -992,6 +1075,36 @@ func TestSimplify(t *testing.T) {
 			&Index{Inner: mktestlist(String("a"), String("b"), String("c")), Offset: 1},
 			String("b"),
 		},
+		{
+			// ["a", "b", "c"][-1] => "c"
+			&Index{Inner: Call(MakeList, String("a"), String("b"), String("c")), Offset: -1},
+			String("c"),
+		},
+		{
+			// ["a", "b", "c"][-3] => "a"
+			&Index{Inner: mktestlist(String("a"), String("b"), String("c")), Offset: -3},
+			String("a"),
+		},
+		{
+			// ["a", "b", "c"][-4] => MISSING
+			&Index{Inner: Call(MakeList, String("a"), String("b"), String("c")), Offset: -4},
+			Missing{},
+		},
+		{
+			// ["a", "b", "c"][1:] => ["b", "c"]
+			&Slice{Inner: Call(MakeList, String("a"), String("b"), String("c")), Start: 1},
+			mktestlist(String("b"), String("c")),
+		},
+		{
+			// ["a", "b", "c"][-5:2] => ["a", "b"]
+			&Slice{Inner: mktestlist(String("a"), String("b"), String("c")), Start: -5, End: intptr(2)},
+			mktestlist(String("a"), String("b")),
+		},
+		{
+			// ["a", "b", "c"][2:1] => []
+			&Slice{Inner: mktestlist(String("a"), String("b"), String("c")), Start: 2, End: intptr(1)},
+			mktestlist(),
+		},
 		{
 			// SELECT * FROM ... ORDER BY const1, ..., constN => drop ORDER BY
 			&Select{OrderBy: []Order{
@@ -1128,6 +1241,40 @@ func TestSimplify(t *testing.T) {
 			Mod((*Rational)(big.NewRat(8, 10)), (*Rational)(big.NewRat(43, 10))),
 			(*Rational)(big.NewRat(8, 10)),
 		},
+		{
+			// string literals compared with timestamps become timestamps
+			Compare(GreaterEquals, DateTrunc(Day, path("x")), String("2023-01-01T00:00:00Z")),
+			Compare(GreaterEquals, DateTrunc(Day, path("x")), ts("2023-01-01T00:00:00Z")),
+		},
+		{
+			Compare(Less, String("2023-01-02T03:04:05.5Z"), DateTrunc(Day, path("x"))),
+			Compare(Greater, DateTrunc(Day, path("x")), ts("2023-01-02T03:04:05.5Z")),
+		},
+		{
+			Compare(Equals, DateTrunc(Hour, path("x")), String("2023-01-01T10:00:00+01:00")),
+			Compare(Equals, DateTrunc(Hour, path("x")), ts("2023-01-01T09:00:00Z")),
+		},
+		{
+			// unparseable strings are left alone
+			Compare(Less, DateTrunc(Day, path("x")), String("yesterday")),
+			Compare(Less, DateTrunc(Day, path("x")), String("yesterday")),
+		},
+		{
+			// expressions that are not known to be timestamps
+			// are compared both as timestamps and as strings
+			Compare(GreaterEquals, path("x"), String("2023-01-01T00:00:00Z")),
+			Or(Compare(GreaterEquals, path("x"), ts("2023-01-01T00:00:00Z")),
+				Compare(GreaterEquals, Call(AssertIonType, path("x"), Integer(ion.StringType), Integer(ion.SymbolType)), String("2023-01-01T00:00:00Z"))),
+		},
+		{
+			Compare(Less, String("2023-01-01T00:00:00Z"), path("x")),
+			Or(Compare(Less, ts("2023-01-01T00:00:00Z"), path("x")),
+				Compare(Less, String("2023-01-01T00:00:00Z"), Call(AssertIonType, path("x"), Integer(ion.StringType), Integer(ion.SymbolType)))),
+		},
+		{
+			Compare(Equals, path("x"), String("yesterday")),
+			Compare(Equals, path("x"), String("yesterday")),
+		},
 	}
 
 	for i := range testcases {
@@ -1179,6 +1326,8 @@ func TestSimplifyWithNaN(t *testing.T) {
 
 		// sign (via rules)
 		Call(Sign, NaN),
+
+		Call(IsNaN, NaN),
 	}
 
 	for i := range expressions {
@@ -1232,3 +1381,7 @@ func mktestlist(values ...Constant) *List {
 		Values: values,
 	}
 }
+
+func intptr(i int) *int {
+	return &i
+}
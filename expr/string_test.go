@@ -212,6 +212,10 @@ func TestParsePath(t *testing.T) {
 			str:  "x[0]",
 			want: &Index{Ident("x"), 0},
 		},
+		{
+			str:  "x[-1]",
+			want: &Index{Ident("x"), -1},
+		},
 		{
 			str:  "first.second[100]",
 			want: &Index{&Dot{Ident("first"), "second"}, 100},
@@ -274,6 +274,25 @@ CONST_DATA_U32(aggregate_conflictdq_norm, 56, $55)
 CONST_DATA_U32(aggregate_conflictdq_norm, 60, $55)
 CONST_GLOBAL(aggregate_conflictdq_norm, $64)
 
+// Consecutive DWORD offsets for 1 ZMM register incremented by 4, for each lane.
+CONST_DATA_U32(consts_offsets_d_4,  0, $(0  * 4))
+CONST_DATA_U32(consts_offsets_d_4,  4, $(1  * 4))
+CONST_DATA_U32(consts_offsets_d_4,  8, $(2  * 4))
+CONST_DATA_U32(consts_offsets_d_4, 12, $(3  * 4))
+CONST_DATA_U32(consts_offsets_d_4, 16, $(4  * 4))
+CONST_DATA_U32(consts_offsets_d_4, 20, $(5  * 4))
+CONST_DATA_U32(consts_offsets_d_4, 24, $(6  * 4))
+CONST_DATA_U32(consts_offsets_d_4, 28, $(7  * 4))
+CONST_DATA_U32(consts_offsets_d_4, 32, $(8  * 4))
+CONST_DATA_U32(consts_offsets_d_4, 36, $(9  * 4))
+CONST_DATA_U32(consts_offsets_d_4, 40, $(10 * 4))
+CONST_DATA_U32(consts_offsets_d_4, 44, $(11 * 4))
+CONST_DATA_U32(consts_offsets_d_4, 48, $(12 * 4))
+CONST_DATA_U32(consts_offsets_d_4, 52, $(13 * 4))
+CONST_DATA_U32(consts_offsets_d_4, 56, $(14 * 4))
+CONST_DATA_U32(consts_offsets_d_4, 60, $(15 * 4))
+CONST_GLOBAL(consts_offsets_d_4, $64)
+
 // Consecutive DWORD offsets for 1 ZMM register incremented by 8, for each lane.
 CONST_DATA_U32(consts_offsets_d_8,  0, $(0  * 8))
 CONST_DATA_U32(consts_offsets_d_8,  4, $(1  * 8))
@@ -32,11 +32,13 @@ func AddFloat64(ptr *float64, value float64) {
 	}
 }
 
+// MinFloat64 atomically stores min(*ptr, value) in *ptr;
+// like math.Min, a NaN in either operand is propagated
 func MinFloat64(ptr *float64, value float64) {
 	for {
 		before := math.Float64frombits(atomic.LoadUint64((*uint64)(unsafe.Pointer(ptr))))
 
-		if before <= value {
+		if before <= value || math.IsNaN(before) {
 			return
 		}
 
@@ -46,11 +48,13 @@ func MinFloat64(ptr *float64, value float64) {
 	}
 }
 
+// MaxFloat64 atomically stores max(*ptr, value) in *ptr;
+// like math.Max, a NaN in either operand is propagated
 func MaxFloat64(ptr *float64, value float64) {
 	for {
 		before := math.Float64frombits(atomic.LoadUint64((*uint64)(unsafe.Pointer(ptr))))
 
-		if before >= value {
+		if before >= value || math.IsNaN(before) {
 			return
 		}
 
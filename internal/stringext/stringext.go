@@ -18,6 +18,7 @@ package stringext
 import (
 	"encoding/binary"
 	"fmt"
+	"math/bits"
 	"slices"
 	"strconv"
 	"strings"
@@ -791,6 +792,30 @@ func NormalizeStringASCIIOnly(bytes []byte) []byte {
 	return result
 }
 
+// DecodeCodePoint decodes the first character of buf the way the
+// CODEPOINT opcode does: the leading byte and the continuation bytes
+// are checked but, unlike utf8.DecodeRune, overlong encodings are accepted
+func DecodeCodePoint(buf []byte) (rune, bool) {
+	if len(buf) == 0 {
+		return 0, false
+	}
+	n := bits.LeadingZeros8(^buf[0])
+	if n == 0 {
+		return rune(buf[0]), true
+	}
+	if n == 1 || n > 4 || n > len(buf) {
+		return 0, false
+	}
+	r := rune(buf[0] & (0x7f >> n))
+	for _, c := range buf[1:n] {
+		if c&0xc0 != 0x80 {
+			return 0, false
+		}
+		r = r<<6 | rune(c&0x3f)
+	}
+	return r, true
+}
+
 // HasNtnRune return true when the provided rune contains a non-trivial normalization; false otherwise
 func HasNtnRune(r rune) bool {
 	if EqualRuneFold(r, 'S') || EqualRuneFold(r, 'K') {
@@ -144,6 +144,38 @@ func TestSplitEscape(t *testing.T) {
 	}
 }
 
+func TestDecodeCodePoint(t *testing.T) {
+	unitTests := []struct {
+		str string
+		r   rune
+		ok  bool
+	}{
+		{"a", 'a', true},
+		{"\x7f", 0x7f, true},
+		{"ą", 'ą', true},
+		{"€", '€', true},
+		{"😀", '😀', true},
+		{"日本", '日', true},
+		{"\xc0\x80", 0, true}, // overlong encodings are accepted
+		{"\xe0\x81\x81", 'A', true},
+		{"a\x80", 'a', true},
+		{"", 0, false},
+		{"\x80", 0, false},
+		{"\xc4", 0, false},
+		{"\xe2\x82", 0, false},
+		{"\xe2\x28\xa1", 0, false},
+		{"\xf0\x9f\x98", 0, false},
+		{"\xf8\x80\x80\x80", 0, false},
+		{"\xff", 0, false},
+	}
+	for _, ut := range unitTests {
+		r, ok := DecodeCodePoint([]byte(ut.str))
+		if r != ut.r || ok != ut.ok {
+			t.Errorf("DecodeCodePoint(%q) = %d, %v; expected %d, %v", ut.str, r, ok, ut.r, ut.ok)
+		}
+	}
+}
+
 func TestSimplifyLikeExpr(t *testing.T) {
 	type unitTest struct {
 		str       string        // expression
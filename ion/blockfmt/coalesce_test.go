@@ -16,6 +16,7 @@ package blockfmt
 
 import (
 	"fmt"
+	"hash/crc32"
 	"reflect"
 	"testing"
 )
@@ -82,3 +83,30 @@ func TestCoalesce(t *testing.T) {
 		})
 	}
 }
+
+func TestCoalesceChecksums(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	sizes := []int{3, 10, 1, 20, 9}
+	blocks := make([]blockpart, len(sizes))
+	off := 0
+	for i, size := range sizes {
+		blocks[i] = blockpart{
+			offset:   int64(off),
+			size:     int64(size),
+			chunks:   1,
+			checksum: crc32.Checksum(data[off:off+size], castagnoli),
+		}
+		off += size
+	}
+	got := coalesce(blocks, 3)
+	for i := range got {
+		b := &got[i]
+		want := crc32.Checksum(data[b.offset:b.offset+b.size], castagnoli)
+		if b.checksum != want {
+			t.Errorf("block %d: checksum %08x, want %08x", i, b.checksum, want)
+		}
+	}
+	if n := len(got); n != 1 {
+		t.Errorf("got %d blocks", n)
+	}
+}
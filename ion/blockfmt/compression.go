@@ -19,6 +19,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"math/bits"
 	"strings"
@@ -36,21 +37,47 @@ var debugFree = false
 // we have frozen the ranges and built
 // a sparse index of the offsets
 type blockpart struct {
-	offset int64
-	chunks int
-	ranges []TimeRange
+	offset   int64
+	size     int64 // compressed size
+	chunks   int
+	ranges   []TimeRange
+	checksum uint32
+	rows     int64 // -1 if unknown
 }
 
 func toDescs(dst []Blockdesc, src []blockpart) []Blockdesc {
 	for i := range src {
 		dst = append(dst, Blockdesc{
-			src[i].offset,
-			src[i].chunks,
+			Offset:   src[i].offset,
+			Chunks:   src[i].chunks,
+			Checksum: src[i].checksum,
+			Rows:     max(src[i].rows, 0),
 		})
 	}
 	return dst
 }
 
+// CountRows returns the number of top-level
+// structures in a chunk of ion data.
+func CountRows(chunk []byte) int64 {
+	n := int64(0)
+	for len(chunk) > 0 {
+		if ion.IsBVM(chunk) {
+			chunk = chunk[4:]
+			continue
+		}
+		if ion.TypeOf(chunk) == ion.StructType {
+			n++
+		}
+		size := ion.SizeOf(chunk)
+		if size <= 0 || size > len(chunk) {
+			break
+		}
+		chunk = chunk[size:]
+	}
+	return n
+}
+
 type Compressor interface {
 	Name() string
 	Compress(src, dst []byte) ([]byte, error)
@@ -177,6 +204,8 @@ type CompressionWriter struct {
 	minsize     int
 	lastblock   int64
 	flushblocks int
+	checksum    uint32 // checksum of the frames since lastblock
+	rows        int64  // rows in the frames since lastblock, or -1 if unknown
 	skipChecks  bool
 
 	// metadata to be attached
@@ -233,12 +262,17 @@ func (w *CompressionWriter) Flush() error {
 		return nil
 	}
 	w.blocks = append(w.blocks, blockpart{
-		offset: w.lastblock,
-		chunks: w.flushblocks,
-		ranges: w.futureRange.pop(),
+		offset:   w.lastblock,
+		size:     w.offset - w.lastblock,
+		chunks:   w.flushblocks,
+		ranges:   w.futureRange.pop(),
+		checksum: w.checksum,
+		rows:     w.rows,
 	})
 	w.lastblock = w.offset
 	w.flushblocks = 0
+	w.checksum = 0
+	w.rows = 0
 	return nil
 }
 
@@ -320,6 +354,7 @@ func (w *CompressionWriter) setSymbols(st *ion.Symtab) {
 func (w *CompressionWriter) writeCompressed(p []byte) error {
 	before := len(w.buffer)
 	w.buffer = appendRawFrame(w.buffer, p)
+	w.rows = -1 // not decompressed, so unknown
 	return w.checkFlush(before)
 }
 
@@ -340,11 +375,15 @@ func (w *CompressionWriter) Write(p []byte) (n int, err error) {
 	if err != nil {
 		return
 	}
+	if w.rows >= 0 {
+		w.rows += CountRows(p)
+	}
 	return len(p), w.checkFlush(before)
 }
 
 func (w *CompressionWriter) checkFlush(before int) error {
 	w.flushblocks++
+	w.checksum = crc32.Update(w.checksum, castagnoli, w.buffer[before:])
 	w.offset += int64(len(w.buffer) - before)
 	if len(w.buffer) >= w.target() {
 		err := w.upload()
@@ -399,7 +438,7 @@ func (t *Trailer) trailer(compname string, align int) []byte {
 	var st ion.Symtab
 	var buf ion.Buffer
 
-	t.Version = 1
+	t.Version = t.minVersion()
 	t.Algo = compname
 	t.BlockShift = bits.TrailingZeros(uint(align))
 
@@ -482,6 +521,70 @@ func appendRawFrame(dst, src []byte) []byte {
 	return dst
 }
 
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// combineChecksums returns the CRC-32C checksum
+// of the concatenation of two byte sequences
+// given the checksum of each sequence and
+// the length of the second one
+//
+// (this is crc32_combine from zlib)
+func combineChecksums(crc1, crc2 uint32, len2 int64) uint32 {
+	if len2 <= 0 {
+		return crc1
+	}
+	// odd is the operator for one zero bit,
+	// which is the polynomial in the first row
+	// followed by shifting each bit by one
+	var even, odd [32]uint32
+	odd[0] = crc32.Castagnoli
+	row := uint32(1)
+	for i := 1; i < 32; i++ {
+		odd[i] = row
+		row <<= 1
+	}
+	gf2square(&even, &odd) // two zero bits
+	gf2square(&odd, &even) // four zero bits
+
+	// apply len2 zero bytes to crc1,
+	// starting with eight zero bits
+	for {
+		gf2square(&even, &odd)
+		if len2&1 != 0 {
+			crc1 = gf2times(&even, crc1)
+		}
+		len2 >>= 1
+		if len2 == 0 {
+			break
+		}
+		gf2square(&odd, &even)
+		if len2&1 != 0 {
+			crc1 = gf2times(&odd, crc1)
+		}
+		len2 >>= 1
+		if len2 == 0 {
+			break
+		}
+	}
+	return crc1 ^ crc2
+}
+
+func gf2times(mat *[32]uint32, vec uint32) uint32 {
+	sum := uint32(0)
+	for i := 0; vec != 0; i, vec = i+1, vec>>1 {
+		if vec&1 != 0 {
+			sum ^= mat[i]
+		}
+	}
+	return sum
+}
+
+func gf2square(square, mat *[32]uint32) {
+	for i := range square {
+		square[i] = gf2times(mat, mat[i])
+	}
+}
+
 // append a compressed frame to dst
 // that is wrapped in an ion 'blob' tag
 func appendFrame(dst []byte, comp Compressor, src []byte) ([]byte, error) {
@@ -585,8 +688,11 @@ func (z *zionDecompressor) Decompress(src, dst []byte) error {
 	if err != nil {
 		return err
 	}
-	if &ret[0] != &dst[0] || len(ret) > len(dst) {
-		return fmt.Errorf("blockfmt: zion.Decode output %d (> %d) bytes", len(ret), len(dst))
+	if len(ret) > len(dst) {
+		return fmt.Errorf("%w: zion.Decode output %d (> %d) bytes", ErrBlockTooLarge, len(ret), len(dst))
+	}
+	if len(ret) > 0 && &ret[0] != &dst[0] {
+		return fmt.Errorf("blockfmt: zion.Decode output buffer realloc'd")
 	}
 	// in order to produce bit-identical results
 	// to the original input buffer, we need to
@@ -599,6 +705,13 @@ func (z *zionDecompressor) Decompress(src, dst []byte) error {
 	return nil
 }
 
+func (d decompressNopCloser) DecodedLen(src []byte) (int, error) {
+	if s, ok := d.Decompressor.(compr.Sizer); ok {
+		return s.DecodedLen(src)
+	}
+	return 0, fmt.Errorf("%s: decompressed size not recorded", d.Name())
+}
+
 func getAlgo(algo string) decompressor {
 	switch algo {
 	case "zion", "zion+zstd", "zion+iguana_v0", "zion+iguana_v0/specialized":
@@ -641,11 +754,45 @@ type Decoder struct {
 	// data allocated via Malloc.
 	Free func([]byte)
 
+	// MaxBlockBytes, if positive, is the maximum
+	// decompressed size of a single block.
+	// Decompress, Copy, and CopyBytes return
+	// ErrBlockTooLarge rather than decoding
+	// blocks that are larger than MaxBlockBytes.
+	// Independently of MaxBlockBytes, they also
+	// return ErrBlockTooLarge for a frame that
+	// decodes to more than the block size, so
+	// the output of a block never exceeds the
+	// limit. (Frames that are passed directly
+	// to a ZionWriter are decoded by the writer.)
+	MaxBlockBytes int64
+
+	// Verify, if set, causes Copy and CopyBytes
+	// to compare the checksum of the data they
+	// read against Checksum and return ErrChecksum
+	// if they do not match.
+	Verify bool
+	// Checksum is the expected CRC-32C checksum
+	// of the data passed to Copy or CopyBytes when
+	// Verify is set. For a single block this is
+	// the Blockdesc.Checksum of the block.
+	// A zero Checksum is not verified.
+	Checksum uint32
+
 	decomp decompressor
 	frame  [5]byte
 	tmp    []byte
 }
 
+// ErrBlockTooLarge is returned by Decoder
+// when the size of a block exceeds Decoder.MaxBlockBytes.
+var ErrBlockTooLarge = errors.New("blockfmt: block size exceeds limit")
+
+// ErrChecksum is returned by Decoder when
+// the checksum of the data does not match
+// Decoder.Checksum.
+var ErrChecksum = errors.New("blockfmt: checksum mismatch")
+
 // Set copies the [Algo] and [BlockShift] fields
 // from [t] into [d].
 func (d *Decoder) Set(t *Trailer) {
@@ -653,6 +800,51 @@ func (d *Decoder) Set(t *Trailer) {
 	d.Algo = t.Algo
 }
 
+// checkBlockSize returns an error if the
+// decompressed size of each block is invalid
+// or larger than d.MaxBlockBytes
+func (d *Decoder) checkBlockSize() error {
+	if d.BlockShift < 0 || d.BlockShift >= 62 {
+		return fmt.Errorf("blockfmt: invalid block shift %d", d.BlockShift)
+	}
+	if d.MaxBlockBytes > 0 && int64(1)<<d.BlockShift > d.MaxBlockBytes {
+		return fmt.Errorf("%w: %d > %d", ErrBlockTooLarge, int64(1)<<d.BlockShift, d.MaxBlockBytes)
+	}
+	return nil
+}
+
+// verify compares sum against d.Checksum
+// if checksum verification is enabled
+func (d *Decoder) verify(sum uint32) error {
+	if !d.Verify || d.Checksum == 0 || sum == d.Checksum {
+		return nil
+	}
+	return fmt.Errorf("%w: got %08x, want %08x", ErrChecksum, sum, d.Checksum)
+}
+
+// verifyFrames checks the checksum of
+// the frames in src before anything has
+// been written (see CopyBytes)
+func (d *Decoder) verifyFrames(src []byte) error {
+	if !d.Verify || d.Checksum == 0 {
+		return nil
+	}
+	return d.verify(crc32.Checksum(src, castagnoli))
+}
+
+// decompress decompresses the frame src into
+// the block dst; frames that record a larger
+// decompressed size are rejected before they
+// are decompressed
+func (d *Decoder) decompress(src, dst []byte) error {
+	if s, ok := d.decomp.(compr.Sizer); ok {
+		if n, err := s.DecodedLen(src); err == nil && n > len(dst) {
+			return fmt.Errorf("%w: frame of %d bytes > block of %d bytes", ErrBlockTooLarge, n, len(dst))
+		}
+	}
+	return d.decomp.Decompress(src, dst)
+}
+
 func (d *Decoder) realloc(size int) []byte {
 	if d.tmp == nil {
 		d.tmp = malloc(size)
@@ -717,7 +909,7 @@ func (d *Decoder) decompressBlocks(src io.Reader, dst []byte) (int, error) {
 		if err != nil {
 			return off, err
 		}
-		err = d.decomp.Decompress(buf, dst[off:off+bs])
+		err = d.decompress(buf, dst[off:off+bs])
 		if err != nil {
 			return 0, fmt.Errorf("decompress @ offset %d block %d size %d: %w", count-n, block, size, err)
 		}
@@ -754,6 +946,9 @@ func (d *Decoder) Decompress(src io.Reader, dst []byte) (int, error) {
 	if d.tmp != nil {
 		panic("concurrent blockfmt.Decoder calls")
 	}
+	if err := d.checkBlockSize(); err != nil {
+		return 0, err
+	}
 	err := d.getDecomp(d.Algo)
 	if err != nil {
 		return 0, err
@@ -785,12 +980,13 @@ func (d *Decoder) copyZion(w io.Writer, src []byte) (int64, error) {
 // same as d.copyZion(), but for an io.Reader
 func (d *Decoder) copyZionFrom(w io.Writer, src io.Reader) (int64, error) {
 	nn := int64(0)
+	sum := uint32(0)
 	defer d.free()
 	for {
 		_, err := io.ReadFull(src, d.frame[:])
 		if err == io.EOF {
 			// we are done
-			return nn, nil
+			return nn, d.verify(sum)
 		}
 		if err != nil {
 			return nn, err
@@ -810,6 +1006,10 @@ func (d *Decoder) copyZionFrom(w io.Writer, src io.Reader) (int64, error) {
 		if err != nil {
 			return nn, err
 		}
+		if d.Verify {
+			sum = crc32.Update(sum, castagnoli, d.frame[:])
+			sum = crc32.Update(sum, castagnoli, buf)
+		}
 		_, err = w.Write(buf)
 		if err != nil {
 			return nn, err
@@ -843,7 +1043,16 @@ func (d *Decoder) acceptsZion(w io.Writer) bool {
 // if any. If dst implements ZionWriter and d.Algo is "zion"
 // then compressed data may be passed directly to dst
 // (see ZionWriter for more details).
+//
+// If d.Verify is set, the checksum of src is
+// verified before any data is written to dst.
 func (d *Decoder) CopyBytes(dst io.Writer, src []byte) (int64, error) {
+	if err := d.checkBlockSize(); err != nil {
+		return 0, err
+	}
+	if err := d.verifyFrames(src); err != nil {
+		return 0, err
+	}
 	size := 1 << d.BlockShift
 	if strings.HasPrefix(d.Algo, "zion") {
 		if d.acceptsZion(dst) {
@@ -872,7 +1081,7 @@ func (d *Decoder) CopyBytes(dst io.Writer, src []byte) (int64, error) {
 		if size < 5 || size > len(src) {
 			return nn, fmt.Errorf("unexpected frame size %d", size)
 		}
-		err := d.decomp.Decompress(src[5:size], vmm)
+		err := d.decompress(src[5:size], vmm)
 		if err != nil {
 			return nn, err
 		}
@@ -895,10 +1104,18 @@ func (d *Decoder) CopyBytes(dst io.Writer, src []byte) (int64, error) {
 // via d.Malloc, so dst may be an io.Writer
 // returned via a vm.QuerySink provided that d.Malloc
 // is set to vm.Malloc.
+//
+// If d.Verify is set, the checksum of src is
+// verified once all of src has been read, so
+// dst may have been written to already when
+// Copy returns ErrChecksum.
 func (d *Decoder) Copy(dst io.Writer, src io.Reader) (int64, error) {
 	if d.tmp != nil {
 		panic("concurrent blockfmt.Decoder calls")
 	}
+	if err := d.checkBlockSize(); err != nil {
+		return 0, err
+	}
 	if strings.HasPrefix(d.Algo, "zion") && d.acceptsZion(dst) {
 		return d.copyZionFrom(dst, src)
 	}
@@ -908,6 +1125,7 @@ func (d *Decoder) Copy(dst io.Writer, src io.Reader) (int64, error) {
 		return 0, err
 	}
 	nn := int64(0)
+	sum := uint32(0)
 	size := 1 << d.BlockShift
 	vmm := d.malloc(size)
 	defer d.drop(vmm)
@@ -915,7 +1133,7 @@ func (d *Decoder) Copy(dst io.Writer, src io.Reader) (int64, error) {
 		_, err := io.ReadFull(src, d.frame[:])
 		if err == io.EOF {
 			// we are done
-			return nn, nil
+			return nn, d.verify(sum)
 		}
 		if err != nil {
 			return nn, err
@@ -935,7 +1153,11 @@ func (d *Decoder) Copy(dst io.Writer, src io.Reader) (int64, error) {
 		if err != nil {
 			return nn, err
 		}
-		err = d.decomp.Decompress(buf, vmm)
+		if d.Verify {
+			sum = crc32.Update(sum, castagnoli, d.frame[:])
+			sum = crc32.Update(sum, castagnoli, buf)
+		}
+		err = d.decompress(buf, vmm)
 		if err != nil {
 			return nn, err
 		}
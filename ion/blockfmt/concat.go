@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/SnellerInc/sneller/date"
+	"github.com/SnellerInc/sneller/expr"
 )
 
 // concat wraps a list of Descriptors
@@ -59,8 +60,11 @@ func (c *concat) add(src *Descriptor) bool {
 		dt.Sparse = t.Sparse.Clone()
 	} else {
 		dt := &c.output.Trailer
-		// ensure trailer is compatible
-		if t.Version != dt.Version ||
+		// ensure trailer is compatible;
+		// versions 1 and 2 only differ
+		// in the optional fields they
+		// may contain
+		if max(t.Version, dt.Version) > 2 && t.Version != dt.Version ||
 			t.Algo != dt.Algo ||
 			t.BlockShift != dt.BlockShift ||
 			!dt.Sparse.Append(&t.Sparse) {
@@ -69,10 +73,13 @@ func (c *concat) add(src *Descriptor) bool {
 	}
 	for i := range t.Blocks {
 		dt.Blocks = append(dt.Blocks, Blockdesc{
-			Offset: dt.Offset + t.Blocks[i].Offset,
-			Chunks: t.Blocks[i].Chunks,
+			Offset:   dt.Offset + t.Blocks[i].Offset,
+			Chunks:   t.Blocks[i].Chunks,
+			Checksum: t.Blocks[i].Checksum,
+			Rows:     t.Blocks[i].Rows,
 		})
 	}
+	dt.Version = max(dt.Version, t.Version)
 	c.inputs = append(c.inputs, *src)
 	// dt.Offset is always the position immediately
 	// following the final block of data
@@ -264,3 +271,110 @@ func (c *IndexConfig) Compact(fs UploadFS, lst []Descriptor) ([]Descriptor, []Qu
 	err := wait()
 	return result, todelete, err
 }
+
+// CompactionPolicy determines how Index.Compact
+// rewrites the packfiles referenced by an Index.
+type CompactionPolicy struct {
+	// TargetSize is the target size of rewritten
+	// packfiles. Packfiles at least this large are
+	// left alone, and smaller compatible packfiles
+	// are concatenated until they reach TargetSize.
+	TargetSize int64
+	// Expiry is the minimum time that replaced
+	// objects should be left around after they
+	// have been dereferenced.
+	Expiry time.Duration
+	// Algo is the compression algorithm used
+	// for rewritten indirect references.
+	// (See IndexConfig.Algo.)
+	Algo string
+	// Delete, if non-nil, is used to remove
+	// the rows matching the tombstones in the
+	// Index from the packfiles that may contain
+	// them. If Delete is nil, tombstones are
+	// left alone.
+	Delete DeleteFunc
+}
+
+// Compact concatenates the small packfiles referenced
+// by each IndirectRef in idx.Indirect into larger
+// packfiles according to policy and rewrites the
+// IndirectRefs that have changed. The replaced packfiles
+// and indirect references are added to idx.ToDelete
+// and also returned. Compact does not change the
+// IndirectRef.OrigObjects accounting.
+//
+// Packfiles are only concatenated with other packfiles
+// from the same IndirectRef. Descriptors in idx.Inline
+// are not concatenated; they are compacted when they are
+// moved into idx.Indirect by IndexConfig.SyncOutputs.
+//
+// If policy.Delete is set and idx has tombstones,
+// every packfile (including those in idx.Inline)
+// that may contain deleted rows is first rewritten
+// without them, and the tombstones are removed once
+// all of the packfiles have been rewritten.
+func (idx *Index) Compact(ifs UploadFS, policy CompactionPolicy) ([]Quarantined, error) {
+	if policy.TargetSize <= 0 {
+		return nil, fmt.Errorf("blockfmt.Index.Compact: invalid target size %d", policy.TargetSize)
+	}
+	c := IndexConfig{
+		TargetSize: policy.TargetSize,
+		Expiry:     policy.Expiry,
+		Algo:       policy.Algo,
+	}
+	var pred expr.Node
+	if policy.Delete != nil {
+		pred = idx.Deleted()
+	}
+	var quarantined []Quarantined
+	refs := idx.Indirect.Refs
+	for i := range refs {
+		r := &refs[i]
+		descs, err := idx.Indirect.decode(ifs, r, nil, nil)
+		if err != nil {
+			return quarantined, err
+		}
+		var old []Quarantined
+		if pred != nil {
+			descs, old, err = policy.purge(ifs, descs, pred)
+			if err != nil {
+				return quarantined, err
+			}
+		}
+		compacted, concatenated, err := c.Compact(ifs, descs)
+		if err != nil {
+			return quarantined, err
+		}
+		old = append(old, concatenated...)
+		if len(old) == 0 {
+			continue
+		}
+		prev := r.Path
+		nr := *r
+		err = c.writeRef(ifs, path.Dir(prev), &nr, compacted)
+		if err != nil {
+			return quarantined, err
+		}
+		*r = nr
+		old = append(old, Quarantined{
+			Path:   prev,
+			Expiry: date.Now().Add(policy.Expiry).Truncate(time.Microsecond),
+		})
+		idx.ToDelete = append(idx.ToDelete, old...)
+		quarantined = append(quarantined, old...)
+	}
+	if pred == nil {
+		return quarantined, nil
+	}
+	inline, old, err := policy.purge(ifs, idx.Inline, pred)
+	if err != nil {
+		return quarantined, err
+	}
+	idx.Inline = inline
+	idx.ToDelete = append(idx.ToDelete, old...)
+	quarantined = append(quarantined, old...)
+	// all of the deleted rows are gone now
+	idx.Tombstones = nil
+	return quarantined, nil
+}
@@ -107,3 +107,112 @@ func TestConcat(t *testing.T) {
 		t.Errorf("found %d items?", n)
 	}
 }
+
+func TestIndexCompact(t *testing.T) {
+	dfs := NewDirFS(t.TempDir())
+	dfs.MinPartSize = 1
+	align := 32 * 1024
+	var descs []Ingested
+	for i := 0; i < 6; i++ {
+		f, err := os.Open("../../testdata/cloudtrail.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := fmt.Sprintf("db/foo/bar/packed-%d", i)
+		up, err := dfs.Create(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := Converter{
+			Output:    up,
+			Comp:      "zion",
+			Inputs:    []Input{{R: f, F: MustSuffixToFormat(".json")}},
+			Align:     align,
+			FlushMeta: 2 * align,
+		}
+		err = c.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		etag, err := ETag(dfs, c.Output, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		descs = append(descs, Ingested{
+			Descriptor: Descriptor{
+				ObjectInfo: ObjectInfo{
+					Path: p,
+					ETag: etag,
+					Size: c.Output.Size(),
+				},
+				Trailer: *c.Trailer(),
+			},
+		})
+	}
+	// put 3 packfiles in each of 2 refs
+	c := IndexConfig{
+		TargetRefSize: 1,
+		AppendBatch:   3,
+	}
+	idx := &Index{Name: "bar", Algo: "zstd"}
+	err := c.Append(idx, dfs, "db/foo/bar", descs, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Indirect.Refs) != 2 {
+		t.Fatalf("got %d refs", len(idx.Indirect.Refs))
+	}
+	rows := func() (int, int) {
+		lst, err := idx.Indirect.Search(dfs, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for i := range lst {
+			f, err := dfs.Open(lst[i].Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var errlog bytes.Buffer
+			n += Validate(f, &lst[i].Trailer, &errlog)
+			f.Close()
+			if errlog.Len() > 0 {
+				t.Fatal(errlog.String())
+			}
+		}
+		return len(lst), n
+	}
+	packs, before := rows()
+	if packs != len(descs) {
+		t.Fatalf("got %d packfiles before compaction", packs)
+	}
+	q, err := idx.Compact(dfs, CompactionPolicy{TargetSize: 1 << 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every packfile and both refs are replaced
+	if len(q) != len(descs)+2 {
+		t.Errorf("got %d quarantined objects", len(q))
+	}
+	if len(idx.ToDelete) != len(q) {
+		t.Errorf("got %d objects in ToDelete", len(idx.ToDelete))
+	}
+	packs, after := rows()
+	if packs != 2 {
+		t.Errorf("got %d packfiles after compaction", packs)
+	}
+	if after != before {
+		t.Errorf("got %d rows after compaction; want %d", after, before)
+	}
+	if n := idx.Objects(); n != len(descs) {
+		t.Errorf("Objects() = %d after compaction", n)
+	}
+	// compacting again is a no-op
+	q, err = idx.Compact(dfs, CompactionPolicy{TargetSize: 1 << 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q) != 0 {
+		t.Errorf("second compaction quarantined %d objects", len(q))
+	}
+}
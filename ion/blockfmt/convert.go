@@ -27,11 +27,13 @@ import (
 	"runtime"
 	"slices"
 	"strings"
+	"sync"
 
 	"github.com/SnellerInc/sneller/aws/s3"
 	"github.com/SnellerInc/sneller/ion"
 	"github.com/SnellerInc/sneller/ion/zion"
 	"github.com/SnellerInc/sneller/jsonrl"
+	"github.com/SnellerInc/sneller/parquet"
 	"github.com/SnellerInc/sneller/xsv"
 
 	"github.com/klauspost/compress/zstd"
@@ -82,8 +84,8 @@ type Input struct {
 // canPrefetch returns true of i.R is worth prefetching
 //
 // (there is no point in prefetching parquet contents
-// because we ask S3 to do the conversion for us;
-// the actual data bits are not of interest to us)
+// because they are read at random offsets
+// rather than sequentially)
 func (i *Input) canPrefetch() bool {
 	return i.F.Name() != "parquet"
 }
@@ -100,18 +102,24 @@ func (j *jsonConverter) Name() string {
 }
 
 func (j *jsonConverter) Convert(r io.Reader, dst *ion.Chunker, cons []ion.Field) error {
+	_, err := j.convertWithReject(r, dst, cons, nil)
+	return err
+}
+
+func (j *jsonConverter) convertWithReject(r io.Reader, dst *ion.Chunker, cons []ion.Field, reject func(int, error) error) (int, error) {
 	rc := r
 	var err, err2 error
 	if j.decomp != nil {
 		rc, err = j.decomp(r)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
+	n := 0
 	if j.isCloudtrail {
 		err = jsonrl.ConvertCloudtrail(rc, dst, cons)
 	} else {
-		err = jsonrl.Convert(rc, dst, j.hints, cons)
+		n, err = jsonrl.ConvertWithReject(rc, dst, j.hints, cons, reject)
 	}
 	if j.decomp != nil {
 		// if the decompressor (i.e. gzip.Reader)
@@ -125,7 +133,7 @@ func (j *jsonConverter) Convert(r io.Reader, dst *ion.Chunker, cons []ion.Field)
 	if err == nil {
 		err = err2
 	}
-	return err
+	return n, err
 }
 
 func parquetReader(r io.Reader) (io.Reader, error) {
@@ -161,18 +169,55 @@ func parquetReader(r io.Reader) (io.Reader, error) {
 }
 
 type parquetConverter struct {
+	// hints, if present, are applied
+	// to the JSON representation of
+	// the file produced by parquetReader
 	hints *jsonrl.Hint
 }
 
 func (p *parquetConverter) Name() string { return "parquet" }
 
+// sizedReaderAt is implemented by inputs
+// (i.e. *s3.File) that support random access
+type sizedReaderAt interface {
+	io.ReaderAt
+	Size() int64
+}
+
 func (p *parquetConverter) Convert(r io.Reader, dst *ion.Chunker, cons []ion.Field) error {
-	pr, err := parquetReader(r)
+	if p.hints != nil {
+		pr, err := parquetReader(r)
+		if err != nil {
+			return fmt.Errorf("cannot read parquet: %s", err)
+		}
+		jc := jsonConverter{hints: p.hints}
+		return jc.Convert(pr, dst, cons)
+	}
+	switch f := r.(type) {
+	case sizedReaderAt:
+		return parquet.Convert(f, f.Size(), dst, cons)
+	case *os.File:
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return parquet.Convert(f, info.Size(), dst, cons)
+	}
+	// the footer lives at the end of the file,
+	// so we need random access to the contents
+	f, err := os.CreateTemp("", "tmp.*.parquet")
 	if err != nil {
-		return fmt.Errorf("cannot read parquet: %s", err)
+		return err
 	}
-	jc := jsonConverter{hints: p.hints}
-	return jc.Convert(pr, dst, cons)
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+	size, err := io.Copy(f, r)
+	if err != nil {
+		return err
+	}
+	return parquet.Convert(f, size, dst, cons)
 }
 
 type xsvConverter struct {
@@ -183,16 +228,21 @@ type xsvConverter struct {
 }
 
 func (t *xsvConverter) Convert(r io.Reader, dst *ion.Chunker, cons []ion.Field) error {
+	_, err := t.convertWithReject(r, dst, cons, nil)
+	return err
+}
+
+func (t *xsvConverter) convertWithReject(r io.Reader, dst *ion.Chunker, cons []ion.Field, reject func(int, error) error) (int, error) {
 	rc := r
 	var err, err2 error
 	if t.decomp != nil {
 		rc, err = t.decomp(r)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
 
-	err = xsv.Convert(rc, dst, t.ch, t.hints, cons)
+	n, err := xsv.ConvertWithReject(rc, dst, t.ch, t.hints, cons, reject)
 	if t.decomp != nil {
 		// if the decompressor (i.e. gzip.Reader)
 		// has a Close() method, then use that;
@@ -205,7 +255,7 @@ func (t *xsvConverter) Convert(r io.Reader, dst *ion.Chunker, cons []ion.Field)
 	if err == nil {
 		err = err2
 	}
-	return err
+	return n, err
 }
 
 func (t *xsvConverter) Name() string {
@@ -252,10 +302,67 @@ func isCompressed(r RowFormat) bool {
 // filename suffixes that correspond
 // to known constructors for RowFormat
 // objects.
+//
+// SuffixToFormat should not be modified
+// directly after package initialization;
+// use RegisterFormat and UnregisterFormat instead.
 var SuffixToFormat = make(map[string]func(hints []byte) (RowFormat, error))
 
+// formatLock guards SuffixToFormat
+var formatLock sync.RWMutex
+
+// RegisterFormat registers a constructor for
+// a RowFormat that is used for objects with
+// the given filename suffix (e.g. ".json.gz").
+// RegisterFormat replaces any constructor that
+// was previously registered for suffix.
+// It is safe to call RegisterFormat concurrently
+// with CollectGlob and the other functions that
+// infer formats from filenames.
+func RegisterFormat(suffix string, cons func(hints []byte) (RowFormat, error)) {
+	if suffix == "" || cons == nil {
+		panic("blockfmt.RegisterFormat: empty suffix or nil constructor")
+	}
+	formatLock.Lock()
+	defer formatLock.Unlock()
+	SuffixToFormat[suffix] = cons
+}
+
+// UnregisterFormat removes the constructor
+// registered for suffix, if there is one.
+func UnregisterFormat(suffix string) {
+	formatLock.Lock()
+	defer formatLock.Unlock()
+	delete(SuffixToFormat, suffix)
+}
+
+// LookupFormat returns the constructor
+// registered for exactly the given suffix,
+// or nil if there is no such constructor.
+func LookupFormat(suffix string) func(hints []byte) (RowFormat, error) {
+	formatLock.RLock()
+	defer formatLock.RUnlock()
+	return SuffixToFormat[suffix]
+}
+
+// FormatForName returns the constructor
+// registered for the longest suffix of name,
+// or nil if no registered suffix matches name.
+func FormatForName(name string) func(hints []byte) (RowFormat, error) {
+	formatLock.RLock()
+	defer formatLock.RUnlock()
+	var best string
+	var cons func(hints []byte) (RowFormat, error)
+	for suff, f := range SuffixToFormat {
+		if len(suff) > len(best) && strings.HasSuffix(name, suff) {
+			best, cons = suff, f
+		}
+	}
+	return cons
+}
+
 func MustSuffixToFormat(suffix string) RowFormat {
-	f := SuffixToFormat[suffix]
+	f := LookupFormat(suffix)
 	if f == nil {
 		panic(fmt.Sprintf("cannot find suffix %q", suffix))
 	}
@@ -322,12 +429,15 @@ func init() {
 		decName := dn
 		decomp := dc
 		SuffixToFormat[".csv"+decName] = func(h []byte) (RowFormat, error) {
-			if h == nil {
-				return nil, errors.New("CSV requires hints")
-			}
-			hints, err := xsv.ParseHint(h)
-			if err != nil {
-				return nil, err
+			// without hints, the first record is
+			// the header and the types are inferred
+			hints := &xsv.Hint{Header: true}
+			if h != nil {
+				var err error
+				hints, err = xsv.ParseHint(h)
+				if err != nil {
+					return nil, err
+				}
 			}
 			return &xsvConverter{
 				name:   "csv" + decName,
@@ -346,12 +456,15 @@ func init() {
 		decName := dn
 		decomp := dc
 		SuffixToFormat[".tsv"+decName] = func(h []byte) (RowFormat, error) {
-			if h == nil {
-				return nil, errors.New("TSV requires hints")
-			}
-			hints, err := xsv.ParseHint(h)
-			if err != nil {
-				return nil, err
+			// without hints, the first record is
+			// the header and the types are inferred
+			hints := &xsv.Hint{Header: true}
+			if h != nil {
+				var err error
+				hints, err = xsv.ParseHint(h)
+				if err != nil {
+					return nil, err
+				}
 			}
 			if hints.Separator != 0 && hints.Separator != '\t' {
 				return nil, errors.New("TSV doesn't support a custom separator")
@@ -456,6 +569,23 @@ type Converter struct {
 	// prefetching of inputs.
 	DisablePrefetch bool
 
+	// ErrorPolicy, if non-nil, determines
+	// how many malformed input records are
+	// skipped before Run fails. (Only the JSON,
+	// CSV, and TSV formats can skip records.)
+	ErrorPolicy *ErrorPolicy
+
+	// Sample, if non-nil, is populated with
+	// a sample of the records from Inputs.
+	// (Records from Prepend are not sampled.)
+	Sample *Reservoir
+
+	// Progress, if non-nil, is called periodically
+	// during Run with the number of Inputs and
+	// the total size of the Inputs converted so far.
+	// Progress is never called concurrently.
+	Progress func(files int, bytes int64)
+
 	// trailer built by the writer. This is only
 	// set if the object was written successfully.
 	trailer *Trailer
@@ -500,6 +630,10 @@ func IsFatal(err error) bool {
 			return true
 		}
 	}
+	var re *RejectError
+	if errors.As(err, &re) {
+		return true
+	}
 	var cie flate.CorruptInputError
 	return errors.As(err, &cie)
 }
@@ -565,11 +699,13 @@ func (c *Converter) Run() error {
 	if len(c.Inputs) == 0 && c.Prepend.R == nil {
 		return errors.New("no inputs or merge sources")
 	}
+	prog := newProgress(c.Progress)
+	defer prog.done()
 	p := c.parallel()
 	if p > 1 {
-		return c.runMulti(p)
+		return c.runMulti(p, prog)
 	}
-	return c.runSingle()
+	return c.runSingle(prog)
 }
 
 func (c *Converter) prefetch() int {
@@ -579,7 +715,7 @@ func (c *Converter) prefetch() int {
 	return DefaultMaxReadsInFlight
 }
 
-func (c *Converter) runSingle() error {
+func (c *Converter) runSingle(prog *progress) error {
 	cname := c.Comp
 	if cname == "zstd" {
 		cname = "zstd-better"
@@ -613,6 +749,8 @@ func (c *Converter) runSingle() error {
 	if err != nil {
 		return err
 	}
+	cn.OnCommit = c.Sample.hook()
+	rt := c.ErrorPolicy.tracker()
 	ready := make([]chan struct{}, len(c.Inputs))
 	next := 1
 	inflight := int64(0) // # bytes being prefetched
@@ -648,7 +786,7 @@ func (c *Converter) runSingle() error {
 			next++
 		}
 
-		err := c.Inputs[i].F.Convert(c.Inputs[i].R, &cn, c.Constants)
+		err := rt.convert(&c.Inputs[i], &cn, c.Constants)
 		err2 := c.Inputs[i].R.Close()
 		if err == nil {
 			err = err2
@@ -668,6 +806,10 @@ func (c *Converter) runSingle() error {
 			c.Inputs[i].Err = err
 			return err
 		}
+		prog.add(c.Inputs[i].Size)
+	}
+	if err := rt.check(); err != nil {
+		return err
 	}
 	err = cn.Flush()
 	if err != nil {
@@ -806,7 +948,7 @@ func (c *Converter) runPrepend(cn *ion.Chunker) error {
 	return err
 }
 
-func (c *Converter) runMulti(p int) error {
+func (c *Converter) runMulti(p int, prog *progress) error {
 	cname := c.Comp
 	if cname == "zstd" {
 		cname = "zstd-better"
@@ -842,6 +984,13 @@ func (c *Converter) runMulti(p int) error {
 		}
 		readyc = doPrefetch(startc, max, DefaultMaxBytesInFlight)
 	}
+	rt := c.ErrorPolicy.tracker()
+	// each worker samples its records on its own;
+	// the samples are merged when all of them are done
+	samples := make([]*Reservoir, p)
+	for i := range samples {
+		samples[i] = c.Sample.fork()
+	}
 	errs := make(chan error, p)
 	// NOTE: consume must be called
 	// before the send on errs so that
@@ -873,8 +1022,9 @@ func (c *Converter) runMulti(p int) error {
 					return
 				}
 			}
+			cn.OnCommit = samples[i].hook()
 			for in := range startc {
-				err := in.F.Convert(in.R, &cn, slices.Clone(c.Constants))
+				err := rt.convert(in, &cn, slices.Clone(c.Constants))
 				err2 := in.R.Close()
 				if err == nil {
 					err = err2
@@ -885,6 +1035,7 @@ func (c *Converter) runMulti(p int) error {
 					errs <- fmt.Errorf("%s: %w", in.Path, err)
 					return
 				}
+				prog.add(in.Size)
 			}
 			err := cn.Flush()
 			if err != nil {
@@ -911,12 +1062,18 @@ func (c *Converter) runMulti(p int) error {
 			extra++
 		}
 	}
+	for i := range samples {
+		c.Sample.merge(samples[i])
+	}
 	if outerr != nil {
 		if extra > 0 {
 			return fmt.Errorf("%w (and %d other errors)", outerr, extra)
 		}
 		return outerr
 	}
+	if err := rt.check(); err != nil {
+		return err
+	}
 	// don't finalize unless everything
 	// up to this point succeeded
 	if err := w.Close(); err != nil {
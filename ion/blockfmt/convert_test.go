@@ -20,16 +20,12 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
-func haveParquet2JSON() bool {
-	_, err := exec.LookPath("parquet2json")
-	return err == nil
-}
-
 func testConvertMulti(t *testing.T, algo string, meta int) {
 	var inputs []Input
 	f, err := os.Open("../../testdata/cloudtrail.json")
@@ -56,16 +52,14 @@ func testConvertMulti(t *testing.T, algo string, meta int) {
 		R: f,
 		F: MustSuffixToFormat(".json"),
 	})
-	if haveParquet2JSON() {
-		f, err = os.Open("../../testdata/userdata1.parquet")
-		if err != nil {
-			t.Fatal(err)
-		}
-		inputs = append(inputs, Input{
-			R: f,
-			F: MustSuffixToFormat(".parquet"),
-		})
+	f, err = os.Open("../../parquet/testdata/dict-snappy.parquet")
+	if err != nil {
+		t.Fatal(err)
 	}
+	inputs = append(inputs, Input{
+		R: f,
+		F: MustSuffixToFormat(".parquet"),
+	})
 
 	var out BufferUploader
 	align := 4096
@@ -76,7 +70,7 @@ func testConvertMulti(t *testing.T, algo string, meta int) {
 		Inputs:    inputs,
 		Align:     align,
 		FlushMeta: align * meta,
-		Parallel:  2, // 3 inputs + 2 parellelism enables prefetching
+		Parallel:  2, // 4 inputs + 2 parellelism enables prefetching
 	}
 	if !c.MultiStream() {
 		t.Fatal("expected MultiStream to be true with 2 inputs")
@@ -239,6 +233,97 @@ func TestConvertEmptyGZ(t *testing.T) {
 	}
 }
 
+func TestConvertErrorPolicy(t *testing.T) {
+	const input = `{"x": 0}
+{"x": 1}
+{"x": oops}
+{"x": 3}
+{"x": 4}
+{"x": 5}
+{"x" 6}
+{"x": 7}
+{"x": 8}
+{"x": 9}
+`
+	// maxErrors is per input
+	tcs := []struct {
+		reject    bool
+		maxErrors int
+		maxRate   float64
+		ok        bool
+	}{
+		{reject: false, ok: false},
+		{reject: true, ok: false},
+		{reject: true, maxErrors: 2, ok: true},
+		{reject: true, maxErrors: 1, ok: false},
+		{reject: true, maxRate: 0.2, ok: true},
+		{reject: true, maxRate: 0.1, ok: false},
+		{reject: true, maxErrors: 5, maxRate: 0.1, ok: false},
+	}
+	for i := range tcs {
+		for _, inputs := range []int{1, 2} {
+			var rejected []RejectedRecord
+			var policy *ErrorPolicy
+			if tcs[i].reject {
+				policy = &ErrorPolicy{
+					MaxErrors:    tcs[i].maxErrors * inputs,
+					MaxErrorRate: tcs[i].maxRate,
+					Reject: func(r *RejectedRecord) {
+						rejected = append(rejected, *r)
+					},
+				}
+			}
+			var in []Input
+			for j := 0; j < inputs; j++ {
+				in = append(in, Input{
+					Path: fmt.Sprintf("input%d.json", j),
+					R:    io.NopCloser(strings.NewReader(input)),
+					F:    MustSuffixToFormat(".json"),
+				})
+			}
+			var out BufferUploader
+			out.PartSize = 4096
+			c := Converter{
+				Output:      &out,
+				Comp:        "zstd",
+				Inputs:      in,
+				Align:       4096,
+				Parallel:    1,
+				ErrorPolicy: policy,
+			}
+			err := c.Run()
+			if !tcs[i].ok {
+				if err == nil {
+					t.Fatalf("case %d: expected an error", i)
+				}
+				if !IsFatal(err) {
+					t.Fatalf("case %d: error %v is not fatal", i, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("case %d: %v", i, err)
+			}
+			check(t, &out)
+			if len(rejected) != 2*inputs {
+				t.Fatalf("case %d: got %d rejected records", i, len(rejected))
+			}
+			for j := range rejected {
+				want := 2
+				if j%2 == 1 {
+					want = 6
+				}
+				if rejected[j].Index != want {
+					t.Errorf("case %d: rejected record %d has index %d", i, j, rejected[j].Index)
+				}
+				if rejected[j].Path != fmt.Sprintf("input%d.json", j/2) {
+					t.Errorf("case %d: rejected record %d has path %q", i, j, rejected[j].Path)
+				}
+			}
+		}
+	}
+}
+
 func gzipped(r io.ReadCloser) io.Reader {
 	rp, wp := io.Pipe()
 	go func() {
@@ -268,8 +353,81 @@ func check(t *testing.T, buf *BufferUploader) int {
 	n := Validate(r, trailer, &errlog)
 	if errlog.Len() > 0 {
 		t.Helper()
-		errlog.Truncate(4096) // don't fill the screen
+		if errlog.Len() > 4096 {
+			errlog.Truncate(4096) // don't fill the screen
+		}
 		t.Fatal(errlog.String())
 	}
 	return n
 }
+
+type progressCall struct {
+	n    int
+	size int64
+}
+
+// checkProgress checks that calls are strictly
+// increasing and end with (n, size)
+func checkProgress(t *testing.T, calls []progressCall, n int, size int64) {
+	t.Helper()
+	if len(calls) == 0 {
+		t.Fatal("progress never reported")
+	}
+	for i := 1; i < len(calls); i++ {
+		if calls[i].n <= calls[i-1].n || calls[i].size < calls[i-1].size {
+			t.Fatalf("progress went from %v to %v", calls[i-1], calls[i])
+		}
+	}
+	if last := calls[len(calls)-1]; last.n != n || last.size != size {
+		t.Fatalf("final progress %v; want {%d %d}", last, n, size)
+	}
+}
+
+func TestConvertProgress(t *testing.T) {
+	defer func(d time.Duration) {
+		progressInterval = d
+	}(progressInterval)
+	progressInterval = 0
+
+	for _, parallel := range []int{1, 2} {
+		t.Run(fmt.Sprintf("parallel=%d", parallel), func(t *testing.T) {
+			var inputs []Input
+			total := int64(0)
+			for i := 0; i < 10; i++ {
+				text := fmt.Sprintf(`{"x": %d, "y": %q}`, i, strings.Repeat("y", i))
+				inputs = append(inputs, Input{
+					Path: fmt.Sprintf("input-%d.json", i),
+					R:    io.NopCloser(strings.NewReader(text)),
+					Size: int64(len(text)),
+					F:    MustSuffixToFormat(".json"),
+				})
+				total += int64(len(text))
+			}
+			var lock sync.Mutex
+			var calls []progressCall
+			var out BufferUploader
+			out.PartSize = 4096
+			c := Converter{
+				Output:    &out,
+				Comp:      "zstd",
+				Inputs:    inputs,
+				Align:     4096,
+				FlushMeta: 4096,
+				Parallel:  parallel,
+				Progress: func(n int, size int64) {
+					lock.Lock()
+					defer lock.Unlock()
+					calls = append(calls, progressCall{n, size})
+				},
+			}
+			err := c.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkProgress(t, calls, len(inputs), total)
+			if len(calls) != len(inputs) {
+				t.Errorf("%d progress calls for %d inputs", len(calls), len(inputs))
+			}
+		})
+	}
+}
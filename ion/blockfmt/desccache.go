@@ -0,0 +1,107 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package blockfmt
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DescCache is an LRU cache of the descriptor
+// lists decoded from the objects referenced by
+// an IndirectTree, keyed by object path and ETag.
+// See IndirectTree.Cache.
+//
+// Descriptors returned through the cache share
+// their Trailer contents with the cached copies,
+// so callers must not modify them in place.
+//
+// A DescCache is safe to use from multiple goroutines.
+type DescCache struct {
+	budget int64
+
+	lock   sync.Mutex
+	size   int64
+	lru    list.List // most-recently-used first
+	byPath map[string]*list.Element
+}
+
+type descEntry struct {
+	path, etag string
+	descs      []Descriptor
+	size       int64
+}
+
+// NewDescCache creates a DescCache that holds
+// descriptor lists with a total decompressed
+// size of at most budget bytes.
+func NewDescCache(budget int64) *DescCache {
+	return &DescCache{
+		budget: budget,
+		byPath: make(map[string]*list.Element),
+	}
+}
+
+// Size returns the decompressed size
+// of the lists currently held in c.
+func (c *DescCache) Size() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.size
+}
+
+func (c *DescCache) get(path, etag string) ([]Descriptor, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.byPath[path]
+	if !ok {
+		return nil, false
+	}
+	ent := e.Value.(*descEntry)
+	if ent.etag != etag {
+		// the object has been replaced
+		c.remove(e)
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return ent.descs, true
+}
+
+func (c *DescCache) put(path, etag string, descs []Descriptor, size int64) {
+	if size > c.budget {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.byPath[path]; ok {
+		c.remove(e)
+	}
+	for c.size+size > c.budget {
+		c.remove(c.lru.Back())
+	}
+	c.byPath[path] = c.lru.PushFront(&descEntry{
+		path:  path,
+		etag:  etag,
+		descs: descs,
+		size:  size,
+	})
+	c.size += size
+}
+
+func (c *DescCache) remove(e *list.Element) {
+	ent := c.lru.Remove(e).(*descEntry)
+	delete(c.byPath, ent.path)
+	c.size -= ent.size
+}
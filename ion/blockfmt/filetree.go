@@ -502,7 +502,7 @@ func (f *level) decode(in []byte) error {
 	if err != nil {
 		return err
 	}
-	d, _, err := ion.ReadDatum(&st, ret)
+	d, _, err := ion.ReadDatumLimited(&st, ret, maxIndexDepth)
 	if err != nil {
 		return err
 	}
@@ -514,7 +514,9 @@ func (f *level) decode(in []byte) error {
 
 // ErrETagChanged is returned by FileTree.Append
 // when attempting to perform an insert with
-// a file that has had its ETag change.
+// a file that has had its ETag change, and by
+// IndirectTree operations when an object that
+// the tree references has been overwritten.
 var ErrETagChanged = errors.New("FileTree: ETag changed")
 
 // Prefetch takes a list of inputs and prefetches
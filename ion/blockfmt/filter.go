@@ -43,6 +43,15 @@ func (f *Filter) Compile(e expr.Node) {
 	f.eval = filtcompile(e)
 }
 
+// Clone returns a copy of f that can be
+// used concurrently with f.
+func (f *Filter) Clone() *Filter {
+	if f == nil {
+		return nil
+	}
+	return &Filter{eval: f.eval}
+}
+
 // Trivial returns true if the compiled filter
 // condition will never select non-trivial
 // subranges of the input slice in Visit.
@@ -218,16 +227,64 @@ func filtnegate(e expr.Node) evalfn {
 	if inner == nil {
 		return nil
 	}
+	paths := filtpaths(e)
 	return func(f *Filter, si *SparseIndex, rest cont) {
+		// without an index for every path,
+		// inner matches everything because it
+		// doesn't know anything, so the negation
+		// has to match everything as well
+		if !indexed(si, paths) {
+			rest(f, 0, si.Blocks())
+			return
+		}
+		// the result is the complement of
+		// the union of the ranges that match e
+		var match ints.Intervals
 		inner(f, si, func(f *Filter, x, y int) {
-			if x > 0 {
-				rest(f, 0, x)
+			match = append(match, ints.Interval{Start: x, End: y})
+		})
+		match.Compress()
+		start := 0
+		for _, in := range match {
+			if in.Start > start {
+				rest(f, start, in.Start)
 			}
-			if y < si.Blocks() {
-				rest(f, y, si.Blocks())
+			start = max(start, in.End)
+		}
+		if start < si.Blocks() {
+			rest(f, start, si.Blocks())
+		}
+	}
+}
+
+// filtpaths returns the paths referenced in e
+func filtpaths(e expr.Node) [][]string {
+	var out [][]string
+	expr.Walk(expr.WalkFunc(func(e expr.Node) bool {
+		if p, ok := expr.FlatPath(e); ok {
+			out = append(out, p)
+			return false
+		}
+		return true
+	}), e)
+	return out
+}
+
+// indexed returns true if every path has
+// either a time index or a constant value in si
+func indexed(si *SparseIndex, paths [][]string) bool {
+	for _, p := range paths {
+		if si.Get(p) != nil {
+			continue
+		}
+		if len(p) == 1 {
+			if _, ok := si.Const(p[0]); ok {
+				continue
 			}
-		})
+		}
+		return false
 	}
+	return true
 }
 
 func toUnixEpoch(e expr.Node) *expr.Timestamp {
@@ -210,4 +210,11 @@ func testFilter(t testing.TB, f *Filter, si *SparseIndex, run func(filt string,
 	run(sprintf("foo = 'foo' and timestamp < %s", minute(10)), [][2]int{{0, 10}})
 	run(sprintf("foo = 'bar' and timestamp < %s", minute(10)), [][2]int{{0, 0}})
 	run(sprintf("timestamp < %s and (foo = 'foo' or foo = 'bar')", minute(10)), [][2]int{{0, 10}})
+	// negations of conditions on fields without
+	// an index can't exclude any blocks
+	run(sprintf("!(x = 'foo' or x = 'bar')"), [][2]int{{0, 60}})
+	run(sprintf("!(x = 3 or x = 5)"), [][2]int{{0, 60}})
+	run(sprintf("!(other < %s or other > %s)", minute(1), minute(2)), [][2]int{{0, 60}})
+	run(sprintf("!(foo = 'foo' or foo = 'bar')"), [][2]int{{0, 0}})
+	run(sprintf("!(foo = 'bar' or foo = 'baz')"), [][2]int{{0, 60}})
 }
@@ -24,7 +24,6 @@ import (
 	"path"
 	"path/filepath"
 	"slices"
-	"strings"
 	"sync"
 
 	"github.com/SnellerInc/sneller/aws/s3"
@@ -71,6 +70,49 @@ type UploadFS interface {
 	Create(path string) (Uploader, error)
 }
 
+// Renamer is an optional interface that can be
+// implemented by an UploadFS that can move
+// a file from one path to another, for example
+// to promote a staged file into its final location.
+//
+// Implementations should document whether or not
+// Rename is atomic.
+type Renamer interface {
+	// Rename moves the file at oldpath to newpath,
+	// replacing any file already present at newpath.
+	Rename(oldpath, newpath string) error
+}
+
+// BatchRemover is an optional interface that can be
+// implemented by an UploadFS that can remove many
+// files more efficiently than one at a time.
+type BatchRemover interface {
+	// RemoveAll removes each of the files in paths
+	// and returns the paths that could not be removed
+	// along with the first error encountered.
+	// Removing a file that does not exist
+	// should not be considered a failure.
+	RemoveAll(paths []string) (failed []string, err error)
+}
+
+// ErrETagMismatch is returned by
+// ConditionalWriter.WriteFileIfMatch when
+// the ETag of the file being overwritten does
+// not match the expected ETag.
+var ErrETagMismatch = errors.New("ETag mismatch")
+
+// ConditionalWriter is an optional interface that
+// can be implemented by an UploadFS that supports
+// optimistic concurrency control for writes.
+type ConditionalWriter interface {
+	// WriteFileIfMatch is like UploadFS.WriteFile,
+	// but it only writes the file if its current ETag
+	// is expectedETag, or, if expectedETag is empty,
+	// if the file does not exist yet. Otherwise it
+	// returns an error wrapping ErrETagMismatch.
+	WriteFileIfMatch(path string, buf []byte, expectedETag string) (etag string, err error)
+}
+
 // S3FS implements UploadFS and InputFS.
 type S3FS struct {
 	s3.BucketFS
@@ -108,6 +150,27 @@ func (s *S3FS) WriteFile(path string, contents []byte) (string, error) {
 	return s.Put(path, contents)
 }
 
+// WriteFileIfMatch implements
+// ConditionalWriter.WriteFileIfMatch
+// using a conditional PutObject request.
+func (s *S3FS) WriteFileIfMatch(path string, contents []byte, expectedETag string) (string, error) {
+	etag, err := s.PutIfMatch(path, contents, expectedETag)
+	if errors.Is(err, s3.ErrPreconditionFailed) {
+		return "", fmt.Errorf("%s: %w", path, ErrETagMismatch)
+	}
+	return etag, err
+}
+
+// Rename implements Renamer.Rename
+// using a server-side copy followed by
+// a deletion of oldpath. The destination
+// is replaced atomically, but the rename
+// as a whole is not atomic: oldpath may
+// remain visible if the deletion fails.
+func (s *S3FS) Rename(oldpath, newpath string) error {
+	return s.BucketFS.Rename(oldpath, newpath)
+}
+
 // NewDirFS creates a new DirFS in dir.
 func NewDirFS(dir string) *DirFS {
 	return &DirFS{
@@ -123,6 +186,52 @@ type DirFS struct {
 	Root        string
 	Log         func(f string, args ...interface{})
 	MinPartSize int
+
+	// SequentialMmap, if set, advises the
+	// kernel that files mapped with Mmap will be
+	// read sequentially, and makes WillNeed
+	// request read-ahead of the given range.
+	// It has no effect on platforms that do
+	// not support madvise(2).
+	SequentialMmap bool
+
+	// condLock protects condPaths, which holds
+	// the lock of each path with a WriteFileIfMatch
+	// call in progress
+	condLock  sync.Mutex
+	condPaths map[string]*pathLock
+}
+
+// pathLock is a reference-counted lock on one path
+type pathLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lockPath locks fullpath for WriteFileIfMatch
+// and returns the function that unlocks it
+func (d *DirFS) lockPath(fullpath string) func() {
+	d.condLock.Lock()
+	l := d.condPaths[fullpath]
+	if l == nil {
+		if d.condPaths == nil {
+			d.condPaths = make(map[string]*pathLock)
+		}
+		l = new(pathLock)
+		d.condPaths[fullpath] = l
+	}
+	l.refs++
+	d.condLock.Unlock()
+	l.Lock()
+	return func() {
+		l.Unlock()
+		d.condLock.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(d.condPaths, fullpath)
+		}
+		d.condLock.Unlock()
+	}
 }
 
 func hashFile(r io.Reader) (string, error) {
@@ -144,7 +253,16 @@ func hashFile(r io.Reader) (string, error) {
 // The caller should be prepared to handle the error and
 // fall back to ordinary [Open] and [Read] calls.
 func (d *DirFS) Mmap(fullpath string) ([]byte, error) {
-	return mmap(filepath.Join(d.Root, filepath.FromSlash(fullpath)))
+	return mmap(filepath.Join(d.Root, filepath.FromSlash(fullpath)), d.SequentialMmap)
+}
+
+// WillNeed advises the kernel that buf[start:end]
+// will be read soon, where buf was returned by [Mmap].
+// WillNeed does nothing unless d.SequentialMmap is set.
+func (d *DirFS) WillNeed(buf []byte, start, end int64) {
+	if d.SequentialMmap {
+		willneed(buf, int(start), int(end))
+	}
 }
 
 // Unmap unmaps a buffer returned by [Mmap].
@@ -180,6 +298,83 @@ func (d *DirFS) Remove(fullpath string) error {
 	return os.Remove(filepath.Join(d.Root, fullpath))
 }
 
+// RemoveAll implements BatchRemover.RemoveAll
+// by calling d.Remove for each path.
+func (d *DirFS) RemoveAll(paths []string) ([]string, error) {
+	var failed []string
+	var first error
+	for _, p := range paths {
+		err := d.Remove(p)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			failed = append(failed, p)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return failed, first
+}
+
+// Rename implements Renamer.Rename using os.Rename.
+// The rename is atomic as long as oldpath and newpath
+// reside on the same filesystem; see rename(2).
+func (d *DirFS) Rename(oldpath, newpath string) error {
+	if d.Log != nil {
+		d.Log("Rename %s %s", oldpath, newpath)
+	}
+	oldpath = path.Clean(oldpath)
+	if !fs.ValidPath(oldpath) || oldpath == "." {
+		return &fs.PathError{Op: "rename", Path: oldpath, Err: fs.ErrInvalid}
+	}
+	newpath = path.Clean(newpath)
+	if !fs.ValidPath(newpath) || newpath == "." {
+		return &fs.PathError{Op: "rename", Path: newpath, Err: fs.ErrInvalid}
+	}
+	dst := filepath.Join(d.Root, filepath.FromSlash(newpath))
+	err := os.MkdirAll(filepath.Dir(dst), 0750)
+	if err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(d.Root, filepath.FromSlash(oldpath)), dst)
+}
+
+// Stat implements fs.StatFS.Stat
+func (d *DirFS) Stat(fullpath string) (fs.FileInfo, error) {
+	return fs.Stat(d.FS, fullpath)
+}
+
+// WriteFileIfMatch implements ConditionalWriter.WriteFileIfMatch
+// by comparing the ETag of the current file against
+// expectedETag and then performing WriteFile.
+// The comparison and write are atomic only with
+// respect to other calls to WriteFileIfMatch
+// for the same path on the same DirFS.
+func (d *DirFS) WriteFileIfMatch(fullpath string, buf []byte, expectedETag string) (string, error) {
+	fullpath = path.Clean(fullpath)
+	if !fs.ValidPath(fullpath) || fullpath == "." {
+		return "", fs.ErrInvalid
+	}
+	defer d.lockPath(fullpath)()
+	f, err := d.Open(fullpath)
+	if errors.Is(err, fs.ErrNotExist) {
+		if expectedETag != "" {
+			return "", fmt.Errorf("%s: %w", fullpath, ErrETagMismatch)
+		}
+	} else if err != nil {
+		return "", err
+	} else {
+		etag, err := hashFile(f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		if etag != expectedETag {
+			return "", fmt.Errorf("%s: %w", fullpath, ErrETagMismatch)
+		}
+	}
+	return d.WriteFile(fullpath, buf)
+}
+
 // WriteFile implements UploadFS.WriteFile
 func (d *DirFS) WriteFile(fullpath string, buf []byte) (string, error) {
 	if d.Log != nil {
@@ -376,11 +571,9 @@ var (
 )
 
 func inferFormat(name string, fallback func(name string) RowFormat) RowFormat {
-	for suff, cons := range SuffixToFormat {
-		if strings.HasSuffix(name, suff) {
-			f, _ := cons(nil)
-			return f
-		}
+	if cons := FormatForName(name); cons != nil {
+		f, _ := cons(nil)
+		return f
 	}
 	if fallback == nil {
 		return nil
@@ -410,6 +603,35 @@ type Collector struct {
 	// Fallback is the function used to
 	// determine the format of an input file.
 	Fallback func(string) RowFormat
+	// Exclude, if non-nil, maps the paths of
+	// inputs that have already been collected
+	// (as in Input.Path) to their ETags.
+	// Inputs whose path and ETag both match
+	// an entry in Exclude are skipped.
+	// Inputs whose path matches but whose ETag
+	// has changed are skipped unless Reingest is set.
+	Exclude map[string]string
+	// Reingest causes inputs in Exclude that
+	// have changed since they were collected
+	// to be collected again.
+	Reingest bool
+	// Progress, if non-nil, is called periodically
+	// during Collect with the number of items
+	// and the total size of the items collected so far.
+	// Progress is always called once more when
+	// Collect finishes if there are new items to report.
+	Progress func(collected int, bytes int64)
+}
+
+// excluded returns whether an input
+// with the given path and etag should
+// be skipped due to c.Exclude
+func (c *Collector) excluded(path, etag string) bool {
+	prev, ok := c.Exclude[path]
+	if !ok {
+		return false
+	}
+	return prev == etag || !c.Reingest
 }
 
 var errStop = errors.New("stop listing")
@@ -421,6 +643,8 @@ var errStop = errors.New("stop listing")
 func (c *Collector) Collect(from InputFS) ([]Input, bool, error) {
 	size := int64(0)
 	prefix := from.Prefix()
+	prog := newProgress(c.Progress)
+	defer prog.done()
 	var have []Input
 	walk := func(p string, f fs.File, err error) error {
 		if err != nil {
@@ -438,6 +662,9 @@ func (c *Collector) Collect(from InputFS) ([]Input, bool, error) {
 		if err != nil {
 			return err
 		}
+		if c.excluded(prefix+p, etag) {
+			return f.Close()
+		}
 		format := inferFormat(p, c.Fallback)
 		have = append(have, Input{
 			Path: prefix + p,
@@ -446,6 +673,8 @@ func (c *Collector) Collect(from InputFS) ([]Input, bool, error) {
 			R:    f,
 			F:    format,
 		})
+		size += info.Size()
+		prog.add(info.Size())
 		if c.MaxItems > 0 && len(have) >= c.MaxItems {
 			return errStop
 		}
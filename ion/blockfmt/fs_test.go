@@ -0,0 +1,424 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package blockfmt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SnellerInc/sneller/ion"
+)
+
+func TestDirFSRename(t *testing.T) {
+	dir := t.TempDir()
+	dfs := NewDirFS(dir)
+	var _ Renamer = dfs
+	var _ Renamer = &S3FS{}
+
+	_, err := dfs.WriteFile("staged/a", []byte("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rename into a directory that doesn't exist yet
+	err = dfs.Rename("staged/a", "final/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dfs.Stat("staged/a"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected staged/a to be gone; got %v", err)
+	}
+	info, err := dfs.Stat("final/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != int64(len("first")) {
+		t.Fatalf("size %d", info.Size())
+	}
+
+	// overwrite an existing destination
+	_, err = dfs.WriteFile("staged/b", []byte("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dfs.Rename("staged/b", "final/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := os.ReadFile(filepath.Join(dir, "final", "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "second" {
+		t.Fatalf("got contents %q", buf)
+	}
+
+	// a missing source is an error
+	err = dfs.Rename("staged/b", "final/b")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected ErrNotExist; got %v", err)
+	}
+
+	invalid := [][2]string{
+		{"../outside", "final/c"},
+		{"final/a", "../outside"},
+		{"/final/a", "final/c"},
+		{"final/a", "."},
+		{".", "final/c"},
+	}
+	for _, pair := range invalid {
+		err := dfs.Rename(pair[0], pair[1])
+		if !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("Rename(%q, %q): expected ErrInvalid; got %v", pair[0], pair[1], err)
+		}
+	}
+	if _, err := dfs.Stat("final/a"); err != nil {
+		t.Fatalf("final/a disturbed by invalid renames: %v", err)
+	}
+}
+
+func TestDirFSRemoveAll(t *testing.T) {
+	dir := t.TempDir()
+	dfs := NewDirFS(dir)
+	var _ BatchRemover = dfs
+	var _ BatchRemover = &S3FS{}
+	for _, p := range []string{"a", "b", "c/d"} {
+		_, err := dfs.WriteFile(p, []byte(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// "c" is a non-empty directory, and "missing"
+	// doesn't exist, which does not count as a failure
+	failed, err := dfs.RemoveAll([]string{"a", "../x", "missing", "c", "b"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	want := []string{"../x", "c"}
+	if !slices.Equal(failed, want) {
+		t.Fatalf("failed = %v, want %v", failed, want)
+	}
+	for _, p := range []string{"a", "b"} {
+		if _, err := dfs.Stat(p); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: expected ErrNotExist; got %v", p, err)
+		}
+	}
+	failed, err = dfs.RemoveAll([]string{"c/d", "c"})
+	if err != nil || len(failed) != 0 {
+		t.Fatalf("got %v, %v", failed, err)
+	}
+}
+
+func TestDirFSWriteFileIfMatch(t *testing.T) {
+	dfs := NewDirFS(t.TempDir())
+	var _ ConditionalWriter = dfs
+	var _ ConditionalWriter = &S3FS{}
+
+	// not-exists: only an empty ETag matches
+	_, err := dfs.WriteFileIfMatch("x/file", []byte("v0"), `"b2sum:bogus"`)
+	if !errors.Is(err, ErrETagMismatch) {
+		t.Fatalf("expected ErrETagMismatch; got %v", err)
+	}
+	etag0, err := dfs.WriteFileIfMatch("x/file", []byte("v0"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// now that it exists, an empty ETag does not match
+	_, err = dfs.WriteFileIfMatch("x/file", []byte("v1"), "")
+	if !errors.Is(err, ErrETagMismatch) {
+		t.Fatalf("expected ErrETagMismatch; got %v", err)
+	}
+	// match
+	etag1, err := dfs.WriteFileIfMatch("x/file", []byte("v1"), etag0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if etag1 == etag0 {
+		t.Fatal("ETag did not change")
+	}
+	// mismatch: a stale ETag is rejected
+	// and the file is left alone
+	_, err = dfs.WriteFileIfMatch("x/file", []byte("v2"), etag0)
+	if !errors.Is(err, ErrETagMismatch) {
+		t.Fatalf("expected ErrETagMismatch; got %v", err)
+	}
+	buf, err := fs.ReadFile(dfs, "x/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "v1" {
+		t.Fatalf("got contents %q", buf)
+	}
+	info, err := dfs.Stat("x/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag, err := dfs.ETag("x/file", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if etag != etag1 {
+		t.Fatalf("ETag %s != returned ETag %s", etag, etag1)
+	}
+
+	// concurrent writers that spell the same path
+	// differently expect the same ETag; only one wins
+	paths := []string{"x/file", "x/../x/file", "./x/file", "x//file"}
+	errs := make([]error, len(paths))
+	var wg sync.WaitGroup
+	for i := range paths {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = dfs.WriteFileIfMatch(paths[i], []byte(paths[i]), etag1)
+		}(i)
+	}
+	wg.Wait()
+	won := 0
+	for i := range errs {
+		if errs[i] == nil {
+			won++
+		} else if !errors.Is(errs[i], ErrETagMismatch) {
+			t.Fatalf("%s: %v", paths[i], errs[i])
+		}
+	}
+	if won != 1 {
+		t.Fatalf("%d writes succeeded", won)
+	}
+	if len(dfs.condPaths) != 0 {
+		t.Fatalf("%d path locks left", len(dfs.condPaths))
+	}
+	if _, err := dfs.WriteFileIfMatch("x/..", nil, ""); !errors.Is(err, fs.ErrInvalid) {
+		t.Fatalf("expected fs.ErrInvalid; got %v", err)
+	}
+}
+
+type fakeFormat struct{}
+
+func (fakeFormat) Name() string { return "fakelog" }
+
+func (fakeFormat) Convert(r io.Reader, dst *ion.Chunker, cons []ion.Field) error {
+	return nil
+}
+
+func TestRegisterFormat(t *testing.T) {
+	dir := t.TempDir()
+	dfs := NewDirFS(dir)
+	for _, name := range []string{"a.fakelog", "b.fakelog", "c.json"} {
+		_, err := dfs.WriteFile("in/"+name, []byte("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	noFormat := func() {
+		t.Helper()
+		lst, err := CollectGlob(dfs, nil, "in/*.fakelog")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range lst {
+			lst[i].R.Close()
+			if lst[i].F != nil {
+				t.Errorf("%s: unexpected format %s", lst[i].Path, lst[i].F.Name())
+			}
+		}
+	}
+	// without a registered format and without
+	// a fallback, no format should be inferred
+	noFormat()
+
+	RegisterFormat(".fakelog", func([]byte) (RowFormat, error) {
+		return fakeFormat{}, nil
+	})
+	defer UnregisterFormat(".fakelog")
+	lst, err := CollectGlob(dfs, nil, "in/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	formats := make(map[string]string)
+	for i := range lst {
+		formats[filepath.Base(lst[i].Path)] = lst[i].F.Name()
+		lst[i].R.Close()
+	}
+	want := map[string]string{
+		"a.fakelog": "fakelog",
+		"b.fakelog": "fakelog",
+		"c.json":    "json",
+	}
+	if !maps.Equal(formats, want) {
+		t.Errorf("got formats %v, want %v", formats, want)
+	}
+
+	UnregisterFormat(".fakelog")
+	if LookupFormat(".fakelog") != nil {
+		t.Fatal("format still registered")
+	}
+	noFormat()
+}
+
+func TestFormatForName(t *testing.T) {
+	// the longest matching suffix should win
+	f := FormatForName("logs/x.cloudtrail.json.gz")
+	if f == nil {
+		t.Fatal("no format")
+	}
+	rf, err := f(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rf.Name() != "cloudtrail.json.gz" {
+		t.Errorf("got format %q", rf.Name())
+	}
+	if FormatForName("x.unknown") != nil {
+		t.Error("unexpected format for x.unknown")
+	}
+}
+
+func TestCollectExclude(t *testing.T) {
+	dir := t.TempDir()
+	dfs := NewDirFS(dir)
+	etags := make(map[string]string)
+	for _, name := range []string{"a.json", "b.json", "c.json"} {
+		etag, err := dfs.WriteFile("in/"+name, []byte("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		etags[dfs.Prefix()+"in/"+name] = etag
+	}
+	// b.json changes after it has been collected,
+	// and d.json has never been collected
+	_, err := dfs.WriteFile("in/b.json", []byte(`{"x": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = dfs.WriteFile("in/d.json", []byte("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	collect := func(reingest bool) []string {
+		t.Helper()
+		c := Collector{
+			Pattern:  "in/*.json",
+			Exclude:  etags,
+			Reingest: reingest,
+		}
+		lst, complete, err := c.Collect(dfs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !complete {
+			t.Fatal("expected complete listing")
+		}
+		var names []string
+		for i := range lst {
+			lst[i].R.Close()
+			names = append(names, filepath.Base(lst[i].Path))
+		}
+		return names
+	}
+	if got, want := collect(false), []string{"d.json"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := collect(true), []string{"b.json", "d.json"}; !slices.Equal(got, want) {
+		t.Errorf("reingest: got %v, want %v", got, want)
+	}
+}
+
+func TestCollectMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	dfs := NewDirFS(dir)
+	for i := 0; i < 5; i++ {
+		_, err := dfs.WriteFile(fmt.Sprintf("in/%d.json", i), []byte(strings.Repeat("x", 10)))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tc := range []struct {
+		max      int64
+		items    int
+		complete bool
+	}{
+		{0, 5, true},
+		{1000, 5, true},
+		// the item that reaches the limit is included
+		{25, 3, false},
+		{10, 1, false},
+	} {
+		c := Collector{
+			Pattern: "in/*.json",
+			MaxSize: tc.max,
+		}
+		lst, complete, err := c.Collect(dfs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range lst {
+			lst[i].R.Close()
+		}
+		if len(lst) != tc.items || complete != tc.complete {
+			t.Errorf("MaxSize %d: got %d items (complete=%v), want %d (complete=%v)",
+				tc.max, len(lst), complete, tc.items, tc.complete)
+		}
+	}
+}
+
+func TestCollectProgress(t *testing.T) {
+	defer func(d time.Duration) {
+		progressInterval = d
+	}(progressInterval)
+	dir := t.TempDir()
+	dfs := NewDirFS(dir)
+	total := int64(0)
+	for i := 0; i < 5; i++ {
+		text := strings.Repeat("x", i+1)
+		_, err := dfs.WriteFile(fmt.Sprintf("in/%d.json", i), []byte(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += int64(len(text))
+	}
+	for _, interval := range []time.Duration{0, time.Hour} {
+		var calls []progressCall
+		progressInterval = interval
+		c := Collector{
+			Pattern: "in/*.json",
+			Progress: func(n int, size int64) {
+				calls = append(calls, progressCall{n, size})
+			},
+		}
+		lst, _, err := c.Collect(dfs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range lst {
+			lst[i].R.Close()
+		}
+		checkProgress(t, calls, 5, total)
+		// a long interval should only
+		// produce the final report
+		if interval == time.Hour && len(calls) != 1 {
+			t.Errorf("got %d calls with a long interval", len(calls))
+		}
+	}
+}
@@ -20,7 +20,9 @@ import (
 	"encoding/base32"
 	"errors"
 	"fmt"
+	"io/fs"
 	"path"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -164,6 +166,10 @@ type Index struct {
 	// Scanning indicates that scanning has
 	// not yet completed.
 	Scanning bool
+
+	// Tombstones is the list of predicates
+	// matching rows that have been deleted.
+	Tombstones []Tombstone
 }
 
 const (
@@ -234,6 +240,7 @@ func Sign(key *Key, idx *Index) ([]byte, error) {
 		expiry   = st.Intern("expiry")
 		indirect = st.Intern("indirect")
 		inputs   = st.Intern("inputs")
+		tombs    = st.Intern("tombstones")
 	)
 	var ibuf ion.Buffer
 	buf.BeginStruct(-1)
@@ -329,6 +336,11 @@ func Sign(key *Key, idx *Index) ([]byte, error) {
 		}
 	}
 
+	if len(idx.Tombstones) > 0 {
+		buf.BeginField(tombs)
+		writeTombstones(&buf, &st, idx.Tombstones)
+	}
+
 	buf.EndStruct()
 	tail := buf.Bytes()
 	buf.Set(nil)
@@ -420,8 +432,17 @@ var (
 	// for an object does not match the
 	// computed MAC.
 	ErrBadMAC = errors.New("bad index signature")
+	// ErrCorruptIndex is returned when an index
+	// or one of the objects it references cannot
+	// be decoded.
+	ErrCorruptIndex = errors.New("corrupt index")
 )
 
+// maxIndexDepth is the maximum nesting depth
+// of the ion structures accepted when decoding
+// indexes, index trees, and trailers
+const maxIndexDepth = 64
+
 func (o *ObjectInfo) set(f ion.Field) (bool, error) {
 	var err error
 	switch f.Label {
@@ -484,7 +505,7 @@ func (idx *Index) readInputs(st *ion.Symtab, d ion.Datum, isize int64, alg strin
 	if err := decomp.Decompress(b, contents); err != nil {
 		return fmt.Errorf("DecodeIndex: readInputs: %w", err)
 	}
-	d, _, err = ion.ReadDatum(st, contents)
+	d, _, err = ion.ReadDatumLimited(st, contents, maxIndexDepth)
 	if err != nil {
 		return err
 	}
@@ -525,7 +546,7 @@ func DecodeIndex(key *Key, index []byte, opts Flag) (*Index, error) {
 	if err != nil {
 		return nil, err
 	}
-	d, _, err := ion.ReadDatum(&st, rest)
+	d, _, err := ion.ReadDatumLimited(&st, rest, maxIndexDepth)
 	if err != nil {
 		return nil, err
 	}
@@ -606,18 +627,20 @@ func DecodeIndex(key *Key, index []byte, opts Flag) (*Index, error) {
 			})
 		case "last-scan":
 			idx.LastScan, err = f.Timestamp()
+		case "tombstones":
+			idx.Tombstones, err = readTombstones(f.Datum)
 		default:
 			err = fmt.Errorf("unexpected field %q", f.Label)
 		}
 		return
 	})
 	if err != nil {
-		return nil, fmt.Errorf("DecodeIndex: decoding structure: %w", err)
+		return nil, fmt.Errorf("DecodeIndex: %w: decoding structure: %w", ErrCorruptIndex, err)
 	}
 	if !inputs.IsEmpty() {
 		err := idx.readInputs(&st, inputs, isize, idx.Algo)
 		if err != nil {
-			return nil, fmt.Errorf("DecodeIndex: decoding inputs: %w", err)
+			return nil, fmt.Errorf("DecodeIndex: %w: decoding inputs: %w", ErrCorruptIndex, err)
 		}
 	}
 	// we don't currently maintain any backwards-compatibility shims:
@@ -629,7 +652,7 @@ func DecodeIndex(key *Key, index []byte, opts Flag) (*Index, error) {
 	}
 	if contents.IsBlob() {
 		if idx.Algo == "" {
-			return nil, fmt.Errorf("DecodeIndex: missing compression algorithm")
+			return nil, fmt.Errorf("DecodeIndex: %w: missing compression algorithm", ErrCorruptIndex)
 		}
 		b, err := contents.Blob()
 		if err != nil {
@@ -639,11 +662,11 @@ func DecodeIndex(key *Key, index []byte, opts Flag) (*Index, error) {
 		buf := malloc(int(size))
 		defer free(buf)
 		if err := decomp.Decompress(b, buf); err != nil {
-			return nil, fmt.Errorf("DecodeIndex: %w", err)
+			return nil, fmt.Errorf("DecodeIndex: %w: %w", ErrCorruptIndex, err)
 		}
-		contents, _, err = ion.ReadDatum(&st, buf)
+		contents, _, err = ion.ReadDatumLimited(&st, buf, maxIndexDepth)
 		if err != nil {
-			return nil, fmt.Errorf("DecodeIndex: reading Contents %w", err)
+			return nil, fmt.Errorf("DecodeIndex: %w: reading Contents: %w", ErrCorruptIndex, err)
 		}
 	}
 	err = contents.UnpackList(func(d ion.Datum) error {
@@ -655,7 +678,7 @@ func DecodeIndex(key *Key, index []byte, opts Flag) (*Index, error) {
 		return nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf("DecodeIndex: decoding Contents: %w", err)
+		return nil, fmt.Errorf("DecodeIndex: %w: decoding Contents: %w", ErrCorruptIndex, err)
 	}
 	return idx, nil
 }
@@ -716,6 +739,94 @@ type IndexConfig struct {
 	// quarantined file should be left around
 	// after it has been dereferenced.
 	Expiry time.Duration
+	// Algo is the compression algorithm
+	// (as accepted by compr.Compression)
+	// used for new indirect references.
+	// If Algo is empty, zstd is used.
+	Algo string
+	// Stats, if non-nil, accumulates the
+	// compression statistics of the indirect
+	// references written by SyncOutputs.
+	Stats *compr.CompressStats
+	// AppendBatch is the maximum number of
+	// descriptors that Append writes in a single
+	// update of the indirect tree. If AppendBatch
+	// is less than or equal to zero, Append writes
+	// all of its descriptors in one update.
+	AppendBatch int
+	// Checkpoint, if non-nil, is called by Append
+	// each time a batch of descriptors has been
+	// written to an IndirectRef, with the updated
+	// index and the input path of the last descriptor
+	// in the batch. If Checkpoint durably stores
+	// the index and the path, an interrupted Append
+	// can be resumed by calling Append again with
+	// the stored index and the stored path as start.
+	// If Checkpoint returns an error, Append stops
+	// and returns that error.
+	Checkpoint func(idx *Index, last string) error
+}
+
+// Ingested is a Descriptor for a newly-written
+// packfile along with the path of the last
+// input object that was ingested into it.
+type Ingested struct {
+	Descriptor
+	// Input is the path of the last input
+	// object ingested into the packfile.
+	// Consecutive entries may share the
+	// same Input.
+	Input string
+}
+
+// Append appends lst to idx.Indirect, writing
+// new indirect references into dir within ofs.
+// If start is not empty, the entries in lst
+// up to and including the last one with Input
+// equal to start are assumed to have been appended
+// already (see c.Checkpoint) and are skipped.
+//
+// Append only calls c.Checkpoint once every
+// entry sharing an Input has been appended, so a
+// batch ending in the middle of a run of entries
+// with the same Input is not checkpointed until
+// the end of the run.
+func (c *IndexConfig) Append(idx *Index, ofs UploadFS, dir string, lst []Ingested, start string) error {
+	if start != "" {
+		i := slices.IndexFunc(lst, func(in Ingested) bool {
+			return in.Input == start
+		})
+		if i < 0 {
+			return fmt.Errorf("blockfmt.IndexConfig.Append: start %q not in list", start)
+		}
+		for i+1 < len(lst) && lst[i+1].Input == start {
+			i++
+		}
+		lst = lst[i+1:]
+	}
+	for len(lst) > 0 {
+		n := len(lst)
+		if c.AppendBatch > 0 && n > c.AppendBatch {
+			n = c.AppendBatch
+		}
+		batch := make([]Descriptor, n)
+		for i := range batch {
+			batch[i] = lst[i].Descriptor
+		}
+		last := lst[n-1].Input
+		lst = lst[n:]
+		err := c.append(idx, ofs, dir, batch, n)
+		if err != nil {
+			return err
+		}
+		if c.Checkpoint != nil && (len(lst) == 0 || lst[0].Input != last) {
+			err = c.Checkpoint(idx, last)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // SyncOutputs synchronizes idx.Indirect to a directory
@@ -792,6 +903,77 @@ func (idx *Index) Objects() int {
 	return idx.Indirect.OrigObjects() + len(idx.Inline)
 }
 
+// Rows returns the number of rows in the packed
+// objects pointed to by this Index, or false if it
+// is not known. The number of rows is only known
+// when every inlined object records the number of
+// rows in each of its blocks and every indirect
+// reference records the number of rows it covers.
+// Rows matching tombstones are included in the count.
+func (idx *Index) Rows() (int64, bool) {
+	n := int64(0)
+	for i := range idx.Indirect.Refs {
+		rows := idx.Indirect.Refs[i].Rows
+		if rows <= 0 {
+			return 0, false
+		}
+		n += rows
+	}
+	for i := range idx.Inline {
+		rows, ok := idx.Inline[i].Trailer.TotalRows()
+		if !ok {
+			return 0, false
+		}
+		n += rows
+	}
+	return n, true
+}
+
+// Verify checks that every packed object and
+// every indirect reference pointed to by this
+// Index is present in ifs with the recorded ETag.
+// Verify does not stop at the first problem; it
+// returns one error for each missing or modified
+// object, or nil if there are no problems.
+func (idx *Index) Verify(ifs InputFS) []error {
+	var errs []error
+	check := func(p, etag string) {
+		if err := verifyETag(ifs, p, etag); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i := range idx.Inline {
+		check(idx.Inline[i].Path, idx.Inline[i].ETag)
+	}
+	for i := range idx.Indirect.Refs {
+		r := &idx.Indirect.Refs[i]
+		descs, err := idx.Indirect.decode(ifs, r, nil, nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Path, err))
+			continue
+		}
+		for j := range descs {
+			check(descs[j].Path, descs[j].ETag)
+		}
+	}
+	return errs
+}
+
+func verifyETag(ifs InputFS, p, etag string) error {
+	info, err := fs.Stat(ifs, p)
+	if err != nil {
+		return err
+	}
+	got, err := ifs.ETag(p, info)
+	if err != nil {
+		return fmt.Errorf("%s: %w", p, err)
+	}
+	if got != etag {
+		return fmt.Errorf("%s: %w: %s -> %s", p, ErrETagChanged, etag, got)
+	}
+	return nil
+}
+
 // Descs collects the list of objects from an
 // index and returns them as a list of
 // descriptors against which queries can be run
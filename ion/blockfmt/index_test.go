@@ -16,12 +16,14 @@ package blockfmt
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -382,3 +384,78 @@ func BenchmarkIndexDecodingAllocs(b *testing.B) {
 		}
 	}
 }
+
+func TestIndexVerify(t *testing.T) {
+	dir := NewDirFS(t.TempDir())
+	dir.MinPartSize = 1
+	idx := smallIndirect(t, dir, date.Now().Truncate(time.Microsecond))
+	if len(idx.Inline) == 0 || len(idx.Indirect.Refs) == 0 {
+		t.Fatal("expected both inline and indirect objects")
+	}
+	if errs := idx.Verify(dir); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	descs, err := idx.Indirect.Search(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// tamper with an object only reachable
+	// through the indirect tree
+	tampered := descs[0].Path
+	_, err = dir.WriteFile(tampered, []byte("tampered"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := idx.Verify(dir)
+	if len(errs) != 1 {
+		t.Fatalf("got %d errors: %v", len(errs), errs)
+	}
+	if !errors.Is(errs[0], ErrETagChanged) || !strings.Contains(errs[0].Error(), tampered) {
+		t.Errorf("unexpected error %v", errs[0])
+	}
+	// a missing inline object is also reported
+	err = dir.Remove(idx.Inline[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs = idx.Verify(dir)
+	if len(errs) != 2 {
+		t.Fatalf("got %d errors: %v", len(errs), errs)
+	}
+	if !errors.Is(errs[0], fs.ErrNotExist) {
+		t.Errorf("unexpected error %v", errs[0])
+	}
+}
+
+func TestIndexRows(t *testing.T) {
+	desc := func(rows ...int64) Descriptor {
+		var d Descriptor
+		for _, n := range rows {
+			d.Trailer.Blocks = append(d.Trailer.Blocks, Blockdesc{Rows: n})
+		}
+		return d
+	}
+	idx := &Index{}
+	if n, ok := idx.Rows(); !ok || n != 0 {
+		t.Errorf("empty index: got %d, %v", n, ok)
+	}
+	idx.Inline = []Descriptor{desc(10, 20), desc(5)}
+	if n, ok := idx.Rows(); !ok || n != 35 {
+		t.Errorf("got %d, %v; wanted 35", n, ok)
+	}
+	// rows are unknown if any block lacks a count
+	idx.Inline = append(idx.Inline, desc(1, 0))
+	if _, ok := idx.Rows(); ok {
+		t.Error("expected unknown row count")
+	}
+	// ... or if an indirect ref lacks a count
+	idx.Inline = idx.Inline[:2]
+	idx.Indirect.Refs = []IndirectRef{{Objects: 1}}
+	if _, ok := idx.Rows(); ok {
+		t.Error("expected unknown row count with indirect refs")
+	}
+	idx.Indirect.Refs = []IndirectRef{{Objects: 1, Rows: 100}, {Objects: 2, Rows: 5}}
+	if n, ok := idx.Rows(); !ok || n != 140 {
+		t.Errorf("got %d, %v; wanted 140", n, ok)
+	}
+}
@@ -23,7 +23,7 @@ import (
 	"syscall"
 )
 
-func mmap(fp string) ([]byte, error) {
+func mmap(fp string, sequential bool) ([]byte, error) {
 	f, err := os.Open(fp)
 	if err != nil {
 		return nil, err
@@ -40,9 +40,24 @@ func mmap(fp string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if sequential && len(mem) > 0 {
+		// this is only a hint, so errors are ignored
+		syscall.Madvise(mem, syscall.MADV_SEQUENTIAL)
+	}
 	return mem, nil
 }
 
+// willneed advises the kernel that mem[start:end]
+// will be accessed soon; mem must be a mapping
+// returned by mmap
+func willneed(mem []byte, start, end int) {
+	start &^= os.Getpagesize() - 1
+	if start < 0 || start >= end || end > len(mem) {
+		return
+	}
+	syscall.Madvise(mem[start:end], syscall.MADV_WILLNEED)
+}
+
 func unmap(mem []byte) error {
 	return syscall.Munmap(mem)
 }
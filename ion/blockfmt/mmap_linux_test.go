@@ -0,0 +1,53 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+//go:build linux
+
+package blockfmt
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMmapSequential(t *testing.T) {
+	dir := t.TempDir()
+	pagesize := os.Getpagesize()
+	want := bytes.Repeat([]byte("0123456789abcdef"), 4*pagesize/16+3)
+	err := os.WriteFile(filepath.Join(dir, "file"), want, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, seq := range []bool{false, true} {
+		dfs := &DirFS{Root: dir, SequentialMmap: seq}
+		mem, err := dfs.Mmap("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// unaligned, empty, and out-of-range
+		// hints should all be harmless
+		dfs.WillNeed(mem, 17, int64(2*pagesize+5))
+		dfs.WillNeed(mem, int64(pagesize), int64(pagesize))
+		dfs.WillNeed(mem, int64(3*pagesize), int64(len(mem)))
+		dfs.WillNeed(mem, 0, int64(len(mem)+1))
+		if !bytes.Equal(mem, want) {
+			t.Errorf("sequential=%v: mapped content mismatch", seq)
+		}
+		if err := dfs.Unmap(mem); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
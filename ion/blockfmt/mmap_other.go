@@ -22,10 +22,12 @@ import (
 
 var errUnimplemented = errors.New("mmap not implemented on this platform")
 
-func mmap(_ string) ([]byte, error) {
+func mmap(_ string, _ bool) ([]byte, error) {
 	return nil, errUnimplemented
 }
 
+func willneed(_ []byte, _, _ int) {}
+
 func unmap(buf []byte) error {
 	return errUnimplemented
 }
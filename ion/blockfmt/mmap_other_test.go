@@ -0,0 +1,42 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+//go:build !linux
+
+package blockfmt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMmapSequential(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "file"), []byte("0123456789abcdef"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, seq := range []bool{false, true} {
+		dfs := &DirFS{Root: dir, SequentialMmap: seq}
+		_, err := dfs.Mmap("file")
+		if !errors.Is(err, errUnimplemented) {
+			t.Errorf("sequential=%v: expected errUnimplemented; got %v", seq, err)
+		}
+		// hints are a no-op without a mapping
+		dfs.WillNeed(nil, 0, 16)
+		dfs.WillNeed([]byte("0123456789abcdef"), 4, 12)
+	}
+}
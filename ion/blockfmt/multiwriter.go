@@ -16,6 +16,7 @@ package blockfmt
 
 import (
 	"fmt"
+	"hash/crc32"
 	"io"
 	"sort"
 	"sync"
@@ -115,6 +116,8 @@ type singleStream struct {
 	comp        Compressor
 	lastblock   int64
 	flushblocks int
+	checksum    uint32 // checksum of the frames since lastblock
+	rows        int64  // rows in the frames since lastblock, or -1 if unknown
 
 	bg chan error
 }
@@ -227,12 +230,17 @@ func (s *singleStream) Flush() error {
 		// add any recent metadata
 		// to the blocks written since the last Flush
 		s.curspan.blockmap = append(s.curspan.blockmap, blockpart{
-			offset: s.lastblock,
-			chunks: s.flushblocks,
-			ranges: s.futureRange.pop(),
+			offset:   s.lastblock,
+			size:     int64(len(s.buf)) - s.lastblock,
+			chunks:   s.flushblocks,
+			ranges:   s.futureRange.pop(),
+			checksum: s.checksum,
+			rows:     s.rows,
 		})
 		s.lastblock = int64(len(s.buf))
 		s.flushblocks = 0
+		s.checksum = 0
+		s.rows = 0
 	}
 	// actually flush only if we've buffered
 	// enough to satisfy the upload invariants
@@ -268,8 +276,16 @@ func (s *singleStream) Write(p []byte) (int, error) {
 	}
 	s.flushblocks++
 	var err error
+	before := len(s.buf)
 	s.buf, err = appendFrame(s.buf, s.comp, p)
-	return len(p), err
+	if err != nil {
+		return len(p), err
+	}
+	s.checksum = crc32.Update(s.checksum, castagnoli, s.buf[before:])
+	if s.rows >= 0 {
+		s.rows += CountRows(p)
+	}
+	return len(p), nil
 }
 
 func (s *singleStream) setSymbols(st *ion.Symtab) {
@@ -278,7 +294,10 @@ func (s *singleStream) setSymbols(st *ion.Symtab) {
 
 func (s *singleStream) writeCompressed(p []byte) error {
 	s.flushblocks++
+	before := len(s.buf)
 	s.buf = appendRawFrame(s.buf, p)
+	s.checksum = crc32.Update(s.checksum, castagnoli, s.buf[before:])
+	s.rows = -1 // not decompressed, so unknown
 	return nil
 }
 
@@ -432,9 +451,12 @@ func (m *MultiWriter) finalize() {
 				panic("blocks out-of-order")
 			}
 			all = append(all, blockpart{
-				offset: block.offset + offset,
-				chunks: block.chunks,
-				ranges: block.ranges,
+				offset:   block.offset + offset,
+				size:     block.size,
+				chunks:   block.chunks,
+				ranges:   block.ranges,
+				checksum: block.checksum,
+				rows:     block.rows,
 			})
 			prev = block.offset
 		}
@@ -15,6 +15,8 @@
 package blockfmt
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"io/fs"
@@ -37,6 +39,12 @@ type IndirectTree struct {
 	// Sparse describes the intervals within refs
 	// that correspond to particular time ranges.
 	Sparse SparseIndex
+
+	// Cache, if non-nil, is consulted for the
+	// descriptor lists of Refs before they are
+	// read from the backing store.
+	// Cache is not part of the encoded tree.
+	Cache *DescCache
 }
 
 // IndirectRef references an object
@@ -51,6 +59,19 @@ type IndirectRef struct {
 	// that were compacted to produce the
 	// packfiles pointed to by Path.
 	OrigObjects int
+	// Algo is the compression algorithm
+	// of the object pointed to by Path.
+	// If Algo is empty, the object is
+	// compressed with zstd.
+	Algo string
+	// DecompressedSize is the decompressed
+	// size of the object when Algo is set.
+	DecompressedSize int64
+	// Rows is the number of rows in the
+	// objects referenced by the packed file
+	// pointed to by Path, or zero if it is
+	// not known.
+	Rows int64
 
 	// for decoding compatibility only!
 	ranges []Range
@@ -74,6 +95,9 @@ func (i *IndirectTree) encode(st *ion.Symtab, buf *ion.Buffer) {
 	size := st.Intern("size")
 	objects := st.Intern("objects")
 	origObjects := st.Intern("orig-objects")
+	algo := st.Intern("algo")
+	decompressedSize := st.Intern("decompressed-size")
+	rows := st.Intern("rows")
 
 	buf.BeginStruct(-1)
 	buf.BeginField(st.Intern("refs"))
@@ -92,6 +116,16 @@ func (i *IndirectTree) encode(st *ion.Symtab, buf *ion.Buffer) {
 		buf.WriteInt(int64(i.Refs[j].Objects))
 		buf.BeginField(origObjects)
 		buf.WriteInt(int64(i.Refs[j].OrigObjects))
+		if i.Refs[j].Algo != "" {
+			buf.BeginField(algo)
+			buf.WriteString(i.Refs[j].Algo)
+			buf.BeginField(decompressedSize)
+			buf.WriteInt(i.Refs[j].DecompressedSize)
+		}
+		if i.Refs[j].Rows > 0 {
+			buf.BeginField(rows)
+			buf.WriteInt(i.Refs[j].Rows)
+		}
 		buf.EndStruct()
 	}
 	buf.EndList()
@@ -108,6 +142,8 @@ func (i *IndirectTree) parse(td *TrailerDecoder, d ion.Datum) error {
 		switch f.Label {
 		case "refs":
 			return f.UnpackList(func(d ion.Datum) error {
+				// refs written without a row count
+				// are left with Rows == 0 (unknown)
 				var ir IndirectRef
 				err := d.UnpackStruct(func(f ion.Field) error {
 					switch f.Label {
@@ -133,6 +169,27 @@ func (i *IndirectTree) parse(td *TrailerDecoder, d ion.Datum) error {
 						}
 						ir.OrigObjects = int(n)
 						return nil
+					case "algo":
+						algo, err := f.String()
+						if err != nil {
+							return err
+						}
+						ir.Algo = algo
+						return nil
+					case "decompressed-size":
+						n, err := f.Int()
+						if err != nil {
+							return err
+						}
+						ir.DecompressedSize = n
+						return nil
+					case "rows":
+						n, err := f.Int()
+						if err != nil {
+							return err
+						}
+						ir.Rows = n
+						return nil
 					default:
 						_, err := ir.ObjectInfo.set(f)
 						return err
@@ -150,15 +207,15 @@ func (i *IndirectTree) parse(td *TrailerDecoder, d ion.Datum) error {
 			})
 		case "sparse":
 			if haveRanges {
-				return fmt.Errorf("IndirectTree.parse: have ranges *and* sparse?")
+				return fmt.Errorf("IndirectTree.parse: %w: have ranges *and* sparse?", ErrCorruptIndex)
 			}
 			err := td.decodeSparse(&i.Sparse, f.Datum)
 			if err != nil {
-				err = fmt.Errorf("Indirect.Sparse.Decode: %w", err)
+				err = fmt.Errorf("Indirect.Sparse.Decode: %w: %w", ErrBadSparse, err)
 			}
 			return err
 		default:
-			return fmt.Errorf("IndirectTree.parse: unexpected field name %q", f.Label)
+			return fmt.Errorf("IndirectTree.parse: %w: unexpected field name %q", ErrCorruptIndex, f.Label)
 		}
 	})
 	// build time ranges if we have them
@@ -185,68 +242,205 @@ func keepAny(t *Trailer, filt *Filter) bool {
 }
 
 func (i *IndirectTree) decode(ifs InputFS, src *IndirectRef, in []Descriptor, filt *Filter) ([]Descriptor, error) {
+	if i.Cache == nil {
+		in, _, err := i.read(ifs, src, in, filt)
+		return in, err
+	}
+	descs, ok := i.Cache.get(src.Path, src.ETag)
+	if !ok {
+		var size int
+		var err error
+		descs, size, err = i.read(ifs, src, nil, nil)
+		if err != nil {
+			return in, err
+		}
+		i.Cache.put(src.Path, src.ETag, descs, int64(size))
+	}
+	for j := range descs {
+		if keepAny(&descs[j].Trailer, filt) {
+			in = append(in, descs[j])
+		}
+	}
+	return in, nil
+}
+
+// read reads the descriptors referenced by src
+// from ifs, appends the ones matching filt to in,
+// and returns the decompressed size of the list
+func (i *IndirectTree) read(ifs InputFS, src *IndirectRef, in []Descriptor, filt *Filter) ([]Descriptor, int, error) {
 	f, err := ifs.Open(src.Path)
 	if err != nil {
-		return in, err
+		return in, 0, err
 	}
 	defer f.Close()
 	info, err := f.Stat()
 	if err != nil {
-		return in, err
+		return in, 0, err
 	}
 	etag, err := ifs.ETag(src.Path, info)
 	if err != nil {
-		return in, err
+		return in, 0, err
 	}
 	if etag != src.ETag {
-		return in, fmt.Errorf("in IndirectTree: ETag changed: %s -> %s", src.ETag, etag)
+		return in, 0, fmt.Errorf("in IndirectTree: %w: %s -> %s", ErrETagChanged, src.ETag, etag)
 	}
 	// the contents of the object
 	// pointed to by an IndirectRef
-	// is a zstd-compressed bytestream
-	// wrapped in an ion 'blob' header;
+	// is a zstd-compressed (or src.Algo-compressed)
+	// bytestream wrapped in an ion 'blob' header;
 	// the contents of the decompressed
 	// bytestream is
 	//   {'contents': [descriptors...]}
 	// (with a leading symbol table)
-	buf := make([]byte, info.Size())
-	_, err = io.ReadFull(f, buf)
-	if err != nil {
-		return in, fmt.Errorf("IndirectTree: io.ReadFull: %w", err)
+	var r io.Reader
+	if src.Algo == "" {
+		zr, err := compr.DecodeReader(f)
+		if err != nil {
+			return in, 0, fmt.Errorf("IndirectTree: %w: compr.DecodeReader: %w", ErrCorruptIndex, err)
+		}
+		defer zr.Close()
+		r = zr
+	} else {
+		// registered algorithms only
+		// support decompressing in full
+		decomp := compr.Decompression(src.Algo)
+		if decomp == nil {
+			return in, 0, fmt.Errorf("IndirectTree: unknown compression algorithm %q", src.Algo)
+		}
+		if src.DecompressedSize < 0 || src.DecompressedSize > maxIndirectSize {
+			return in, 0, fmt.Errorf("IndirectTree: %w: invalid decompressed size %d", ErrCorruptIndex, src.DecompressedSize)
+		}
+		buf := make([]byte, info.Size())
+		_, err = io.ReadFull(f, buf)
+		if err != nil {
+			return in, 0, fmt.Errorf("IndirectTree: io.ReadFull: %w", err)
+		}
+		contents := make([]byte, src.DecompressedSize)
+		if err := decomp.Decompress(buf, contents); err != nil {
+			return in, 0, fmt.Errorf("IndirectTree: %w: %s: %w", ErrCorruptIndex, src.Algo, err)
+		}
+		r = bytes.NewReader(contents)
+	}
+	cr := &countingReader{r: r}
+	br := bufio.NewReader(cr)
+	in, err = readContents(br, in, filt)
+	if err == nil {
+		// consume the rest of the stream so
+		// that the checksum is verified
+		_, err = io.Copy(io.Discard, br)
 	}
-	buf, err = compr.DecodeZstd(buf, nil)
 	if err != nil {
-		return in, fmt.Errorf("IndirectTree: compr.DecodeZstd: %w", err)
+		return in, 0, fmt.Errorf("IndirectTree.decode: %w: %w", ErrCorruptIndex, err)
 	}
+	return in, int(cr.n), nil
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// readContents reads the decompressed contents
+// of an indirect ref one descriptor at a time,
+// so only one descriptor has to be buffered
+// rather than the whole stream
+func readContents(r *bufio.Reader, in []Descriptor, filt *Filter) ([]Descriptor, error) {
 	var st ion.Symtab
-	buf, err = st.Unmarshal(buf)
+	buf, err := readValue(r, -1)
+	if err != nil {
+		return in, err
+	}
+	if _, err := st.Unmarshal(buf); err != nil {
+		return in, err
+	}
+
+	// {'contents': [...]}
+	structBody, _, err := readHeader(r, ion.StructType)
 	if err != nil {
-		return in, fmt.Errorf("IndirectTree.decode: %w", err)
+		return in, err
+	}
+	p, _ := r.Peek(10)
+	sym, rest, err := ion.ReadLabel(p)
+	if err != nil {
+		return in, err
 	}
-	d, _, err := ion.ReadDatum(&st, buf)
+	if name, ok := st.Lookup(sym); !ok || name != "contents" {
+		return in, fmt.Errorf("unrecognized field %q", name)
+	}
+	labelSize := len(p) - len(rest)
+	r.Discard(labelSize)
+	listBody, listHeader, err := readHeader(r, ion.ListType)
 	if err != nil {
-		return in, fmt.Errorf("IndirectTree.decode: %w", err)
+		return in, err
 	}
+	if structBody != int64(labelSize+listHeader)+listBody {
+		// the contents should be the only field
+		return in, fmt.Errorf("list of %d bytes does not match struct of %d bytes", listBody, structBody)
+	}
+
 	var td TrailerDecoder
-	err = d.UnpackStruct(func(f ion.Field) error {
-		switch f.Label {
-		case "contents":
-			return f.UnpackList(func(v ion.Datum) error {
-				var d Descriptor
-				err := d.Decode(&td, v, 0)
-				if err != nil {
-					return err
-				}
-				if keepAny(&d.Trailer, filt) {
-					in = append(in, d)
-				}
-				return nil
-			})
-		default:
-			return fmt.Errorf("unrecognized field %q", f.Label)
+	for listBody > 0 {
+		buf, err := readValue(r, listBody)
+		if err != nil {
+			return in, err
 		}
-	})
-	return in, err
+		listBody -= int64(len(buf))
+		v, _, err := ion.ReadDatumLimited(&st, buf, maxIndexDepth-2)
+		if err != nil {
+			return in, err
+		}
+		var d Descriptor
+		if err := d.Decode(&td, v, 0); err != nil {
+			return in, err
+		}
+		if keepAny(&d.Trailer, filt) {
+			in = append(in, d)
+		}
+	}
+	return in, nil
+}
+
+// readHeader consumes the header of the next
+// value, which must have type t, and returns
+// the size of the body and the header
+func readHeader(r *bufio.Reader, t ion.Type) (int64, int, error) {
+	p, _ := r.Peek(10)
+	if len(p) == 0 {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	if ion.TypeOf(p) != t {
+		return 0, 0, fmt.Errorf("expected %s; found %s", t, ion.TypeOf(p))
+	}
+	size, hdr := ion.SizeOf(p), ion.HeaderSizeOf(p)
+	if size < 0 || hdr < 0 || hdr > size {
+		return 0, 0, fmt.Errorf("invalid %s header", t)
+	}
+	r.Discard(hdr)
+	return int64(size - hdr), hdr, nil
+}
+
+// readValue reads the next value, which must be
+// at most max bytes (unless max is negative)
+func readValue(r *bufio.Reader, max int64) ([]byte, error) {
+	_, size, err := ion.Peek(r)
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 || size > maxIndirectSize || (max >= 0 && int64(size) > max) {
+		return nil, fmt.Errorf("invalid value size %d", size)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
 }
 
 // Purge purges entries from the tree that do not
@@ -268,7 +462,7 @@ func (i *IndirectTree) Purge(ifs InputFS, keep *Filter, expiry time.Duration) ([
 		}
 		if si.Fields() > 0 {
 			if !si.AppendBlocks(&i.Sparse, start, end) {
-				err = fmt.Errorf("sparse index append failed?")
+				err = fmt.Errorf("%w: sparse index append failed?", ErrBadSparse)
 			}
 		}
 		deleted = append(deleted, i.Refs[prevend:start]...)
@@ -288,7 +482,7 @@ func (i *IndirectTree) Purge(ifs InputFS, keep *Filter, expiry time.Duration) ([
 	// with the refs we are keeping
 	if si.Fields() != 0 {
 		if nb, nk := si.Blocks(), len(kept); nb != nk {
-			return nil, fmt.Errorf("bad bookkeeping: %d blocks, %d kept", nb, nk)
+			return nil, fmt.Errorf("%w: bad bookkeeping: %d blocks, %d kept", ErrBadSparse, nb, nk)
 		}
 	}
 
@@ -347,6 +541,33 @@ func (i *IndirectTree) Search(ifs InputFS, filt *Filter) ([]Descriptor, error) {
 	return descs, err
 }
 
+// Locate decodes the refs in the tree, from oldest
+// to newest, until it finds the descriptor for the
+// object at fullpath. Locate returns an error wrapping
+// fs.ErrNotExist if no descriptor in the tree has
+// that path.
+//
+// Object paths do not encode the time ranges covered
+// by their contents, so the sparse index cannot be used
+// to narrow the search, and Locate may need to decode
+// every ref in the tree.
+func (i *IndirectTree) Locate(ifs InputFS, fullpath string) (*Descriptor, error) {
+	var descs []Descriptor
+	var err error
+	for j := range i.Refs {
+		descs, err = i.decode(ifs, &i.Refs[j], descs[:0], nil)
+		if err != nil {
+			return nil, err
+		}
+		for k := range descs {
+			if descs[k].Path == fullpath {
+				return &descs[k], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("IndirectTree.Locate: %s: %w", fullpath, fs.ErrNotExist)
+}
+
 // defaultTargetRefSize is the default target
 // size of stored refs; we keep appending to an
 // IndirectRef until its compressed size exceeds
@@ -358,6 +579,11 @@ func (i *IndirectTree) Search(ifs InputFS, filt *Filter) ([]Descriptor, error) {
 // approximation of the compressed size of one ref)
 const defaultTargetRefSize = 256 * 1024
 
+// maxIndirectSize is the maximum decompressed
+// size of an IndirectRef that we are willing
+// to allocate when decoding
+const maxIndirectSize = 1 << 30
+
 // append 1 new block to dst
 // for each indexed value in lst[*].Trailer.Sparse
 // where the block summarizes the union'd (min,max)
@@ -407,7 +633,23 @@ func (c *IndexConfig) append(idx *Index, ofs UploadFS, basedir string, lst []Des
 		pushSummary(&i.Sparse, lst)
 	}
 	all := append(prepend, lst...)
+	err = c.writeRef(ofs, basedir, r, all)
+	if err != nil {
+		return err
+	}
+	r.OrigObjects += delta
+	if prev != "" {
+		idx.ToDelete = append(idx.ToDelete, Quarantined{
+			Path:   prev,
+			Expiry: date.Now().Add(c.Expiry).Truncate(time.Microsecond),
+		})
+	}
+	return nil
+}
 
+// writeRef writes all to a new object in basedir
+// and updates r to point to it (except for r.OrigObjects)
+func (c *IndexConfig) writeRef(ofs UploadFS, basedir string, r *IndirectRef, all []Descriptor) error {
 	// encode the list of objects:
 	var buf ion.Buffer
 	var st ion.Symtab
@@ -420,7 +662,19 @@ func (c *IndexConfig) append(idx *Index, ofs UploadFS, basedir string, lst []Des
 	st.Marshal(&buf, true)
 	contents := buf.Bytes()
 	symtab, body := contents[split:], contents[:split]
-	compressed := compr.Compression("zstd").Compress(append(symtab, body...), nil)
+	algo := c.Algo
+	if algo == "" {
+		algo = "zstd"
+	}
+	comp := compr.Compression(algo)
+	if comp == nil {
+		return fmt.Errorf("IndirectTree: unknown compression algorithm %q", algo)
+	}
+	decompressed := append(symtab, body...)
+	compressed, stats := compr.CompressWithStats(comp, decompressed, nil)
+	if c.Stats != nil {
+		c.Stats.Add(&stats)
+	}
 
 	p := path.Join(basedir, "indirect-"+uuid())
 	etag, err := ofs.WriteFile(p, compressed)
@@ -431,7 +685,19 @@ func (c *IndexConfig) append(idx *Index, ofs UploadFS, basedir string, lst []Des
 	r.ETag = etag
 	r.Size = int64(len(compressed))
 	r.Objects = len(all)
-	r.OrigObjects += delta
+	r.Rows = 0
+	for i := range all {
+		n, ok := all[i].Trailer.TotalRows()
+		if !ok {
+			r.Rows = 0
+			break
+		}
+		r.Rows += n
+	}
+	r.Algo, r.DecompressedSize = c.Algo, 0
+	if c.Algo != "" {
+		r.DecompressedSize = int64(len(decompressed))
+	}
 
 	info, err := fs.Stat(ofs, p)
 	if err != nil {
@@ -442,14 +708,8 @@ func (c *IndexConfig) append(idx *Index, ofs UploadFS, basedir string, lst []Des
 		return err
 	}
 	if storedEtag != etag {
-		return fmt.Errorf("stored etag is %s instead of %s?", storedEtag, etag)
+		return fmt.Errorf("%w: stored etag is %s instead of %s?", ErrETagChanged, storedEtag, etag)
 	}
 	r.LastModified = date.FromTime(info.ModTime()).Truncate(time.Microsecond)
-	if prev != "" {
-		idx.ToDelete = append(idx.ToDelete, Quarantined{
-			Path:   prev,
-			Expiry: date.Now().Add(c.Expiry).Truncate(time.Microsecond),
-		})
-	}
 	return nil
 }
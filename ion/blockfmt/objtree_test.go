@@ -17,12 +17,17 @@ package blockfmt
 import (
 	"bytes"
 	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
 	"path"
 	"reflect"
 	"slices"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/SnellerInc/sneller/compr"
 	"github.com/SnellerInc/sneller/date"
 	"github.com/SnellerInc/sneller/expr"
 )
@@ -90,7 +95,7 @@ func TestIndirectTree(t *testing.T) {
 				Size:         16,
 			},
 			Trailer: Trailer{
-				Version:    1,
+				Version:    2,
 				Offset:     11,
 				BlockShift: 20,
 				Algo:       "zstd",
@@ -106,6 +111,7 @@ func TestIndirectTree(t *testing.T) {
 			d.Trailer.Blocks = append(d.Trailer.Blocks, Blockdesc{
 				Offset: int64(i) * 98246,
 				Chunks: 50,
+				Rows:   10,
 			})
 			d.Trailer.Sparse.push([]string{"timestamp"}, lo, hi)
 			d.Trailer.Sparse.bump()
@@ -211,6 +217,9 @@ func TestIndirectTree(t *testing.T) {
 			t.Errorf("Indirect.Objects() = %d, len(idx.Inline) = %d, len(all) = %d",
 				idx.Indirect.OrigObjects(), len(idx.Inline), len(all))
 		}
+		if n, ok := idx.Rows(); !ok || n != int64(len(all)*30*10) {
+			t.Errorf("iter %d: Rows() = %d, %v; wanted %d", i, n, ok, len(all)*30*10)
+		}
 
 		gotAll := allRefs(idx)
 		assertEquivalent(gotAll, all)
@@ -242,3 +251,404 @@ func TestIndirectTree(t *testing.T) {
 	}
 	t.Logf("final refs: %d, orig objects %d, objects: %d", len(idx.Indirect.Refs), idx.Indirect.OrigObjects(), idx.Objects())
 }
+
+// openCountFS counts calls to Open
+type openCountFS struct {
+	*DirFS
+	opens int
+}
+
+func (o *openCountFS) Open(name string) (fs.File, error) {
+	o.opens++
+	return o.DirFS.Open(name)
+}
+
+// smallIndirect returns an index with a few
+// small refs in its IndirectTree, one per hour
+// of descriptors starting at start
+func smallIndirect(t *testing.T, dir *DirFS, start date.Time) *Index {
+	return smallIndirectWith(t, dir, start, &IndexConfig{})
+}
+
+// smallDescriptor writes a small packfile
+// and returns a descriptor for it covering
+// hour i after start
+func smallDescriptor(t *testing.T, dir *DirFS, start date.Time, i int) Descriptor {
+	d := Descriptor{
+		ObjectInfo: ObjectInfo{
+			Path:         path.Join("db", "foo", "bar", "packed-"+uuid()),
+			LastModified: start,
+			Format:       Version,
+			Size:         16,
+		},
+		Trailer: Trailer{
+			Version:    1,
+			Offset:     11,
+			BlockShift: 20,
+			Algo:       "zstd",
+		},
+	}
+	lo := start.Add(time.Duration(i) * time.Hour)
+	d.Trailer.Blocks = append(d.Trailer.Blocks, Blockdesc{Chunks: 50})
+	d.Trailer.Sparse.push([]string{"timestamp"}, lo, lo.Add(time.Minute))
+	d.Trailer.Sparse.bump()
+	etag, err := dir.WriteFile(d.Path, bytes.Repeat([]byte{0xff}, int(d.Size)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.ETag = etag
+	return d
+}
+
+// smallIndirectWith is smallIndirect with the
+// remaining settings (Algo, Stats) taken from c
+func smallIndirectWith(t *testing.T, dir *DirFS, start date.Time, c *IndexConfig) *Index {
+	idx := &Index{Algo: "zstd"}
+	c.MaxInlined = 1
+	c.TargetSize = 1
+	c.TargetRefSize = 1
+	for i := 0; i < 4; i++ {
+		idx.Inline = append(idx.Inline, smallDescriptor(t, dir, start, i))
+		err := c.SyncOutputs(idx, dir, path.Join("db", "foo", "bar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(idx.Indirect.Refs) < 2 {
+		t.Fatalf("only %d indirect refs", len(idx.Indirect.Refs))
+	}
+	return idx
+}
+
+func TestIndirectTreeCache(t *testing.T) {
+	dir := NewDirFS(t.TempDir())
+	dir.MinPartSize = 1
+	start := date.Now().Truncate(time.Microsecond)
+	idx := smallIndirect(t, dir, start)
+
+	cfs := &openCountFS{DirFS: dir}
+	idx.Indirect.Cache = NewDescCache(1 << 20)
+	first, err := idx.Indirect.Search(cfs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfs.opens != len(idx.Indirect.Refs) {
+		t.Fatalf("%d opens for %d refs", cfs.opens, len(idx.Indirect.Refs))
+	}
+	if idx.Indirect.Cache.Size() == 0 {
+		t.Fatal("nothing cached")
+	}
+	second, err := idx.Indirect.Search(cfs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfs.opens != len(idx.Indirect.Refs) {
+		t.Errorf("second Search performed %d opens", cfs.opens-len(idx.Indirect.Refs))
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("cached results differ")
+	}
+	// filtering is still applied to cached lists
+	var f Filter
+	f.Compile(expr.Compare(expr.Less, expr.Identifier("timestamp"), &expr.Timestamp{Value: start.Add(time.Hour)}))
+	some, err := idx.Indirect.Search(cfs, &f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(some) != 1 || some[0].Path != first[0].Path {
+		t.Errorf("filtered search returned %d descriptors", len(some))
+	}
+
+	// an entry with a different ETag is dropped
+	// and the backing store is consulted again
+	opens := cfs.opens
+	size := idx.Indirect.Cache.Size()
+	etag := idx.Indirect.Refs[0].ETag
+	idx.Indirect.Refs[0].ETag = `"b2sum:bogus"`
+	_, err = idx.Indirect.Search(cfs, nil)
+	if !errors.Is(err, ErrETagChanged) {
+		t.Fatalf("expected ErrETagChanged; got %v", err)
+	}
+	if cfs.opens != opens+1 {
+		t.Errorf("expected one more open; got %d", cfs.opens-opens)
+	}
+	if idx.Indirect.Cache.Size() >= size {
+		t.Errorf("cache size %d -> %d", size, idx.Indirect.Cache.Size())
+	}
+
+	// a zero budget caches nothing
+	idx.Indirect.Refs[0].ETag = etag
+	idx.Indirect.Cache = NewDescCache(0)
+	opens = cfs.opens
+	for i := 0; i < 2; i++ {
+		_, err = idx.Indirect.Search(cfs, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cfs.opens != opens+2*len(idx.Indirect.Refs) {
+		t.Errorf("%d opens with an empty cache", cfs.opens-opens)
+	}
+}
+
+func TestIndirectTreeErrors(t *testing.T) {
+	dir := NewDirFS(t.TempDir())
+	dir.MinPartSize = 1
+	idx := smallIndirect(t, dir, date.Now().Truncate(time.Microsecond))
+	ref := &idx.Indirect.Refs[0]
+
+	// overwrite the object referenced by the tree
+	etag, err := dir.WriteFile(ref.Path, []byte("not a zstd stream"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = idx.Indirect.Search(dir, nil)
+	if !errors.Is(err, ErrETagChanged) {
+		t.Fatalf("expected ErrETagChanged; got %v", err)
+	}
+	if errors.Is(err, ErrCorruptIndex) {
+		t.Fatalf("%v should not be ErrCorruptIndex", err)
+	}
+
+	// with a matching ETag, the garbage is corrupt
+	ref.ETag = etag
+	_, err = idx.Indirect.Search(dir, nil)
+	if !errors.Is(err, ErrCorruptIndex) {
+		t.Fatalf("expected ErrCorruptIndex; got %v", err)
+	}
+	if errors.Is(err, ErrETagChanged) {
+		t.Fatalf("%v should not be ErrETagChanged", err)
+	}
+}
+
+func TestIndirectTreeLocate(t *testing.T) {
+	dir := NewDirFS(t.TempDir())
+	dir.MinPartSize = 1
+	idx := smallIndirect(t, dir, date.Now().Truncate(time.Microsecond))
+	all, err := idx.Indirect.Search(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfs := &openCountFS{DirFS: dir}
+	for i := range all {
+		cfs.opens = 0
+		d, err := idx.Indirect.Locate(cfs, all[i].Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(d, &all[i]) {
+			t.Fatalf("Locate(%s) returned %s", all[i].Path, d.Path)
+		}
+		if cfs.opens > len(idx.Indirect.Refs) {
+			t.Errorf("%d opens for %d refs", cfs.opens, len(idx.Indirect.Refs))
+		}
+	}
+	// the first descriptor lives in the first ref
+	cfs.opens = 0
+	_, err = idx.Indirect.Locate(cfs, all[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfs.opens != 1 {
+		t.Errorf("Locate did not stop at the first hit: %d opens", cfs.opens)
+	}
+	_, err = idx.Indirect.Locate(cfs, "db/foo/bar/packed-missing.ion.zst")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected ErrNotExist; got %v", err)
+	}
+}
+
+// rawCodec is a trivial codec that
+// stores the data uncompressed
+type rawCodec struct{}
+
+func (rawCodec) Name() string { return "blockfmt-test-raw" }
+
+func (rawCodec) Compress(src, dst []byte) []byte { return append(dst, src...) }
+
+func (rawCodec) Decompress(src, dst []byte) error {
+	if len(src) != len(dst) {
+		return fmt.Errorf("expected %d bytes decompressed; got %d", len(dst), len(src))
+	}
+	copy(dst, src)
+	return nil
+}
+
+var registerRawOnce sync.Once
+
+func TestIndirectTreeAlgo(t *testing.T) {
+	registerRawOnce.Do(func() {
+		compr.Register("blockfmt-test-raw", rawCodec{}, rawCodec{})
+	})
+	dir := NewDirFS(t.TempDir())
+	dir.MinPartSize = 1
+	start := date.Now().Truncate(time.Microsecond)
+	want, err := smallIndirect(t, dir, start).Indirect.Search(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, algo := range []string{"blockfmt-test-raw", "lz4"} {
+		t.Run(algo, func(t *testing.T) {
+			dir := NewDirFS(t.TempDir())
+			dir.MinPartSize = 1
+			stats := &compr.CompressStats{}
+			idx := smallIndirectWith(t, dir, start, &IndexConfig{Algo: algo, Stats: stats})
+			// each ref is written exactly once
+			if stats.Calls != len(idx.Indirect.Refs) {
+				t.Fatalf("%d compression calls for %d refs", stats.Calls, len(idx.Indirect.Refs))
+			}
+			if algo == "blockfmt-test-raw" && stats.In != stats.Out {
+				t.Fatalf("raw codec: %d bytes in, %d bytes out", stats.In, stats.Out)
+			}
+			var total int64
+			for i := range idx.Indirect.Refs {
+				total += idx.Indirect.Refs[i].Size
+			}
+			if stats.Out != total {
+				t.Fatalf("%d bytes out, but refs total %d bytes", stats.Out, total)
+			}
+			for i := range idx.Indirect.Refs {
+				ref := &idx.Indirect.Refs[i]
+				if ref.Algo != algo {
+					t.Fatalf("ref %d has algo %q", i, ref.Algo)
+				}
+				if ref.DecompressedSize <= 0 {
+					t.Fatalf("ref %d: decompressed size %d", i, ref.DecompressedSize)
+				}
+			}
+
+			// the algorithm should survive encoding the index
+			var key Key
+			rand.Read(key[:])
+			buf, err := Sign(&key, idx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			idx2, err := DecodeIndex(&key, buf, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(idx.Indirect.Refs, idx2.Indirect.Refs) {
+				t.Fatal("refs not equal after decoding")
+			}
+			got, err := idx2.Indirect.Search(dir, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d descriptors; want %d", len(got), len(want))
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i].Trailer, want[i].Trailer) {
+					t.Fatalf("descriptor %d: trailers not equal", i)
+				}
+			}
+
+			// unknown algorithms are reported
+			idx2.Indirect.Refs[0].Algo = "blockfmt-test-unknown"
+			_, err = idx2.Indirect.Search(dir, nil)
+			if err == nil {
+				t.Fatal("expected an error for an unknown algorithm")
+			}
+		})
+	}
+}
+
+func TestIndirectTreeResume(t *testing.T) {
+	dir := NewDirFS(t.TempDir())
+	dir.MinPartSize = 1
+	start := date.Now().Truncate(time.Microsecond)
+	// the second and third packfiles
+	// were both produced from input-1
+	inputs := []string{"input-0", "input-1", "input-1", "input-2", "input-3", "input-4"}
+	var lst []Ingested
+	for i := range inputs {
+		lst = append(lst, Ingested{
+			Descriptor: smallDescriptor(t, dir, start, i),
+			Input:      inputs[i],
+		})
+	}
+	var key Key
+	rand.Read(key[:])
+	basedir := path.Join("db", "foo", "bar")
+	idxpath := path.Join(basedir, "index")
+
+	// the checkpoint durably stores the index
+	// along with the last ingested input path
+	var last string
+	var appended []string
+	errCrash := errors.New("simulated crash")
+	crashAfter := 1
+	c := IndexConfig{
+		TargetRefSize: 1,
+		AppendBatch:   2,
+		Checkpoint: func(idx *Index, p string) error {
+			buf, err := Sign(&key, idx)
+			if err != nil {
+				return err
+			}
+			_, err = dir.WriteFile(idxpath, buf)
+			if err != nil {
+				return err
+			}
+			last = p
+			appended = append(appended, p)
+			if len(appended) == crashAfter {
+				return errCrash
+			}
+			return nil
+		},
+	}
+	idx := &Index{Name: "bar", Algo: "zstd"}
+	err := c.Append(idx, dir, basedir, lst, "")
+	if !errors.Is(err, errCrash) {
+		t.Fatalf("expected simulated crash; got %v", err)
+	}
+	// the first batch ends in the middle of
+	// the input-1 entries, so the first
+	// checkpoint happens after the second batch
+	if last != "input-2" {
+		t.Fatalf("checkpoint at %q; want %q", last, "input-2")
+	}
+
+	// resume from the stored index
+	buf, err := fs.ReadFile(dir, idxpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err = DecodeIndex(&key, buf, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appended = nil
+	crashAfter = -1
+	err = c.Append(idx, dir, basedir, lst, last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"input-4"}; !slices.Equal(appended, want) {
+		t.Errorf("resumed batches ended at %v; want %v", appended, want)
+	}
+	got, err := idx.Indirect.Search(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(lst) {
+		t.Fatalf("got %d descriptors; want %d", len(got), len(lst))
+	}
+	for i := range got {
+		if got[i].Path != lst[i].Path {
+			t.Errorf("descriptor %d is %s; want %s", i, got[i].Path, lst[i].Path)
+		}
+	}
+	if n := idx.Indirect.OrigObjects(); n != len(lst) {
+		t.Errorf("OrigObjects() = %d; want %d", n, len(lst))
+	}
+
+	// an unknown start is an error
+	err = c.Append(idx, dir, basedir, lst, "input-unknown")
+	if err == nil {
+		t.Fatal("expected an error for an unknown start")
+	}
+}
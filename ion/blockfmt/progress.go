@@ -0,0 +1,76 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package blockfmt
+
+import (
+	"sync"
+	"time"
+)
+
+// progressInterval is the minimum interval
+// between successive calls to a progress callback
+var progressInterval = 100 * time.Millisecond
+
+// progress tracks a count of items and bytes
+// and reports them to fn at most once every
+// progressInterval
+type progress struct {
+	lock     sync.Mutex
+	fn       func(int, int64)
+	n        int
+	bytes    int64
+	reported int
+	last     time.Time
+}
+
+func newProgress(fn func(int, int64)) *progress {
+	if fn == nil {
+		return nil
+	}
+	return &progress{fn: fn, last: time.Now()}
+}
+
+// add adds one item of the given size
+func (p *progress) add(size int64) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.n++
+	p.bytes += size
+	if now := time.Now(); now.Sub(p.last) >= progressInterval {
+		p.last = now
+		p.report()
+	}
+}
+
+// done reports the final counts
+// if they have not been reported already
+func (p *progress) done() {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.reported != p.n {
+		p.report()
+	}
+}
+
+func (p *progress) report() {
+	p.reported = p.n
+	p.fn(p.n, p.bytes)
+}
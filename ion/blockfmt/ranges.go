@@ -140,6 +140,13 @@ func union(a, b []TimeRange) []TimeRange {
 func (b *blockpart) merge(from *blockpart) {
 	b.chunks += from.chunks
 	b.ranges = union(b.ranges, from.ranges)
+	b.checksum = combineChecksums(b.checksum, from.checksum, from.size)
+	b.size += from.size
+	if b.rows < 0 || from.rows < 0 {
+		b.rows = -1
+	} else {
+		b.rows += from.rows
+	}
 }
 
 func collectRanges(t *Trailer) [][]string {
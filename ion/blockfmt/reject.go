@@ -0,0 +1,160 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package blockfmt
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// ErrorPolicy determines how many input records
+// that cannot be converted are tolerated by
+// Converter.Run. Rejected records are skipped.
+//
+// If both MaxErrors and MaxErrorRate are zero,
+// no records may be rejected.
+type ErrorPolicy struct {
+	// MaxErrors, if positive, is the maximum
+	// number of records that may be rejected.
+	MaxErrors int
+	// MaxErrorRate, if positive, is the maximum
+	// fraction (from 0 to 1) of all of the input
+	// records that may be rejected. It is checked
+	// once all of the inputs have been converted.
+	MaxErrorRate float64
+	// Reject, if non-nil, is called for
+	// each rejected record. Calls to Reject
+	// are never made concurrently.
+	Reject func(r *RejectedRecord)
+}
+
+// RejectedRecord describes a record
+// rejected under an ErrorPolicy.
+type RejectedRecord struct {
+	// Path is the path of the input.
+	Path string
+	// Index is the index of the record
+	// within the input (counting both the
+	// converted and the rejected records).
+	Index int
+	// Err is the conversion error.
+	Err error
+}
+
+// RejectError is returned from Converter.Run when
+// more records were rejected than permitted by
+// Converter.ErrorPolicy.
+type RejectError struct {
+	// Rejected is the number of rejected records.
+	Rejected int
+	// Records is the total number of records,
+	// including the rejected records.
+	Records int
+	// Err is the first conversion error.
+	Err error
+}
+
+func (r *RejectError) Error() string {
+	return fmt.Sprintf("%d of %d records rejected; first error: %s", r.Rejected, r.Records, r.Err)
+}
+
+func (r *RejectError) Unwrap() error { return r.Err }
+
+// rejectingFormat is implemented by
+// RowFormats that can skip malformed records
+type rejectingFormat interface {
+	// convertWithReject is like Convert, but it calls
+	// reject for each record that cannot be converted
+	// and skips it if reject returns nil. It returns
+	// the number of records that were converted.
+	convertWithReject(r io.Reader, dst *ion.Chunker, cons []ion.Field, reject func(int, error) error) (int, error)
+}
+
+// rejectTracker tracks the rejected
+// records for one call to Converter.Run
+type rejectTracker struct {
+	policy *ErrorPolicy
+
+	lock     sync.Mutex
+	records  int // converted records
+	rejected int
+	first    error
+}
+
+func (p *ErrorPolicy) tracker() *rejectTracker {
+	if p == nil {
+		return nil
+	}
+	return &rejectTracker{policy: p}
+}
+
+// convert converts one input; t may be nil
+func (t *rejectTracker) convert(in *Input, dst *ion.Chunker, cons []ion.Field) error {
+	rf, ok := in.F.(rejectingFormat)
+	if t == nil || !ok {
+		return in.F.Convert(in.R, dst, cons)
+	}
+	n, err := rf.convertWithReject(in.R, dst, cons, func(rec int, err error) error {
+		return t.reject(in.Path, rec, err)
+	})
+	t.lock.Lock()
+	t.records += n
+	t.lock.Unlock()
+	return err
+}
+
+func (t *rejectTracker) reject(path string, rec int, err error) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.rejected++
+	if t.first == nil {
+		t.first = fmt.Errorf("%s: record %d: %w", path, rec, err)
+	}
+	if t.policy.Reject != nil {
+		t.policy.Reject(&RejectedRecord{Path: path, Index: rec, Err: err})
+	}
+	p := t.policy
+	if (p.MaxErrors <= 0 && p.MaxErrorRate <= 0) || (p.MaxErrors > 0 && t.rejected > p.MaxErrors) {
+		return t.errorf()
+	}
+	return nil
+}
+
+func (t *rejectTracker) errorf() error {
+	return &RejectError{
+		Rejected: t.rejected,
+		Records:  t.records + t.rejected,
+		Err:      t.first,
+	}
+}
+
+// check returns a RejectError if the
+// error rate exceeds the policy
+func (t *rejectTracker) check() error {
+	if t == nil {
+		return nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	p := t.policy
+	if t.rejected > 0 && p.MaxErrorRate > 0 &&
+		float64(t.rejected) > p.MaxErrorRate*float64(t.records+t.rejected) {
+		return t.errorf()
+	}
+	return nil
+}
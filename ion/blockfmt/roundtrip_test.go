@@ -17,9 +17,12 @@ package blockfmt_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"hash/crc32"
 	"io"
 	"math/rand"
 	"os"
+	"slices"
 	"sync"
 	"testing"
 
@@ -298,6 +301,22 @@ func check(t *testing.T, buf []byte) []byte {
 		t.Helper()
 		t.Errorf("%d bytes decompressed instead of %d", n, len(out))
 	}
+	// each block checksum covers the compressed
+	// bytes of that block, and the checksum of all
+	// of the blocks covers everything up to the trailer
+	for i := range trailer.Blocks {
+		end := trailer.Offset
+		if i < len(trailer.Blocks)-1 {
+			end = trailer.Blocks[i+1].Offset
+		}
+		sum := crc32.Checksum(buf[trailer.Blocks[i].Offset:end], castagnoli)
+		if sum != trailer.Blocks[i].Checksum {
+			t.Helper()
+			t.Errorf("block %d: checksum %08x, want %08x", i, trailer.Blocks[i].Checksum, sum)
+		}
+	}
+	dec.Verify = true
+	dec.Checksum = crc32.Checksum(buf[:trailer.Offset], castagnoli)
 	var dst bytes.Buffer
 	r.Seek(0, io.SeekStart)
 	nn, err := dec.Copy(&dst, io.LimitReader(r, trailer.Offset))
@@ -327,3 +346,298 @@ func check(t *testing.T, buf []byte) []byte {
 	}
 	return out
 }
+
+// blobFrame wraps data in an ion blob
+// with a 5-byte header, like the frames
+// produced by CompressionWriter
+func blobFrame(data []byte) []byte {
+	size := len(data)
+	return append([]byte{
+		byte(ion.BlobType<<4) | 0xe,
+		byte(size>>21) & 0x7f,
+		byte(size>>14) & 0x7f,
+		byte(size>>7) & 0x7f,
+		byte(size&0x7f) | 0x80,
+	}, data...)
+}
+
+func TestDecoderMaxBlockBytes(t *testing.T) {
+	// a trailer claiming 1GiB blocks, followed by
+	// a (tiny) frame that would decompress into one
+	trailer := &blockfmt.Trailer{
+		Algo:       "zstd",
+		BlockShift: 30,
+		Blocks:     []blockfmt.Blockdesc{{Offset: 0, Chunks: 1}},
+	}
+	src := blobFrame(make([]byte, 64))
+
+	dec := blockfmt.Decoder{
+		MaxBlockBytes: 1 << 20,
+		Malloc: func(size int) []byte {
+			t.Fatalf("unexpected allocation of %d bytes", size)
+			return nil
+		},
+		Free: func([]byte) {},
+	}
+	dec.Set(trailer)
+
+	_, err := dec.CopyBytes(io.Discard, src)
+	if !errors.Is(err, blockfmt.ErrBlockTooLarge) {
+		t.Errorf("CopyBytes: got error %v", err)
+	}
+	_, err = dec.Copy(io.Discard, bytes.NewReader(src))
+	if !errors.Is(err, blockfmt.ErrBlockTooLarge) {
+		t.Errorf("Copy: got error %v", err)
+	}
+	_, err = dec.Decompress(bytes.NewReader(src), nil)
+	if !errors.Is(err, blockfmt.ErrBlockTooLarge) {
+		t.Errorf("Decompress: got error %v", err)
+	}
+
+	// a block within the limit is decoded as usual
+	block := bytes.Repeat([]byte{0x0f}, 1<<10)
+	comp, err := blockfmt.CompressorByName("zstd").Compress(block, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	dec = blockfmt.Decoder{MaxBlockBytes: 1 << 10}
+	dec.Set(&blockfmt.Trailer{Algo: "zstd", BlockShift: 10})
+	if _, err := dec.CopyBytes(&out, blobFrame(comp)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), block) {
+		t.Error("CopyBytes returned different data")
+	}
+}
+
+func TestDecoderFrameTooLarge(t *testing.T) {
+	// a trailer with 1KiB blocks, followed by
+	// a frame that decompresses into 4KiB
+	large := bytes.Repeat([]byte{0x0f}, 4<<10)
+	for _, algo := range []string{"zstd", "s2"} {
+		comp, err := blockfmt.CompressorByName(algo).Compress(large, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := blobFrame(comp)
+		dec := blockfmt.Decoder{MaxBlockBytes: 1 << 10}
+		dec.Set(&blockfmt.Trailer{
+			Algo:       algo,
+			BlockShift: 10,
+			Blocks:     []blockfmt.Blockdesc{{Offset: 0, Chunks: 1}},
+		})
+		_, err = dec.CopyBytes(io.Discard, src)
+		if !errors.Is(err, blockfmt.ErrBlockTooLarge) {
+			t.Errorf("%s: CopyBytes: got error %v", algo, err)
+		}
+		_, err = dec.Copy(io.Discard, bytes.NewReader(src))
+		if !errors.Is(err, blockfmt.ErrBlockTooLarge) {
+			t.Errorf("%s: Copy: got error %v", algo, err)
+		}
+		_, err = dec.Decompress(bytes.NewReader(src), make([]byte, 1<<10))
+		if !errors.Is(err, blockfmt.ErrBlockTooLarge) {
+			t.Errorf("%s: Decompress: got error %v", algo, err)
+		}
+	}
+}
+
+func TestDecoderVerify(t *testing.T) {
+	buf, _ := synthesize(t, "../../testdata/parking2.json", 4)
+	r := bytes.NewReader(buf)
+	trailer, err := blockfmt.ReadTrailer(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range trailer.Blocks {
+		if trailer.Blocks[i].Checksum == 0 {
+			t.Fatalf("block %d has no checksum", i)
+		}
+	}
+	start := trailer.Blocks[1].Offset
+	end := trailer.Offset
+	if len(trailer.Blocks) > 2 {
+		end = trailer.Blocks[2].Offset
+	}
+	block := buf[start:end]
+
+	dec := blockfmt.Decoder{Checksum: trailer.Blocks[1].Checksum}
+	dec.Set(trailer)
+	copyBlock := func(src []byte, verify bool) ([]byte, error) {
+		var out bytes.Buffer
+		dec.Verify = verify
+		_, err := dec.CopyBytes(&out, src)
+		return out.Bytes(), err
+	}
+	want, err := copyBlock(block, true)
+	if err != nil {
+		t.Fatalf("intact block: %v", err)
+	}
+	dec.Verify = true
+	if _, err := dec.Copy(io.Discard, bytes.NewReader(block)); err != nil {
+		t.Fatalf("intact block: %v", err)
+	}
+
+	// flip a byte in the compressed data, picking one
+	// where the corruption isn't noticed by the decompressor
+	// (CopyBytes skips the zstd frame checksums)
+	corrupt := slices.Clone(block)
+	found := false
+	for i := 64; i < len(corrupt); i++ {
+		corrupt[i] ^= 0x20
+		got, err := copyBlock(corrupt, false)
+		if err == nil && !bytes.Equal(got, want) {
+			found = true
+			break
+		}
+		corrupt[i] ^= 0x20
+	}
+	if !found {
+		t.Fatal("couldn't find an undetected corruption")
+	}
+	if _, err := copyBlock(corrupt, true); !errors.Is(err, blockfmt.ErrChecksum) {
+		t.Errorf("CopyBytes: got error %v", err)
+	}
+	dec.Verify = true
+	if _, err := dec.Copy(io.Discard, bytes.NewReader(corrupt)); err == nil {
+		t.Error("Copy: corrupt block not detected")
+	}
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+func TestDecoderVerifyOrder(t *testing.T) {
+	f, err := os.Open("../../testdata/parking2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	u, _, err := versify.FromJSON(json.NewDecoder(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out blockfmt.BufferUploader
+	out.PartSize = 1 << 12
+	w := blockfmt.CompressionWriter{
+		Output:            &out,
+		Comp:              blockfmt.CompressorByName("zstd"),
+		InputAlign:        1 << 12,
+		MinChunksPerBlock: 4,
+	}
+	cn := ion.Chunker{Align: w.InputAlign, W: &w}
+	src := rand.New(rand.NewSource(0))
+	for w.WrittenBlocks() < 2 {
+		u.Generate(src).Encode(&cn.Buffer, &cn.Symbols)
+		if err := cn.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cn.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	buf := out.Bytes()
+	trailer := &w.Trailer
+
+	// find a block with at least two frames
+	var block []byte
+	var first int
+	for i := range trailer.Blocks {
+		end := trailer.Offset
+		if i < len(trailer.Blocks)-1 {
+			end = trailer.Blocks[i+1].Offset
+		}
+		mem := buf[trailer.Blocks[i].Offset:end]
+		first = ion.SizeOf(mem)
+		if first > 0 && first < len(mem) {
+			block = mem
+			break
+		}
+	}
+	if block == nil {
+		t.Fatal("no block with more than one frame")
+	}
+	second := ion.SizeOf(block[first:])
+	if second <= 0 {
+		t.Fatal("bad second frame")
+	}
+	// swap the first two frames; each frame
+	// still decompresses on its own, so only
+	// the checksum can notice the difference
+	swapped := make([]byte, 0, len(block))
+	swapped = append(swapped, block[first:first+second]...)
+	swapped = append(swapped, block[:first]...)
+	swapped = append(swapped, block[first+second:]...)
+
+	dec := blockfmt.Decoder{Checksum: crc32.Checksum(block, castagnoli)}
+	dec.Set(trailer)
+	dec.Verify = true
+	if _, err := dec.CopyBytes(io.Discard, block); err != nil {
+		t.Fatalf("intact block: %v", err)
+	}
+	if _, err := dec.CopyBytes(io.Discard, swapped); !errors.Is(err, blockfmt.ErrChecksum) {
+		t.Errorf("CopyBytes: got error %v", err)
+	}
+	if _, err := dec.Copy(io.Discard, bytes.NewReader(swapped)); !errors.Is(err, blockfmt.ErrChecksum) {
+		t.Errorf("Copy: got error %v", err)
+	}
+}
+
+func TestTrailerRows(t *testing.T) {
+	buf, parts := synthesize(t, "../../testdata/parking2.json", 4)
+	r := bytes.NewReader(buf)
+	trailer, err := blockfmt.ReadTrailer(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := int64(0)
+	for i := range parts {
+		var st ion.Symtab
+		rest := parts[i]
+		for len(rest) > 0 {
+			var d ion.Datum
+			d, rest, err = ion.ReadDatum(&st, rest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Type() == ion.StructType {
+				want++
+			}
+		}
+	}
+	if len(trailer.Blocks) < 2 {
+		t.Fatalf("expected multiple blocks; got %d", len(trailer.Blocks))
+	}
+	got, ok := trailer.TotalRows()
+	if !ok {
+		t.Fatal("row counts not known")
+	}
+	if got != want {
+		t.Errorf("trailer has %d rows; want %d", got, want)
+	}
+	sum := int64(0)
+	for i := range trailer.Blocks {
+		n, ok := trailer.Rows(i, i+1)
+		if !ok {
+			t.Fatalf("block %d: row count not known", i)
+		}
+		sum += n
+	}
+	if sum != got {
+		t.Errorf("per-block rows sum to %d; TotalRows returned %d", sum, got)
+	}
+
+	// the decompressed size should match
+	// the size of a full scan of the data
+	var dec blockfmt.Decoder
+	dec.Set(trailer)
+	n, err := dec.Copy(io.Discard, io.NewSectionReader(r, 0, trailer.Offset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != trailer.Decompressed() {
+		t.Errorf("decompressed %d bytes; trailer.Decompressed() = %d", n, trailer.Decompressed())
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package blockfmt
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// Reservoir is a fixed-size uniform random
+// sample of a stream of records.
+//
+// A Reservoir can be assigned to Converter.Sample
+// to sample the records being converted.
+// The sample depends only on the seed and the
+// order in which records are added, so it is
+// reproducible when the records arrive in the same
+// order. When a Converter runs in parallel, each
+// worker samples the inputs it converts on its own
+// and the samples are merged when Run returns,
+// so the sample is only reproducible when
+// Converter.Parallel is 1.
+type Reservoir struct {
+	lock   sync.Mutex
+	size   int
+	rand   *rand.Rand
+	seen   int
+	sample []ion.Datum
+}
+
+// NewReservoir constructs a Reservoir that
+// holds up to size records, using seed to
+// seed its random number generator.
+func NewReservoir(size int, seed int64) *Reservoir {
+	return &Reservoir{
+		size: size,
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Add adds the record rec, encoded using st,
+// to the stream. Add may be called concurrently.
+func (r *Reservoir) Add(st *ion.Symtab, rec []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.seen++
+	j := len(r.sample)
+	if j >= r.size {
+		j = r.rand.Intn(r.seen)
+		if j >= r.size {
+			return
+		}
+	}
+	d, _, err := ion.ReadDatum(st, rec)
+	if err != nil {
+		return
+	}
+	d = d.Clone()
+	if j == len(r.sample) {
+		r.sample = append(r.sample, d)
+	} else {
+		r.sample[j] = d
+	}
+}
+
+// Seen returns the number of records
+// passed to Add.
+func (r *Reservoir) Seen() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.seen
+}
+
+// Sample returns the current sample,
+// which contains min(Seen(), size) records.
+func (r *Reservoir) Sample() []ion.Datum {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return slices.Clone(r.sample)
+}
+
+// fork returns an empty Reservoir with the same
+// size as r and a seed drawn from r, so that a
+// fork can be populated without synchronizing
+// with r and then passed to r.merge
+func (r *Reservoir) fork() *Reservoir {
+	if r == nil {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return NewReservoir(r.size, r.rand.Int63())
+}
+
+// merge merges the sample in src into r,
+// so that r holds a uniform sample of the
+// records added to either reservoir
+func (r *Reservoir) merge(src *Reservoir) {
+	if r == nil || src == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	src.lock.Lock()
+	defer src.lock.Unlock()
+	// pick each record of the merged sample from
+	// one of the two samples with a probability
+	// proportional to the number of records that
+	// sample stands for and have not been picked yet
+	a, b := r.sample, src.sample
+	na, nb := r.seen, src.seen
+	out := make([]ion.Datum, 0, min(r.size, len(a)+len(b)))
+	pick := func(lst []ion.Datum) ([]ion.Datum, ion.Datum) {
+		j := r.rand.Intn(len(lst))
+		d := lst[j]
+		lst[j] = lst[len(lst)-1]
+		return lst[:len(lst)-1], d
+	}
+	for len(out) < cap(out) {
+		var d ion.Datum
+		if r.rand.Intn(na+nb) < na {
+			a, d = pick(a)
+			na--
+		} else {
+			b, d = pick(b)
+			nb--
+		}
+		out = append(out, d)
+	}
+	r.seen += src.seen
+	r.sample = out
+}
+
+// hook returns the ion.Chunker.OnCommit
+// hook for r, or nil if r is nil
+func (r *Reservoir) hook() func(*ion.Symtab, []byte) {
+	if r == nil {
+		return nil
+	}
+	return r.Add
+}
+
+// DataShape returns the 'fields' output of
+// SNELLER_DATASHAPE (without the value ranges)
+// computed over the current sample.
+// The result is suitable for passing to the
+// elastic proxy's DataShapeToElasticMapping.
+func (r *Reservoir) DataShape() map[string]any {
+	var acc ion.DatashapeAccumulator
+	for _, d := range r.Sample() {
+		acc.Add(d)
+	}
+	return acc.Shape()
+}
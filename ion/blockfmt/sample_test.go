@@ -0,0 +1,137 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package blockfmt
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReservoir(t *testing.T) {
+	var text strings.Builder
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&text, "{\"n\": %d, \"tags\": [\"x\"], \"inner\": {\"ok\": true}}\n", i)
+	}
+	sample := func(size int, seed int64) (*Reservoir, []int64) {
+		r := NewReservoir(size, seed)
+		var out BufferUploader
+		out.PartSize = 4096
+		c := Converter{
+			Output: &out,
+			Comp:   "zstd",
+			Inputs: []Input{{
+				R: io.NopCloser(strings.NewReader(text.String())),
+				F: MustSuffixToFormat(".json"),
+			}},
+			Align:  4096,
+			Sample: r,
+		}
+		if err := c.Run(); err != nil {
+			t.Fatal(err)
+		}
+		check(t, &out)
+		var n []int64
+		for _, d := range r.Sample() {
+			v, err := d.Field("n").Int()
+			if err != nil {
+				t.Fatal(err)
+			}
+			n = append(n, v)
+		}
+		return r, n
+	}
+
+	r, first := sample(20, 42)
+	if r.Seen() != 1000 {
+		t.Errorf("saw %d records, not 1000", r.Seen())
+	}
+	if len(first) != 20 {
+		t.Fatalf("sample has %d records, not 20", len(first))
+	}
+	_, again := sample(20, 42)
+	if !reflect.DeepEqual(first, again) {
+		t.Errorf("sample %v != %v with the same seed", first, again)
+	}
+	_, other := sample(20, 43)
+	if reflect.DeepEqual(first, other) {
+		t.Errorf("sample %v is the same with a different seed", first)
+	}
+	_, all := sample(2000, 42)
+	if len(all) != 1000 {
+		t.Errorf("sample has %d records, not 1000", len(all))
+	}
+
+	want := map[string]any{
+		"n":           map[string]any{"int": 20},
+		"tags":        map[string]any{"list": 20},
+		"tags.$items": map[string]any{"string": 20},
+		"inner":       map[string]any{"struct": 20},
+		"inner.ok":    map[string]any{"bool": 20},
+	}
+	if got := r.DataShape(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got data shape %v", got)
+	}
+}
+
+func TestReservoirParallel(t *testing.T) {
+	var inputs []Input
+	for i := 0; i < 4; i++ {
+		var text strings.Builder
+		for j := 0; j < 500; j++ {
+			fmt.Fprintf(&text, "{\"input\": %d, \"n\": %d}\n", i, j)
+		}
+		inputs = append(inputs, Input{
+			R: io.NopCloser(strings.NewReader(text.String())),
+			F: MustSuffixToFormat(".json"),
+		})
+	}
+	r := NewReservoir(100, 42)
+	var out BufferUploader
+	out.PartSize = 4096
+	c := Converter{
+		Output:   &out,
+		Comp:     "zstd",
+		Inputs:   inputs,
+		Align:    4096,
+		Parallel: 4,
+		Sample:   r,
+	}
+	if err := c.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Seen() != 2000 {
+		t.Errorf("saw %d records, not 2000", r.Seen())
+	}
+	sample := r.Sample()
+	if len(sample) != 100 {
+		t.Fatalf("sample has %d records, not 100", len(sample))
+	}
+	// every input should be represented
+	// (the chance that one isn't is ~1e-12)
+	counts := make(map[int64]int)
+	for _, d := range sample {
+		v, err := d.Field("input").Int()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[v]++
+	}
+	if len(counts) != 4 {
+		t.Errorf("unexpected sample distribution %v", counts)
+	}
+}
@@ -15,6 +15,7 @@
 package blockfmt
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 	"sort"
@@ -24,6 +25,11 @@ import (
 	"github.com/SnellerInc/sneller/ion"
 )
 
+// ErrBadSparse is returned when a sparse
+// index is malformed or inconsistent with
+// the data it describes.
+var ErrBadSparse = errors.New("bad sparse index")
+
 type timeIndex struct {
 	path   []string
 	ranges TimeIndex
@@ -135,13 +141,46 @@ func (s *SparseIndex) AppendBlocks(next *SparseIndex, i, j int) bool {
 	if !slices.EqualFunc(s.indices, next.indices, eq) {
 		return false
 	}
-	for i := range s.indices {
-		s.indices[i].ranges.appendBlocks(&next.indices[i].ranges, i, j)
+	for k := range s.indices {
+		s.indices[k].ranges.appendBlocks(&next.indices[k].ranges, i, j)
 	}
 	s.blocks += j - i
 	return true
 }
 
+// Merge appends the blocks indexed by other after
+// the blocks indexed by s, as when concatenating the
+// data described by s with the data described by other.
+//
+// Both indexes must track exactly the same set of fields
+// and constants; a field that is indexed on only one side
+// cannot be given meaningful ranges for the blocks on the
+// other side, so Merge returns an error wrapping
+// ErrBadSparse rather than attempting a union.
+// As a special case, merging into an index with no blocks
+// produces a copy of other, and merging an index with
+// no blocks is a no-op. On error, s is left unmodified.
+func (s *SparseIndex) Merge(other *SparseIndex) error {
+	if other.blocks == 0 {
+		return nil
+	}
+	if s.blocks == 0 {
+		*s = other.Clone()
+		return nil
+	}
+	if !s.consts.Equal(other.consts) {
+		return fmt.Errorf("SparseIndex.Merge: %w: constants differ", ErrBadSparse)
+	}
+	if !slices.Equal(s.FieldNames(), other.FieldNames()) {
+		return fmt.Errorf("SparseIndex.Merge: %w: fields %v and %v differ",
+			ErrBadSparse, s.FieldNames(), other.FieldNames())
+	}
+	if !s.Append(other) {
+		return fmt.Errorf("SparseIndex.Merge: %w: append failed", ErrBadSparse)
+	}
+	return nil
+}
+
 // Fields returns the number of individually
 // indexed fields.
 func (s *SparseIndex) Fields() int { return len(s.indices) }
@@ -197,7 +236,7 @@ func (d *TrailerDecoder) decodeSparse(s *SparseIndex, v ion.Datum) error {
 			s.blocks = int(n)
 		case "consts":
 			if !f.IsStruct() {
-				return fmt.Errorf("expected consts to be a struct")
+				return fmt.Errorf("%w: expected consts to be a struct", ErrBadSparse)
 			}
 			// XXX: we have to copy the bytes because
 			// the resulting ion.Struct will alias the
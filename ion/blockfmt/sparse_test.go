@@ -15,6 +15,7 @@
 package blockfmt
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -122,3 +123,78 @@ func TestSparseCorruption(t *testing.T) {
 		t.Fatal("consts was corrupted")
 	}
 }
+
+func TestSparseMerge(t *testing.T) {
+	start := date.Now().Truncate(time.Microsecond)
+	hour := func(i int) date.Time {
+		return start.Add(time.Duration(i) * time.Hour)
+	}
+	// build an index over the given fields
+	// with one block per hour in [lo, hi)
+	build := func(fields []string, lo, hi int) SparseIndex {
+		var si SparseIndex
+		for i := lo; i < hi; i++ {
+			for _, f := range fields {
+				si.push([]string{f}, hour(i), hour(i+1).Add(-time.Microsecond))
+			}
+			si.bump()
+		}
+		return si
+	}
+
+	a := build([]string{"x", "y"}, 0, 3)
+	b := build([]string{"x", "y"}, 3, 5)
+	err := a.Merge(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Blocks() != 5 {
+		t.Fatalf("%d blocks after merge", a.Blocks())
+	}
+	want := build([]string{"x", "y"}, 0, 5)
+	for _, f := range []string{"x", "y"} {
+		got, exp := a.Get([]string{f}), want.Get([]string{f})
+		for i := 0; i < 5; i++ {
+			when := hour(i).Add(time.Minute)
+			if got.Start(when) != exp.Start(when) || got.End(when) != exp.End(when) {
+				t.Errorf("%s: hour %d: [%d, %d) != [%d, %d)", f, i,
+					got.Start(when), got.End(when), exp.Start(when), exp.End(when))
+			}
+		}
+	}
+	testSparseRoundtrip(t, &a)
+	// b is not modified
+	if b.Blocks() != 2 {
+		t.Errorf("b has %d blocks", b.Blocks())
+	}
+
+	// disjoint field sets are rejected
+	// and leave the destination alone
+	c := build([]string{"x", "y"}, 0, 3)
+	d := build([]string{"x", "z"}, 3, 5)
+	orig := c.Clone()
+	err = c.Merge(&d)
+	if !errors.Is(err, ErrBadSparse) {
+		t.Fatalf("expected ErrBadSparse; got %v", err)
+	}
+	if !reflect.DeepEqual(&c, &orig) {
+		t.Fatal("failed Merge modified the index")
+	}
+	e := build([]string{"x"}, 3, 5)
+	if err := c.Merge(&e); !errors.Is(err, ErrBadSparse) {
+		t.Fatalf("expected ErrBadSparse for a subset; got %v", err)
+	}
+
+	// merging with an empty index on
+	// either side succeeds regardless of fields
+	var empty SparseIndex
+	if err := c.Merge(&empty); err != nil || c.Blocks() != 3 {
+		t.Fatalf("merging empty index: %v, %d blocks", err, c.Blocks())
+	}
+	if err := empty.Merge(&d); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&empty, &d) {
+		t.Fatal("merge into empty index is not a copy")
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package blockfmt
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/SnellerInc/sneller/date"
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// Tombstone records a predicate that matches
+// rows which have been deleted from a table.
+//
+// Since packfiles are immutable, deleted rows
+// remain in the packfiles referenced by the index
+// and are excluded from query results by the
+// query planner (see Index.Deleted). The rows are
+// physically removed by Index.Compact when
+// CompactionPolicy.Delete is set, which also drops
+// the tombstones. Tombstones that no longer match
+// any rows because the data has been rewritten
+// otherwise (e.g. with SELECT ... INTO) can be
+// dropped with Index.PruneTombstones.
+type Tombstone struct {
+	// Pred matches the deleted rows.
+	Pred expr.Node
+	// Created is the time at which
+	// the tombstone was created.
+	Created date.Time
+}
+
+// AddTombstone adds a tombstone for the rows matching pred.
+func (idx *Index) AddTombstone(pred expr.Node) {
+	idx.Tombstones = append(idx.Tombstones, Tombstone{
+		Pred:    pred,
+		Created: date.Now().Truncate(time.Microsecond),
+	})
+}
+
+// Deleted returns a predicate that matches all
+// of the rows deleted by idx.Tombstones, or nil
+// if there are no tombstones. The returned
+// expression does not alias idx.Tombstones.
+func (idx *Index) Deleted() expr.Node {
+	var ret expr.Node
+	for i := range idx.Tombstones {
+		p := expr.Copy(idx.Tombstones[i].Pred)
+		if ret == nil {
+			ret = p
+		} else {
+			ret = expr.Or(ret, p)
+		}
+	}
+	return ret
+}
+
+// PruneTombstones removes the tombstones that can
+// no longer match any rows according to the sparse
+// indexes of the objects in idx and returns the
+// number of tombstones that were removed.
+func (idx *Index) PruneTombstones(src InputFS) (int, error) {
+	kept := idx.Tombstones[:0]
+	for i := range idx.Tombstones {
+		var f Filter
+		f.Compile(idx.Tombstones[i].Pred)
+		descs, _, _, err := idx.Descs(src, &f)
+		if err != nil {
+			return 0, err
+		}
+		if len(descs) > 0 {
+			kept = append(kept, idx.Tombstones[i])
+		}
+	}
+	n := len(idx.Tombstones) - len(kept)
+	clear(idx.Tombstones[len(kept):])
+	idx.Tombstones = kept
+	return n, nil
+}
+
+// DeleteFunc removes deleted rows from a stream
+// of ion records. It is called with the decompressed
+// contents of an object in src and must write the
+// records of src for which pred is not TRUE to dst.
+// The vm package provides an implementation that
+// can evaluate any predicate (see vm.DeleteRows).
+type DeleteFunc func(dst io.Writer, src io.Reader, pred expr.Node) error
+
+// purge rewrites the objects in lst that may contain
+// rows matching pred (according to their sparse indexes)
+// without those rows and returns the new list of objects
+// along with the objects that were replaced
+func (p *CompactionPolicy) purge(ifs UploadFS, lst []Descriptor, pred expr.Node) ([]Descriptor, []Quarantined, error) {
+	var f Filter
+	f.Compile(pred)
+	expiry := date.Now().Add(p.Expiry).Truncate(time.Microsecond)
+	var out []Descriptor
+	var old []Quarantined
+	for i := range lst {
+		if !f.MatchesAny(&lst[i].Trailer.Sparse) {
+			out = append(out, lst[i])
+			continue
+		}
+		d, err := p.deleteRows(ifs, &lst[i], pred)
+		if err != nil {
+			return nil, nil, err
+		}
+		old = append(old, Quarantined{Path: lst[i].Path, Expiry: expiry})
+		if len(d.Trailer.Blocks) == 0 {
+			// every row was deleted
+			old = append(old, Quarantined{Path: d.Path, Expiry: expiry})
+			continue
+		}
+		out = append(out, d)
+	}
+	return out, old, nil
+}
+
+// deleteRows writes a copy of the object d without
+// the rows matching pred to a new packfile in the
+// same directory and returns its descriptor
+func (p *CompactionPolicy) deleteRows(ifs UploadFS, d *Descriptor, pred expr.Node) (Descriptor, error) {
+	f, err := ifs.Open(d.Path)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return Descriptor{}, err
+	}
+	etag, err := ifs.ETag(d.Path, info)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	if etag != d.ETag {
+		return Descriptor{}, fmt.Errorf("blockfmt: deleting rows from %s: %w: %s -> %s", d.Path, ErrETagChanged, d.ETag, etag)
+	}
+	name := path.Join(path.Dir(d.Path), "packed-"+uuid()+suffixForComp(d.Trailer.Algo))
+	up, err := ifs.Create(name)
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	// decompress the object into raw and
+	// filter it into kept, which is converted
+	// into the new object
+	raw, rawout := io.Pipe()
+	kept, keptout := io.Pipe()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		var dec Decoder
+		dec.Set(&d.Trailer)
+		_, err := dec.Copy(rawout, io.LimitReader(f, d.Trailer.Offset))
+		rawout.CloseWithError(err)
+	}()
+	go func() {
+		defer wg.Done()
+		err := p.Delete(keptout, raw, pred)
+		if err == nil {
+			err = io.EOF
+		}
+		raw.CloseWithError(err)
+		keptout.CloseWithError(err)
+	}()
+	align := 1 << d.Trailer.BlockShift
+	chunks := 1
+	if len(d.Trailer.Blocks) > 0 {
+		chunks = max(chunks, d.Trailer.Blocks[0].Chunks)
+	}
+	c := Converter{
+		Output:    up,
+		Comp:      d.Trailer.Algo,
+		Inputs:    []Input{{Path: d.Path, ETag: d.ETag, R: kept, F: UnsafeION()}},
+		Align:     align,
+		FlushMeta: align * chunks,
+		Parallel:  1,
+		Constants: d.Trailer.Sparse.consts.Fields(nil),
+	}
+	err = c.Run()
+	kept.Close()
+	wg.Wait()
+	if err != nil {
+		return Descriptor{}, err
+	}
+	etag, err = ETag(ifs, up, name)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	return Descriptor{
+		ObjectInfo: ObjectInfo{
+			Path:   name,
+			ETag:   etag,
+			Format: Version,
+			Size:   up.Size(),
+		},
+		Trailer: *c.Trailer(),
+	}, nil
+}
+
+func writeTombstones(buf *ion.Buffer, st *ion.Symtab, lst []Tombstone) {
+	pred := st.Intern("pred")
+	created := st.Intern("created")
+	buf.BeginList(-1)
+	for i := range lst {
+		buf.BeginStruct(-1)
+		buf.BeginField(pred)
+		lst[i].Pred.Encode(buf, st)
+		buf.BeginField(created)
+		buf.WriteTime(lst[i].Created)
+		buf.EndStruct()
+	}
+	buf.EndList()
+}
+
+func readTombstones(d ion.Datum) ([]Tombstone, error) {
+	var lst []Tombstone
+	err := d.UnpackList(func(d ion.Datum) error {
+		var t Tombstone
+		err := d.UnpackStruct(func(f ion.Field) error {
+			var err error
+			switch f.Label {
+			case "pred":
+				t.Pred, err = expr.Decode(f.Datum)
+			case "created":
+				t.Created, err = f.Timestamp()
+			default:
+				// ignore
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if t.Pred == nil {
+			return fmt.Errorf("tombstone missing predicate")
+		}
+		lst = append(lst, t)
+		return nil
+	})
+	return lst, err
+}
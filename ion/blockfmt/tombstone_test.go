@@ -0,0 +1,244 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package blockfmt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SnellerInc/sneller/date"
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+func TestTombstones(t *testing.T) {
+	dir := NewDirFS(t.TempDir())
+	dir.MinPartSize = 1
+	start := date.Now().Truncate(time.Microsecond)
+	idx := smallIndirect(t, dir, start)
+	if idx.Deleted() != nil {
+		t.Fatal("unexpected deletion predicate")
+	}
+
+	// the data covers hours [0, 4) after start,
+	// so the first tombstone matches nothing
+	before := expr.Compare(expr.Less, expr.Ident("timestamp"), &expr.Timestamp{Value: start})
+	after := expr.Compare(expr.GreaterEquals, expr.Ident("timestamp"), &expr.Timestamp{Value: start.Add(time.Hour)})
+	idx.AddTombstone(before)
+	idx.AddTombstone(after)
+	want := expr.Or(before, after)
+	if got := idx.Deleted(); !expr.Equal(got, want) {
+		t.Fatalf("Deleted() = %s, want %s", expr.ToString(got), expr.ToString(want))
+	}
+
+	var key Key
+	rand.Read(key[:])
+	buf, err := Sign(&key, idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret, err := DecodeIndex(&key, buf, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ret.Tombstones) != 2 {
+		t.Fatalf("got %d tombstones back", len(ret.Tombstones))
+	}
+	for i := range ret.Tombstones {
+		if !expr.Equal(ret.Tombstones[i].Pred, idx.Tombstones[i].Pred) {
+			t.Errorf("tombstone %d: got %s", i, expr.ToString(ret.Tombstones[i].Pred))
+		}
+		if !ret.Tombstones[i].Created.Equal(idx.Tombstones[i].Created) {
+			t.Errorf("tombstone %d: created %s != %s", i, ret.Tombstones[i].Created, idx.Tombstones[i].Created)
+		}
+	}
+
+	n, err := ret.PruneTombstones(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || len(ret.Tombstones) != 1 || !expr.Equal(ret.Tombstones[0].Pred, after) {
+		t.Fatalf("pruned %d; left %d", n, len(ret.Tombstones))
+	}
+}
+
+// deleteAfter is a DeleteFunc that ignores the
+// predicate and deletes the rows with a timestamp
+// at or after t
+func deleteAfter(t date.Time) DeleteFunc {
+	return func(dst io.Writer, src io.Reader, _ expr.Node) error {
+		body, err := io.ReadAll(src)
+		if err != nil {
+			return err
+		}
+		var st ion.Symtab
+		var out ion.Buffer
+		for len(body) > 0 {
+			if ion.TypeOf(body) == ion.NullType && ion.SizeOf(body) > 1 {
+				body = body[ion.SizeOf(body):]
+				continue
+			}
+			var d ion.Datum
+			d, body, err = ion.ReadDatum(&st, body)
+			if err != nil {
+				return err
+			}
+			if d.IsEmpty() {
+				continue
+			}
+			ts, err := d.Field("timestamp").Timestamp()
+			if err != nil {
+				return err
+			}
+			if ts.Before(t) {
+				d.Encode(&out, &st)
+			}
+		}
+		var hdr ion.Buffer
+		st.Marshal(&hdr, true)
+		_, err = dst.Write(append(hdr.Bytes(), out.Bytes()...))
+		return err
+	}
+}
+
+func TestCompactTombstones(t *testing.T) {
+	dfs := NewDirFS(t.TempDir())
+	dfs.MinPartSize = 1
+	start := date.Date(2023, 1, 1, 0, 0, 0, 0)
+	// packfile i holds 100 rows from hour i after start
+	pack := func(i int) Descriptor {
+		var text strings.Builder
+		for j := 0; j < 100; j++ {
+			ts := start.Add(time.Duration(i)*time.Hour + time.Duration(j)*time.Second)
+			fmt.Fprintf(&text, "{\"timestamp\": %q, \"n\": %d}\n", ts.Time().Format(time.RFC3339), j)
+		}
+		p := fmt.Sprintf("db/foo/bar/packed-%d", i)
+		up, err := dfs.Create(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := Converter{
+			Output:    up,
+			Comp:      "zstd",
+			Inputs:    []Input{{R: io.NopCloser(strings.NewReader(text.String())), F: MustSuffixToFormat(".json")}},
+			Align:     4096,
+			FlushMeta: 4096,
+		}
+		if err := c.Run(); err != nil {
+			t.Fatal(err)
+		}
+		etag, err := ETag(dfs, c.Output, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Descriptor{
+			ObjectInfo: ObjectInfo{Path: p, ETag: etag, Format: Version, Size: c.Output.Size()},
+			Trailer:    *c.Trailer(),
+		}
+	}
+	var descs []Ingested
+	for i := 0; i < 4; i++ {
+		descs = append(descs, Ingested{Descriptor: pack(i)})
+	}
+	c := IndexConfig{
+		TargetRefSize: 1,
+		AppendBatch:   2,
+	}
+	idx := &Index{Name: "bar", Algo: "zstd"}
+	err := c.Append(idx, dfs, "db/foo/bar", descs, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx.Inline = append(idx.Inline, pack(4))
+
+	rows := func() int {
+		lst, err := idx.Indirect.Search(dfs, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lst = append(lst, idx.Inline...)
+		n := 0
+		for i := range lst {
+			f, err := dfs.Open(lst[i].Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var errlog bytes.Buffer
+			n += Validate(f, &lst[i].Trailer, &errlog)
+			f.Close()
+			if errlog.Len() > 0 {
+				t.Fatal(errlog.String())
+			}
+		}
+		return n
+	}
+	if n := rows(); n != 500 {
+		t.Fatalf("got %d rows before compaction", n)
+	}
+
+	// delete the second half of hour 3 and all of hour 4
+	cutoff := start.Add(3*time.Hour + 50*time.Second)
+	pred := expr.Compare(expr.GreaterEquals, expr.Ident("timestamp"), &expr.Timestamp{Value: cutoff})
+	idx.AddTombstone(pred)
+
+	// without a DeleteFunc, the tombstone is kept
+	policy := CompactionPolicy{TargetSize: 1}
+	q, err := idx.Compact(dfs, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q) != 0 || len(idx.Tombstones) != 1 {
+		t.Fatalf("quarantined %d objects; %d tombstones left", len(q), len(idx.Tombstones))
+	}
+
+	policy.Delete = deleteAfter(cutoff)
+	q, err = idx.Compact(dfs, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Tombstones) != 0 {
+		t.Errorf("%d tombstones left", len(idx.Tombstones))
+	}
+	// packfile 3 and the ref holding it are replaced;
+	// packfile 4 is removed along with its (empty) rewrite
+	var paths []string
+	for i := range q {
+		paths = append(paths, q[i].Path)
+	}
+	if len(q) != 4 || paths[0] != "db/foo/bar/packed-3" || paths[2] != "db/foo/bar/packed-4" {
+		t.Errorf("quarantined %v", paths)
+	}
+	if len(idx.Inline) != 0 {
+		t.Errorf("%d inline objects left", len(idx.Inline))
+	}
+	if n := rows(); n != 350 {
+		t.Errorf("got %d rows after compaction", n)
+	}
+
+	// an object that changed underneath
+	// the index is not rewritten
+	d := pack(5)
+	d.ETag = "tampered"
+	_, err = policy.deleteRows(dfs, &d, pred)
+	if !errors.Is(err, ErrETagChanged) {
+		t.Errorf("expected ErrETagChanged; got %v", err)
+	}
+}
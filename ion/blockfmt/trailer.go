@@ -29,6 +29,7 @@ package blockfmt
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/SnellerInc/sneller/date"
 	"github.com/SnellerInc/sneller/ion"
@@ -46,13 +47,29 @@ type Blockdesc struct {
 	// 1 << Trailer.BlockShift) within
 	// this block
 	Chunks int
+	// Checksum is the CRC-32C checksum of
+	// the compressed data of this block (every
+	// frame, including its header), or zero if
+	// the checksum is not known.
+	Checksum uint32
+	// Rows is the number of rows within
+	// this block, or zero if the number
+	// of rows is not known.
+	Rows int64
 }
 
 // Trailer is a collection
 // of block descriptions.
 type Trailer struct {
 	// Version is an indicator
-	// of the encoded trailer format version
+	// of the encoded trailer format version.
+	//
+	// Version 2 trailers may contain per-block
+	// checksums and row counts. Readers of version 1
+	// trailers reject fields they do not recognize,
+	// but they skip over unknown fields in trailers
+	// with a later version, so those fields are only
+	// written in version 2 trailers. (See Trailer.Encode.)
 	Version int
 	// Offset is the offset of the trailer
 	// within the output stream.
@@ -75,15 +92,29 @@ type Trailer struct {
 	Sparse SparseIndex
 }
 
+// minVersion returns the lowest trailer
+// version that can encode the contents of t
+func (t *Trailer) minVersion() int {
+	if t.hasRows() || slices.ContainsFunc(t.Blocks, func(b Blockdesc) bool { return b.Checksum != 0 }) {
+		return 2
+	}
+	return 1
+}
+
+// hasRows returns whether the row counts are encoded,
+// which is only the case if every block has a known row count
+func (t *Trailer) hasRows() bool {
+	return len(t.Blocks) > 0 && !slices.ContainsFunc(t.Blocks, func(b Blockdesc) bool { return b.Rows <= 0 })
+}
+
 // Encode encodes a trailer to the provided buffer
 // using the provided symbol table.
 // Note that Encode may add new symbols to the symbol table.
 func (t *Trailer) Encode(dst *ion.Buffer, st *ion.Symtab) {
 	dst.BeginStruct(-1)
 
-	// we're encoding trailer version 1
 	dst.BeginField(st.Intern("version"))
-	dst.WriteInt(int64(1))
+	dst.WriteInt(int64(max(t.Version, t.minVersion())))
 
 	dst.BeginField(st.Intern("offset"))
 	dst.WriteInt(t.Offset)
@@ -123,6 +154,25 @@ func (t *Trailer) Encode(dst *ion.Buffer, st *ion.Symtab) {
 	}
 	dst.EndList()
 
+	// checksums are only encoded if
+	// they are present at all
+	if slices.ContainsFunc(t.Blocks, func(b Blockdesc) bool { return b.Checksum != 0 }) {
+		dst.BeginField(st.Intern("checksums"))
+		dst.BeginList(-1)
+		for i := range t.Blocks {
+			dst.WriteUint(uint64(t.Blocks[i].Checksum))
+		}
+		dst.EndList()
+	}
+	if t.hasRows() {
+		dst.BeginField(st.Intern("rows"))
+		dst.BeginList(-1)
+		for i := range t.Blocks {
+			dst.WriteInt(t.Blocks[i].Rows)
+		}
+		dst.EndList()
+	}
+
 	dst.EndStruct()
 }
 
@@ -289,6 +339,7 @@ func (d *TrailerDecoder) unpackRanges(v ion.Datum) ([]Range, error) {
 // Decode decodes a trailer.
 func (d *TrailerDecoder) Decode(v ion.Datum, dst *Trailer) error {
 	seenSparse := false
+	var checksums, rows ion.Datum
 	err := v.UnpackStruct(func(f ion.Field) error {
 		switch f.Label {
 		case "version":
@@ -327,6 +378,12 @@ func (d *TrailerDecoder) Decode(v ion.Datum, dst *Trailer) error {
 		case "sparse":
 			seenSparse = true
 			return d.decodeSparse(&dst.Sparse, f.Datum)
+		case "checksums":
+			// applied once the block list is known
+			checksums = f.Datum
+		case "rows":
+			// applied once the block list is known
+			rows = f.Datum
 		case "blocks-delta":
 			// smaller delta-encoded block list format
 			n, err := countList(f.Datum)
@@ -399,12 +456,58 @@ func (d *TrailerDecoder) Decode(v ion.Datum, dst *Trailer) error {
 		}
 		return nil
 	})
+	if err == nil && !checksums.IsEmpty() {
+		err = dst.unpackChecksums(checksums)
+	}
+	if err == nil && !rows.IsEmpty() {
+		err = dst.unpackRows(rows)
+	}
 	if err != nil {
 		return fmt.Errorf("Trailer.Decode: %w", err)
 	}
 	return nil
 }
 
+func (t *Trailer) unpackChecksums(d ion.Datum) error {
+	i := 0
+	err := d.UnpackList(func(v ion.Datum) error {
+		if i >= len(t.Blocks) {
+			return fmt.Errorf("more checksums than blocks (%d)", len(t.Blocks))
+		}
+		sum, err := v.Uint()
+		if err != nil {
+			return err
+		}
+		t.Blocks[i].Checksum = uint32(sum)
+		i++
+		return nil
+	})
+	if err == nil && i != len(t.Blocks) {
+		err = fmt.Errorf("%d checksums for %d blocks", i, len(t.Blocks))
+	}
+	return err
+}
+
+func (t *Trailer) unpackRows(d ion.Datum) error {
+	i := 0
+	err := d.UnpackList(func(v ion.Datum) error {
+		if i >= len(t.Blocks) {
+			return fmt.Errorf("more row counts than blocks (%d)", len(t.Blocks))
+		}
+		rows, err := v.Int()
+		if err != nil {
+			return err
+		}
+		t.Blocks[i].Rows = rows
+		i++
+		return nil
+	})
+	if err == nil && i != len(t.Blocks) {
+		err = fmt.Errorf("%d row counts for %d blocks", i, len(t.Blocks))
+	}
+	return err
+}
+
 func (t *Trailer) unpackBlocks(body []byte) error {
 	body, _ = ion.Contents(body)
 	var v int64
@@ -437,7 +540,7 @@ func (t *Trailer) unpackBlocks(body []byte) error {
 
 // Decode decodes a trailer encoded using Encode.
 func (t *Trailer) Decode(st *ion.Symtab, body []byte) error {
-	v, _, err := ion.ReadDatum(st, body)
+	v, _, err := ion.ReadDatumLimited(st, body, maxIndexDepth)
 	if err != nil {
 		return err
 	}
@@ -473,6 +576,31 @@ func (t *Trailer) BlockSize(i int) int64 {
 	return end - start
 }
 
+// Rows returns the number of rows within
+// blocks [start, end) of the object, or false
+// if the number of rows in any of those blocks
+// is not known.
+func (t *Trailer) Rows(start, end int) (int64, bool) {
+	n := int64(0)
+	for i := start; i < end; i++ {
+		if t.Blocks[i].Rows <= 0 {
+			return 0, false
+		}
+		n += t.Blocks[i].Rows
+	}
+	return n, true
+}
+
+// TotalRows returns the number of rows within
+// all of the blocks of the object, or false
+// if the number of rows in any block is not known.
+//
+// See also Decompressed for the total
+// decompressed size of the object.
+func (t *Trailer) TotalRows() (int64, bool) {
+	return t.Rows(0, len(t.Blocks))
+}
+
 // DecompressedSize returns the decompressed
 // size of block [i] within the object.
 func (t *Trailer) DecompressedSize(i int) int64 {
@@ -51,6 +51,49 @@ func TestTrailerEncode(t *testing.T) {
 				{[]string{"foo"}, time0.Add(time.Second), time0.Add(time.Minute)},
 			}),
 		},
+		{
+			Version:    2,
+			Offset:     0x12345,
+			Algo:       "zstd",
+			BlockShift: 20,
+			Blocks: []Blockdesc{
+				{
+					Offset: 0,
+					Chunks: 700,
+					Rows:   123456,
+				},
+				{
+					Offset: 1 << 20,
+					Chunks: 1,
+					Rows:   17,
+				},
+			},
+			Sparse: mksparse(nil, []TimeRange{
+				{[]string{"foo"}, time0, time0.Add(time.Second)},
+				{[]string{"foo"}, time0.Add(time.Second), time0.Add(time.Minute)},
+			}),
+		},
+		{
+			Version:    2,
+			Offset:     0x12345,
+			Algo:       "lz4",
+			BlockShift: 20,
+			Blocks: []Blockdesc{
+				{
+					Offset:   0,
+					Chunks:   700,
+					Checksum: 0xdeadbeef,
+				},
+				{
+					Offset: 1 << 20,
+					Chunks: 1,
+				},
+			},
+			Sparse: mksparse(nil, []TimeRange{
+				{[]string{"foo"}, time0, time0.Add(time.Second)},
+				{[]string{"foo"}, time0.Add(time.Second), time0.Add(time.Minute)},
+			}),
+		},
 	}
 
 	for i := range samples {
@@ -76,3 +119,87 @@ func TestTrailerEncode(t *testing.T) {
 		}
 	}
 }
+
+func TestTrailerVersion(t *testing.T) {
+	time0 := date.Now().Truncate(time.Microsecond)
+	encode := func(trailer *Trailer, extra ...ion.Field) (*ion.Symtab, []byte) {
+		var st ion.Symtab
+		var buf ion.Buffer
+		trailer.Encode(&buf, &st)
+		d, _, err := ion.ReadDatum(&st, buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := d.Struct()
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Reset()
+		ion.NewStruct(&st, append(s.Fields(nil), extra...)).Encode(&buf, &st)
+		return &st, buf.Bytes()
+	}
+	trailer := Trailer{
+		Version:    1,
+		Algo:       "zstd",
+		BlockShift: 20,
+		Blocks:     []Blockdesc{{Offset: 0, Chunks: 1}},
+		Sparse: mksparse(nil, []TimeRange{
+			{[]string{"foo"}, time0, time0.Add(time.Second)},
+		}),
+	}
+	var out Trailer
+	st, buf := encode(&trailer)
+	if err := out.Decode(st, buf); err != nil {
+		t.Fatal(err)
+	}
+	if out.Version != 1 {
+		t.Errorf("trailer without checksums has version %d", out.Version)
+	}
+
+	// checksums are only written in
+	// trailers that version 1 readers
+	// know to skip unknown fields in
+	trailer.Blocks[0].Checksum = 0x1234
+	st, buf = encode(&trailer)
+	if err := out.Decode(st, buf); err != nil {
+		t.Fatal(err)
+	}
+	if out.Version != 2 || out.Blocks[0].Checksum != 0x1234 {
+		t.Errorf("got version %d checksum %x", out.Version, out.Blocks[0].Checksum)
+	}
+	future := ion.Field{Label: "future", Datum: ion.Int(1)}
+	st, buf = encode(&trailer, future)
+	if err := out.Decode(st, buf); err != nil {
+		t.Errorf("version 2 trailer with unknown field: %s", err)
+	}
+	trailer.Blocks[0].Checksum = 0
+	st, buf = encode(&trailer, future)
+	if err := out.Decode(st, buf); err == nil {
+		t.Error("version 1 trailer with unknown field decoded")
+	}
+
+	// row counts are only written if every
+	// block has one, and only then do they
+	// require version 2
+	trailer.Blocks = append(trailer.Blocks, Blockdesc{Offset: 100, Chunks: 1})
+	trailer.Sparse = mksparse(nil, []TimeRange{
+		{[]string{"foo"}, time0, time0.Add(time.Second)},
+		{[]string{"foo"}, time0, time0.Add(time.Second)},
+	})
+	trailer.Blocks[0].Rows = 10
+	st, buf = encode(&trailer)
+	if err := out.Decode(st, buf); err != nil {
+		t.Fatal(err)
+	}
+	if out.Version != 1 || out.Blocks[0].Rows != 0 {
+		t.Errorf("partial rows: got version %d rows %d", out.Version, out.Blocks[0].Rows)
+	}
+	trailer.Blocks[1].Rows = 20
+	st, buf = encode(&trailer)
+	if err := out.Decode(st, buf); err != nil {
+		t.Fatal(err)
+	}
+	if out.Version != 2 || out.Blocks[0].Rows != 10 || out.Blocks[1].Rows != 20 {
+		t.Errorf("full rows: got version %d rows %d %d", out.Version, out.Blocks[0].Rows, out.Blocks[1].Rows)
+	}
+}
@@ -77,8 +77,6 @@ type Chunker struct {
 	writesyms Symtab       // symbol table for Write()
 	rs        resymbolizer // resymbolizer for Write()
 
-	rowcount int // row count associated with Ranges
-
 	// WalkTimeRanges is the list of time ranges
 	// that is automatically scanned during
 	// Chunker.Write.
@@ -86,6 +84,12 @@ type Chunker struct {
 	// symbolized WalkTimeRanges
 	rangeSyms [][]Symbol
 
+	// OnCommit, if non-nil, is called from Commit
+	// with each committed object and the symbol
+	// table used to encode it. The object is only
+	// valid for the duration of the call.
+	OnCommit func(st *Symtab, obj []byte)
+
 	tmpbuf  Buffer // scratch buffer
 	lastoff int    // last committed object offset
 	lastst  int    // last symbol table size
@@ -101,6 +105,13 @@ type Chunker struct {
 	noCompress bool
 }
 
+// SymbolEpoch returns a counter that is incremented
+// each time Commit replaces c.Symbols with a new
+// symbol table. Callers that cache symbols interned
+// in c.Symbols must intern them again when the
+// epoch changes.
+func (c *Chunker) SymbolEpoch() int { return c.symEpoch }
+
 // Set sets the buffer used by c to b and resets c to
 // its initial state. This should only be used between
 // benchmark runs to avoid allocation overhead.
@@ -245,19 +256,14 @@ func (c *Chunker) flushRanges() error {
 	c.flushID = 0
 	c.rangeSyms = c.rangeSyms[:0]
 
-	// TODO: make this configurable?
-	// Just 33% of rows containing a value
-	// seems like a pretty conservative lower-bound.
-	minRange := c.rowcount / 3
-
+	// note that every range has to be included,
+	// however sparse: a path without a range in
+	// this chunk is indexed as having no values
+	// in it if the path has a range in any
+	// other chunk
 	if mm, ok := c.W.(minMaxSetter); ok {
 		for _, p := range c.Ranges.paths {
 			r := c.Ranges.m[p]
-			if r.count() < minRange {
-				// don't include this range if
-				// it was too sparse to be interesting
-				continue
-			}
 			if min, max, ok := r.ranges(); ok {
 				path := p.resolve(&c.Symbols)
 				mm.SetMinMax(path, min, max)
@@ -271,7 +277,6 @@ func (c *Chunker) flushRanges() error {
 		}
 	}
 	c.Ranges.flush()
-	c.rowcount = 0
 	c.written = 0
 	return nil
 }
@@ -411,6 +416,9 @@ func (c *Chunker) Commit() error {
 	if lastsize > c.Align {
 		return err2big(c.Align)
 	}
+	if c.OnCommit != nil {
+		c.OnCommit(&c.Symbols, cur[c.lastoff:])
+	}
 	c.compressed = false
 	if len(cur) <= c.Align && c.adjustSyms() {
 		c.lastoff = c.Buffer.Size()
@@ -420,11 +428,23 @@ func (c *Chunker) Commit() error {
 	} else if err := c.compressOrFlush(); err != nil {
 		return err
 	}
-	c.rowcount++
 	c.Ranges.commit()
 	return nil
 }
 
+// Abort discards the object that is currently
+// being written (everything written since the last
+// call to Commit), including any open structures
+// or lists and any uncommitted range values.
+//
+// Abort can be used to skip an object that
+// could not be written completely.
+func (c *Chunker) Abort() {
+	c.Buffer.segs = c.Buffer.segs[:0]
+	c.Buffer.buf = c.Buffer.buf[:c.lastoff]
+	c.Ranges.abort()
+}
+
 // Flush flushes the output of the chunker,
 // regardless of whether or not the current
 // buffer is approaching the target alignment.
@@ -501,7 +521,7 @@ func (c *Chunker) ReadFrom(r io.Reader, cons []Field) (int64, error) {
 				buf = make([]byte, size)
 				this = buf
 			}
-			_, err = io.ReadFull(b, buf)
+			_, err = io.ReadFull(b, this)
 			if err != nil {
 				return n, err
 			}
@@ -15,9 +15,14 @@
 package ion
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/SnellerInc/sneller/date"
 )
 
 func TestPathLess(t *testing.T) {
@@ -81,3 +86,81 @@ func TestUniqueFields(t *testing.T) {
 		}
 	}
 }
+
+type minMaxWriter struct {
+	bytes.Buffer
+	ranges map[string][2]Datum
+}
+
+func (w *minMaxWriter) SetMinMax(path []string, min, max Datum) {
+	if w.ranges == nil {
+		w.ranges = make(map[string][2]Datum)
+	}
+	w.ranges[strings.Join(path, ".")] = [2]Datum{min, max}
+}
+
+func TestChunkerAbort(t *testing.T) {
+	var out minMaxWriter
+	cn := Chunker{W: &out, Align: 2048}
+	ts := cn.Symbols.Intern("ts")
+	var path Symbuf
+	path.Prepare(1)
+	path.Push(ts)
+	write := func(n int64, abort bool) {
+		t.Helper()
+		cn.BeginStruct(-1)
+		cn.BeginField(ts)
+		tm := date.Unix(n, 0)
+		cn.WriteTime(tm)
+		cn.Ranges.AddTime(path, tm)
+		if abort {
+			// abort in the middle of a nested object
+			cn.BeginField(cn.Symbols.Intern("partial"))
+			cn.BeginList(-1)
+			cn.WriteInt(n)
+			cn.Abort()
+			return
+		}
+		cn.EndStruct()
+		if err := cn.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(100, false)
+	write(50, true)
+	write(200, false)
+	write(300, true)
+	if err := cn.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	var st Symtab
+	var got []int64
+	mem := out.Bytes()
+	for len(mem) > 0 {
+		var d Datum
+		var err error
+		d, mem, err = ReadDatum(&st, mem)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Type() != StructType {
+			continue
+		}
+		tm, err := d.Field("ts").Timestamp()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !d.Field("partial").IsEmpty() {
+			t.Errorf("unexpected field in %v", d)
+		}
+		got = append(got, tm.Unix())
+	}
+	if !slices.Equal(got, []int64{100, 200}) {
+		t.Errorf("got records %v", got)
+	}
+	want := [2]Datum{Timestamp(date.Unix(100, 0)), Timestamp(date.Unix(200, 0))}
+	r, ok := out.ranges["ts"]
+	if !ok || !r[0].Equal(want[0]) || !r[1].Equal(want[1]) {
+		t.Errorf("got range %v, want %v", r, want)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package ion
+
+import (
+	"strings"
+)
+
+// shapeTypes maps an Ion type to its
+// name in the output of SNELLER_DATASHAPE
+var shapeTypes = [...]string{
+	NullType:       "null",
+	BoolType:       "bool",
+	UintType:       "int",
+	IntType:        "int",
+	FloatType:      "float",
+	DecimalType:    "decimal",
+	TimestampType:  "timestamp",
+	SymbolType:     "string",
+	StringType:     "string",
+	ClobType:       "clob",
+	BlobType:       "blob",
+	ListType:       "list",
+	SexpType:       "sexp",
+	StructType:     "struct",
+	AnnotationType: "annotation",
+}
+
+// DatashapeAccumulator tallies the types of the
+// values at each path in a sequence of structures,
+// like the 'fields' output of SNELLER_DATASHAPE
+// (without the value ranges).
+//
+// The zero value of DatashapeAccumulator
+// is ready to use.
+type DatashapeAccumulator struct {
+	total  int
+	fields map[string]map[string]int
+}
+
+// Add adds the fields of the structure d.
+// Values that are not structures are ignored.
+func (a *DatashapeAccumulator) Add(d Datum) {
+	if !d.IsStruct() {
+		return
+	}
+	if a.fields == nil {
+		a.fields = make(map[string]map[string]int)
+	}
+	a.total++
+	d.Walk(func(path []string, d Datum) error {
+		if len(path) == 0 {
+			return nil
+		}
+		t := d.Type()
+		if int(t) >= len(shapeTypes) || shapeTypes[t] == "" {
+			return nil
+		}
+		p := strings.Join(path, ".")
+		counts := a.fields[p]
+		if counts == nil {
+			counts = make(map[string]int)
+			a.fields[p] = counts
+		}
+		counts[shapeTypes[t]]++
+		return nil
+	})
+}
+
+// Total returns the number of
+// structures passed to Add.
+func (a *DatashapeAccumulator) Total() int { return a.total }
+
+// Shape returns the accumulated shape as a map
+// from each path to a map from each type name
+// ("null", "int", "string", etc.) to its count.
+// The result has the same structure as the decoded
+// 'fields' output of SNELLER_DATASHAPE.
+func (a *DatashapeAccumulator) Shape() map[string]any {
+	shape := make(map[string]any, len(a.fields))
+	for path, counts := range a.fields {
+		m := make(map[string]any, len(counts))
+		for typ, n := range counts {
+			m[typ] = n
+		}
+		shape[path] = m
+	}
+	return shape
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package ion
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDatashapeAccumulator(t *testing.T) {
+	rows := `
+{"id": 1, "name": "a", "tags": ["x", 2], "user": {"karma": 1.5}}
+{"id": -2, "name": null, "tags": [], "user": {"karma": 3, "admin": true}}
+{"id": "three", "tags": [["y"]]}
+`
+	var acc DatashapeAccumulator
+	var st Symtab
+	dec := json.NewDecoder(strings.NewReader(rows))
+	for dec.More() {
+		d, err := FromJSON(&st, dec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		acc.Add(d)
+	}
+	acc.Add(Int(4)) // not a struct; ignored
+	if acc.Total() != 3 {
+		t.Errorf("got total %d", acc.Total())
+	}
+	want := map[string]any{
+		"id":                 map[string]any{"int": 2, "string": 1},
+		"name":               map[string]any{"string": 1, "null": 1},
+		"tags":               map[string]any{"list": 3},
+		"tags.$items":        map[string]any{"string": 1, "int": 1, "list": 1},
+		"tags.$items.$items": map[string]any{"string": 1},
+		"user":               map[string]any{"struct": 2},
+		"user.karma":         map[string]any{"float": 1, "int": 1},
+		"user.admin":         map[string]any{"bool": 1},
+	}
+	if got := acc.Shape(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %v", got)
+		t.Errorf("want %v", want)
+	}
+}
+
+func TestDatumWalkStop(t *testing.T) {
+	var st Symtab
+	d, err := FromJSON(&st, json.NewDecoder(strings.NewReader(`{"a": [1, {"b": 2}], "c": 3}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	err = d.Walk(func(path []string, d Datum) error {
+		p := strings.Join(path, ".")
+		paths = append(paths, p)
+		if p == "a.$items.b" {
+			return Stop
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"", "a", "a.$items", "a.$items", "a.$items.b"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got paths %q", paths)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"slices"
 	"strings"
@@ -32,6 +33,17 @@ import (
 //lint:ignore ST1012 sentinel error
 var Stop = errors.New("stop early")
 
+// SymbolError is the error returned when
+// a datum references a symbol that is not
+// present in its symbol table.
+type SymbolError struct {
+	ID Symbol
+}
+
+func (e *SymbolError) Error() string {
+	return fmt.Sprintf("symbol %d not in symbol table", e.ID)
+}
+
 // Datum represents any Ion datum.
 //
 // The Marshal and Unmarshal functions natively
@@ -41,7 +53,7 @@ var Stop = errors.New("stop early")
 // A Datum should be a value returned by
 //
 //	Float, Int, Uint, Struct, List, Bool,
-//	BigInt, Timestamp, Annotation, ..., or ReadDatum.
+//	BigInt, Timestamp, Annotation, ..., MarshalDatum, or ReadDatum.
 type Datum struct {
 	st  []string
 	buf []byte
@@ -75,6 +87,13 @@ func (d Datum) Clone() Datum {
 // Equal returns whether d and x are
 // semantically equivalent.
 func (d Datum) Equal(x Datum) bool {
+	// identical encodings with compatible
+	// symbol tables are always equal
+	// (annotations are never considered
+	// equal, so they take the slow path)
+	if len(d.buf) > 0 && bytes.Equal(d.buf, x.buf) && stoverlap(d.st, x.st) && d.Type() != AnnotationType {
+		return true
+	}
 	switch d.Type() {
 	case NullType:
 		return x.IsNull()
@@ -261,6 +280,22 @@ func (d Datum) Encode(dst *Buffer, st *Symtab) {
 	rs.resym(dst, d.buf)
 }
 
+// MarshalBinary implements encoding.BinaryMarshaler.
+// The returned bytes are a self-contained ion
+// stream: a BVM and a symbol table containing
+// only the symbols used by d, followed by d.
+func (d Datum) MarshalBinary() ([]byte, error) {
+	if d.IsEmpty() {
+		return nil, fmt.Errorf("ion: cannot marshal empty datum")
+	}
+	var st Symtab
+	var body, out Buffer
+	d.Encode(&body, &st)
+	st.Marshal(&out, true)
+	out.UnsafeAppend(body.Bytes())
+	return out.Bytes(), nil
+}
+
 // performance-sensitive resymbolization path
 func (r *resymbolizer) resym(dst *Buffer, buf []byte) []byte {
 	switch TypeOf(buf) {
@@ -384,7 +419,7 @@ func (d Datum) float(field string) (float64, error) {
 	}
 	f, _, err := ReadFloat64(d.buf)
 	if err != nil {
-		panic(err)
+		return 0, err
 	}
 	return f, nil
 }
@@ -395,7 +430,7 @@ func (d Datum) int(field string) (int64, error) {
 	}
 	i, _, err := ReadInt(d.buf)
 	if err != nil {
-		panic(err)
+		return 0, err
 	}
 	return i, nil
 }
@@ -406,7 +441,7 @@ func (d Datum) uint(field string) (uint64, error) {
 	}
 	u, _, err := ReadUint(d.buf)
 	if err != nil {
-		panic(err)
+		return 0, err
 	}
 	return u, nil
 }
@@ -447,12 +482,12 @@ func (d Datum) annotation(field string) (string, Datum, error) {
 	}
 	sym, body, _, err := ReadAnnotation(d.buf)
 	if err != nil {
-		panic(err)
+		return "", Empty, err
 	}
 	st := d.symtab()
 	s, ok := st.Lookup(sym)
 	if !ok {
-		panic("ion.Datum.Annotation: missing symbol")
+		return "", Empty, fmt.Errorf("ion.Datum.Annotation: %w", &SymbolError{ID: sym})
 	}
 	return s, Datum{st: d.st, buf: body}, nil
 }
@@ -463,7 +498,7 @@ func (d Datum) bool(field string) (bool, error) {
 	}
 	b, _, err := ReadBool(d.buf)
 	if err != nil {
-		panic(err)
+		return false, err
 	}
 	return b, nil
 }
@@ -474,18 +509,21 @@ func (d Datum) symbol(field string) (Symbol, error) {
 	}
 	sym, _, err := ReadSymbol(d.buf)
 	if err != nil {
-		panic(err)
+		return 0, err
 	}
 	return sym, nil
 }
 
 func (d Datum) string(field string) (string, error) {
 	if d.IsSymbol() {
-		sym, _ := d.Symbol()
+		sym, err := d.symbol(field)
+		if err != nil {
+			return "", err
+		}
 		st := d.symtab()
 		s, ok := st.Lookup(sym)
 		if !ok {
-			panic("ion.Datum.String: missing symbol")
+			return "", fmt.Errorf("ion.Datum.String: %w", &SymbolError{ID: sym})
 		}
 		return s, nil
 	}
@@ -499,7 +537,7 @@ func (d Datum) stringShared(field string) ([]byte, error) {
 	}
 	s, _, err := ReadStringShared(d.buf)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	return s, nil
 }
@@ -515,7 +553,7 @@ func (d Datum) blobShared(field string) ([]byte, error) {
 	}
 	b, _ := Contents(d.buf)
 	if b == nil {
-		panic("ion.Datum.Blob: invalid ion")
+		return nil, errInvalidIon
 	}
 	return b, nil
 }
@@ -526,7 +564,7 @@ func (d Datum) timestamp(field string) (date.Time, error) {
 	}
 	t, _, err := ReadTime(d.buf)
 	if err != nil {
-		panic(err)
+		return date.Time{}, err
 	}
 	return t, nil
 }
@@ -624,7 +662,7 @@ func ReadField(st *Symtab, body []byte) (Field, []byte, error) {
 	}
 	name, ok := st.Lookup(sym)
 	if !ok {
-		return Field{}, nil, fmt.Errorf("symbol %d not in symbol table", sym)
+		return Field{}, nil, &SymbolError{ID: sym}
 	}
 	val, rest, err := ReadDatum(st, body)
 	if err != nil {
@@ -718,6 +756,12 @@ func NewStruct(st *Symtab, f []Field) Struct {
 	return Struct{st: st.alias(), buf: dst.Bytes()}
 }
 
+// WriteStruct writes a structure containing
+// the fields f.
+//
+// Fields are always encoded in ascending order
+// of their symbol IDs (see Buffer.BeginField),
+// so the encoding does not depend on the order of f.
 func (b *Buffer) WriteStruct(st *Symtab, f []Field) {
 	if len(f) == 0 {
 		b.UnsafeAppend(emptyStruct)
@@ -904,6 +948,20 @@ func (s Struct) FieldByName(name string) (Field, bool) {
 	return field, ok
 }
 
+// ContainsValue returns whether any field
+// in s has a value that is Equal to d.
+func (s Struct) ContainsValue(d Datum) bool {
+	found := false
+	s.Each(func(f Field) error {
+		if f.Datum.Equal(d) {
+			found = true
+			return Stop
+		}
+		return nil
+	})
+	return found
+}
+
 // mergeFields merges the given fields with the
 // fields of this struct into a new struct,
 // overwriting any previous fields with
@@ -1082,6 +1140,63 @@ func (l List) Items(items []Datum) []Datum {
 	return items
 }
 
+// ListItems is the path component used by
+// Datum.Walk for the elements of a list.
+// (It matches the name of list elements
+// in the output of SNELLER_DATASHAPE.)
+const ListItems = "$items"
+
+// Walk calls fn for d and then for each value
+// nested inside d in depth-first order.
+// The path passed to fn holds the labels of the
+// enclosing struct fields, with ListItems for each
+// enclosing list, so it is empty for d itself.
+// The path is only valid for the duration of the call.
+//
+// If fn returns Stop, Walk stops and returns nil.
+// If fn returns any other non-nil error, or if Walk
+// encounters malformed data, Walk stops and returns
+// that error.
+func (d Datum) Walk(fn func(path []string, d Datum) error) error {
+	err := d.walk(nil, fn)
+	if err == errStopWalk {
+		err = nil
+	}
+	return err
+}
+
+// errStopWalk replaces Stop inside Walk so that
+// it is not swallowed by Struct.Each or List.Each
+var errStopWalk = errors.New("stop walk")
+
+func (d Datum) walk(path []string, fn func([]string, Datum) error) error {
+	if err := fn(path, d); err != nil {
+		if err == Stop {
+			err = errStopWalk
+		}
+		return err
+	}
+	switch d.Type() {
+	case StructType:
+		s, err := d.Struct()
+		if err != nil {
+			return err
+		}
+		return s.Each(func(f Field) error {
+			return f.Datum.walk(append(path, f.Label), fn)
+		})
+	case ListType:
+		l, err := d.List()
+		if err != nil {
+			return err
+		}
+		return l.Each(func(d Datum) error {
+			return d.walk(append(path, ListItems), fn)
+		})
+	}
+	return nil
+}
+
 func (l List) Equal(l2 List) bool {
 	if l.IsEmpty() {
 		return l2.IsEmpty()
@@ -1103,6 +1218,20 @@ func (l List) Equal(l2 List) bool {
 	return true
 }
 
+// Contains returns whether any item
+// in l is Equal to d.
+func (l List) Contains(d Datum) bool {
+	found := false
+	l.Each(func(item Datum) error {
+		if item.Equal(d) {
+			found = true
+			return Stop
+		}
+		return nil
+	})
+	return found
+}
+
 // ErrUnexpectedEnd is returned if Iterator.Next
 // is called after the list has been exhausted.
 var ErrUnexpectedEnd = errors.New("unexpected end of list")
@@ -1297,7 +1426,7 @@ func decodeSymbolDatum(st *Symtab, b []byte) (Datum, []byte, error) {
 		return Empty, rest, err
 	}
 	if _, ok := st.Lookup(sym); !ok {
-		return Empty, rest, fmt.Errorf("symbol %d not in symbol table", sym)
+		return Empty, rest, &SymbolError{ID: sym}
 	}
 	return rawDatum(st, b), rest, nil
 }
@@ -1344,7 +1473,7 @@ func decodeAnnotationDatum(st *Symtab, b []byte) (Datum, []byte, error) {
 		return Empty, rest, err
 	}
 	if _, ok := st.Lookup(sym); !ok {
-		return Empty, rest, fmt.Errorf("symbol %d not in symbol table", sym)
+		return Empty, rest, &SymbolError{ID: sym}
 	}
 	_, err = validateDatum(st, body)
 	if err != nil {
@@ -1372,6 +1501,9 @@ func decodeAnnotationDatum(st *Symtab, b []byte) (Datum, []byte, error) {
 // the caller must guarantee that the contents of buf
 // will not be modified until it is no longer needed.
 func ReadDatum(st *Symtab, buf []byte) (Datum, []byte, error) {
+	if len(buf) == 0 {
+		return Empty, nil, io.ErrUnexpectedEOF
+	}
 	var err error
 	if IsBVM(buf) || TypeOf(buf) == AnnotationType {
 		buf, err = st.Unmarshal(buf)
@@ -1382,6 +1514,10 @@ func ReadDatum(st *Symtab, buf []byte) (Datum, []byte, error) {
 			return Empty, buf, nil
 		}
 	}
+	if t := TypeOf(buf); t != NullType && t < AnnotationType && buf[0]&0x0f == 0x0f {
+		// typed null
+		return Datum{buf: buf[:1]}, buf[1:], nil
+	}
 	switch t := TypeOf(buf); t {
 	case NullType:
 		return decodeNullDatum(st, buf)
@@ -1441,6 +1577,94 @@ func validateDatum(st *Symtab, buf []byte) (next []byte, err error) {
 	return buf[size:], nil
 }
 
+// maxDatumElements is the maximum number of
+// elements (including the datum itself) that
+// may appear in a datum returned by ReadDatumLimited
+var maxDatumElements = 1 << 26
+
+var (
+	errDatumTooDeep     = errors.New("ion: datum exceeds nesting depth limit")
+	errDatumTooManyElms = errors.New("ion: datum exceeds element count limit")
+)
+
+// ReadDatumLimited is equivalent to ReadDatum,
+// but it returns an error if the datum contains
+// structures, lists, or annotations nested more
+// than maxDepth levels deep or if it contains an
+// unreasonably large number of elements.
+//
+// Many of the methods on Datum (Equal, Encode, etc.)
+// recurse through nested values, so ReadDatumLimited
+// should be preferred when buf comes from a source
+// that is not trusted.
+func ReadDatumLimited(st *Symtab, buf []byte, maxDepth int) (Datum, []byte, error) {
+	d, rest, err := ReadDatum(st, buf)
+	if err != nil || d.IsEmpty() {
+		return d, rest, err
+	}
+	n := 0
+	err = checkLimits(d.buf, maxDepth, &n)
+	if err != nil {
+		return Empty, rest, err
+	}
+	return d, rest, nil
+}
+
+// checkLimits checks that the datum in buf
+// does not nest containers more than depth
+// levels deep and adds the number of elements
+// in the datum to *n
+func checkLimits(buf []byte, depth int, n *int) error {
+	if len(buf) == 0 {
+		return errInvalidIon
+	}
+	*n++
+	if *n > maxDatumElements {
+		return errDatumTooManyElms
+	}
+	switch t := TypeOf(buf); t {
+	case StructType, ListType, SexpType:
+		if depth <= 0 {
+			return errDatumTooDeep
+		}
+		body, _ := Contents(buf)
+		if body == nil {
+			return errInvalidIon
+		}
+		var err error
+		for len(body) > 0 {
+			if t == StructType {
+				_, body, err = ReadLabel(body)
+				if err != nil {
+					return err
+				}
+				if len(body) == 0 {
+					return errInvalidIon
+				}
+			}
+			size := SizeOf(body)
+			if size <= 0 || size > len(body) {
+				return errInvalidIon
+			}
+			err = checkLimits(body[:size], depth-1, n)
+			if err != nil {
+				return err
+			}
+			body = body[size:]
+		}
+	case AnnotationType:
+		if depth <= 0 {
+			return errDatumTooDeep
+		}
+		_, body, _, err := ReadAnnotation(buf)
+		if err != nil {
+			return err
+		}
+		return checkLimits(body, depth-1, n)
+	}
+	return nil
+}
+
 // Equal returns whether a and b are
 // semantically equivalent.
 func Equal(a, b Datum) bool {
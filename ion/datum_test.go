@@ -18,10 +18,16 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/SnellerInc/sneller/date"
 )
 
 func TestDatumEncode(t *testing.T) {
@@ -154,6 +160,70 @@ func BenchmarkDatumPassthrough(b *testing.B) {
 	}
 }
 
+func TestReadDatumLimited(t *testing.T) {
+	// {"x": [[[ ... 0 ... ]]]} with the given
+	// number of nested lists
+	nested := func(st *Symtab, depth int) []byte {
+		var buf Buffer
+		buf.BeginStruct(-1)
+		buf.BeginField(st.Intern("x"))
+		for i := 0; i < depth; i++ {
+			buf.BeginList(-1)
+		}
+		buf.WriteInt(0)
+		for i := 0; i < depth; i++ {
+			buf.EndList()
+		}
+		buf.EndStruct()
+		return buf.Bytes()
+	}
+
+	var st Symtab
+	buf := nested(&st, 10000)
+	_, _, err := ReadDatumLimited(&st, buf, 100)
+	if !errors.Is(err, errDatumTooDeep) {
+		t.Fatalf("expected depth limit error; got %v", err)
+	}
+	// ReadDatum does not look past the outer struct
+	if _, _, err := ReadDatum(&st, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// struct + 10 lists fits in exactly 11 levels
+	buf = nested(&st, 10)
+	_, _, err = ReadDatumLimited(&st, buf, 10)
+	if !errors.Is(err, errDatumTooDeep) {
+		t.Fatalf("expected depth limit error; got %v", err)
+	}
+	d, rest, err := ReadDatumLimited(&st, buf, 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 0 || !d.IsStruct() {
+		t.Fatalf("unexpected result %s (%d bytes left)", d.JSON(), len(rest))
+	}
+
+	// element count limit
+	old := maxDatumElements
+	maxDatumElements = 5
+	t.Cleanup(func() { maxDatumElements = old })
+	items := make([]Datum, 4)
+	for i := range items {
+		items[i] = Int(int64(i))
+	}
+	var b Buffer
+	NewList(nil, items).Encode(&b, &st)
+	if _, _, err := ReadDatumLimited(&st, b.Bytes(), 2); err != nil {
+		t.Fatal(err)
+	}
+	b.Reset()
+	NewList(nil, append(items, Int(4))).Encode(&b, &st)
+	_, _, err = ReadDatumLimited(&st, b.Bytes(), 2)
+	if !errors.Is(err, errDatumTooManyElms) {
+		t.Fatalf("expected element limit error; got %v", err)
+	}
+}
+
 func BenchmarkIteratorNextAllocs(b *testing.B) {
 	in := make([]Datum, b.N)
 	for i := range in {
@@ -199,14 +269,347 @@ func FuzzReadDatum(f *testing.F) {
 			if err != nil {
 				break
 			}
-			switch d.Type() {
-			case ListType:
-				d, _ := d.List()
-				d.Each(func(d Datum) error { return nil })
-			case StructType:
-				d, _ := d.Struct()
-				d.Each(func(f Field) error { return nil })
+			fuzzWalk(d)
+		}
+	})
+}
+
+// fuzzWalk calls every accessor that applies
+// to d and to each of its children; none of
+// them should panic regardless of the input
+func fuzzWalk(d Datum) {
+	switch d.Type() {
+	case BoolType:
+		d.Bool()
+	case UintType:
+		d.Uint()
+		d.Int()
+	case IntType:
+		d.Int()
+	case FloatType:
+		d.Float()
+	case TimestampType:
+		d.Timestamp()
+	case SymbolType:
+		d.Symbol()
+		d.String()
+	case StringType:
+		d.String()
+		d.StringShared()
+	case BlobType:
+		d.Blob()
+		d.BlobShared()
+	case AnnotationType:
+		_, body, err := d.Annotation()
+		if err == nil {
+			fuzzWalk(body)
+		}
+	case ListType:
+		d, _ := d.List()
+		d.Each(func(d Datum) error {
+			fuzzWalk(d)
+			return nil
+		})
+	case StructType:
+		d, _ := d.Struct()
+		d.Each(func(f Field) error {
+			fuzzWalk(f.Datum)
+			return nil
+		})
+	}
+}
+
+func TestReadTypedNull(t *testing.T) {
+	for typ := BoolType; typ < AnnotationType; typ++ {
+		buf := []byte{byte(typ<<4) | 0x0f, 0x20}
+		d, rest, err := ReadDatum(nil, buf)
+		if err != nil {
+			t.Errorf("%s: %v", textNullNames[typ], err)
+			continue
+		}
+		if d.Type() != typ || len(d.Raw()) != 1 || len(rest) != 1 {
+			t.Errorf("%s: got %s with %d bytes left", textNullNames[typ], d.Type(), len(rest))
+		}
+	}
+}
+
+func TestDatumEqualFastPath(t *testing.T) {
+	var st Symtab
+	values := []Datum{
+		Null,
+		Int(-5),
+		Uint(5),
+		Float(1.5),
+		Float(math.NaN()),
+		Bool(true),
+		String("foo"),
+		Interned(&st, "bar"),
+		Blob([]byte("blob")),
+		Timestamp(date.Date(2023, 1, 2, 3, 4, 5, 0)),
+		NewList(&st, []Datum{Int(1), Interned(&st, "x")}).Datum(),
+		NewStruct(&st, []Field{
+			{Label: "a", Datum: Int(1)},
+			{Label: "b", Datum: Interned(&st, "y")},
+		}).Datum(),
+		Annotation(&st, "note", Int(1)),
+	}
+	for i, d := range values {
+		want := d.Type() != AnnotationType
+		if got := d.Equal(d); got != want {
+			t.Errorf("%d: %v.Equal(itself) = %v", i, d.Type(), got)
+		}
+		if got := d.Equal(d.Clone()); got != want {
+			t.Errorf("%d: %v.Equal(clone) = %v", i, d.Type(), got)
+		}
+		for j, x := range values {
+			if i != j && d.Equal(x) {
+				t.Errorf("%d: %v equals %d: %v", i, d.Type(), j, x.Type())
 			}
 		}
+	}
+
+	// re-reading identical bytes with a symbol table
+	// that assigns the symbols differently must not
+	// compare equal just because the bytes are equal
+	var st1, st2, st3 Symtab
+	st1.Intern("a")
+	st1.Intern("b")
+	st2.Intern("b")
+	st2.Intern("a")
+	st3.Intern("a")
+	st3.Intern("b")
+	st3.Intern("c")
+	s := NewStruct(&st1, []Field{
+		{Label: "a", Datum: Int(1)},
+		{Label: "b", Datum: Interned(&st1, "a")},
+	}).Datum()
+	var buf Buffer
+	s.Encode(&buf, &st1)
+	read := func(st *Symtab) Datum {
+		d, _, err := ReadDatum(st, buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	d1, d2, d3 := read(&st1), read(&st2), read(&st3)
+	if !bytes.Equal(d1.buf, d2.buf) {
+		t.Fatal("expected identical encodings")
+	}
+	if d1.Equal(d2) || d2.Equal(d1) {
+		t.Error("datums with different symbol tables compare equal")
+	}
+	// st3 extends st1, so the datums are the same
+	if !d1.Equal(d3) || !d3.Equal(d1) {
+		t.Error("datums with compatible symbol tables are not equal")
+	}
+}
+
+func BenchmarkDatumEqual(b *testing.B) {
+	var st Symtab
+	fields := make([]Field, 1000)
+	for i := range fields {
+		fields[i] = Field{
+			Label: fmt.Sprintf("field%d", i),
+			Datum: NewList(&st, []Datum{Int(int64(i)), String("value")}).Datum(),
+		}
+	}
+	d := NewStruct(&st, fields).Datum()
+	x := d.Clone()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if !d.Equal(x) {
+			b.Fatal("not equal")
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	var st Symtab
+	lst := NewList(&st, []Datum{Int(1), String("foo"), Interned(&st, "bar")})
+	s := NewStruct(&st, []Field{
+		{Label: "a", Datum: Int(1)},
+		{Label: "b", Datum: lst.Datum()},
+	})
+	for _, tc := range []struct {
+		d          Datum
+		list, strc bool
+	}{
+		{d: Int(1), list: true, strc: true},
+		{d: Float(1), list: true, strc: true},
+		{d: String("bar"), list: true},
+		{d: Interned(&st, "foo"), list: true},
+		{d: lst.Datum(), strc: true},
+		{d: Int(2)},
+		{d: String("a")},
+		{d: Null},
+	} {
+		if got := lst.Contains(tc.d); got != tc.list {
+			t.Errorf("List.Contains(%v) = %v", tc.d.Type(), got)
+		}
+		if got := s.ContainsValue(tc.d); got != tc.strc {
+			t.Errorf("Struct.ContainsValue(%v) = %v", tc.d.Type(), got)
+		}
+	}
+	var empty List
+	if empty.Contains(Null) || NewList(&st, nil).Contains(Int(1)) {
+		t.Error("empty list contains an item")
+	}
+	var emptyStruct Struct
+	if emptyStruct.ContainsValue(Null) || NewStruct(&st, nil).ContainsValue(Int(1)) {
+		t.Error("empty struct contains a value")
+	}
+}
+
+func TestSymbolError(t *testing.T) {
+	var st Symtab
+	s := NewStruct(&st, []Field{
+		{Label: "a", Datum: Int(1)},
+		{Label: "b", Datum: Int(2)},
+	})
+	var buf Buffer
+	s.Datum().Encode(&buf, &st)
+
+	// drop "b" from the symbol table
+	sym, ok := st.Symbolize("b")
+	if !ok {
+		t.Fatal("symbol b missing")
+	}
+	st.Truncate(int(sym))
+	check := func(err error) {
+		t.Helper()
+		var se *SymbolError
+		if !errors.As(err, &se) {
+			t.Fatalf("got error %v; want *SymbolError", err)
+		}
+		if se.ID != sym {
+			t.Fatalf("got symbol %d; want %d", se.ID, sym)
+		}
+	}
+	d, _, err := ReadDatum(&st, buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err = d.Struct()
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(s.Each(func(Field) error { return nil }))
+}
+
+func TestWriteStructOrder(t *testing.T) {
+	var st Symtab
+	st.Intern("c")
+	st.Intern("a")
+	st.Intern("b")
+	f0 := []Field{
+		{Label: "a", Datum: Int(1)},
+		{Label: "b", Datum: String("two")},
+		{Label: "c", Datum: NewList(&st, []Datum{Int(3)}).Datum()},
+	}
+	f1 := []Field{f0[2], f0[0], f0[1]}
+	f2 := []Field{f0[1], f0[2], f0[0]}
+	var b0, b1, b2 Buffer
+	b0.WriteStruct(&st, f0)
+	b1.WriteStruct(&st, f1)
+	b2.WriteStruct(&st, f2)
+	if !bytes.Equal(b0.Bytes(), b1.Bytes()) || !bytes.Equal(b0.Bytes(), b2.Bytes()) {
+		t.Fatalf("encodings differ: %x %x %x", b0.Bytes(), b1.Bytes(), b2.Bytes())
+	}
+	d, _, err := ReadDatum(&st, b0.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := d.Struct()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels []string
+	s.Each(func(f Field) error {
+		labels = append(labels, f.Label)
+		return nil
 	})
+	if want := []string{"c", "a", "b"}; !slices.Equal(labels, want) {
+		t.Fatalf("got fields %v; want %v", labels, want)
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	var src Symtab
+	var items []Datum
+	kinds := []string{"alpha", "beta", "gamma"}
+	for i := 0; i < 100; i++ {
+		items = append(items, NewStruct(&src, []Field{
+			{Label: "kind", Datum: String(kinds[i%len(kinds)])},
+			{Label: "id", Datum: Int(int64(i))},
+		}).Datum())
+	}
+	// used only once; should remain inline
+	items = append(items, String("unique"))
+	// a symbol used only once should be expanded
+	items = append(items, Interned(&src, "lonely"))
+	d := NewList(&src, items).Datum()
+
+	var st Symtab
+	c, err := d.Canonicalize(&st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.buf) >= len(d.buf) {
+		t.Fatalf("canonical size %d >= original size %d", len(c.buf), len(d.buf))
+	}
+	if !c.Equal(d) || !d.Equal(c) {
+		t.Fatal("canonical datum not equal to the original")
+	}
+	for _, s := range kinds {
+		if _, ok := st.Symbolize(s); !ok {
+			t.Errorf("%q not interned", s)
+		}
+	}
+	for _, s := range []string{"unique", "lonely"} {
+		if _, ok := st.Symbolize(s); ok {
+			t.Errorf("%q interned", s)
+		}
+	}
+	lst, err := c.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := lst.Items(nil)
+	if n := len(got); n != len(items) {
+		t.Fatalf("got %d items; want %d", n, len(items))
+	}
+	if kind := got[0].Field("kind"); !kind.IsSymbol() {
+		t.Errorf("repeated string has type %v", kind.Type())
+	}
+	for _, d := range got[len(got)-2:] {
+		if !d.IsString() {
+			t.Errorf("unique value has type %v", d.Type())
+		}
+	}
+
+	// text already present in st is always symbolized
+	if c, err := String("alpha").Canonicalize(&st); err != nil || !c.IsSymbol() || !c.Equal(String("alpha")) {
+		t.Errorf("String(\"alpha\") canonicalized to %v (%v)", c.Type(), err)
+	}
+
+	// malformed input is an error
+	for _, buf := range [][]byte{
+		{0xb4, 0x21, 0x01},       // list longer than the input
+		{0xb2, 0x24, 0x01},       // item longer than the list
+		{0xd3, 0x8a, 0x21, 0x01}, // unknown field symbol
+		{0xd2, 0x8a},             // field without a value
+		{0xb3, 0x71, 0x7f, 0x21}, // unknown symbol value
+	} {
+		_, err := Datum{buf: buf}.Canonicalize(&Symtab{})
+		if err == nil {
+			t.Errorf("%x: expected an error", buf)
+		}
+	}
+	// and never a panic
+	for i := range c.buf {
+		buf := slices.Clone(c.buf)
+		buf[i] ^= 0xff
+		Datum{st: c.st, buf: buf}.Canonicalize(&Symtab{})
+	}
 }
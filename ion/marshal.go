@@ -45,6 +45,43 @@ func init() {
 
 type encodefn func(*Symtab, *Buffer, reflect.Value)
 
+// parseTag returns the ion field name for f
+// and its options according to the "ion" struct tag,
+// which follows the encoding/json conventions:
+// the name defaults to the Go field name,
+// "-" skips the field, and "-," names it "-"
+func parseTag(f *reflect.StructField) (name string, omitempty, skip bool) {
+	tag, ok := f.Tag.Lookup("ion")
+	if !ok {
+		return f.Name, false, false
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+// isEmpty returns whether a field with
+// the omitempty option should be omitted
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
 func compileEncoder(t reflect.Type) (encodefn, bool) {
 	// in order to break dependency chains for (mutually-)recursive types,
 	// force any concurrent lookups to delay compilation until eval time
@@ -76,20 +113,11 @@ func compileEncoder(t reflect.Type) (encodefn, bool) {
 		if fields[i].PkgPath != "" || len(fields[i].Index) != 1 {
 			continue // unexported or promoted embedded struct field
 		}
-		name := fields[i].Name
-		typ := fields[i].Type
-		omitempty := false
-		if val, ok := fields[i].Tag.Lookup("ion"); ok {
-			var rest string
-			name, rest, ok = strings.Cut(val, ",")
-			if ok && rest == "omitempty" {
-				omitempty = true
-			}
-		}
-		if name == "-" {
+		name, omitempty, skip := parseTag(&fields[i])
+		if skip {
 			continue // explicitly ignored
 		}
-		efn, ok := encoderFunc(typ)
+		efn, ok := encoderFunc(fields[i].Type)
 		if !ok {
 			continue
 		}
@@ -104,7 +132,7 @@ func compileEncoder(t reflect.Type) (encodefn, bool) {
 		dst.BeginStruct(-1)
 		for i := range encs {
 			val := src.Field(encs[i].index)
-			if encs[i].omitempty && val.IsZero() {
+			if encs[i].omitempty && isEmpty(val) {
 				continue
 			}
 			dst.BeginField(st.Intern(encs[i].name))
@@ -216,7 +244,20 @@ func encoderFunc(t reflect.Type) (encodefn, bool) {
 // Marshal encodes src into dst, updating the symbol
 // table as necessary for new symbols that are introduced
 // as part of encoding.
+//
+// Structure fields are named according to their "ion"
+// struct tags, following the encoding/json conventions:
+// `ion:"name"` sets the field name, `ion:"-"` skips
+// the field, and the omitempty option skips fields
+// that are zero or have zero length. (Unlike
+// encoding/json, omitempty also skips zero structures
+// such as the zero time.Time.) Unexported fields and
+// fields of types that cannot be encoded are skipped.
 func Marshal(st *Symtab, dst *Buffer, src any) error {
+	if src == nil {
+		dst.WriteNull()
+		return nil
+	}
 	v := reflect.ValueOf(src)
 	t := v.Type()
 	enc, ok := encoderFunc(t)
@@ -226,3 +267,15 @@ func Marshal(st *Symtab, dst *Buffer, src any) error {
 	enc(st, dst, v)
 	return nil
 }
+
+// MarshalDatum is like Marshal, but it
+// returns the encoded value as a Datum.
+func MarshalDatum(src any) (Datum, error) {
+	var st Symtab
+	var dst Buffer
+	if err := Marshal(&st, &dst, src); err != nil {
+		return Empty, err
+	}
+	d, _, err := ReadDatum(&st, dst.Bytes())
+	return d, err
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package ion
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMarshalDatum(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	type record struct {
+		Name     string    `ion:"name"`
+		Count    int64     `ion:"count,omitempty"`
+		Ratio    float64   `ion:",omitempty"`
+		Tags     []string  `ion:"tags,omitempty"`
+		Empty    []string  `ion:"empty,omitempty"`
+		When     time.Time `ion:"when"`
+		Points   []point   `ion:"points"`
+		Inner    *point    `ion:"inner"`
+		Nil      *point    `ion:"nil"`
+		Skipped  int       `ion:"-"`
+		Dash     bool      `ion:"-,"`
+		Meta     map[string]int
+		unexport int
+	}
+	now := time.Date(2023, 1, 2, 3, 4, 5, 6000, time.UTC)
+	in := record{
+		Name:     "x",
+		Ratio:    0.5,
+		Tags:     []string{"a", "b"},
+		Empty:    []string{},
+		When:     now,
+		Points:   []point{{1, 2}, {3, 4}},
+		Inner:    &point{X: 5},
+		Skipped:  7,
+		Dash:     true,
+		Meta:     map[string]int{"k": 1},
+		unexport: 8,
+	}
+	d, err := MarshalDatum(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// round-trip through the encoded form
+	var st Symtab
+	var buf Buffer
+	d.Encode(&buf, &st)
+	var tmp Buffer
+	st.Marshal(&tmp, true)
+	tmp.UnsafeAppend(buf.Bytes())
+	var st2 Symtab
+	d2, rest, err := ReadDatum(&st2, tmp.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 0 {
+		t.Fatalf("%d bytes remaining", len(rest))
+	}
+	if !d.Equal(d2) {
+		t.Fatalf("%s != %s", d.Text(), d2.Text())
+	}
+
+	got, err := d2.ToGo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"name":  "x",
+		"Ratio": 0.5,
+		"tags":  []any{"a", "b"},
+		"when":  now,
+		"points": []any{
+			map[string]any{"X": int64(1), "Y": int64(2)},
+			map[string]any{"X": int64(3), "Y": int64(4)},
+		},
+		"inner": map[string]any{"X": int64(5), "Y": int64(0)},
+		"nil":   nil,
+		"-":     true,
+		"Meta":  map[string]any{"k": int64(1)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %#v\nwant %#v", got, want)
+	}
+
+	var out record
+	if _, err := Unmarshal(&st2, buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	in.Empty = nil
+	in.Skipped = 0
+	in.unexport = 0
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got  %#v\nwant %#v", out, in)
+	}
+
+	d, err = MarshalDatum(nil)
+	if err != nil || !d.IsNull() {
+		t.Errorf("MarshalDatum(nil) = %s, %v", d.Text(), err)
+	}
+	if _, err := MarshalDatum(make(chan int)); err == nil {
+		t.Error("expected an error marshaling a channel")
+	}
+}
@@ -57,6 +57,14 @@ func (rs *Ranges) commit() {
 	}
 }
 
+// abort is called when an object is discarded
+// to drop any uncommitted range values.
+func (rs *Ranges) abort() {
+	for _, r := range rs.m {
+		r.abort()
+	}
+}
+
 // flush is called after every flush to indicate that
 // the committed ranges have been written or otherwise
 // consumed.
@@ -93,6 +101,10 @@ type dataRange interface {
 	// committed and confirmed to be part of the
 	// current chunk.
 	commit()
+	// abort is called when the object that
+	// is currently being written is discarded;
+	// it should drop any uncommitted values.
+	abort()
 	// flush is called after every flush to
 	// indicate that the committed range has been
 	// written or otherwise consumed.
@@ -141,6 +153,8 @@ func (r *timeRange) commit() {
 	r.hasPending = false
 }
 
+func (r *timeRange) abort() { r.hasPending = false }
+
 func (r *timeRange) count() int { return r.commits }
 
 func (r *timeRange) flush() bool {
@@ -0,0 +1,93 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package ion
+
+import (
+	"bufio"
+	"io"
+)
+
+// RecordWriter is an io.Writer that re-encodes
+// each top-level value of an ion stream as an
+// independent record.
+//
+// Unlike the input stream, where values share
+// the symbol table that precedes them, each
+// record is self-contained so that it can be
+// decoded without any of the preceding output,
+// at the expense of repeating the symbols used
+// by each value.
+//
+// Binary records are produced with Datum.MarshalBinary.
+// Each one begins with a BVM, which resets the symbol
+// table, so the output is an ordinary ion stream that
+// can be read with ReadDatum, and each record can also
+// be decoded on its own. Binary records are not separated
+// by newlines: a newline byte is a valid ion NOP pad
+// header, and binary values may contain newline bytes.
+//
+// Text records are produced with Datum.Text
+// and are each followed by a newline;
+// they never contain a newline themselves.
+type RecordWriter struct {
+	// Text, if set, causes values to be
+	// written as ion text rather than binary ion.
+	Text bool
+
+	w  io.Writer
+	b  *bufio.Writer
+	st Symtab
+}
+
+// NewRecordWriter constructs a RecordWriter
+// that writes records to w.
+func NewRecordWriter(w io.Writer) *RecordWriter {
+	return &RecordWriter{w: w, b: bufio.NewWriter(w)}
+}
+
+// Write implements io.Writer.Write
+//
+// The buffer passed to Write must contain complete ion objects.
+func (w *RecordWriter) Write(src []byte) (int, error) {
+	p := len(src)
+	for len(src) > 0 {
+		if TypeOf(src) == NullType && SizeOf(src) > 1 {
+			// skip nop pad
+			src = src[SizeOf(src):]
+			continue
+		}
+		d, rest, err := ReadDatum(&w.st, src)
+		if err != nil {
+			w.b.Flush()
+			return p - len(src), err
+		}
+		src = rest
+		if d.IsEmpty() {
+			continue // symbol table
+		}
+		if w.Text {
+			w.b.WriteString(d.Text())
+			w.b.WriteByte('\n')
+			continue
+		}
+		buf, err := d.MarshalBinary()
+		if err != nil {
+			w.b.Flush()
+			return p - len(src), err
+		}
+		w.b.Write(buf)
+	}
+	return p, w.b.Flush()
+}
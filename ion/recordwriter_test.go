@@ -0,0 +1,114 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package ion
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRecordWriter(t *testing.T) {
+	var st Symtab
+	rows := []Datum{
+		NewStruct(&st, []Field{
+			{Label: "name", Datum: String("first\nline")},
+			{Label: "kind", Datum: Interned(&st, "sym")},
+			{Label: "inner", Datum: NewStruct(&st, []Field{
+				{Label: "list", Datum: NewList(&st, []Datum{Int(10), Interned(&st, "other")}).Datum()},
+			}).Datum()},
+		}).Datum(),
+		NewStruct(&st, []Field{
+			{Label: "kind", Datum: Interned(&st, "another")},
+			{Label: "n", Datum: Uint(10)}, // encodes as a newline byte
+		}).Datum(),
+		Int(-3),
+	}
+	var body, stream Buffer
+	for i := range rows {
+		rows[i].Encode(&body, &st)
+	}
+	st.Marshal(&stream, true)
+	stream.UnsafeAppend(body.Bytes())
+	// add a nop pad
+	stream.UnsafeAppend([]byte{0x01, 0x00})
+
+	var out bytes.Buffer
+	w := NewRecordWriter(&out)
+	n, err := w.Write(stream.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(stream.Bytes()) {
+		t.Fatalf("wrote %d of %d bytes", n, len(stream.Bytes()))
+	}
+	// the output must be readable as
+	// an ordinary stream of ion values
+	var rst Symtab
+	var d Datum
+	buf := out.Bytes()
+	var got []Datum
+	for len(buf) > 0 {
+		d, buf, err = ReadDatum(&rst, buf)
+		if err != nil {
+			t.Fatalf("after %d values: %s", len(got), err)
+		}
+		if !d.IsEmpty() {
+			got = append(got, d)
+		}
+	}
+	if len(got) != len(rows) {
+		t.Fatalf("read %d values, want %d", len(got), len(rows))
+	}
+	for i := range rows {
+		if !got[i].Equal(rows[i]) {
+			t.Errorf("value %d: got %s, want %s", i, got[i].Text(), rows[i].Text())
+		}
+	}
+	// ... and each record must decode on its
+	// own, with a fresh symbol table
+	buf = out.Bytes()
+	for i := range rows {
+		var st Symtab
+		for d = (Datum{}); d.IsEmpty(); {
+			d, buf, err = ReadDatum(&st, buf)
+			if err != nil {
+				t.Fatalf("record %d: %s", i, err)
+			}
+		}
+		if !d.Equal(rows[i]) {
+			t.Errorf("record %d: got %s, want %s", i, d.Text(), rows[i].Text())
+		}
+	}
+	if len(buf) != 0 {
+		t.Fatalf("%d trailing bytes", len(buf))
+	}
+
+	out.Reset()
+	w = NewRecordWriter(&out)
+	w.Text = true
+	if _, err := w.Write(stream.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) != len(rows)+1 || lines[len(rows)] != "" {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+	for i := range rows {
+		if want := rows[i].Text(); lines[i] != want {
+			t.Errorf("line %d: got %q, want %q", i, lines[i], want)
+		}
+	}
+}
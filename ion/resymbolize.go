@@ -37,3 +37,169 @@ func resymbolize(dst *Buffer, rng *Ranges, st *Symtab, buf []byte) {
 	rng.paths = newp
 	newst.CloneInto(st)
 }
+
+// canonicalRepeat is the number of times a
+// string value must occur within a datum
+// before Datum.Canonicalize encodes it as a symbol
+const canonicalRepeat = 2
+
+type canonicalizer struct {
+	resymbolizer
+	counts map[string]int
+}
+
+// Canonicalize returns d re-encoded against st
+// with its string and symbol values rewritten
+// so that text which occurs at least twice in d
+// or which is already interned in st is encoded
+// as a symbol, and all other text is encoded inline
+// as a string. Since strings and symbols compare
+// equal, the result is Equal to d.
+//
+// Symbols may be added to st. An error is
+// returned if d is not valid ion.
+func (d Datum) Canonicalize(st *Symtab) (Datum, error) {
+	if d.IsEmpty() {
+		return d, nil
+	}
+	src := d.symtab()
+	c := canonicalizer{
+		resymbolizer: resymbolizer{
+			srctab: &src,
+			dsttab: st,
+		},
+		counts: make(map[string]int),
+	}
+	// count checks the encoding, so
+	// write can assume it is valid
+	if err := c.count(d.buf); err != nil {
+		return Empty, err
+	}
+	var dst Buffer
+	c.write(&dst, d.buf)
+	return Datum{st: st.alias(), buf: dst.Bytes()}, nil
+}
+
+// splitValue splits off the first value of body
+func splitValue(body []byte) ([]byte, []byte, error) {
+	size := SizeOf(body)
+	if size <= 0 || size > len(body) {
+		return nil, nil, errInvalidIon
+	}
+	return body[:size], body[size:], nil
+}
+
+// count counts the occurrences of each string
+// or symbol value in buf
+func (c *canonicalizer) count(buf []byte) error {
+	switch t := TypeOf(buf); t {
+	case StringType:
+		s, _, err := ReadStringShared(buf)
+		if err != nil {
+			return err
+		}
+		c.counts[string(s)]++
+	case SymbolType:
+		sym, _, err := ReadSymbol(buf)
+		if err != nil {
+			return err
+		}
+		text, ok := c.srctab.Lookup(sym)
+		if !ok {
+			return &SymbolError{ID: sym}
+		}
+		c.counts[text]++
+	case StructType, ListType:
+		body, _ := Contents(buf)
+		if body == nil {
+			return errInvalidIon
+		}
+		var val []byte
+		var err error
+		for len(body) > 0 {
+			if t == StructType {
+				var sym Symbol
+				sym, body, err = ReadLabel(body)
+				if err != nil {
+					return err
+				}
+				if _, ok := c.srctab.Lookup(sym); !ok {
+					return &SymbolError{ID: sym}
+				}
+			}
+			val, body, err = splitValue(body)
+			if err != nil {
+				return err
+			}
+			if err := c.count(val); err != nil {
+				return err
+			}
+		}
+	case AnnotationType:
+		sym, body, _, err := ReadAnnotation(buf)
+		if err != nil {
+			return err
+		}
+		if _, ok := c.srctab.Lookup(sym); !ok {
+			return &SymbolError{ID: sym}
+		}
+		return c.count(body)
+	default:
+		if size := SizeOf(buf); size <= 0 || size > len(buf) {
+			return errInvalidIon
+		}
+	}
+	return nil
+}
+
+// text writes a string or symbol value
+func (c *canonicalizer) text(dst *Buffer, s string) {
+	if c.counts[s] >= canonicalRepeat {
+		dst.WriteSymbol(c.dsttab.Intern(s))
+	} else if sym, ok := c.dsttab.Symbolize(s); ok {
+		dst.WriteSymbol(sym)
+	} else {
+		dst.WriteString(s)
+	}
+}
+
+// write writes buf, which has been checked by count
+func (c *canonicalizer) write(dst *Buffer, buf []byte) {
+	switch TypeOf(buf) {
+	case StringType:
+		s, _, _ := ReadStringShared(buf)
+		c.text(dst, string(s))
+	case SymbolType:
+		sym, _, _ := ReadSymbol(buf)
+		c.text(dst, c.srctab.Get(sym))
+	case StructType:
+		dst.BeginStruct(-1)
+		body, _ := Contents(buf)
+		var sym Symbol
+		for len(body) > 0 {
+			sym, body, _ = ReadLabel(body)
+			dst.BeginField(c.get(sym))
+			size := SizeOf(body)
+			c.write(dst, body[:size])
+			body = body[size:]
+		}
+		dst.EndStruct()
+	case ListType:
+		dst.BeginList(-1)
+		body, _ := Contents(buf)
+		for len(body) > 0 {
+			size := SizeOf(body)
+			c.write(dst, body[:size])
+			body = body[size:]
+		}
+		dst.EndList()
+	case AnnotationType:
+		sym, body, _, _ := ReadAnnotation(buf)
+		dst.BeginAnnotation(1)
+		dst.BeginField(c.get(sym))
+		c.write(dst, body)
+		dst.EndAnnotation()
+	default:
+		dst.UnsafeAppend(buf[:SizeOf(buf)])
+	}
+}
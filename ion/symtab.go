@@ -197,11 +197,31 @@ func (s *Symtab) SymbolizeBytes(x []byte) (Symbol, bool) {
 	return s.rawGetBytes(x)
 }
 
-// Equal checks if two symtabs are equal.
+// Equal checks if two symtabs are equal,
+// i.e. they contain the same symbols
+// interned in the same order, so that
+// each symbol ID maps to the same string
+// in both tables.
 func (s *Symtab) Equal(o *Symtab) bool {
 	return slices.Equal(s.interned, o.interned)
 }
 
+// Clone returns a deep copy of s.
+//
+// The returned Symtab initially shares its
+// list of symbols with s, and that list is
+// marked as aliased in both tables, so
+// interning or unmarshaling symbols into
+// either table copies the list rather than
+// modifying it. Consequently, datums that
+// alias the symbols of s (see Datum) remain
+// valid after s or the clone are modified.
+func (s *Symtab) Clone() *Symtab {
+	o := new(Symtab)
+	s.CloneInto(o)
+	return o
+}
+
 // CloneInto performs a deep copy
 // of s into o. CloneInto takes care to
 // use some of the existing storage in o
@@ -210,7 +230,7 @@ func (s *Symtab) CloneInto(o *Symtab) {
 	o.interned = s.alias()
 	o.aliased = len(o.interned)
 	if o.toindex == nil {
-		o.toindex = make(map[string]int, len(o.interned)+len(systemsyms))
+		o.init()
 	}
 	o.memsize = s.memsize
 	if s.toindex != nil {
@@ -223,6 +223,92 @@ func TestSymtabAlias(t *testing.T) {
 	}
 }
 
+func TestSymtabClone(t *testing.T) {
+	var st Symtab
+	var buf Buffer
+	st.Intern("foo")
+	st.Intern("bar")
+	st.Marshal(&buf, true)
+	rec := NewStruct(&st, []Field{
+		{Label: "foo", Datum: String("x")},
+		{Label: "bar", Datum: Int(1)},
+	})
+	rec.Encode(&buf, &st)
+	body := buf.Bytes()
+
+	// the original interns a new symbol;
+	// the clone should be unaffected
+	clone := st.Clone()
+	if !clone.Equal(&st) {
+		t.Fatal("clone not equal to original")
+	}
+	st.Intern("baz")
+	if clone.Equal(&st) || clone.MaxID() != st.MaxID()-1 {
+		t.Fatalf("clone %s modified by original %s", clone, &st)
+	}
+	if sym := clone.Intern("symbols"); sym != SystemSymSymbols {
+		t.Errorf("clone interned system symbol as %d", sym)
+	}
+
+	// a datum decoded with the clone is identical
+	// to one decoded with a fresh symbol table
+	var fresh Symtab
+	rest, err := fresh.Unmarshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _, err := ReadDatum(&fresh, rest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := ReadDatum(clone, rest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// modifying the clone doesn't affect
+	// datums that alias its symbols
+	clone.Reset()
+	clone.Intern("quux")
+	clone.Intern("quuux")
+	if !got.Equal(want) {
+		t.Errorf("after reset: got %v, want %v", got, want)
+	}
+	s, err := got.Struct()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.FieldByName("foo"); !ok {
+		t.Error("field foo not found after reset")
+	}
+}
+
+func TestSymtabEqual(t *testing.T) {
+	var a, b, c Symtab
+	a.Intern("foo")
+	a.Intern("bar")
+	b.Intern("bar")
+	b.Intern("foo")
+	c.Intern("foo")
+	c.Intern("bar")
+	if a.Equal(&b) {
+		t.Error("symbols interned in different orders are equal")
+	}
+	if !a.Equal(&c) || !c.Equal(&a) {
+		t.Error("symbols interned in the same order are not equal")
+	}
+	c.Intern("baz")
+	if a.Equal(&c) {
+		t.Error("symbol tables of different sizes are equal")
+	}
+	var empty Symtab
+	if !empty.Equal(new(Symtab)) || empty.Equal(&a) {
+		t.Error("empty symbol table comparison")
+	}
+}
+
 func TestMergeSymtabs(t *testing.T) {
 	testcases := []struct {
 		existing []string
@@ -0,0 +1,340 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package ion
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Text returns d formatted as Ion text.
+// Any error encountered while formatting d
+// is ignored; use WriteText to detect errors.
+//
+// See WriteText for a description of the output.
+func (d Datum) Text() string {
+	st := d.symtab()
+	out, _, _ := appendText(nil, &st, d.buf)
+	return string(out)
+}
+
+// WriteText writes d to w formatted as Ion text
+// and returns the first error encountered, if any.
+//
+// Structures are written as {name: value, ...},
+// lists as [value, ...], sexps as (value ...),
+// symbols as 'name', strings as "text",
+// timestamps in Ion timestamp syntax,
+// blobs as {{base64}}, clobs as {{"text"}},
+// and annotations as name::value.
+// Floats always include an exponent so that
+// they are not confused with decimals when
+// the output is parsed again.
+func (d Datum) WriteText(w io.Writer) error {
+	if d.IsEmpty() {
+		return fmt.Errorf("ion: cannot format empty datum as text")
+	}
+	st := d.symtab()
+	out, _, err := appendText(nil, &st, d.buf)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+var textNullNames = [...]string{
+	NullType:      "null",
+	BoolType:      "null.bool",
+	UintType:      "null.int",
+	IntType:       "null.int",
+	FloatType:     "null.float",
+	DecimalType:   "null.decimal",
+	TimestampType: "null.timestamp",
+	SymbolType:    "null.symbol",
+	StringType:    "null.string",
+	ClobType:      "null.clob",
+	BlobType:      "null.blob",
+	ListType:      "null.list",
+	SexpType:      "null.sexp",
+	StructType:    "null.struct",
+}
+
+// isPad returns whether buf begins with a nop pad
+func isPad(buf []byte) bool {
+	return TypeOf(buf) == NullType && buf[0]&0x0f != 0x0f
+}
+
+// skipPad skips the nop pad at the start of buf
+func skipPad(buf []byte) ([]byte, error) {
+	size := SizeOf(buf)
+	if size <= 0 || size > len(buf) {
+		return nil, errInvalidIon
+	}
+	return buf[size:], nil
+}
+
+func appendText(dst []byte, st *Symtab, buf []byte) ([]byte, []byte, error) {
+	if len(buf) == 0 {
+		return dst, nil, errInvalidIon
+	}
+	t := TypeOf(buf)
+	if t < AnnotationType && buf[0]&0x0f == 0x0f {
+		return append(dst, textNullNames[t]...), buf[1:], nil
+	}
+	switch t {
+	case BoolType:
+		b, rest, err := ReadBool(buf)
+		if err != nil {
+			return dst, rest, err
+		}
+		return strconv.AppendBool(dst, b), rest, nil
+	case UintType:
+		u, rest, err := ReadUint(buf)
+		if err != nil {
+			return dst, rest, err
+		}
+		return strconv.AppendUint(dst, u, 10), rest, nil
+	case IntType:
+		i, rest, err := ReadInt(buf)
+		if err != nil {
+			return dst, rest, err
+		}
+		return strconv.AppendInt(dst, i, 10), rest, nil
+	case FloatType:
+		bits := 64
+		if buf[0] == 0x44 {
+			bits = 32
+		}
+		f, rest, err := ReadFloat64(buf)
+		if err != nil {
+			return dst, rest, err
+		}
+		return appendTextFloat(dst, f, bits), rest, nil
+	case DecimalType:
+		return dst, buf, fmt.Errorf("ion: decimal text formatting not implemented")
+	case TimestampType:
+		ts, rest, err := ReadTime(buf)
+		if err != nil {
+			return dst, rest, err
+		}
+		return ts.AppendRFC3339Nano(dst), rest, nil
+	case SymbolType:
+		sym, rest, err := ReadSymbol(buf)
+		if err != nil {
+			return dst, rest, err
+		}
+		return appendTextSymbol(dst, st, sym), rest, nil
+	case StringType:
+		body, rest := Contents(buf)
+		if body == nil {
+			return dst, buf, errInvalidIon
+		}
+		return appendTextQuoted(dst, body, '"'), rest, nil
+	case ClobType:
+		body, rest := Contents(buf)
+		if body == nil {
+			return dst, buf, errInvalidIon
+		}
+		dst = append(dst, "{{"...)
+		dst = appendTextQuoted(dst, body, '"')
+		return append(dst, "}}"...), rest, nil
+	case BlobType:
+		body, rest := Contents(buf)
+		if body == nil {
+			return dst, buf, errInvalidIon
+		}
+		dst = append(dst, "{{"...)
+		start := len(dst)
+		dst = slices.Grow(dst, base64.StdEncoding.EncodedLen(len(body)))
+		dst = dst[:start+base64.StdEncoding.EncodedLen(len(body))]
+		base64.StdEncoding.Encode(dst[start:], body)
+		return append(dst, "}}"...), rest, nil
+	case ListType, SexpType:
+		body, rest := Contents(buf)
+		if body == nil {
+			return dst, buf, errInvalidIon
+		}
+		open, sep, end := byte('['), ", ", byte(']')
+		if t == SexpType {
+			open, sep, end = '(', " ", ')'
+		}
+		dst = append(dst, open)
+		first := true
+		var err error
+		for len(body) > 0 {
+			if isPad(body) {
+				body, err = skipPad(body)
+				if err != nil {
+					return dst, rest, err
+				}
+				continue
+			}
+			if !first {
+				dst = append(dst, sep...)
+			}
+			dst, body, err = appendText(dst, st, body)
+			if err != nil {
+				return dst, rest, err
+			}
+			first = false
+		}
+		return append(dst, end), rest, nil
+	case StructType:
+		body, rest := Contents(buf)
+		if body == nil {
+			return dst, buf, errInvalidIon
+		}
+		dst = append(dst, '{')
+		first := true
+		var sym Symbol
+		var err error
+		for len(body) > 0 {
+			sym, body, err = ReadLabel(body)
+			if err != nil {
+				return dst, rest, err
+			}
+			if len(body) == 0 {
+				return dst, rest, errInvalidIon
+			}
+			if isPad(body) {
+				body, err = skipPad(body)
+				if err != nil {
+					return dst, rest, err
+				}
+				continue
+			}
+			if !first {
+				dst = append(dst, ", "...)
+			}
+			dst = appendTextName(dst, st, sym)
+			dst = append(dst, ": "...)
+			dst, body, err = appendText(dst, st, body)
+			if err != nil {
+				return dst, rest, err
+			}
+			first = false
+		}
+		return append(dst, '}'), rest, nil
+	case AnnotationType:
+		sym, body, rest, err := ReadAnnotation(buf)
+		if err != nil {
+			return dst, rest, err
+		}
+		dst = appendTextName(dst, st, sym)
+		dst = append(dst, "::"...)
+		dst, _, err = appendText(dst, st, body)
+		return dst, rest, err
+	case NullType:
+		// nop pad at the top level
+		rest, err := skipPad(buf)
+		return dst, rest, err
+	default:
+		return dst, buf, fmt.Errorf("ion: cannot format type %s as text", t)
+	}
+}
+
+func appendTextFloat(dst []byte, f float64, bits int) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(dst, "nan"...)
+	case math.IsInf(f, 1):
+		return append(dst, "+inf"...)
+	case math.IsInf(f, -1):
+		return append(dst, "-inf"...)
+	}
+	start := len(dst)
+	dst = strconv.AppendFloat(dst, f, 'g', -1, bits)
+	if bytes.IndexByte(dst[start:], 'e') < 0 {
+		dst = append(dst, "e0"...)
+	}
+	return dst
+}
+
+// appendTextSymbol appends a symbol value,
+// which is always quoted so that it cannot be
+// confused with a keyword like null or true
+func appendTextSymbol(dst []byte, st *Symtab, sym Symbol) []byte {
+	name, ok := st.Lookup(sym)
+	if !ok {
+		return append(dst, "$"+strconv.Itoa(int(sym))...)
+	}
+	return appendTextQuoted(dst, []byte(name), '\'')
+}
+
+// appendTextName appends a field name or annotation,
+// which is only quoted when it is not an identifier
+func appendTextName(dst []byte, st *Symtab, sym Symbol) []byte {
+	name, ok := st.Lookup(sym)
+	if !ok {
+		return append(dst, "$"+strconv.Itoa(int(sym))...)
+	}
+	if isTextIdentifier(name) {
+		return append(dst, name...)
+	}
+	return appendTextQuoted(dst, []byte(name), '\'')
+}
+
+func isTextIdentifier(s string) bool {
+	switch s {
+	case "", "null", "true", "false", "nan":
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' || c == '$':
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// appendTextQuoted appends s surrounded by quote,
+// escaping quote, backslashes and non-printable
+// characters; invalid UTF-8 bytes are written
+// as \xHH escapes
+func appendTextQuoted(dst []byte, s []byte, quote byte) []byte {
+	const hex = "0123456789abcdef"
+	dst = append(dst, quote)
+	for len(s) > 0 {
+		r, size := utf8.DecodeRune(s)
+		switch {
+		case r == rune(quote) || r == '\\':
+			dst = append(dst, '\\', byte(r))
+		case r == '\n':
+			dst = append(dst, '\\', 'n')
+		case r == '\t':
+			dst = append(dst, '\\', 't')
+		case r == '\r':
+			dst = append(dst, '\\', 'r')
+		case r < 0x20 || r == 0x7f || (r == utf8.RuneError && size == 1):
+			dst = append(dst, '\\', 'x', hex[s[0]>>4], hex[s[0]&0xf])
+		default:
+			dst = append(dst, s[:size]...)
+		}
+		s = s[size:]
+	}
+	return append(dst, quote)
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package ion
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/SnellerInc/sneller/date"
+)
+
+func TestDatumText(t *testing.T) {
+	var st Symtab
+	typedNull := func(t Type) Datum {
+		return Datum{buf: []byte{byte(t<<4) | 0x0f}}
+	}
+	// sexps and clobs are encoded like lists
+	// and strings with a different type tag
+	retag := func(d Datum, t Type) Datum {
+		d = d.Clone()
+		d.buf[0] = byte(t<<4) | d.buf[0]&0x0f
+		return d
+	}
+	sexp := func(items ...Datum) Datum {
+		return retag(NewList(&st, items).Datum(), SexpType)
+	}
+	clob := func(s string) Datum {
+		return retag(String(s), ClobType)
+	}
+	tcs := []struct {
+		datum Datum
+		want  string
+	}{
+		{Null, "null"},
+		{typedNull(StringType), "null.string"},
+		{typedNull(StructType), "null.struct"},
+		{Bool(true), "true"},
+		{Bool(false), "false"},
+		{Int(-3), "-3"},
+		{Uint(1000), "1000"},
+		{Float(1.5), "1.5e0"},
+		{Float(0), "0e0"},
+		{Float(1e21), "1e+21"},
+		{Float(math.Inf(-1)), "-inf"},
+		{Float(math.NaN()), "nan"},
+		{Timestamp(date.Date(2023, 1, 2, 3, 4, 5, 600000000)), "2023-01-02T03:04:05.6Z"},
+		{Interned(&st, "sym"), "'sym'"},
+		{Interned(&st, "it's"), `'it\'s'`},
+		{String("foo"), `"foo"`},
+		{String("a\"b\\c\n\x01"), `"a\"b\\c\n\x01"`},
+		{String("naïve"), `"naïve"`},
+		{Blob([]byte("hello")), "{{aGVsbG8=}}"},
+		{clob("hi"), `{{"hi"}}`},
+		{Annotation(&st, "note", Int(1)), "note::1"},
+		{Annotation(&st, "two words", String("x")), `'two words'::"x"`},
+		{NewList(&st, nil).Datum(), "[]"},
+		{NewList(&st, []Datum{Int(1), String("x"), Null}).Datum(), `[1, "x", null]`},
+		{sexp(Interned(&st, "+"), Int(1), Int(2)), "('+' 1 2)"},
+		{NewStruct(&st, nil).Datum(), "{}"},
+		{NewStruct(&st, []Field{
+			{Label: "a", Datum: Int(1)},
+			{Label: "b c", Datum: Bool(true)},
+			{Label: "null", Datum: Null},
+		}).Datum(), `{a: 1, 'b c': true, 'null': null}`},
+		{
+			// struct-in-list
+			NewList(&st, []Datum{
+				NewStruct(&st, []Field{
+					{Label: "name", Datum: String("x")},
+					{Label: "tags", Datum: NewList(&st, []Datum{Interned(&st, "t0")}).Datum()},
+				}).Datum(),
+				NewStruct(&st, []Field{
+					{Label: "name", Datum: String("y")},
+					{Label: "when", Datum: Timestamp(date.Date(2022, 12, 31, 0, 0, 0, 0))},
+				}).Datum(),
+			}).Datum(),
+			`[{name: "x", tags: ['t0']}, {name: "y", when: 2022-12-31T00:00:00Z}]`,
+		},
+	}
+	for i := range tcs {
+		got := tcs[i].datum.Text()
+		if got != tcs[i].want {
+			t.Errorf("case %d: got %s, want %s", i, got, tcs[i].want)
+		}
+		var sb strings.Builder
+		if err := tcs[i].datum.WriteText(&sb); err != nil {
+			t.Errorf("case %d: WriteText: %s", i, err)
+		} else if sb.String() != tcs[i].want {
+			t.Errorf("case %d: WriteText wrote %s, want %s", i, sb.String(), tcs[i].want)
+		}
+	}
+	if err := Empty.WriteText(&strings.Builder{}); err == nil {
+		t.Error("expected an error writing an empty datum")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package ion
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// ToGo converts d into a native Go value,
+// recursing into structures and lists:
+//
+//	null (of any type)  nil
+//	bool                bool
+//	int                 int64
+//	uint                int64, or uint64 if it
+//	                    exceeds math.MaxInt64
+//	float               float64
+//	timestamp           time.Time (in UTC)
+//	string, symbol      string
+//	blob, clob          []byte
+//	list, sexp          []any
+//	struct              map[string]any
+//
+// Annotations are dropped and the annotated
+// value is converted in their place. If a
+// structure contains more than one field with
+// the same name, the last one is used. The
+// returned strings and byte slices do not share
+// memory with d. Decimals cannot be converted
+// and produce an error, as does malformed data.
+func (d Datum) ToGo() (any, error) {
+	if d.IsEmpty() {
+		return nil, fmt.Errorf("ion: cannot convert empty datum")
+	}
+	t := d.Type()
+	if t < AnnotationType && d.buf[0]&0x0f == 0x0f {
+		return nil, nil
+	}
+	switch t {
+	case BoolType:
+		return d.Bool()
+	case IntType:
+		return d.Int()
+	case UintType:
+		u, err := d.Uint()
+		if err != nil {
+			return nil, err
+		}
+		if u > math.MaxInt64 {
+			return u, nil
+		}
+		return int64(u), nil
+	case FloatType:
+		return d.Float()
+	case TimestampType:
+		ts, err := d.Timestamp()
+		if err != nil {
+			return nil, err
+		}
+		return ts.Time(), nil
+	case StringType, SymbolType:
+		return d.String()
+	case BlobType:
+		return d.Blob()
+	case ClobType:
+		b, _ := Contents(d.buf)
+		if b == nil {
+			return nil, errInvalidIon
+		}
+		return slices.Clone(b), nil
+	case ListType, SexpType:
+		body, _ := Contents(d.buf)
+		if body == nil {
+			return nil, errInvalidIon
+		}
+		out := []any{}
+		i := Iterator{st: d.st, buf: body}
+		for !i.Done() {
+			v, err := i.Next()
+			if err != nil {
+				return nil, err
+			}
+			g, err := v.ToGo()
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, g)
+		}
+		return out, nil
+	case StructType:
+		s, err := d.Struct()
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[string]any)
+		err = s.Each(func(f Field) error {
+			g, err := f.Datum.ToGo()
+			if err != nil {
+				return fmt.Errorf("field %q: %w", f.Label, err)
+			}
+			out[f.Label] = g
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return out, nil
+	case AnnotationType:
+		_, v, err := d.Annotation()
+		if err != nil {
+			return nil, err
+		}
+		return v.ToGo()
+	default:
+		return nil, fmt.Errorf("ion: cannot convert %s to a Go value", t)
+	}
+}
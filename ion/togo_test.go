@@ -0,0 +1,104 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package ion
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SnellerInc/sneller/date"
+)
+
+func TestToGo(t *testing.T) {
+	var st Symtab
+	t0 := time.Date(2023, 1, 2, 3, 4, 5, 6000, time.UTC)
+	d := NewStruct(&st, []Field{
+		{Label: "name", Datum: String("x")},
+		{Label: "kind", Datum: Interned(&st, "sym")},
+		{Label: "n", Datum: Int(-1)},
+		{Label: "u", Datum: Uint(math.MaxUint64)},
+		{Label: "small", Datum: Uint(3)},
+		{Label: "f", Datum: Float(1.5)},
+		{Label: "ok", Datum: Bool(true)},
+		{Label: "when", Datum: Timestamp(date.FromTime(t0))},
+		{Label: "blob", Datum: Blob([]byte{0, 1, 2})},
+		{Label: "none", Datum: Null},
+		{Label: "list", Datum: NewList(&st, []Datum{
+			Int(1),
+			String("two"),
+			NewStruct(&st, []Field{
+				{Label: "inner", Datum: NewList(&st, nil).Datum()},
+			}).Datum(),
+		}).Datum()},
+	}).Datum()
+
+	got, err := d.ToGo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"name":  "x",
+		"kind":  "sym",
+		"n":     int64(-1),
+		"u":     uint64(math.MaxUint64),
+		"small": int64(3),
+		"f":     1.5,
+		"ok":    true,
+		"when":  t0,
+		"blob":  []byte{0, 1, 2},
+		"none":  nil,
+		"list": []any{
+			int64(1),
+			"two",
+			map[string]any{"inner": []any{}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %#v\nwant %#v", got, want)
+	}
+
+	m := got.(map[string]any)
+	if ts, ok := m["when"].(time.Time); !ok || !ts.Equal(t0) {
+		t.Errorf("timestamp: got %#v", m["when"])
+	}
+	if b, ok := m["blob"].([]byte); ok {
+		b[0] = 0xff // must not alias d
+		v, _ := d.Field("blob").Blob()
+		if v[0] != 0 {
+			t.Error("blob shares memory with the datum")
+		}
+	} else {
+		t.Errorf("blob: got %#v", m["blob"])
+	}
+	if s, ok := m["kind"].(string); !ok || s != "sym" {
+		t.Errorf("symbol: got %#v", m["kind"])
+	}
+
+	// annotations are dropped
+	if v, err := Annotation(&st, "tag", Int(5)).ToGo(); err != nil || v != int64(5) {
+		t.Errorf("annotation: got %#v, %v", v, err)
+	}
+
+	// typed nulls convert to nil
+	typed := Datum{buf: []byte{byte(IntType<<4) | 0x0f}}
+	if v, err := typed.ToGo(); err != nil || v != nil {
+		t.Errorf("null.int: got %#v, %v", v, err)
+	}
+	if _, err := Empty.ToGo(); err == nil {
+		t.Error("expected an error converting an empty datum")
+	}
+}
@@ -17,11 +17,11 @@ package ion
 import (
 	"bufio"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"reflect"
-	"strings"
 	"sync"
 	"time"
 
@@ -848,7 +848,7 @@ func UnpackStructBody(st *Symtab, body []byte, fn func(string, []byte) error) (r
 		}
 		name, ok := st.Lookup(sym)
 		if !ok {
-			return rest, fmt.Errorf("symbol %d not in symbol table", sym)
+			return rest, &SymbolError{ID: sym}
 		}
 		next := SizeOf(body)
 		if next <= 0 || next > len(body) {
@@ -865,6 +865,17 @@ func UnpackStructBody(st *Symtab, body []byte, fn func(string, []byte) error) (r
 
 // Unmarshal unmarshals data from a raw slice
 // into the value v using the provided symbol table.
+//
+// Structures are unmarshaled into Go structs
+// using the same "ion" struct tags as Marshal;
+// fields that are absent from the data are left
+// unmodified, and data fields without a corresponding
+// Go field are ignored. Numbers are converted to
+// the numeric type of the destination as long as
+// they can be represented exactly, and symbols
+// can be unmarshaled into strings. Values that
+// do not match the destination type produce
+// a *TypeError.
 func Unmarshal(st *Symtab, data []byte, v any) ([]byte, error) {
 	rv := reflect.ValueOf(v)
 	typ := rv.Type()
@@ -882,6 +893,17 @@ func Unmarshal(st *Symtab, data []byte, v any) ([]byte, error) {
 	return dec(st, data, dst)
 }
 
+// UnmarshalDatum is like Unmarshal,
+// but it unmarshals from d.
+func UnmarshalDatum(d Datum, v any) error {
+	if d.IsEmpty() {
+		return fmt.Errorf("ion.UnmarshalDatum: empty datum")
+	}
+	st := d.symtab()
+	_, err := Unmarshal(&st, d.buf, v)
+	return err
+}
+
 // Decoder is a stateful decoder of streams of
 // ion objects. A Decoder wraps an io.Reader so
 // that a sequence of ion records can be read
@@ -1062,7 +1084,6 @@ fieldloop:
 		if fields[i].PkgPath != "" {
 			continue // unexported
 		}
-		name := fields[i].Name
 		index := fields[i].Index
 		if len(index) != 1 {
 			continue // promoted anonymous field
@@ -1071,13 +1092,9 @@ fieldloop:
 		if !ok {
 			continue fieldloop
 		}
-		if altname, ok := fields[i].Tag.Lookup("ion"); ok {
-			altname, _, _ = strings.Cut(altname, ",") // ignore options
-			if altname == "-" {
-				continue fieldloop
-			} else if altname != "" {
-				name = altname
-			}
+		name, _, skip := parseTag(&fields[i])
+		if skip {
+			continue fieldloop
 		}
 		dec.fields[name] = fieldDecoder{
 			index: index[0],
@@ -1086,7 +1103,7 @@ fieldloop:
 	}
 	self := func(st *Symtab, data []byte, dst reflect.Value) ([]byte, error) {
 		if TypeOf(data) != StructType {
-			return nil, fmt.Errorf("cannot unmarshal %s into %s", TypeOf(data), dst.Type())
+			return nil, bad(TypeOf(data), StructType, "Unmarshal")
 		}
 		body, rest := Contents(data)
 		for len(body) > 0 {
@@ -1103,6 +1120,15 @@ fieldloop:
 			f := dst.Field(dec.index)
 			body, err = dec.dec(st, val, f)
 			if err != nil {
+				var te *TypeError
+				if errors.As(err, &te) {
+					// record the path to the field
+					if te.Field == "" {
+						te.Field = name
+					} else {
+						te.Field = name + "." + te.Field
+					}
+				}
 				return nil, err
 			}
 		}
@@ -1202,7 +1228,7 @@ func decodeAny(st *Symtab, data []byte, dst reflect.Value) ([]byte, error) {
 
 func decodeList(st *Symtab, data []byte, inner decodefn, dst reflect.Value) ([]byte, error) {
 	if TypeOf(data) != ListType {
-		return nil, fmt.Errorf("cannot unmarshal %s into a slice", TypeOf(data))
+		return nil, bad(TypeOf(data), ListType, "Unmarshal")
 	}
 	slicetype := dst.Type()
 	elem := slicetype.Elem()
@@ -1266,7 +1292,7 @@ func decodeFunc(dst reflect.Type) (decodefn, bool) {
 				rest = r
 				i = int64(f)
 			default:
-				return nil, fmt.Errorf("bad ion type %s for unmarshaling into an integer", TypeOf(data))
+				return nil, bad(TypeOf(data), IntType, "Unmarshal")
 			}
 			if dst.OverflowInt(i) {
 				return nil, fmt.Errorf("ion value %d overflows type %s", i, dst.Type().String())
@@ -1274,7 +1300,7 @@ func decodeFunc(dst reflect.Type) (decodefn, bool) {
 			dst.SetInt(i)
 			return rest, nil
 		}, true
-	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return func(st *Symtab, data []byte, dst reflect.Value) ([]byte, error) {
 			var u uint64
 			var rest []byte
@@ -1307,7 +1333,7 @@ func decodeFunc(dst reflect.Type) (decodefn, bool) {
 				rest = r
 				u = uint64(f)
 			default:
-				return nil, fmt.Errorf("bad ion type %s for unmarshaling into an integer", TypeOf(data))
+				return nil, bad(TypeOf(data), UintType, "Unmarshal")
 			}
 			if dst.OverflowUint(u) {
 				return nil, fmt.Errorf("ion value %d overflows type %s", u, dst.Type().String())
@@ -1342,7 +1368,7 @@ func decodeFunc(dst reflect.Type) (decodefn, bool) {
 				rest = r
 				f = fv
 			default:
-				return nil, fmt.Errorf("bad ion type %s for unmarshaling into an float", TypeOf(data))
+				return nil, bad(TypeOf(data), FloatType, "Unmarshal")
 			}
 			dst.SetFloat(f)
 			return rest, nil
@@ -1360,7 +1386,7 @@ func decodeFunc(dst reflect.Type) (decodefn, bool) {
 				dst.Set(reflect.Zero(t))
 				return data[SizeOf(data):], nil
 			} else if dt != StructType {
-				return nil, fmt.Errorf("cannot ion.Unmarshal ion type %s into a map", dt)
+				return nil, bad(dt, StructType, "Unmarshal")
 			}
 			vt := t.Elem()
 			decoder, ok := decodeFunc(vt)
@@ -1436,7 +1462,7 @@ func decodeFunc(dst reflect.Type) (decodefn, bool) {
 				sym, rest, err = ReadSymbol(data)
 				str = st.Get(sym)
 			default:
-				err = fmt.Errorf("unexpected ion type %s for go string", TypeOf(data))
+				err = bad(TypeOf(data), StringType, "Unmarshal")
 			}
 			if err != nil {
 				return nil, err
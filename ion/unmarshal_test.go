@@ -667,3 +667,96 @@ func BenchmarkWriteTime(b *testing.B) {
 		buf.Reset()
 	}
 }
+
+func TestUnmarshalDatum(t *testing.T) {
+	type point struct {
+		X float64 `ion:"x"`
+		Y int     `ion:"y"`
+	}
+	type record struct {
+		Name    string     `ion:"name"`
+		Kind    string     `ion:"kind"`
+		Count   uint       `ion:"count"`
+		When    *time.Time `ion:"when"`
+		Inner   *point     `ion:"inner"`
+		Points  []point    `ion:"points"`
+		Grid    [][]int    `ion:"grid"`
+		Missing *point     `ion:"missing"`
+		Default int        `ion:"default"`
+	}
+	now := time.Date(2023, 1, 2, 3, 4, 5, 6000, time.UTC)
+	var st Symtab
+	d := NewStruct(&st, []Field{
+		{Label: "name", Datum: String("x")},
+		{Label: "kind", Datum: Interned(&st, "sym")},
+		{Label: "count", Datum: Int(3)},
+		{Label: "when", Datum: Timestamp(date.FromTime(now))},
+		{Label: "inner", Datum: NewStruct(&st, []Field{
+			{Label: "x", Datum: Int(2)},   // int into float
+			{Label: "y", Datum: Float(4)}, // integral float into int
+		}).Datum()},
+		{Label: "points", Datum: NewList(&st, []Datum{
+			NewStruct(&st, []Field{{Label: "x", Datum: Float(0.5)}}).Datum(),
+			NewStruct(&st, []Field{{Label: "y", Datum: Uint(7)}}).Datum(),
+		}).Datum()},
+		{Label: "grid", Datum: NewList(&st, []Datum{
+			NewList(&st, []Datum{Int(1), Int(2)}).Datum(),
+			NewList(&st, nil).Datum(),
+		}).Datum()},
+		{Label: "extra", Datum: String("ignored")},
+	}).Datum()
+
+	out := record{Default: 9}
+	if err := UnmarshalDatum(d, &out); err != nil {
+		t.Fatal(err)
+	}
+	want := record{
+		Name:    "x",
+		Kind:    "sym",
+		Count:   3,
+		When:    &now,
+		Inner:   &point{X: 2, Y: 4},
+		Points:  []point{{X: 0.5}, {Y: 7}},
+		Grid:    [][]int{{1, 2}, {}},
+		Default: 9,
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("got  %#v\nwant %#v", out, want)
+	}
+
+	// round-trip through MarshalDatum
+	d2, err := MarshalDatum(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out2 record
+	if err := UnmarshalDatum(d2, &out2); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out2, want) {
+		t.Errorf("got  %#v\nwant %#v", out2, want)
+	}
+
+	// type mismatches produce a *TypeError
+	// with the path to the field
+	bad := NewStruct(&st, []Field{
+		{Label: "inner", Datum: NewStruct(&st, []Field{
+			{Label: "y", Datum: String("four")},
+		}).Datum()},
+	}).Datum()
+	err = UnmarshalDatum(bad, &out)
+	var te *TypeError
+	if !errors.As(err, &te) {
+		t.Fatalf("expected a *TypeError; got %v", err)
+	}
+	if te.Field != "inner.y" || te.Found != StringType || te.Wanted != IntType {
+		t.Errorf("unexpected error %#v", te)
+	}
+	err = UnmarshalDatum(Int(-1), &out)
+	if !errors.As(err, &te) || te.Found != IntType || te.Wanted != StructType {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := UnmarshalDatum(Empty, &out); err == nil {
+		t.Error("expected an error unmarshaling an empty datum")
+	}
+}
@@ -59,7 +59,9 @@ type Buffer struct {
 // Set sets the buffer used by 'b'
 // and resets the state of the buffer.
 // Subsequent calls to Write* functions
-// on 'b' will append to the given buffer.
+// on 'b' will append to the given buffer,
+// so callers that want to overwrite p
+// should pass p[:0].
 func (b *Buffer) Set(p []byte) {
 	b.Reset()
 	b.buf = p
@@ -565,11 +567,42 @@ func (b *Buffer) WriteTruncatedTime(t date.Time, trunc TimeTrunc) {
 func (b *Buffer) Bytes() []byte { return b.buf }
 
 // Reset resets a buffer to its initial state.
+// Reset discards the contents of the buffer
+// and any open structures, lists, or annotations,
+// but it retains the capacity of the underlying
+// memory so that it can be reused.
+//
+// A Buffer does not hold any symbol table state;
+// the symbols referenced by data written after
+// Reset are determined entirely by the Symtab
+// passed to subsequent calls, so callers that
+// reuse a Symtab along with a Buffer should
+// call Symtab.Reset as well.
 func (b *Buffer) Reset() {
 	b.buf = b.buf[:0]
 	b.segs = b.segs[:0]
 }
 
+// Truncate discards all but the first n bytes
+// of the buffer, retaining the capacity of the
+// underlying memory. Truncate can be used along
+// with Size to rewind the buffer to the position
+// before a datum was written.
+//
+// Truncate panics if n is out of range or if there
+// are open calls to BeginStruct, BeginList, or
+// BeginAnnotation, since the positions of their
+// headers could not be preserved.
+func (b *Buffer) Truncate(n int) {
+	if len(b.segs) != 0 {
+		panic("ion.Buffer.Truncate: inside struct, list, or annotation")
+	}
+	if n < 0 || n > len(b.buf) {
+		panic("ion.Buffer.Truncate: out of range")
+	}
+	b.buf = b.buf[:n]
+}
+
 // Ok returns false if there are any
 // open calls to BeginStruct or BeginList
 // that have not been paired with
@@ -71,3 +71,80 @@ func TestWriteTruncatedTimeMatchesWriteTime(t *testing.T) {
 		}
 	}
 }
+
+func TestBufferReuse(t *testing.T) {
+	write := func(b *Buffer, st *Symtab) {
+		st.Intern("foo")
+		st.Intern("bar")
+		st.Marshal(b, true)
+		b.BeginStruct(-1)
+		b.BeginField(st.Intern("foo"))
+		b.WriteString("a string that is long enough to need a length field")
+		b.BeginField(st.Intern("bar"))
+		b.BeginList(-1)
+		b.WriteInt(-1)
+		b.WriteFloat64(3.5)
+		b.EndList()
+		b.EndStruct()
+	}
+	var fresh Buffer
+	var st Symtab
+	write(&fresh, &st)
+	want := fresh.Bytes()
+
+	var reused Buffer
+	var rst Symtab
+	for i := 0; i < 3; i++ {
+		// leave the buffer within an open
+		// structure before resetting it
+		rst.Intern("baz")
+		rst.Marshal(&reused, true)
+		reused.BeginStruct(-1)
+		reused.BeginField(rst.Intern("baz"))
+		reused.BeginList(-1)
+		reused.WriteString("xyz")
+		reused.Reset()
+		rst.Reset()
+
+		write(&reused, &rst)
+		if !reused.Ok() {
+			t.Fatalf("iter %d: buffer not ok", i)
+		}
+		if !bytes.Equal(reused.Bytes(), want) {
+			t.Fatalf("iter %d: got %x, want %x", i, reused.Bytes(), want)
+		}
+		reused.Reset()
+		rst.Reset()
+	}
+
+	// Truncate rewinds to a previous position
+	reused.Reset()
+	rst.Reset()
+	write(&reused, &rst)
+	size := reused.Size()
+	reused.WriteString("discarded")
+	reused.Truncate(size)
+	if !bytes.Equal(reused.Bytes(), want) {
+		t.Fatalf("after Truncate: got %x, want %x", reused.Bytes(), want)
+	}
+	reused.Truncate(0)
+	if reused.Size() != 0 {
+		t.Fatalf("Truncate(0) left %d bytes", reused.Size())
+	}
+	write(&reused, &rst)
+	if !bytes.Equal(reused.Bytes(), want) {
+		t.Fatalf("after Truncate(0): got %x, want %x", reused.Bytes(), want)
+	}
+
+	mustPanic := func(what string, fn func()) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: no panic", what)
+			}
+		}()
+		fn()
+	}
+	mustPanic("out of range", func() { reused.Truncate(reused.Size() + 1) })
+	reused.BeginStruct(-1)
+	mustPanic("open struct", func() { reused.Truncate(0) })
+}
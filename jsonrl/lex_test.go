@@ -344,6 +344,170 @@ func TestParseWithHints(t *testing.T) {
 	}
 }
 
+// TestCoerceHints tests ingesting a field
+// with mixed types using a type hint
+func TestCoerceHints(t *testing.T) {
+	input := `{"id": 0, "v": 1}
+{"id": 1, "v": "two"}
+{"id": 2, "v": 3.5}
+{"id": 3, "v": "4"}
+{"id": 4, "v": true}
+{"id": 5, "v": "2019-07-26T00:00:00Z"}
+{"id": 6, "v": null}
+`
+	var (
+		str     = ion.StringType
+		float   = ion.FloatType
+		missing = ion.InvalidType
+	)
+	testcases := []struct {
+		hints string
+		types []ion.Type // type of "v" in each record
+		err   bool
+	}{
+		{
+			hints: `{"v": "string"}`,
+			types: []ion.Type{str, str, str, str, str, str, ion.NullType},
+		},
+		{
+			// values that can't be coerced are kept as-is
+			hints: `{"v": "float"}`,
+			types: []ion.Type{float, str, float, float, ion.BoolType, ion.TimestampType, ion.NullType},
+		},
+		{
+			hints: `{"v": ["float", "missing_on_error"]}`,
+			types: []ion.Type{float, missing, float, float, missing, missing, ion.NullType},
+		},
+		{
+			hints: `{"v": ["string", "fail_on_error"]}`,
+			types: []ion.Type{str, str, str, str, str, str, ion.NullType},
+		},
+		{
+			hints: `{"v": ["float", "fail_on_error"]}`,
+			err:   true,
+		},
+		{
+			// bools are coerced to 0 or 1
+			hints: `{"v": ["int", "missing_on_error"]}`,
+			types: []ion.Type{ion.UintType, missing, missing, ion.UintType, ion.UintType, missing, ion.NullType},
+		},
+	}
+	for i := range testcases {
+		tc := &testcases[i]
+		t.Run(tc.hints, func(t *testing.T) {
+			h, err := ParseHint([]byte(tc.hints))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			cn := ion.Chunker{W: &out, Align: 4096}
+			err = Convert(strings.NewReader(input), &cn, h, nil)
+			if tc.err {
+				if !errors.Is(err, ErrCoerce) {
+					t.Fatalf("expected ErrCoerce; got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cn.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			var got []ion.Type
+			for _, d := range readRecords(t, out.Bytes()) {
+				if d.Field("id").IsEmpty() {
+					t.Fatalf("record without id: %v", d)
+				}
+				got = append(got, d.Field("v").Type())
+			}
+			if !reflect.DeepEqual(got, tc.types) {
+				t.Errorf("got types  %v", got)
+				t.Errorf("want types %v", tc.types)
+			}
+		})
+	}
+}
+
+// readRecords reads the structures
+// in the output of an ion.Chunker
+func readRecords(t *testing.T, mem []byte) []ion.Datum {
+	t.Helper()
+	var st ion.Symtab
+	var out []ion.Datum
+	for len(mem) > 0 {
+		var d ion.Datum
+		var err error
+		d, mem, err = ion.ReadDatum(&st, mem)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Type() == ion.StructType {
+			out = append(out, d.Clone())
+		}
+	}
+	return out
+}
+
+func TestConvertWithReject(t *testing.T) {
+	input := `{"a": 0}
+not json
+{"a": 1}
+{"a": 2,}
+{"a": 3
+{"a": 4}
+}
+{"a": "x"}
+{"a": 5}`
+	h, err := ParseHint([]byte(`{"a": ["int", "fail_on_error"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	cn := ion.Chunker{W: &out, Align: 4096}
+	var rejected []int
+	n, err := ConvertWithReject(strings.NewReader(input), &cn, h, nil, func(rec int, err error) error {
+		rejected = append(rejected, rec)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cn.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("got %d records, want 4", n)
+	}
+	if want := []int{1, 3, 4, 6, 7}; !reflect.DeepEqual(rejected, want) {
+		t.Errorf("rejected %v, want %v", rejected, want)
+	}
+	var got []int64
+	for _, d := range readRecords(t, out.Bytes()) {
+		i, err := d.Field("a").Int()
+		if err != nil {
+			t.Fatalf("%v: %s", d, err)
+		}
+		got = append(got, i)
+	}
+	if want := []int64{0, 1, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// an error from the reject function stops the conversion
+	stop := errors.New("stop")
+	cn = ion.Chunker{W: io.Discard, Align: 4096}
+	n, err = ConvertWithReject(strings.NewReader(input), &cn, h, nil, func(rec int, err error) error {
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("got error %v", err)
+	}
+	if n != 1 {
+		t.Errorf("got %d records before stopping, want 1", n)
+	}
+}
+
 func timestamp(s string) ion.Datum {
 	t, ok := date.Parse([]byte(s))
 	if !ok {
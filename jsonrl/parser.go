@@ -15,6 +15,7 @@
 package jsonrl
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -54,6 +55,10 @@ var (
 	// MaxObjectSize bytes of buffering in order
 	// for a complete object to be parsed.
 	ErrTooLarge = errors.New("jsonrl: object too large")
+	// ErrCoerce is returned from Convert
+	// when a value cannot be coerced to the
+	// type given by a hint with the fail_on_error action
+	ErrCoerce = errors.New("jsonrl: cannot coerce value")
 )
 
 type token int
@@ -402,6 +407,29 @@ func parseObject(st *state, in []byte) (int, error) {
 // MaxObjectDepth, MaxIndexingDepth), or if the object does not
 // fit in dst.Align after being serialized as ion data.
 func Convert(src io.Reader, dst *ion.Chunker, hints *Hint, cons []ion.Field) error {
+	_, err := ConvertWithReject(src, dst, hints, cons, nil)
+	return err
+}
+
+// A RejectFunc is called by ConvertWithReject
+// with the index and the conversion error of each
+// record that cannot be converted. If it returns nil,
+// the record is discarded and conversion continues
+// with the next record. Otherwise, conversion stops
+// and the returned error is passed on to the caller.
+type RejectFunc func(rec int, err error) error
+
+// ConvertWithReject is like Convert, but it calls
+// reject (if it is non-nil) for each record that
+// cannot be converted instead of failing immediately.
+// It returns the number of records written to dst.
+//
+// After a malformed record, conversion resumes
+// at the start of the next line of input, so
+// records are only skipped reliably in
+// newline-delimited JSON. Errors reading from
+// src are never passed to reject.
+func ConvertWithReject(src io.Reader, dst *ion.Chunker, hints *Hint, cons []ion.Field, reject RejectFunc) (int, error) {
 	st := newState(dst)
 	st.UseHints(hints)
 	tb := &parser{output: st, constants: cons}
@@ -410,15 +438,71 @@ func Convert(src io.Reader, dst *ion.Chunker, hints *Hint, cons []ion.Field) err
 		input: src,
 	}
 	rec := 0
+	rejected := 0
 	for {
+		in.chomp()
+		begin := in.consumed()
 		err := tb.parseTopLevel(in)
 		if err != nil {
-			return fmt.Errorf("object %d: %w", rec, err)
+			if reject == nil || in.err != nil {
+				return st.records, fmt.Errorf("object %d: %w", rec, err)
+			}
+			if err := reject(st.records+rejected, err); err != nil {
+				return st.records, err
+			}
+			rejected++
+			tb.reset()
+			// resume at the next line unless the
+			// error was detected at the start of
+			// a line (i.e. the record was truncated)
+			if in.consumed() == begin || !in.atLineStart() {
+				in.skipLine()
+			}
+			continue
 		}
 		if tb.tok == tokEOF {
 			break
 		}
 		rec++
 	}
-	return nil
+	return st.records, nil
+}
+
+// reset discards the partially-converted
+// record after a conversion error
+func (t *parser) reset() {
+	t.depth = 0
+	t.output.reset()
+}
+
+// atLineStart returns true if the only
+// bytes between the last newline and the
+// current position are whitespace
+func (b *reader) atLineStart() bool {
+	for i := b.rpos - 1; i >= 0; i-- {
+		switch b.buf[i] {
+		case '\n':
+			return true
+		case '\r', ' ', '\f', '\v', '\t':
+			continue
+		}
+		return false
+	}
+	return false
+}
+
+// skipLine discards input up to
+// and including the next newline
+func (b *reader) skipLine() {
+	for {
+		if i := bytes.IndexByte(b.avail(), '\n'); i >= 0 {
+			b.rpos += i + 1
+			return
+		}
+		b.rpos = len(b.buf)
+		if b.atEOF || b.err != nil {
+			return
+		}
+		b.fill()
+	}
 }
@@ -57,10 +57,20 @@ const (
 	hintUnixMicroSeconds
 	hintUnixNanoSeconds
 
+	hintFloat
+
 	hintIgnore
 	hintNoIndex
+	hintMissingOnError
+	hintFailOnError
 )
 
+// hintTypes are the hints that
+// declare the type of a value
+const hintTypes = hintString | hintNumber | hintInt | hintBool | hintDateTime |
+	hintUnixSeconds | hintUnixMilliSeconds | hintUnixMicroSeconds | hintUnixNanoSeconds |
+	hintFloat
+
 var (
 	hintStrings = map[hints]string{
 		hintDefault:          "default",
@@ -73,8 +83,11 @@ var (
 		hintUnixMilliSeconds: "unix_milli_seconds",
 		hintUnixMicroSeconds: "unix_micro_seconds",
 		hintUnixNanoSeconds:  "unix_nano_seconds",
+		hintFloat:            "float",
 		hintIgnore:           "ignore",
 		hintNoIndex:          "no_index",
+		hintMissingOnError:   "missing_on_error",
+		hintFailOnError:      "fail_on_error",
 	}
 	hintValues = reverseMap(hintStrings)
 )
@@ -150,14 +163,25 @@ type Hint struct {
 // Supported actions:
 //   - `ignore` -> do not parse this property
 //   - `no_index` -> do not add this property to the sparse index
+//   - `missing_on_error` -> omit values that cannot be coerced to the hinted type
+//   - `fail_on_error` -> fail the conversion when a value cannot be coerced to the hinted type
 //
 // Supported hints:
 //   - string
 //   - number -> either float or int
 //   - int
+//   - float -> always float
 //   - bool
 //   - datetime -> RFC3339Nano
 //   - unix_seconds
+//   - unix_milli_seconds
+//   - unix_micro_seconds
+//   - unix_nano_seconds
+//
+// By default, a scalar value that cannot be coerced to the
+// hinted type (for example, the string "abc" with the `int` hint)
+// is written as if it had no hint. Null values and
+// structures are never coerced.
 func ParseHint(rules []byte) (hint *Hint, err error) {
 	hint = &Hint{}
 	err = json.Unmarshal(rules, hint)
@@ -607,7 +631,10 @@ func (s *hintState) leave() {
 	if s.current.parent != nil {
 		s.current = s.current.parent
 	} else {
+		// leaving the top-level record;
+		// the next record starts at the root
 		s.level = -1
+		s.next = nil
 	}
 }
 
@@ -665,6 +692,18 @@ type state struct {
 
 	hints hintState
 
+	// label is the field label that is held
+	// back while a value that may be
+	// omitted (see hintMissingOnError) is parsed
+	label    ion.Symbol
+	deferred bool
+
+	// err is the first coercion
+	// failure (see hintFailOnError)
+	err error
+
+	records int // number of committed records
+
 	constResolved bool
 }
 
@@ -686,6 +725,50 @@ func (s *state) shouldNotIndex() bool {
 	return s.hints.hints&hintNoIndex != 0
 }
 
+func (s *state) coerceFloat() bool {
+	return s.hints.hints&hintFloat != 0
+}
+
+func (s *state) coerceBool() bool {
+	return s.hints.hints&hintBool != 0
+}
+
+func (s *state) coerceUnix() bool {
+	return s.hints.hints&(hintUnixSeconds|hintUnixMilliSeconds|hintUnixMicroSeconds|hintUnixNanoSeconds) != 0
+}
+
+// beginValue writes the field label
+// held back by beginField, if any
+func (s *state) beginValue() {
+	if s.deferred {
+		s.out.BeginField(s.label)
+		s.deferred = false
+	}
+}
+
+// mismatch is invoked when a value of the given
+// type cannot be coerced to the hinted type.
+// It returns true if the value should be written
+// without coercion, or false if it should be omitted.
+func (s *state) mismatch(typ string) bool {
+	h := s.hints.hints
+	if h&hintMissingOnError != 0 {
+		s.deferred = false
+		s.after()
+		return false
+	}
+	if h&hintFailOnError != 0 && s.err == nil {
+		if s.flags&flagField != 0 && len(s.stack) > 0 {
+			s.err = fmt.Errorf("%w: field %q: %s value is not %s",
+				ErrCoerce, s.out.Symbols.Get(s.stack[len(s.stack)-1]), typ, h&hintTypes)
+		} else {
+			s.err = fmt.Errorf("%w: %s value is not %s", ErrCoerce, typ, h&hintTypes)
+		}
+	}
+	s.beginValue()
+	return true
+}
+
 func (s *state) coerceString() bool {
 	return s.hints.hints&hintString != 0
 }
@@ -722,7 +805,26 @@ func (s *state) Commit() error {
 	if len(s.stack) != 0 {
 		return fmt.Errorf("state.Commit inside object?")
 	}
-	return s.out.Commit()
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.out.Commit(); err != nil {
+		return err
+	}
+	s.records++
+	return nil
+}
+
+// reset discards the record that
+// is currently being converted
+func (s *state) reset() {
+	s.out.Abort()
+	s.stack = s.stack[:0]
+	s.flags = 0
+	s.oldflags = s.oldflags[:0]
+	s.hints = makeHintState(s.hints.root)
+	s.deferred = false
+	s.err = nil
 }
 
 // adjust the parser state after each
@@ -759,7 +861,11 @@ func (s *state) parseInt(i int64) {
 	if s.shouldIgnore() {
 		return
 	}
+	if s.hints.hints&(hintBool|hintDateTime) != 0 && !s.mismatch("int") {
+		return
+	}
 
+	s.beginValue()
 	if s.coerceString() {
 		v := strconv.Itoa(int(i))
 		s.out.WriteString(v)
@@ -779,6 +885,8 @@ func (s *state) parseInt(i int64) {
 		t := date.Unix(i/1e9, i%1e9)
 		s.addTimeRange(t)
 		s.out.WriteTime(t)
+	} else if s.coerceFloat() {
+		s.out.WriteFloat64(float64(i))
 	} else {
 		s.out.WriteInt(i)
 	}
@@ -870,10 +978,24 @@ func (s *state) parseFloat(f float64) {
 	if s.shouldIgnore() {
 		return
 	}
+	if (s.coerceI64() || s.coerceUnix()) && !s.coerceString() {
+		if i := int64(f); float64(i) == f {
+			s.parseInt(i)
+			return
+		}
+		if !s.mismatch("float") {
+			return
+		}
+	} else if s.hints.hints&(hintBool|hintDateTime) != 0 && !s.mismatch("float") {
+		return
+	}
 
+	s.beginValue()
 	if s.coerceString() {
 		v := strconv.FormatFloat(f, 'f', -1, 32)
 		s.out.WriteString(v)
+	} else if s.coerceFloat() {
+		s.out.WriteFloat64(f)
 	} else {
 		// emit the core-normalized representation of f
 		if i := int64(f); float64(i) == f {
@@ -911,6 +1033,7 @@ func (s *state) beginRecord() {
 		return
 	}
 
+	s.beginValue()
 	s.pushRecord()
 	s.pushFlags(flagInRecord)
 	s.out.BeginStruct(-1)
@@ -995,6 +1118,12 @@ func (s *state) beginField(label []byte, esc bool) {
 	}
 	s.stack[len(s.stack)-1] = sym
 	s.flags |= flagField
+	if s.hints.hints&hintMissingOnError != 0 {
+		// the value may be omitted
+		s.label = sym
+		s.deferred = true
+		return
+	}
 	s.out.BeginField(sym)
 }
 
@@ -1006,6 +1135,7 @@ func (s *state) beginList() {
 		return
 	}
 
+	s.beginValue()
 	s.pushFlags(flagInList)
 	s.out.BeginList(-1)
 }
@@ -1026,7 +1156,13 @@ func (s *state) parseBool(b bool) {
 	if s.shouldIgnore() {
 		return
 	}
+	if s.hints.hints&(hintNumber|hintFloat|hintDateTime) != 0 || s.coerceUnix() {
+		if !s.coerceString() && !s.coerceI64() && !s.coerceBool() && !s.mismatch("bool") {
+			return
+		}
+	}
 
+	s.beginValue()
 	if s.coerceString() {
 		if b {
 			s.out.WriteString("true")
@@ -1051,6 +1187,7 @@ func (s *state) parseNull() {
 		return
 	}
 
+	s.beginValue()
 	s.out.WriteNull()
 	s.after()
 }
@@ -1066,9 +1203,14 @@ func (s *state) parseString(seg []byte, esc bool) {
 
 	emitDefault := true
 
-	if s.coerceNumber() {
+	if s.coerceString() {
+		emitDefault = false
+		s.beginValue()
+		s.out.WriteStringBytes(seg)
+	} else if s.coerceNumber() {
 		if f, err := strconv.ParseFloat(string(seg), 64); err == nil {
 			emitDefault = false
+			s.beginValue()
 			// emit the core-normalized representation of f
 			if i := int64(f); float64(i) == f {
 				s.out.WriteInt(i)
@@ -1076,20 +1218,38 @@ func (s *state) parseString(seg []byte, esc bool) {
 				s.out.WriteFloat64(f)
 			}
 		}
+	} else if s.coerceFloat() {
+		if f, err := strconv.ParseFloat(string(seg), 64); err == nil {
+			emitDefault = false
+			s.beginValue()
+			s.out.WriteFloat64(f)
+		}
 	} else if s.coerceI64() {
 		if i, err := strconv.Atoi(string(seg)); err == nil {
 			emitDefault = false
+			s.beginValue()
 			s.out.WriteInt(int64(i))
 		}
 	} else if s.coerceDateTime() {
 		if t, ok := date.Parse(seg); ok {
 			emitDefault = false
+			s.beginValue()
 			s.addTimeRange(t)
 			s.out.WriteTime(t)
 		}
+	} else if s.coerceBool() {
+		if b, err := strconv.ParseBool(string(seg)); err == nil {
+			emitDefault = false
+			s.beginValue()
+			s.out.WriteBool(b)
+		}
+	}
+	if emitDefault && s.hints.hints&hintTypes != 0 && !s.mismatch("string") {
+		return
 	}
 
 	if emitDefault {
+		s.beginValue()
 		if t, ok := date.Parse(seg); ok {
 			s.addTimeRange(t)
 			s.out.WriteTime(t)
@@ -0,0 +1,320 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+
+	"github.com/SnellerInc/sneller/compr"
+
+	"github.com/klauspost/compress/s2"
+)
+
+// maxPageSize is the largest (uncompressed)
+// page that we are willing to decode
+const maxPageSize = 1 << 30
+
+// column is the decoded contents of
+// one column chunk of a row group
+type column struct {
+	leaf *node
+	// rep and def are the repetition and
+	// definition levels of each entry;
+	// they are nil when the maximum level is zero,
+	// in which case every level is zero
+	rep, def []int32
+	vals     values
+	dict     *values
+	n        int // number of entries
+	// cursors into the levels and values
+	pos, vpos int
+
+	page page
+	buf  []byte // raw column chunk
+}
+
+func (c *column) reset() {
+	c.rep = c.rep[:0]
+	c.def = c.def[:0]
+	c.vals.reset()
+	c.dict = nil
+	c.n = 0
+	c.pos = 0
+	c.vpos = 0
+}
+
+func (c *column) defAt() int32 {
+	if c.def == nil {
+		return 0
+	}
+	return c.def[c.pos]
+}
+
+func (c *column) repAt() int32 {
+	if c.rep == nil {
+		return 0
+	}
+	return c.rep[c.pos]
+}
+
+func decompress(codec int32, src []byte, size int) ([]byte, error) {
+	if size < 0 || size > maxPageSize {
+		return nil, fmt.Errorf("parquet: invalid page size %d", size)
+	}
+	var dst []byte
+	var err error
+	switch codec {
+	case codecUncompressed:
+		dst = src
+	case codecSnappy:
+		// s2 decodes snappy blocks
+		dst, err = s2.Decode(make([]byte, size), src)
+	case codecGzip:
+		var zr *gzip.Reader
+		zr, err = gzip.NewReader(bytes.NewReader(src))
+		if err == nil {
+			dst = make([]byte, size)
+			_, err = io.ReadFull(zr, dst)
+		}
+	case codecZstd:
+		dst, err = compr.DecodeZstd(src, make([]byte, 0, size))
+	default:
+		return nil, fmt.Errorf("parquet: unsupported compression codec %d", codec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parquet: decompressing page: %w", err)
+	}
+	if len(dst) != size {
+		return nil, fmt.Errorf("parquet: page decompressed to %d bytes instead of %d", len(dst), size)
+	}
+	return dst, nil
+}
+
+// levels decodes n levels of at most max
+// from a data page (v1) with the given encoding
+// and returns the rest of the page
+func levels(enc int32, buf []byte, max int, n int, dst []int32) ([]int32, []byte, error) {
+	w := bits.Len(uint(max))
+	switch enc {
+	case encRLE:
+		if len(buf) < 4 {
+			return dst, nil, errCorrupt
+		}
+		size := binary.LittleEndian.Uint32(buf)
+		if uint64(size) > uint64(len(buf)-4) {
+			return dst, nil, errCorrupt
+		}
+		dst, err := hybrid(buf[4:4+size], w, n, dst)
+		return dst, buf[4+size:], err
+	case encBitPacked:
+		return bitpacked(buf, w, n, dst)
+	default:
+		return dst, nil, fmt.Errorf("parquet: unsupported level encoding %d", enc)
+	}
+}
+
+// checkLevels ensures that all of the
+// levels in lvl are at most max
+func checkLevels(lvl []int32, max int) error {
+	for _, l := range lvl {
+		if l < 0 || int(l) > max {
+			return fmt.Errorf("parquet: level %d exceeds maximum %d", l, max)
+		}
+	}
+	return nil
+}
+
+// present returns the number of non-null
+// values in the last n entries of c
+func (c *column) present(n int) int {
+	if c.def == nil {
+		return n
+	}
+	k := 0
+	max := int32(c.leaf.def)
+	for _, d := range c.def[len(c.def)-n:] {
+		if d == max {
+			k++
+		}
+	}
+	return k
+}
+
+// read reads and decodes the column chunk cc
+func (c *column) read(r io.ReaderAt, size int64, cc *columnChunk) error {
+	c.reset()
+	if cc.filePath != "" {
+		return fmt.Errorf("parquet: column chunks in external files (%s) are not supported", cc.filePath)
+	}
+	m := &cc.meta
+	if int(m.typ) != int(c.leaf.elem.typ) {
+		return fmt.Errorf("parquet: column %v has type %d instead of %d", m.path, m.typ, c.leaf.elem.typ)
+	}
+	start := m.dataPageOffset
+	if m.hasDictionaryPage && m.dictionaryPageOffset > 0 && m.dictionaryPageOffset < start {
+		start = m.dictionaryPageOffset
+	}
+	if start < 4 || m.totalCompressedSize < 0 || start+m.totalCompressedSize > size {
+		return fmt.Errorf("parquet: column %v has invalid offset %d and size %d", m.path, start, m.totalCompressedSize)
+	}
+	if cap(c.buf) < int(m.totalCompressedSize) {
+		c.buf = make([]byte, m.totalCompressedSize)
+	}
+	buf := c.buf[:m.totalCompressedSize]
+	if _, err := r.ReadAt(buf, start); err != nil {
+		return err
+	}
+	if c.leaf.rep > 0 && c.rep == nil {
+		c.rep = make([]int32, 0, 1024)
+	}
+	if c.leaf.def > 0 && c.def == nil {
+		c.def = make([]int32, 0, 1024)
+	}
+	c.page = page{typ: m.typ, typeLength: int(c.leaf.elem.typeLength)}
+	for int64(c.n) < m.numValues {
+		if len(buf) == 0 {
+			return fmt.Errorf("parquet: column %v: found %d of %d values", m.path, c.n, m.numValues)
+		}
+		var ph pageHeader
+		d := decoder{buf: buf}
+		d.pageHeader(&ph)
+		if d.err != nil {
+			return d.err
+		}
+		buf = d.buf
+		if ph.compressedSize < 0 || int(ph.compressedSize) > len(buf) {
+			return fmt.Errorf("parquet: column %v: invalid page size %d", m.path, ph.compressedSize)
+		}
+		body := buf[:ph.compressedSize]
+		buf = buf[ph.compressedSize:]
+		var err error
+		switch ph.typ {
+		case pageDictionary:
+			err = c.readDict(m.codec, &ph, body)
+		case pageData:
+			err = c.readData(m.codec, &ph, body)
+		case pageDataV2:
+			err = c.readDataV2(m.codec, &ph, body)
+		}
+		if err != nil {
+			return fmt.Errorf("parquet: column %v: %w", m.path, err)
+		}
+	}
+	return nil
+}
+
+func (c *column) readDict(codec int32, ph *pageHeader, body []byte) error {
+	data, err := decompress(codec, body, int(ph.uncompressedSize))
+	if err != nil {
+		return err
+	}
+	if enc := ph.dict.encoding; enc != encPlain && enc != encPlainDictionary {
+		return fmt.Errorf("unsupported dictionary encoding %d", enc)
+	}
+	if ph.dict.numValues < 0 {
+		return errCorrupt
+	}
+	c.dict = new(values)
+	return c.page.plain(data, int(ph.dict.numValues), c.dict)
+}
+
+func (c *column) readData(codec int32, ph *pageHeader, body []byte) error {
+	data, err := decompress(codec, body, int(ph.uncompressedSize))
+	if err != nil {
+		return err
+	}
+	n := int(ph.data.numValues)
+	if n < 0 {
+		return errCorrupt
+	}
+	if c.rep != nil {
+		c.rep, data, err = levels(ph.data.repEncoding, data, c.leaf.rep, n, c.rep)
+		if err != nil {
+			return err
+		}
+	}
+	if c.def != nil {
+		c.def, data, err = levels(ph.data.defEncoding, data, c.leaf.def, n, c.def)
+		if err != nil {
+			return err
+		}
+	}
+	return c.values(ph.data.encoding, data, n)
+}
+
+func (c *column) readDataV2(codec int32, ph *pageHeader, body []byte) error {
+	h := &ph.data2
+	n := int(h.numValues)
+	if n < 0 || h.repLength < 0 || h.defLength < 0 || int(h.repLength)+int(h.defLength) > len(body) {
+		return errCorrupt
+	}
+	replvl := body[:h.repLength]
+	deflvl := body[h.repLength : h.repLength+h.defLength]
+	data := body[h.repLength+h.defLength:]
+	var err error
+	if c.rep != nil {
+		c.rep, err = hybrid(replvl, bits.Len(uint(c.leaf.rep)), n, c.rep)
+		if err != nil {
+			return err
+		}
+	}
+	if c.def != nil {
+		c.def, err = hybrid(deflvl, bits.Len(uint(c.leaf.def)), n, c.def)
+		if err != nil {
+			return err
+		}
+	}
+	if h.compressed {
+		data, err = decompress(codec, data, int(ph.uncompressedSize)-int(h.repLength)-int(h.defLength))
+		if err != nil {
+			return err
+		}
+	}
+	return c.values(h.encoding, data, n)
+}
+
+// values decodes the values of a data page
+// with n entries, whose levels have already
+// been decoded
+func (c *column) values(enc int32, data []byte, n int) error {
+	if c.rep != nil {
+		if err := checkLevels(c.rep[len(c.rep)-n:], c.leaf.rep); err != nil {
+			return err
+		}
+		if c.n == 0 && n > 0 && c.rep[0] != 0 {
+			return fmt.Errorf("first repetition level is %d", c.rep[0])
+		}
+	}
+	if c.def != nil {
+		if err := checkLevels(c.def[len(c.def)-n:], c.leaf.def); err != nil {
+			return err
+		}
+	}
+	k := c.present(n)
+	before := c.vals.len()
+	if err := c.page.decode(enc, data, k, c.dict, &c.vals); err != nil {
+		return err
+	}
+	if c.vals.len()-before != k {
+		return fmt.Errorf("decoded %d values instead of %d", c.vals.len()-before, k)
+	}
+	c.n += n
+	return nil
+}
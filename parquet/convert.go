@@ -0,0 +1,283 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SnellerInc/sneller/date"
+
+	"github.com/google/uuid"
+)
+
+// ways of writing primitive values
+const (
+	wBool = iota
+	wInt32
+	wUint32
+	wInt64
+	wUint64
+	wFloat32
+	wFloat64
+	wString
+	wAuto // string if valid UTF-8, blob otherwise
+	wBlob
+	wDate
+	wTimestamp
+	wInt96
+	wDecimal
+	wUUID
+	wFloat16
+)
+
+// julianEpoch is the julian day
+// number of 1970-01-01
+const julianEpoch = 2440588
+
+// convert describes how the values
+// of a leaf column are written
+type convert struct {
+	kind  int
+	scale int   // for decimals
+	unit  int16 // for timestamps
+}
+
+func (c convert) isString() bool { return c.kind == wString || c.kind == wAuto }
+
+func (c convert) isTime() bool {
+	return c.kind == wDate || c.kind == wTimestamp || c.kind == wInt96
+}
+
+func (n *node) setConv() error {
+	el := n.elem
+	l := &el.logical
+	ct := el.convertedType
+	decimal := l.kind == logicalDecimal || ct == convDecimal
+	scale := int(el.scale)
+	if l.kind == logicalDecimal {
+		scale = int(l.scale)
+	}
+	if decimal && (scale < 0 || scale > 38) {
+		return fmt.Errorf("parquet: column %q has invalid decimal scale %d", el.name, scale)
+	}
+	unsigned := (l.kind == logicalInteger && !l.signed) ||
+		(ct >= convUint8 && ct <= convUint64)
+	c := &n.conv
+	switch el.typ {
+	case typeBoolean:
+		c.kind = wBool
+	case typeInt32:
+		switch {
+		case l.kind == logicalDate || ct == convDate:
+			c.kind = wDate
+		case decimal:
+			c.kind, c.scale = wDecimal, scale
+		case unsigned:
+			c.kind = wUint32
+		default:
+			c.kind = wInt32
+		}
+	case typeInt64:
+		switch {
+		case l.kind == logicalTimestamp:
+			c.kind, c.unit = wTimestamp, l.unit
+		case ct == convTimestampMillis:
+			c.kind, c.unit = wTimestamp, unitMillis
+		case ct == convTimestampMicros:
+			c.kind, c.unit = wTimestamp, unitMicros
+		case decimal:
+			c.kind, c.scale = wDecimal, scale
+		case unsigned:
+			c.kind = wUint64
+		default:
+			c.kind = wInt64
+		}
+		if c.kind == wTimestamp && (c.unit < unitMillis || c.unit > unitNanos) {
+			return fmt.Errorf("parquet: column %q has invalid time unit %d", el.name, c.unit)
+		}
+	case typeInt96:
+		c.kind = wInt96
+	case typeFloat:
+		c.kind = wFloat32
+	case typeDouble:
+		c.kind = wFloat64
+	case typeByteArray:
+		switch {
+		case l.kind == logicalString || l.kind == logicalEnum || l.kind == logicalJSON ||
+			ct == convUTF8 || ct == convEnum || ct == convJSON:
+			c.kind = wString
+		case decimal:
+			c.kind, c.scale = wDecimal, scale
+		case l.kind == logicalNone && ct == convNone:
+			c.kind = wAuto
+		default:
+			c.kind = wBlob
+		}
+	case typeFixedLenByteArray:
+		switch {
+		case l.kind == logicalUUID && el.typeLength == 16:
+			c.kind = wUUID
+		case l.kind == logicalFloat16 && el.typeLength == 2:
+			c.kind = wFloat16
+		case decimal:
+			c.kind, c.scale = wDecimal, scale
+		default:
+			c.kind = wBlob
+		}
+		if el.typeLength < 0 {
+			return fmt.Errorf("parquet: column %q has invalid length %d", el.name, el.typeLength)
+		}
+	default:
+		return fmt.Errorf("parquet: column %q has unknown type %d", el.name, el.typ)
+	}
+	return nil
+}
+
+// write writes value i of the column of n
+func (c convert) write(a *assembler, n *node, i int) {
+	dst := a.dst
+	v := &n.leaves[0].vals
+	switch c.kind {
+	case wBool:
+		dst.WriteBool(v.bools[i])
+	case wInt32:
+		dst.WriteInt(int64(v.i32[i]))
+	case wUint32:
+		dst.WriteUint(uint64(uint32(v.i32[i])))
+	case wInt64:
+		dst.WriteInt(v.i64[i])
+	case wUint64:
+		dst.WriteUint(uint64(v.i64[i]))
+	case wFloat32:
+		dst.WriteFloat32(v.f32[i])
+	case wFloat64:
+		dst.WriteFloat64(v.f64[i])
+	case wString:
+		dst.WriteStringBytes(v.bytes[i])
+	case wAuto:
+		if utf8.Valid(v.bytes[i]) {
+			dst.WriteStringBytes(v.bytes[i])
+		} else {
+			dst.WriteBlob(v.bytes[i])
+		}
+	case wBlob:
+		dst.WriteBlob(v.bytes[i])
+	case wDate:
+		a.time(n, date.Unix(int64(v.i32[i])*86400, 0))
+	case wTimestamp:
+		t := v.i64[i]
+		switch c.unit {
+		case unitMillis:
+			a.time(n, date.FromTime(time.UnixMilli(t)))
+		case unitMicros:
+			a.time(n, date.UnixMicro(t))
+		default:
+			a.time(n, date.Unix(0, t))
+		}
+	case wInt96:
+		b := v.bytes[i]
+		ns := int64(binary.LittleEndian.Uint64(b))
+		day := int64(binary.LittleEndian.Uint32(b[8:]))
+		a.time(n, date.Unix((day-julianEpoch)*86400, ns))
+	case wDecimal:
+		c.decimal(a, n.elem.typ, v, i)
+	case wUUID:
+		dst.WriteString(uuid.UUID(v.bytes[i]).String())
+	case wFloat16:
+		dst.WriteFloat32(float16(binary.LittleEndian.Uint16(v.bytes[i])))
+	}
+}
+
+func (a *assembler) time(n *node, t date.Time) {
+	a.dst.WriteTime(t)
+	if n.ranged {
+		a.dst.Ranges.AddTime(n.symbuf, t)
+	}
+}
+
+func (c convert) decimal(a *assembler, typ int32, v *values, i int) {
+	var unscaled int64
+	switch typ {
+	case typeInt32:
+		unscaled = int64(v.i32[i])
+	case typeInt64:
+		unscaled = v.i64[i]
+	default:
+		b := v.bytes[i]
+		if len(b) > 8 {
+			a.bigDecimal(b, c.scale)
+			return
+		}
+		// sign-extend the big-endian
+		// two's complement value
+		for j := range b {
+			if j == 0 {
+				unscaled = int64(int8(b[0]))
+			} else {
+				unscaled = unscaled<<8 | int64(b[j])
+			}
+		}
+	}
+	if c.scale == 0 {
+		a.dst.WriteInt(unscaled)
+		return
+	}
+	a.dst.WriteFloat64(float64(unscaled) / math.Pow10(c.scale))
+}
+
+func (a *assembler) bigDecimal(b []byte, scale int) {
+	u := new(big.Int).SetBytes(b)
+	if b[0]&0x80 != 0 {
+		// two's complement
+		u.Sub(u, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	if scale == 0 && u.IsInt64() {
+		a.dst.WriteInt(u.Int64())
+		return
+	}
+	f := new(big.Float).SetInt(u)
+	if scale > 0 {
+		f.Quo(f, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	}
+	r, _ := f.Float64()
+	a.dst.WriteFloat64(r)
+}
+
+// float16 converts an IEEE 754 half-precision float
+func float16(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+	switch {
+	case exp == 0x1f:
+		// infinity or NaN
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	case exp == 0 && frac == 0:
+		return math.Float32frombits(sign)
+	case exp == 0:
+		// subnormal
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | frac<<13)
+}
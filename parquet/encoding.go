@@ -0,0 +1,489 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var errCorrupt = errors.New("parquet: corrupt page data")
+
+// values holds the decoded values of a column;
+// only the slice that corresponds to the physical
+// type of the column is populated
+type values struct {
+	bools []bool
+	i32   []int32
+	i64   []int64
+	f32   []float32
+	f64   []float64
+	// BYTE_ARRAY, FIXED_LEN_BYTE_ARRAY and INT96
+	bytes [][]byte
+}
+
+func (v *values) reset() {
+	v.bools = v.bools[:0]
+	v.i32 = v.i32[:0]
+	v.i64 = v.i64[:0]
+	v.f32 = v.f32[:0]
+	v.f64 = v.f64[:0]
+	v.bytes = v.bytes[:0]
+}
+
+func (v *values) len() int {
+	return len(v.bools) + len(v.i32) + len(v.i64) + len(v.f32) + len(v.f64) + len(v.bytes)
+}
+
+// getbits returns the w-bit little-endian
+// value starting at bit offset 'bit' of buf
+func getbits(buf []byte, bit int, w int) uint64 {
+	u := uint64(0)
+	for k := 0; k < w; {
+		b := buf[bit>>3] >> (bit & 7)
+		take := 8 - (bit & 7)
+		if take > w-k {
+			take = w - k
+		}
+		u |= uint64(b&(1<<take-1)) << k
+		k += take
+		bit += take
+	}
+	return u
+}
+
+// hybrid decodes n values of width w encoded
+// with the RLE/bit-packing hybrid encoding
+// and appends them to dst
+func hybrid(buf []byte, w int, n int, dst []int32) ([]int32, error) {
+	if w < 0 || w > 32 {
+		return dst, fmt.Errorf("parquet: invalid bit width %d", w)
+	}
+	for n > 0 {
+		h, k := binary.Uvarint(buf)
+		if k <= 0 {
+			return dst, errCorrupt
+		}
+		buf = buf[k:]
+		if h&1 == 0 {
+			// repeated run
+			count := h >> 1
+			size := (w + 7) / 8
+			if len(buf) < size {
+				return dst, errCorrupt
+			}
+			u := uint32(0)
+			for i := 0; i < size; i++ {
+				u |= uint32(buf[i]) << (8 * i)
+			}
+			buf = buf[size:]
+			if count > uint64(n) {
+				count = uint64(n)
+			}
+			for i := uint64(0); i < count; i++ {
+				dst = append(dst, int32(u))
+			}
+			n -= int(count)
+			continue
+		}
+		// bit-packed run of groups of 8 values;
+		// the last run may be padded beyond n values
+		groups := h >> 1
+		if w > 0 && groups > uint64(len(buf)) {
+			return dst, errCorrupt
+		}
+		size := int(groups) * w
+		if len(buf) < size {
+			return dst, errCorrupt
+		}
+		count := n
+		if groups < uint64(n+7)/8 {
+			count = int(groups) * 8
+		}
+		for i := 0; i < count; i++ {
+			dst = append(dst, int32(getbits(buf, i*w, w)))
+		}
+		buf = buf[size:]
+		n -= count
+	}
+	return dst, nil
+}
+
+// bitpacked decodes n values of width w
+// packed from the most significant bit
+// (the deprecated BIT_PACKED level encoding)
+// and returns the remaining data
+func bitpacked(buf []byte, w int, n int, dst []int32) ([]int32, []byte, error) {
+	size := (n*w + 7) / 8
+	if len(buf) < size {
+		return dst, nil, errCorrupt
+	}
+	for i := 0; i < n; i++ {
+		u := uint32(0)
+		for j := 0; j < w; j++ {
+			bit := i*w + j
+			u = u<<1 | uint32(buf[bit>>3]>>(7-bit&7))&1
+		}
+		dst = append(dst, int32(u))
+	}
+	return dst, buf[size:], nil
+}
+
+// maxDeltaBlock is the largest DELTA_BINARY_PACKED
+// block size that we accept
+const maxDeltaBlock = 1 << 20
+
+// deltaBinaryPacked decodes a DELTA_BINARY_PACKED
+// sequence of at most max values and appends the
+// values to dst; it returns the data following
+// the sequence
+func deltaBinaryPacked(buf []byte, max int, dst []int64) ([]int64, []byte, error) {
+	d := decoder{buf: buf}
+	blockSize := d.uvarint()
+	miniBlocks := d.uvarint()
+	total := d.uvarint()
+	first := d.varint()
+	if d.err != nil {
+		return dst, nil, errCorrupt
+	}
+	if miniBlocks == 0 || blockSize > maxDeltaBlock ||
+		blockSize%miniBlocks != 0 || (blockSize/miniBlocks)%8 != 0 {
+		return dst, nil, fmt.Errorf("parquet: invalid delta block size %d/%d", blockSize, miniBlocks)
+	}
+	if total > uint64(max) {
+		return dst, nil, errCorrupt
+	}
+	if total == 0 {
+		return dst, d.buf, nil
+	}
+	per := int(blockSize / miniBlocks)
+	buf = d.buf
+	prev := first
+	dst = append(dst, prev)
+	remaining := total - 1
+	for remaining > 0 {
+		d.buf = buf
+		minDelta := d.varint()
+		if d.err != nil || uint64(len(d.buf)) < miniBlocks {
+			return dst, nil, errCorrupt
+		}
+		widths := d.buf[:miniBlocks]
+		buf = d.buf[miniBlocks:]
+		for i := range widths {
+			if remaining == 0 {
+				break
+			}
+			w := int(widths[i])
+			if w > 64 {
+				return dst, nil, fmt.Errorf("parquet: invalid delta bit width %d", w)
+			}
+			size := per * w / 8
+			if len(buf) < size {
+				return dst, nil, errCorrupt
+			}
+			for j := 0; j < per && remaining > 0; j++ {
+				prev = int64(uint64(prev) + uint64(minDelta) + getbits(buf, j*w, w))
+				dst = append(dst, prev)
+				remaining--
+			}
+			buf = buf[size:]
+		}
+	}
+	return dst, buf, nil
+}
+
+// page is the state of a data page
+// while its values are being decoded
+type page struct {
+	typ        int32 // physical type
+	typeLength int
+	// scratch buffers
+	ints    []int32
+	longs   []int64
+	lengths []int64
+}
+
+// plain decodes n PLAIN-encoded values
+func (p *page) plain(buf []byte, n int, dst *values) error {
+	switch p.typ {
+	case typeBoolean:
+		if len(buf)*8 < n {
+			return errCorrupt
+		}
+		for i := 0; i < n; i++ {
+			dst.bools = append(dst.bools, buf[i>>3]&(1<<(i&7)) != 0)
+		}
+	case typeInt32:
+		if len(buf) < 4*n {
+			return errCorrupt
+		}
+		for i := 0; i < n; i++ {
+			dst.i32 = append(dst.i32, int32(binary.LittleEndian.Uint32(buf[4*i:])))
+		}
+	case typeInt64:
+		if len(buf) < 8*n {
+			return errCorrupt
+		}
+		for i := 0; i < n; i++ {
+			dst.i64 = append(dst.i64, int64(binary.LittleEndian.Uint64(buf[8*i:])))
+		}
+	case typeFloat:
+		if len(buf) < 4*n {
+			return errCorrupt
+		}
+		for i := 0; i < n; i++ {
+			dst.f32 = append(dst.f32, math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:])))
+		}
+	case typeDouble:
+		if len(buf) < 8*n {
+			return errCorrupt
+		}
+		for i := 0; i < n; i++ {
+			dst.f64 = append(dst.f64, math.Float64frombits(binary.LittleEndian.Uint64(buf[8*i:])))
+		}
+	case typeInt96:
+		if len(buf) < 12*n {
+			return errCorrupt
+		}
+		for i := 0; i < n; i++ {
+			dst.bytes = append(dst.bytes, buf[12*i:12*i+12])
+		}
+	case typeFixedLenByteArray:
+		w := p.typeLength
+		if len(buf) < w*n {
+			return errCorrupt
+		}
+		for i := 0; i < n; i++ {
+			dst.bytes = append(dst.bytes, buf[w*i:w*i+w])
+		}
+	case typeByteArray:
+		for i := 0; i < n; i++ {
+			if len(buf) < 4 {
+				return errCorrupt
+			}
+			size := binary.LittleEndian.Uint32(buf)
+			buf = buf[4:]
+			if uint64(size) > uint64(len(buf)) {
+				return errCorrupt
+			}
+			dst.bytes = append(dst.bytes, buf[:size])
+			buf = buf[size:]
+		}
+	default:
+		return fmt.Errorf("parquet: unknown physical type %d", p.typ)
+	}
+	return nil
+}
+
+// dictionary decodes n dictionary indices
+// and appends the values they refer to
+func (p *page) dictionary(buf []byte, n int, dict *values, dst *values) error {
+	if dict == nil {
+		return errors.New("parquet: dictionary-encoded page without a dictionary")
+	}
+	if n == 0 {
+		return nil
+	}
+	if len(buf) == 0 {
+		return errCorrupt
+	}
+	var err error
+	p.ints, err = hybrid(buf[1:], int(buf[0]), n, p.ints[:0])
+	if err != nil {
+		return err
+	}
+	size := dict.len()
+	for _, i := range p.ints {
+		if i < 0 || int(i) >= size {
+			return fmt.Errorf("parquet: dictionary index %d out of range", i)
+		}
+	}
+	switch p.typ {
+	case typeBoolean:
+		for _, i := range p.ints {
+			dst.bools = append(dst.bools, dict.bools[i])
+		}
+	case typeInt32:
+		for _, i := range p.ints {
+			dst.i32 = append(dst.i32, dict.i32[i])
+		}
+	case typeInt64:
+		for _, i := range p.ints {
+			dst.i64 = append(dst.i64, dict.i64[i])
+		}
+	case typeFloat:
+		for _, i := range p.ints {
+			dst.f32 = append(dst.f32, dict.f32[i])
+		}
+	case typeDouble:
+		for _, i := range p.ints {
+			dst.f64 = append(dst.f64, dict.f64[i])
+		}
+	default:
+		for _, i := range p.ints {
+			dst.bytes = append(dst.bytes, dict.bytes[i])
+		}
+	}
+	return nil
+}
+
+// delta decodes n DELTA_BINARY_PACKED values
+func (p *page) delta(buf []byte, n int, dst *values) error {
+	var err error
+	p.longs, _, err = deltaBinaryPacked(buf, n, p.longs[:0])
+	if err != nil {
+		return err
+	}
+	if len(p.longs) < n {
+		return errCorrupt
+	}
+	switch p.typ {
+	case typeInt32:
+		for _, v := range p.longs[:n] {
+			dst.i32 = append(dst.i32, int32(v))
+		}
+	case typeInt64:
+		dst.i64 = append(dst.i64, p.longs[:n]...)
+	default:
+		return fmt.Errorf("parquet: DELTA_BINARY_PACKED is not valid for type %d", p.typ)
+	}
+	return nil
+}
+
+// deltaLength decodes n DELTA_LENGTH_BYTE_ARRAY values
+func (p *page) deltaLength(buf []byte, n int, dst *values) error {
+	if p.typ != typeByteArray {
+		return fmt.Errorf("parquet: DELTA_LENGTH_BYTE_ARRAY is not valid for type %d", p.typ)
+	}
+	var err error
+	p.lengths, buf, err = deltaBinaryPacked(buf, n, p.lengths[:0])
+	if err != nil {
+		return err
+	}
+	if len(p.lengths) < n {
+		return errCorrupt
+	}
+	for _, size := range p.lengths[:n] {
+		if size < 0 || size > int64(len(buf)) {
+			return errCorrupt
+		}
+		dst.bytes = append(dst.bytes, buf[:size])
+		buf = buf[size:]
+	}
+	return nil
+}
+
+// deltaByteArray decodes n DELTA_BYTE_ARRAY values
+func (p *page) deltaByteArray(buf []byte, n int, dst *values) error {
+	if p.typ != typeByteArray && p.typ != typeFixedLenByteArray {
+		return fmt.Errorf("parquet: DELTA_BYTE_ARRAY is not valid for type %d", p.typ)
+	}
+	var err error
+	p.longs, buf, err = deltaBinaryPacked(buf, n, p.longs[:0])
+	if err != nil {
+		return err
+	}
+	p.lengths, buf, err = deltaBinaryPacked(buf, n, p.lengths[:0])
+	if err != nil {
+		return err
+	}
+	if len(p.longs) < n || len(p.lengths) < n {
+		return errCorrupt
+	}
+	var prev []byte
+	for i := 0; i < n; i++ {
+		prefix, size := p.longs[i], p.lengths[i]
+		if prefix < 0 || prefix > int64(len(prev)) || size < 0 || size > int64(len(buf)) {
+			return errCorrupt
+		}
+		// each value needs its own memory,
+		// since it is a prefix of the next value
+		v := make([]byte, 0, int(prefix+size))
+		v = append(append(v, prev[:prefix]...), buf[:size]...)
+		buf = buf[size:]
+		dst.bytes = append(dst.bytes, v)
+		prev = v
+	}
+	return nil
+}
+
+// byteStreamSplit decodes n BYTE_STREAM_SPLIT values
+func (p *page) byteStreamSplit(buf []byte, n int, dst *values) error {
+	var w int
+	switch p.typ {
+	case typeInt32, typeFloat:
+		w = 4
+	case typeInt64, typeDouble:
+		w = 8
+	case typeFixedLenByteArray:
+		w = p.typeLength
+	default:
+		return fmt.Errorf("parquet: BYTE_STREAM_SPLIT is not valid for type %d", p.typ)
+	}
+	if len(buf) < w*n {
+		return errCorrupt
+	}
+	// interleave the streams and decode
+	// the result as PLAIN values
+	tmp := make([]byte, w*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < w; j++ {
+			tmp[i*w+j] = buf[j*n+i]
+		}
+	}
+	return p.plain(tmp, n, dst)
+}
+
+// decode decodes n values with the given encoding
+func (p *page) decode(enc int32, buf []byte, n int, dict *values, dst *values) error {
+	switch enc {
+	case encPlain:
+		return p.plain(buf, n, dst)
+	case encPlainDictionary, encRLEDictionary:
+		return p.dictionary(buf, n, dict, dst)
+	case encRLE:
+		if p.typ != typeBoolean {
+			return fmt.Errorf("parquet: RLE is not valid for type %d", p.typ)
+		}
+		if len(buf) < 4 {
+			return errCorrupt
+		}
+		size := binary.LittleEndian.Uint32(buf)
+		if uint64(size) > uint64(len(buf)-4) {
+			return errCorrupt
+		}
+		var err error
+		p.ints, err = hybrid(buf[4:4+size], 1, n, p.ints[:0])
+		if err != nil {
+			return err
+		}
+		for _, v := range p.ints {
+			dst.bools = append(dst.bools, v != 0)
+		}
+		return nil
+	case encDeltaBinaryPacked:
+		return p.delta(buf, n, dst)
+	case encDeltaLengthByteArray:
+		return p.deltaLength(buf, n, dst)
+	case encDeltaByteArray:
+		return p.deltaByteArray(buf, n, dst)
+	case encByteStreamSplit:
+		return p.byteStreamSplit(buf, n, dst)
+	default:
+		return fmt.Errorf("parquet: unsupported encoding %d", enc)
+	}
+}
@@ -0,0 +1,602 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Package parquet implements converting
+// Apache Parquet files to binary ION format.
+//
+// Each row of the input is converted to an ion
+// structure. Groups are converted to structures,
+// LIST (and bare repeated) fields are converted
+// to lists and MAP fields with string keys are
+// converted to structures. Null values are
+// omitted from structures and written as ion
+// nulls inside lists.
+//
+// Logical types are mapped as follows:
+//
+//   - STRING, ENUM and JSON are written as strings
+//   - TIMESTAMP, DATE and INT96 are written as timestamps
+//   - DECIMAL is written as an integer when its scale
+//     is zero and as a float otherwise
+//   - UUID is written as a string
+//   - BSON and other binary data are written as blobs,
+//     except for byte arrays without any annotation,
+//     which are written as strings when they are valid UTF-8
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/SnellerInc/sneller/ion"
+)
+
+const magic = "PAR1"
+
+// maxFooterSize is the largest file
+// footer (metadata) that we accept
+const maxFooterSize = 64 << 20
+
+// maxSchemaDepth is the maximum
+// nesting depth of a schema
+const maxSchemaDepth = 64
+
+var (
+	// ErrNotParquet is returned by Convert
+	// when the input is not a parquet file.
+	ErrNotParquet = errors.New("parquet: not a parquet file")
+
+	errLevels = errors.New("parquet: inconsistent repetition or definition levels")
+)
+
+// node kinds
+const (
+	kindPrimitive = iota
+	kindGroup
+	kindList
+	kindMap
+)
+
+// node is a node of the schema tree
+type node struct {
+	elem     *schemaElement
+	children []*node
+	kind     int
+	// maximum definition and
+	// repetition levels of the node
+	def, rep int
+	// the leaf columns below this node,
+	// in schema order
+	leaves []*column
+	sym    ion.Symbol
+
+	// for lists and maps, repeated is the repeated
+	// child and element is the node that represents
+	// each list element (or map value)
+	repeated, element *node
+	// for maps, the key
+	key *node
+
+	// for primitives, how values are written
+	conv convert
+	// ranged is set for timestamp columns
+	// that are only nested in structures
+	ranged bool
+	path   []string
+	symbuf ion.Symbuf
+}
+
+type schemaBuilder struct {
+	elems []schemaElement
+	pos   int
+	cols  []*column
+}
+
+func (b *schemaBuilder) build(parent *node, depth int) (*node, error) {
+	if b.pos >= len(b.elems) {
+		return nil, errors.New("parquet: schema has too few elements")
+	}
+	if depth > maxSchemaDepth {
+		return nil, errors.New("parquet: schema nested too deeply")
+	}
+	el := &b.elems[b.pos]
+	b.pos++
+	n := &node{elem: el}
+	if parent != nil {
+		n.def, n.rep = parent.def, parent.rep
+		switch el.repetition {
+		case repOptional:
+			n.def++
+		case repRepeated:
+			n.def++
+			n.rep++
+		}
+		n.path = append(append([]string{}, parent.path...), el.name)
+	}
+	if el.numChildren == 0 && parent != nil {
+		if el.typ < 0 {
+			return nil, fmt.Errorf("parquet: schema element %q has no type", el.name)
+		}
+		col := &column{leaf: n}
+		n.leaves = []*column{col}
+		n.kind = kindPrimitive
+		if err := n.setConv(); err != nil {
+			return nil, err
+		}
+		b.cols = append(b.cols, col)
+		return n, nil
+	}
+	if el.numChildren < 0 || int(el.numChildren) > len(b.elems)-b.pos {
+		return nil, fmt.Errorf("parquet: schema element %q has invalid number of children %d", el.name, el.numChildren)
+	}
+	n.kind = kindGroup
+	for i := 0; i < int(el.numChildren); i++ {
+		c, err := b.build(n, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, c)
+		n.leaves = append(n.leaves, c.leaves...)
+	}
+	if len(n.leaves) == 0 {
+		return nil, fmt.Errorf("parquet: group %q has no columns", el.name)
+	}
+	if parent != nil {
+		n.annotate()
+	}
+	return n, nil
+}
+
+// annotate determines if a group
+// is a list or map according to the
+// rules for the LIST and MAP annotations
+// (including the backward-compatibility rules)
+func (n *node) annotate() {
+	if len(n.children) != 1 || n.children[0].elem.repetition != repRepeated {
+		return
+	}
+	r := n.children[0]
+	el := n.elem
+	switch {
+	case el.logical.kind == logicalList || el.convertedType == convList:
+		n.kind = kindList
+		n.repeated = r
+		switch {
+		case r.kind == kindPrimitive, len(r.children) > 1,
+			r.elem.name == "array", r.elem.name == el.name+"_tuple":
+			// the repeated field is the element
+			n.element = r
+		default:
+			n.element = r.children[0]
+		}
+	case el.logical.kind == logicalMap || el.convertedType == convMap || el.convertedType == convMapKeyValue:
+		if r.kind == kindPrimitive || len(r.children) > 2 {
+			return
+		}
+		key := r.children[0]
+		n.repeated = r
+		if key.kind != kindPrimitive || !key.conv.isString() {
+			// maps without string keys are
+			// written as lists of key-value structs
+			n.kind = kindList
+			n.element = r
+			return
+		}
+		n.kind = kindMap
+		n.key = key
+		if len(r.children) == 2 {
+			n.element = r.children[1]
+		}
+	}
+}
+
+// intern interns the field names of the
+// schema and prepares the symbol paths
+// of the columns that are indexed
+func (n *node) intern(st *ion.Symtab, structs bool) {
+	n.sym = st.Intern(n.elem.name)
+	if n.kind == kindPrimitive {
+		n.ranged = structs && n.elem.repetition != repRepeated && n.conv.isTime()
+		if n.ranged {
+			n.symbuf.Prepare(len(n.path))
+			for i := range n.path {
+				n.symbuf.Push(st.Intern(n.path[i]))
+			}
+		}
+		return
+	}
+	structs = structs && n.kind == kindGroup && n.elem.repetition != repRepeated
+	for i := range n.children {
+		n.children[i].intern(st, structs)
+	}
+}
+
+// File is an open parquet file.
+type File struct {
+	r    io.ReaderAt
+	size int64
+	meta fileMetaData
+	root *node
+	cols []*column
+}
+
+// Open reads the metadata of the parquet
+// file r, which is size bytes long.
+func Open(r io.ReaderAt, size int64) (*File, error) {
+	if size < 12 {
+		return nil, ErrNotParquet
+	}
+	var head [4]byte
+	var tail [8]byte
+	if _, err := r.ReadAt(head[:], 0); err != nil {
+		return nil, err
+	}
+	if _, err := r.ReadAt(tail[:], size-8); err != nil {
+		return nil, err
+	}
+	if string(head[:]) != magic {
+		return nil, ErrNotParquet
+	}
+	if string(tail[4:]) != magic {
+		if string(tail[4:]) == "PARE" {
+			return nil, errors.New("parquet: encrypted files are not supported")
+		}
+		return nil, ErrNotParquet
+	}
+	footer := int64(binary.LittleEndian.Uint32(tail[:]))
+	if footer > maxFooterSize || footer > size-12 {
+		return nil, fmt.Errorf("parquet: invalid footer size %d", footer)
+	}
+	buf := make([]byte, footer)
+	if _, err := r.ReadAt(buf, size-8-footer); err != nil {
+		return nil, err
+	}
+	f := &File{r: r, size: size}
+	d := decoder{buf: buf}
+	d.fileMetaData(&f.meta)
+	if d.err != nil {
+		return nil, fmt.Errorf("parquet: reading file metadata: %w", d.err)
+	}
+	if len(f.meta.schema) == 0 {
+		return nil, errors.New("parquet: file has no schema")
+	}
+	b := schemaBuilder{elems: f.meta.schema}
+	root, err := b.build(nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if b.pos != len(b.elems) {
+		return nil, errors.New("parquet: schema has too many elements")
+	}
+	f.root = root
+	f.cols = b.cols
+	return f, nil
+}
+
+// NumRows returns the number of rows in f.
+func (f *File) NumRows() int64 { return f.meta.numRows }
+
+// intern interns the constant fields in cons
+// and the field names of the schema in dst.Symbols
+func (f *File) intern(dst *ion.Chunker, cons []ion.Field) error {
+	// make sure constant field IDs are interned
+	prev := ion.Symbol(0)
+	for i := range cons {
+		cons[i].Sym = dst.Symbols.Intern(cons[i].Label)
+		if cons[i].Sym < prev {
+			return fmt.Errorf("parquet: internal error: constant interned symbols out-of-order")
+		}
+		prev = cons[i].Sym
+	}
+	for _, c := range f.root.children {
+		c.intern(&dst.Symbols, true)
+	}
+	return nil
+}
+
+// Convert writes each of the rows of f
+// to dst as an ion structure, along with
+// the constant fields in cons.
+func (f *File) Convert(dst *ion.Chunker, cons []ion.Field) error {
+	if err := f.intern(dst, cons); err != nil {
+		return err
+	}
+	epoch := dst.SymbolEpoch()
+	a := assembler{dst: dst}
+	for i := range f.meta.rowGroups {
+		rg := &f.meta.rowGroups[i]
+		if len(rg.columns) != len(f.cols) {
+			return fmt.Errorf("parquet: row group %d has %d columns instead of %d", i, len(rg.columns), len(f.cols))
+		}
+		for j, c := range f.cols {
+			cc := &rg.columns[j]
+			if cc.meta.path != nil && !equal(cc.meta.path, c.leaf.path) {
+				return fmt.Errorf("parquet: row group %d: column %v is out of order", i, cc.meta.path)
+			}
+			if err := c.read(f.r, f.size, cc); err != nil {
+				return err
+			}
+		}
+		for k := int64(0); k < rg.numRows; k++ {
+			dst.BeginStruct(-1)
+			for i := range cons {
+				cons[i].Encode(&dst.Buffer, &dst.Symbols)
+			}
+			a.row(f.root)
+			dst.EndStruct()
+			if a.err != nil {
+				return a.err
+			}
+			if err := dst.Commit(); err != nil {
+				return err
+			}
+			if e := dst.SymbolEpoch(); e != epoch {
+				// the symbol table was reset
+				if err := f.intern(dst, cons); err != nil {
+					return err
+				}
+				epoch = e
+			}
+		}
+		for _, c := range f.cols {
+			if c.pos != c.n {
+				return errLevels
+			}
+		}
+	}
+	return nil
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Convert reads the parquet file r, which is
+// size bytes long, and writes each of its rows
+// to dst as an ion structure, along with the
+// constant fields in cons.
+func Convert(r io.ReaderAt, size int64, dst *ion.Chunker, cons []ion.Field) error {
+	f, err := Open(r, size)
+	if err != nil {
+		return err
+	}
+	return f.Convert(dst, cons)
+}
+
+// assembler reassembles rows from the
+// repetition and definition levels
+// of the leaf columns
+type assembler struct {
+	dst *ion.Chunker
+	err error
+}
+
+// ok determines if c has another entry
+func (a *assembler) ok(c *column) bool {
+	if c.pos < c.n {
+		return true
+	}
+	if a.err == nil {
+		a.err = errLevels
+	}
+	return false
+}
+
+// row writes the fields of one row
+func (a *assembler) row(root *node) {
+	for _, c := range root.leaves {
+		if !a.ok(c) {
+			return
+		}
+		if c.repAt() != 0 {
+			a.err = errLevels
+			return
+		}
+	}
+	for _, n := range root.children {
+		a.field(n)
+	}
+}
+
+// skip consumes the entry of each of
+// the columns below a node that is null
+// (or an empty list)
+func (a *assembler) skip(n *node) {
+	for _, c := range n.leaves {
+		if !a.ok(c) {
+			return
+		}
+		if int(c.defAt()) == c.leaf.def {
+			// not actually null
+			a.err = errLevels
+			return
+		}
+		c.pos++
+	}
+}
+
+// field writes n as a structure field
+// unless its value is null
+func (a *assembler) field(n *node) {
+	c := n.leaves[0]
+	if !a.ok(c) {
+		return
+	}
+	if int(c.defAt()) < n.def {
+		a.skip(n)
+		return
+	}
+	a.dst.BeginField(n.sym)
+	if n.elem.repetition == repRepeated {
+		a.list(n, n)
+	} else {
+		a.value(n)
+	}
+}
+
+// list writes the elements of the repeated
+// node r as a list, where e is the node that
+// represents each element
+func (a *assembler) list(r, e *node) {
+	a.dst.BeginList(-1)
+	c := r.leaves[0]
+	for a.err == nil {
+		if e == r {
+			a.value(r)
+		} else {
+			a.elem(e)
+		}
+		if c.pos >= c.n || int(c.repAt()) != r.rep {
+			break
+		}
+	}
+	a.dst.EndList()
+}
+
+// elem writes the list element e
+func (a *assembler) elem(e *node) {
+	c := e.leaves[0]
+	if !a.ok(c) {
+		return
+	}
+	present := int(c.defAt()) >= e.def
+	switch e.elem.repetition {
+	case repOptional:
+		if !present {
+			a.skip(e)
+			a.dst.WriteNull()
+			return
+		}
+	case repRepeated:
+		if !present {
+			a.skip(e)
+			a.dst.BeginList(-1)
+			a.dst.EndList()
+			return
+		}
+		a.list(e, e)
+		return
+	}
+	a.value(e)
+}
+
+// value writes the value of n,
+// which is known to be non-null
+func (a *assembler) value(n *node) {
+	switch n.kind {
+	case kindPrimitive:
+		a.leaf(n)
+	case kindGroup:
+		a.dst.BeginStruct(-1)
+		for _, c := range n.children {
+			a.field(c)
+			if a.err != nil {
+				break
+			}
+		}
+		a.dst.EndStruct()
+	case kindList:
+		r := n.repeated
+		c := r.leaves[0]
+		if !a.ok(c) {
+			return
+		}
+		if int(c.defAt()) < r.def {
+			a.skip(n)
+			a.dst.BeginList(-1)
+			a.dst.EndList()
+			return
+		}
+		a.list(r, n.element)
+	case kindMap:
+		r := n.repeated
+		c := r.leaves[0]
+		if !a.ok(c) {
+			return
+		}
+		a.dst.BeginStruct(-1)
+		if int(c.defAt()) < r.def {
+			a.skip(n)
+		} else {
+			for a.err == nil {
+				a.entry(n)
+				if c.pos >= c.n || int(c.repAt()) != r.rep {
+					break
+				}
+			}
+		}
+		a.dst.EndStruct()
+	}
+}
+
+// entry writes one entry of the map n
+func (a *assembler) entry(n *node) {
+	k := n.key.leaves[0]
+	if !a.ok(k) {
+		return
+	}
+	if int(k.defAt()) < n.key.def {
+		a.err = errors.New("parquet: null map key")
+		return
+	}
+	name := k.vals.bytes[k.vpos]
+	k.pos++
+	k.vpos++
+	sym := a.dst.Symbols.Intern(string(name))
+	v := n.element
+	if v == nil {
+		a.dst.BeginField(sym)
+		a.dst.WriteNull()
+		return
+	}
+	c := v.leaves[0]
+	if !a.ok(c) {
+		return
+	}
+	if int(c.defAt()) < v.def {
+		a.skip(v)
+		return
+	}
+	a.dst.BeginField(sym)
+	if v.elem.repetition == repRepeated {
+		a.list(v, v)
+	} else {
+		a.value(v)
+	}
+}
+
+// leaf writes the current value of a leaf column
+func (a *assembler) leaf(n *node) {
+	c := n.leaves[0]
+	if !a.ok(c) {
+		return
+	}
+	if int(c.defAt()) != n.def {
+		a.err = errLevels
+		return
+	}
+	n.conv.write(a, n, c.vpos)
+	c.pos++
+	c.vpos++
+}
@@ -0,0 +1,537 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/SnellerInc/sneller/date"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+func TestHybrid(t *testing.T) {
+	testcases := []struct {
+		in    []byte
+		width int
+		n     int
+		want  []int32
+	}{
+		{
+			// bit-packed example from the
+			// parquet encoding specification
+			in:    []byte{3, 0x88, 0xc6, 0xfa},
+			width: 3,
+			n:     8,
+			want:  []int32{0, 1, 2, 3, 4, 5, 6, 7},
+		},
+		{
+			// padding beyond n is ignored
+			in:    []byte{3, 0x88, 0xc6, 0xfa},
+			width: 3,
+			n:     5,
+			want:  []int32{0, 1, 2, 3, 4},
+		},
+		{
+			// repeated run followed by a bit-packed run
+			in:    []byte{5 << 1, 0x02, 0x01<<1 | 1, 0x0f, 0x00},
+			width: 2,
+			n:     9,
+			want:  []int32{2, 2, 2, 2, 2, 3, 3, 0, 0},
+		},
+		{
+			in:    []byte{4 << 1, 0x34, 0x12},
+			width: 16,
+			n:     4,
+			want:  []int32{0x1234, 0x1234, 0x1234, 0x1234},
+		},
+	}
+	for i := range testcases {
+		tc := &testcases[i]
+		got, err := hybrid(tc.in, tc.width, tc.n, nil)
+		if err != nil {
+			t.Errorf("case %d: %s", i, err)
+			continue
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("case %d: got %v, want %v", i, got, tc.want)
+		}
+	}
+	// truncated input
+	_, err := hybrid([]byte{3, 0x88}, 3, 8, nil)
+	if err == nil {
+		t.Error("expected an error for truncated input")
+	}
+}
+
+func TestDeltaBinaryPacked(t *testing.T) {
+	testcases := []struct {
+		in   []byte
+		want []int64
+	}{
+		{
+			// examples from the parquet
+			// encoding specification
+			in:   []byte{0x80, 0x01, 4, 5, 2, 2, 0, 0, 0, 0},
+			want: []int64{1, 2, 3, 4, 5},
+		},
+		{
+			in: append([]byte{0x80, 0x01, 4, 8, 14, 3, 2, 0, 0, 0, 0xc0, 0x3f},
+				make([]byte, 6)...),
+			want: []int64{7, 5, 3, 1, 2, 3, 4, 5},
+		},
+		{
+			in:   []byte{0x80, 0x01, 4, 1, 0x7f},
+			want: []int64{-64},
+		},
+	}
+	for i := range testcases {
+		tc := &testcases[i]
+		got, rest, err := deltaBinaryPacked(tc.in, len(tc.want), nil)
+		if err != nil {
+			t.Errorf("case %d: %s", i, err)
+			continue
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("case %d: got %v, want %v", i, got, tc.want)
+		}
+		if len(rest) != 0 {
+			t.Errorf("case %d: %d bytes left over", i, len(rest))
+		}
+	}
+	// round-trip through the test encoder
+	// with multiple blocks and large deltas
+	var in []int64
+	for i := 0; i < 1000; i++ {
+		in = append(in, int64(i*i*i)-int64(i%7)<<40)
+	}
+	in = append(in, -1<<63, 1<<63-1, 0)
+	got, rest, err := deltaBinaryPacked(deltaEncode(in), len(in), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, in) || len(rest) != 0 {
+		t.Error("round-trip mismatch")
+	}
+}
+
+func TestFloat16(t *testing.T) {
+	testcases := []struct {
+		in   uint16
+		want float32
+	}{
+		{0x0000, 0},
+		{0x3c00, 1},
+		{0xc000, -2},
+		{0x3555, 0.33325195},
+		{0x7bff, 65504},
+		{0x0001, 5.9604645e-08},
+	}
+	for _, tc := range testcases {
+		if got := float16(tc.in); got != tc.want {
+			t.Errorf("float16(%#x) = %g, want %g", tc.in, got, tc.want)
+		}
+	}
+}
+
+func leaf(name string, rep, typ int32) schemaElement {
+	return schemaElement{name: name, repetition: rep, typ: typ, convertedType: convNone}
+}
+
+func group(name string, rep int32, children int) schemaElement {
+	return schemaElement{name: name, repetition: rep, typ: -1, numChildren: int32(children), convertedType: convNone}
+}
+
+func str(name string, rep int32) schemaElement {
+	s := leaf(name, rep, typeByteArray)
+	s.logical.kind = logicalString
+	return s
+}
+
+func bytesOf(s ...string) [][]byte {
+	out := make([][]byte, len(s))
+	for i := range s {
+		out[i] = []byte(s[i])
+	}
+	return out
+}
+
+func int96(t date.Time) []byte {
+	ns := int64(t.Hour())*3600e9 + int64(t.Minute())*60e9 + int64(t.Second())*1e9 + int64(t.Nanosecond())
+	day := t.Time().Unix()/86400 + julianEpoch
+	b := binary.LittleEndian.AppendUint64(nil, uint64(ns))
+	return binary.LittleEndian.AppendUint32(b, uint32(day))
+}
+
+// nestedSchema is a schema with nested
+// and timestamp columns:
+//
+//	message schema {
+//	  required int64 id;
+//	  optional binary name (STRING);
+//	  optional int64 ts (TIMESTAMP(MICROS, true));
+//	  optional group tags (LIST) {
+//	    repeated group list {
+//	      optional binary element (STRING);
+//	    }
+//	  }
+//	  optional group address {
+//	    required binary city (STRING);
+//	    optional int32 zip;
+//	  }
+//	  optional group attrs (MAP) {
+//	    repeated group key_value {
+//	      required binary key (STRING);
+//	      optional int32 value;
+//	    }
+//	  }
+//	  optional int32 price (DECIMAL(9, 2));
+//	  optional int32 day (DATE);
+//	  optional int96 legacy;
+//	  optional boolean flag;
+//	  optional double score;
+//	  optional binary raw;
+//	}
+func nestedSchema() []schemaElement {
+	ts := leaf("ts", repOptional, typeInt64)
+	ts.logical = logicalType{kind: logicalTimestamp, unit: unitMicros}
+	tags := group("tags", repOptional, 1)
+	tags.logical.kind = logicalList
+	attrs := group("attrs", repOptional, 1)
+	attrs.convertedType = convMap
+	price := leaf("price", repOptional, typeInt32)
+	price.convertedType = convDecimal
+	price.scale, price.precision = 2, 9
+	day := leaf("day", repOptional, typeInt32)
+	day.logical.kind = logicalDate
+	return []schemaElement{
+		group("schema", repRequired, 12),
+		leaf("id", repRequired, typeInt64),
+		str("name", repOptional),
+		ts,
+		tags,
+		group("list", repRepeated, 1),
+		str("element", repOptional),
+		group("address", repOptional, 2),
+		str("city", repRequired),
+		leaf("zip", repOptional, typeInt32),
+		attrs,
+		group("key_value", repRepeated, 2),
+		str("key", repRequired),
+		leaf("value", repOptional, typeInt32),
+		price,
+		day,
+		leaf("legacy", repOptional, typeInt96),
+		leaf("flag", repOptional, typeBoolean),
+		leaf("score", repOptional, typeDouble),
+		leaf("raw", repOptional, typeByteArray),
+	}
+}
+
+// nestedColumns are the columns of four rows
+// of data in nestedSchema; the levels are
+// derived by hand from the rows in nestedRows
+func nestedColumns() []testColumn {
+	p := func(s ...string) []string { return s }
+	legacy := date.Date(2023, 1, 1, 12, 0, 0, 500000)
+	return []testColumn{
+		{typ: typeInt64, path: p("id"),
+			vals: values{i64: []int64{1, 2, 3, 4}}},
+		{typ: typeByteArray, path: p("name"), maxDef: 1,
+			def:  []int32{1, 0, 1, 1},
+			vals: values{bytes: bytesOf("alice", "carol", "dave")}},
+		{typ: typeInt64, path: p("ts"), maxDef: 1,
+			def:  []int32{1, 0, 0, 1},
+			vals: values{i64: []int64{1672628645123456, 0}}},
+		{typ: typeByteArray, path: p("tags", "list", "element"), maxDef: 3, maxRep: 1,
+			rep:  []int32{0, 1, 0, 0, 1, 0},
+			def:  []int32{3, 3, 1, 2, 3, 0},
+			vals: values{bytes: bytesOf("a", "b", "c")}},
+		{typ: typeByteArray, path: p("address", "city"), maxDef: 1,
+			def:  []int32{1, 0, 1, 1},
+			vals: values{bytes: bytesOf("Paris", "Oslo", "Rome")}},
+		{typ: typeInt32, path: p("address", "zip"), maxDef: 2,
+			def:  []int32{2, 0, 1, 2},
+			vals: values{i32: []int32{75001, 100}}},
+		{typ: typeByteArray, path: p("attrs", "key_value", "key"), maxDef: 2, maxRep: 1,
+			rep:  []int32{0, 1, 0, 0, 0},
+			def:  []int32{2, 2, 0, 1, 2},
+			vals: values{bytes: bytesOf("x", "y", "z")}},
+		{typ: typeInt32, path: p("attrs", "key_value", "value"), maxDef: 3, maxRep: 1,
+			rep:  []int32{0, 1, 0, 0, 0},
+			def:  []int32{3, 2, 0, 1, 3},
+			vals: values{i32: []int32{1, 5}}},
+		{typ: typeInt32, path: p("price"), maxDef: 1,
+			def:  []int32{1, 0, 1, 0},
+			vals: values{i32: []int32{1234, -5}}},
+		{typ: typeInt32, path: p("day"), maxDef: 1,
+			def:  []int32{1, 0, 0, 1},
+			vals: values{i32: []int32{19358, 0}}},
+		{typ: typeInt96, path: p("legacy"), maxDef: 1,
+			def:  []int32{1, 0, 0, 0},
+			vals: values{bytes: [][]byte{int96(legacy)}}},
+		{typ: typeBoolean, path: p("flag"), maxDef: 1,
+			def:  []int32{1, 1, 0, 1},
+			vals: values{bools: []bool{true, false, true}}},
+		{typ: typeDouble, path: p("score"), maxDef: 1,
+			def:  []int32{1, 0, 1, 0},
+			vals: values{f64: []float64{1.5, -2.25}}},
+		{typ: typeByteArray, path: p("raw"), maxDef: 1,
+			def:  []int32{1, 1, 0, 1},
+			vals: values{bytes: [][]byte{[]byte("hello"), {0xff, 0xfe}, {}}}},
+	}
+}
+
+var nestedRows = []string{
+	`{"name": "alice", "input": "test.parquet", "id": 1, "ts": "2023-01-02T03:04:05.123456Z", "tags": ["a", "b"], "address": {"city": "Paris", "zip": 75001}, "attrs": {"x": 1}, "price": 12.34, "day": "2023-01-01T00:00:00Z", "legacy": "2023-01-01T12:00:00.0005Z", "flag": true, "score": 1.5, "raw": "hello"}`,
+	`{"input": "test.parquet", "id": 2, "tags": [], "flag": false, "raw": "//4="}`,
+	`{"name": "carol", "input": "test.parquet", "id": 3, "tags": [null, "c"], "address": {"city": "Oslo"}, "attrs": {}, "price": -0.05, "score": -2.25}`,
+	`{"name": "dave", "input": "test.parquet", "id": 4, "ts": "1970-01-01T00:00:00Z", "address": {"city": "Rome", "zip": 100}, "attrs": {"z": 5}, "day": "1970-01-01T00:00:00Z", "flag": true, "raw": ""}`,
+}
+
+// testConvert converts a test file and returns
+// the JSON representation of each row
+func testConvert(t *testing.T, buf []byte) ([]string, *ion.Chunker) {
+	var out bytes.Buffer
+	dst := &ion.Chunker{
+		Align: 1024 * 1024,
+		W:     ion.NewJSONWriter(&out, '\n'),
+	}
+	cons := []ion.Field{{Label: "input", Datum: ion.String("test.parquet")}}
+	err := Convert(bytes.NewReader(buf), int64(len(buf)), dst, cons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(out.String()), "\n"), dst
+}
+
+func TestConvert(t *testing.T) {
+	options := []writeOptions{
+		{},
+		{dict: true},
+		{delta: true},
+		{v2: true},
+		{v2: true, dict: true, codec: codecSnappy},
+		{v2: true, delta: true, codec: codecZstd},
+		{codec: codecSnappy, page: 1},
+		{codec: codecGzip, dict: true, rowGroup: 3},
+		{codec: codecZstd, rowGroup: 1, page: 1},
+		{v2: true, codec: codecGzip, rowGroup: 2, page: 1},
+	}
+	for i := range options {
+		opt := &options[i]
+		t.Run(opt.String(), func(t *testing.T) {
+			buf := writeTestFile(nestedSchema(), nestedColumns(), opt)
+			got, _ := testConvert(t, buf)
+			if len(got) != len(nestedRows) {
+				t.Fatalf("got %d rows, want %d", len(got), len(nestedRows))
+			}
+			for j := range got {
+				if got[j] != nestedRows[j] {
+					t.Errorf("row %d:\ngot  %s\nwant %s", j, got[j], nestedRows[j])
+				}
+			}
+		})
+	}
+}
+
+// TestGolden tests converting files written by
+// the Apache Arrow parquet writer; see
+// testdata/generate for how they are produced
+func TestGolden(t *testing.T) {
+	want, err := os.ReadFile("testdata/golden.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantrows := strings.Split(strings.TrimSpace(string(want)), "\n")
+	files := []string{
+		"plain.parquet",
+		"dict-snappy.parquet",
+		"v2-zstd.parquet",
+		"int96-gzip.parquet",
+	}
+	for _, name := range files {
+		t.Run(name, func(t *testing.T) {
+			buf, err := os.ReadFile(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := testConvert(t, buf)
+			if len(got) != len(wantrows) {
+				t.Fatalf("got %d rows, want %d", len(got), len(wantrows))
+			}
+			for j := range got {
+				var g, w map[string]any
+				if err := json.Unmarshal([]byte(got[j]), &g); err != nil {
+					t.Fatalf("row %d: %s", j, err)
+				}
+				if err := json.Unmarshal([]byte(wantrows[j]), &w); err != nil {
+					t.Fatalf("row %d: %s", j, err)
+				}
+				w["input"] = "test.parquet"
+				if !reflect.DeepEqual(g, w) {
+					t.Errorf("row %d:\ngot  %s\nwant %s", j, got[j], wantrows[j])
+				}
+			}
+		})
+	}
+}
+
+// TestLegacyLists tests the backward-compatibility
+// rules for lists and maps:
+//
+//	message schema {
+//	  repeated int32 nums;
+//	  optional group pairs (LIST) {
+//	    repeated group array {
+//	      required int32 a;
+//	    }
+//	  }
+//	  optional group m (MAP) {
+//	    repeated group key_value {
+//	      required int32 key;
+//	      optional binary value (UTF8);
+//	    }
+//	  }
+//	}
+func TestLegacyLists(t *testing.T) {
+	pairs := group("pairs", repOptional, 1)
+	pairs.convertedType = convList
+	m := group("m", repOptional, 1)
+	m.logical.kind = logicalMap
+	value := leaf("value", repOptional, typeByteArray)
+	value.convertedType = convUTF8
+	schema := []schemaElement{
+		group("schema", repRequired, 3),
+		leaf("nums", repRepeated, typeInt32),
+		pairs,
+		group("array", repRepeated, 1),
+		leaf("a", repRequired, typeInt32),
+		m,
+		group("key_value", repRepeated, 2),
+		leaf("key", repRequired, typeInt32),
+		value,
+	}
+	p := func(s ...string) []string { return s }
+	cols := []testColumn{
+		{typ: typeInt32, path: p("nums"), maxDef: 1, maxRep: 1,
+			rep:  []int32{0, 1, 0},
+			def:  []int32{1, 1, 0},
+			vals: values{i32: []int32{1, 2}}},
+		{typ: typeInt32, path: p("pairs", "array", "a"), maxDef: 2, maxRep: 1,
+			rep:  []int32{0, 1, 0},
+			def:  []int32{2, 2, 0},
+			vals: values{i32: []int32{1, 2}}},
+		{typ: typeInt32, path: p("m", "key_value", "key"), maxDef: 2, maxRep: 1,
+			rep:  []int32{0, 0},
+			def:  []int32{2, 1},
+			vals: values{i32: []int32{1}}},
+		{typ: typeByteArray, path: p("m", "key_value", "value"), maxDef: 3, maxRep: 1,
+			rep:  []int32{0, 0},
+			def:  []int32{3, 1},
+			vals: values{bytes: bytesOf("x")}},
+	}
+	want := []string{
+		`{"input": "test.parquet", "nums": [1, 2], "pairs": [{"a": 1}, {"a": 2}], "m": [{"key": 1, "value": "x"}]}`,
+		`{"input": "test.parquet", "m": []}`,
+	}
+	got, _ := testConvert(t, writeTestFile(schema, cols, &writeOptions{}))
+	if !slices.Equal(got, want) {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// rangeWriter records the time ranges
+// produced by an ion.Chunker
+type rangeWriter struct {
+	ranges map[string][2]ion.Datum
+}
+
+func (w *rangeWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func (w *rangeWriter) SetMinMax(path []string, min, max ion.Datum) {
+	if w.ranges == nil {
+		w.ranges = make(map[string][2]ion.Datum)
+	}
+	w.ranges[strings.Join(path, ".")] = [2]ion.Datum{min, max}
+}
+
+func TestTimeRanges(t *testing.T) {
+	buf := writeTestFile(nestedSchema(), nestedColumns(), &writeOptions{})
+	rw := &rangeWriter{}
+	dst := &ion.Chunker{Align: 1024 * 1024, W: rw}
+	if err := Convert(bytes.NewReader(buf), int64(len(buf)), dst, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][2]ion.Datum{
+		"ts": {
+			ion.Timestamp(date.Unix(0, 0)),
+			ion.Timestamp(date.Date(2023, 1, 2, 3, 4, 5, 123456000)),
+		},
+		"day": {
+			ion.Timestamp(date.Unix(0, 0)),
+			ion.Timestamp(date.Date(2023, 1, 1, 0, 0, 0, 0)),
+		},
+		"legacy": {
+			ion.Timestamp(date.Date(2023, 1, 1, 12, 0, 0, 500000)),
+			ion.Timestamp(date.Date(2023, 1, 1, 12, 0, 0, 500000)),
+		},
+	}
+	if len(rw.ranges) != len(want) {
+		t.Errorf("got ranges %v", rw.ranges)
+	}
+	for k, v := range want {
+		got, ok := rw.ranges[k]
+		if !ok {
+			t.Errorf("no range for %s", k)
+			continue
+		}
+		if !got[0].Equal(v[0]) || !got[1].Equal(v[1]) {
+			t.Errorf("%s: got %v, want %v", k, got, v)
+		}
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	buf := writeTestFile(nestedSchema(), nestedColumns(), &writeOptions{dict: true})
+	var dst ion.Chunker
+	dst.Align = 1024 * 1024
+	dst.W = &bytes.Buffer{}
+	err := Convert(bytes.NewReader(buf[:len(buf)-1]), int64(len(buf)-1), &dst, nil)
+	if !errors.Is(err, ErrNotParquet) {
+		t.Errorf("truncated file: got %v", err)
+	}
+	// corrupting the file must never cause a panic
+	for i := 4; i < len(buf)-8; i++ {
+		cp := slices.Clone(buf)
+		cp[i] ^= 0x55
+		dst := ion.Chunker{Align: 1024 * 1024, W: &bytes.Buffer{}}
+		Convert(bytes.NewReader(cp), int64(len(cp)), &dst, nil)
+	}
+}
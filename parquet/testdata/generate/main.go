@@ -0,0 +1,399 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Command generate writes the golden parquet files
+// in the parent directory using the Apache Arrow
+// parquet writer, along with golden.json, which
+// holds the rows that the files are expected to
+// be converted to.
+//
+// It is a separate module so that the parquet
+// package does not depend on Apache Arrow:
+//
+//	cd parquet/testdata/generate && go run . ..
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/decimal128"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet"
+	"github.com/apache/arrow/go/v14/parquet/compress"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+)
+
+const rows = 40
+
+var (
+	names  = []string{"alice", "bob", "carol", "dave"}
+	cities = []string{"Paris", "Oslo", "Rome"}
+)
+
+var schema = arrow.NewSchema([]arrow.Field{
+	{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+	{Name: "address", Type: arrow.StructOf(
+		arrow.Field{Name: "city", Type: arrow.BinaryTypes.String},
+		arrow.Field{Name: "zip", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+	), Nullable: true},
+	{Name: "attrs", Type: arrow.MapOf(arrow.BinaryTypes.String, arrow.PrimitiveTypes.Int64), Nullable: true},
+	{Name: "price", Type: &arrow.Decimal128Type{Precision: 9, Scale: 2}, Nullable: true},
+	{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}, Nullable: true},
+	{Name: "day", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+	{Name: "flag", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+	{Name: "score", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	{Name: "raw", Type: arrow.BinaryTypes.Binary, Nullable: true},
+	{Name: "matrix", Type: arrow.ListOf(arrow.ListOf(arrow.PrimitiveTypes.Int32)), Nullable: true},
+}, nil)
+
+// the value of each column for row i;
+// nil means null
+
+func name(i int) any {
+	if i%5 == 3 {
+		return nil
+	}
+	return names[i%len(names)]
+}
+
+func tags(i int) any {
+	if i%7 == 6 {
+		return nil
+	}
+	lst := []any{}
+	for j := 0; j < i%4; j++ {
+		if (i+j)%5 == 4 {
+			lst = append(lst, nil)
+		} else {
+			lst = append(lst, fmt.Sprintf("t%d", (i+j)%3))
+		}
+	}
+	return lst
+}
+
+func address(i int) any {
+	if i%6 == 5 {
+		return nil
+	}
+	m := map[string]any{"city": cities[i%len(cities)]}
+	if i%2 == 0 {
+		m["zip"] = int32(10000 + i)
+	}
+	return m
+}
+
+func attrs(i int) any {
+	if i%8 == 7 {
+		return nil
+	}
+	m := map[string]any{}
+	for j := 0; j < i%3; j++ {
+		m[fmt.Sprintf("k%d", j)] = int64(i * j)
+	}
+	return m
+}
+
+func price(i int) any {
+	if i%9 == 8 {
+		return nil
+	}
+	return int64(i*137 - 500)
+}
+
+func ts(i int) any {
+	if i%4 == 2 {
+		return nil
+	}
+	return int64(1672628645123456) + int64(i)*3600000001
+}
+
+func day(i int) any {
+	if i%10 == 9 {
+		return nil
+	}
+	return int32(19358 + i)
+}
+
+func flag(i int) any {
+	if i%11 == 10 {
+		return nil
+	}
+	return i%3 == 0
+}
+
+func score(i int) any {
+	if i%3 == 2 {
+		return nil
+	}
+	return float64(i)*0.25 - 3
+}
+
+func raw(i int) any {
+	if i%13 == 12 {
+		return nil
+	}
+	if i%5 == 0 {
+		return []byte{0xff, 0xfe}
+	}
+	return []byte(fmt.Sprintf("r%d", i))
+}
+
+func matrix(i int) any {
+	if i%9 == 4 {
+		return nil
+	}
+	lst := []any{}
+	for j := 0; j < i%3; j++ {
+		inner := []any{}
+		for k := 0; k <= j; k++ {
+			inner = append(inner, int32(k*i))
+		}
+		lst = append(lst, inner)
+	}
+	return lst
+}
+
+func record() arrow.Record {
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	for i := 0; i < rows; i++ {
+		b.Field(0).(*array.Int64Builder).Append(int64(i + 1))
+		if v := name(i); v == nil {
+			b.Field(1).AppendNull()
+		} else {
+			b.Field(1).(*array.StringBuilder).Append(v.(string))
+		}
+		lb := b.Field(2).(*array.ListBuilder)
+		if v := tags(i); v == nil {
+			lb.AppendNull()
+		} else {
+			lb.Append(true)
+			vb := lb.ValueBuilder().(*array.StringBuilder)
+			for _, s := range v.([]any) {
+				if s == nil {
+					vb.AppendNull()
+				} else {
+					vb.Append(s.(string))
+				}
+			}
+		}
+		sb := b.Field(3).(*array.StructBuilder)
+		if v := address(i); v == nil {
+			sb.AppendNull()
+		} else {
+			m := v.(map[string]any)
+			sb.Append(true)
+			sb.FieldBuilder(0).(*array.StringBuilder).Append(m["city"].(string))
+			if z, ok := m["zip"]; ok {
+				sb.FieldBuilder(1).(*array.Int32Builder).Append(z.(int32))
+			} else {
+				sb.FieldBuilder(1).AppendNull()
+			}
+		}
+		mb := b.Field(4).(*array.MapBuilder)
+		if v := attrs(i); v == nil {
+			mb.AppendNull()
+		} else {
+			mb.Append(true)
+			m := v.(map[string]any)
+			for j := 0; j < len(m); j++ {
+				k := fmt.Sprintf("k%d", j)
+				mb.KeyBuilder().(*array.StringBuilder).Append(k)
+				mb.ItemBuilder().(*array.Int64Builder).Append(m[k].(int64))
+			}
+		}
+		if v := price(i); v == nil {
+			b.Field(5).AppendNull()
+		} else {
+			b.Field(5).(*array.Decimal128Builder).Append(decimal128.FromI64(v.(int64)))
+		}
+		if v := ts(i); v == nil {
+			b.Field(6).AppendNull()
+		} else {
+			b.Field(6).(*array.TimestampBuilder).Append(arrow.Timestamp(v.(int64)))
+		}
+		if v := day(i); v == nil {
+			b.Field(7).AppendNull()
+		} else {
+			b.Field(7).(*array.Date32Builder).Append(arrow.Date32(v.(int32)))
+		}
+		if v := flag(i); v == nil {
+			b.Field(8).AppendNull()
+		} else {
+			b.Field(8).(*array.BooleanBuilder).Append(v.(bool))
+		}
+		if v := score(i); v == nil {
+			b.Field(9).AppendNull()
+		} else {
+			b.Field(9).(*array.Float64Builder).Append(v.(float64))
+		}
+		if v := raw(i); v == nil {
+			b.Field(10).AppendNull()
+		} else {
+			b.Field(10).(*array.BinaryBuilder).Append(v.([]byte))
+		}
+		ob := b.Field(11).(*array.ListBuilder)
+		if v := matrix(i); v == nil {
+			ob.AppendNull()
+		} else {
+			ob.Append(true)
+			ib := ob.ValueBuilder().(*array.ListBuilder)
+			for _, inner := range v.([]any) {
+				ib.Append(true)
+				eb := ib.ValueBuilder().(*array.Int32Builder)
+				for _, x := range inner.([]any) {
+					eb.Append(x.(int32))
+				}
+			}
+		}
+	}
+	return b.NewRecord()
+}
+
+// golden returns row i as it is expected
+// to be converted: nulls are omitted from
+// structures and kept in lists, maps become
+// structures, and timestamps are strings
+func golden(i int) map[string]any {
+	m := map[string]any{"id": int64(i + 1)}
+	set := func(k string, v any) {
+		if v != nil {
+			m[k] = v
+		}
+	}
+	set("name", name(i))
+	set("tags", tags(i))
+	set("address", address(i))
+	set("attrs", attrs(i))
+	if v := price(i); v != nil {
+		m["price"] = float64(v.(int64)) / 100
+	}
+	if v := ts(i); v != nil {
+		m["ts"] = time.UnixMicro(v.(int64)).UTC().Format(time.RFC3339Nano)
+	}
+	if v := day(i); v != nil {
+		m["day"] = time.Unix(int64(v.(int32))*86400, 0).UTC().Format(time.RFC3339Nano)
+	}
+	set("flag", flag(i))
+	set("score", score(i))
+	if v := raw(i); v != nil {
+		if i%5 == 0 {
+			m["raw"] = base64.StdEncoding.EncodeToString(v.([]byte))
+		} else {
+			m["raw"] = string(v.([]byte))
+		}
+	}
+	set("matrix", matrix(i))
+	return m
+}
+
+type file struct {
+	name  string
+	props []parquet.WriterProperty
+	arrow []pqarrow.WriterOption
+	chunk int64
+}
+
+var files = []file{{
+	// converted types rather than logical types,
+	// and PLAIN encoding everywhere
+	name: "plain.parquet",
+	props: []parquet.WriterProperty{
+		parquet.WithVersion(parquet.V1_0),
+		parquet.WithDictionaryDefault(false),
+	},
+	chunk: rows,
+}, {
+	name: "dict-snappy.parquet",
+	props: []parquet.WriterProperty{
+		parquet.WithVersion(parquet.V2_LATEST),
+		parquet.WithDictionaryDefault(true),
+		parquet.WithCompression(compress.Codecs.Snappy),
+	},
+	chunk: rows,
+}, {
+	// data page v2 with many small pages
+	// and several row groups
+	name: "v2-zstd.parquet",
+	props: []parquet.WriterProperty{
+		parquet.WithVersion(parquet.V2_LATEST),
+		parquet.WithDataPageVersion(parquet.DataPageV2),
+		parquet.WithDictionaryDefault(true),
+		parquet.WithCompression(compress.Codecs.Zstd),
+		parquet.WithDataPageSize(64),
+		parquet.WithBatchSize(4),
+	},
+	chunk: 16,
+}, {
+	name: "int96-gzip.parquet",
+	props: []parquet.WriterProperty{
+		parquet.WithVersion(parquet.V2_4),
+		parquet.WithDictionaryDefault(false),
+		parquet.WithCompression(compress.Codecs.Gzip),
+	},
+	arrow: []pqarrow.WriterOption{pqarrow.WithDeprecatedInt96Timestamps(true)},
+	chunk: 7,
+}}
+
+func write(dir string, f *file) error {
+	rec := record()
+	defer rec.Release()
+	tbl := array.NewTableFromRecords(schema, []arrow.Record{rec})
+	defer tbl.Release()
+	out, err := os.Create(filepath.Join(dir, f.name))
+	if err != nil {
+		return err
+	}
+	props := parquet.NewWriterProperties(append(f.props, parquet.WithCreatedBy("sneller golden file generator"))...)
+	// WriteTable closes out
+	return pqarrow.WriteTable(tbl, out, f.chunk, props, pqarrow.NewArrowWriterProperties(f.arrow...))
+}
+
+func main() {
+	dir := "."
+	if len(os.Args) > 1 {
+		dir = os.Args[1]
+	}
+	for i := range files {
+		if err := write(dir, &files[i]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	out, err := os.Create(filepath.Join(dir, "golden.json"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	enc := json.NewEncoder(out)
+	for i := 0; i < rows; i++ {
+		if err := enc.Encode(golden(i)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if err := out.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
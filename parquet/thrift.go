@@ -0,0 +1,643 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// thrift compact protocol type codes
+const (
+	ctStop   = 0
+	ctTrue   = 1
+	ctFalse  = 2
+	ctByte   = 3
+	ctI16    = 4
+	ctI32    = 5
+	ctI64    = 6
+	ctDouble = 7
+	ctBinary = 8
+	ctList   = 9
+	ctSet    = 10
+	ctMap    = 11
+	ctStruct = 12
+)
+
+// maxDepth is the maximum nesting depth
+// of thrift structures that we accept
+const maxDepth = 64
+
+var errTruncated = errors.New("parquet: truncated thrift data")
+
+// decoder decodes the thrift compact protocol,
+// which is used for all of the parquet metadata
+//
+// decoding errors are sticky: once an error has
+// occurred, all subsequent reads return zero values
+// and the error is available in decoder.err
+type decoder struct {
+	buf   []byte
+	err   error
+	depth int
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+	d.buf = nil
+}
+
+func (d *decoder) byte() byte {
+	if len(d.buf) == 0 {
+		d.fail(errTruncated)
+		return 0
+	}
+	b := d.buf[0]
+	d.buf = d.buf[1:]
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	u, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail(errTruncated)
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return u
+}
+
+func (d *decoder) varint() int64 {
+	u := d.uvarint()
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (d *decoder) i32() int32 {
+	v := d.varint()
+	if v < math.MinInt32 || v > math.MaxInt32 {
+		d.fail(fmt.Errorf("parquet: thrift i32 %d out of range", v))
+		return 0
+	}
+	return int32(v)
+}
+
+func (d *decoder) i64() int64 { return d.varint() }
+
+func (d *decoder) double() float64 {
+	if len(d.buf) < 8 {
+		d.fail(errTruncated)
+		return 0
+	}
+	f := math.Float64frombits(binary.LittleEndian.Uint64(d.buf))
+	d.buf = d.buf[8:]
+	return f
+}
+
+func (d *decoder) binary() []byte {
+	n := d.uvarint()
+	if n > uint64(len(d.buf)) {
+		d.fail(errTruncated)
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) string() string { return string(d.binary()) }
+
+// boolean returns the value of a boolean
+// struct field with the given type code
+// (the value is part of the field header)
+func (d *decoder) boolean(typ byte) bool {
+	return typ == ctTrue
+}
+
+// list reads a list or set header
+// and returns the element type and
+// the number of elements
+func (d *decoder) list() (byte, int) {
+	h := d.byte()
+	typ := h & 0xf
+	n := uint64(h >> 4)
+	if n == 15 {
+		n = d.uvarint()
+	}
+	// every element occupies at least one byte
+	// (except for empty structs, which we never expect)
+	if n > uint64(len(d.buf)) {
+		d.fail(errTruncated)
+		return 0, 0
+	}
+	return typ, int(n)
+}
+
+// fields calls fn for each of the fields of
+// a structure; fn must consume the field
+// value or call skip
+func (d *decoder) fields(fn func(id int16, typ byte)) {
+	d.depth++
+	if d.depth > maxDepth {
+		d.fail(errors.New("parquet: thrift structure nested too deeply"))
+	}
+	last := int16(0)
+	for d.err == nil {
+		h := d.byte()
+		typ := h & 0xf
+		if typ == ctStop {
+			break
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			id = int16(d.varint())
+		}
+		last = id
+		fn(id, typ)
+	}
+	d.depth--
+}
+
+// skip skips a value of type typ
+func (d *decoder) skip(typ byte) {
+	switch typ {
+	case ctTrue, ctFalse:
+		// value is part of the field header
+	case ctByte:
+		d.byte()
+	case ctI16, ctI32, ctI64:
+		d.uvarint()
+	case ctDouble:
+		d.double()
+	case ctBinary:
+		d.binary()
+	case ctList, ctSet:
+		et, n := d.list()
+		for i := 0; i < n && d.err == nil; i++ {
+			if et == ctTrue || et == ctFalse {
+				d.byte()
+			} else {
+				d.skip(et)
+			}
+		}
+	case ctMap:
+		n := d.uvarint()
+		if n == 0 {
+			return
+		}
+		kv := d.byte()
+		for i := uint64(0); i < n && d.err == nil; i++ {
+			d.skip(kv >> 4)
+			d.skip(kv & 0xf)
+		}
+	case ctStruct:
+		d.fields(func(_ int16, typ byte) { d.skip(typ) })
+	default:
+		d.fail(fmt.Errorf("parquet: unknown thrift type %d", typ))
+	}
+}
+
+// physical types
+const (
+	typeBoolean           = 0
+	typeInt32             = 1
+	typeInt64             = 2
+	typeInt96             = 3
+	typeFloat             = 4
+	typeDouble            = 5
+	typeByteArray         = 6
+	typeFixedLenByteArray = 7
+)
+
+// field repetition types
+const (
+	repRequired = 0
+	repOptional = 1
+	repRepeated = 2
+)
+
+// converted types (the legacy logical type annotations)
+const (
+	convNone            = -1
+	convUTF8            = 0
+	convMap             = 1
+	convMapKeyValue     = 2
+	convList            = 3
+	convEnum            = 4
+	convDecimal         = 5
+	convDate            = 6
+	convTimeMillis      = 7
+	convTimeMicros      = 8
+	convTimestampMillis = 9
+	convTimestampMicros = 10
+	convUint8           = 11
+	convUint16          = 12
+	convUint32          = 13
+	convUint64          = 14
+	convInt8            = 15
+	convInt16           = 16
+	convInt32           = 17
+	convInt64           = 18
+	convJSON            = 19
+	convBSON            = 20
+)
+
+// logical types; the values are the
+// field IDs of the LogicalType union
+const (
+	logicalNone      = 0
+	logicalString    = 1
+	logicalMap       = 2
+	logicalList      = 3
+	logicalEnum      = 4
+	logicalDecimal   = 5
+	logicalDate      = 6
+	logicalTime      = 7
+	logicalTimestamp = 8
+	logicalInteger   = 10
+	logicalUnknown   = 11
+	logicalJSON      = 12
+	logicalBSON      = 13
+	logicalUUID      = 14
+	logicalFloat16   = 15
+)
+
+// time units; the values are the
+// field IDs of the TimeUnit union
+const (
+	unitMillis = 1
+	unitMicros = 2
+	unitNanos  = 3
+)
+
+// page types
+const (
+	pageData       = 0
+	pageIndex      = 1
+	pageDictionary = 2
+	pageDataV2     = 3
+)
+
+// encodings
+const (
+	encPlain                = 0
+	encPlainDictionary      = 2
+	encRLE                  = 3
+	encBitPacked            = 4
+	encDeltaBinaryPacked    = 5
+	encDeltaLengthByteArray = 6
+	encDeltaByteArray       = 7
+	encRLEDictionary        = 8
+	encByteStreamSplit      = 9
+)
+
+// compression codecs
+const (
+	codecUncompressed = 0
+	codecSnappy       = 1
+	codecGzip         = 2
+	codecLZO          = 3
+	codecBrotli       = 4
+	codecLZ4          = 5
+	codecZstd         = 6
+	codecLZ4Raw       = 7
+)
+
+type fileMetaData struct {
+	version   int32
+	schema    []schemaElement
+	numRows   int64
+	rowGroups []rowGroup
+	createdBy string
+}
+
+type schemaElement struct {
+	typ           int32 // -1 for groups
+	typeLength    int32
+	repetition    int32
+	name          string
+	numChildren   int32
+	convertedType int32
+	scale         int32
+	precision     int32
+	logical       logicalType
+}
+
+type logicalType struct {
+	kind int16 // one of the logical* constants
+	// DECIMAL
+	scale, precision int32
+	// TIME and TIMESTAMP
+	unit int16
+	// INTEGER
+	bitWidth int8
+	signed   bool
+}
+
+type rowGroup struct {
+	columns []columnChunk
+	numRows int64
+}
+
+type columnChunk struct {
+	filePath string
+	meta     columnMetaData
+}
+
+type columnMetaData struct {
+	typ                  int32
+	path                 []string
+	codec                int32
+	numValues            int64
+	totalCompressedSize  int64
+	dataPageOffset       int64
+	dictionaryPageOffset int64
+	hasDictionaryPage    bool
+}
+
+type pageHeader struct {
+	typ              int32
+	uncompressedSize int32
+	compressedSize   int32
+	data             dataPageHeader
+	dict             dictionaryPageHeader
+	data2            dataPageHeaderV2
+}
+
+type dataPageHeader struct {
+	numValues   int32
+	encoding    int32
+	defEncoding int32
+	repEncoding int32
+}
+
+type dictionaryPageHeader struct {
+	numValues int32
+	encoding  int32
+}
+
+type dataPageHeaderV2 struct {
+	numValues  int32
+	numNulls   int32
+	numRows    int32
+	encoding   int32
+	defLength  int32
+	repLength  int32
+	compressed bool
+}
+
+func (d *decoder) fileMetaData(m *fileMetaData) {
+	d.fields(func(id int16, typ byte) {
+		switch {
+		case id == 1 && typ == ctI32:
+			m.version = d.i32()
+		case id == 2 && typ == ctList:
+			et, n := d.list()
+			if et != ctStruct {
+				d.fail(fmt.Errorf("parquet: unexpected schema element type %d", et))
+				return
+			}
+			m.schema = make([]schemaElement, n)
+			for i := range m.schema {
+				d.schemaElement(&m.schema[i])
+			}
+		case id == 3 && typ == ctI64:
+			m.numRows = d.i64()
+		case id == 4 && typ == ctList:
+			et, n := d.list()
+			if et != ctStruct {
+				d.fail(fmt.Errorf("parquet: unexpected row group type %d", et))
+				return
+			}
+			m.rowGroups = make([]rowGroup, n)
+			for i := range m.rowGroups {
+				d.rowGroup(&m.rowGroups[i])
+			}
+		case id == 6 && typ == ctBinary:
+			m.createdBy = d.string()
+		default:
+			d.skip(typ)
+		}
+	})
+}
+
+func (d *decoder) schemaElement(s *schemaElement) {
+	s.typ = -1
+	s.convertedType = convNone
+	d.fields(func(id int16, typ byte) {
+		switch {
+		case id == 1 && typ == ctI32:
+			s.typ = d.i32()
+		case id == 2 && typ == ctI32:
+			s.typeLength = d.i32()
+		case id == 3 && typ == ctI32:
+			s.repetition = d.i32()
+		case id == 4 && typ == ctBinary:
+			s.name = d.string()
+		case id == 5 && typ == ctI32:
+			s.numChildren = d.i32()
+		case id == 6 && typ == ctI32:
+			s.convertedType = d.i32()
+		case id == 7 && typ == ctI32:
+			s.scale = d.i32()
+		case id == 8 && typ == ctI32:
+			s.precision = d.i32()
+		case id == 10 && typ == ctStruct:
+			d.logicalType(&s.logical)
+		default:
+			d.skip(typ)
+		}
+	})
+}
+
+func (d *decoder) logicalType(l *logicalType) {
+	d.fields(func(id int16, typ byte) {
+		if typ != ctStruct {
+			d.skip(typ)
+			return
+		}
+		l.kind = id
+		switch id {
+		case logicalDecimal:
+			d.fields(func(id int16, typ byte) {
+				switch {
+				case id == 1 && typ == ctI32:
+					l.scale = d.i32()
+				case id == 2 && typ == ctI32:
+					l.precision = d.i32()
+				default:
+					d.skip(typ)
+				}
+			})
+		case logicalTime, logicalTimestamp:
+			d.fields(func(id int16, typ byte) {
+				if id == 2 && typ == ctStruct {
+					// TimeUnit is a union of empty structs
+					d.fields(func(id int16, typ byte) {
+						l.unit = id
+						d.skip(typ)
+					})
+					return
+				}
+				d.skip(typ)
+			})
+		case logicalInteger:
+			d.fields(func(id int16, typ byte) {
+				switch {
+				case id == 1 && typ == ctByte:
+					l.bitWidth = int8(d.byte())
+				case id == 2 && (typ == ctTrue || typ == ctFalse):
+					l.signed = d.boolean(typ)
+				default:
+					d.skip(typ)
+				}
+			})
+		default:
+			d.skip(typ)
+		}
+	})
+}
+
+func (d *decoder) rowGroup(r *rowGroup) {
+	d.fields(func(id int16, typ byte) {
+		switch {
+		case id == 1 && typ == ctList:
+			et, n := d.list()
+			if et != ctStruct {
+				d.fail(fmt.Errorf("parquet: unexpected column chunk type %d", et))
+				return
+			}
+			r.columns = make([]columnChunk, n)
+			for i := range r.columns {
+				d.columnChunk(&r.columns[i])
+			}
+		case id == 3 && typ == ctI64:
+			r.numRows = d.i64()
+		default:
+			d.skip(typ)
+		}
+	})
+}
+
+func (d *decoder) columnChunk(c *columnChunk) {
+	d.fields(func(id int16, typ byte) {
+		switch {
+		case id == 1 && typ == ctBinary:
+			c.filePath = d.string()
+		case id == 3 && typ == ctStruct:
+			d.columnMetaData(&c.meta)
+		default:
+			d.skip(typ)
+		}
+	})
+}
+
+func (d *decoder) columnMetaData(m *columnMetaData) {
+	d.fields(func(id int16, typ byte) {
+		switch {
+		case id == 1 && typ == ctI32:
+			m.typ = d.i32()
+		case id == 3 && typ == ctList:
+			et, n := d.list()
+			if et != ctBinary {
+				d.fail(fmt.Errorf("parquet: unexpected path element type %d", et))
+				return
+			}
+			m.path = make([]string, n)
+			for i := range m.path {
+				m.path[i] = d.string()
+			}
+		case id == 4 && typ == ctI32:
+			m.codec = d.i32()
+		case id == 5 && typ == ctI64:
+			m.numValues = d.i64()
+		case id == 7 && typ == ctI64:
+			m.totalCompressedSize = d.i64()
+		case id == 9 && typ == ctI64:
+			m.dataPageOffset = d.i64()
+		case id == 11 && typ == ctI64:
+			m.dictionaryPageOffset = d.i64()
+			m.hasDictionaryPage = true
+		default:
+			d.skip(typ)
+		}
+	})
+}
+
+func (d *decoder) pageHeader(p *pageHeader) {
+	p.data2.compressed = true
+	d.fields(func(id int16, typ byte) {
+		switch {
+		case id == 1 && typ == ctI32:
+			p.typ = d.i32()
+		case id == 2 && typ == ctI32:
+			p.uncompressedSize = d.i32()
+		case id == 3 && typ == ctI32:
+			p.compressedSize = d.i32()
+		case id == 5 && typ == ctStruct:
+			d.fields(func(id int16, typ byte) {
+				switch {
+				case id == 1 && typ == ctI32:
+					p.data.numValues = d.i32()
+				case id == 2 && typ == ctI32:
+					p.data.encoding = d.i32()
+				case id == 3 && typ == ctI32:
+					p.data.defEncoding = d.i32()
+				case id == 4 && typ == ctI32:
+					p.data.repEncoding = d.i32()
+				default:
+					d.skip(typ)
+				}
+			})
+		case id == 7 && typ == ctStruct:
+			d.fields(func(id int16, typ byte) {
+				switch {
+				case id == 1 && typ == ctI32:
+					p.dict.numValues = d.i32()
+				case id == 2 && typ == ctI32:
+					p.dict.encoding = d.i32()
+				default:
+					d.skip(typ)
+				}
+			})
+		case id == 8 && typ == ctStruct:
+			d.fields(func(id int16, typ byte) {
+				switch {
+				case id == 1 && typ == ctI32:
+					p.data2.numValues = d.i32()
+				case id == 2 && typ == ctI32:
+					p.data2.numNulls = d.i32()
+				case id == 3 && typ == ctI32:
+					p.data2.numRows = d.i32()
+				case id == 4 && typ == ctI32:
+					p.data2.encoding = d.i32()
+				case id == 5 && typ == ctI32:
+					p.data2.defLength = d.i32()
+				case id == 6 && typ == ctI32:
+					p.data2.repLength = d.i32()
+				case id == 7 && (typ == ctTrue || typ == ctFalse):
+					p.data2.compressed = d.boolean(typ)
+				default:
+					d.skip(typ)
+				}
+			})
+		default:
+			d.skip(typ)
+		}
+	})
+}
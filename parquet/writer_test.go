@@ -0,0 +1,694 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/SnellerInc/sneller/compr"
+
+	"github.com/klauspost/compress/s2"
+)
+
+// this file implements a minimal parquet
+// writer that is used to produce test inputs
+
+// tenc encodes the thrift compact protocol
+type tenc struct {
+	buf  []byte
+	last []int16
+}
+
+func (e *tenc) uvarint(u uint64) { e.buf = binary.AppendUvarint(e.buf, u) }
+func (e *tenc) varint(v int64)   { e.uvarint(uint64(v<<1) ^ uint64(v>>63)) }
+
+func (e *tenc) begin() { e.last = append(e.last, 0) }
+
+func (e *tenc) end() {
+	e.buf = append(e.buf, ctStop)
+	e.last = e.last[:len(e.last)-1]
+}
+
+func (e *tenc) field(id int16, typ byte) {
+	last := &e.last[len(e.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		e.buf = append(e.buf, byte(delta)<<4|typ)
+	} else {
+		e.buf = append(e.buf, typ)
+		e.varint(int64(id))
+	}
+	*last = id
+}
+
+func (e *tenc) i32(id int16, v int32) {
+	e.field(id, ctI32)
+	e.varint(int64(v))
+}
+
+func (e *tenc) i64(id int16, v int64) {
+	e.field(id, ctI64)
+	e.varint(v)
+}
+
+func (e *tenc) binary(id int16, b string) {
+	e.field(id, ctBinary)
+	e.uvarint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *tenc) boolean(id int16, v bool) {
+	if v {
+		e.field(id, ctTrue)
+	} else {
+		e.field(id, ctFalse)
+	}
+}
+
+func (e *tenc) list(id int16, et byte, n int) {
+	e.field(id, ctList)
+	if n < 15 {
+		e.buf = append(e.buf, byte(n)<<4|et)
+	} else {
+		e.buf = append(e.buf, 0xf0|et)
+		e.uvarint(uint64(n))
+	}
+}
+
+// structure writes a structure field
+// with contents written by fn
+func (e *tenc) structure(id int16, fn func()) {
+	e.field(id, ctStruct)
+	e.begin()
+	fn()
+	e.end()
+}
+
+func (e *tenc) logicalType(l *logicalType) {
+	e.begin()
+	e.structure(l.kind, func() {
+		switch l.kind {
+		case logicalDecimal:
+			e.i32(1, l.scale)
+			e.i32(2, l.precision)
+		case logicalTime, logicalTimestamp:
+			e.boolean(1, true)
+			e.structure(2, func() {
+				e.structure(l.unit, func() {})
+			})
+		case logicalInteger:
+			e.field(1, ctByte)
+			e.buf = append(e.buf, byte(l.bitWidth))
+			e.boolean(2, l.signed)
+		}
+	})
+	e.end()
+}
+
+func (e *tenc) schemaElement(s *schemaElement, root bool) {
+	e.begin()
+	if s.typ >= 0 {
+		e.i32(1, s.typ)
+	}
+	if s.typeLength > 0 {
+		e.i32(2, s.typeLength)
+	}
+	if !root {
+		e.i32(3, s.repetition)
+	}
+	e.binary(4, s.name)
+	if s.numChildren > 0 {
+		e.i32(5, s.numChildren)
+	}
+	if s.convertedType != convNone {
+		e.i32(6, s.convertedType)
+	}
+	if s.scale != 0 || s.precision != 0 {
+		e.i32(7, s.scale)
+		e.i32(8, s.precision)
+	}
+	if s.logical.kind != logicalNone {
+		e.field(10, ctStruct)
+		e.logicalType(&s.logical)
+	}
+	e.end()
+}
+
+// testColumn is the contents of
+// a column of a test file
+type testColumn struct {
+	typ            int32
+	typeLength     int
+	path           []string
+	maxRep, maxDef int
+	rep, def       []int32
+	vals           values
+}
+
+// rows returns the index of the
+// first entry of each row
+func (c *testColumn) rows() []int {
+	var starts []int
+	n := len(c.def)
+	if c.maxDef == 0 {
+		n = c.vals.len()
+	}
+	for i := 0; i < n; i++ {
+		if c.rep == nil || c.rep[i] == 0 {
+			starts = append(starts, i)
+		}
+	}
+	return append(starts, n)
+}
+
+// present returns the number of values
+// in the first n entries of c
+func (c *testColumn) present(n int) int {
+	if c.maxDef == 0 {
+		return n
+	}
+	k := 0
+	for _, d := range c.def[:n] {
+		if int(d) == c.maxDef {
+			k++
+		}
+	}
+	return k
+}
+
+func sliceValues(v *values, lo, hi int) values {
+	var out values
+	switch {
+	case v.bools != nil:
+		out.bools = v.bools[lo:hi]
+	case v.i32 != nil:
+		out.i32 = v.i32[lo:hi]
+	case v.i64 != nil:
+		out.i64 = v.i64[lo:hi]
+	case v.f32 != nil:
+		out.f32 = v.f32[lo:hi]
+	case v.f64 != nil:
+		out.f64 = v.f64[lo:hi]
+	case v.bytes != nil:
+		out.bytes = v.bytes[lo:hi]
+	}
+	return out
+}
+
+// slice returns the rows [lo, hi) of c
+func (c *testColumn) slice(lo, hi int) *testColumn {
+	starts := c.rows()
+	elo, ehi := starts[lo], starts[hi]
+	out := *c
+	if c.rep != nil {
+		out.rep = c.rep[elo:ehi]
+	}
+	if c.def != nil {
+		out.def = c.def[elo:ehi]
+	}
+	out.vals = sliceValues(&c.vals, c.present(elo), c.present(ehi))
+	return &out
+}
+
+func (c *testColumn) entries() int {
+	if c.maxDef == 0 {
+		return c.vals.len()
+	}
+	return len(c.def)
+}
+
+func (c *testColumn) nulls() int {
+	return c.entries() - c.vals.len()
+}
+
+// rle encodes v with the RLE/bit-packing hybrid
+// encoding, using repeated runs for runs of values
+// that are at least 8 long and bit-packed runs otherwise
+func rle(v []int32, w int) []byte {
+	var out []byte
+	for i := 0; i < len(v); {
+		j := i
+		for j < len(v) && v[j] == v[i] {
+			j++
+		}
+		if j-i >= 8 || j == len(v) && i == 0 {
+			out = binary.AppendUvarint(out, uint64(j-i)<<1)
+			for k := 0; k < (w+7)/8; k++ {
+				out = append(out, byte(v[i]>>(8*k)))
+			}
+			i = j
+			continue
+		}
+		// bit-pack up to the start of
+		// the next long run, in groups of 8
+		end := i + 8
+		for end < len(v) {
+			k := end
+			for k < len(v) && v[k] == v[end] {
+				k++
+			}
+			if k-end >= 8 {
+				break
+			}
+			end += 8
+		}
+		if end > len(v) {
+			end = len(v)
+		}
+		groups := (end - i + 7) / 8
+		out = binary.AppendUvarint(out, uint64(groups)<<1|1)
+		packed := make([]byte, groups*w)
+		for k := i; k < end; k++ {
+			for b := 0; b < w; b++ {
+				if v[k]&(1<<b) != 0 {
+					bit := (k-i)*w + b
+					packed[bit>>3] |= 1 << (bit & 7)
+				}
+			}
+		}
+		out = append(out, packed...)
+		i = end
+	}
+	return out
+}
+
+func plainValues(c *testColumn, v *values) []byte {
+	var out []byte
+	switch c.typ {
+	case typeBoolean:
+		out = make([]byte, (len(v.bools)+7)/8)
+		for i, b := range v.bools {
+			if b {
+				out[i>>3] |= 1 << (i & 7)
+			}
+		}
+	case typeInt32:
+		for _, x := range v.i32 {
+			out = binary.LittleEndian.AppendUint32(out, uint32(x))
+		}
+	case typeInt64:
+		for _, x := range v.i64 {
+			out = binary.LittleEndian.AppendUint64(out, uint64(x))
+		}
+	case typeFloat:
+		for _, x := range v.f32 {
+			out = binary.LittleEndian.AppendUint32(out, math.Float32bits(x))
+		}
+	case typeDouble:
+		for _, x := range v.f64 {
+			out = binary.LittleEndian.AppendUint64(out, math.Float64bits(x))
+		}
+	case typeByteArray:
+		for _, b := range v.bytes {
+			out = binary.LittleEndian.AppendUint32(out, uint32(len(b)))
+			out = append(out, b...)
+		}
+	default:
+		for _, b := range v.bytes {
+			out = append(out, b...)
+		}
+	}
+	return out
+}
+
+// deltaEncode encodes v with DELTA_BINARY_PACKED
+// using blocks of 128 values and 4 miniblocks
+func deltaEncode(v []int64) []byte {
+	const block, mini = 128, 4
+	const per = block / mini
+	out := binary.AppendUvarint(nil, block)
+	out = binary.AppendUvarint(out, mini)
+	out = binary.AppendUvarint(out, uint64(len(v)))
+	if len(v) == 0 {
+		return binary.AppendVarint(out, 0)
+	}
+	out = binary.AppendVarint(out, v[0])
+	for i := 1; i < len(v); i += block {
+		end := i + block
+		if end > len(v) {
+			end = len(v)
+		}
+		deltas := make([]int64, end-i)
+		for j := range deltas {
+			deltas[j] = v[i+j] - v[i+j-1]
+		}
+		min := deltas[0]
+		for _, d := range deltas {
+			if d < min {
+				min = d
+			}
+		}
+		out = binary.AppendVarint(out, min)
+		widths := make([]byte, mini)
+		for m := range widths {
+			for j := m * per; j < (m+1)*per && j < len(deltas); j++ {
+				if w := bits.Len64(uint64(deltas[j] - min)); w > int(widths[m]) {
+					widths[m] = byte(w)
+				}
+			}
+		}
+		out = append(out, widths...)
+		for m := 0; m*per < len(deltas); m++ {
+			w := int(widths[m])
+			packed := make([]byte, per*w/8)
+			for j := 0; j < per && m*per+j < len(deltas); j++ {
+				u := uint64(deltas[m*per+j] - min)
+				for b := 0; b < w; b++ {
+					if u&(1<<b) != 0 {
+						bit := j*w + b
+						packed[bit>>3] |= 1 << (bit & 7)
+					}
+				}
+			}
+			out = append(out, packed...)
+		}
+	}
+	return out
+}
+
+// deltaValues encodes the values of c with the
+// DELTA_BINARY_PACKED or DELTA_LENGTH_BYTE_ARRAY encoding
+func deltaValues(c *testColumn, v *values) (int32, []byte) {
+	switch c.typ {
+	case typeInt32:
+		ints := make([]int64, len(v.i32))
+		for i := range v.i32 {
+			ints[i] = int64(v.i32[i])
+		}
+		return encDeltaBinaryPacked, deltaEncode(ints)
+	case typeInt64:
+		return encDeltaBinaryPacked, deltaEncode(v.i64)
+	case typeByteArray:
+		lengths := make([]int64, len(v.bytes))
+		var data []byte
+		for i, b := range v.bytes {
+			lengths[i] = int64(len(b))
+			data = append(data, b...)
+		}
+		return encDeltaLengthByteArray, append(deltaEncode(lengths), data...)
+	}
+	return encPlain, plainValues(c, v)
+}
+
+// dictionary returns the dictionary of the values
+// of c and the index of each value of v
+func dictionary(c *testColumn, v *values) (*values, []int32) {
+	dict := new(values)
+	var idx []int32
+	seen := make(map[string]int32)
+	for i := 0; i < v.len(); i++ {
+		one := sliceValues(v, i, i+1)
+		key := string(plainValues(c, &one))
+		j, ok := seen[key]
+		if !ok {
+			j = int32(dict.len())
+			seen[key] = j
+			switch {
+			case one.bools != nil:
+				dict.bools = append(dict.bools, one.bools...)
+			case one.i32 != nil:
+				dict.i32 = append(dict.i32, one.i32...)
+			case one.i64 != nil:
+				dict.i64 = append(dict.i64, one.i64...)
+			case one.f32 != nil:
+				dict.f32 = append(dict.f32, one.f32...)
+			case one.f64 != nil:
+				dict.f64 = append(dict.f64, one.f64...)
+			default:
+				dict.bytes = append(dict.bytes, one.bytes...)
+			}
+		}
+		idx = append(idx, j)
+	}
+	return dict, idx
+}
+
+type writeOptions struct {
+	v2       bool  // use data page v2
+	codec    int32 // compression codec
+	dict     bool  // dictionary-encode values
+	delta    bool  // delta-encode values (if not dict)
+	rowGroup int   // rows per row group, or 0
+	page     int   // rows per page, or 0
+}
+
+func (o *writeOptions) String() string {
+	return fmt.Sprintf("v2=%v/codec=%d/dict=%v/delta=%v/rg=%d/page=%d",
+		o.v2, o.codec, o.dict, o.delta, o.rowGroup, o.page)
+}
+
+func compress(codec int32, b []byte) []byte {
+	switch codec {
+	case codecSnappy:
+		return s2.EncodeSnappy(nil, b)
+	case codecGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(b)
+		w.Close()
+		return buf.Bytes()
+	case codecZstd:
+		return compr.Compression("zstd").Compress(b, nil)
+	}
+	return b
+}
+
+type chunkInfo struct {
+	col                 *testColumn
+	offset, dictOffset  int64
+	compressed, uncompr int64
+	encodings           []int32
+	numValues           int64
+	hasDict             bool
+}
+
+type testFile struct {
+	buf []byte
+	opt *writeOptions
+}
+
+func (f *testFile) pageHeader(typ int32, uncompressed, compressed int, fn func(e *tenc)) {
+	e := tenc{buf: f.buf}
+	e.begin()
+	e.i32(1, typ)
+	e.i32(2, int32(uncompressed))
+	e.i32(3, int32(compressed))
+	fn(&e)
+	e.end()
+	f.buf = e.buf
+}
+
+func (f *testFile) page(c *testColumn, dict *values, ci *chunkInfo) {
+	var repLvl, defLvl []byte
+	if c.maxRep > 0 {
+		repLvl = rle(c.rep, bits.Len(uint(c.maxRep)))
+	}
+	if c.maxDef > 0 {
+		defLvl = rle(c.def, bits.Len(uint(c.maxDef)))
+	}
+	enc := int32(encPlain)
+	var data []byte
+	switch {
+	case dict != nil:
+		enc = encRLEDictionary
+		_, idx := dictionary(c, &c.vals)
+		// the indices refer to the dictionary of
+		// the whole column chunk, so look them up
+		for i := range idx {
+			one := sliceValues(&c.vals, i, i+1)
+			key := string(plainValues(c, &one))
+			for j := 0; j < dict.len(); j++ {
+				d := sliceValues(dict, j, j+1)
+				if string(plainValues(c, &d)) == key {
+					idx[i] = int32(j)
+					break
+				}
+			}
+		}
+		w := bits.Len(uint(dict.len()))
+		data = append([]byte{byte(w)}, rle(idx, w)...)
+	case f.opt.delta:
+		enc, data = deltaValues(c, &c.vals)
+	default:
+		data = plainValues(c, &c.vals)
+	}
+	ci.encodings = append(ci.encodings, enc)
+	ci.numValues += int64(c.entries())
+	start := len(f.buf)
+	if f.opt.v2 {
+		cdata := compress(f.opt.codec, data)
+		rows := len(c.rows()) - 1
+		size := len(repLvl) + len(defLvl)
+		f.pageHeader(pageDataV2, size+len(data), size+len(cdata), func(e *tenc) {
+			e.structure(8, func() {
+				e.i32(1, int32(c.entries()))
+				e.i32(2, int32(c.nulls()))
+				e.i32(3, int32(rows))
+				e.i32(4, enc)
+				e.i32(5, int32(len(defLvl)))
+				e.i32(6, int32(len(repLvl)))
+			})
+		})
+		ci.uncompr += int64(len(f.buf)-start) + int64(size+len(data))
+		f.buf = append(f.buf, repLvl...)
+		f.buf = append(f.buf, defLvl...)
+		f.buf = append(f.buf, cdata...)
+		ci.compressed += int64(len(f.buf) - start)
+		return
+	}
+	var body []byte
+	if repLvl != nil {
+		body = binary.LittleEndian.AppendUint32(body, uint32(len(repLvl)))
+		body = append(body, repLvl...)
+	}
+	if defLvl != nil {
+		body = binary.LittleEndian.AppendUint32(body, uint32(len(defLvl)))
+		body = append(body, defLvl...)
+	}
+	body = append(body, data...)
+	cbody := compress(f.opt.codec, body)
+	f.pageHeader(pageData, len(body), len(cbody), func(e *tenc) {
+		e.structure(5, func() {
+			e.i32(1, int32(c.entries()))
+			e.i32(2, enc)
+			e.i32(3, encRLE)
+			e.i32(4, encRLE)
+		})
+	})
+	ci.uncompr += int64(len(f.buf)-start) + int64(len(body))
+	f.buf = append(f.buf, cbody...)
+	ci.compressed += int64(len(f.buf) - start)
+}
+
+func (f *testFile) chunk(c *testColumn) *chunkInfo {
+	ci := &chunkInfo{col: c, offset: int64(len(f.buf))}
+	var dict *values
+	if f.opt.dict {
+		dict, _ = dictionary(c, &c.vals)
+		ci.hasDict = true
+		ci.dictOffset = int64(len(f.buf))
+		body := plainValues(c, dict)
+		cbody := compress(f.opt.codec, body)
+		start := len(f.buf)
+		f.pageHeader(pageDictionary, len(body), len(cbody), func(e *tenc) {
+			e.structure(7, func() {
+				e.i32(1, int32(dict.len()))
+				e.i32(2, encPlain)
+			})
+		})
+		ci.uncompr += int64(len(f.buf)-start) + int64(len(body))
+		f.buf = append(f.buf, cbody...)
+		ci.compressed += int64(len(f.buf) - start)
+		ci.encodings = append(ci.encodings, encPlain)
+	}
+	dataOffset := int64(len(f.buf))
+	rows := len(c.rows()) - 1
+	step := f.opt.page
+	if step <= 0 {
+		step = rows
+	}
+	for lo := 0; lo < rows || lo == 0; lo += step {
+		hi := lo + step
+		if hi > rows {
+			hi = rows
+		}
+		f.page(c.slice(lo, hi), dict, ci)
+		if hi == rows {
+			break
+		}
+	}
+	ci.offset = dataOffset
+	return ci
+}
+
+// writeTestFile produces a parquet file with
+// the given schema and column contents
+func writeTestFile(schema []schemaElement, cols []testColumn, opt *writeOptions) []byte {
+	f := &testFile{buf: []byte(magic), opt: opt}
+	rows := len(cols[0].rows()) - 1
+	step := opt.rowGroup
+	if step <= 0 {
+		step = rows
+	}
+	type group struct {
+		rows   int
+		chunks []*chunkInfo
+	}
+	var groups []group
+	for lo := 0; lo < rows; lo += step {
+		hi := lo + step
+		if hi > rows {
+			hi = rows
+		}
+		g := group{rows: hi - lo}
+		for i := range cols {
+			g.chunks = append(g.chunks, f.chunk(cols[i].slice(lo, hi)))
+		}
+		groups = append(groups, g)
+	}
+
+	e := tenc{buf: nil}
+	e.begin()
+	e.i32(1, 1)
+	e.list(2, ctStruct, len(schema))
+	for i := range schema {
+		e.schemaElement(&schema[i], i == 0)
+	}
+	e.i64(3, int64(rows))
+	e.list(4, ctStruct, len(groups))
+	for _, g := range groups {
+		e.begin()
+		e.list(1, ctStruct, len(g.chunks))
+		total := int64(0)
+		for _, ci := range g.chunks {
+			e.begin()
+			e.i64(2, ci.offset)
+			e.structure(3, func() {
+				e.i32(1, ci.col.typ)
+				e.list(2, ctI32, len(ci.encodings))
+				for _, enc := range ci.encodings {
+					e.varint(int64(enc))
+				}
+				e.list(3, ctBinary, len(ci.col.path))
+				for _, p := range ci.col.path {
+					e.uvarint(uint64(len(p)))
+					e.buf = append(e.buf, p...)
+				}
+				e.i32(4, f.opt.codec)
+				e.i64(5, ci.numValues)
+				e.i64(6, ci.uncompr)
+				e.i64(7, ci.compressed)
+				e.i64(9, ci.offset)
+				if ci.hasDict {
+					e.i64(11, ci.dictOffset)
+				}
+			})
+			e.end()
+			total += ci.uncompr
+		}
+		e.i64(2, total)
+		e.i64(3, int64(g.rows))
+		e.end()
+	}
+	e.binary(6, "sneller test writer")
+	e.end()
+	f.buf = append(f.buf, e.buf...)
+	f.buf = binary.LittleEndian.AppendUint32(f.buf, uint32(len(e.buf)))
+	return append(f.buf, magic...)
+}
@@ -0,0 +1,202 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package plan
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/ion"
+	"github.com/SnellerInc/sneller/ion/blockfmt"
+	"github.com/SnellerInc/sneller/vm"
+)
+
+// probeStats are the statistics collected
+// for the output of a single Op
+type probeStats struct {
+	rows, bytes int64
+	start, end  int64 // unix nanoseconds
+}
+
+func (p *probeStats) wall() int64 {
+	start, end := atomic.LoadInt64(&p.start), atomic.LoadInt64(&p.end)
+	if start == 0 || end < start {
+		return 0
+	}
+	return end - start
+}
+
+// probeSink is a vm.QuerySink that
+// records the rows and bytes written
+// into dst, along with the span of time
+// between the first call to Open and
+// the call to Close
+type probeSink struct {
+	dst   vm.QuerySink
+	stats *probeStats
+}
+
+func (p *probeSink) Open() (io.WriteCloser, error) {
+	atomic.CompareAndSwapInt64(&p.stats.start, 0, time.Now().UnixNano())
+	w, err := p.dst.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &probeWriter{dst: w, stats: p.stats}, nil
+}
+
+func (p *probeSink) Close() error {
+	err := p.dst.Close()
+	atomic.StoreInt64(&p.stats.end, time.Now().UnixNano())
+	return err
+}
+
+type probeWriter struct {
+	dst   io.WriteCloser
+	stats *probeStats
+}
+
+func (p *probeWriter) Write(buf []byte) (int, error) {
+	atomic.AddInt64(&p.stats.rows, blockfmt.CountRows(buf))
+	atomic.AddInt64(&p.stats.bytes, int64(len(buf)))
+	return p.dst.Write(buf)
+}
+
+func (p *probeWriter) Close() error { return p.dst.Close() }
+
+// EndSegment implements vm.EndSegmentWriter
+func (p *probeWriter) EndSegment() { vm.HintEndSegment(p.dst) }
+
+// probe is an Op that is transparently
+// inserted between an Op and its input
+// in order to record the statistics
+// of the rows produced by the input
+type probe struct {
+	Nonterminal
+	stats probeStats
+}
+
+func (p *probe) String() string { return p.From.String() }
+
+func (p *probe) exec(dst vm.QuerySink, src *Input, ep *ExecParams) error {
+	return p.From.exec(&probeSink{dst: dst, stats: &p.stats}, src, ep)
+}
+
+// probes are only inserted into the part of the
+// plan that analyze executes locally, so they
+// are never sent to a remote Transport
+func (p *probe) encode(dst *ion.Buffer, st *ion.Symtab, ep *ExecParams) error {
+	return fmt.Errorf("plan.probe: cannot encode")
+}
+
+func (p *probe) SetField(f ion.Field) error {
+	return fmt.Errorf("plan.probe: cannot decode")
+}
+
+type discardSink struct{}
+
+type discardWriter struct{}
+
+func (discardSink) Open() (io.WriteCloser, error) { return discardWriter{}, nil }
+func (discardSink) Close() error                  { return nil }
+
+func (discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (discardWriter) Close() error                { return nil }
+
+// analyze executes e.Tree and writes the
+// plan annotated with the statistics
+// collected for each Op into dst
+//
+// NOTE: ops that are executed by a remote
+// Transport are accounted for as part of
+// the op that dispatched them
+func (e *Explain) analyze(dst vm.QuerySink, ep *ExecParams) error {
+	// ops[i] writes into the sink measured by probes[i]
+	var ops []Op
+	var probes []*probe
+	root := &probe{}
+	for op := e.Tree.Root.Op; op != nil; op = op.input() {
+		if len(ops) > 0 {
+			p := &probe{Nonterminal: Nonterminal{From: op}}
+			ops[len(ops)-1].setinput(p)
+			probes = append(probes, p)
+		} else {
+			probes = append(probes, root)
+		}
+		ops = append(ops, op)
+	}
+	defer func() {
+		for i := 1; i < len(ops); i++ {
+			ops[i-1].setinput(ops[i])
+		}
+	}()
+
+	scanned := atomic.LoadInt64(&ep.Stats.BytesScanned)
+	start := time.Now()
+	err := e.Tree.exec(&probeSink{dst: discardSink{}, stats: &root.stats}, ep)
+	wall := time.Since(start)
+	if err != nil {
+		return err
+	}
+	scanned = atomic.LoadInt64(&ep.Stats.BytesScanned) - scanned
+
+	var st ion.Symtab
+	var body ion.Buffer
+	body.BeginStruct(-1)
+	body.BeginField(st.Intern("query"))
+	body.WriteString(expr.ToString(e.Query))
+	body.BeginField(st.Intern("wall-ns"))
+	body.WriteInt(wall.Nanoseconds())
+	body.BeginField(st.Intern("bytes-scanned"))
+	body.WriteInt(scanned)
+	body.BeginField(st.Intern("analyze"))
+	for i := range ops {
+		if i > 0 {
+			body.BeginField(st.Intern("input"))
+		}
+		body.BeginStruct(-1)
+		body.BeginField(st.Intern("op"))
+		body.WriteString(ops[i].String())
+		if i+1 < len(ops) {
+			in := &probes[i+1].stats
+			body.BeginField(st.Intern("rows-in"))
+			body.WriteInt(atomic.LoadInt64(&in.rows))
+			body.BeginField(st.Intern("bytes-in"))
+			body.WriteInt(atomic.LoadInt64(&in.bytes))
+		} else {
+			body.BeginField(st.Intern("bytes-scanned"))
+			body.WriteInt(scanned)
+		}
+		out := &probes[i].stats
+		body.BeginField(st.Intern("rows-out"))
+		body.WriteInt(atomic.LoadInt64(&out.rows))
+		body.BeginField(st.Intern("bytes-out"))
+		body.WriteInt(atomic.LoadInt64(&out.bytes))
+		body.BeginField(st.Intern("wall-ns"))
+		body.WriteInt(out.wall())
+	}
+	for range ops {
+		body.EndStruct()
+	}
+	body.EndStruct()
+
+	var b ion.Buffer
+	st.Marshal(&b, true)
+	b.UnsafeAppend(body.Bytes())
+	return writeIon(&b, dst)
+}
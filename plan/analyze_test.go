@@ -0,0 +1,119 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package plan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/SnellerInc/sneller/expr/partiql"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+func TestExplainAnalyze(t *testing.T) {
+	env := &testenv{t: t}
+	q, err := partiql.Parse([]byte(`EXPLAIN AS analyze SELECT COUNT(*) FROM parking WHERE Make = 'ACUR'`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := New(q, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dst bytes.Buffer
+	ep := &ExecParams{
+		Plan:   tree,
+		Output: &dst,
+		Runner: env,
+	}
+	err = Exec(ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var st ion.Symtab
+	d, _, err := ion.ReadDatum(&st, dst.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("%s", toJSON(&st, d))
+
+	wall := func(t *testing.T, s ion.Struct) {
+		f, ok := s.FieldByName("wall-ns")
+		if !ok {
+			t.Fatal("missing wall-ns")
+		}
+		n, err := f.Int()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n <= 0 {
+			t.Errorf("wall-ns = %d", n)
+		}
+	}
+	count := func(t *testing.T, s ion.Struct, field string) int64 {
+		f, ok := s.FieldByName(field)
+		if !ok {
+			t.Fatalf("missing %s", field)
+		}
+		n, err := f.Int()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	top, err := d.Struct()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wall(t, top)
+	if n := count(t, top, "bytes-scanned"); n != parkingBytes {
+		t.Errorf("bytes-scanned = %d, want %d", n, parkingBytes)
+	}
+	f, ok := top.FieldByName("analyze")
+	if !ok {
+		t.Fatal("missing analyze")
+	}
+	// walk to the leaf, checking that
+	// the rows in and out of each op line up
+	s, err := f.Struct()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := count(t, s, "rows-out"); n != 1 {
+		t.Errorf("root rows-out = %d, want 1", n)
+	}
+	for {
+		wall(t, s)
+		f, ok := s.FieldByName("input")
+		if !ok {
+			break
+		}
+		in, err := f.Struct()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := count(t, s, "rows-in"), count(t, in, "rows-out"); got != want {
+			t.Errorf("rows-in = %d but input rows-out = %d", got, want)
+		}
+		s = in
+	}
+	if n := count(t, s, "rows-out"); n != 1023 {
+		t.Errorf("leaf rows-out = %d, want 1023", n)
+	}
+	if n := count(t, s, "bytes-scanned"); n != parkingBytes {
+		t.Errorf("leaf bytes-scanned = %d, want %d", n, parkingBytes)
+	}
+}
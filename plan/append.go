@@ -0,0 +1,142 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package plan
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/ion"
+	"github.com/SnellerInc/sneller/vm"
+)
+
+// Append produces the rows of its input
+// followed by one row for each element of Expr,
+// in which Result is bound to the element
+type Append struct {
+	Nonterminal
+	Expr   expr.Node
+	Result string
+	// Project, if non-nil, is a projection
+	// applied to the appended rows only
+	Project []expr.Binding
+}
+
+// extra returns the op that produces the appended rows
+func (a *Append) extra() Op {
+	var op Op = &Unnest{
+		Nonterminal: Nonterminal{From: DummyOutput{}},
+		Expr:        a.Expr,
+		Result:      a.Result,
+	}
+	if a.Project != nil {
+		op = &Project{
+			Nonterminal: Nonterminal{From: op},
+			Using:       a.Project,
+		}
+	}
+	return op
+}
+
+func (a *Append) exec(dst vm.QuerySink, src *Input, ep *ExecParams) error {
+	mw, err := newOpenSink(dst, ep.Parallel)
+	if err != nil {
+		return err
+	}
+	xw, err := newOpenSink(dst, 1)
+	if err != nil {
+		mw.Close()
+		dst.Close()
+		return err
+	}
+	subep := ep.clone()
+	subep.Parallel = mw.Len()
+	err = a.From.exec(mw, src, subep)
+	ep.Stats.atomicAdd(&subep.Stats)
+	if err == nil || errors.Is(err, io.EOF) {
+		err = a.extra().exec(xw, src, ep)
+	} else {
+		xw.Close()
+	}
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	err2 := dst.Close()
+	if err == nil {
+		err = err2
+	}
+	return err
+}
+
+func (a *Append) encode(dst *ion.Buffer, st *ion.Symtab, ep *ExecParams) error {
+	dst.BeginStruct(-1)
+	settype("append", dst, st)
+	dst.BeginField(st.Intern("expr"))
+	ep.rewrite(a.Expr).Encode(dst, st)
+	dst.BeginField(st.Intern("result"))
+	dst.WriteString(a.Result)
+	if a.Project != nil {
+		dst.BeginField(st.Intern("project"))
+		encodeBindings(a.Project, dst, st, ep)
+	}
+	dst.EndStruct()
+	return nil
+}
+
+func (a *Append) SetField(f ion.Field) error {
+	switch f.Label {
+	case "expr":
+		e, err := expr.Decode(f.Datum)
+		if err != nil {
+			return err
+		}
+		a.Expr = e
+	case "result":
+		s, err := f.String()
+		if err != nil {
+			return err
+		}
+		a.Result = s
+	case "project":
+		bind, err := expr.DecodeBindings(f.Datum)
+		if err != nil {
+			return err
+		}
+		a.Project = bind
+	default:
+		return errUnexpectedField
+	}
+	return nil
+}
+
+func (a *Append) String() string {
+	var out strings.Builder
+	out.WriteString("APPEND ")
+	out.WriteString(expr.ToString(a.Expr))
+	out.WriteString(" AS ")
+	out.WriteString(a.Result)
+	if a.Project != nil {
+		out.WriteString(" PROJECT ")
+		for i := range a.Project {
+			if i != 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(expr.ToString(&a.Project[i]))
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,136 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrScanBudget is returned (wrapped) from Exec
+	// when a query scans more than Budget.MaxBytesScanned bytes.
+	ErrScanBudget = errors.New("query exceeded its scan budget")
+	// ErrTimeBudget is returned (wrapped) from Exec
+	// when a query runs for longer than Budget.MaxDuration.
+	ErrTimeBudget = errors.New("query exceeded its time budget")
+	// ErrScratchBudget is returned (wrapped) from Exec
+	// when a query buffers more than Budget.MaxScratchBytes
+	// of intermediate results.
+	ErrScratchBudget = errors.New("query exceeded its scratch memory budget")
+)
+
+// Budget is a set of limits on the resources
+// that a query may consume during execution.
+// A limit that is zero is not enforced.
+//
+// Budgets are enforced by LocalTransport;
+// they are not sent to remote peers.
+type Budget struct {
+	// MaxBytesScanned is the maximum number
+	// of bytes that may be scanned from inputs.
+	// FSRunner stops reading blocks once the limit
+	// is reached; other Runners are checked against
+	// the limit after they update ExecParams.Stats.
+	MaxBytesScanned int64
+	// MaxDuration is the maximum wall-clock
+	// time that the query may run for.
+	MaxDuration time.Duration
+	// MaxScratchBytes is the maximum number of
+	// bytes of sub-query results (including the
+	// inner side of joins) that may be buffered
+	// while the query runs.
+	MaxScratchBytes int64
+}
+
+// budget tracks the resources used by a query;
+// it is shared by every ExecParams cloned from
+// the ExecParams that started the query
+type budget struct {
+	Budget
+	scanned, scratch int64
+}
+
+// check returns an error if local bytes scanned
+// in addition to those already accounted for
+// would exceed b.MaxBytesScanned
+func (b *budget) check(local int64) error {
+	if b == nil || b.MaxBytesScanned <= 0 {
+		return nil
+	}
+	if atomic.LoadInt64(&b.scanned)+local > b.MaxBytesScanned {
+		return fmt.Errorf("%w of %d bytes", ErrScanBudget, b.MaxBytesScanned)
+	}
+	return nil
+}
+
+// scan accounts for n bytes scanned
+func (b *budget) scan(n int64) error {
+	if b == nil {
+		return nil
+	}
+	atomic.AddInt64(&b.scanned, n)
+	return b.check(0)
+}
+
+// buffer accounts for n bytes of scratch memory
+func (b *budget) buffer(n int64) error {
+	if b == nil || b.MaxScratchBytes <= 0 {
+		return nil
+	}
+	if atomic.AddInt64(&b.scratch, n) > b.MaxScratchBytes {
+		return fmt.Errorf("%w of %d bytes", ErrScratchBudget, b.MaxScratchBytes)
+	}
+	return nil
+}
+
+// start begins enforcing ep.Budget if it is not
+// already being enforced by a parent query;
+// the returned function must be called with
+// the result of executing the query
+func (ep *ExecParams) start() func(err error) error {
+	if ep.budget != nil {
+		return func(err error) error { return err }
+	}
+	ep.budget = &budget{Budget: ep.Budget}
+	d := ep.Budget.MaxDuration
+	if d <= 0 {
+		return func(err error) error {
+			ep.budget = nil
+			return err
+		}
+	}
+	// ep.Context is restored exactly as it
+	// was, even if it was nil, once we're done
+	orig := ep.Context
+	parent := orig
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeoutCause(parent, d, ErrTimeBudget)
+	ep.Context = ctx
+	return func(err error) error {
+		if err != nil && errors.Is(context.Cause(ctx), ErrTimeBudget) {
+			err = fmt.Errorf("%w of %s", ErrTimeBudget, d)
+		}
+		cancel()
+		ep.Context = orig
+		ep.budget = nil
+		return err
+	}
+}
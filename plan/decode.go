@@ -144,10 +144,14 @@ func empty(name string) (Op, bool) {
 		op = &Filter{}
 	case "unnest":
 		op = &Unnest{}
+	case "append":
+		op = &Append{}
 	case "unionmap":
 		op = &UnionMap{}
 	case "union_partition":
 		op = &UnionPartition{}
+	case "union_buckets":
+		op = &UnionBuckets{}
 	case "outpart":
 		op = &OutputPart{}
 	case "outidx":
@@ -435,6 +435,34 @@ where case
 			firstrow:    countmsg(122 + 4),
 			expectBytes: parkingBytes,
 		},
+		{
+			// no Make matches a VendorID, so every
+			// row of both tables is produced once
+			query:    `select count(*) from parking a full join nyc_taxi b on a.Make = b.VendorID`,
+			rows:     1,
+			firstrow: countmsg(1023 + 8560),
+		},
+		{
+			query: `select count(*), sum(a.Fine) from parking a join parking b on a.Ticket = b.Ticket`,
+			expectedRows: []string{
+				`{"count": 1023, "sum": 71277}`,
+			},
+		},
+		{
+			// a join with a side that is too large to be
+			// materialized at once is executed one hash bucket
+			// at a time and produces the same rows
+			query: `select count(*), sum(a.Fine) from parking a join parking b on a.Ticket = b.Ticket`,
+			indexer: testindexer{
+				"parking": sizedIndex{rows: 50000},
+			},
+			expectedRows: []string{
+				`{"count": 1023, "sum": 71277}`,
+			},
+			matchPlan: []string{
+				"UNION BUCKETS 16",
+			},
+		},
 		{
 			query: `select coalesce(x, y, z) as val
 from json('{"x": 1}{"y": 2}{"z": 3}')
@@ -1202,6 +1230,24 @@ where Make in (
 				`{"count": 9583}`,
 			},
 		},
+		{
+			// rows matching a tombstone are excluded;
+			// rows where Make is MISSING are not
+			query: `select count(*) from parking`,
+			indexer: testindexer{
+				"parking": tombstoned(expr.Compare(expr.Equals, expr.Ident("Make"), expr.String("HOND"))),
+			},
+			rows:     1,
+			firstrow: countmsg(1023 - 122),
+		},
+		{
+			query: `select count(*) from parking where Make = 'HOND' or Make is missing`,
+			indexer: testindexer{
+				"parking": tombstoned(expr.Compare(expr.Equals, expr.Ident("Make"), expr.String("HOND"))),
+			},
+			rows:     1,
+			firstrow: countmsg(4),
+		},
 		{
 			query: `select earliest(foo), latest(foo) from parking ++ nyc_taxi`,
 			indexer: testindexer{
@@ -1742,6 +1788,109 @@ where Make in (
 	}
 }
 
+func TestMaxJoinRows(t *testing.T) {
+	env := &testenv{t: t}
+	run := func(text string, max int64) (int, error) {
+		q, err := partiql.Parse([]byte(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree, err := New(q, env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var dst bytes.Buffer
+		ep := &ExecParams{
+			Plan:        tree,
+			Output:      &dst,
+			Runner:      env,
+			MaxJoinRows: max,
+		}
+		err = Exec(ep)
+		if err != nil {
+			return 0, err
+		}
+		return rowcount(t, dst.Bytes()), nil
+	}
+	const cross = `select a.Ticket, b.Ticket from parking a cross join parking b`
+	n, err := run(cross, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1023*1023 {
+		t.Errorf("got %d rows instead of %d", n, 1023*1023)
+	}
+	_, err = run(cross, 10000)
+	if !errors.Is(err, vm.ErrUnnestLimit) {
+		t.Fatalf("expected row limit error; got %v", err)
+	}
+	// joins with an equality condition are not limited
+	n, err = run(`select a.Ticket from parking a join parking b on a.Ticket = b.Ticket`, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1023 {
+		t.Errorf("got %d rows instead of %d", n, 1023)
+	}
+}
+
+func TestRandomSeed(t *testing.T) {
+	env := &testenv{t: t}
+	const text = `select count(*) from parking where RANDOM() < 0.5 and RANDOM() < 0.5`
+	plan := func() *Tree {
+		q, err := partiql.Parse([]byte(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree, err := New(q, env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tree
+	}
+	run := func(seed int64) string {
+		var dst bytes.Buffer
+		ep := &ExecParams{
+			Plan:   plan(),
+			Output: &dst,
+			Runner: env,
+			Seed:   seed,
+		}
+		if err := Exec(ep); err != nil {
+			t.Fatal(err)
+		}
+		var st ion.Symtab
+		rest, err := st.Unmarshal(dst.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, _, err := ion.ReadDatum(&st, rest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return toJSON(&st, d)
+	}
+	// planning doesn't pick a seed
+	if a, b := plan().String(), plan().String(); a != b {
+		t.Fatalf("plans differ:\n%s\n%s", a, b)
+	}
+	// the same seed produces the same results
+	if a, b := run(1), run(1); a != b {
+		t.Errorf("seed 1 produced %s and %s", a, b)
+	}
+	// ... but the two calls are seeded differently,
+	// so they select roughly a quarter of the rows
+	// rather than half of them
+	got := run(1)
+	var out struct{ Count int }
+	if err := json.Unmarshal([]byte(got), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Count < 1023/8 || out.Count > 1023*3/8 {
+		t.Errorf("selected %d of 1023 rows", out.Count)
+	}
+}
+
 func BenchmarkPlan(b *testing.B) {
 	env := &testenv{t: b}
 	queries := []string{
@@ -1906,6 +2055,14 @@ func (e *splitEnv) ListTables(db string) ([]string, error) {
 	return tl.ListTables(db)
 }
 
+func (e *splitEnv) Index(tbl expr.Node) (Index, error) {
+	ie, ok := e.Env.(Indexer)
+	if !ok {
+		return nil, nil
+	}
+	return ie.Index(tbl)
+}
+
 func (e *splitEnv) Geometry() *Geometry {
 	return e.geom
 }
@@ -2181,8 +2338,27 @@ func (t testindexer) Index(e expr.Node) (Index, error) {
 
 type testindex map[string][2]date.Time
 
+// tombstoned returns an index with
+// a tombstone for each predicate
+func tombstoned(pred ...expr.Node) *blockfmt.Index {
+	idx := &blockfmt.Index{}
+	for i := range pred {
+		idx.AddTombstone(pred[i])
+	}
+	return idx
+}
+
 func (t testindex) HasPartition(x string) bool { return false }
 
+// sizedIndex is an index of a table
+// that reports the given number of rows
+type sizedIndex struct {
+	testindex
+	rows int64
+}
+
+func (s sizedIndex) Rows() (int64, bool) { return s.rows, true }
+
 func (t testindex) TimeRange(p []string) (min, max date.Time, ok bool) {
 	r, ok := t[p[0]]
 	return r[0], r[1], ok
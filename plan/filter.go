@@ -37,7 +37,7 @@ func (f *Filter) exec(dst vm.QuerySink, src *Input, ep *ExecParams) error {
 	if ep.Rewriter != nil {
 		push(filt, f.From)
 	}
-	filter, err := vm.NewFilter(filt, dst)
+	filter, err := ep.Programs.NewFilter(filt, dst)
 	if err != nil {
 		return err
 	}
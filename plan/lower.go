@@ -41,6 +41,16 @@ func lowerIterValue(in *pir.IterValue, from Op) (Op, error) {
 		},
 		Expr:   in.Value,
 		Result: in.Result,
+		Outer:  in.Outer,
+	}, nil
+}
+
+func lowerAppend(in *pir.Append, from Op) (Op, error) {
+	return &Append{
+		Nonterminal: Nonterminal{From: from},
+		Expr:        in.Value,
+		Result:      in.Result,
+		Project:     in.Columns,
 	}, nil
 }
 
@@ -276,6 +286,12 @@ func (w *walker) lowerUnionMap(in *pir.UnionMap, env Env) (Op, error) {
 			By:          in.PartitionBy,
 		}, nil
 	}
+	if in.Buckets > 0 {
+		return &UnionBuckets{
+			Nonterminal: Nonterminal{From: sub},
+			Buckets:     in.Buckets,
+		}, nil
+	}
 	var geom *Geometry
 	if split, ok := env.(SplitEnv); ok {
 		geom = split.Geometry()
@@ -540,6 +556,8 @@ func (w *walker) walkBuild(in pir.Step, env Env) (Op, error) {
 	switch n := in.(type) {
 	case *pir.IterValue:
 		return lowerIterValue(n, input)
+	case *pir.Append:
+		return lowerAppend(n, input)
 	case *pir.Filter:
 		return lowerFilter(n, input)
 	case *pir.Distinct:
@@ -624,16 +642,24 @@ func (w *walker) addReplace(op Op, in *pir.Trace, env Env) (Op, error) {
 	}
 	// push a substitution node for replacements if necessary
 	inner := make([]*Node, len(in.Replacements))
+	var exists []bool
 	for i := range in.Replacements {
 		inner[i] = &Node{}
 		err := w.toNode(inner[i], in.Replacements[i], env)
 		if err != nil {
 			return nil, err
 		}
+		if in.Replacements[i].Exists {
+			if exists == nil {
+				exists = make([]bool, len(inner))
+			}
+			exists[i] = true
+		}
 	}
 	return &Substitute{
 		Nonterminal: Nonterminal{op},
 		Inner:       inner,
+		Exists:      exists,
 	}, nil
 }
 
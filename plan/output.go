@@ -281,11 +281,21 @@ func (is *indexSink) Close() error {
 		return nil
 	}
 	is.closed = true
+	// move leading parts into the indirect tree
+	// just like ingestion would so that very large
+	// outputs do not produce enormous indexes
+	c := blockfmt.IndexConfig{
+		MaxInlined: db.DefaultMaxInlineBytes,
+	}
+	idxpath := db.IndexPath(is.db, is.tbl)
+	err := c.SyncOutputs(is.idx, is.store, path.Dir(idxpath))
+	if err != nil {
+		return err
+	}
 	idxmem, err := blockfmt.Sign(is.key, is.idx)
 	if err != nil {
 		return err
 	}
-	idxpath := db.IndexPath(is.db, is.tbl)
 	_, err = is.store.WriteFile(idxpath, idxmem)
 	if err != nil {
 		return err
@@ -18,6 +18,8 @@ import (
 	"bytes"
 	"crypto/rand"
 	"fmt"
+	"io"
+	"slices"
 	"testing"
 
 	"github.com/SnellerInc/sneller/db"
@@ -30,8 +32,13 @@ import (
 func TestOutput(t *testing.T) {
 	cases := []struct {
 		text string // create temp table
+		want string // query producing the same rows
 	}{{
 		text: "SELECT * INTO foo.bar FROM parking",
+		want: "SELECT * FROM parking",
+	}, {
+		text: "SELECT Make, Color INTO foo.bar FROM parking WHERE Make = 'HOND'",
+		want: "SELECT Make, Color FROM parking WHERE Make = 'HOND'",
 	}}
 	for i := range cases {
 		c := &cases[i]
@@ -97,10 +104,90 @@ func TestOutput(t *testing.T) {
 				t.Fatal(err)
 			}
 			t.Log("index:", idx)
+
+			// the materialized table should
+			// contain exactly the query output
+			descs, _, _, err := idx.Descs(env.fs, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for i := range descs {
+				f, err := env.fs.Open(descs[i].Path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var dec blockfmt.Decoder
+				dec.Set(&descs[i].Trailer)
+				var out bytes.Buffer
+				_, err = dec.Copy(&out, io.LimitReader(f, descs[i].Trailer.Offset))
+				f.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, outputRows(t, out.Bytes())...)
+			}
+			want := outputRows(t, execQuery(t, env, c.want))
+			if len(want) == 0 {
+				t.Fatal("no rows in expected output")
+			}
+			slices.Sort(got)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("table has %d rows; want %d", len(got), len(want))
+				for i := 0; i < len(got) && i < len(want); i++ {
+					if got[i] != want[i] {
+						t.Errorf("got  %s", got[i])
+						t.Errorf("want %s", want[i])
+						break
+					}
+				}
+			}
 		})
 	}
 }
 
+func execQuery(t *testing.T, env *outputenv, text string) []byte {
+	q, err := partiql.Parse([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := New(q, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dst bytes.Buffer
+	err = Exec(&ExecParams{
+		Plan:   tree,
+		Output: &dst,
+		Runner: env,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dst.Bytes()
+}
+
+// outputRows returns the JSON text of
+// each row in buf
+func outputRows(t *testing.T, buf []byte) []string {
+	var st ion.Symtab
+	var rows []string
+	for len(buf) > 0 {
+		d, rest, err := ion.ReadDatum(&st, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf = rest
+		// skip symbol tables and nop padding
+		if d.IsEmpty() || d.IsNull() {
+			continue
+		}
+		rows = append(rows, d.JSON())
+	}
+	return rows
+}
+
 var _ UploadEnv = (*outputenv)(nil)
 
 type outputenv struct {
@@ -26,6 +26,7 @@ import (
 	"sync"
 
 	"github.com/SnellerInc/sneller/ion"
+	"github.com/SnellerInc/sneller/vm"
 )
 
 type frame uint32
@@ -69,6 +70,7 @@ func getframe(src []byte) frame {
 type server struct {
 	run    Runner
 	initfs func(ion.Datum) (fs.FS, error)
+	progs  *vm.ProgramCache
 
 	pipe io.ReadWriteCloser
 	rd   *bufio.Reader
@@ -99,6 +101,9 @@ type Server struct {
 	// appropriate information necessary to access
 	// file system (e.g., credentials).
 	InitFS func(ion.Datum) (fs.FS, error)
+	// Programs, if non-nil, is used as
+	// ExecParams.Programs for every query.
+	Programs *vm.ProgramCache
 }
 
 // Serve serves queries from [rw] using [run] to
@@ -118,6 +123,7 @@ func (s *Server) Serve(rw io.ReadWriteCloser) error {
 	sv := serverPool.Get().(*server)
 	sv.run = s.Runner
 	sv.initfs = s.InitFS
+	sv.progs = s.Programs
 	sv.pipe = rw
 	sv.tmp = sv.tmp[:0]
 	sv.writeFail = false
@@ -305,10 +311,11 @@ func (s *server) runQuery(buf []byte, ctx context.Context, cancel func()) error
 	}
 	lp := LocalTransport{}
 	ep := ExecParams{
-		Plan:    t,
-		Output:  s,
-		Context: ctx,
-		Runner:  s.run,
+		Plan:     t,
+		Output:   s,
+		Context:  ctx,
+		Runner:   s.run,
+		Programs: s.progs,
 	}
 	if s.initfs != nil && !t.Data.IsEmpty() {
 		ep.FS, err = s.initfs(t.Data)
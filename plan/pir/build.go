@@ -94,19 +94,79 @@ func (b *Trace) walkFromTable(f *expr.Table, e Env) error {
 	}
 }
 
+// isPlainTable returns true if t references
+// a table rather than a sub-query
+func isPlainTable(t *expr.Table) bool {
+	switch t.Expr.(type) {
+	case *expr.Select, *expr.Unpivot:
+		return false
+	default:
+		return true
+	}
+}
+
 func (b *Trace) walkFromJoin(f *expr.Join, e Env) error {
+	if f.Kind == expr.RightJoin {
+		// a RIGHT JOIN b is b LEFT JOIN a,
+		// provided that a is a plain table
+		t, ok := f.Left.(*expr.Table)
+		if !ok {
+			return errorf(f, "RIGHT JOIN requires a table on the left-hand side")
+		}
+		if err := b.walkFrom(&expr.Table{Binding: f.Right}, e); err != nil {
+			return err
+		}
+		return b.innerJoin(&t.Binding, f.On, e, true)
+	}
+	if f.Kind == expr.FullJoin {
+		// the rows of the rhs without a match are
+		// appended to the output of a LEFT JOIN,
+		// which requires the lhs to be a plain table
+		// (see fullJoin)
+		t, ok := f.Left.(*expr.Table)
+		if !ok || !isPlainTable(t) {
+			return errorf(f, "FULL JOIN requires a table on the left-hand side")
+		}
+		if u, ok := f.Right.Expr.(*expr.Builtin); ok && u.Func == expr.Unnest {
+			return errorf(f, "FULL JOIN UNNEST not supported")
+		}
+		if err := b.walkFrom(t, e); err != nil {
+			return err
+		}
+		return b.fullJoin(t, &f.Right, f.On, e)
+	}
 	err := b.walkFrom(f.Left, e)
 	if err != nil {
 		return err
 	}
+	if u, ok := f.Right.Expr.(*expr.Builtin); ok && u.Func == expr.Unnest {
+		// FROM t CROSS JOIN UNNEST(t.x) AS y is
+		// the same as FROM t, t.x AS y, but with
+		// LEFT JOIN UNNEST(t.x) AS y ON TRUE the rows
+		// of t without any element in t.x are kept
+		as := ""
+		if f.Right.Explicit() {
+			as = f.Right.Result()
+		}
+		bind := expr.Bind(u.Args[0], as)
+		if f.Kind == expr.LeftJoin {
+			return b.IterateOuter(&bind)
+		}
+		return b.Iterate(&bind)
+	}
 	switch f.Kind {
 	case expr.CrossJoin:
+		if b.isTableRef(f.Right.Expr) {
+			return b.innerJoin(&f.Right, nil, e, false)
+		}
 		// FIXME: if the rhs expression is a SELECT,
 		// then this is almost certainly a correlated
 		// sub-query ...
 		return b.Iterate(&f.Right)
 	case expr.InnerJoin:
-		return b.innerJoin(&f.Right, f.On, e)
+		return b.innerJoin(&f.Right, f.On, e, false)
+	case expr.LeftJoin:
+		return b.innerJoin(&f.Right, f.On, e, true)
 	default:
 		return errorf(f, "join %q not yet supported", f.Kind)
 	}
@@ -171,6 +231,74 @@ type Index interface {
 	HasPartition(field string) bool
 }
 
+// Deleter may optionally be implemented by an Index
+// to indicate that some rows in the table have been
+// deleted and should be excluded from the query.
+type Deleter interface {
+	// Deleted returns a predicate matching
+	// the deleted rows, or nil if no rows
+	// have been deleted.
+	Deleted() expr.Node
+}
+
+// Sizer may optionally be implemented by an Index
+// to provide the number of rows in the table, which
+// is used to decide which side of a join to build
+// the hash table from and whether the hash table
+// has to be built one hash bucket at a time.
+type Sizer interface {
+	// Rows returns the number of rows in the
+	// table, or false if it is not known.
+	Rows() (int64, bool)
+}
+
+// tableRows returns the number of rows in
+// the table referenced by t, if it is known.
+func tableRows(e Env, t expr.Node) (int64, bool) {
+	if e == nil {
+		return 0, false
+	}
+	idx, err := e.Index(t)
+	if err != nil {
+		return 0, false
+	}
+	s, ok := idx.(Sizer)
+	if !ok {
+		return 0, false
+	}
+	return s.Rows()
+}
+
+// smallerLeft returns true if both sides of an
+// inner join are plain tables and the left-hand side
+// is known to have fewer rows than the right-hand side,
+// in which case it should be the side we build the
+// hash table from (the one that is broadcast to every
+// peer) instead of the right-hand side
+func smallerLeft(f *expr.Join, e Env) bool {
+	if f.Kind != expr.CrossJoin && f.Kind != expr.InnerJoin {
+		return false
+	}
+	t, ok := f.Left.(*expr.Table)
+	if !ok {
+		return false
+	}
+	lp, ok := expr.FlatPath(t.Expr)
+	if !ok {
+		return false
+	}
+	rp, ok := expr.FlatPath(f.Right.Expr)
+	if !ok || rp[0] == t.Result() || len(lp) > 2 || len(rp) > 2 {
+		return false
+	}
+	lrows, ok := tableRows(e, t.Expr)
+	if !ok {
+		return false
+	}
+	rrows, ok := tableRows(e, f.Right.Expr)
+	return ok && lrows < rrows
+}
+
 // Build walks the provided Query
 // and lowers it into the optimized query IR.
 // If the provided SchemaHint is non-nil,
@@ -178,6 +306,7 @@ type Index interface {
 // type information that can be used to type-check
 // and optimize the query.
 func Build(q *expr.Query, e Env) (*Trace, error) {
+	numberRandom(q)
 	body := q.Body
 	var err error
 	if len(q.With) > 0 {
@@ -220,6 +349,38 @@ func build(parent *Trace, s *expr.Select, e Env) (*Trace, error) {
 	return b, err
 }
 
+// randomNumberer replaces each RANDOM() without
+// a seed with UNSEEDED_RANDOM(n), where n counts
+// the calls in the order they are visited
+type randomNumberer struct {
+	n int
+}
+
+func (r *randomNumberer) Walk(e expr.Node) expr.Rewriter { return r }
+
+func (r *randomNumberer) Rewrite(e expr.Node) expr.Node {
+	b, ok := e.(*expr.Builtin)
+	if !ok || b.Func != expr.Random || len(b.Args) != 0 {
+		return e
+	}
+	e = expr.Call(expr.UnseededRandom, expr.Integer(r.n))
+	r.n++
+	return e
+}
+
+// numberRandom gives each RANDOM() without a seed
+// in q a distinct placeholder; the placeholders are
+// bound to seeds when the query is executed, so that
+// the plan itself doesn't depend on a random seed
+// but each call still produces different values
+func numberRandom(q *expr.Query) {
+	r := &randomNumberer{}
+	for i := range q.With {
+		q.With[i].As = expr.Rewrite(r, q.With[i].As).(*expr.Select)
+	}
+	q.Body = expr.Rewrite(r, q.Body)
+}
+
 type tableReplacer struct {
 	with []expr.CTE
 	err  error
@@ -358,6 +519,29 @@ type hoistwalk struct {
 	in     []*Trace
 	err    error
 	env    Env
+
+	// exists is the set of sub-queries
+	// that were produced by EXISTS(...)
+	exists map[*expr.Select]bool
+}
+
+// isExists returns the sub-query of e if
+// e is the expression produced for
+// EXISTS(sub-query) by the parser:
+//
+//	(SELECT ... LIMIT 1) IS NOT MISSING
+//
+// or its negation
+func isExists(e expr.Node) (*expr.Select, bool) {
+	is, ok := e.(*expr.IsKey)
+	if !ok || (is.Key != expr.IsMissing && is.Key != expr.IsNotMissing) {
+		return nil, false
+	}
+	s, ok := is.Expr.(*expr.Select)
+	if !ok || s.Limit == nil || *s.Limit != 1 {
+		return nil, false
+	}
+	return s, true
 }
 
 func (h *hoistwalk) Walk(e expr.Node) expr.Rewriter {
@@ -375,6 +559,12 @@ func (h *hoistwalk) Walk(e expr.Node) expr.Rewriter {
 	if _, ok := e.(*expr.Select); ok {
 		return nil
 	}
+	if s, ok := isExists(e); ok {
+		if h.exists == nil {
+			h.exists = make(map[*expr.Select]bool)
+		}
+		h.exists[s] = true
+	}
 	return h
 }
 
@@ -518,6 +708,9 @@ func (h *hoistwalk) Rewrite(e expr.Node) expr.Node {
 	switch class {
 	case SizeOne:
 		h.in = append(h.in, t)
+		// for an uncorrelated EXISTS(...), only
+		// the first row of the replacement is needed
+		t.Exists = corrv == nil && h.exists[s]
 		if corrv != nil {
 			kind := structkind
 			if scalar {
@@ -762,7 +955,7 @@ func (w *windowHoist) Rewrite(e expr.Node) expr.Node {
 	// is turned into
 	//   HASH_LOOKUP($__key, (SELECT SUM(x) AS $__val, y AS $__key FROM ... GROUP BY y), default)
 	def := (expr.Node)(expr.Null{})
-	if agg.Op == expr.OpCount {
+	if agg.Op == expr.OpCount || agg.Op == expr.OpCountIf {
 		def = expr.Integer(0)
 	}
 	ret := expr.Call(expr.HashReplacement,
@@ -828,6 +1021,15 @@ func (b *Trace) walkSelect(s *expr.Select, e Env) error {
 		return err
 	}
 
+	// a JOIN b is b JOIN a; the right-hand side
+	// is the one that is materialized, so it
+	// should be the smaller of the two
+	// (unless SELECT * needs the rows of the left side)
+	if j, ok := s.From.(*expr.Join); ok && !selectall && smallerLeft(j, e) {
+		t := j.Left.(*expr.Table)
+		j.Left, j.Right = &expr.Table{Binding: j.Right}, t.Binding
+	}
+
 	// Walk in binding order:
 	// FROM -> WHERE -> (SELECT / GROUP BY / ORDER BY)
 	err = b.walkFrom(s.From, e)
@@ -40,11 +40,11 @@ const (
 	countType  = uintType
 )
 
-func mkenv(h expr.Hint, idx *blockfmt.Index, parts []string) Env {
-	if h == nil && idx == nil && parts == nil {
+func mkenv(h expr.Hint, idx *blockfmt.Index, parts []string, rows map[string]int64) Env {
+	if h == nil && idx == nil && parts == nil && rows == nil {
 		return nil
 	}
-	return &testenv{hint: h, idx: idx, parts: parts}
+	return &testenv{hint: h, idx: idx, parts: parts, rows: rows}
 }
 
 func TestBuildError(t *testing.T) {
@@ -138,6 +138,18 @@ func TestBuildError(t *testing.T) {
 			input: "SELECT a FROM UNPIVOT table AS a AT a",
 			rx:    "the AS and AT UNPIVOT labels must not be the same 'a'",
 		},
+		{
+			input: "SELECT UNNEST(t.lst) FROM table AS t",
+			rx:    "UNNEST can only be used on the right-hand side of a join",
+		},
+		{
+			input: "SELECT v FROM table AS t JOIN UNNEST(t.lst) AS v ON v = t.x",
+			rx:    "UNNEST cannot be used with JOIN",
+		},
+		{
+			input: "SELECT v FROM table AS t LEFT JOIN UNNEST(t.lst) AS v ON v = t.x",
+			rx:    "LEFT JOIN UNNEST requires ON TRUE",
+		},
 		{
 			input: `SELECT x, ROW_NUMBER() OVER() FROM tbl`,
 			rx:    "meaningless without ORDER BY",
@@ -171,9 +183,23 @@ func TestBuildError(t *testing.T) {
 			rx:    `is self-referenced as "x" in the outer query`,
 		},
 		{
-			// join on with erronous syntax (issue #2471)
-			input: `SELECT passenger_count FROM table JOIN X ON X=Y`,
-			rx:    `unable to eliminate join`,
+			input: `SELECT a.x, b.y FROM a AS a LEFT JOIN b AS b ON a.x = b.x AND a.y < b.y`,
+			rx:    `LEFT JOIN ON condition .* unsupported`,
+		},
+		{
+			input: `SELECT a.x, c.y FROM a AS a JOIN b AS b ON a.x = b.x RIGHT JOIN c AS c ON a.x = c.x`,
+			rx:    `RIGHT JOIN requires a table`,
+		},
+		{
+			// the unmatched rows of b are found by
+			// looking up their keys in a, so every
+			// condition has to be part of the key
+			input: `SELECT a.x, b.y FROM a AS a FULL JOIN b AS b ON a.x = b.x AND a.y < b.y`,
+			rx:    `FULL JOIN ON condition .* unsupported`,
+		},
+		{
+			input: `SELECT a.x, c.y FROM a AS a JOIN b AS b ON a.x = b.x FULL JOIN c AS c ON a.x = c.x`,
+			rx:    `FULL JOIN requires a table on the left-hand side`,
 		},
 		{
 			input: `SELECT DISTINCT ON (a, b) x, y, z FROM table GROUP BY x AS a, y AS b`,
@@ -198,7 +224,7 @@ func TestBuildError(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			b, err := Build(s, mkenv(schema, nil, nil))
+			b, err := Build(s, mkenv(schema, nil, nil, nil))
 			if err == nil {
 				var str strings.Builder
 				b.Describe(&str)
@@ -221,11 +247,18 @@ type testenv struct {
 	hint  expr.Hint
 	idx   *blockfmt.Index
 	parts []string
+	rows  map[string]int64
 }
 
 type testindex struct {
 	idx   *blockfmt.Index
 	parts []string
+	rows  int64
+	sized bool
+}
+
+func (t *testindex) Rows() (int64, bool) {
+	return t.rows, t.sized
 }
 
 func (t *testindex) TimeRange(path []string) (min, max date.Time, ok bool) {
@@ -244,8 +277,9 @@ func (e *testenv) Schema(expr.Node) expr.Hint {
 	return e.hint
 }
 
-func (e *testenv) Index(expr.Node) (Index, error) {
-	return &testindex{idx: e.idx, parts: e.parts}, nil
+func (e *testenv) Index(t expr.Node) (Index, error) {
+	rows, sized := e.rows[expr.ToString(t)]
+	return &testindex{idx: e.idx, parts: e.parts, rows: rows, sized: sized}, nil
 }
 
 type nameType struct {
@@ -287,6 +321,7 @@ type buildTestcase struct {
 	schema  expr.Hint // applied to the inner-most table expression
 	index   *blockfmt.Index
 	parts   []string
+	rows    map[string]int64 // number of rows in each table
 }
 
 func TestBuild(t *testing.T) {
@@ -328,6 +363,44 @@ func TestBuild(t *testing.T) {
 				"PROJECT v AS v",
 			},
 		},
+		{
+			input: "SELECT t.x, v FROM input AS t CROSS JOIN UNNEST(t.lst) AS v",
+			expect: []string{
+				"ITERATE input AS t FIELDS [lst, x]",
+				"ITERATE FIELD lst AS v",
+				"PROJECT x AS x, v AS v",
+			},
+		},
+		{
+			input: "SELECT t.x, v FROM input AS t LEFT JOIN UNNEST(t.lst) AS v ON TRUE WHERE t.x > 0",
+			expect: []string{
+				"ITERATE input AS t FIELDS [lst, x] WHERE x > 0",
+				"ITERATE OUTER FIELD lst AS v",
+				"PROJECT x AS x, v AS v",
+			},
+		},
+		{
+			// a filter on a single field name (and its value)
+			// is evaluated on the rows of the table as well
+			input: "SELECT v FROM UNPIVOT input AS v AT a WHERE a = 'x' AND v > 3",
+			expect: []string{
+				"ITERATE input FIELDS * WHERE x IS NOT MISSING AND x > 3",
+				"UNPIVOT AS v AT a",
+				"FILTER a = 'x' AND v > 3",
+				"PROJECT v AS v",
+			},
+		},
+		{
+			// a filter on the value alone
+			// cannot be evaluated on the table
+			input: "SELECT a FROM UNPIVOT input AS v AT a WHERE v > 3",
+			expect: []string{
+				"ITERATE input FIELDS *",
+				"UNPIVOT AS v AT a",
+				"FILTER v > 3",
+				"PROJECT a AS a",
+			},
+		},
 		{
 			input: "select 3, 'foo' || 'bar'",
 			expect: []string{
@@ -779,6 +852,26 @@ where out.Make = 'CHRY' and entry.BodyStyle = 'PA'`,
 			},
 			results: []expr.TypeSet{expr.UnsignedType, expr.AnyType},
 		},
+		{
+			// 'having' conditions on the grouping columns
+			// are pushed into the table scan; conditions
+			// on aggregates remain after the aggregation
+			input: `select count(x) as c, y from table where x > 0 group by y having y <> 'foo' and count(x) > 100`,
+			expect: []string{
+				"ITERATE table FIELDS [x, y] WHERE x > 0 AND y <> 'foo'",
+				"AGGREGATE COUNT(x) AS $_0_1 BY y AS $_0_0",
+				"FILTER $_0_1 > 100",
+				"PROJECT $_0_1 AS c, $_0_0 AS y",
+			},
+		},
+		{
+			input: `select count(x) as c, y+1 as z from table group by y+1 having z > 2`,
+			expect: []string{
+				"ITERATE table FIELDS [x, y] WHERE y + 1 > 2",
+				"AGGREGATE COUNT(x) AS $_0_1 BY y + 1 AS $_0_0",
+				"PROJECT $_0_1 AS c, $_0_0 AS z",
+			},
+		},
 		{
 			input: `select * from foo where 1 > 2`,
 			expect: []string{
@@ -1125,6 +1218,32 @@ ORDER BY m, d, h`,
 				"PROJECT x AS x, !(IN_REPLACEMENT(x, 0)) AS no_other",
 			},
 		},
+		{
+			// EXISTS with two correlated keys -> semi-join on a list
+			input: `SELECT x, y FROM input WHERE EXISTS(SELECT * FROM other WHERE a = x AND b = y)`,
+			expect: []string{
+				"WITH (",
+				"	ITERATE other FIELDS [a, b]",
+				"	FILTER DISTINCT [a, b]",
+				"	PROJECT [a, b] AS $_0_0",
+				") AS REPLACEMENT(0)",
+				"ITERATE input FIELDS [x, y] WHERE IN_REPLACEMENT([x, y], 0)",
+				"PROJECT x AS x, y AS y",
+			},
+		},
+		{
+			// correlated scalar aggregate with two keys
+			input: `SELECT x, y, (SELECT COUNT(*) FROM other WHERE a = x AND b = y) AS n FROM input`,
+			expect: []string{
+				"WITH (",
+				"	ITERATE other FIELDS [a, b]",
+				"	AGGREGATE COUNT(*) AS \"count\" BY a AS $_0_1, b AS $_0_2",
+				"	PROJECT \"count\" AS \"count\", [$_0_1, $_0_2] AS $_0_0",
+				") AS REPLACEMENT(0)",
+				"ITERATE input FIELDS [x, y]",
+				"PROJECT x AS x, y AS y, HASH_REPLACEMENT(0, 'scalar', '$_0_0', [x, y]) AS n",
+			},
+		},
 		{
 			input: `SELECT y, z FROM table GROUP BY x+1 AS y, z`,
 			expect: []string{
@@ -1194,9 +1313,8 @@ ORDER BY m, d, h`,
 				// of a PARTITION_VALUE() and move the replacement step
 				// into this subquery so that the hash lookup can be eliminated altogether
 				"UNION MAP foo PARTITION BY y (",
-				"	ITERATE PART foo FIELDS [var, x, z] WHERE z = 'foo'",
+				"	ITERATE PART foo FIELDS [var, x, z] WHERE z = 'foo' AND HASH_REPLACEMENT(0, 'scalar', '$__key', PARTITION_VALUE(0), 0) > 100",
 				"	NONEMPTY AGGREGATE SUM(var) AS $_0_2 BY x AS $_0_1",
-				"	FILTER HASH_REPLACEMENT(0, 'scalar', '$__key', PARTITION_VALUE(0), 0) > 100",
 				"	PROJECT $_0_1 AS x, PARTITION_VALUE(0) AS y, $_0_2 AS \"sum\", HASH_REPLACEMENT(0, 'scalar', '$__key', PARTITION_VALUE(0), 0) AS x_per_y)",
 			},
 			split: []string{
@@ -1214,10 +1332,9 @@ ORDER BY m, d, h`,
 				// into this subquery so that the hash lookup can be eliminated altogether
 				"UNION MAP foo PARTITION BY y (",
 				"	UNION MAP foo (",
-				"		ITERATE PART foo FIELDS [var, x, z] WHERE z = 'foo'",
+				"		ITERATE PART foo FIELDS [var, x, z] WHERE z = 'foo' AND HASH_REPLACEMENT(0, 'scalar', '$__key', PARTITION_VALUE(0), 0) > 100",
 				"		NONEMPTY AGGREGATE SUM.PARTIAL(var) AS $_2_0 BY x AS $_0_1)",
 				"	NONEMPTY AGGREGATE SUM.MERGE($_2_0) AS $_0_2 BY $_0_1 AS $_0_1",
-				"	FILTER HASH_REPLACEMENT(0, 'scalar', '$__key', PARTITION_VALUE(0), 0) > 100",
 				"	PROJECT $_0_1 AS x, PARTITION_VALUE(0) AS y, $_0_2 AS \"sum\", HASH_REPLACEMENT(0, 'scalar', '$__key', PARTITION_VALUE(0), 0) AS x_per_y)",
 			},
 			parts: []string{"y"},
@@ -1300,13 +1417,280 @@ z = (SELECT a FROM bar LIMIT 1)`,
 			},
 		},
 		{
-			// check that the unnesting is removed appropriately:
+			// the unnesting is kept even though y isn't used,
+			// since it determines how many rows are produced:
 			input: "SELECT o.x, o.z FROM table AS o, o.lst as y",
 			expect: []string{
-				"ITERATE table AS o FIELDS [x, z]",
+				"ITERATE table AS o FIELDS [lst, x, z]",
+				"ITERATE FIELD lst AS y",
 				"PROJECT x AS x, z AS z",
 			},
 		},
+		{
+			// a JOIN without an equality condition
+			// is a nested-loop join on a constant key
+			input: "SELECT a.x, b.y FROM a AS a JOIN b AS b ON a.x < b.y",
+			expect: []string{
+				"WITH (",
+				"	ITERATE b AS b FIELDS [y]",
+				"	PROJECT TRUE AS $__key, [y] AS $__val",
+				") AS REPLACEMENT(0)",
+				"ITERATE a AS a FIELDS [x]",
+				"ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', TRUE) AS b",
+				"FILTER x < b[0]",
+				"PROJECT x AS x, b[0] AS y",
+			},
+		},
+		{
+			input: "SELECT a.x, b.z FROM a AS a LEFT JOIN b AS b ON a.x = b.y AND b.z > 0 WHERE b.z < 10",
+			expect: []string{
+				"WITH (",
+				"	ITERATE b AS b FIELDS [y, z] WHERE z > 0",
+				"	PROJECT y AS $__key, [z] AS $__val",
+				") AS REPLACEMENT(0)",
+				"ITERATE a AS a FIELDS [x]",
+				"ITERATE OUTER FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', x) AS b",
+				"FILTER b[0] < 10",
+				"PROJECT x AS x, b[0] AS z",
+			},
+		},
+		{
+			input: "SELECT a.x, b.z FROM a AS a RIGHT JOIN b AS b ON a.x = b.y",
+			expect: []string{
+				"WITH (",
+				"	ITERATE a AS a FIELDS [x]",
+				"	PROJECT x AS $__key, [x] AS $__val",
+				") AS REPLACEMENT(0)",
+				"ITERATE b AS b FIELDS [y, z]",
+				"ITERATE OUTER FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', y) AS a",
+				"PROJECT a[0] AS x, z AS z",
+			},
+		},
+		{
+			input: "SELECT a.x, b.z FROM a AS a FULL JOIN b AS b ON a.x = b.y",
+			expect: []string{
+				"WITH (",
+				"	ITERATE b AS b FIELDS [y, z]",
+				"	PROJECT y AS $__key, [z] AS $__val",
+				") AS REPLACEMENT(0)",
+				"WITH (",
+				"	WITH (",
+				"		ITERATE a AS a FIELDS [x]",
+				"		FILTER DISTINCT [x, TRUE]",
+				"		PROJECT x AS $__key, TRUE AS $__val",
+				"	) AS REPLACEMENT(0)",
+				"	ITERATE b AS b FIELDS [y, z] WHERE HASH_REPLACEMENT(0, 'scalar', '$__key', y) IS NOT TRUE",
+				"	PROJECT TRUE AS $__key, [z] AS $__val",
+				") AS REPLACEMENT(1)",
+				"ITERATE a AS a FIELDS [x]",
+				"ITERATE OUTER FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', x) AS b",
+				"APPEND FIELD HASH_REPLACEMENT(1, 'joinlist', '$__key', TRUE) AS b",
+				"PROJECT x AS x, b[0] AS z",
+			},
+		},
+		{
+			// issue #2471: this used to fail with
+			// "unable to eliminate join"; now X is the
+			// joined table and Y is a field of the outer
+			// table, so this is a hash join of the two
+			input: "SELECT passenger_count FROM table JOIN X ON X=Y",
+			expect: []string{
+				"WITH (",
+				"	ITERATE X FIELDS [X]",
+				"	PROJECT X AS $__key, [] AS $__val",
+				") AS REPLACEMENT(0)",
+				"ITERATE table FIELDS [Y, passenger_count]",
+				"ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', Y) AS X",
+				"PROJECT passenger_count AS passenger_count",
+			},
+		},
+		{
+			// the smaller side of a join is materialized
+			input: "SELECT a.x, b.y FROM a AS a JOIN b AS b ON a.x < b.y",
+			rows:  map[string]int64{"a": 10, "b": 1000},
+			expect: []string{
+				"WITH (",
+				"	ITERATE a AS a FIELDS [x]",
+				"	PROJECT TRUE AS $__key, [x] AS $__val",
+				") AS REPLACEMENT(0)",
+				"ITERATE b AS b FIELDS [y]",
+				"ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', TRUE) AS a",
+				"FILTER a[0] < y",
+				"PROJECT a[0] AS x, y AS y",
+			},
+		},
+		{
+			input: "SELECT a.x, b.y FROM a AS a JOIN b AS b ON a.x < b.y",
+			rows:  map[string]int64{"a": 1000, "b": 10},
+			expect: []string{
+				"WITH (",
+				"	ITERATE b AS b FIELDS [y]",
+				"	PROJECT TRUE AS $__key, [y] AS $__val",
+				") AS REPLACEMENT(0)",
+				"ITERATE a AS a FIELDS [x]",
+				"ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', TRUE) AS b",
+				"FILTER x < b[0]",
+				"PROJECT x AS x, b[0] AS y",
+			},
+		},
+		{
+			// ... and the sides of an equi-join are swapped the same way
+			input: "SELECT a.x, b.y FROM a AS a JOIN b AS b ON a.id = b.id",
+			rows:  map[string]int64{"a": 10, "b": 1000},
+			expect: []string{
+				"WITH (",
+				"	ITERATE a AS a FIELDS [id, x]",
+				"	PROJECT id AS $__key, [x] AS $__val",
+				") AS REPLACEMENT(0)",
+				"ITERATE b AS b FIELDS [id, y]",
+				"ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', id) AS a",
+				"PROJECT a[0] AS x, y AS y",
+			},
+		},
+		{
+			// SELECT * produces the rows of the left side,
+			// so the join can't be swapped
+			input: "SELECT * FROM a AS a JOIN b AS b ON a.id = b.id",
+			rows:  map[string]int64{"a": 10, "b": 1000},
+			expect: []string{
+				"WITH (",
+				"	ITERATE b AS b FIELDS [id]",
+				"	PROJECT id AS $__key, [] AS $__val",
+				") AS REPLACEMENT(0)",
+				"ITERATE a AS a FIELDS *",
+				"ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', id) AS b",
+			},
+		},
+		{
+			// but not when the size of one side is unknown
+			input: "SELECT a.x, b.y FROM a AS a JOIN b AS b ON a.id = b.id",
+			rows:  map[string]int64{"a": 10},
+			expect: []string{
+				"WITH (",
+				"	ITERATE b AS b FIELDS [id, y]",
+				"	PROJECT id AS $__key, [y] AS $__val",
+				") AS REPLACEMENT(0)",
+				"ITERATE a AS a FIELDS [id, x]",
+				"ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', id) AS b",
+				"PROJECT x AS x, b[0] AS y",
+			},
+		},
+		{
+			// or for a LEFT JOIN
+			input: "SELECT a.x, b.y FROM a AS a LEFT JOIN b AS b ON a.id = b.id",
+			rows:  map[string]int64{"a": 10, "b": 1000},
+			expect: []string{
+				"WITH (",
+				"	ITERATE b AS b FIELDS [id, y]",
+				"	PROJECT id AS $__key, [y] AS $__val",
+				") AS REPLACEMENT(0)",
+				"ITERATE a AS a FIELDS [id, x]",
+				"ITERATE OUTER FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', id) AS b",
+				"PROJECT x AS x, b[0] AS y",
+			},
+		},
+		{
+			// a join whose materialized side exceeds
+			// LargeSize rows is split into hash buckets
+			input: "SELECT a.x, b.y FROM a AS a JOIN b AS b ON a.id = b.id",
+			rows:  map[string]int64{"a": 100000, "b": 50000},
+			expect: []string{
+				"UNION MAP a AS a BUCKETS 16 (",
+				"	WITH (",
+				"		ITERATE b AS b FIELDS [id, y] WHERE HASH_BUCKET(id, 16) = BUCKET_VALUE()",
+				"		PROJECT id AS $__key, [y] AS $__val",
+				"	) AS REPLACEMENT(0)",
+				"	ITERATE a AS a FIELDS [id, x] WHERE HASH_BUCKET(id, 16) = BUCKET_VALUE()",
+				"	ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', id) AS b",
+				"	PROJECT x AS x, b[0] AS y)",
+			},
+			split: []string{
+				"UNION MAP a AS a BUCKETS 16 (",
+				"	WITH (",
+				"		UNION MAP b AS b (",
+				"			ITERATE PART b AS b FIELDS [id, y] WHERE HASH_BUCKET(id, 16) = BUCKET_VALUE()",
+				"			PROJECT id AS $__key, [y] AS $__val)",
+				"	) AS REPLACEMENT(0)",
+				"	UNION MAP a AS a (",
+				"		ITERATE PART a AS a FIELDS [id, x] WHERE HASH_BUCKET(id, 16) = BUCKET_VALUE()",
+				"		ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', id) AS b",
+				"		PROJECT x AS x, b[0] AS y))",
+			},
+		},
+		{
+			// ... and the rows of the buckets are aggregated together
+			input: "SELECT COUNT(*) FROM a AS a JOIN b AS b ON a.id = b.id",
+			rows:  map[string]int64{"a": 100000, "b": 50000},
+			expect: []string{
+				"UNION MAP a AS a BUCKETS 16 (",
+				"	WITH (",
+				"		ITERATE b AS b FIELDS [id] WHERE HASH_BUCKET(id, 16) = BUCKET_VALUE()",
+				"		PROJECT id AS $__key, [] AS $__val",
+				"	) AS REPLACEMENT(0)",
+				"	ITERATE a AS a FIELDS [id] WHERE HASH_BUCKET(id, 16) = BUCKET_VALUE()",
+				"	ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', id) AS b)",
+				"AGGREGATE COUNT(*) AS \"count\"",
+			},
+			split: []string{
+				"UNION MAP a AS a BUCKETS 16 (",
+				"	WITH (",
+				"		UNION MAP b AS b (",
+				"			ITERATE PART b AS b FIELDS [id] WHERE HASH_BUCKET(id, 16) = BUCKET_VALUE()",
+				"			PROJECT id AS $__key, [] AS $__val)",
+				"	) AS REPLACEMENT(0)",
+				"	UNION MAP a AS a (",
+				"		ITERATE PART a AS a FIELDS [id] WHERE HASH_BUCKET(id, 16) = BUCKET_VALUE()",
+				"		ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', id) AS b))",
+				"AGGREGATE COUNT(*) AS \"count\"",
+			},
+		},
+		{
+			// the rows of every table read by the
+			// materialized side count towards its size
+			input: "SELECT a.x, b.y FROM a AS a JOIN (SELECT b.id, c.y FROM b AS b JOIN c AS c ON b.k = c.k) AS b ON a.id = b.id",
+			rows:  map[string]int64{"a": 100000, "b": 6000, "c": 6000},
+			expect: []string{
+				"UNION MAP a AS a BUCKETS 4 (",
+				"	WITH (",
+				"		WITH (",
+				"			ITERATE c AS c FIELDS [k, y]",
+				"			PROJECT k AS $__key, [y] AS $__val",
+				"		) AS REPLACEMENT(0)",
+				"		ITERATE b AS b FIELDS [id, k] WHERE HASH_BUCKET(id, 4) = BUCKET_VALUE()",
+				"		ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', k) AS c",
+				"		PROJECT id AS $__key, [c[0]] AS $__val",
+				"	) AS REPLACEMENT(0)",
+				"	ITERATE a AS a FIELDS [id, x] WHERE HASH_BUCKET(id, 4) = BUCKET_VALUE()",
+				"	ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', id) AS b",
+				"	PROJECT x AS x, b[0] AS y)",
+			},
+		},
+		{
+			// LargeSize rows are still materialized at once
+			input: "SELECT a.x, b.y FROM a AS a JOIN b AS b ON a.id = b.id",
+			rows:  map[string]int64{"a": 100000, "b": LargeSize},
+			expect: []string{
+				"WITH (",
+				"	ITERATE b AS b FIELDS [id, y]",
+				"	PROJECT id AS $__key, [y] AS $__val",
+				") AS REPLACEMENT(0)",
+				"ITERATE a AS a FIELDS [id, x]",
+				"ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', id) AS b",
+				"PROJECT x AS x, b[0] AS y",
+			},
+		},
+		{
+			input: "SELECT COUNT(*) FROM a AS a CROSS JOIN b AS b",
+			expect: []string{
+				"WITH (",
+				"	ITERATE b AS b FIELDS []",
+				"	PROJECT TRUE AS $__key, [] AS $__val",
+				") AS REPLACEMENT(0)",
+				"ITERATE a AS a FIELDS []",
+				"ITERATE FIELD HASH_REPLACEMENT(0, 'joinlist', '$__key', TRUE) AS b",
+				"AGGREGATE COUNT(*) AS \"count\"",
+			},
+		},
 		{
 			input: "SELECT grp, SUM(x) AS sumx, AVG(x) FILTER(WHERE foo = 1) AS avgx, 1 + SUM(x) AS sum2 FROM foo GROUP BY grp ORDER BY grp LIMIT 1",
 			expect: []string{
@@ -1591,7 +1975,7 @@ func buildSplit(t *testing.T, tc *buildTestcase, split bool) *Trace {
 	if err != nil {
 		t.Fatal(err)
 	}
-	b, err := Build(s, mkenv(tc.schema, tc.index, tc.parts))
+	b, err := Build(s, mkenv(tc.schema, tc.index, tc.parts, tc.rows))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -135,6 +135,10 @@ func (b *Trace) Class() SizeClass {
 			}
 		case *Distinct:
 			next = SizeColumnCardinality
+		case *Append:
+			// the steps below do not bound
+			// the number of appended rows
+			return cur
 		}
 		if next < cur {
 			cur = next
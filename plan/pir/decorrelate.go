@@ -15,6 +15,9 @@
 package pir
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/SnellerInc/sneller/expr"
 )
 
@@ -23,16 +26,23 @@ import (
 // subquery must meet the following conditions to be
 // decorrelated:
 //
-//   - The subquery contains only one correlated
-//     reference (x).
-//   - x must be related to a column (y) by an equality
-//     comparison (x = y).
+//   - Each correlated reference (x) must be related
+//     to a column (y) by an equality comparison (x = y)
+//     in the top-level conjunctions of the WHERE clause.
 //   - There must be no other conditions referencing x.
+//   - The subquery must produce either a projection
+//     (e.g. EXISTS) or an aggregate without GROUP BY
+//     (a scalar aggregate), and the outputs must not
+//     reference x.
+//
+// If there is more than one correlated reference,
+// the key is MAKE_LIST(y...) and the correlated
+// value is MAKE_LIST(x...) in the same order.
 //
 // If the subquery had a correlated reference and was
 // successfully rewritten, This returns the name of the
 // key field in the results (k) and the path of the
-// correlated variable in the outer query (v).
+// correlated variable(s) in the outer query (v).
 //
 // If v == nil and err == nil, the subquery did not
 // contain a correlated reference and should be
@@ -42,7 +52,7 @@ import (
 // reference, but decorrelation was unsuccessful and
 // the trace may no longer be valid.
 func (b *Trace) decorrelate() (k, v expr.Node, x string, err error) {
-	// first we need to find a correlated variable
+	// first we need to find the correlated variables
 	// in the trace by checking its free variables
 	// against the parent trace
 	top := b.top
@@ -55,10 +65,9 @@ func (b *Trace) decorrelate() (k, v expr.Node, x string, err error) {
 	if !ok || it.Filter == nil {
 		return nil, nil, "", nil
 	}
+	var xs []string
+	vs := make(map[string]expr.Node)
 	for free := range it.free {
-		if free == x {
-			continue
-		}
 		_, node := b.Parent.top.get(free)
 		if node == nil {
 			continue
@@ -66,18 +75,16 @@ func (b *Trace) decorrelate() (k, v expr.Node, x string, err error) {
 		if _, ok := node.(*expr.Select); ok {
 			continue
 		}
-		// multiple correlated references are
-		// unsupported for now
-		if x != "" {
-			return nil, nil, "", decorrerr(node, free)
-		}
-		x = free
-		v = node
-		continue
+		xs = append(xs, free)
+		vs[free] = node
 	}
-	if x == "" {
+	if len(xs) == 0 {
 		return nil, nil, "", nil
 	}
+	// it.free is a map, so pick a
+	// deterministic order for the keys
+	slices.Sort(xs)
+	x, v = xs[0], vs[xs[0]]
 	// remove any limit steps in the child trace
 	var prev Step
 	for s := b.top; s != nil; s = s.parent() {
@@ -100,9 +107,21 @@ func (b *Trace) decorrelate() (k, v expr.Node, x string, err error) {
 		}
 	}
 	// find "x = y" in the WHERE clause
-	y := b.decorrelateWhere(x, it)
-	if y == nil {
-		return nil, nil, "", decorrerr(v, x)
+	// for each correlated variable
+	ys := make([]expr.Node, len(xs))
+	for i := range xs {
+		ys[i] = b.decorrelateWhere(xs[i], it)
+		if ys[i] == nil {
+			return nil, nil, "", decorrerr(vs[xs[i]], xs[i])
+		}
+	}
+	refs := func(e expr.Node) bool {
+		for i := range xs {
+			if hasReference(xs[i], e) {
+				return true
+			}
+		}
+		return false
 	}
 	// the top step must either be a Bind or
 	// Aggregate with at least one output
@@ -112,33 +131,66 @@ func (b *Trace) decorrelate() (k, v expr.Node, x string, err error) {
 			return nil, nil, "", decorrerr(v, x)
 		}
 		for i := range s.bind {
-			if hasReference(x, s.bind[i].Expr) {
+			if refs(s.bind[i].Expr) {
 				return nil, nil, "", decorrerr(v, x)
 			}
 		}
-		key := expr.Bind(y, gensym(0, 0))
+		key := expr.Bind(listOf(ys), gensym(0, 0))
 		s.bind = append(s.bind, key)
-		// insert "FILTER DISTINCT y" before
+		// insert "FILTER DISTINCT y..." before
 		// the bind step
 		di := &Distinct{
-			Columns: []expr.Node{y},
+			Columns: ys,
 		}
 		di.setparent(s.parent())
 		s.setparent(di)
 		k = expr.String(key.Result())
 	case *Aggregate:
-		if len(s.Agg) == 0 || s.GroupBy != nil || hasReference(x, s.Agg[0].Expr) {
+		if len(s.Agg) == 0 || s.GroupBy != nil || refs(s.Agg[0].Expr) {
 			return nil, nil, "", decorrerr(v, x)
 		}
-		by := expr.Bind(y, gensym(0, 0))
-		s.GroupBy = append(s.GroupBy, by)
-		k = expr.String(by.Result())
+		if len(ys) == 1 {
+			by := expr.Bind(ys[0], gensym(0, 0))
+			s.GroupBy = append(s.GroupBy, by)
+			k = expr.String(by.Result())
+			break
+		}
+		// group by each y and then combine
+		// the groups into a single key column
+		cols := make([]expr.Node, len(ys))
+		for i := range ys {
+			by := expr.Bind(ys[i], gensym(0, i+1))
+			s.GroupBy = append(s.GroupBy, by)
+			cols[i] = expr.Identifier(by.Result())
+		}
+		bi := &Bind{complete: true}
+		for i := range s.Agg {
+			bi.bind = append(bi.bind, expr.Identity(s.Agg[i].Result))
+		}
+		key := expr.Bind(listOf(cols), gensym(0, 0))
+		bi.bind = append(bi.bind, key)
+		bi.setparent(s)
+		b.top = bi
+		k = expr.String(key.Result())
 	default:
 		return nil, nil, "", decorrerr(v, x)
 	}
 	// do some bookkeeping
-	delete(it.free, x)
-	return k, v, x, nil
+	corr := make([]expr.Node, len(xs))
+	for i := range xs {
+		delete(it.free, xs[i])
+		corr[i] = vs[xs[i]]
+	}
+	return k, listOf(corr), strings.Join(xs, ", "), nil
+}
+
+// listOf returns lst[0] if lst has one element
+// and MAKE_LIST(lst...) otherwise
+func listOf(lst []expr.Node) expr.Node {
+	if len(lst) == 1 {
+		return lst[0]
+	}
+	return expr.Call(expr.MakeList, lst...)
 }
 
 func decorrerr(e expr.Node, x string) error {
@@ -39,6 +39,7 @@ func mergereplacements(b *Trace) {
 				}
 			}
 			repl[j] = i
+			ri.Exists = ri.Exists && rj.Exists
 			b.Replacements[j] = nil
 		}
 	}
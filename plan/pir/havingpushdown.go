@@ -0,0 +1,115 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package pir
+
+import (
+	"github.com/SnellerInc/sneller/expr"
+)
+
+// onlyReferencesGroups determines if every
+// identifier in e refers to one of the grouping
+// columns of a (and not to an aggregate result)
+func onlyReferencesGroups(e expr.Node, a *Aggregate) bool {
+	ok := true
+	visit := expr.WalkFunc(func(e expr.Node) bool {
+		if !ok {
+			return false
+		}
+		id, isid := e.(expr.Ident)
+		if isid {
+			ok = false
+			for i := range a.GroupBy {
+				if a.GroupBy[i].Result() == string(id) {
+					ok = true
+					break
+				}
+			}
+		}
+		return !isid
+	})
+	expr.Walk(visit, e)
+	return ok
+}
+
+// havingpushdown moves the conjuncts of a filter
+// following a grouped aggregation (i.e. HAVING) that
+// only reference grouping columns ahead of the
+// aggregation, so that they can be merged into
+// the table scan by filterpushdown
+//
+// for example
+//
+//	SELECT COUNT(*), x FROM t GROUP BY x HAVING x > 0 AND COUNT(*) > 1
+//
+// is evaluated as
+//
+//	SELECT COUNT(*), x FROM t WHERE x > 0 GROUP BY x HAVING COUNT(*) > 1
+func havingpushdown(b *Trace) {
+	var child Step
+	for s := b.top; s != nil; s = s.parent() {
+		f, ok := s.(*Filter)
+		if !ok {
+			child = s
+			continue
+		}
+		a, ok := f.parent().(*Aggregate)
+		if !ok || a.GroupBy == nil {
+			child = s
+			continue
+		}
+		// filtering out groups would change the
+		// input of window functions computed
+		// over all of the groups
+		window := false
+		for i := range a.Agg {
+			if a.Agg[i].Expr.Over != nil {
+				window = true
+				break
+			}
+		}
+		if window {
+			child = s
+			continue
+		}
+		var pushed, kept []expr.Node
+		for _, c := range conjunctions(f.Where, nil) {
+			if onlyReferencesGroups(c, a) {
+				c = expr.Rewrite(&bindflattener{from: a.GroupBy}, c)
+				pushed = append(pushed, c)
+			} else {
+				kept = append(kept, c)
+			}
+		}
+		if len(pushed) == 0 {
+			child = s
+			continue
+		}
+		where := new(Filter)
+		where.Where = conjoinAll(pushed, a)
+		where.setparent(a.parent())
+		a.setparent(where)
+		if len(kept) == 0 {
+			// splice out the HAVING filter entirely
+			if child == nil {
+				b.top = a
+			} else {
+				child.setparent(a)
+			}
+			continue
+		}
+		f.Where = conjoinAll(kept, f)
+		child = s
+	}
+}
@@ -20,10 +20,58 @@ import (
 	"github.com/SnellerInc/sneller/expr"
 )
 
-func joinhash(b *Trace, eq *EquiJoin) expr.Node {
+func joinhash(b *Trace, value expr.Node) (expr.Node, int) {
 	id := len(b.Replacements)
 	b.Replacements = append(b.Replacements, nil) // will be assigned to later
-	return expr.Call(expr.HashReplacement, expr.Integer(id), expr.String("joinlist"), expr.String("$__key"), eq.value)
+	return expr.Call(expr.HashReplacement, expr.Integer(id), expr.String("joinlist"), expr.String("$__key"), value), id
+}
+
+// unmatched builds the trace that produces the
+// rows of the rhs of a FULL JOIN without a match
+// in the lhs; the rows have the same $__val column
+// as the rows the join is built from, and they are
+// all produced for the key TRUE so that they can
+// be retrieved with a single HASH_REPLACEMENT()
+func unmatched(b *Trace, eq *EquiJoin, collist expr.Node) (*Trace, error) {
+	self := expr.Copy(eq.built.From.(*expr.Table)).(*expr.Table)
+	keys, values, _ := equalities(self.Result(), expr.Copy(eq.full.on))
+	t := &Trace{Parent: b}
+	// the keys of the lhs are looked up by hash,
+	// just like the keys of the join itself, so
+	// that exactly the rows of the rhs that did
+	// not match anything are produced
+	matched, err := build(t, &expr.Select{
+		Distinct: true,
+		Columns: []expr.Binding{
+			expr.Bind(joinkey(values), "$__key"),
+			expr.Bind(expr.Bool(true), "$__val"),
+		},
+		From: expr.Copy(eq.full.left).(*expr.Table),
+	}, eq.env)
+	if err != nil {
+		return nil, err
+	}
+	lookup := expr.Call(expr.HashReplacement,
+		expr.Integer(len(t.Replacements)),
+		expr.String("scalar"),
+		expr.String("$__key"),
+		joinkey(keys))
+	t.Replacements = append(t.Replacements, matched)
+	sel := &expr.Select{
+		Columns: []expr.Binding{
+			expr.Bind(expr.Bool(true), "$__key"),
+			expr.Bind(expr.Copy(collist), "$__val"),
+		},
+		From:  self,
+		Where: expr.Is(lookup, expr.IsNotTrue),
+	}
+	if err := expr.Check(sel); err != nil {
+		return nil, err
+	}
+	if err := t.walkSelect(sel, eq.env); err != nil {
+		return nil, err
+	}
+	return t, t.optimize()
 }
 
 type joinResult struct {
@@ -31,6 +79,10 @@ type joinResult struct {
 	into expr.Node
 	used []string
 	err  error
+
+	// unmatched is set for a FULL JOIN
+	// to the rows of the rhs without a match
+	unmatched expr.Node
 }
 
 type joinRewriter struct {
@@ -122,7 +174,6 @@ func joinelim(b *Trace) error {
 	fn := func(e expr.Node, _ bool) expr.Node {
 		return expr.Rewrite(&jw, e)
 	}
-	start := len(b.Replacements)
 	for s := b.top; s != nil; s = s.parent() {
 		jw.parent = s.parent()
 		if jw.parent == nil {
@@ -130,12 +181,21 @@ func joinelim(b *Trace) error {
 		}
 		s.rewrite(fn)
 	}
+	// every join needs a replacement,
+	// even if none of its fields are referenced,
+	// since it still determines which rows are produced
+	for s := b.top; s != nil; s = s.parent() {
+		if eq, ok := s.(*EquiJoin); ok {
+			jw.get(eq)
+		}
+	}
 	for i := range jw.results {
 		jr := &jw.results[i]
 		if jr.err != nil {
 			return jr.err
 		}
-		jr.into = joinhash(b, jr.eq)
+		var id int
+		jr.into, id = joinhash(b, jr.eq.value)
 		eq := jr.eq
 		lstitems := make([]expr.Node, len(jr.used))
 		for j := range jr.used {
@@ -148,7 +208,16 @@ func joinelim(b *Trace) error {
 		if err != nil {
 			return err
 		}
-		b.Replacements[start+i] = t
+		b.Replacements[id] = t
+		if eq.full == nil {
+			continue
+		}
+		t, err = unmatched(b, eq, collist)
+		if err != nil {
+			return err
+		}
+		jr.unmatched, id = joinhash(b, expr.Bool(true))
+		b.Replacements[id] = t
 	}
 
 	// now remove all the equijoin steps;
@@ -183,12 +252,24 @@ func joinelim(b *Trace) error {
 		nv := &IterValue{
 			Value:  res.into,
 			Result: eq.built.From.(*expr.Table).Result(),
+			Outer:  eq.outer,
 		}
 		nv.setparent(eq.parent())
+		var top Step = nv
+		if res.unmatched != nil {
+			// the rows of the rhs without a match
+			// follow the output of the LEFT JOIN
+			ap := &Append{
+				Value:  res.unmatched,
+				Result: nv.Result,
+			}
+			ap.setparent(nv)
+			top = ap
+		}
 		if prev == nil {
-			b.top = nv
+			b.top = top
 		} else {
-			prev.setparent(nv)
+			prev.setparent(top)
 		}
 		prev = nv
 	}
@@ -16,9 +16,12 @@ package pir
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/SnellerInc/sneller/expr"
 	"github.com/SnellerInc/sneller/vm"
+
+	"golang.org/x/exp/maps"
 )
 
 // Split splits a query plan into a mapping
@@ -48,6 +51,9 @@ func Split(b *Trace) (*Trace, error) {
 	if _, ok := b.top.(NoOutput); ok {
 		return b, nil
 	}
+	if err := splitAppend(b); err != nil {
+		return nil, err
+	}
 	reduce := &Trace{finalTypes: b.FinalTypes()}
 	reduce.Replacements, b.Replacements = b.Replacements, nil
 	_, err := splitOne(b.top, b, reduce)
@@ -74,6 +80,58 @@ func NoSplit(t *Trace) *Trace {
 	return t
 }
 
+// splitAppend prepares each Append step in b
+// for splitting: the appended rows are produced
+// once in the reduction step, so the bindings
+// that the subsequent steps reference have to be
+// projected explicitly on both sides of the split
+// (the rows produced by the mapping step do not
+// carry the auxiliary bindings of IterValue)
+func splitAppend(b *Trace) error {
+	used := make(map[string]struct{})
+	walk := func(e expr.Node) {
+		if id, ok := e.(expr.Ident); ok {
+			used[string(id)] = struct{}{}
+		}
+	}
+	bound := false
+	for s := b.top; s != nil; s = s.parent() {
+		switch s := s.(type) {
+		case *Bind:
+			for i := range s.bind {
+				if _, ok := s.bind[i].Expr.(expr.Star); ok {
+					return fmt.Errorf("pir: cannot split %s following FULL JOIN", expr.ToString(&s.bind[i]))
+				}
+			}
+			maps.Clear(used)
+			bound = true
+		case *Aggregate:
+			maps.Clear(used)
+			bound = true
+		case *Unpivot, *UnpivotAtDistinct:
+			bound = false
+		case *Append:
+			if !bound {
+				return fmt.Errorf("pir: cannot split FULL JOIN without a projection")
+			}
+			names := maps.Keys(used)
+			if len(names) == 0 {
+				break
+			}
+			slices.Sort(names)
+			proj := &Bind{complete: true}
+			for i := range names {
+				proj.bind = append(proj.bind, expr.Bind(expr.Ident(names[i]), names[i]))
+				s.Columns = append(s.Columns, expr.Bind(expr.Ident(names[i]), names[i]))
+			}
+			proj.setparent(s.parent())
+			s.setparent(proj)
+		}
+		s.walk(walkfn(walk))
+	}
+	return nil
+}
+
 func fusesLimit(s Step) bool {
 	if _, ok := s.(*Order); ok {
 		return true
@@ -94,7 +152,7 @@ func splitOne(s Step, mapping, reduce *Trace) (bool, error) {
 		if um, ok := s.(*UnionMap); ok {
 			// we can't split a PARTITION BY ... step
 			// but we can split its contents!
-			if len(um.PartitionBy) == 0 {
+			if len(um.PartitionBy) == 0 && um.Buckets == 0 {
 				return false, fmt.Errorf("pir: unexpected partitioning step encountered during splitting")
 			}
 			child, err := Split(um.Child)
@@ -179,6 +237,12 @@ func splitOne(s Step, mapping, reduce *Trace) (bool, error) {
 		return false, nil
 	case *Aggregate:
 		return false, reduceAggregate(n, mapping, reduce)
+	case *Append:
+		// produce the appended rows once
+		mapping.top = par
+		n.setparent(reduce.top)
+		reduce.top = n
+		return false, nil
 	case *OutputIndex:
 		mapping.top = par
 		n.setparent(reduce.top)
@@ -254,7 +318,7 @@ func reduceAggregate(a *Aggregate, mapping, reduce *Trace) error {
 	needsFinalProjection := false
 	for i := range a.Agg {
 		switch a.Agg[i].Expr.Op {
-		case expr.OpApproxCountDistinct, expr.OpSum, expr.OpApproxPercentile, expr.OpApproxMedian:
+		case expr.OpApproxCountDistinct, expr.OpSum, expr.OpApproxPercentile, expr.OpApproxMedian, expr.OpMode:
 			// Opcode becomes its partial counterpart
 			a.Agg[i].Expr.Role = expr.AggregateRolePartial
 
@@ -335,7 +399,7 @@ func reduceAggregate(a *Aggregate, mapping, reduce *Trace) error {
 		innerref := expr.Identifier(gen)
 		var newagg *expr.Aggregate
 		switch age.Op {
-		case expr.OpCount:
+		case expr.OpCount, expr.OpCountIf:
 			// convert to SUM_COUNT(COUNT(x))
 			newagg = expr.SumCount(innerref)
 		case expr.OpMin, expr.OpMax:
@@ -369,6 +433,8 @@ func reduceAggregate(a *Aggregate, mapping, reduce *Trace) error {
 				Role:      expr.AggregateRoleMerge,
 				Precision: age.Precision,
 				Inner:     innerref}
+		case expr.OpMode:
+			newagg = &expr.Aggregate{Op: expr.OpMode, Role: expr.AggregateRoleMerge, Inner: innerref}
 		case expr.OpSystemDatashape:
 			newagg = &expr.Aggregate{
 				Op:    expr.OpSystemDatashapeMerge,
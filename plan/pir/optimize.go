@@ -75,6 +75,8 @@ func (b *Trace) optimize() error {
 	countdistinct2count(b) // turn count(distinct x) -> count(x) from (select distinct ...)
 	strengthReduce(b)      // strength-reduce kernels, replacing generic subtraces with their case-specific optimized variants
 	filterelim(b)          // eliminate WHERE TRUE
+	havingpushdown(b)      // move HAVING conditions on grouping columns ahead of GROUP BY
+	unpivotpushdown(b)     // filter the rows of an unpivoted table when possible
 	filterpushdown(b)      // merge adjacent filters
 	limitpushdown(b)       // push down LIMIT
 	err := joinelim(b)     // turn EquiJoin into a correlated sub-query + projection
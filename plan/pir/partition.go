@@ -100,6 +100,11 @@ func joinByPartition(b *Trace, s *IterValue) (*UnionMap, bool) {
 	//
 	// for b.Replacements[id]
 	//
+	if s.Outer {
+		// partitions of s without a matching
+		// partition on the other side would be lost
+		return nil, false
+	}
 	hr, ok := s.Value.(*expr.Builtin)
 	if !ok || hr.Func != expr.HashReplacement {
 		return nil, false
@@ -180,6 +185,96 @@ func joinByPartition(b *Trace, s *IterValue) (*UnionMap, bool) {
 	return um, true
 }
 
+// joinBuckets returns the number of hash buckets
+// into which a join is split when the side that is
+// materialized has the given number of rows;
+// each bucket is expected to hold at most
+// LargeSize/2 rows so that some skew between
+// the buckets is tolerated
+func joinBuckets(rows int64) int {
+	n := 1
+	for rows > int64(n)*(LargeSize/2) {
+		n *= 2
+	}
+	return n
+}
+
+// bucketFilter inserts a filter that matches
+// HASH_BUCKET(key, n) = BUCKET_VALUE() below s
+func bucketFilter(s Step, key expr.Node, n int) {
+	f := &Filter{
+		Where: expr.Compare(expr.Equals,
+			expr.Call(expr.HashBucket, key, expr.Integer(n)),
+			expr.Call(expr.BucketValue)),
+	}
+	f.setparent(s.parent())
+	s.setparent(f)
+}
+
+func joinByBucket(b *Trace, s *IterValue) (*UnionMap, bool) {
+	// match
+	//
+	//   HASH_REPLACEMENT(id, 'joinlist', k, x)
+	//
+	// where the replacement is known to have
+	// too many rows to be materialized at once
+	// and split both sides of the join into
+	// buckets by the hash of the join key so that
+	// only one bucket of the replacement is
+	// materialized at a time
+	if s.Outer {
+		// rows of s with a MISSING key
+		// do not belong to any bucket
+		return nil, false
+	}
+	if len(b.Replacements) != 1 {
+		// the other replacements would have to be
+		// moved into the bucketed part of the query
+		return nil, false
+	}
+	hr, ok := s.Value.(*expr.Builtin)
+	if !ok || hr.Func != expr.HashReplacement {
+		return nil, false
+	}
+	if str, ok := hr.Args[1].(expr.String); !ok || string(str) != "joinlist" {
+		return nil, false
+	}
+	if _, ok := hr.Args[3].(expr.Constant); ok {
+		// a nested-loop join has only one bucket
+		return nil, false
+	}
+	id := int(hr.Args[0].(expr.Integer))
+	k := string(hr.Args[2].(expr.String))
+	sub := b.Replacements[id]
+	rows, ok := traceRows(sub)
+	if !ok || rows <= LargeSize {
+		return nil, false
+	}
+	var outert *IterTable
+	for step := Step(s); step != nil; step = step.parent() {
+		outert, _ = step.(*IterTable)
+	}
+	whence, key := sub.top.get(k)
+	if outert == nil || whence == nil || key == nil {
+		return nil, false
+	}
+	n := joinBuckets(rows)
+	bucketFilter(whence, key, n)
+	bucketFilter(s, hr.Args[3], n)
+	filterpushdown(sub)
+	um := &UnionMap{
+		Inner: outert,
+		Child: &Trace{
+			Parent:       b,
+			Replacements: b.Replacements,
+			top:          s,
+		},
+		Buckets: n,
+	}
+	b.Replacements = nil
+	return um, true
+}
+
 func distinctByPartition(b *Trace, s *Bind) (*UnionMap, bool) {
 	d, ok := s.parent().(*Distinct)
 	if !ok {
@@ -313,6 +408,37 @@ func aggByPartition(b *Trace, agg *Aggregate) (*UnionMap, bool) {
 	return um, true
 }
 
+// traceRows returns the total number of rows
+// in the tables iterated by b and by the
+// replacements it depends on, or false if the
+// number of rows in any of them is not known.
+func traceRows(b *Trace) (int64, bool) {
+	var rows int64
+	for _, step := range steps(b) {
+		it, ok := step.(*IterTable)
+		if !ok {
+			continue
+		}
+		sz, ok := it.Index.(Sizer)
+		if !ok {
+			return 0, false
+		}
+		n, ok := sz.Rows()
+		if !ok {
+			return 0, false
+		}
+		rows += n
+	}
+	for _, r := range b.Replacements {
+		n, ok := traceRows(r)
+		if !ok {
+			return 0, false
+		}
+		rows += n
+	}
+	return rows, true
+}
+
 func steps(b *Trace) []Step {
 	var out []Step
 	for s := b.top; s != nil; s = s.parent() {
@@ -332,6 +458,13 @@ func trivialSplit(s Step) bool {
 
 func partition(b *Trace) {
 	lst := steps(b)
+	for i := range lst {
+		if _, ok := lst[i].(*Append); ok {
+			// the appended rows do not
+			// belong to any partition
+			return
+		}
+	}
 	for i := range lst {
 		s := lst[i]
 		var ok bool
@@ -339,6 +472,9 @@ func partition(b *Trace) {
 		switch s := s.(type) {
 		case *IterValue:
 			self, ok = joinByPartition(b, s)
+			if !ok {
+				self, ok = joinByBucket(b, s)
+			}
 		case *Aggregate:
 			if len(s.GroupBy) > 0 {
 				self, ok = aggByPartition(b, s)
@@ -212,6 +212,9 @@ type IterValue struct {
 	parented
 	Value  expr.Node // the expression to be iterated
 	Result string    // the binding produced by iteration
+	// Outer is set if rows for which Value
+	// is not a non-empty list are preserved
+	Outer bool
 }
 
 func (i *IterValue) walk(v expr.Visitor) {
@@ -221,10 +224,14 @@ func (i *IterValue) walk(v expr.Visitor) {
 func (i *IterValue) equals(x Step) bool {
 	i2, ok := x.(*IterValue)
 	return ok && (i == i2 ||
-		(expr.Equal(i.Value, i2.Value) && i.Result == i2.Result))
+		(expr.Equal(i.Value, i2.Value) && i.Result == i2.Result && i.Outer == i2.Outer))
 }
 
 func (i *IterValue) describe(dst io.Writer) {
+	if i.Outer {
+		fmt.Fprintf(dst, "ITERATE OUTER FIELD %s AS %s\n", expr.ToString(i.Value), i.Result)
+		return
+	}
 	fmt.Fprintf(dst, "ITERATE FIELD %s AS %s\n", expr.ToString(i.Value), i.Result)
 }
 
@@ -232,6 +239,56 @@ func (i *IterValue) rewrite(rw func(expr.Node, bool) expr.Node) {
 	i.Value = rw(i.Value, false)
 }
 
+// Append produces the rows produced by its parent
+// followed by one row for each element of Value,
+// in which Result is bound to the element and
+// nothing else is bound. It is used for the rows
+// of the rhs of a FULL JOIN without a match.
+type Append struct {
+	parented
+	Value  expr.Node
+	Result string
+	// Columns, if non-nil, is a projection
+	// applied to the appended rows only
+	// (see splitAppend)
+	Columns []expr.Binding
+}
+
+func (a *Append) walk(v expr.Visitor) {
+	expr.Walk(v, a.Value)
+	for i := range a.Columns {
+		expr.Walk(v, a.Columns[i].Expr)
+	}
+}
+
+func (a *Append) equals(x Step) bool {
+	a2, ok := x.(*Append)
+	return ok && (a == a2 ||
+		expr.Equal(a.Value, a2.Value) && a.Result == a2.Result &&
+			slices.EqualFunc(a.Columns, a2.Columns, expr.Binding.Equals))
+}
+
+func (a *Append) describe(dst io.Writer) {
+	fmt.Fprintf(dst, "APPEND FIELD %s AS %s", expr.ToString(a.Value), a.Result)
+	if a.Columns != nil {
+		io.WriteString(dst, " PROJECT ")
+		for i := range a.Columns {
+			if i != 0 {
+				io.WriteString(dst, ", ")
+			}
+			io.WriteString(dst, expr.ToString(&a.Columns[i]))
+		}
+	}
+	io.WriteString(dst, "\n")
+}
+
+func (a *Append) rewrite(rw func(expr.Node, bool) expr.Node) {
+	a.Value = rw(a.Value, false)
+	for i := range a.Columns {
+		a.Columns[i].Expr = rw(a.Columns[i].Expr, false)
+	}
+}
+
 type EquiJoin struct {
 	parented
 
@@ -250,6 +307,30 @@ type EquiJoin struct {
 	// key is the computed inner key expression,
 	// and value is the outer variable compared against it
 	key, value expr.Node
+
+	// outer is set for a LEFT JOIN, in which case
+	// rows without a match are preserved with
+	// the join binding set to MISSING
+	outer bool
+
+	// full is set for a FULL JOIN, which is
+	// also an outer join
+	full *fullInfo
+}
+
+// fullInfo is the part of a FULL JOIN that is
+// needed to find the rows of the rhs that
+// do not match any row of the lhs
+type fullInfo struct {
+	left *expr.Table // the lhs
+	on   expr.Node   // the ON condition
+}
+
+func (f *fullInfo) equals(f2 *fullInfo) bool {
+	if f == nil || f2 == nil {
+		return f == f2
+	}
+	return f.left.Equals(f2.left) && f.on.Equals(f2.on)
 }
 
 func (e *EquiJoin) get(x string) (Step, expr.Node) {
@@ -262,14 +343,22 @@ func (e *EquiJoin) get(x string) (Step, expr.Node) {
 
 // push one part of a filter expression
 func (e *EquiJoin) filterOne(node expr.Node, s *Trace) bool {
+	if e.full != nil {
+		// neither side can be filtered ahead of
+		// a FULL JOIN, since either one may be
+		// NULL-extended
+		return false
+	}
 	self := e.built.From.(*expr.Table).Result()
 	// base case: doesn't reference the join
 	if doesNotReference(node, self) {
 		push(&Filter{Where: node}, e.parent(), s)
 		return true
 	}
-	// another base case: *only* references the join
-	if onlyReferences(node, self) {
+	// another base case: *only* references the join;
+	// this can't be pushed down in an outer join, since
+	// it has to reject the rows that didn't match
+	if onlyReferences(node, self) && !e.outer {
 		// easy: just push this into the inner WHERE
 		if e.built.Where == nil {
 			e.built.Where = node
@@ -282,6 +371,11 @@ func (e *EquiJoin) filterOne(node expr.Node, s *Trace) bool {
 }
 
 func (e *EquiJoin) describe(w io.Writer) {
+	if e.full != nil {
+		io.WriteString(w, "FULL ")
+	} else if e.outer {
+		io.WriteString(w, "LEFT ")
+	}
 	fmt.Fprintf(w, "EQUIJOIN ON %s = %s FROM %s\n",
 		expr.ToString(e.key), expr.ToString(e.value), expr.ToString(e.built))
 }
@@ -293,7 +387,9 @@ func (e *EquiJoin) equals(s Step) bool {
 	}
 	return e.built.Equals(e2.built) &&
 		e.key.Equals(e2.key) &&
-		e.value.Equals(e2.value)
+		e.value.Equals(e2.value) &&
+		e.outer == e2.outer &&
+		e.full.equals(e2.full)
 }
 
 func (e *EquiJoin) rewrite(rw func(expr.Node, bool) expr.Node) {
@@ -420,6 +516,12 @@ type UnionMap struct {
 	// PartitionBy[i] corresponds to PARTITION_VALUE(i)
 	// within each step within Child.
 	PartitionBy []string
+	// Buckets, if non-zero, is the number of
+	// hash buckets over which the unioning is
+	// partitioned instead: Child is invoked once
+	// for each bucket with BUCKET_VALUE() replaced
+	// by the number of the bucket.
+	Buckets int
 
 	noexprs
 }
@@ -430,7 +532,7 @@ func (u *UnionMap) setparent(Step) { panic("cannot UnionMap.setparent()") }
 func (u *UnionMap) equals(x Step) bool {
 	u2, ok := x.(*UnionMap)
 	return ok && (u == u2 || u.Inner.equals(u2.Inner) &&
-		u.Child.Equals(u2.Child))
+		u.Buckets == u2.Buckets && u.Child.Equals(u2.Child))
 }
 
 func (u *UnionMap) get(x string) (Step, expr.Node) {
@@ -461,6 +563,9 @@ func (u *UnionMap) describe(dst io.Writer) {
 		}
 		io.WriteString(dst, u.PartitionBy[i])
 	}
+	if u.Buckets > 0 {
+		fmt.Fprintf(dst, " BUCKETS %d", u.Buckets)
+	}
 	io.WriteString(dst, " (\n\t")
 	dst.Write(inner)
 	io.WriteString(dst, ")\n")
@@ -846,6 +951,11 @@ type Trace struct {
 	// The traces in Input may be executed
 	// in any order.
 	Replacements []*Trace
+	// Exists is set if this trace is a replacement
+	// that is only used to determine whether or not
+	// it produces any rows, in which case only
+	// the first row it produces is needed.
+	Exists bool
 
 	prcache *pathRewriter
 
@@ -896,6 +1006,13 @@ func (b *Trace) Begin(f *expr.Table, e Env) error {
 		it.Index = idx
 	}
 	b.top = it
+	// rows matching tombstones are filtered
+	// out as if they were never present
+	if d, ok := it.Index.(Deleter); ok {
+		if pred := d.Deleted(); pred != nil {
+			return b.Where(expr.Is(pred, expr.IsNotTrue))
+		}
+	}
 	return nil
 }
 
@@ -964,7 +1081,18 @@ func (b *Trace) Where(e expr.Node) error {
 
 // Iterate pushes an implicit iteration to the stack
 func (b *Trace) Iterate(bind *expr.Binding) error {
-	iv := &IterValue{Value: bind.Expr}
+	return b.iterate(bind, false)
+}
+
+// IterateOuter is like Iterate, but every row for which
+// the iterated value has no elements is preserved with
+// the iteration binding set to MISSING
+func (b *Trace) IterateOuter(bind *expr.Binding) error {
+	return b.iterate(bind, true)
+}
+
+func (b *Trace) iterate(bind *expr.Binding, outer bool) error {
+	iv := &IterValue{Value: bind.Expr, Outer: outer}
 	iv.Result = bind.Result()
 	// walk with the current scope
 	// set to the parent scope; we don't
@@ -1170,24 +1298,119 @@ func splitOnEqual(self string, on expr.Node) (key, value expr.Node, err error) {
 	return key, value, nil
 }
 
-func (b *Trace) innerJoin(bind *expr.Binding, on expr.Node, env Env) error {
-	key, value, err := splitOnEqual(bind.Result(), on)
-	if err != nil {
+// equalities splits the conjunctions of the ON
+// condition of a join against self into the inner
+// and outer halves of the equality conditions
+// (see splitOnEqual) and everything else
+func equalities(self string, on expr.Node) (keys, values, rest []expr.Node) {
+	if on == nil {
+		return nil, nil, nil
+	}
+	for _, c := range conjunctions(on, nil) {
+		k, v, err := splitOnEqual(self, c)
+		if err != nil {
+			rest = append(rest, c)
+			continue
+		}
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	return keys, values, rest
+}
+
+// joinkey combines the halves of a list of
+// equality conditions into a single hash key
+func joinkey(lst []expr.Node) expr.Node {
+	if len(lst) == 1 {
+		return lst[0]
+	}
+	return expr.Call(expr.MakeList, lst...)
+}
+
+// fullJoin pushes an EquiJoin against bind that
+// is a LEFT JOIN which additionally produces the
+// rows of bind without a match in left (see joinelim)
+func (b *Trace) fullJoin(left *expr.Table, bind *expr.Binding, on expr.Node, env Env) error {
+	// the rows of bind that are produced without
+	// a match are found by looking up their keys in
+	// the keys of left, so every condition has to
+	// be part of the key
+	keys, _, rest := equalities(bind.Result(), expr.Copy(on))
+	if len(rest) > 0 {
+		return fmt.Errorf("FULL JOIN ON condition %s unsupported", expr.ToString(rest[0]))
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("FULL JOIN requires an equality ON condition")
+	}
+	orig := expr.Copy(on)
+	if err := b.innerJoin(bind, on, env, true); err != nil {
 		return err
 	}
+	eq := b.top.(*EquiJoin)
+	eq.full = &fullInfo{
+		left: expr.Copy(left).(*expr.Table),
+		on:   orig,
+	}
+	return nil
+}
+
+// innerJoin pushes an EquiJoin against bind;
+// if outer is set, then it is a LEFT JOIN instead
+func (b *Trace) innerJoin(bind *expr.Binding, on expr.Node, env Env, outer bool) error {
+	// equality conditions become the hash key;
+	// everything else is evaluated as a filter
+	// on the output of the join
+	keys, values, rest := equalities(bind.Result(), on)
+	var where expr.Node
+	if outer {
+		// the conditions on the rhs alone can be
+		// evaluated before the join; anything else
+		// would have to reject matches after the fact,
+		// which we can't express with a hash lookup
+		for _, c := range rest {
+			if !onlyReferences(c, bind.Result()) {
+				return fmt.Errorf("LEFT JOIN ON condition %s unsupported", expr.ToString(c))
+			}
+			if where == nil {
+				where = c
+			} else {
+				where = expr.And(where, c)
+			}
+		}
+		rest = nil
+	}
+	var key, value expr.Node
+	switch len(keys) {
+	case 0:
+		// no equi-key, so this is a nested-loop join:
+		// every row of the rhs lands in the same bucket
+		// and is produced for every row of the lhs
+		//
+		// NOTE: the rhs is always the materialized (inner)
+		// side, but the sides of a join of two tables are
+		// swapped when the lhs is known to be smaller
+		// (see smallerLeft); the number of rows the rhs
+		// may produce is bounded by ExecParams.MaxSubqueryRows
+		// at execution time
+		key, value = expr.Bool(true), expr.Bool(true)
+	default:
+		key, value = joinkey(keys), joinkey(values)
+	}
 	eq := &EquiJoin{
 		built: &expr.Select{
 			Columns: []expr.Binding{expr.Bind(key, "$__key")},
 			From:    &expr.Table{Binding: *bind},
+			Where:   where,
 		},
-		env: env,
-		key: key,
+		env:   env,
+		key:   key,
+		outer: outer,
 	}
 	eq.setparent(b.top)
 	b.cur = eq
 	// check the inner condition (the one that references the outer table(s))
 	// against the existing trace
-	value, err = b.pathwalk(value)
+	value, err := b.pathwalk(value)
 	if err != nil {
 		return err
 	}
@@ -1195,7 +1418,37 @@ func (b *Trace) innerJoin(bind *expr.Binding, on expr.Node, env Env) error {
 	if err := check(b.top, value); err != nil {
 		return err
 	}
-	return b.push()
+	if err := b.push(); err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	var cond expr.Node
+	for i := len(rest) - 1; i >= 0; i-- {
+		if cond == nil {
+			cond = rest[i]
+		} else {
+			cond = expr.And(cond, rest[i])
+		}
+	}
+	return b.Where(cond)
+}
+
+// isTableRef returns true if e is a path that
+// cannot resolve to any binding that is in scope,
+// in which case it must refer to another table
+func (b *Trace) isTableRef(e expr.Node) bool {
+	path, ok := expr.FlatPath(e)
+	if !ok {
+		return false
+	}
+	step, node := b.top.get(path[0])
+	if node != nil {
+		return false
+	}
+	it, ok := step.(*IterTable)
+	return ok && it.Bind != "" && !it.haveParent
 }
 
 // Into handles the INTO clause by pushing
@@ -145,12 +145,6 @@ loop:
 			maps.Clear(used)
 		case *IterTable:
 			s.trim(used)
-		case *IterValue:
-			if _, ok := used[s.Result]; !ok {
-				// cross-join result isn't used
-				parent.setparent(s.parent())
-				continue loop
-			}
 		case *Unpivot, *UnpivotAtDistinct:
 			return // all incoming fields are used
 		default:
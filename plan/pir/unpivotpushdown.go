@@ -0,0 +1,126 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package pir
+
+import (
+	"github.com/SnellerInc/sneller/expr"
+)
+
+// unpivotKey returns the field name k if
+// e is equivalent to 'at = k'
+func unpivotKey(e expr.Node, at string) (string, bool) {
+	cmp, ok := e.(*expr.Comparison)
+	if !ok || cmp.Op != expr.Equals {
+		return "", false
+	}
+	left, right := cmp.Left, cmp.Right
+	if _, ok := left.(expr.String); ok {
+		left, right = right, left
+	}
+	id, ok := left.(expr.Ident)
+	if !ok || string(id) != at {
+		return "", false
+	}
+	str, ok := right.(expr.String)
+	return string(str), ok
+}
+
+// unpivotPrefilter returns a filter that is
+// implied by the conjunctions conj of a filter
+// applied to the output of UNPIVOT and that can
+// be evaluated on the rows that are unpivoted,
+// or nil if there is no such filter
+func unpivotPrefilter(u *expr.Unpivot, conj []expr.Node) expr.Node {
+	if u.At == nil {
+		return nil
+	}
+	key := ""
+	found := false
+	for i := range conj {
+		key, found = unpivotKey(conj[i], *u.At)
+		if found {
+			break
+		}
+	}
+	if !found {
+		return nil
+	}
+	// only rows that contain the field produce
+	// an output row with 'at = key'...
+	pre := []expr.Node{expr.Is(expr.Ident(key), expr.IsNotMissing)}
+	if u.As != nil {
+		// ...and the value of that output row
+		// is the value of the field
+		rw := &bindflattener{from: []expr.Binding{expr.Bind(expr.Ident(key), *u.As)}}
+		for i := range conj {
+			if onlyReferences(conj[i], *u.As) {
+				pre = append(pre, expr.Rewrite(rw, expr.Copy(conj[i])))
+			}
+		}
+	}
+	var out expr.Node = pre[0]
+	for i := range pre[1:] {
+		out = expr.And(out, pre[i+1])
+	}
+	return out
+}
+
+// unpivotpushdown pushes the part of a filter
+// on the output of UNPIVOT that can be evaluated
+// on the rows of the unpivoted table into the
+// table scan:
+//
+//	SELECT * FROM UNPIVOT t AS v AT a WHERE a = 'x' AND v > 0
+//
+// only produces rows from rows of t where
+// 'x IS NOT MISSING AND x > 0' holds.
+//
+// The original filter is always kept, since the
+// other fields of a matching row are unpivoted
+// as well. The following filters are not pushed
+// down, since their scan-level equivalent depends
+// on the set of fields of each row, which is not
+// known when the query is planned:
+//
+//   - filters on the value alone (WHERE v > 0 holds
+//     for a row if any one of its fields is positive)
+//   - filters on the field name other than equality
+//     with a constant (a <> 'x', a LIKE 'x%', ...)
+//   - disjunctions of conditions on the field name
+//     and the value (a = 'x' OR v > 0)
+//
+// The rewrite also assumes that field names are
+// unique within a row, so that 'x' in the scan
+// refers to the same value as 'v' in the output.
+func unpivotpushdown(b *Trace) {
+	for s := b.top; s != nil; s = s.parent() {
+		f, ok := s.(*Filter)
+		if !ok {
+			continue
+		}
+		u, ok := f.parent().(*Unpivot)
+		if !ok {
+			continue
+		}
+		it, ok := u.parent().(*IterTable)
+		if !ok {
+			continue
+		}
+		pre := unpivotPrefilter(u.Ast, conjunctions(f.Where, nil))
+		if pre != nil {
+			it.filter(pre, b)
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync/atomic"
 
 	"github.com/SnellerInc/sneller/expr"
 	"github.com/SnellerInc/sneller/ion"
@@ -345,7 +346,19 @@ func (s *SimpleAggregate) exec(dst vm.QuerySink, src *Input, ep *ExecParams) err
 		}
 	}
 
-	a, err := vm.NewAggregate(ep.rewriteAgg(s.Outputs), dst)
+	outputs := ep.rewriteAgg(s.Outputs)
+	if n, ok := s.metadataCount(outputs, src, ep); ok {
+		var b ion.Buffer
+		var st ion.Symtab
+		label := st.Intern(outputs[0].Result)
+		st.Marshal(&b, true)
+		b.BeginStruct(-1)
+		b.BeginField(label)
+		b.WriteInt(n)
+		b.EndStruct()
+		return writeIon(&b, dst)
+	}
+	a, err := vm.NewAggregate(outputs, dst)
 	if err != nil {
 		return err
 	}
@@ -353,6 +366,59 @@ func (s *SimpleAggregate) exec(dst vm.QuerySink, src *Input, ep *ExecParams) err
 	return s.From.exec(a, src, ep)
 }
 
+// metadataCount computes the result of a lone
+// COUNT(*) over an unfiltered table from the row
+// counts recorded in the trailers of src.
+// It returns false if the query does not have
+// that shape, if any of the row counts are unknown,
+// or if ep.Runner is anything other than an FSRunner
+// that reads every row of every block without
+// side-effects, in which case the query must be
+// executed normally.
+//
+// Filtered counts always take the normal path:
+// the sparse index can prove that a block has
+// no matching rows, but not that every row in a
+// block matches (a row without the indexed field
+// is still inside the block's time range), so
+// there is no set of blocks whose row counts
+// could be used as-is.
+func (s *SimpleAggregate) metadataCount(outputs vm.Aggregation, src *Input, ep *ExecParams) (int64, bool) {
+	if len(outputs) != 1 || src == nil {
+		return 0, false
+	}
+	r, ok := ep.Runner.(*FSRunner)
+	if !ok || r.SampleFraction > 0 || r.Filter != nil || r.OnBlock != nil || r.Verify {
+		return 0, false
+	}
+	if ep.budget != nil && ep.budget.MaxBytesScanned > 0 {
+		return 0, false
+	}
+	agg := outputs[0].Expr
+	if agg.Op != expr.OpCount || agg.Inner != (expr.Star{}) || agg.Filter != nil || agg.Over != nil {
+		return 0, false
+	}
+	leaf, ok := s.From.(*Leaf)
+	if !ok || leaf.Filter != nil || len(leaf.EqualExpr) > 0 {
+		return 0, false
+	}
+	total := int64(0)
+	for i := range src.Descs {
+		tr := &src.Descs[i].Trailer
+		for _, iv := range src.Descs[i].Blocks {
+			n, ok := tr.Rows(iv.Start, iv.End)
+			if !ok {
+				return 0, false
+			}
+			total += n
+		}
+	}
+	if total == 0 && s.NonEmpty {
+		return 0, false
+	}
+	return total, true
+}
+
 func settype(name string, dst *ion.Buffer, st *ion.Symtab) {
 	dst.BeginField(st.Intern("type"))
 	dst.WriteSymbol(st.Intern(name))
@@ -452,10 +518,14 @@ func (l *Leaf) exec(dst vm.QuerySink, src *Input, ep *ExecParams) error {
 	if filt != nil {
 		src = src.Filter(filt)
 	}
+	before := atomic.LoadInt64(&ep.Stats.BytesScanned)
 	err := ep.Runner.Run(dst, src, ep)
 	if errors.Is(err, io.EOF) {
 		err = nil
 	}
+	if err == nil {
+		err = ep.budget.scan(atomic.LoadInt64(&ep.Stats.BytesScanned) - before)
+	}
 	err2 := dst.Close()
 	if err == nil {
 		err = err2
@@ -1379,6 +1449,10 @@ func (e *Explain) SetField(f ion.Field) error {
 }
 
 func (e *Explain) exec(dst vm.QuerySink, src *Input, ep *ExecParams) error {
+	if e.Format == expr.ExplainAnalyze {
+		return e.analyze(dst, ep)
+	}
+
 	var b ion.Buffer
 	var st ion.Symtab
 
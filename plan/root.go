@@ -16,8 +16,10 @@ package plan
 
 import (
 	"context"
+	"hash/fnv"
 	"io"
 	"io/fs"
+	"math/rand"
 	"runtime"
 	"slices"
 	"sync"
@@ -48,25 +50,83 @@ type Runner interface {
 // a file system.
 type FSRunner struct {
 	fs.FS
+
+	// SampleFraction, if it is between 0 and 1,
+	// is the fraction of blocks that are read
+	// from each input; the remaining blocks are
+	// skipped entirely. The set of blocks that is
+	// read is determined by SampleSeed and the
+	// position of each block, so the same seed
+	// always selects the same blocks.
+	SampleFraction float64
+	// SampleSeed is the seed used to select
+	// blocks when SampleFraction is set.
+	SampleSeed uint64
+
+	// Filter, if non-nil, is a predicate that is
+	// evaluated against the sparse index of each
+	// input; blocks that cannot match Filter
+	// are not read, and the number of skipped
+	// blocks is added to ExecStats.BlocksSkipped.
+	Filter *blockfmt.Filter
+
+	// Verify, if set, causes the checksum of
+	// each block to be verified as it is read.
+	Verify bool
+
+	// MaxReads, if positive, is the maximum number
+	// of blocks that are read concurrently across
+	// every call to Run, regardless of the parallelism
+	// requested by each call. This bounds the total
+	// number of block reads when many inputs are
+	// scanned at once (for example, by the partitions
+	// of a query or by concurrent sub-queries).
+	MaxReads int
+
+	// OnBlock, if non-nil, is called after each
+	// block is read with the position of the block
+	// and the number of rows that it produced.
+	// Blocks are numbered consecutively across the
+	// descriptors of each input, in order.
+	// OnBlock may be called concurrently
+	// from multiple goroutines.
+	OnBlock func(block int, rows int64)
+
+	once  sync.Once
+	reads chan struct{}
 }
 
 // Run implements Runner.Run
 func (r *FSRunner) Run(dst vm.QuerySink, src *Input, ep *ExecParams) error {
 	in := make([]readerInput, len(src.Descs))
+	base := 0
 	for i := range src.Descs {
 		in[i].desc = &src.Descs[i].Descriptor
 		in[i].blks = src.Descs[i].Blocks.Clone()
+		in[i].base = base
+		base += len(in[i].desc.Trailer.Blocks)
 	}
 	tbl := readerTable{
-		fs:     r.FS,
-		in:     in,
-		fields: src.Fields,
+		fs:      r.FS,
+		in:      in,
+		fields:  src.Fields,
+		sample:  r.SampleFraction,
+		seed:    r.SampleSeed,
+		filter:  r.Filter.Clone(),
+		verify:  r.Verify,
+		budget:  ep.budget,
+		onblock: r.OnBlock,
+	}
+	if r.MaxReads > 0 {
+		r.once.Do(func() { r.reads = make(chan struct{}, r.MaxReads) })
+		tbl.reads = r.reads
 	}
 	// fast-path for local files: use mmap for reading
 	if dfs, ok := r.FS.(*blockfmt.DirFS); ok {
 		for i := range in {
 			in[i].mapped, _ = dfs.Mmap(in[i].desc.Path)
 		}
+		tbl.willneed = dfs.WillNeed
 		defer func() {
 			for i := range in {
 				if in[i].mapped != nil {
@@ -75,8 +135,13 @@ func (r *FSRunner) Run(dst vm.QuerySink, src *Input, ep *ExecParams) error {
 			}
 		}()
 	}
-	err := tbl.WriteChunks(dst, ep.Parallel)
+	ctx := ep.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := tbl.WriteChunksContext(ctx, dst, ep.Parallel)
 	ep.Stats.Observe(&tbl)
+	atomic.AddInt64(&ep.Stats.BlocksSkipped, tbl.Skipped())
 	return err
 }
 
@@ -84,6 +149,7 @@ type readerInput struct {
 	desc   *blockfmt.Descriptor
 	blks   ints.Intervals
 	mapped []byte
+	base   int // number of blocks in preceding inputs
 }
 
 type readerTable struct {
@@ -93,6 +159,33 @@ type readerTable struct {
 	idx     int
 	lock    sync.Mutex
 	scanned int64
+
+	// block sampling; see FSRunner.SampleFraction
+	sample float64
+	seed   uint64
+
+	// block pruning; see FSRunner.Filter
+	filter  *blockfmt.Filter
+	skipped int64
+
+	verify bool // see FSRunner.Verify
+
+	// budget, if non-nil, limits the
+	// number of bytes that may be scanned
+	budget *budget
+
+	// reads, if non-nil, is a semaphore
+	// shared with other tables that limits
+	// the number of concurrent block reads;
+	// see FSRunner.MaxReads
+	reads chan struct{}
+
+	onblock func(block int, rows int64) // see FSRunner.OnBlock
+
+	// willneed, if set, is called with the
+	// range of the block following the one
+	// being copied from readerInput.mapped
+	willneed func(mem []byte, start, end int64)
 }
 
 func (f *readerTable) next() (in *readerInput, off int) {
@@ -101,6 +194,9 @@ func (f *readerTable) next() (in *readerInput, off int) {
 	for f.idx < len(f.in) {
 		in = &f.in[f.idx]
 		if off, ok := in.blks.Next(); ok {
+			if !f.sampled(in, off) {
+				continue
+			}
 			return in, off
 		}
 		f.idx = f.idx + 1
@@ -108,6 +204,22 @@ func (f *readerTable) next() (in *readerInput, off int) {
 	return nil, 0
 }
 
+// sampled returns whether block off of in
+// belongs to the sample selected by f.seed
+func (f *readerTable) sampled(in *readerInput, off int) bool {
+	if f.sample <= 0 || f.sample >= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	io.WriteString(h, in.desc.Path)
+	z := f.seed ^ h.Sum64()
+	z += uint64(off+1) * 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11)/(1<<53) < f.sample
+}
+
 // IfMatcher can be implemented by a file that
 // supports ETag matching using semantics
 // compatible with the HTTP "If-Match" header.
@@ -129,43 +241,168 @@ func vmMalloc(size int) []byte {
 	return vm.Malloc()[:size]
 }
 
-func (f *readerTable) write(dst io.Writer) error {
+// write copies blocks into dst until
+// there are none left, ctx is canceled,
+// or done is closed because the output
+// will not accept any more rows
+func (f *readerTable) write(ctx context.Context, dst io.Writer, done <-chan struct{}) error {
 	var d blockfmt.Decoder
 	d.Malloc = vmMalloc
 	d.Free = vm.Free
 	d.Fields = f.fields
+	d.MaxBlockBytes = vm.PageSize
+	d.Verify = f.verify
+	var br *blockRows
+	if f.onblock != nil {
+		br = &blockRows{dst: dst}
+		dst = br
+	}
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if f.reads != nil {
+			select {
+			case f.reads <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-done:
+				return nil
+			}
+		}
+		select {
+		case <-done:
+			f.release()
+			return nil
+		default:
+		}
 		in, off := f.next()
 		if in == nil {
+			f.release()
 			break
 		}
-		d.Set(&in.desc.Trailer)
-		pos := in.desc.Trailer.Blocks[off].Offset
-		end := in.desc.Trailer.Offset
-		if off < len(in.desc.Trailer.Blocks)-1 {
-			end = in.desc.Trailer.Blocks[off+1].Offset
+		if br != nil {
+			br.start(in.desc.Trailer.Blocks[off].Rows)
 		}
-		size := int64(in.desc.Trailer.Blocks[off].Chunks) << d.BlockShift
-		var err error
-		if in.mapped != nil {
-			_, err = d.CopyBytes(dst, in.mapped[pos:end])
-		} else {
-			var src io.ReadCloser
-			src, err = fsutil.OpenRange(f.fs, in.desc.Path, in.desc.ETag, pos, end-pos)
-			if err != nil {
-				return err
-			}
-			_, err = d.Copy(dst, src)
-			src.Close()
+		size, err := f.copy(ctx, &d, dst, in, off)
+		f.release()
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
 		if err != nil {
 			return err
 		}
-		atomic.AddInt64(&f.scanned, size)
+		if err := f.budget.check(atomic.AddInt64(&f.scanned, size)); err != nil {
+			return err
+		}
+		if br != nil {
+			f.onblock(in.base+off, br.count())
+		}
 	}
 	return nil
 }
 
+// blockRows counts the rows written
+// into dst for the block being read
+type blockRows struct {
+	dst  io.Writer
+	rows int64
+	// known is the number of rows in the
+	// block being read according to the
+	// trailer, or zero if it is not known
+	known int64
+	// zion is set if dst accepted zion-encoded
+	// data for the block being read
+	zion bool
+}
+
+// start prepares to count the rows of a new block;
+// known is the number of rows recorded in the
+// trailer for the block, or zero
+func (b *blockRows) start(known int64) {
+	b.rows = 0
+	b.known = known
+	b.zion = false
+}
+
+// count returns the number of rows in the block
+func (b *blockRows) count() int64 {
+	if b.zion {
+		return b.known
+	}
+	return b.rows
+}
+
+func (b *blockRows) Write(p []byte) (int, error) {
+	n, err := b.dst.Write(p)
+	if err == nil && !b.zion {
+		b.rows += blockfmt.CountRows(p)
+	}
+	return n, err
+}
+
+// ConfigureZion implements blockfmt.ZionWriter.
+//
+// Zion-encoded data can't be counted as it is
+// written, so it is only passed through when
+// the trailer records the number of rows in
+// the block.
+func (b *blockRows) ConfigureZion(blocksize int64, fields []string) bool {
+	if b.known <= 0 {
+		return false
+	}
+	zw, ok := b.dst.(blockfmt.ZionWriter)
+	b.zion = ok && zw.ConfigureZion(blocksize, fields)
+	return b.zion
+}
+
+// EndSegment implements vm.EndSegmentWriter
+func (b *blockRows) EndSegment() { vm.HintEndSegment(b.dst) }
+
+// release releases the semaphore
+// acquired in write, if any
+func (f *readerTable) release() {
+	if f.reads != nil {
+		<-f.reads
+	}
+}
+
+// copy copies block off of in into dst
+// and returns the decompressed size of the block
+func (f *readerTable) copy(ctx context.Context, d *blockfmt.Decoder, dst io.Writer, in *readerInput, off int) (int64, error) {
+	d.Set(&in.desc.Trailer)
+	d.Checksum = in.desc.Trailer.Blocks[off].Checksum
+	pos := in.desc.Trailer.Blocks[off].Offset
+	end := in.desc.Trailer.Offset
+	if off < len(in.desc.Trailer.Blocks)-1 {
+		end = in.desc.Trailer.Blocks[off+1].Offset
+	}
+	size := int64(in.desc.Trailer.Blocks[off].Chunks) << d.BlockShift
+	if in.mapped != nil {
+		if f.willneed != nil && off < len(in.desc.Trailer.Blocks)-1 {
+			next := in.desc.Trailer.Offset
+			if off < len(in.desc.Trailer.Blocks)-2 {
+				next = in.desc.Trailer.Blocks[off+2].Offset
+			}
+			f.willneed(in.mapped, end, next)
+		}
+		_, err := d.CopyBytes(dst, in.mapped[pos:end])
+		return size, err
+	}
+	src, err := fsutil.OpenRange(f.fs, in.desc.Path, in.desc.ETag, pos, end-pos)
+	if err != nil {
+		return 0, err
+	}
+	// closing src interrupts a read that
+	// is blocked when ctx is canceled
+	stop := context.AfterFunc(ctx, func() { src.Close() })
+	_, err = d.Copy(dst, src)
+	if stop() {
+		src.Close()
+	}
+	return size, err
+}
+
 var _ CachedTable = &readerTable{}
 
 func (f *readerTable) Hits() int64   { return 0 }
@@ -174,8 +411,42 @@ func (f *readerTable) Bytes() int64 {
 	return f.scanned
 }
 
+// Skipped returns the number of blocks that
+// were not read because they could not match
+// the table's filter.
+func (f *readerTable) Skipped() int64 {
+	return f.skipped
+}
+
+// prune removes the blocks that cannot
+// match f.filter from each input
+func (f *readerTable) prune() {
+	if f.filter == nil || f.filter.Trivial() {
+		return
+	}
+	for i := range f.in {
+		in := &f.in[i]
+		if in.desc.Trailer.Sparse.Blocks() == 0 {
+			continue // no index to evaluate
+		}
+		n := in.blks.Len()
+		in.blks = in.blks.Intersect(f.filter.Intervals(&in.desc.Trailer.Sparse))
+		f.skipped += int64(n - in.blks.Len())
+	}
+}
+
 func (f *readerTable) WriteChunks(dst vm.QuerySink, parallel int) error {
-	return vm.SplitInput(dst, parallel, f.write)
+	return f.WriteChunksContext(context.Background(), dst, parallel)
+}
+
+// WriteChunksContext is like WriteChunks, but it
+// stops reading blocks once ctx is canceled and
+// returns ctx.Err() in that case.
+func (f *readerTable) WriteChunksContext(ctx context.Context, dst vm.QuerySink, parallel int) error {
+	f.prune()
+	return vm.SplitInputDone(ctx, dst, parallel, func(w io.Writer, done <-chan struct{}) error {
+		return f.write(ctx, w, done)
+	})
 }
 
 // ExecParams is a collection of all the
@@ -208,8 +479,47 @@ type ExecParams struct {
 	// This may implement UploadFS, which is
 	// required to enable support for SELECT INTO.
 	FS fs.FS
-
-	get func(i int) *Input
+	// MaxSubqueryRows is the maximum number of rows
+	// that a sub-query (including the inner side of
+	// a join) may produce before the query fails.
+	// If MaxSubqueryRows is zero, then pir.LargeSize
+	// is used instead.
+	MaxSubqueryRows int
+	// MaxJoinRows is the maximum number of rows
+	// that a nested-loop join (a join without an
+	// equality condition) may produce before the
+	// query fails. The limit is encoded with the
+	// query plan, so it applies to each process
+	// that executes a part of the join.
+	// If MaxJoinRows is zero, then DefaultMaxJoinRows
+	// is used instead.
+	MaxJoinRows int64
+	// Budget limits the resources that
+	// the query may consume; exceeding a limit
+	// causes execution to fail with one of
+	// ErrScanBudget, ErrTimeBudget or ErrScratchBudget.
+	//
+	// The Budget is not encoded with the query plan,
+	// so it only limits the work done by this process:
+	// partitions and sub-queries executed by a remote
+	// Transport (including tenant processes) run
+	// without limits, and only the bytes they report
+	// through ExecParams.Stats count towards
+	// MaxBytesScanned.
+	Budget Budget
+	// Programs, if non-nil, is used to share
+	// compiled filter programs between queries
+	// that contain identical WHERE clauses.
+	Programs *vm.ProgramCache
+	// Seed determines the values produced by
+	// calls to RANDOM() without an explicit seed.
+	// If Seed is zero, then a seed is chosen
+	// at random each time the query is executed.
+	Seed int64
+
+	get    func(i int) *Input
+	budget *budget
+	random *randomBinder
 }
 
 type multiRewriter struct {
@@ -310,7 +620,63 @@ func (ep *ExecParams) clone() *ExecParams {
 		Rewriter: ep.Rewriter,
 		Runner:   ep.Runner,
 		FS:       ep.FS,
-		get:      ep.get,
+
+		MaxSubqueryRows: ep.MaxSubqueryRows,
+		MaxJoinRows:     ep.MaxJoinRows,
+		Budget:          ep.Budget,
+		Programs:        ep.Programs,
+		Seed:            ep.Seed,
+
+		get:    ep.get,
+		budget: ep.budget,
+		random: ep.random,
+	}
+}
+
+// randomBinder replaces the UNSEEDED_RANDOM(n)
+// placeholders produced by the query planner
+// with RANDOM(seed) calls
+type randomBinder struct {
+	seed uint64
+}
+
+func (r *randomBinder) Walk(e expr.Node) expr.Rewriter { return r }
+
+func (r *randomBinder) Rewrite(e expr.Node) expr.Node {
+	b, ok := e.(*expr.Builtin)
+	if !ok || b.Func != expr.UnseededRandom || len(b.Args) != 1 {
+		return e
+	}
+	n, ok := b.Args[0].(expr.Integer)
+	if !ok {
+		return e
+	}
+	// splitmix64 finalizer, so that adjacent
+	// placeholders get unrelated seeds
+	z := r.seed + (uint64(n)+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return expr.Call(expr.Random, expr.Integer(int64(z)))
+}
+
+// bindRandom arranges for each RANDOM() without a seed
+// to be given one derived from ep.Seed if that is not
+// already done by a parent query; the returned function
+// must be called once the query has been executed
+func (ep *ExecParams) bindRandom() func() {
+	if ep.random != nil {
+		return func() {}
+	}
+	seed := ep.Seed
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	ep.random = &randomBinder{seed: uint64(seed)}
+	ep.AddRewrite(ep.random)
+	return func() {
+		ep.PopRewrite()
+		ep.random = nil
 	}
 }
 
@@ -356,7 +722,10 @@ func (l *LocalTransport) Exec(ep *ExecParams) error {
 	if ep.Parallel == 0 {
 		ep.Parallel = runtime.GOMAXPROCS(0)
 	}
-	return ep.Plan.exec(s, ep)
+	unbind := ep.bindRandom()
+	defer unbind()
+	done := ep.start()
+	return done(ep.Plan.exec(s, ep))
 }
 
 // Transport models the exection environment
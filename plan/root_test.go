@@ -0,0 +1,790 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package plan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SnellerInc/sneller/date"
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/ints"
+	"github.com/SnellerInc/sneller/ion"
+	"github.com/SnellerInc/sneller/ion/blockfmt"
+	"github.com/SnellerInc/sneller/vm"
+)
+
+// multiBlockInput writes n rows into a
+// zion object with many small blocks
+func multiBlockInput(t *testing.T, n int) (*blockfmt.DirFS, *Input) {
+	var text strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&text, "{\"x\": %d, \"y\": \"row number %d\"}\n", i, i*7919)
+	}
+	return textInput(t, text.String())
+}
+
+// textInput writes JSON text into a
+// zion object with many small blocks
+func textInput(t *testing.T, text string) (*blockfmt.DirFS, *Input) {
+	dfs := blockfmt.NewDirFS(t.TempDir())
+	dfs.MinPartSize = 1
+	up, err := dfs.Create("sample.zion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := strings.NewReader(text)
+	c := blockfmt.Converter{
+		Inputs: []blockfmt.Input{{
+			Size: src.Size(),
+			R:    io.NopCloser(src),
+			F:    blockfmt.MustSuffixToFormat(".json"),
+		}},
+		Output:     up,
+		Comp:       "zion",
+		Align:      1024,
+		FlushMeta:  4 * 1024,
+		TargetSize: 1024,
+	}
+	if err := c.Run(); err != nil {
+		t.Fatal(err)
+	}
+	tr := c.Trailer()
+	return dfs, &Input{
+		Descs: []Descriptor{{
+			Descriptor: blockfmt.Descriptor{
+				ObjectInfo: blockfmt.ObjectInfo{Path: "sample.zion"},
+				Trailer:    *tr,
+			},
+			Blocks: ints.Intervals{{Start: 0, End: len(tr.Blocks)}},
+		}},
+	}
+}
+
+func TestSampleFraction(t *testing.T) {
+	const rowsTotal = 10000
+	dfs, in := multiBlockInput(t, rowsTotal)
+	total := len(in.Descs[0].Trailer.Blocks)
+	if total < 32 {
+		t.Fatalf("only %d blocks", total)
+	}
+
+	// blocks returns the blocks selected by the given seed
+	blocks := func(fraction float64, seed uint64) []int {
+		tbl := readerTable{
+			in: []readerInput{{
+				desc: &in.Descs[0].Descriptor,
+				blks: in.Descs[0].Blocks.Clone(),
+			}},
+			sample: fraction,
+			seed:   seed,
+		}
+		var out []int
+		for {
+			in, off := tbl.next()
+			if in == nil {
+				return out
+			}
+			out = append(out, off)
+		}
+	}
+	// rows returns the number of rows read with the given seed
+	rows := func(fraction float64, seed uint64) int {
+		var buf bytes.Buffer
+		r := FSRunner{FS: dfs, SampleFraction: fraction, SampleSeed: seed, Verify: true}
+		err := r.Run(vm.LockedSink(&buf), in, &ExecParams{Parallel: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rowcount(t, buf.Bytes())
+	}
+
+	if n := len(blocks(0, 1)); n != total {
+		t.Errorf("no sampling: got %d blocks, want %d", n, total)
+	}
+	if n := rows(0, 1); n != rowsTotal {
+		t.Errorf("no sampling: got %d rows, want %d", n, rowsTotal)
+	}
+
+	half := blocks(0.5, 1)
+	if len(half) < total/4 || len(half) > total*3/4 {
+		t.Errorf("0.5 sample read %d of %d blocks", len(half), total)
+	}
+	if again := blocks(0.5, 1); !slices.Equal(half, again) {
+		t.Errorf("same seed selected %v and %v", half, again)
+	}
+	if other := blocks(0.5, 2); slices.Equal(half, other) {
+		t.Errorf("different seeds selected the same blocks %v", half)
+	}
+
+	n := rows(0.5, 1)
+	if n <= 0 || n >= rowsTotal {
+		t.Errorf("0.5 sample read %d rows", n)
+	}
+	if again := rows(0.5, 1); again != n {
+		t.Errorf("same seed read %d and %d rows", n, again)
+	}
+}
+
+func TestReaderTableFilter(t *testing.T) {
+	day := func(d int) ion.Datum {
+		t := date.Date(2021, 01, d, 0, 0, 0, 0)
+		return ion.Timestamp(t)
+	}
+	mkrange := func(a, b int) blockfmt.Range {
+		return blockfmt.NewRange([]string{"timestamp"}, day(a), day(b))
+	}
+
+	// blocks 0 through 3 cover days 1-2, 3-4, 5-6 and 7-8
+	var indexed, unindexed blockfmt.Trailer
+	for i := 0; i < 4; i++ {
+		indexed.Sparse.Push([]blockfmt.Range{mkrange(2*i+1, 2*i+2)})
+	}
+	indexed.Blocks = make([]blockfmt.Blockdesc, 4)
+	unindexed.Blocks = make([]blockfmt.Blockdesc, 2)
+
+	run := func(e string) ([]int, int64) {
+		var f blockfmt.Filter
+		f.Compile(parseExpr(e))
+		tbl := readerTable{
+			in: []readerInput{{
+				desc: &blockfmt.Descriptor{Trailer: indexed},
+				blks: ints.Intervals{{Start: 0, End: 4}},
+			}, {
+				desc: &blockfmt.Descriptor{Trailer: unindexed},
+				blks: ints.Intervals{{Start: 0, End: 2}},
+			}},
+			filter: &f,
+		}
+		tbl.prune()
+		var out []int
+		for {
+			in, off := tbl.next()
+			if in == nil {
+				return out, tbl.Skipped()
+			}
+			if in == &tbl.in[1] {
+				off += 100
+			}
+			out = append(out, off)
+		}
+	}
+
+	tcs := []struct {
+		expr    string
+		blocks  []int
+		skipped int64
+	}{
+		{"timestamp >= `2021-01-05T00:00:00Z`", []int{2, 3, 100, 101}, 2},
+		{"timestamp < `2021-01-03T00:00:00Z`", []int{0, 100, 101}, 3},
+		{"timestamp >= `2021-01-04T00:00:00Z` AND timestamp < `2021-01-06T00:00:00Z`", []int{1, 2, 100, 101}, 2},
+		{"timestamp > `2021-02-01T00:00:00Z`", []int{100, 101}, 4},
+		// not a usable predicate: nothing is skipped
+		{"x = 1", []int{0, 1, 2, 3, 100, 101}, 0},
+	}
+	for i := range tcs {
+		blocks, skipped := run(tcs[i].expr)
+		if !slices.Equal(blocks, tcs[i].blocks) || skipped != tcs[i].skipped {
+			t.Errorf("%s: got blocks %v (%d skipped), want %v (%d skipped)",
+				tcs[i].expr, blocks, skipped, tcs[i].blocks, tcs[i].skipped)
+		}
+	}
+}
+
+// cancelSink cancels a context once
+// a number of chunks have been written
+type cancelSink struct {
+	writes, after int64
+	cancel        func()
+}
+
+func (c *cancelSink) Open() (io.WriteCloser, error) { return c, nil }
+func (c *cancelSink) Close() error                  { return nil }
+
+func (c *cancelSink) Write(p []byte) (int, error) {
+	if atomic.AddInt64(&c.writes, 1) == c.after {
+		c.cancel()
+	}
+	return len(p), nil
+}
+
+// blockingFS returns readers that block
+// until they are closed
+type blockingFS struct {
+	fs.FS
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (b *blockingFS) OpenRange(name, etag string, off, width int64) (io.ReadCloser, error) {
+	return b, nil
+}
+
+func (b *blockingFS) Read(p []byte) (int, error) {
+	<-b.closed
+	return 0, fs.ErrClosed
+}
+
+func (b *blockingFS) Close() error {
+	b.once.Do(func() { close(b.closed) })
+	return nil
+}
+
+// countingFS counts the number of
+// readers that are open at once
+type countingFS struct {
+	fs.FS
+	open, peak int64
+}
+
+func (c *countingFS) OpenRange(name, etag string, off, width int64) (io.ReadCloser, error) {
+	n := atomic.AddInt64(&c.open, 1)
+	for {
+		peak := atomic.LoadInt64(&c.peak)
+		if n <= peak || atomic.CompareAndSwapInt64(&c.peak, peak, n) {
+			break
+		}
+	}
+	f, err := c.FS.Open(name)
+	if err != nil {
+		atomic.AddInt64(&c.open, -1)
+		return nil, err
+	}
+	// hold the reader open for long enough
+	// that concurrent reads overlap
+	time.Sleep(time.Millisecond)
+	return &countingReader{Reader: io.NewSectionReader(f.(io.ReaderAt), off, width), f: f, fs: c}, nil
+}
+
+type countingReader struct {
+	io.Reader
+	f    fs.File
+	fs   *countingFS
+	once sync.Once
+}
+
+func (c *countingReader) Close() error {
+	c.once.Do(func() { atomic.AddInt64(&c.fs.open, -1) })
+	return c.f.Close()
+}
+
+func TestMaxReads(t *testing.T) {
+	const sources, rowsEach = 4, 2000
+	dfs, in := multiBlockInput(t, rowsEach)
+	var wg sync.WaitGroup
+	for _, limit := range []int{1, 3} {
+		fsys := &countingFS{FS: dfs}
+		// each call to Run requests more
+		// parallelism than the shared limit
+		r := &FSRunner{FS: fsys, MaxReads: limit}
+		rows := make([]int, sources)
+		errs := make([]error, sources)
+		for i := 0; i < sources; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				var buf bytes.Buffer
+				errs[i] = r.Run(vm.LockedSink(&buf), in, &ExecParams{Parallel: 4})
+				rows[i] = rowcount(t, buf.Bytes())
+			}(i)
+		}
+		wg.Wait()
+		for i := range errs {
+			if errs[i] != nil {
+				t.Fatalf("limit=%d: source %d: %s", limit, i, errs[i])
+			}
+			if rows[i] != rowsEach {
+				t.Errorf("limit=%d: source %d: got %d rows", limit, i, rows[i])
+			}
+		}
+		if fsys.peak > int64(limit) {
+			t.Errorf("limit=%d: %d concurrent reads", limit, fsys.peak)
+		}
+	}
+}
+
+func TestWriteChunksContext(t *testing.T) {
+	dfs, in := multiBlockInput(t, 10000)
+	trailer := &in.Descs[0].Trailer
+	chunks, maxchunks := 0, 0
+	for i := range trailer.Blocks {
+		chunks += trailer.Blocks[i].Chunks
+		maxchunks = max(maxchunks, trailer.Blocks[i].Chunks)
+	}
+	table := func(fsys fs.FS) *readerTable {
+		return &readerTable{
+			fs: fsys,
+			in: []readerInput{{
+				desc: &in.Descs[0].Descriptor,
+				blks: in.Descs[0].Blocks.Clone(),
+			}},
+		}
+	}
+
+	for _, parallel := range []int{1, 4} {
+		ctx, cancel := context.WithCancel(context.Background())
+		sink := &cancelSink{after: 2, cancel: cancel}
+		err := table(dfs).WriteChunksContext(ctx, sink, parallel)
+		cancel()
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("parallel=%d: got error %v", parallel, err)
+		}
+		// each goroutine stops after the block
+		// it was reading when the scan was canceled
+		if sink.writes > int64(2+parallel*maxchunks) {
+			t.Errorf("parallel=%d: %d of %d chunks written", parallel, sink.writes, chunks)
+		}
+	}
+
+	// a read that is blocked when the context
+	// is canceled is interrupted by closing the reader
+	bfs := &blockingFS{FS: dfs, closed: make(chan struct{})}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- table(bfs).WriteChunksContext(ctx, &cancelSink{}, 1)
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("scan not interrupted")
+	}
+	select {
+	case <-bfs.closed:
+	default:
+		t.Error("reader not closed")
+	}
+}
+
+func TestWriteChunksLimit(t *testing.T) {
+	dfs, in := multiBlockInput(t, 10000)
+	trailer := &in.Descs[0].Trailer
+	total := int64(0)
+	for i := range trailer.Blocks {
+		total += int64(trailer.Blocks[i].Chunks) << trailer.BlockShift
+	}
+	for _, parallel := range []int{1, 4} {
+		var buf bytes.Buffer
+		r := FSRunner{FS: dfs}
+		ep := &ExecParams{Parallel: parallel}
+		err := r.Run(vm.NewLimit(100, vm.LockedSink(&buf)), in, ep)
+		if err != nil {
+			t.Fatalf("parallel=%d: %s", parallel, err)
+		}
+		if n := rowcount(t, buf.Bytes()); n != 100 {
+			t.Errorf("parallel=%d: got %d rows", parallel, n)
+		}
+		// once the limit is reached, the remaining
+		// goroutines stop before reading another block
+		if ep.Stats.BytesScanned >= total {
+			t.Errorf("parallel=%d: scanned %d of %d bytes", parallel, ep.Stats.BytesScanned, total)
+		}
+	}
+}
+
+func TestBudget(t *testing.T) {
+	dfs, in := multiBlockInput(t, 10000)
+	trailer := &in.Descs[0].Trailer
+	total := int64(0)
+	for i := range trailer.Blocks {
+		total += int64(trailer.Blocks[i].Chunks) << trailer.BlockShift
+	}
+	scan := &Node{
+		Op:    &Leaf{Orig: &expr.Table{Binding: expr.Bind(expr.Ident("sample"), "")}},
+		Input: 0,
+	}
+	run := func(fsys fs.FS, root *Node, b Budget) (*ExecParams, error) {
+		ep := &ExecParams{
+			Plan:     &Tree{Inputs: []*Input{in}, Root: *root},
+			Output:   io.Discard,
+			Parallel: 4,
+			Runner:   &FSRunner{FS: fsys},
+			Budget:   b,
+		}
+		return ep, Exec(ep)
+	}
+
+	ep, err := run(dfs, scan, Budget{MaxBytesScanned: total})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ep.Stats.BytesScanned != total {
+		t.Errorf("scanned %d of %d bytes", ep.Stats.BytesScanned, total)
+	}
+	ep, err = run(dfs, scan, Budget{MaxBytesScanned: total / 4})
+	if !errors.Is(err, ErrScanBudget) {
+		t.Fatalf("expected scan budget error; got %v", err)
+	}
+	// each goroutine stops after the block
+	// that exceeded the budget
+	if ep.Stats.BytesScanned >= total {
+		t.Errorf("scanned %d of %d bytes", ep.Stats.BytesScanned, total)
+	}
+
+	// sub-query results count against the scratch budget
+	sub := &Node{
+		Op: &Substitute{
+			Nonterminal: Nonterminal{From: NoOutput{}},
+			Inner:       []*Node{scan},
+		},
+	}
+	_, err = run(dfs, sub, Budget{MaxScratchBytes: 1024})
+	if !errors.Is(err, ErrScratchBudget) {
+		t.Fatalf("expected scratch budget error; got %v", err)
+	}
+
+	// a scan that is blocked when the
+	// deadline passes is interrupted
+	bfs := &blockingFS{FS: dfs, closed: make(chan struct{})}
+	done := make(chan error, 1)
+	go func() {
+		_, err := run(bfs, scan, Budget{MaxDuration: 10 * time.Millisecond})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrTimeBudget) {
+			t.Errorf("expected time budget error; got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("query not interrupted")
+	}
+
+	// the budget is enforced afresh each
+	// time the same ExecParams is executed
+	ep, err = run(dfs, scan, Budget{MaxBytesScanned: total})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep.Stats = ExecStats{}
+	if err := Exec(ep); err != nil {
+		t.Fatalf("second Exec: %s", err)
+	}
+	ep.Runner = &FSRunner{FS: &blockingFS{FS: dfs, closed: make(chan struct{})}}
+	ep.Budget = Budget{MaxDuration: 10 * time.Millisecond}
+	go func() {
+		done <- Exec(ep)
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrTimeBudget) {
+			t.Errorf("expected time budget error; got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second query not interrupted")
+	}
+
+	// the caller's context is restored
+	// exactly once the budget is lifted,
+	// including when it was nil
+	type ctxkey struct{}
+	for _, orig := range []context.Context{
+		nil, context.WithValue(context.Background(), ctxkey{}, true),
+	} {
+		ep := &ExecParams{Context: orig, Budget: Budget{MaxDuration: time.Minute}}
+		done := ep.start()
+		if ep.Context == nil || ep.Context == orig {
+			t.Fatal("start did not install a deadline")
+		}
+		done(nil)
+		if ep.Context != orig {
+			t.Errorf("ep.Context is %v after the query; want %v", ep.Context, orig)
+		}
+	}
+}
+
+func TestConcatTable(t *testing.T) {
+	var text0, text1 strings.Builder
+	var want []string
+	for i := 0; i < 3000; i++ {
+		fmt.Fprintf(&text0, "{\"x\": %d, \"y\": \"first %d\"}\n", i, i)
+		want = append(want, fmt.Sprintf(`{"x": %d, "y": "first %d"}`, i, i))
+	}
+	// the second table has a different
+	// set of symbols from the first
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&text1, "{\"z\": \"second\", \"y\": %d}\n", i)
+		want = append(want, fmt.Sprintf(`{"z": "second", "y": %d}`, i))
+	}
+	slices.Sort(want)
+	fs0, in0 := textInput(t, text0.String())
+	fs1, in1 := textInput(t, text1.String())
+	table := func(fsys fs.FS, in *Input) *readerTable {
+		return &readerTable{
+			fs: fsys,
+			in: []readerInput{{
+				desc: &in.Descs[0].Descriptor,
+				blks: in.Descs[0].Blocks.Clone(),
+			}},
+		}
+	}
+	for _, parallel := range []int{1, 4} {
+		var buf bytes.Buffer
+		ct := vm.NewConcatTable(table(fs0, in0), table(fs1, in1))
+		err := ct.WriteChunks(vm.LockedSink(&buf), parallel)
+		if err != nil {
+			t.Fatalf("parallel=%d: %s", parallel, err)
+		}
+		var got []string
+		var st ion.Symtab
+		rest := buf.Bytes()
+		for len(rest) > 0 {
+			var d ion.Datum
+			d, rest, err = ion.ReadDatum(&st, rest)
+			if err != nil {
+				t.Fatalf("parallel=%d: %s", parallel, err)
+			}
+			if d.IsEmpty() || d.IsNull() {
+				continue // symbol table or nop pad
+			}
+			got = append(got, strings.TrimSpace(toJSON(&st, d)))
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("parallel=%d: got %d rows, want %d", parallel, len(got), len(want))
+		}
+	}
+}
+
+func TestOnBlock(t *testing.T) {
+	const rowsTotal = 5000
+	dfs, in := multiBlockInput(t, rowsTotal)
+	blocks := len(in.Descs[0].Trailer.Blocks)
+	// two descriptors, so that the second
+	// one has block numbers after the first
+	in.Descs = append(in.Descs, in.Descs[0])
+	var lock sync.Mutex
+	seen := make(map[int]int)
+	total := int64(0)
+	r := FSRunner{
+		FS: dfs,
+		OnBlock: func(block int, rows int64) {
+			lock.Lock()
+			defer lock.Unlock()
+			seen[block]++
+			total += rows
+		},
+	}
+	var buf bytes.Buffer
+	err := r.Run(vm.LockedSink(&buf), in, &ExecParams{Parallel: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := rowcount(t, buf.Bytes()); int64(n) != total || n != 2*rowsTotal {
+		t.Errorf("callbacks counted %d rows; output has %d", total, n)
+	}
+	if len(seen) != 2*blocks {
+		t.Errorf("got callbacks for %d of %d blocks", len(seen), 2*blocks)
+	}
+	for block, n := range seen {
+		if block < 0 || block >= 2*blocks || n != 1 {
+			t.Errorf("block %d: %d callbacks", block, n)
+		}
+	}
+}
+
+// zionSink wraps the writers returned from
+// a QuerySink to count successful calls to
+// ConfigureZion
+type zionSink struct {
+	vm.QuerySink
+	configured int64
+}
+
+type zionSinkWriter struct {
+	io.WriteCloser
+	parent *zionSink
+}
+
+func (z *zionSink) Open() (io.WriteCloser, error) {
+	w, err := z.QuerySink.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &zionSinkWriter{WriteCloser: w, parent: z}, nil
+}
+
+func (z *zionSinkWriter) ConfigureZion(blocksize int64, fields []string) bool {
+	zw, ok := z.WriteCloser.(blockfmt.ZionWriter)
+	if ok && zw.ConfigureZion(blocksize, fields) {
+		atomic.AddInt64(&z.parent.configured, 1)
+		return true
+	}
+	return false
+}
+
+// TestOnBlockZion tests that OnBlock does not
+// prevent zion data from being passed directly to
+// the output when the trailer records row counts
+func TestOnBlockZion(t *testing.T) {
+	const rowsTotal = 5000
+	dfs, in := multiBlockInput(t, rowsTotal)
+	if _, ok := in.Descs[0].Trailer.TotalRows(); !ok {
+		t.Fatal("trailer does not record row counts")
+	}
+	// zion data is only passed through
+	// when the set of fields is known
+	in.Fields = []string{"x"}
+	total := int64(0)
+	r := FSRunner{
+		FS: dfs,
+		OnBlock: func(block int, rows int64) {
+			atomic.AddInt64(&total, rows)
+		},
+	}
+	var buf bytes.Buffer
+	filt, err := vm.NewFilter(expr.Compare(expr.GreaterEquals, expr.Ident("x"), expr.Integer(0)), vm.LockedSink(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := &zionSink{QuerySink: filt}
+	err = r.Run(dst, in, &ExecParams{Parallel: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst.configured == 0 {
+		t.Error("zion data was never passed to the output")
+	}
+	if total != rowsTotal {
+		t.Errorf("callbacks counted %d rows, want %d", total, rowsTotal)
+	}
+	if n := rowcount(t, buf.Bytes()); n != rowsTotal {
+		t.Errorf("output has %d rows, want %d", n, rowsTotal)
+	}
+}
+
+func TestMetadataCount(t *testing.T) {
+	const rowsTotal = 10000
+	dfs, in := multiBlockInput(t, rowsTotal)
+	count := func(in *Input, r *FSRunner) (int, ExecStats) {
+		var buf bytes.Buffer
+		op := &SimpleAggregate{
+			Nonterminal: Nonterminal{From: &Leaf{}},
+			Outputs:     vm.Aggregation{{Expr: expr.Count(expr.Star{}), Result: "count"}},
+		}
+		ep := &ExecParams{Parallel: 1, Runner: r}
+		err := op.exec(vm.LockedSink(&buf), in, ep)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var st ion.Symtab
+		d, _, err := ion.ReadDatum(&st, buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := d.Struct()
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, ok := s.FieldByName("count")
+		if !ok {
+			t.Fatal("no count field")
+		}
+		n, err := f.Int()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return int(n), ep.Stats
+	}
+	fast, stats := count(in, &FSRunner{FS: dfs})
+	if scanned := stats.BytesScanned; scanned != 0 {
+		t.Errorf("fast path scanned %d bytes", scanned)
+	}
+
+	// without row counts, the blocks are scanned
+	slow := Input{Descs: slices.Clone(in.Descs)}
+	slow.Descs[0].Trailer.Blocks = slices.Clone(in.Descs[0].Trailer.Blocks)
+	slow.Descs[0].Trailer.Blocks[0].Rows = 0
+	full, stats := count(&slow, &FSRunner{FS: dfs})
+	if stats.BytesScanned == 0 {
+		t.Error("full scan did not scan any bytes")
+	}
+	if fast != rowsTotal || full != rowsTotal {
+		t.Errorf("fast path counted %d rows and full scan %d; want %d", fast, full, rowsTotal)
+	}
+
+	// the fast path is not taken when
+	// the runner would skip blocks
+	var text strings.Builder
+	for i := 0; i < rowsTotal; i++ {
+		fmt.Fprintf(&text, "{\"x\": %d, \"t\": \"2021-01-%02dT00:00:00Z\"}\n", i, 1+i*30/rowsTotal)
+	}
+	dfs, in = textInput(t, text.String())
+	var filt blockfmt.Filter
+	filt.Compile(parseExpr("t >= `2021-01-16T00:00:00Z`"))
+	runners := map[string]*FSRunner{
+		"sample": {FS: dfs, SampleFraction: 0.5, SampleSeed: 1},
+		"filter": {FS: dfs, Filter: &filt},
+	}
+	for name, r := range runners {
+		n, stats := count(in, r)
+		if stats.BytesScanned == 0 {
+			t.Errorf("%s: no bytes scanned", name)
+		}
+		if n == 0 || n >= rowsTotal {
+			t.Errorf("%s: counted %d of %d rows", name, n, rowsTotal)
+		}
+	}
+
+	// skipped blocks are reported for each query
+	// rather than accumulated by the runner
+	r := runners["filter"]
+	_, first := count(in, r)
+	_, second := count(in, r)
+	if first.BlocksSkipped == 0 || second.BlocksSkipped != first.BlocksSkipped {
+		t.Errorf("skipped %d blocks and then %d", first.BlocksSkipped, second.BlocksSkipped)
+	}
+	if _, stats := count(in, runners["sample"]); stats.BlocksSkipped != 0 {
+		t.Errorf("sampling reported %d blocks skipped by a filter", stats.BlocksSkipped)
+	}
+}
+
+func TestFilterProgramCache(t *testing.T) {
+	const rowsTotal = 1000
+	dfs, in := multiBlockInput(t, rowsTotal)
+	var progs vm.ProgramCache
+	for i := 0; i < 3; i++ {
+		var buf bytes.Buffer
+		op := &Filter{
+			Nonterminal: Nonterminal{From: &Leaf{}},
+			Expr:        parseExpr("x < 100"),
+		}
+		ep := &ExecParams{Parallel: 4, Runner: &FSRunner{FS: dfs}, Programs: &progs}
+		err := op.exec(vm.LockedSink(&buf), in, ep)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := rowcount(t, buf.Bytes()); n != 100 {
+			t.Errorf("query %d: got %d rows, want 100", i, n)
+		}
+	}
+	if n := progs.Len(); n != 1 {
+		t.Errorf("%d programs cached, want 1", n)
+	}
+}
@@ -38,6 +38,11 @@ type ExecStats struct {
 	// BytesScanned is the number
 	// of bytes scanned.
 	BytesScanned int64
+	// BlocksSkipped is the number of blocks
+	// that were not read because the sparse
+	// index showed that they could not match
+	// FSRunner.Filter.
+	BlocksSkipped int64
 }
 
 // CachedTable is an interface optionally
@@ -57,6 +62,7 @@ func (e *ExecStats) atomicAdd(tmp *ExecStats) {
 	atomic.AddInt64(&e.CacheHits, tmp.CacheHits)
 	atomic.AddInt64(&e.CacheMisses, tmp.CacheMisses)
 	atomic.AddInt64(&e.BytesScanned, tmp.BytesScanned)
+	atomic.AddInt64(&e.BlocksSkipped, tmp.BlocksSkipped)
 }
 
 func (e *ExecStats) Observe(table vm.Table) {
@@ -92,6 +98,10 @@ func (e *ExecStats) Encode(dst *ion.Buffer, st *ion.Symtab) {
 		dst.BeginField(st.Intern("scanned"))
 		dst.WriteInt(e.BytesScanned)
 	}
+	if e.BlocksSkipped != 0 {
+		dst.BeginField(st.Intern("skipped"))
+		dst.WriteInt(e.BlocksSkipped)
+	}
 	dst.EndStruct()
 }
 
@@ -105,6 +115,8 @@ func (e *ExecStats) Decode(buf []byte, st *ion.Symtab) error {
 			e.CacheMisses, _, err = ion.ReadInt(body)
 		case "scanned":
 			e.BytesScanned, _, err = ion.ReadInt(body)
+		case "skipped":
+			e.BlocksSkipped, _, err = ion.ReadInt(body)
 		default:
 			return errUnexpectedField
 		}
@@ -132,6 +144,7 @@ func init() {
 		"hits",
 		"misses",
 		"scanned",
+		"skipped",
 	} {
 		statsSymtab.Intern(s)
 	}
@@ -29,6 +29,17 @@ type replacement struct {
 	lock sync.Mutex
 
 	rows []ion.Struct
+	// limit, if non-zero, is the number of
+	// rows after which the replacement stops
+	// accepting more rows
+	limit int
+	// max, if non-zero, is the number of rows
+	// beyond which the replacement fails;
+	// otherwise pir.LargeSize is used
+	max int
+	// budget, if non-nil, limits the
+	// number of bytes that may be buffered
+	budget *budget
 }
 
 func mustConst(d ion.Datum) expr.Constant {
@@ -279,7 +290,18 @@ type subreplacement struct {
 	tmp    []ion.Struct
 }
 
+// done returns true if the parent
+// replacement does not need more rows
+func (s *subreplacement) done() bool {
+	s.parent.lock.Lock()
+	defer s.parent.lock.Unlock()
+	return s.parent.limit > 0 && len(s.parent.rows) >= s.parent.limit
+}
+
 func (s *subreplacement) Write(buf []byte) (int, error) {
+	if s.done() {
+		return 0, io.EOF
+	}
 	buf = slices.Clone(buf)
 	orig := len(buf)
 	s.tmp = s.tmp[:0]
@@ -296,11 +318,23 @@ func (s *subreplacement) Write(buf []byte) (int, error) {
 		st, _ := d.Struct()
 		s.tmp = append(s.tmp, st)
 	}
+	if err := s.parent.budget.buffer(int64(orig)); err != nil {
+		return 0, err
+	}
 	s.parent.lock.Lock()
 	defer s.parent.lock.Unlock()
 	s.parent.rows = append(s.parent.rows, s.tmp...)
 	s.tmp = s.tmp[:0]
-	if len(s.parent.rows) > pir.LargeSize {
+	if lim := s.parent.limit; lim > 0 && len(s.parent.rows) >= lim {
+		// signal that the sub-query can stop
+		s.parent.rows = s.parent.rows[:lim]
+		return orig, io.EOF
+	}
+	max := s.parent.max
+	if max <= 0 {
+		max = pir.LargeSize
+	}
+	if len(s.parent.rows) > max {
 		return orig, fmt.Errorf("%d items in subreplacement exceeds limit", len(s.parent.rows))
 	}
 	return orig, nil
@@ -354,10 +388,8 @@ func (r *replacer) Rewrite(e expr.Node) expr.Node {
 		return r.inputs[id].toList()
 	case expr.InReplacement:
 		id := int(b.Args[1].(expr.Integer))
-		return &expr.Member{
-			Arg: b.Args[0],
-			Set: r.inputs[id].toScalarList(),
-		}
+		set := r.inputs[id].toScalarList()
+		return expr.InSet(b.Args[0], &set)
 	case expr.HashReplacement:
 		id := int(b.Args[0].(expr.Integer))
 		kind := string(b.Args[1].(expr.String))
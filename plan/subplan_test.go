@@ -0,0 +1,146 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package plan
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/expr/partiql"
+	"github.com/SnellerInc/sneller/vm"
+)
+
+func TestExistsReplacement(t *testing.T) {
+	env := &testenv{t: t}
+	q, err := partiql.Parse([]byte(`SELECT EXISTS(SELECT * FROM parking WHERE Fine > 100) AS e FROM nyc_taxi LIMIT 1`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := New(q, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sub *Substitute
+	for op := tree.Root.Op; op != nil; op = op.input() {
+		if s, ok := op.(*Substitute); ok {
+			sub = s
+		}
+	}
+	if sub == nil {
+		t.Fatalf("no substitution in plan:\n%s", tree.String())
+	}
+	if len(sub.Exists) != 1 || !sub.Exists[0] {
+		t.Fatalf("Exists = %v", sub.Exists)
+	}
+	testPlanSerialize(t, tree)
+
+	// a scalar sub-query that is merely compared
+	// with MISSING must still produce all of its rows,
+	// so that it fails if it produces more than one
+	q, err = partiql.Parse([]byte(`SELECT (SELECT MAX(Fine) FROM parking) IS NOT MISSING AS e FROM nyc_taxi LIMIT 1`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err = New(q, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub = nil
+	for op := tree.Root.Op; op != nil; op = op.input() {
+		if s, ok := op.(*Substitute); ok {
+			sub = s
+		}
+	}
+	if sub == nil {
+		t.Fatalf("no substitution in plan:\n%s", tree.String())
+	}
+	if len(sub.Exists) > 0 && sub.Exists[0] {
+		t.Fatalf("Exists = %v for a scalar sub-query", sub.Exists)
+	}
+
+	// a sub-query that produces many rows
+	// stops once the first one has been written
+	dfs, in := multiBlockInput(t, 10000)
+	trailer := &in.Descs[0].Trailer
+	total := int64(0)
+	for i := range trailer.Blocks {
+		total += int64(trailer.Blocks[i].Chunks) << trailer.BlockShift
+	}
+	sub = &Substitute{
+		Nonterminal: Nonterminal{From: NoOutput{}},
+		Inner: []*Node{{
+			Op:    &Leaf{Orig: &expr.Table{Binding: expr.Bind(expr.Ident("sample"), "")}},
+			Input: 0,
+		}},
+		Exists: []bool{true},
+	}
+	ep := &ExecParams{
+		Parallel: 4,
+		Runner:   &FSRunner{FS: dfs},
+	}
+	ep.get = func(int) *Input { return in }
+	rp := &replacement{limit: 1}
+	err = sub.Inner[0].exec(rp, ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rp.rows) != 1 {
+		t.Errorf("got %d rows", len(rp.rows))
+	}
+	if ep.Stats.BytesScanned >= total {
+		t.Errorf("scanned %d of %d bytes", ep.Stats.BytesScanned, total)
+	}
+	// executing the whole Substitute should succeed
+	ep = &ExecParams{
+		Parallel: 4,
+		Runner:   &FSRunner{FS: dfs},
+	}
+	ep.get = func(int) *Input { return in }
+	if err := sub.exec(vm.LockedSink(io.Discard), nil, ep); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaxSubqueryRows(t *testing.T) {
+	dfs, in := multiBlockInput(t, 100)
+	sub := &Substitute{
+		Nonterminal: Nonterminal{From: NoOutput{}},
+		Inner: []*Node{{
+			Op:    &Leaf{Orig: &expr.Table{Binding: expr.Bind(expr.Ident("sample"), "")}},
+			Input: 0,
+		}},
+	}
+	run := func(max int) error {
+		ep := &ExecParams{
+			Parallel:        4,
+			Runner:          &FSRunner{FS: dfs},
+			MaxSubqueryRows: max,
+		}
+		ep.get = func(int) *Input { return in }
+		return sub.exec(vm.LockedSink(io.Discard), nil, ep)
+	}
+	if err := run(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(100); err != nil {
+		t.Fatal(err)
+	}
+	err := run(10)
+	if err == nil || !strings.Contains(err.Error(), "exceeds limit") {
+		t.Fatalf("expected limit error with 10 rows; got %v", err)
+	}
+}
@@ -17,6 +17,7 @@ package plan
 import (
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 
@@ -132,6 +133,12 @@ type Substitute struct {
 	// is important, as each Inner node i is used to substitute
 	// results into the *REPLACEMENT(i) expressions.
 	Inner []*Node
+	// Exists, if non-nil, indicates which of
+	// the Inner nodes are only used to determine
+	// whether or not they produce any rows.
+	// The execution of those nodes stops
+	// once they have produced one row.
+	Exists []bool
 }
 
 func (s *Substitute) exec(dst vm.QuerySink, src *Input, ep *ExecParams) error {
@@ -140,10 +147,19 @@ func (s *Substitute) exec(dst vm.QuerySink, src *Input, ep *ExecParams) error {
 	wg.Add(len(s.Inner))
 	errlist := make([]error, len(s.Inner))
 	for i := range s.Inner {
+		rp[i].max = ep.MaxSubqueryRows
+		rp[i].budget = ep.budget
+		if i < len(s.Exists) && s.Exists[i] {
+			rp[i].limit = 1
+		}
 		subex := ep.clone()
 		go func(i int) {
 			defer wg.Done()
-			errlist[i] = s.Inner[i].exec(&rp[i], subex)
+			err := s.Inner[i].exec(&rp[i], subex)
+			if rp[i].limit > 0 && errors.Is(err, io.EOF) {
+				err = nil // stopped early
+			}
+			errlist[i] = err
 			ep.Stats.atomicAdd(&subex.Stats)
 		}(i)
 	}
@@ -167,6 +183,14 @@ func (s *Substitute) encode(dst *ion.Buffer, st *ion.Symtab, ep *ExecParams) err
 		}
 	}
 	dst.EndList()
+	if s.Exists != nil {
+		dst.BeginField(st.Intern("exists"))
+		dst.BeginList(-1)
+		for i := range s.Exists {
+			dst.WriteBool(s.Exists[i])
+		}
+		dst.EndList()
+	}
 	dst.EndStruct()
 	return nil
 }
@@ -183,6 +207,15 @@ func (s *Substitute) SetField(f ion.Field) error {
 			s.Inner = append(s.Inner, nn)
 			return nil
 		})
+	case "exists":
+		return f.UnpackList(func(v ion.Datum) error {
+			b, err := v.Bool()
+			if err != nil {
+				return err
+			}
+			s.Exists = append(s.Exists, b)
+			return nil
+		})
 	default:
 		return errUnexpectedField
 	}
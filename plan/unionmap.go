@@ -336,6 +336,98 @@ func (u *UnionPartition) exec(dst vm.QuerySink, src *Input, ep *ExecParams) erro
 	return err
 }
 
+// UnionBuckets is an op that executes its
+// input once for each of Buckets hash buckets
+// and yields the union of the results.
+//
+// Only one bucket is executed at a time,
+// so the replacements within the input
+// only have to hold one bucket of rows.
+type UnionBuckets struct {
+	Nonterminal
+	Buckets int
+}
+
+// bucketValue implements expr.Rewriter in order
+// to rewrite BUCKET_VALUE() expressions into
+// the number of the current bucket.
+type bucketValue int
+
+func (b bucketValue) Walk(e expr.Node) expr.Rewriter {
+	return b
+}
+
+func (b bucketValue) Rewrite(e expr.Node) expr.Node {
+	bi, ok := e.(*expr.Builtin)
+	if !ok || bi.Func != expr.BucketValue {
+		return e
+	}
+	return expr.Integer(b)
+}
+
+func (u *UnionBuckets) encode(dst *ion.Buffer, st *ion.Symtab, ep *ExecParams) error {
+	dst.BeginStruct(-1)
+	settype("union_buckets", dst, st)
+	dst.BeginField(st.Intern("buckets"))
+	dst.WriteInt(int64(u.Buckets))
+	dst.EndStruct()
+	return nil
+}
+
+func (u *UnionBuckets) SetField(f ion.Field) error {
+	switch f.Label {
+	case "buckets":
+		n, err := f.Int()
+		if err != nil {
+			return err
+		}
+		u.Buckets = int(n)
+	default:
+		return errUnexpectedField
+	}
+	return nil
+}
+
+func (u *UnionBuckets) String() string {
+	return fmt.Sprintf("UNION BUCKETS %d", u.Buckets)
+}
+
+func (u *UnionBuckets) exec(dst vm.QuerySink, src *Input, ep *ExecParams) error {
+	if u.Buckets <= 0 {
+		return fmt.Errorf("plan: UnionBuckets: %d buckets to split?", u.Buckets)
+	}
+	var err error
+	for i := 0; i < u.Buckets; i++ {
+		var mw *openSink
+		mw, err = newOpenSink(dst, ep.Parallel)
+		if err != nil {
+			if i == 0 {
+				return err
+			}
+			break
+		}
+		subep := ep.clone()
+		subep.Parallel = mw.Len()
+		subep.Output = nil
+		// stack the BUCKET_VALUE() rewrite
+		subep.AddRewrite(bucketValue(i))
+		err = u.From.exec(mw, src, subep)
+		ep.Stats.atomicAdd(&subep.Stats)
+		if err != nil {
+			break
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		// the output doesn't want any more rows
+		err = nil
+	}
+	err2 := dst.Close()
+	if err == nil {
+		err = err2
+	}
+	return err
+}
+
 // produce a histogram with a sum equal to value
 // with each element proportional to h[i]
 func distribute(h []tablePart, value int) []int {
@@ -19,6 +19,7 @@ import (
 
 	"github.com/SnellerInc/sneller/expr"
 	"github.com/SnellerInc/sneller/ion"
+	"github.com/SnellerInc/sneller/plan/pir"
 	"github.com/SnellerInc/sneller/vm"
 )
 
@@ -29,15 +30,68 @@ type Unnest struct {
 	Nonterminal // source op
 	Expr        expr.Node
 	Result      string
+	// Outer is set if rows for which Expr
+	// has no elements are preserved
+	Outer bool
+	// MaxRows, if positive, is the maximum number
+	// of rows that the op may produce before it fails.
+	// Otherwise, a nested-loop join (an Unnest of a
+	// lookup with a constant key) is limited to
+	// ExecParams.MaxJoinRows rows.
+	MaxRows int64
+}
+
+// DefaultMaxJoinRows is the number of rows
+// a nested-loop join may produce when
+// ExecParams.MaxJoinRows is not set, which
+// is enough to join two sides of
+// pir.LargeSize rows each.
+const DefaultMaxJoinRows = pir.LargeSize * pir.LargeSize
+
+// nestedLoop returns true if e is the
+// rewritten join list of a nested-loop join,
+// in which every row of the inner side is
+// produced for every row of the outer side
+func nestedLoop(e expr.Node) bool {
+	l, ok := e.(*expr.Lookup)
+	if !ok {
+		return false
+	}
+	_, ok = l.Expr.(expr.Constant)
+	return ok
+}
+
+// maxRows returns the row limit for u
+// given its rewritten expression e
+func (u *Unnest) maxRows(e expr.Node, ep *ExecParams) int64 {
+	if u.MaxRows > 0 {
+		return u.MaxRows
+	}
+	if !nestedLoop(e) {
+		return 0
+	}
+	if ep.MaxJoinRows > 0 {
+		return ep.MaxJoinRows
+	}
+	return DefaultMaxJoinRows
 }
 
 func (u *Unnest) encode(dst *ion.Buffer, st *ion.Symtab, ep *ExecParams) error {
 	dst.BeginStruct(-1)
 	settype("unnest", dst, st)
+	e := ep.rewrite(u.Expr)
 	dst.BeginField(st.Intern("expr"))
-	ep.rewrite(u.Expr).Encode(dst, st)
+	e.Encode(dst, st)
 	dst.BeginField(st.Intern("result"))
 	dst.WriteString(u.Result)
+	if u.Outer {
+		dst.BeginField(st.Intern("outer"))
+		dst.WriteBool(true)
+	}
+	if max := u.maxRows(e, ep); max > 0 {
+		dst.BeginField(st.Intern("max_rows"))
+		dst.WriteInt(max)
+	}
 	dst.EndStruct()
 	return nil
 }
@@ -65,6 +119,14 @@ func (u *Unnest) SetField(f ion.Field) error {
 			return err
 		}
 		u.Expr = e
+	case "outer":
+		var err error
+		u.Outer, err = f.Bool()
+		return err
+	case "max_rows":
+		var err error
+		u.MaxRows, err = f.Int()
+		return err
 	default:
 		return errUnexpectedField
 	}
@@ -74,6 +136,9 @@ func (u *Unnest) SetField(f ion.Field) error {
 func (u *Unnest) String() string {
 	var out strings.Builder
 	out.WriteString("UNNEST ")
+	if u.Outer {
+		out.WriteString("OUTER ")
+	}
 	out.WriteString(expr.ToString(u.Expr))
 	out.WriteString(" AS ")
 	out.WriteString(u.Result)
@@ -81,9 +146,12 @@ func (u *Unnest) String() string {
 }
 
 func (u *Unnest) exec(dst vm.QuerySink, src *Input, ep *ExecParams) error {
-	op, err := vm.NewUnnest(dst, ep.rewrite(u.Expr), u.Result)
+	e := ep.rewrite(u.Expr)
+	op, err := vm.NewUnnest(dst, e, u.Result)
 	if err != nil {
 		return err
 	}
+	op.SetOuter(u.Outer)
+	op.SetMaxRows(u.maxRows(e, ep))
 	return u.From.exec(op, src, ep)
 }
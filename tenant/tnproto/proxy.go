@@ -448,10 +448,11 @@ func (s *Server) serveDirect(t *plan.Tree, conn io.WriteCloser, errpipe net.Conn
 	}()
 	pl := plan.LocalTransport{}
 	ep := plan.ExecParams{
-		Plan:    t,
-		Output:  conn,
-		Context: ctx,
-		Runner:  s.Runner,
+		Plan:     t,
+		Output:   conn,
+		Context:  ctx,
+		Runner:   s.Runner,
+		Programs: s.Programs,
 	}
 	if s.InitFS != nil && !t.Data.IsEmpty() {
 		fs, err := s.InitFS(t.Data)
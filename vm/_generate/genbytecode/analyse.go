@@ -649,6 +649,8 @@ type registerContent struct {
 var ignoredMacrosList = []string{
 	"BC_AGGREGATE_SLOT_COUNT_OP",
 	"BC_AGGREGATE_SLOT_MARK_OP",
+	"BC_AGGREGATE_SLOT_NAN_F64_BEGIN",
+	"BC_AGGREGATE_SLOT_NAN_F64_END",
 	"BC_ALLOC_SLICE",
 	"BC_ARITH_OP_F64_IMM_IMPL",
 	"BC_ARITH_OP_F64_IMM_IMPL_K",
@@ -663,6 +665,7 @@ var ignoredMacrosList = []string{
 	"BC_CALC_ADVANCE",
 	"BC_CALC_STRING_TLV_AND_HLEN",
 	"BC_CALC_VALUE_HLEN",
+	"BC_CALL_PORTABLE",
 	"BC_CMP_OP_F64_IMM",
 	"BC_CMP_OP_I64",
 	"BC_CMP_OP_I64_IMM",
@@ -93,6 +93,9 @@ func analyseOpcodes(opcodes []Opcode) error {
 
 		case "makelist", "makestruct": // expected a number
 			return false
+
+		case "arrayposition", "arraycontains": // both branches of #ifdef are analysed
+			return false
 		}
 		return true
 	}
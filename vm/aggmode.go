@@ -0,0 +1,326 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+// Implementation of MODE.
+//
+// The state of MODE is a table of up to modeCapacity
+// distinct values along with the number of times each
+// of them has been seen. Once the table is full, a new
+// value is accounted for with the Misra-Gries algorithm:
+// every count is reduced by the weight of the new value
+// (or by the smallest count, if that is less), entries
+// whose count drops to zero are evicted, and whatever
+// remains of the weight of the new value is stored in
+// one of the freed entries. A value that makes up more
+// than 1/(modeCapacity+1) of the input is guaranteed
+// to be retained, but the result is approximate for
+// inputs with more than modeCapacity distinct values.
+//
+// Numbers and strings of up to modeValueSize bytes
+// are stored inline in the table; longer strings
+// are not counted.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// modeCapacity is the maximum number of distinct
+// values tracked by MODE for each group
+const modeCapacity = 16
+
+// modeValueSize is the size of each value in the
+// state of MODE, and thus the maximum length of
+// a string that MODE can count
+const modeValueSize = 32
+
+// modeDataSize is the size of the state of MODE:
+// modeCapacity values (modeValueSize bytes each),
+// followed by their counts (8 bytes each), their
+// kinds (1 byte each) and their sizes (1 byte each);
+// entries with a zero count are unused
+const modeDataSize = modeCapacity * (modeValueSize + 8 + 1 + 1)
+
+const (
+	modeCountsOffset = modeCapacity * modeValueSize
+	modeKindsOffset  = modeCountsOffset + modeCapacity*8
+	modeSizesOffset  = modeKindsOffset + modeCapacity
+)
+
+const (
+	modeInt    = 0 // the value is an int64
+	modeFloat  = 1 // the value is a non-integral float64
+	modeString = 2 // the value is a string
+)
+
+// modeKey is a normalized value;
+// integral floats are stored as integers
+// and all NaNs are the same value
+type modeKey struct {
+	kind byte
+	size byte // length of a string
+	data [modeValueSize]byte
+}
+
+func modeBitsKey(kind byte, bits uint64) modeKey {
+	k := modeKey{kind: kind}
+	binary.LittleEndian.PutUint64(k.data[:], bits)
+	return k
+}
+
+func modeIntKey(i int64) modeKey {
+	return modeBitsKey(modeInt, uint64(i))
+}
+
+func modeFloatKey(f float64) modeKey {
+	if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+		return modeIntKey(int64(f))
+	}
+	if math.IsNaN(f) {
+		f = math.NaN()
+	}
+	return modeBitsKey(modeFloat, math.Float64bits(f))
+}
+
+// modeStringKey returns the key of str,
+// or false if str is too long to be stored
+func modeStringKey(str []byte) (modeKey, bool) {
+	if len(str) > modeValueSize {
+		return modeKey{}, false
+	}
+	k := modeKey{kind: modeString, size: byte(len(str))}
+	copy(k.data[:], str)
+	return k, true
+}
+
+// modeKeyOf returns the key of the ion value in mem,
+// or false if the value is not a number or a string
+// of at most modeValueSize bytes
+func modeKeyOf(mem []byte) (modeKey, bool) {
+	if len(mem) == 0 {
+		return modeKey{}, false
+	}
+	switch ion.TypeOf(mem) {
+	case ion.IntType:
+		i, _, err := ion.ReadInt(mem)
+		return modeIntKey(i), err == nil
+	case ion.UintType:
+		u, _, err := ion.ReadUint(mem)
+		if u > math.MaxInt64 {
+			return modeFloatKey(float64(u)), err == nil
+		}
+		return modeIntKey(int64(u)), err == nil
+	case ion.FloatType:
+		f, _, err := ion.ReadFloat64(mem)
+		return modeFloatKey(f), err == nil
+	case ion.StringType:
+		str, _, err := ion.ReadStringShared(mem)
+		if err != nil {
+			return modeKey{}, false
+		}
+		return modeStringKey(str)
+	}
+	return modeKey{}, false
+}
+
+func (k modeKey) int() int64 {
+	return int64(binary.LittleEndian.Uint64(k.data[:]))
+}
+
+func (k modeKey) float() float64 {
+	if k.kind == modeInt {
+		return float64(k.int())
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(k.data[:]))
+}
+
+func (k *modeKey) str() []byte {
+	return k.data[:k.size]
+}
+
+// less orders numbers numerically with NaN last,
+// followed by strings in byte order
+func (k modeKey) less(o modeKey) bool {
+	if (k.kind == modeString) != (o.kind == modeString) {
+		return o.kind == modeString
+	}
+	if k.kind == modeString {
+		return bytes.Compare(k.str(), o.str()) < 0
+	}
+	if k.kind == modeInt && o.kind == modeInt {
+		return k.int() < o.int()
+	}
+	a, b := k.float(), o.float()
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return !math.IsNaN(a)
+	}
+	return a < b
+}
+
+type modeState []byte
+
+func (s modeState) key(i int) modeKey {
+	k := modeKey{
+		kind: s[modeKindsOffset+i],
+		size: s[modeSizesOffset+i],
+	}
+	copy(k.data[:], s[i*modeValueSize:])
+	return k
+}
+
+func (s modeState) count(i int) uint64 {
+	return binary.LittleEndian.Uint64(s[modeCountsOffset+i*8:])
+}
+
+func (s modeState) setCount(i int, n uint64) {
+	binary.LittleEndian.PutUint64(s[modeCountsOffset+i*8:], n)
+}
+
+func (s modeState) set(i int, k modeKey, n uint64) {
+	copy(s[i*modeValueSize:], k.data[:])
+	s[modeKindsOffset+i] = k.kind
+	s[modeSizesOffset+i] = k.size
+	s.setCount(i, n)
+}
+
+// add accounts for n occurrences of k
+func (s modeState) add(k modeKey, n uint64) {
+	free := -1
+	for i := 0; i < modeCapacity; i++ {
+		if s.count(i) == 0 {
+			if free < 0 {
+				free = i
+			}
+		} else if s.key(i) == k {
+			s.setCount(i, s.count(i)+n)
+			return
+		}
+	}
+	if free < 0 {
+		// the table is full: reduce every count
+		// by as much of n as the table can absorb
+		m, most := n, uint64(0)
+		for i := 0; i < modeCapacity; i++ {
+			m = min(m, s.count(i))
+			most = max(most, s.count(i))
+		}
+		if m == n && most == n {
+			// every entry, including k, would be
+			// evicted; keep the value that best()
+			// would have chosen as a candidate
+			// (with the smallest possible count)
+			// rather than forgetting every value
+			best, _ := s.best()
+			if k.less(best) {
+				best = k
+			}
+			for i := 0; i < modeCapacity; i++ {
+				s.setCount(i, 0)
+			}
+			s.set(0, best, 1)
+			return
+		}
+		for i := 0; i < modeCapacity; i++ {
+			c := s.count(i) - m
+			s.setCount(i, c)
+			if c == 0 && free < 0 {
+				free = i
+			}
+		}
+		n -= m
+		if n == 0 {
+			return
+		}
+	}
+	s.set(free, k, n)
+}
+
+// aggModeInit initializes an aggregation buffer
+func aggModeInit(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// aggModeMerge merges src into dst
+func aggModeMerge(dst, src []byte) {
+	d, s := modeState(dst[:modeDataSize]), modeState(src[:modeDataSize])
+	for i := 0; i < modeCapacity; i++ {
+		if n := s.count(i); n != 0 {
+			d.add(s.key(i), n)
+		}
+	}
+}
+
+// best returns the most frequent value, or false
+// if there are no values; ties are resolved in favor
+// of the smallest value
+func (s modeState) best() (modeKey, bool) {
+	best := -1
+	for i := 0; i < modeCapacity; i++ {
+		n := s.count(i)
+		if n == 0 {
+			continue
+		}
+		if best < 0 || n > s.count(best) || (n == s.count(best) && s.key(i).less(s.key(best))) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return modeKey{}, false
+	}
+	return s.key(best), true
+}
+
+// aggModeWrite writes the most frequent
+// value, or NULL if there were no values
+func aggModeWrite(b *ion.Buffer, data []byte) {
+	k, ok := modeState(data[:modeDataSize]).best()
+	if !ok {
+		b.WriteNull()
+	} else if k.kind == modeInt {
+		b.WriteInt(k.int())
+	} else if k.kind == modeString {
+		b.WriteStringBytes(k.str())
+	} else {
+		b.WriteFloat64(k.float())
+	}
+}
+
+// cmpMode compares the results of two MODE states;
+// NULL results sort last
+func cmpMode(left, right []byte) int {
+	lk, lok := modeState(left[:modeDataSize]).best()
+	rk, rok := modeState(right[:modeDataSize]).best()
+	if !lok {
+		if !rok {
+			return 0
+		}
+		return 1
+	} else if !rok {
+		return -1
+	}
+	if lk.less(rk) {
+		return -1
+	}
+	if rk.less(lk) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,183 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// modeRows returns rows {g: i, x: groups[i][j]} for
+// every group; a nil value produces a row without x
+func modeRows(groups [][]any) []byte {
+	var st ion.Symtab
+	var buf ion.Buffer
+	x := st.Intern("x")
+	g := st.Intern("g")
+	st.Marshal(&buf, true)
+	for i := range groups {
+		for _, v := range groups[i] {
+			buf.BeginStruct(-1)
+			buf.BeginField(g)
+			buf.WriteInt(int64(i))
+			switch v := v.(type) {
+			case int:
+				buf.BeginField(x)
+				buf.WriteInt(int64(v))
+			case float64:
+				buf.BeginField(x)
+				buf.WriteFloat64(v)
+			case string:
+				buf.BeginField(x)
+				buf.WriteString(v)
+			}
+			buf.EndStruct()
+		}
+	}
+	return buf.Bytes()
+}
+
+// runMode computes MODE(x) grouped by g and returns
+// the JSON representation of the result of each group
+func runMode(t *testing.T, groups [][]any) []string {
+	aggs := Aggregation{{Expr: &expr.Aggregate{Op: expr.OpMode, Inner: path(t, "x")}, Result: "mode"}}
+	var qb QueryBuffer
+	sink, err := NewHashAggregate(aggs, nil, Selection{{Expr: path(t, "g")}}, &qb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CopyRows(sink, buftbl(modeRows(groups)), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sink.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]string, len(groups))
+	var st ion.Symtab
+	buf := qb.Bytes()
+	for len(buf) > 0 {
+		var d ion.Datum
+		d, buf, err = ion.ReadDatum(&st, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.IsEmpty() || d.Type() != ion.StructType {
+			continue
+		}
+		s, _ := d.Struct()
+		g, ok := s.FieldByName("g")
+		if !ok {
+			t.Fatalf("no group in %s", toJSON(&st, d))
+		}
+		i, err := g.Int()
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, ok := s.FieldByName("mode")
+		if !ok {
+			t.Fatalf("no mode in %s", toJSON(&st, d))
+		}
+		out[i] = strings.TrimSpace(toJSON(&st, f.Datum))
+	}
+	return out
+}
+
+func TestAggregateMode(t *testing.T) {
+	distinct := make([]any, modeCapacity)
+	for i := range distinct {
+		distinct[i] = modeCapacity - i
+	}
+	overflow := make([]any, modeCapacity+1)
+	for i := range overflow {
+		overflow[i] = len(overflow) - i
+	}
+	overflowMany := make([]any, 3*modeCapacity+5)
+	for i := range overflowMany {
+		overflowMany[i] = len(overflowMany) - i
+	}
+	long := strings.Repeat("x", modeValueSize+1)
+	tcs := []struct {
+		name string
+		vals []any
+		want string
+	}{
+		{name: "clear", vals: []any{3, 1, 3, 2, 3, 1}, want: "3"},
+		// integral floats are the same value as integers
+		{name: "mixed", vals: []any{2.5, 7, 7.0, 2.5, 7, "7", nil}, want: "7"},
+		{name: "float", vals: []any{1.5, 2.5, 1.5}, want: "1.5"},
+		// both 5 and 2 occur twice; the smaller value wins
+		{name: "tie", vals: []any{5, 2, 5, 9, 2}, want: "2"},
+		// every value occurs once, so the smallest one wins
+		{name: "distinct", vals: distinct, want: "1"},
+		// MISSING values are skipped
+		{name: "missing", vals: []any{nil, 4, nil}, want: "4"},
+		{name: "empty", vals: []any{nil}, want: "null"},
+		{name: "string", vals: []any{"b", 3, "a", "b", 3, "b"}, want: `"b"`},
+		// numbers are smaller than strings
+		{name: "string-tie", vals: []any{"a", 10, "a", 10}, want: "10"},
+		// strings that are too long are skipped
+		{name: "long", vals: []any{long, long, "short"}, want: `"short"`},
+		// once the table is full, a candidate
+		// survives values that occur only once
+		{name: "overflow", vals: overflow, want: "1"},
+		{name: "overflow-many", vals: overflowMany, want: "1"},
+	}
+	groups := make([][]any, len(tcs))
+	for i := range tcs {
+		groups[i] = tcs[i].vals
+	}
+	run := func(t *testing.T) {
+		got := runMode(t, groups)
+		for i := range tcs {
+			if got[i] != tcs[i].want {
+				t.Errorf("%s: got %s, want %s", tcs[i].name, got[i], tcs[i].want)
+			}
+		}
+	}
+	level := globalOptimizationLevel
+	defer SetOptimizationLevel(level)
+	t.Run("default", run)
+	SetOptimizationLevel(OptimizationLevelNone)
+	t.Run("portable", run)
+}
+
+func TestAggregateModeMerge(t *testing.T) {
+	// a value that makes up more than 1/(modeCapacity+1)
+	// of the input survives both eviction and merging
+	var a, b modeState = make([]byte, modeDataSize), make([]byte, modeDataSize)
+	for i := 0; i < 100; i++ {
+		a.add(modeIntKey(int64(i)), 1)
+		if i%4 == 0 {
+			a.add(modeIntKey(-1), 1)
+			b.add(modeIntKey(-1), 1)
+		}
+		b.add(modeFloatKey(float64(i)+0.5), 1)
+	}
+	aggModeMerge(a, b)
+	var buf ion.Buffer
+	aggModeWrite(&buf, a)
+	d, _, err := ion.ReadDatum(nil, buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i, err := d.Int(); err != nil || i != -1 {
+		t.Errorf("got %v, want -1", d)
+	}
+}
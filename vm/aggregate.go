@@ -58,6 +58,7 @@ const (
 	AggregateOpMaxTS
 	AggregateOpCount
 	AggregateOpApproxCountDistinct
+	AggregateOpMode
 )
 
 func (o AggregateOpFn) String() string {
@@ -102,6 +103,8 @@ func (o AggregateOpFn) String() string {
 		return "AggregateOpApproxCountDistinct"
 	case AggregateOpTDigest:
 		return "AggregateOpTDigest"
+	case AggregateOpMode:
+		return "AggregateOpMode"
 	default:
 		return fmt.Sprintf("<AggregateOpFn=%d>", int(o))
 	}
@@ -182,6 +185,7 @@ var aggregateOpInfoTable = [...]aggregateOpInfo{
 
 	AggregateOpTDigest:             {isAtomic: false, initFunc: tDigestInit},
 	AggregateOpApproxCountDistinct: {isAtomic: false, initFunc: aggApproxCountDistinctInit},
+	AggregateOpMode:                {isAtomic: false, initFunc: aggModeInit},
 }
 
 func (a *AggregateOp) dataSize() int {
@@ -230,6 +234,9 @@ func (a *AggregateOp) dataSize() int {
 
 	case AggregateOpApproxCountDistinct:
 		return 1 << a.precision
+
+	case AggregateOpMode:
+		return modeDataSize
 	}
 
 	return 0
@@ -270,12 +277,18 @@ func mergeAggregateBuffers(dst, src []byte, op AggregateOp) bool {
 	case AggregateOpTDigest:
 		tDigestMerge(dst, src)
 		return true
+
+	case AggregateOpMode:
+		aggModeMerge(dst, src)
+		return true
 	}
 
 	return false
 }
 
-func mergeAggregatedValues(dst, src []byte, aggregateOps []AggregateOp) {
+// mergeAggregatedValues merges the aggregated values in src into dst;
+// it fails if an integer SUM or AVG overflows
+func mergeAggregatedValues(dst, src []byte, aggregateOps []AggregateOp) error {
 	for i, op := range aggregateOps {
 		if op.mergestate() {
 			dst = dst[aggregateOpMergeBufferSize:]
@@ -309,7 +322,9 @@ func mergeAggregatedValues(dst, src []byte, aggregateOps []AggregateOp) {
 			src = src[8:]
 
 		case AggregateOpSumI:
-			bufferAddInt64(dst, src)
+			if !bufferAddInt64Checked(dst, src) {
+				return bcerrIntOverflow
+			}
 			dst = dst[8:]
 			src = src[8:]
 			bufferOrInt64(dst, src)
@@ -325,7 +340,9 @@ func mergeAggregatedValues(dst, src []byte, aggregateOps []AggregateOp) {
 			src = src[8:]
 
 		case AggregateOpAvgI:
-			bufferAddInt64(dst, src)
+			if !bufferAddInt64Checked(dst, src) {
+				return bcerrIntOverflow
+			}
 			dst = dst[8:]
 			src = src[8:]
 			bufferAddInt64(dst, src)
@@ -383,13 +400,37 @@ func mergeAggregatedValues(dst, src []byte, aggregateOps []AggregateOp) {
 			dst = dst[n:]
 			src = src[n:]
 
+		case AggregateOpMode:
+			aggModeMerge(dst, src)
+			dst = dst[modeDataSize:]
+			src = src[modeDataSize:]
+
 		default:
 			panic(fmt.Sprintf("unsupported operation %s", aggregateOps[i].fn))
 		}
 	}
+	return nil
 }
 
-func mergeAggregatedValuesAtomically(dst, src []byte, aggregateOps []AggregateOp) {
+// atomicAddInt64Checked atomically adds v to *p
+// unless the sum would overflow, in which case
+// *p is left unchanged and false is returned
+func atomicAddInt64Checked(p *int64, v int64) bool {
+	for {
+		old := atomic.LoadInt64(p)
+		sum, ok := addInt64(old, v)
+		if !ok {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(p, old, sum) {
+			return true
+		}
+	}
+}
+
+// mergeAggregatedValuesAtomically is mergeAggregatedValues
+// for aggregates whose state can be updated atomically
+func mergeAggregatedValuesAtomically(dst, src []byte, aggregateOps []AggregateOp) error {
 	for i := range aggregateOps {
 		switch aggregateOps[i].fn {
 
@@ -409,7 +450,17 @@ func mergeAggregatedValuesAtomically(dst, src []byte, aggregateOps []AggregateOp
 			dst = dst[8:]
 			src = src[8:]
 
-		case AggregateOpSumI, AggregateOpAvgI, AggregateOpSumC:
+		case AggregateOpSumI, AggregateOpAvgI:
+			if !atomicAddInt64Checked((*int64)(unsafe.Pointer(&dst[0])), int64(binary.LittleEndian.Uint64(src))) {
+				return bcerrIntOverflow
+			}
+			dst = dst[8:]
+			src = src[8:]
+			atomic.AddUint64((*uint64)(unsafe.Pointer(&dst[0])), binary.LittleEndian.Uint64(src))
+			dst = dst[8:]
+			src = src[8:]
+
+		case AggregateOpSumC:
 			atomic.AddUint64((*uint64)(unsafe.Pointer(&dst[0])), binary.LittleEndian.Uint64(src))
 			dst = dst[8:]
 			src = src[8:]
@@ -466,6 +517,7 @@ func mergeAggregatedValuesAtomically(dst, src []byte, aggregateOps []AggregateOp
 			panic(fmt.Sprintf("unsupported aggregate operation %s", aggregateOps[i].fn))
 		}
 	}
+	return nil
 }
 
 // writeAggregatedValue writes the final result of the Aggregation to the ion.Buffer
@@ -558,6 +610,10 @@ func writeAggregatedValue(b *ion.Buffer, data []byte, op AggregateOp) int {
 		b.WriteCanonicalFloat(float64(percentiles[0]))
 		return tDigestDataSize
 
+	case AggregateOpMode:
+		aggModeWrite(b, data)
+		return modeDataSize
+
 	default:
 		panic(fmt.Sprintf("Invalid aggregate op: %v", op.fn))
 	}
@@ -721,7 +777,7 @@ func (p *aggregateLocal) symbolize(st *symtab, aux *auxbindings) error {
 }
 
 func evalaggregate(bc *bytecode, delims []vmref, aggregateDataBuffer []byte) int {
-	if globalOptimizationLevel >= OptimizationLevelAVX512V1 {
+	if bc.useAsm() {
 		return evalaggregatebc(bc, delims, aggregateDataBuffer)
 	}
 
@@ -795,17 +851,18 @@ func (p *aggregateLocal) next() rowConsumer {
 func (p *aggregateLocal) Close() error {
 	atomic.AddInt64(&p.parent.rowcount, int64(p.rowCount))
 	p.rowCount = 0
+	var err error
 	if p.parent.canMergeAtomically() {
-		mergeAggregatedValuesAtomically(p.parent.AggregatedData, p.partialData, p.parent.aggregateOps)
+		err = mergeAggregatedValuesAtomically(p.parent.AggregatedData, p.partialData, p.parent.aggregateOps)
 	} else {
 		p.parent.lock.Lock()
-		mergeAggregatedValues(p.parent.AggregatedData, p.partialData, p.parent.aggregateOps)
+		err = mergeAggregatedValues(p.parent.AggregatedData, p.partialData, p.parent.aggregateOps)
 		p.parent.lock.Unlock()
 	}
 
 	p.partialData = nil
 	p.bc.reset()
-	return nil
+	return err
 }
 
 // NewAggregate constructs an aggregation QuerySink.
@@ -868,6 +925,19 @@ func (q *Aggregate) compileAggregate(aggregates Aggregation) error {
 			}
 			ops[i].fn = AggregateOpCount
 
+		case expr.OpCountIf:
+			// COUNT_IF(p) is COUNT(*) restricted to the
+			// lanes where p is true (and the filter holds)
+			k, err := p.compileAsBool(agg.Inner)
+			if err != nil {
+				return err
+			}
+			if filter != nil {
+				k = p.and(k, filter)
+			}
+			mem[i] = p.aggregateCount(p.validLanes(), k, offset)
+			ops[i].fn = AggregateOpCount
+
 		case expr.OpApproxCountDistinct:
 			v, err := compile(p, agg.Inner)
 			if err != nil {
@@ -885,6 +955,22 @@ func (q *Aggregate) compileAggregate(aggregates Aggregation) error {
 				mem[i] = p.aggregateMergeState(v, offset)
 			}
 
+		case expr.OpMode:
+			v, err := p.serialized(agg.Inner)
+			if err != nil {
+				return fmt.Errorf("don't know how to aggregate %q: %w", agg.Inner, err)
+			}
+
+			ops[i].fn = AggregateOpMode
+			ops[i].role = agg.Role
+			switch agg.Role {
+			case expr.AggregateRoleFinal, expr.AggregateRolePartial:
+				mem[i] = p.aggregateMode(p.unsymbolized(v), filter, offset)
+
+			case expr.AggregateRoleMerge:
+				mem[i] = p.aggregateMergeState(v, offset)
+			}
+
 		case expr.OpBoolAnd, expr.OpBoolOr:
 			argv, err := compile(p, agg.Inner)
 			if err != nil {
@@ -0,0 +1,177 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// nanRows returns rows {x: vals[i], g: i%groups}
+func nanRows(vals []float64, groups int) []byte {
+	var st ion.Symtab
+	var buf ion.Buffer
+	x := st.Intern("x")
+	g := st.Intern("g")
+	st.Marshal(&buf, true)
+	for i := range vals {
+		buf.BeginStruct(-1)
+		buf.BeginField(x)
+		buf.WriteFloat64(vals[i])
+		buf.BeginField(g)
+		buf.WriteInt(int64(i % groups))
+		buf.EndStruct()
+	}
+	return buf.Bytes()
+}
+
+// runNaN computes op(x) AS "out", grouped by g
+// if groups > 0, and returns the results of all
+// output rows; if skip is set, NaN values of x are
+// filtered out with FILTER (WHERE NOT IS_NAN(x))
+func runNaN(t *testing.T, op expr.AggregateOp, vals []float64, groups int, skip bool) []float64 {
+	agg := &expr.Aggregate{Op: op, Inner: path(t, "x")}
+	if skip {
+		agg.Filter = &expr.Not{Expr: expr.Call(expr.IsNaN, path(t, "x"))}
+	}
+	aggs := Aggregation{{Expr: agg, Result: "out"}}
+	var qb QueryBuffer
+	var sink QuerySink
+	var err error
+	if groups > 0 {
+		sink, err = NewHashAggregate(aggs, nil, Selection{{Expr: path(t, "g")}}, &qb)
+	} else {
+		sink, err = NewAggregate(aggs, &qb)
+		groups = 1
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CopyRows(sink, buftbl(nanRows(vals, groups)), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sink.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var st ion.Symtab
+	var out []float64
+	buf := qb.Bytes()
+	for len(buf) > 0 {
+		var d ion.Datum
+		d, buf, err = ion.ReadDatum(&st, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.IsEmpty() || d.Type() != ion.StructType {
+			continue
+		}
+		s, _ := d.Struct()
+		f, ok := s.FieldByName("out")
+		if !ok {
+			t.Fatalf("no output in %s", toJSON(&st, d))
+		}
+		v, err := f.CoerceFloat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func TestAggregateNaN(t *testing.T) {
+	nan := math.NaN()
+	// 40 values, so there are several lanes and batches;
+	// the NaN is placed at the start, in the middle and at
+	// the end so that the result doesn't depend on the
+	// order in which values are aggregated
+	column := func(at int) []float64 {
+		out := make([]float64, 40)
+		for i := range out {
+			out[i] = float64(i + 1)
+		}
+		out[at] = nan
+		return out
+	}
+	type result struct {
+		sum, min, max float64
+	}
+	// the NaN is at an even position,
+	// so it ends up in group 0
+	tcs := []struct {
+		name   string
+		at     int
+		groups int
+		skip   result
+	}{
+		{name: "first", at: 0, skip: result{sum: 820 - 1, min: 2, max: 40}},
+		{name: "middle", at: 20, skip: result{sum: 820 - 21, min: 1, max: 40}},
+		{name: "last", at: 38, skip: result{sum: 820 - 39, min: 1, max: 40}},
+		{name: "grouped", at: 20, groups: 2, skip: result{sum: 400 - 21, min: 1, max: 39}},
+	}
+	ops := []struct {
+		op  expr.AggregateOp
+		get func(r *result) float64
+	}{
+		{expr.OpSum, func(r *result) float64 { return r.sum }},
+		{expr.OpMin, func(r *result) float64 { return r.min }},
+		{expr.OpMax, func(r *result) float64 { return r.max }},
+	}
+	run := func(t *testing.T) {
+		for i := range tcs {
+			tc := &tcs[i]
+			for j := range ops {
+				op := ops[j].op
+				t.Run(tc.name+"/"+op.String(), func(t *testing.T) {
+					vals := column(tc.at)
+					// by default a single NaN makes
+					// the aggregate (of its group) NaN
+					got := runNaN(t, op, vals, tc.groups, false)
+					nans := 0
+					for _, v := range got {
+						if math.IsNaN(v) {
+							nans++
+						}
+					}
+					if nans != 1 {
+						t.Errorf("propagate: got %v, want exactly one NaN", got)
+					}
+					got = runNaN(t, op, vals, tc.groups, true)
+					want := ops[j].get(&tc.skip)
+					found := false
+					for _, v := range got {
+						if math.IsNaN(v) {
+							t.Errorf("skip: got %v", got)
+						}
+						found = found || v == want
+					}
+					if !found {
+						t.Errorf("skip: got %v, want %g", got, want)
+					}
+				})
+			}
+		}
+	}
+	level := globalOptimizationLevel
+	defer SetOptimizationLevel(level)
+	t.Run("default", run)
+	SetOptimizationLevel(OptimizationLevelNone)
+	t.Run("portable", run)
+}
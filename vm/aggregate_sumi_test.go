@@ -0,0 +1,147 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// sumiRows returns rows {x: vals[i], g: i%groups}
+func sumiRows(vals []int64, groups int) []byte {
+	var st ion.Symtab
+	var buf ion.Buffer
+	x := st.Intern("x")
+	g := st.Intern("g")
+	st.Marshal(&buf, true)
+	for i := range vals {
+		buf.BeginStruct(-1)
+		buf.BeginField(x)
+		buf.WriteInt(vals[i])
+		buf.BeginField(g)
+		buf.WriteInt(int64(i % groups))
+		buf.EndStruct()
+	}
+	return buf.Bytes()
+}
+
+// runSumi computes op(CAST(x AS INTEGER)) AS "sum",
+// grouped by g if groups > 0, and returns the result
+// of the first output row
+func runSumi(t *testing.T, op expr.AggregateOp, vals []int64, groups int) (ion.Datum, error) {
+	// a bare path would be summed as a float
+	x := &expr.Cast{From: path(t, "x"), To: expr.IntegerType}
+	agg := Aggregation{{Expr: &expr.Aggregate{Op: op, Inner: x}, Result: "sum"}}
+	var qb QueryBuffer
+	var sink QuerySink
+	var err error
+	if groups > 0 {
+		sink, err = NewHashAggregate(agg, nil, Selection{{Expr: path(t, "g")}}, &qb)
+	} else {
+		sink, err = NewAggregate(agg, &qb)
+		groups = 1
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CopyRows(sink, buftbl(sumiRows(vals, groups)), 1)
+	if err != nil {
+		return ion.Datum{}, err
+	}
+	err = sink.Close()
+	if err != nil {
+		return ion.Datum{}, err
+	}
+	var st ion.Symtab
+	d, _, err := ion.ReadDatum(&st, qb.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := d.Struct()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, ok := s.FieldByName("sum")
+	if !ok {
+		t.Fatalf("no sum in %s", toJSON(&st, d))
+	}
+	return f.Datum, nil
+}
+
+func TestAggregateSumiOverflow(t *testing.T) {
+	repeat := func(v int64, n int) []int64 {
+		out := make([]int64, n)
+		for i := range out {
+			out[i] = v
+		}
+		return out
+	}
+	tcs := []struct {
+		name   string
+		op     expr.AggregateOp
+		vals   []int64
+		groups int
+		want   int64 // if !overflow
+		// overflow is set if the query
+		// is expected to fail
+		overflow bool
+	}{
+		// the ungrouped sum is exact, so it may temporarily
+		// exceed int64 as long as the result fits
+		{name: "max", op: expr.OpSum, vals: []int64{math.MaxInt64, 1, -1}, want: math.MaxInt64},
+		{name: "min", op: expr.OpSum, vals: []int64{math.MinInt64, -1, 1}, want: math.MinInt64},
+		{name: "max+1", op: expr.OpSum, vals: []int64{math.MaxInt64, 1}, overflow: true},
+		{name: "min-1", op: expr.OpSum, vals: []int64{math.MinInt64, -1}, overflow: true},
+		{name: "batches", op: expr.OpSum, vals: repeat(math.MaxInt64/32, 33), overflow: true},
+		{name: "batches-fit", op: expr.OpSum, vals: repeat(math.MaxInt64/32, 32), want: 32 * (math.MaxInt64 / 32)},
+		{name: "avg", op: expr.OpAvg, vals: repeat(math.MaxInt64/4, 5), overflow: true},
+		{name: "grouped", op: expr.OpSum, vals: repeat(math.MaxInt64/32, 66), groups: 2, overflow: true},
+		{name: "grouped-fit", op: expr.OpSum, vals: repeat(math.MaxInt64/32, 64), groups: 2, want: 32 * (math.MaxInt64 / 32)},
+		{name: "grouped-avg", op: expr.OpAvg, vals: repeat(math.MinInt64/4, 10), groups: 2, overflow: true},
+	}
+	run := func(t *testing.T) {
+		for i := range tcs {
+			tc := &tcs[i]
+			t.Run(tc.name, func(t *testing.T) {
+				d, err := runSumi(t, tc.op, tc.vals, tc.groups)
+				if tc.overflow {
+					if !errors.Is(err, bcerrIntOverflow) {
+						t.Fatalf("expected overflow error; got %v", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := d.Int()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != tc.want {
+					t.Errorf("got %d, want %d", got, tc.want)
+				}
+			})
+		}
+	}
+	level := globalOptimizationLevel
+	defer SetOptimizationLevel(level)
+	t.Run("default", run)
+	SetOptimizationLevel(OptimizationLevelNone)
+	t.Run("portable", run)
+}
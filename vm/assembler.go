@@ -22,6 +22,7 @@ import (
 type assembler struct {
 	code       []byte
 	scratchuse int
+	goonly     bool // emitted an op with no assembly implementation
 }
 
 func (a *assembler) grabCode() []byte {
@@ -55,6 +56,9 @@ func (a *assembler) emitOpcodeValue(op bcop) {
 	if a.scratchuse > PageSize {
 		a.scratchuse = PageSize
 	}
+	if opinfo[op].goonly {
+		a.goonly = true
+	}
 	a.code = append(a.code, byte(op), byte(op>>8))
 }
 
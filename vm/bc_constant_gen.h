@@ -33,6 +33,7 @@ CONST_DATA_U64(constpool, 48, $10) // 0x000000000000000a
 #define CONSTQ_12() CONST_GET_PTR(constpool, 56)
 CONST_DATA_U64(constpool, 56, $12) // 0x000000000000000c
 
+#define CONSTD_24() CONST_GET_PTR(constpool, 64)
 #define CONSTQ_24() CONST_GET_PTR(constpool, 64)
 CONST_DATA_U64(constpool, 64, $24) // 0x0000000000000018
 
@@ -120,324 +121,383 @@ CONST_DATA_U64(constpool, 256, $146097) // 0x0000000000023ab1
 #define CONSTQ_1000000() CONST_GET_PTR(constpool, 264)
 CONST_DATA_U64(constpool, 264, $1000000) // 0x00000000000f4240
 
-#define CONSTD_0x00808080() CONST_GET_PTR(constpool, 272)
-#define CONSTQ_0x0000000000808080() CONST_GET_PTR(constpool, 272)
-CONST_DATA_U64(constpool, 272, $8421504) // 0x0000000000808080
+#define CONSTQ_0x10FFFF() CONST_GET_PTR(constpool, 272)
+CONST_DATA_U64(constpool, 272, $1114111) // 0x000000000010ffff
 
-#define CONSTQ_0xFFFFFF() CONST_GET_PTR(constpool, 280)
-CONST_DATA_U64(constpool, 280, $16777215) // 0x0000000000ffffff
+#define CONSTD_0x00808080() CONST_GET_PTR(constpool, 280)
+#define CONSTQ_0x0000000000808080() CONST_GET_PTR(constpool, 280)
+CONST_DATA_U64(constpool, 280, $8421504) // 0x0000000000808080
 
-#define CONSTQ_18764999() CONST_GET_PTR(constpool, 288)
-CONST_DATA_U64(constpool, 288, $18764999) // 0x00000000011e54c7
+#define CONSTQ_0xFFFFFF() CONST_GET_PTR(constpool, 288)
+CONST_DATA_U64(constpool, 288, $16777215) // 0x0000000000ffffff
 
-#define CONSTQ_60000000() CONST_GET_PTR(constpool, 296)
-CONST_DATA_U64(constpool, 296, $60000000) // 0x0000000003938700
+#define CONSTQ_18764999() CONST_GET_PTR(constpool, 296)
+CONST_DATA_U64(constpool, 296, $18764999) // 0x00000000011e54c7
 
-#define CONSTQ_100000000() CONST_GET_PTR(constpool, 304)
-CONST_DATA_U64(constpool, 304, $100000000) // 0x0000000005f5e100
+#define CONSTQ_60000000() CONST_GET_PTR(constpool, 304)
+CONST_DATA_U64(constpool, 304, $60000000) // 0x0000000003938700
 
-#define CONSTQ_274877907() CONST_GET_PTR(constpool, 312)
-CONST_DATA_U64(constpool, 312, $274877907) // 0x0000000010624dd3
+#define CONSTQ_100000000() CONST_GET_PTR(constpool, 312)
+CONST_DATA_U64(constpool, 312, $100000000) // 0x0000000005f5e100
 
-#define CONSTQ_376287347() CONST_GET_PTR(constpool, 320)
-CONST_DATA_U64(constpool, 320, $376287347) // 0x00000000166db073
+#define CONSTQ_274877907() CONST_GET_PTR(constpool, 320)
+CONST_DATA_U64(constpool, 320, $274877907) // 0x0000000010624dd3
 
-#define CONSTQ_0b00000000_00000000_00000000_00000000_00011111_00000000_00000000_00011111() CONST_GET_PTR(constpool, 328)
-CONST_DATA_U64(constpool, 328, $520093727) // 0x000000001f00001f
+#define CONSTQ_376287347() CONST_GET_PTR(constpool, 328)
+CONST_DATA_U64(constpool, 328, $376287347) // 0x00000000166db073
 
-#define CONSTQ_600479951() CONST_GET_PTR(constpool, 336)
-CONST_DATA_U64(constpool, 336, $600479951) // 0x0000000023ca98cf
+#define CONSTQ_0b00000000_00000000_00000000_00000000_00011111_00000000_00000000_00011111() CONST_GET_PTR(constpool, 336)
+CONST_DATA_U64(constpool, 336, $520093727) // 0x000000001f00001f
 
-#define CONSTB_57() CONST_GET_PTR(constpool, 347)
-#define CONSTQ_963315389() CONST_GET_PTR(constpool, 344)
-CONST_DATA_U64(constpool, 344, $963315389) // 0x00000000396b06bd
+#define CONSTQ_600479951() CONST_GET_PTR(constpool, 344)
+CONST_DATA_U64(constpool, 344, $600479951) // 0x0000000023ca98cf
 
-#define CONSTQ_963321983() CONST_GET_PTR(constpool, 352)
-CONST_DATA_U64(constpool, 352, $963321983) // 0x00000000396b207f
+#define CONSTB_57() CONST_GET_PTR(constpool, 355)
+#define CONSTQ_963315389() CONST_GET_PTR(constpool, 352)
+CONST_DATA_U64(constpool, 352, $963315389) // 0x00000000396b06bd
 
-#define CONSTQ_1125899907() CONST_GET_PTR(constpool, 360)
-CONST_DATA_U64(constpool, 360, $1125899907) // 0x00000000431bde83
+#define CONSTQ_963321983() CONST_GET_PTR(constpool, 360)
+CONST_DATA_U64(constpool, 360, $963321983) // 0x00000000396b207f
 
-#define CONSTQ_1281023895() CONST_GET_PTR(constpool, 368)
-CONST_DATA_U64(constpool, 368, $1281023895) // 0x000000004c5adf97
+#define CONSTQ_1125899907() CONST_GET_PTR(constpool, 368)
+CONST_DATA_U64(constpool, 368, $1125899907) // 0x00000000431bde83
 
-#define CONSTQ_1374389535() CONST_GET_PTR(constpool, 376)
-CONST_DATA_U64(constpool, 376, $1374389535) // 0x0000000051eb851f
+#define CONSTQ_1281023895() CONST_GET_PTR(constpool, 376)
+CONST_DATA_U64(constpool, 376, $1281023895) // 0x000000004c5adf97
 
-#define CONSTQ_1441151881() CONST_GET_PTR(constpool, 384)
-CONST_DATA_U64(constpool, 384, $1441151881) // 0x0000000055e63b89
+#define CONSTQ_1374389535() CONST_GET_PTR(constpool, 384)
+CONST_DATA_U64(constpool, 384, $1374389535) // 0x0000000051eb851f
 
-#define CONSTQ_2290649225() CONST_GET_PTR(constpool, 392)
-CONST_DATA_U64(constpool, 392, $2290649225) // 0x0000000088888889
+#define CONSTQ_1441151881() CONST_GET_PTR(constpool, 392)
+CONST_DATA_U64(constpool, 392, $1441151881) // 0x0000000055e63b89
 
-#define CONSTQ_2562048517() CONST_GET_PTR(constpool, 400)
-CONST_DATA_U64(constpool, 400, $2562048517) // 0x0000000098b5c205
+#define CONSTQ_2290649225() CONST_GET_PTR(constpool, 400)
+CONST_DATA_U64(constpool, 400, $2290649225) // 0x0000000088888889
 
-#define CONSTQ_3037000499() CONST_GET_PTR(constpool, 408)
-CONST_DATA_U64(constpool, 408, $3037000499) // 0x00000000b504f333
+#define CONSTQ_2562048517() CONST_GET_PTR(constpool, 408)
+CONST_DATA_U64(constpool, 408, $2562048517) // 0x0000000098b5c205
 
-#define CONSTQ_0x00000000C6808080() CONST_GET_PTR(constpool, 416)
-CONST_DATA_U64(constpool, 416, $3330310272) // 0x00000000c6808080
+#define CONSTQ_3037000499() CONST_GET_PTR(constpool, 416)
+CONST_DATA_U64(constpool, 416, $3037000499) // 0x00000000b504f333
 
-#define CONSTQ_3518437209() CONST_GET_PTR(constpool, 424)
-CONST_DATA_U64(constpool, 424, $3518437209) // 0x00000000d1b71759
+#define CONSTQ_0x00000000C6808080() CONST_GET_PTR(constpool, 424)
+CONST_DATA_U64(constpool, 424, $3330310272) // 0x00000000c6808080
 
-#define CONSTQ_3593175255() CONST_GET_PTR(constpool, 432)
-CONST_DATA_U64(constpool, 432, $3593175255) // 0x00000000d62b80d7
+#define CONSTQ_3518437209() CONST_GET_PTR(constpool, 432)
+CONST_DATA_U64(constpool, 432, $3518437209) // 0x00000000d1b71759
 
-#define CONSTQ_3600000000() CONST_GET_PTR(constpool, 440)
-CONST_DATA_U64(constpool, 440, $3600000000) // 0x00000000d693a400
+#define CONSTQ_3593175255() CONST_GET_PTR(constpool, 440)
+CONST_DATA_U64(constpool, 440, $3593175255) // 0x00000000d62b80d7
 
-#define CONSTD_0xFFFFFFFF() CONST_GET_PTR(constpool, 448)
-#define CONSTD_NEG_1() CONST_GET_PTR(constpool, 448)
-#define CONSTQ_0xFFFFFFFF() CONST_GET_PTR(constpool, 448)
-CONST_DATA_U64(constpool, 448, $4294967295) // 0x00000000ffffffff
+#define CONSTQ_3600000000() CONST_GET_PTR(constpool, 448)
+CONST_DATA_U64(constpool, 448, $3600000000) // 0x00000000d693a400
 
-#define CONSTD_20() CONST_GET_PTR(constpool, 460)
-#define CONSTQ_86400000000() CONST_GET_PTR(constpool, 456)
-CONST_DATA_U64(constpool, 456, $86400000000) // 0x000000141dd76000
+#define CONSTD_0xFFFFFFFF() CONST_GET_PTR(constpool, 456)
+#define CONSTD_NEG_1() CONST_GET_PTR(constpool, 456)
+#define CONSTQ_0xFFFFFFFF() CONST_GET_PTR(constpool, 456)
+CONST_DATA_U64(constpool, 456, $4294967295) // 0x00000000ffffffff
 
-#define CONSTD_0x7F7F7F7F() CONST_GET_PTR(constpool, 464)
-#define CONSTQ_0x0000007F7F7F7F7F() CONST_GET_PTR(constpool, 464)
-CONST_DATA_U64(constpool, 464, $547599908735) // 0x0000007f7f7f7f7f
+#define CONSTD_20() CONST_GET_PTR(constpool, 468)
+#define CONSTQ_86400000000() CONST_GET_PTR(constpool, 464)
+CONST_DATA_U64(constpool, 464, $86400000000) // 0x000000141dd76000
 
-#define CONSTQ_1970_01_01_TO_0000_03_01_US_OFFSET_SHR_13() CONST_GET_PTR(constpool, 472)
-CONST_DATA_U64(constpool, 472, $7588139062500) // 0x000006e6c05554e4
+#define CONSTD_0x7F7F7F7F() CONST_GET_PTR(constpool, 472)
+#define CONSTQ_0x0000007F7F7F7F7F() CONST_GET_PTR(constpool, 472)
+CONST_DATA_U64(constpool, 472, $547599908735) // 0x0000007f7f7f7f7f
 
-#define CONSTQ_35184372088832() CONST_GET_PTR(constpool, 480)
-CONST_DATA_U64(constpool, 480, $35184372088832) // 0x0000200000000000
+#define CONSTQ_1970_01_01_TO_0000_03_01_US_OFFSET_SHR_13() CONST_GET_PTR(constpool, 480)
+CONST_DATA_U64(constpool, 480, $7588139062500) // 0x000006e6c05554e4
 
-#define CONSTQ_0x0000FFFFFFFFFFFF() CONST_GET_PTR(constpool, 488)
-CONST_DATA_U64(constpool, 488, $281474976710655) // 0x0000ffffffffffff
+#define CONSTQ_35184372088832() CONST_GET_PTR(constpool, 488)
+CONST_DATA_U64(constpool, 488, $35184372088832) // 0x0000200000000000
 
-#define CONSTQ_1970_01_01_TO_0000_03_01_US_OFFSET() CONST_GET_PTR(constpool, 496)
-CONST_DATA_U64(constpool, 496, $62162035200000000) // 0x00dcd80aaa9c8000
+#define CONSTQ_0x0000FFFFFFFFFFFF() CONST_GET_PTR(constpool, 496)
+CONST_DATA_U64(constpool, 496, $281474976710655) // 0x0000ffffffffffff
 
-#define CONSTQ_0x3D86800000000000() CONST_GET_PTR(constpool, 504)
-CONST_DATA_U64(constpool, 504, $4433371620681187328) // 0x3d86800000000000
+#define CONSTQ_1970_01_01_TO_0000_03_01_US_OFFSET() CONST_GET_PTR(constpool, 504)
+CONST_DATA_U64(constpool, 504, $62162035200000000) // 0x00dcd80aaa9c8000
 
-#define CONSTQ_0x3D96800000000000() CONST_GET_PTR(constpool, 512)
-CONST_DATA_U64(constpool, 512, $4437875220308557824) // 0x3d96800000000000
+#define CONSTQ_0x3D86800000000000() CONST_GET_PTR(constpool, 512)
+CONST_DATA_U64(constpool, 512, $4433371620681187328) // 0x3d86800000000000
 
-#define CONSTQ_0x5555555555555555() CONST_GET_PTR(constpool, 520)
-CONST_DATA_U64(constpool, 520, $6148914691236517205) // 0x5555555555555555
+#define CONSTQ_0x3D96800000000000() CONST_GET_PTR(constpool, 520)
+CONST_DATA_U64(constpool, 520, $4437875220308557824) // 0x3d96800000000000
 
-#define CONSTF64_ABS_BITS() CONST_GET_PTR(constpool, 528)
-#define CONSTQ_0x7FFFFFFFFFFFFFFF() CONST_GET_PTR(constpool, 528)
-CONST_DATA_U64(constpool, 528, $9223372036854775807) // 0x7fffffffffffffff
+#define CONSTF64_1() CONST_GET_PTR(constpool, 528)
+#define CONSTQ_0x3FF0000000000000() CONST_GET_PTR(constpool, 528)
+CONST_DATA_U64(constpool, 528, $4607182418800017408) // 0x3ff0000000000000
 
-#define CONSTF64_SIGN_BIT() CONST_GET_PTR(constpool, 536)
-#define CONSTQ_0x8000000000000000() CONST_GET_PTR(constpool, 536)
-CONST_DATA_U64(constpool, 536, $9223372036854775808) // 0x8000000000000000
+#define CONSTQ_0x5555555555555555() CONST_GET_PTR(constpool, 536)
+CONST_DATA_U64(constpool, 536, $6148914691236517205) // 0x5555555555555555
 
-#define CONSTQ_0xFFFFFFFFFFFFFFFF() CONST_GET_PTR(constpool, 544)
-#define CONSTQ_NEG_1() CONST_GET_PTR(constpool, 544)
-CONST_DATA_U64(constpool, 544, $18446744073709551615) // 0xffffffffffffffff
+#define CONSTF64_ABS_BITS() CONST_GET_PTR(constpool, 544)
+#define CONSTQ_0x7FFFFFFFFFFFFFFF() CONST_GET_PTR(constpool, 544)
+CONST_DATA_U64(constpool, 544, $9223372036854775807) // 0x7fffffffffffffff
+
+#define CONSTF64_SIGN_BIT() CONST_GET_PTR(constpool, 552)
+#define CONSTQ_0x8000000000000000() CONST_GET_PTR(constpool, 552)
+CONST_DATA_U64(constpool, 552, $9223372036854775808) // 0x8000000000000000
+
+#define CONSTQ_0x94D049BB133111EB() CONST_GET_PTR(constpool, 560)
+CONST_DATA_U64(constpool, 560, $10723151780598845931) // 0x94d049bb133111eb
+
+#define CONSTQ_0x9E3779B97F4A7C15() CONST_GET_PTR(constpool, 568)
+CONST_DATA_U64(constpool, 568, $11400714819323198485) // 0x9e3779b97f4a7c15
+
+#define CONSTQ_0xBF58476D1CE4E5B9() CONST_GET_PTR(constpool, 576)
+CONST_DATA_U64(constpool, 576, $13787848793156543929) // 0xbf58476d1ce4e5b9
+
+#define CONSTQ_0xFFFFFFFFFFFFFFFF() CONST_GET_PTR(constpool, 584)
+#define CONSTQ_NEG_1() CONST_GET_PTR(constpool, 584)
+CONST_DATA_U64(constpool, 584, $18446744073709551615) // 0xffffffffffffffff
 
 // uint32 constants
-#define CONSTD_6() CONST_GET_PTR(constpool, 552)
-CONST_DATA_U32(constpool, 552, $6) // 0x00000006
+#define CONSTD_6() CONST_GET_PTR(constpool, 592)
+CONST_DATA_U32(constpool, 592, $6) // 0x00000006
+
+#define CONSTD_0x0B() CONST_GET_PTR(constpool, 596)
+CONST_DATA_U32(constpool, 596, $11) // 0x0000000b
+
+#define CONSTD_0x0D() CONST_GET_PTR(constpool, 600)
+#define CONSTD_13() CONST_GET_PTR(constpool, 600)
+CONST_DATA_U32(constpool, 600, $13) // 0x0000000d
+
+#define CONSTD_0x0E() CONST_GET_PTR(constpool, 604)
+#define CONSTD_14() CONST_GET_PTR(constpool, 604)
+CONST_DATA_U32(constpool, 604, $14) // 0x0000000e
+
+#define CONSTD_0x0F() CONST_GET_PTR(constpool, 608)
+#define CONSTD_15() CONST_GET_PTR(constpool, 608)
+CONST_DATA_U32(constpool, 608, $15) // 0x0000000f
+
+#define CONSTD_16() CONST_GET_PTR(constpool, 612)
+#define CONSTD_FALSE_BYTE() CONST_GET_PTR(constpool, 612)
+CONST_DATA_U32(constpool, 612, $16) // 0x00000010
+
+#define CONSTD_TRUE_BYTE() CONST_GET_PTR(constpool, 616)
+CONST_DATA_U32(constpool, 616, $17) // 0x00000011
 
-#define CONSTD_0x0B() CONST_GET_PTR(constpool, 556)
-CONST_DATA_U32(constpool, 556, $11) // 0x0000000b
+#define CONSTD_0x2E() CONST_GET_PTR(constpool, 620)
+CONST_DATA_U32(constpool, 620, $46) // 0x0000002e
 
-#define CONSTD_0x0D() CONST_GET_PTR(constpool, 560)
-#define CONSTD_13() CONST_GET_PTR(constpool, 560)
-CONST_DATA_U32(constpool, 560, $13) // 0x0000000d
+#define CONSTD_131() CONST_GET_PTR(constpool, 624)
+CONST_DATA_U32(constpool, 624, $131) // 0x00000083
 
-#define CONSTD_0x0E() CONST_GET_PTR(constpool, 564)
-#define CONSTD_14() CONST_GET_PTR(constpool, 564)
-CONST_DATA_U32(constpool, 564, $14) // 0x0000000e
+#define CONSTD_0xB0() CONST_GET_PTR(constpool, 628)
+CONST_DATA_U32(constpool, 628, $176) // 0x000000b0
 
-#define CONSTD_0x0F() CONST_GET_PTR(constpool, 568)
-#define CONSTD_15() CONST_GET_PTR(constpool, 568)
-CONST_DATA_U32(constpool, 568, $15) // 0x0000000f
+#define CONSTD_0b11000000() CONST_GET_PTR(constpool, 632)
+CONST_DATA_U32(constpool, 632, $192) // 0x000000c0
 
-#define CONSTD_16() CONST_GET_PTR(constpool, 572)
-#define CONSTD_FALSE_BYTE() CONST_GET_PTR(constpool, 572)
-CONST_DATA_U32(constpool, 572, $16) // 0x00000010
+#define CONSTD_0xD0() CONST_GET_PTR(constpool, 636)
+CONST_DATA_U32(constpool, 636, $208) // 0x000000d0
 
-#define CONSTD_TRUE_BYTE() CONST_GET_PTR(constpool, 576)
-CONST_DATA_U32(constpool, 576, $17) // 0x00000011
+#define CONSTD_0b11100000() CONST_GET_PTR(constpool, 640)
+CONST_DATA_U32(constpool, 640, $224) // 0x000000e0
 
-#define CONSTD_0x2E() CONST_GET_PTR(constpool, 580)
-CONST_DATA_U32(constpool, 580, $46) // 0x0000002e
+#define CONSTD_0b11110000() CONST_GET_PTR(constpool, 644)
+#define CONSTD_0xF0() CONST_GET_PTR(constpool, 644)
+CONST_DATA_U32(constpool, 644, $240) // 0x000000f0
 
-#define CONSTD_131() CONST_GET_PTR(constpool, 584)
-CONST_DATA_U32(constpool, 584, $131) // 0x00000083
+#define CONSTD_0b11111000() CONST_GET_PTR(constpool, 648)
+CONST_DATA_U32(constpool, 648, $248) // 0x000000f8
 
-#define CONSTD_0xB0() CONST_GET_PTR(constpool, 588)
-CONST_DATA_U32(constpool, 588, $176) // 0x000000b0
+#define CONSTD_0xFF() CONST_GET_PTR(constpool, 652)
+CONST_DATA_U32(constpool, 652, $255) // 0x000000ff
 
-#define CONSTD_0b11000000() CONST_GET_PTR(constpool, 592)
-CONST_DATA_U32(constpool, 592, $192) // 0x000000c0
+#define CONSTD_0x800() CONST_GET_PTR(constpool, 656)
+CONST_DATA_U32(constpool, 656, $2048) // 0x00000800
 
-#define CONSTD_0xD0() CONST_GET_PTR(constpool, 596)
-CONST_DATA_U32(constpool, 596, $208) // 0x000000d0
+#define CONSTD_5243() CONST_GET_PTR(constpool, 660)
+CONST_DATA_U32(constpool, 660, $5243) // 0x0000147b
 
-#define CONSTD_0b11100000() CONST_GET_PTR(constpool, 600)
-CONST_DATA_U32(constpool, 600, $224) // 0x000000e0
+#define CONSTD_6554() CONST_GET_PTR(constpool, 664)
+CONST_DATA_U32(constpool, 664, $6554) // 0x0000199a
 
-#define CONSTD_0b11110000() CONST_GET_PTR(constpool, 604)
-CONST_DATA_U32(constpool, 604, $240) // 0x000000f0
+#define CONSTD_0x3F00() CONST_GET_PTR(constpool, 668)
+CONST_DATA_U32(constpool, 668, $16128) // 0x00003f00
 
-#define CONSTD_0b11111000() CONST_GET_PTR(constpool, 608)
-CONST_DATA_U32(constpool, 608, $248) // 0x000000f8
+#define CONSTD_0x3FFF() CONST_GET_PTR(constpool, 672)
+CONST_DATA_U32(constpool, 672, $16383) // 0x00003fff
 
-#define CONSTD_0xFF() CONST_GET_PTR(constpool, 612)
-CONST_DATA_U32(constpool, 612, $255) // 0x000000ff
+#define CONSTD_16388() CONST_GET_PTR(constpool, 676)
+CONST_DATA_U32(constpool, 676, $16388) // 0x00004004
 
-#define CONSTD_5243() CONST_GET_PTR(constpool, 616)
-CONST_DATA_U32(constpool, 616, $5243) // 0x0000147b
+#define CONSTD_0xD800() CONST_GET_PTR(constpool, 680)
+CONST_DATA_U32(constpool, 680, $55296) // 0x0000d800
 
-#define CONSTD_6554() CONST_GET_PTR(constpool, 620)
-CONST_DATA_U32(constpool, 620, $6554) // 0x0000199a
+#define CONSTD_0x10000() CONST_GET_PTR(constpool, 684)
+CONST_DATA_U32(constpool, 684, $65536) // 0x00010000
 
-#define CONSTD_0x3FFF() CONST_GET_PTR(constpool, 624)
-CONST_DATA_U32(constpool, 624, $16383) // 0x00003fff
+#define CONSTD_0x10101() CONST_GET_PTR(constpool, 688)
+CONST_DATA_U32(constpool, 688, $65793) // 0x00010101
 
-#define CONSTD_16388() CONST_GET_PTR(constpool, 628)
-CONST_DATA_U32(constpool, 628, $16388) // 0x00004004
+#define CONSTD_0x10801() CONST_GET_PTR(constpool, 692)
+CONST_DATA_U32(constpool, 692, $67585) // 0x00010801
 
-#define CONSTD_0x10101() CONST_GET_PTR(constpool, 632)
-CONST_DATA_U32(constpool, 632, $65793) // 0x00010101
+#define CONSTD_0x00011000() CONST_GET_PTR(constpool, 696)
+CONST_DATA_U32(constpool, 696, $69632) // 0x00011000
 
-#define CONSTD_0x10801() CONST_GET_PTR(constpool, 636)
-CONST_DATA_U32(constpool, 636, $67585) // 0x00010801
+#define CONSTD_0x3F0000() CONST_GET_PTR(constpool, 700)
+CONST_DATA_U32(constpool, 700, $4128768) // 0x003f0000
 
-#define CONSTD_0x400001() CONST_GET_PTR(constpool, 640)
-CONST_DATA_U32(constpool, 640, $4194305) // 0x00400001
+#define CONSTD_0x400001() CONST_GET_PTR(constpool, 704)
+CONST_DATA_U32(constpool, 704, $4194305) // 0x00400001
 
-#define CONSTD_0x007F007F() CONST_GET_PTR(constpool, 644)
-CONST_DATA_U32(constpool, 644, $8323199) // 0x007f007f
+#define CONSTD_0x007F007F() CONST_GET_PTR(constpool, 708)
+CONST_DATA_U32(constpool, 708, $8323199) // 0x007f007f
 
-#define CONSTD_0x01010101() CONST_GET_PTR(constpool, 648)
-CONST_DATA_U32(constpool, 648, $16843009) // 0x01010101
+#define CONSTD_0x01010101() CONST_GET_PTR(constpool, 712)
+CONST_DATA_U32(constpool, 712, $16843009) // 0x01010101
 
-#define CONSTD_134217727() CONST_GET_PTR(constpool, 652)
-CONST_DATA_U32(constpool, 652, $134217727) // 0x07ffffff
+#define CONSTD_0x01400140() CONST_GET_PTR(constpool, 716)
+CONST_DATA_U32(constpool, 716, $20971840) // 0x01400140
 
-#define CONSTD_0x0F0F0F0F() CONST_GET_PTR(constpool, 656)
-CONST_DATA_U32(constpool, 656, $252645135) // 0x0f0f0f0f
+#define CONSTD_134217727() CONST_GET_PTR(constpool, 720)
+CONST_DATA_U32(constpool, 720, $134217727) // 0x07ffffff
 
-#define CONSTD_0x3FFFFFFF() CONST_GET_PTR(constpool, 660)
-CONST_DATA_U32(constpool, 660, $1073741823) // 0x3fffffff
+#define CONSTD_0x0F0F0F0F() CONST_GET_PTR(constpool, 724)
+CONST_DATA_U32(constpool, 724, $252645135) // 0x0f0f0f0f
 
-#define CONSTD_UTF8_4B_MASK() CONST_GET_PTR(constpool, 664)
-CONST_DATA_U32(constpool, 664, $2155905264) // 0x808080f0
+#define CONSTD_0x3F000000() CONST_GET_PTR(constpool, 728)
+CONST_DATA_U32(constpool, 728, $1056964608) // 0x3f000000
 
-#define CONSTD_UTF8_3B_MASK() CONST_GET_PTR(constpool, 668)
-CONST_DATA_U32(constpool, 668, $2155929600) // 0x8080e000
+#define CONSTD_0x3F3F3F00() CONST_GET_PTR(constpool, 732)
+CONST_DATA_U32(constpool, 732, $1061109504) // 0x3f3f3f00
 
-#define CONSTD_UTF8_2B_MASK() CONST_GET_PTR(constpool, 672)
-CONST_DATA_U32(constpool, 672, $2160066560) // 0x80c00000
+#define CONSTD_0x3FFFFFFF() CONST_GET_PTR(constpool, 736)
+CONST_DATA_U32(constpool, 736, $1073741823) // 0x3fffffff
 
-#define CONSTD_0b11001110_01110011_10011100_11100111() CONST_GET_PTR(constpool, 676)
-CONST_DATA_U32(constpool, 676, $3463683303) // 0xce739ce7
+#define CONSTD_0x80808000() CONST_GET_PTR(constpool, 740)
+CONST_DATA_U32(constpool, 740, $2155905024) // 0x80808000
 
-#define CONSTD_0xFFFF0000() CONST_GET_PTR(constpool, 680)
-CONST_DATA_U32(constpool, 680, $4294901760) // 0xffff0000
+#define CONSTD_0x80808080() CONST_GET_PTR(constpool, 744)
+CONST_DATA_U32(constpool, 744, $2155905152) // 0x80808080
+
+#define CONSTD_UTF8_4B_MASK() CONST_GET_PTR(constpool, 748)
+CONST_DATA_U32(constpool, 748, $2155905264) // 0x808080f0
+
+#define CONSTD_UTF8_3B_MASK() CONST_GET_PTR(constpool, 752)
+CONST_DATA_U32(constpool, 752, $2155929600) // 0x8080e000
+
+#define CONSTD_UTF8_2B_MASK() CONST_GET_PTR(constpool, 756)
+CONST_DATA_U32(constpool, 756, $2160066560) // 0x80c00000
+
+#define CONSTD_0x9E3779B1() CONST_GET_PTR(constpool, 760)
+CONST_DATA_U32(constpool, 760, $2654435761) // 0x9e3779b1
+
+#define CONSTD_0xC0C0C0C0() CONST_GET_PTR(constpool, 764)
+CONST_DATA_U32(constpool, 764, $3233857728) // 0xc0c0c0c0
+
+#define CONSTD_0b11001110_01110011_10011100_11100111() CONST_GET_PTR(constpool, 768)
+CONST_DATA_U32(constpool, 768, $3463683303) // 0xce739ce7
+
+#define CONSTD_0xFFFF0000() CONST_GET_PTR(constpool, 772)
+CONST_DATA_U32(constpool, 772, $4294901760) // 0xffff0000
+
+#define CONSTD_0xFFFFF800() CONST_GET_PTR(constpool, 776)
+CONST_DATA_U32(constpool, 776, $4294965248) // 0xfffff800
+
+#define CONSTD_0xFFFFFF00() CONST_GET_PTR(constpool, 780)
+CONST_DATA_U32(constpool, 780, $4294967040) // 0xffffff00
 
 // uint8 constants
-#define CONSTB_97() CONST_GET_PTR(constpool, 684)
-CONST_DATA_U8(constpool, 684, $97) // 0x61
+#define CONSTB_97() CONST_GET_PTR(constpool, 784)
+CONST_DATA_U8(constpool, 784, $97) // 0x61
 
-#define CONSTB_122() CONST_GET_PTR(constpool, 685)
-CONST_DATA_U8(constpool, 685, $122) // 0x7a
+#define CONSTB_122() CONST_GET_PTR(constpool, 785)
+CONST_DATA_U8(constpool, 785, $122) // 0x7a
 
 // float32 constants
-#define CONSTF32_16_RECI() CONST_GET_PTR(constpool, 686)
-CONST_DATA_U32(constpool, 686, $0x000000003d800000) // float32(0.062500)
+#define CONSTF32_16_RECI() CONST_GET_PTR(constpool, 786)
+CONST_DATA_U32(constpool, 786, $0x000000003d800000) // float32(0.062500)
 
-#define CONSTF32_PI_TIMES_16_RECI() CONST_GET_PTR(constpool, 690)
-CONST_DATA_U32(constpool, 690, $0x000000003e490fdb) // float32(0.196350)
+#define CONSTF32_PI_TIMES_16_RECI() CONST_GET_PTR(constpool, 790)
+CONST_DATA_U32(constpool, 790, $0x000000003e490fdb) // float32(0.196350)
 
-#define CONSTF32_PI_RECI() CONST_GET_PTR(constpool, 694)
-CONST_DATA_U32(constpool, 694, $0x000000003ea2f983) // float32(0.318310)
+#define CONSTF32_PI_RECI() CONST_GET_PTR(constpool, 794)
+CONST_DATA_U32(constpool, 794, $0x000000003ea2f983) // float32(0.318310)
 
-#define CONSTF32_2_RECI() CONST_GET_PTR(constpool, 698)
-CONST_DATA_U32(constpool, 698, $0x000000003f000000) // float32(0.500000)
+#define CONSTF32_2_RECI() CONST_GET_PTR(constpool, 798)
+CONST_DATA_U32(constpool, 798, $0x000000003f000000) // float32(0.500000)
 
-#define CONSTF32_1() CONST_GET_PTR(constpool, 702)
-CONST_DATA_U32(constpool, 702, $0x000000003f800000) // float32(1.000000)
+#define CONSTF32_1() CONST_GET_PTR(constpool, 802)
+CONST_DATA_U32(constpool, 802, $0x000000003f800000) // float32(1.000000)
 
-#define CONSTF32_HALF_PI() CONST_GET_PTR(constpool, 706)
-CONST_DATA_U32(constpool, 706, $0x000000003fc90fdb) // float32(1.570796)
+#define CONSTF32_HALF_PI() CONST_GET_PTR(constpool, 806)
+CONST_DATA_U32(constpool, 806, $0x000000003fc90fdb) // float32(1.570796)
 
-#define CONSTF32_2() CONST_GET_PTR(constpool, 710)
-CONST_DATA_U32(constpool, 710, $0x0000000040000000) // float32(2.000000)
+#define CONSTF32_2() CONST_GET_PTR(constpool, 810)
+CONST_DATA_U32(constpool, 810, $0x0000000040000000) // float32(2.000000)
 
-#define CONSTF32_16_TIMES_PI_RECI() CONST_GET_PTR(constpool, 714)
-CONST_DATA_U32(constpool, 714, $0x0000000040a2f983) // float32(5.092958)
+#define CONSTF32_16_TIMES_PI_RECI() CONST_GET_PTR(constpool, 814)
+CONST_DATA_U32(constpool, 814, $0x0000000040a2f983) // float32(5.092958)
 
-#define CONSTF32_16() CONST_GET_PTR(constpool, 718)
-CONST_DATA_U32(constpool, 718, $0x0000000041800000) // float32(16.000000)
+#define CONSTF32_16() CONST_GET_PTR(constpool, 818)
+CONST_DATA_U32(constpool, 818, $0x0000000041800000) // float32(16.000000)
 
-#define CONSTF32_POSITIVE_INF() CONST_GET_PTR(constpool, 722)
-CONST_DATA_U32(constpool, 722, $0x000000007f800000) // float32(+Inf)
+#define CONSTF32_POSITIVE_INF() CONST_GET_PTR(constpool, 822)
+CONST_DATA_U32(constpool, 822, $0x000000007f800000) // float32(+Inf)
 
-#define CONSTF32_NEGATIVE_INF() CONST_GET_PTR(constpool, 726)
-CONST_DATA_U32(constpool, 726, $0x00000000ff800000) // float32(-Inf)
+#define CONSTF32_NEGATIVE_INF() CONST_GET_PTR(constpool, 826)
+CONST_DATA_U32(constpool, 826, $0x00000000ff800000) // float32(-Inf)
 
 // float64 constants
-#define CONSTF64_PI_DIV_180() CONST_GET_PTR(constpool, 730)
-CONST_DATA_U64(constpool, 730, $0x3f91df46a2529d39) // float64(0.017453)
-
-#define CONSTF64_HALF() CONST_GET_PTR(constpool, 738)
-CONST_DATA_U64(constpool, 738, $0x3fe0000000000000) // float64(0.500000)
+#define CONSTF64_PI_DIV_180() CONST_GET_PTR(constpool, 830)
+CONST_DATA_U64(constpool, 830, $0x3f91df46a2529d39) // float64(0.017453)
 
-#define CONSTF64_0p9999() CONST_GET_PTR(constpool, 746)
-CONST_DATA_U64(constpool, 746, $0x3fefff2e48e8a71e) // float64(0.999900)
+#define CONSTF64_HALF() CONST_GET_PTR(constpool, 838)
+CONST_DATA_U64(constpool, 838, $0x3fe0000000000000) // float64(0.500000)
 
-#define CONSTF64_1() CONST_GET_PTR(constpool, 754)
-CONST_DATA_U64(constpool, 754, $0x3ff0000000000000) // float64(1.000000)
+#define CONSTF64_0p9999() CONST_GET_PTR(constpool, 846)
+CONST_DATA_U64(constpool, 846, $0x3fefff2e48e8a71e) // float64(0.999900)
 
-#define CONSTF64_4() CONST_GET_PTR(constpool, 762)
-CONST_DATA_U64(constpool, 762, $0x4010000000000000) // float64(4.000000)
+#define CONSTF64_4() CONST_GET_PTR(constpool, 854)
+CONST_DATA_U64(constpool, 854, $0x4010000000000000) // float64(4.000000)
 
-#define CONSTF64_7() CONST_GET_PTR(constpool, 770)
-CONST_DATA_U64(constpool, 770, $0x401c000000000000) // float64(7.000000)
+#define CONSTF64_7() CONST_GET_PTR(constpool, 862)
+CONST_DATA_U64(constpool, 862, $0x401c000000000000) // float64(7.000000)
 
-#define CONSTF64_11() CONST_GET_PTR(constpool, 778)
-CONST_DATA_U64(constpool, 778, $0x4026000000000000) // float64(11.000000)
+#define CONSTF64_11() CONST_GET_PTR(constpool, 870)
+CONST_DATA_U64(constpool, 870, $0x4026000000000000) // float64(11.000000)
 
-#define CONSTF64_12() CONST_GET_PTR(constpool, 786)
-CONST_DATA_U64(constpool, 786, $0x4028000000000000) // float64(12.000000)
+#define CONSTF64_12() CONST_GET_PTR(constpool, 878)
+CONST_DATA_U64(constpool, 878, $0x4028000000000000) // float64(12.000000)
 
-#define CONSTF64_65536() CONST_GET_PTR(constpool, 794)
-CONST_DATA_U64(constpool, 794, $0x40f0000000000000) // float64(65536.000000)
+#define CONSTF64_65536() CONST_GET_PTR(constpool, 886)
+CONST_DATA_U64(constpool, 886, $0x40f0000000000000) // float64(65536.000000)
 
-#define CONSTF64_MICROSECONDS_IN_1_DAY_SHR_13() CONST_GET_PTR(constpool, 802)
-CONST_DATA_U64(constpool, 802, $0x41641dd760000000) // float64(10546875.000000)
+#define CONSTF64_MICROSECONDS_IN_1_DAY_SHR_13() CONST_GET_PTR(constpool, 894)
+CONST_DATA_U64(constpool, 894, $0x41641dd760000000) // float64(10546875.000000)
 
-#define CONSTF64_12742000() CONST_GET_PTR(constpool, 810)
-CONST_DATA_U64(constpool, 810, $0x41684dae00000000) // float64(12742000.000000)
+#define CONSTF64_12742000() CONST_GET_PTR(constpool, 902)
+CONST_DATA_U64(constpool, 902, $0x41684dae00000000) // float64(12742000.000000)
 
-#define CONSTF64_100000000() CONST_GET_PTR(constpool, 818)
-CONST_DATA_U64(constpool, 818, $0x4197d78400000000) // float64(100000000.000000)
+#define CONSTF64_100000000() CONST_GET_PTR(constpool, 910)
+CONST_DATA_U64(constpool, 910, $0x4197d78400000000) // float64(100000000.000000)
 
-#define CONSTF64_152587890625() CONST_GET_PTR(constpool, 826)
-CONST_DATA_U64(constpool, 826, $0x4241c37937e08000) // float64(152587890625.000000)
+#define CONSTF64_152587890625() CONST_GET_PTR(constpool, 918)
+CONST_DATA_U64(constpool, 918, $0x4241c37937e08000) // float64(152587890625.000000)
 
-#define CONSTF64_281474976710656_DIV_360() CONST_GET_PTR(constpool, 834)
-CONST_DATA_U64(constpool, 834, $0x4266c16c16c16c17) // float64(781874935307.377808)
+#define CONSTF64_281474976710656_DIV_360() CONST_GET_PTR(constpool, 926)
+CONST_DATA_U64(constpool, 926, $0x4266c16c16c16c17) // float64(781874935307.377808)
 
-#define CONSTF64_281474976710656_DIV_4PI() CONST_GET_PTR(constpool, 842)
-CONST_DATA_U64(constpool, 842, $0x42b45f306dc9c883) // float64(22399066950088.511719)
+#define CONSTF64_281474976710656_DIV_4PI() CONST_GET_PTR(constpool, 934)
+CONST_DATA_U64(constpool, 934, $0x42b45f306dc9c883) // float64(22399066950088.511719)
 
-#define CONSTF64_140737488355328() CONST_GET_PTR(constpool, 850)
-CONST_DATA_U64(constpool, 850, $0x42e0000000000000) // float64(140737488355328.000000)
+#define CONSTF64_140737488355328() CONST_GET_PTR(constpool, 942)
+CONST_DATA_U64(constpool, 942, $0x42e0000000000000) // float64(140737488355328.000000)
 
-#define CONSTF64_POSITIVE_INF() CONST_GET_PTR(constpool, 858)
-CONST_DATA_U64(constpool, 858, $0x7ff0000000000000) // float64(+Inf)
+#define CONSTF64_POSITIVE_INF() CONST_GET_PTR(constpool, 950)
+CONST_DATA_U64(constpool, 950, $0x7ff0000000000000) // float64(+Inf)
 
-#define CONSTF64_NAN() CONST_GET_PTR(constpool, 866)
-CONST_DATA_U64(constpool, 866, $0x7ff8000000000001) // float64(NaN)
+#define CONSTF64_NAN() CONST_GET_PTR(constpool, 958)
+CONST_DATA_U64(constpool, 958, $0x7ff8000000000001) // float64(NaN)
 
-#define CONSTF64_MINUS_0p9999() CONST_GET_PTR(constpool, 874)
-CONST_DATA_U64(constpool, 874, $0xbfefff2e48e8a71e) // float64(-0.999900)
+#define CONSTF64_MINUS_0p9999() CONST_GET_PTR(constpool, 966)
+CONST_DATA_U64(constpool, 966, $0xbfefff2e48e8a71e) // float64(-0.999900)
 
-#define CONSTF64_NEGATIVE_INF() CONST_GET_PTR(constpool, 882)
-CONST_DATA_U64(constpool, 882, $0xfff0000000000000) // float64(-Inf)
+#define CONSTF64_NEGATIVE_INF() CONST_GET_PTR(constpool, 974)
+CONST_DATA_U64(constpool, 974, $0xfff0000000000000) // float64(-Inf)
 
-CONST_GLOBAL(constpool, $890)
+CONST_GLOBAL(constpool, $982)
@@ -25,10 +25,7 @@ func bufferMinFloat64(dst, src []byte) {
 
 	a := math.Float64frombits(binary.LittleEndian.Uint64(dst))
 	b := math.Float64frombits(binary.LittleEndian.Uint64(src))
-	result := a
-	if b < a {
-		result = b
-	}
+	result := math.Min(a, b)
 	binary.LittleEndian.PutUint64(dst, math.Float64bits(result))
 }
 
@@ -38,13 +35,33 @@ func bufferMaxFloat64(dst, src []byte) {
 
 	a := math.Float64frombits(binary.LittleEndian.Uint64(dst))
 	b := math.Float64frombits(binary.LittleEndian.Uint64(src))
-	result := a
-	if b > a {
-		result = b
-	}
+	result := math.Max(a, b)
 	binary.LittleEndian.PutUint64(dst, math.Float64bits(result))
 }
 
+// addInt64 returns a + b and whether
+// the addition did not overflow
+func addInt64(a, b int64) (int64, bool) {
+	r := a + b
+	return r, (a^r)&(b^r) >= 0
+}
+
+// bufferAddInt64Checked is bufferAddInt64 for
+// signed accumulators; it returns false and leaves
+// dst unchanged if the sum overflows
+func bufferAddInt64Checked(dst, src []byte) bool {
+	_ = dst[:8]
+	_ = src[:8]
+
+	a := int64(binary.LittleEndian.Uint64(dst))
+	b := int64(binary.LittleEndian.Uint64(src))
+	result, ok := addInt64(a, b)
+	if ok {
+		binary.LittleEndian.PutUint64(dst, uint64(result))
+	}
+	return ok
+}
+
 func bufferAddInt64(dst, src []byte) {
 	_ = dst[:8]
 	_ = src[:8]
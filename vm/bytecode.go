@@ -21,6 +21,7 @@ import (
 	"strings"
 	"unsafe"
 
+	"github.com/SnellerInc/sneller/expr"
 	"github.com/SnellerInc/sneller/ion"
 )
 
@@ -38,6 +39,11 @@ const bcSlotSize = 2                    // stack slot size in bytes
 const bcLaneCount = 16                  // number of lanes processed per iteration
 const bcLaneCountMask = bcLaneCount - 1 // number of lanes as mask
 
+// lane masks (kRegData, bytecode.vmState) are uint16s,
+// so bcLaneCount cannot exceed 16; the portable interpreter
+// otherwise only depends on bcLaneCount (see portableBatch)
+var _ [16 - bcLaneCount]struct{}
+
 // actual bytecode constants are generated automatically
 // by reading the assembly source and generating a named
 // constant for each bytecode function
@@ -161,6 +167,12 @@ type bcopinfo struct {
 	va       []bcArgType
 	scratch  int // desired scratch space (up to PageSize)
 	portable opfn
+	// goonly is set for ops that only have a portable
+	// implementation that cannot be reached from the
+	// assembly interpreter via BC_CALL_PORTABLE();
+	// programs that use them are always executed by
+	// the portable interpreter
+	goonly bool
 }
 
 func (op bcop) scratch() int { return opinfo[op].scratch }
@@ -195,6 +207,9 @@ const (
 	// there was no symbol table
 	bcerrNullSymbolTable
 	bcerrNotSupported
+	// IntOverflow is returned when an integer
+	// SUM or AVG accumulator overflows int64
+	bcerrIntOverflow
 )
 
 func (b bcerr) Error() string {
@@ -211,6 +226,8 @@ func (b bcerr) Error() string {
 		return "null symbol table"
 	case bcerrNotSupported:
 		return "bytecode op not supported in portable mode"
+	case bcerrIntOverflow:
+		return "integer overflow in SUM or AVG"
 	default:
 		return "unknown bytecode error"
 	}
@@ -219,6 +236,7 @@ func (b bcerr) Error() string {
 func (b *bytecode) prepare(rp *rowParams) {
 	b.auxvals = rp.auxbound
 	b.auxpos = 0
+	b.random = rp.pos
 }
 
 type interpreterState struct {
@@ -263,6 +281,10 @@ type bytecode struct {
 
 	vstacksize int
 
+	// goonly is set if the program contains an op
+	// that can only be executed by the portable interpreter
+	goonly bool
+
 	// set from abort handlers
 	err   bcerr
 	errpc int32
@@ -272,9 +294,23 @@ type bytecode struct {
 	// mask used to abort 'opaggbucket' in case one or more bucket wasn't found.
 	missingBucketMask uint16
 
+	// random is the position of the row in the
+	// first lane evaluated by 'oprandom'; it is set
+	// from rowParams.pos by prepare and advanced by
+	// the number of lanes each time 'oprandom' runs
+	random uint64
+
 	// currently only used by the interpreter to hold states that are otherwise
 	// passed / retrieved in registers
 	vmState interpreterState
+
+	// lambdas are the programs evaluated
+	// by optransform, indexed by its immediate
+	lambdas []*bytecode
+
+	// jsonpaths are the parsed paths used
+	// by opjsonextract, indexed by its immediate
+	jsonpaths [][]expr.JSONPathStep
 }
 
 type bcFormatFlags uint
@@ -579,6 +615,9 @@ func (b *bytecode) dropScratch() {
 	b.scratch = nil
 	// this will trigger a fault if it is used:
 	b.scratchoff = 0x80000000
+	for i := range b.lambdas {
+		b.lambdas[i].dropScratch()
+	}
 }
 
 // restoreScratch updates the scratch state in b
@@ -586,6 +625,9 @@ func (b *bytecode) dropScratch() {
 // from the symbol table's spare pages
 func (b *bytecode) restoreScratch(st *symtab) {
 	b.symtab = st.symrefs
+	for i := range b.lambdas {
+		b.lambdas[i].restoreScratch(st)
+	}
 	if b.scratchtotal == 0 {
 		// this will trigger a fault if it is used:
 		b.scratchoff = 0x80000000
@@ -598,6 +640,12 @@ func (b *bytecode) restoreScratch(st *symtab) {
 	b.scratchoff, _ = vmdispl(b.scratch[:1])
 }
 
+// useAsm returns whether b should be executed
+// by the assembly implementation of the interpreter
+func (b *bytecode) useAsm() bool {
+	return globalOptimizationLevel >= OptimizationLevelAVX512V1 && !b.goonly
+}
+
 func (b *bytecode) reset() {
 	*b = bytecode{}
 }
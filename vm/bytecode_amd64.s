@@ -200,156 +200,156 @@ DATA opaddrs+0x610(SB)/8, $bcdatetruncquarter(SB)
 DATA opaddrs+0x618(SB)/8, $bcdatetruncyear(SB)
 DATA opaddrs+0x620(SB)/8, $bcunboxts(SB)
 DATA opaddrs+0x628(SB)/8, $bcboxts(SB)
-DATA opaddrs+0x630(SB)/8, $bcwidthbucketf64(SB)
-DATA opaddrs+0x638(SB)/8, $bcwidthbucketi64(SB)
-DATA opaddrs+0x640(SB)/8, $bctimebucketts(SB)
-DATA opaddrs+0x648(SB)/8, $bcgeohash(SB)
-DATA opaddrs+0x650(SB)/8, $bcgeohashimm(SB)
-DATA opaddrs+0x658(SB)/8, $bcgeotilex(SB)
-DATA opaddrs+0x660(SB)/8, $bcgeotiley(SB)
-DATA opaddrs+0x668(SB)/8, $bcgeotilees(SB)
-DATA opaddrs+0x670(SB)/8, $bcgeotileesimm(SB)
-DATA opaddrs+0x678(SB)/8, $bcgeodistance(SB)
-DATA opaddrs+0x680(SB)/8, $bcalloc(SB)
-DATA opaddrs+0x688(SB)/8, $bcconcatstr(SB)
-DATA opaddrs+0x690(SB)/8, $bcfindsym(SB)
-DATA opaddrs+0x698(SB)/8, $bcfindsym2(SB)
-DATA opaddrs+0x6a0(SB)/8, $bcblendv(SB)
-DATA opaddrs+0x6a8(SB)/8, $bcblendf64(SB)
-DATA opaddrs+0x6b0(SB)/8, $bcunpack(SB)
-DATA opaddrs+0x6b8(SB)/8, $bcunsymbolize(SB)
-DATA opaddrs+0x6c0(SB)/8, $bcunboxktoi64(SB)
-DATA opaddrs+0x6c8(SB)/8, $bcunboxcoercef64(SB)
-DATA opaddrs+0x6d0(SB)/8, $bcunboxcoercei64(SB)
-DATA opaddrs+0x6d8(SB)/8, $bcunboxcvtf64(SB)
-DATA opaddrs+0x6e0(SB)/8, $bcunboxcvti64(SB)
-DATA opaddrs+0x6e8(SB)/8, $bcboxf64(SB)
-DATA opaddrs+0x6f0(SB)/8, $bcboxi64(SB)
-DATA opaddrs+0x6f8(SB)/8, $bcboxk(SB)
-DATA opaddrs+0x700(SB)/8, $bcboxstr(SB)
-DATA opaddrs+0x708(SB)/8, $bcboxlist(SB)
-DATA opaddrs+0x710(SB)/8, $bcmakelist(SB)
-DATA opaddrs+0x718(SB)/8, $bcmakestruct(SB)
-DATA opaddrs+0x720(SB)/8, $bchashvalue(SB)
-DATA opaddrs+0x728(SB)/8, $bchashvalueplus(SB)
-DATA opaddrs+0x730(SB)/8, $bchashmember(SB)
-DATA opaddrs+0x738(SB)/8, $bchashlookup(SB)
-DATA opaddrs+0x740(SB)/8, $bcaggandk(SB)
-DATA opaddrs+0x748(SB)/8, $bcaggork(SB)
-DATA opaddrs+0x750(SB)/8, $bcaggslotsumf(SB)
-DATA opaddrs+0x758(SB)/8, $bcaggsumf(SB)
-DATA opaddrs+0x760(SB)/8, $bcaggsumi(SB)
-DATA opaddrs+0x768(SB)/8, $bcaggminf(SB)
-DATA opaddrs+0x770(SB)/8, $bcaggmini(SB)
-DATA opaddrs+0x778(SB)/8, $bcaggmaxf(SB)
-DATA opaddrs+0x780(SB)/8, $bcaggmaxi(SB)
-DATA opaddrs+0x788(SB)/8, $bcaggandi(SB)
-DATA opaddrs+0x790(SB)/8, $bcaggori(SB)
-DATA opaddrs+0x798(SB)/8, $bcaggxori(SB)
-DATA opaddrs+0x7a0(SB)/8, $bcaggcount(SB)
-DATA opaddrs+0x7a8(SB)/8, $bcaggmergestate(SB)
-DATA opaddrs+0x7b0(SB)/8, $bcaggbucket(SB)
-DATA opaddrs+0x7b8(SB)/8, $bcaggslotandk(SB)
-DATA opaddrs+0x7c0(SB)/8, $bcaggslotork(SB)
-DATA opaddrs+0x7c8(SB)/8, $bcaggslotsumi(SB)
-DATA opaddrs+0x7d0(SB)/8, $bcaggslotavgf(SB)
-DATA opaddrs+0x7d8(SB)/8, $bcaggslotavgi(SB)
-DATA opaddrs+0x7e0(SB)/8, $bcaggslotminf(SB)
-DATA opaddrs+0x7e8(SB)/8, $bcaggslotmini(SB)
-DATA opaddrs+0x7f0(SB)/8, $bcaggslotmaxf(SB)
-DATA opaddrs+0x7f8(SB)/8, $bcaggslotmaxi(SB)
-DATA opaddrs+0x800(SB)/8, $bcaggslotandi(SB)
-DATA opaddrs+0x808(SB)/8, $bcaggslotori(SB)
-DATA opaddrs+0x810(SB)/8, $bcaggslotxori(SB)
-DATA opaddrs+0x818(SB)/8, $bcaggslotcount(SB)
-DATA opaddrs+0x820(SB)/8, $bcaggslotcount_v2(SB)
-DATA opaddrs+0x828(SB)/8, $bcaggslotmergestate(SB)
-DATA opaddrs+0x830(SB)/8, $bclitref(SB)
-DATA opaddrs+0x838(SB)/8, $bcauxval(SB)
-DATA opaddrs+0x840(SB)/8, $bcsplit(SB)
-DATA opaddrs+0x848(SB)/8, $bctuple(SB)
-DATA opaddrs+0x850(SB)/8, $bcmovk(SB)
-DATA opaddrs+0x858(SB)/8, $bczerov(SB)
-DATA opaddrs+0x860(SB)/8, $bcmovv(SB)
-DATA opaddrs+0x868(SB)/8, $bcmovvk(SB)
-DATA opaddrs+0x870(SB)/8, $bcmovf64(SB)
-DATA opaddrs+0x878(SB)/8, $bcmovi64(SB)
-DATA opaddrs+0x880(SB)/8, $bcobjectsize(SB)
-DATA opaddrs+0x888(SB)/8, $bcarraysize(SB)
-DATA opaddrs+0x890(SB)/8, $bcarrayposition(SB)
-DATA opaddrs+0x898(SB)/8, $bcarraysum(SB)
-DATA opaddrs+0x8a0(SB)/8, $bcvectorinnerproduct(SB)
-DATA opaddrs+0x8a8(SB)/8, $bcvectorinnerproductimm(SB)
-DATA opaddrs+0x8b0(SB)/8, $bcvectorl1distance(SB)
-DATA opaddrs+0x8b8(SB)/8, $bcvectorl1distanceimm(SB)
-DATA opaddrs+0x8c0(SB)/8, $bcvectorl2distance(SB)
-DATA opaddrs+0x8c8(SB)/8, $bcvectorl2distanceimm(SB)
-DATA opaddrs+0x8d0(SB)/8, $bcvectorcosinedistance(SB)
-DATA opaddrs+0x8d8(SB)/8, $bcvectorcosinedistanceimm(SB)
-DATA opaddrs+0x8e0(SB)/8, $bcCmpStrEqCs(SB)
-DATA opaddrs+0x8e8(SB)/8, $bcCmpStrEqCi(SB)
-DATA opaddrs+0x8f0(SB)/8, $bcCmpStrEqUTF8Ci(SB)
-DATA opaddrs+0x8f8(SB)/8, $bcCmpStrFuzzyA3(SB)
-DATA opaddrs+0x900(SB)/8, $bcCmpStrFuzzyUnicodeA3(SB)
-DATA opaddrs+0x908(SB)/8, $bcHasSubstrFuzzyA3(SB)
-DATA opaddrs+0x910(SB)/8, $bcHasSubstrFuzzyUnicodeA3(SB)
-DATA opaddrs+0x918(SB)/8, $bcSkip1charLeft(SB)
-DATA opaddrs+0x920(SB)/8, $bcSkip1charRight(SB)
-DATA opaddrs+0x928(SB)/8, $bcSkipNcharLeft(SB)
-DATA opaddrs+0x930(SB)/8, $bcSkipNcharRight(SB)
-DATA opaddrs+0x938(SB)/8, $bcTrimWsLeft(SB)
-DATA opaddrs+0x940(SB)/8, $bcTrimWsRight(SB)
-DATA opaddrs+0x948(SB)/8, $bcTrim4charLeft(SB)
-DATA opaddrs+0x950(SB)/8, $bcTrim4charRight(SB)
-DATA opaddrs+0x958(SB)/8, $bcoctetlength(SB)
-DATA opaddrs+0x960(SB)/8, $bccharlength(SB)
-DATA opaddrs+0x968(SB)/8, $bcSubstr(SB)
-DATA opaddrs+0x970(SB)/8, $bcSplitPart(SB)
-DATA opaddrs+0x978(SB)/8, $bcContainsPrefixCs(SB)
-DATA opaddrs+0x980(SB)/8, $bcContainsPrefixCi(SB)
-DATA opaddrs+0x988(SB)/8, $bcContainsPrefixUTF8Ci(SB)
-DATA opaddrs+0x990(SB)/8, $bcContainsSuffixCs(SB)
-DATA opaddrs+0x998(SB)/8, $bcContainsSuffixCi(SB)
-DATA opaddrs+0x9a0(SB)/8, $bcContainsSuffixUTF8Ci(SB)
-DATA opaddrs+0x9a8(SB)/8, $bcContainsSubstrCs(SB)
-DATA opaddrs+0x9b0(SB)/8, $bcContainsSubstrCi(SB)
-DATA opaddrs+0x9b8(SB)/8, $bcContainsSubstrUTF8Ci(SB)
-DATA opaddrs+0x9c0(SB)/8, $bcEqPatternCs(SB)
-DATA opaddrs+0x9c8(SB)/8, $bcEqPatternCi(SB)
-DATA opaddrs+0x9d0(SB)/8, $bcEqPatternUTF8Ci(SB)
-DATA opaddrs+0x9d8(SB)/8, $bcContainsPatternCs(SB)
-DATA opaddrs+0x9e0(SB)/8, $bcContainsPatternCi(SB)
-DATA opaddrs+0x9e8(SB)/8, $bcContainsPatternUTF8Ci(SB)
-DATA opaddrs+0x9f0(SB)/8, $bcIsSubnetOfIP4(SB)
-DATA opaddrs+0x9f8(SB)/8, $bcDfaT6(SB)
-DATA opaddrs+0xa00(SB)/8, $bcDfaT7(SB)
-DATA opaddrs+0xa08(SB)/8, $bcDfaT8(SB)
-DATA opaddrs+0xa10(SB)/8, $bcDfaT6Z(SB)
-DATA opaddrs+0xa18(SB)/8, $bcDfaT7Z(SB)
-DATA opaddrs+0xa20(SB)/8, $bcDfaT8Z(SB)
-DATA opaddrs+0xa28(SB)/8, $bcDfaLZ(SB)
-DATA opaddrs+0xa30(SB)/8, $bcAggTDigest(SB)
-DATA opaddrs+0xa38(SB)/8, $bcslower(SB)
-DATA opaddrs+0xa40(SB)/8, $bcsupper(SB)
-DATA opaddrs+0xa48(SB)/8, $bcaggapproxcount(SB)
-DATA opaddrs+0xa50(SB)/8, $bcaggslotapproxcount(SB)
-DATA opaddrs+0xa58(SB)/8, $bcpowuintf64(SB)
-DATA opaddrs+0xa60(SB)/8, $bctrap(SB)
-DATA opaddrs+0xa68(SB)/8, $bctrap(SB)
-DATA opaddrs+0xa70(SB)/8, $bctrap(SB)
-DATA opaddrs+0xa78(SB)/8, $bctrap(SB)
-DATA opaddrs+0xa80(SB)/8, $bctrap(SB)
-DATA opaddrs+0xa88(SB)/8, $bctrap(SB)
-DATA opaddrs+0xa90(SB)/8, $bctrap(SB)
-DATA opaddrs+0xa98(SB)/8, $bctrap(SB)
-DATA opaddrs+0xaa0(SB)/8, $bctrap(SB)
-DATA opaddrs+0xaa8(SB)/8, $bctrap(SB)
-DATA opaddrs+0xab0(SB)/8, $bctrap(SB)
-DATA opaddrs+0xab8(SB)/8, $bctrap(SB)
-DATA opaddrs+0xac0(SB)/8, $bctrap(SB)
-DATA opaddrs+0xac8(SB)/8, $bctrap(SB)
-DATA opaddrs+0xad0(SB)/8, $bctrap(SB)
-DATA opaddrs+0xad8(SB)/8, $bctrap(SB)
+DATA opaddrs+0x630(SB)/8, $bcrandom(SB)
+DATA opaddrs+0x638(SB)/8, $bcwidthbucketf64(SB)
+DATA opaddrs+0x640(SB)/8, $bcwidthbucketi64(SB)
+DATA opaddrs+0x648(SB)/8, $bctimebucketts(SB)
+DATA opaddrs+0x650(SB)/8, $bcgeohash(SB)
+DATA opaddrs+0x658(SB)/8, $bcgeohashimm(SB)
+DATA opaddrs+0x660(SB)/8, $bcgeotilex(SB)
+DATA opaddrs+0x668(SB)/8, $bcgeotiley(SB)
+DATA opaddrs+0x670(SB)/8, $bcgeotilees(SB)
+DATA opaddrs+0x678(SB)/8, $bcgeotileesimm(SB)
+DATA opaddrs+0x680(SB)/8, $bcgeodistance(SB)
+DATA opaddrs+0x688(SB)/8, $bcalloc(SB)
+DATA opaddrs+0x690(SB)/8, $bcconcatstr(SB)
+DATA opaddrs+0x698(SB)/8, $bcfindsym(SB)
+DATA opaddrs+0x6a0(SB)/8, $bcfindsym2(SB)
+DATA opaddrs+0x6a8(SB)/8, $bcblendv(SB)
+DATA opaddrs+0x6b0(SB)/8, $bcblendf64(SB)
+DATA opaddrs+0x6b8(SB)/8, $bcunpack(SB)
+DATA opaddrs+0x6c0(SB)/8, $bcunsymbolize(SB)
+DATA opaddrs+0x6c8(SB)/8, $bcunboxktoi64(SB)
+DATA opaddrs+0x6d0(SB)/8, $bcunboxcoercef64(SB)
+DATA opaddrs+0x6d8(SB)/8, $bcunboxcoercei64(SB)
+DATA opaddrs+0x6e0(SB)/8, $bcunboxcvtf64(SB)
+DATA opaddrs+0x6e8(SB)/8, $bcunboxcvti64(SB)
+DATA opaddrs+0x6f0(SB)/8, $bcboxf64(SB)
+DATA opaddrs+0x6f8(SB)/8, $bcboxi64(SB)
+DATA opaddrs+0x700(SB)/8, $bcboxk(SB)
+DATA opaddrs+0x708(SB)/8, $bcboxstr(SB)
+DATA opaddrs+0x710(SB)/8, $bcboxlist(SB)
+DATA opaddrs+0x718(SB)/8, $bcjsonextract(SB)
+DATA opaddrs+0x720(SB)/8, $bcparsets(SB)
+DATA opaddrs+0x728(SB)/8, $bcformatts(SB)
+DATA opaddrs+0x730(SB)/8, $bcmakelist(SB)
+DATA opaddrs+0x738(SB)/8, $bcmakestruct(SB)
+DATA opaddrs+0x740(SB)/8, $bchashvalue(SB)
+DATA opaddrs+0x748(SB)/8, $bchashvalueplus(SB)
+DATA opaddrs+0x750(SB)/8, $bchashmember(SB)
+DATA opaddrs+0x758(SB)/8, $bchashlookup(SB)
+DATA opaddrs+0x760(SB)/8, $bchashbucket(SB)
+DATA opaddrs+0x768(SB)/8, $bcaggandk(SB)
+DATA opaddrs+0x770(SB)/8, $bcaggork(SB)
+DATA opaddrs+0x778(SB)/8, $bcaggslotsumf(SB)
+DATA opaddrs+0x780(SB)/8, $bcaggsumf(SB)
+DATA opaddrs+0x788(SB)/8, $bcaggsumi(SB)
+DATA opaddrs+0x790(SB)/8, $bcaggminf(SB)
+DATA opaddrs+0x798(SB)/8, $bcaggmini(SB)
+DATA opaddrs+0x7a0(SB)/8, $bcaggmaxf(SB)
+DATA opaddrs+0x7a8(SB)/8, $bcaggmaxi(SB)
+DATA opaddrs+0x7b0(SB)/8, $bcaggandi(SB)
+DATA opaddrs+0x7b8(SB)/8, $bcaggori(SB)
+DATA opaddrs+0x7c0(SB)/8, $bcaggxori(SB)
+DATA opaddrs+0x7c8(SB)/8, $bcaggcount(SB)
+DATA opaddrs+0x7d0(SB)/8, $bcaggmergestate(SB)
+DATA opaddrs+0x7d8(SB)/8, $bcaggbucket(SB)
+DATA opaddrs+0x7e0(SB)/8, $bcaggbucketbool(SB)
+DATA opaddrs+0x7e8(SB)/8, $bcaggslotandk(SB)
+DATA opaddrs+0x7f0(SB)/8, $bcaggslotork(SB)
+DATA opaddrs+0x7f8(SB)/8, $bcaggslotsumi(SB)
+DATA opaddrs+0x800(SB)/8, $bcaggslotavgf(SB)
+DATA opaddrs+0x808(SB)/8, $bcaggslotavgi(SB)
+DATA opaddrs+0x810(SB)/8, $bcaggslotminf(SB)
+DATA opaddrs+0x818(SB)/8, $bcaggslotmini(SB)
+DATA opaddrs+0x820(SB)/8, $bcaggslotmaxf(SB)
+DATA opaddrs+0x828(SB)/8, $bcaggslotmaxi(SB)
+DATA opaddrs+0x830(SB)/8, $bcaggslotandi(SB)
+DATA opaddrs+0x838(SB)/8, $bcaggslotori(SB)
+DATA opaddrs+0x840(SB)/8, $bcaggslotxori(SB)
+DATA opaddrs+0x848(SB)/8, $bcaggslotcount(SB)
+DATA opaddrs+0x850(SB)/8, $bcaggslotcount_v2(SB)
+DATA opaddrs+0x858(SB)/8, $bcaggslotmergestate(SB)
+DATA opaddrs+0x860(SB)/8, $bcaggmode(SB)
+DATA opaddrs+0x868(SB)/8, $bcaggslotmode(SB)
+DATA opaddrs+0x870(SB)/8, $bclitref(SB)
+DATA opaddrs+0x878(SB)/8, $bcauxval(SB)
+DATA opaddrs+0x880(SB)/8, $bcsplit(SB)
+DATA opaddrs+0x888(SB)/8, $bctuple(SB)
+DATA opaddrs+0x890(SB)/8, $bcmovk(SB)
+DATA opaddrs+0x898(SB)/8, $bczerov(SB)
+DATA opaddrs+0x8a0(SB)/8, $bcmovv(SB)
+DATA opaddrs+0x8a8(SB)/8, $bcmovvk(SB)
+DATA opaddrs+0x8b0(SB)/8, $bcmovf64(SB)
+DATA opaddrs+0x8b8(SB)/8, $bcmovi64(SB)
+DATA opaddrs+0x8c0(SB)/8, $bcobjectsize(SB)
+DATA opaddrs+0x8c8(SB)/8, $bcarraysize(SB)
+DATA opaddrs+0x8d0(SB)/8, $bcarrayposition(SB)
+DATA opaddrs+0x8d8(SB)/8, $bcarraycontains(SB)
+DATA opaddrs+0x8e0(SB)/8, $bcarrayindex(SB)
+DATA opaddrs+0x8e8(SB)/8, $bcarrayslice(SB)
+DATA opaddrs+0x8f0(SB)/8, $bctransform(SB)
+DATA opaddrs+0x8f8(SB)/8, $bcarraysum(SB)
+DATA opaddrs+0x900(SB)/8, $bcvectorinnerproduct(SB)
+DATA opaddrs+0x908(SB)/8, $bcvectorinnerproductimm(SB)
+DATA opaddrs+0x910(SB)/8, $bcvectorl1distance(SB)
+DATA opaddrs+0x918(SB)/8, $bcvectorl1distanceimm(SB)
+DATA opaddrs+0x920(SB)/8, $bcvectorl2distance(SB)
+DATA opaddrs+0x928(SB)/8, $bcvectorl2distanceimm(SB)
+DATA opaddrs+0x930(SB)/8, $bcvectorcosinedistance(SB)
+DATA opaddrs+0x938(SB)/8, $bcvectorcosinedistanceimm(SB)
+DATA opaddrs+0x940(SB)/8, $bcCmpStrEqCs(SB)
+DATA opaddrs+0x948(SB)/8, $bcCmpStrEqCi(SB)
+DATA opaddrs+0x950(SB)/8, $bcCmpStrEqUTF8Ci(SB)
+DATA opaddrs+0x958(SB)/8, $bcCmpStrFuzzyA3(SB)
+DATA opaddrs+0x960(SB)/8, $bcCmpStrFuzzyUnicodeA3(SB)
+DATA opaddrs+0x968(SB)/8, $bcHasSubstrFuzzyA3(SB)
+DATA opaddrs+0x970(SB)/8, $bcHasSubstrFuzzyUnicodeA3(SB)
+DATA opaddrs+0x978(SB)/8, $bcSkip1charLeft(SB)
+DATA opaddrs+0x980(SB)/8, $bcSkip1charRight(SB)
+DATA opaddrs+0x988(SB)/8, $bcSkipNcharLeft(SB)
+DATA opaddrs+0x990(SB)/8, $bcSkipNcharRight(SB)
+DATA opaddrs+0x998(SB)/8, $bcTrimWsLeft(SB)
+DATA opaddrs+0x9a0(SB)/8, $bcTrimWsRight(SB)
+DATA opaddrs+0x9a8(SB)/8, $bcTrimWsBoth(SB)
+DATA opaddrs+0x9b0(SB)/8, $bcTrim4charLeft(SB)
+DATA opaddrs+0x9b8(SB)/8, $bcTrim4charRight(SB)
+DATA opaddrs+0x9c0(SB)/8, $bcoctetlength(SB)
+DATA opaddrs+0x9c8(SB)/8, $bccharlength(SB)
+DATA opaddrs+0x9d0(SB)/8, $bcSubstr(SB)
+DATA opaddrs+0x9d8(SB)/8, $bcSplitPart(SB)
+DATA opaddrs+0x9e0(SB)/8, $bcTranslate(SB)
+DATA opaddrs+0x9e8(SB)/8, $bccodepoint(SB)
+DATA opaddrs+0x9f0(SB)/8, $bcchr(SB)
+DATA opaddrs+0x9f8(SB)/8, $bcContainsPrefixCs(SB)
+DATA opaddrs+0xa00(SB)/8, $bcContainsPrefixCi(SB)
+DATA opaddrs+0xa08(SB)/8, $bcContainsPrefixUTF8Ci(SB)
+DATA opaddrs+0xa10(SB)/8, $bcContainsSuffixCs(SB)
+DATA opaddrs+0xa18(SB)/8, $bcContainsSuffixCi(SB)
+DATA opaddrs+0xa20(SB)/8, $bcContainsSuffixUTF8Ci(SB)
+DATA opaddrs+0xa28(SB)/8, $bcContainsSubstrCs(SB)
+DATA opaddrs+0xa30(SB)/8, $bcContainsSubstrCi(SB)
+DATA opaddrs+0xa38(SB)/8, $bcContainsSubstrUTF8Ci(SB)
+DATA opaddrs+0xa40(SB)/8, $bcEqPatternCs(SB)
+DATA opaddrs+0xa48(SB)/8, $bcEqPatternCi(SB)
+DATA opaddrs+0xa50(SB)/8, $bcEqPatternUTF8Ci(SB)
+DATA opaddrs+0xa58(SB)/8, $bcContainsPatternCs(SB)
+DATA opaddrs+0xa60(SB)/8, $bcContainsPatternCi(SB)
+DATA opaddrs+0xa68(SB)/8, $bcContainsPatternUTF8Ci(SB)
+DATA opaddrs+0xa70(SB)/8, $bcIsSubnetOfIP4(SB)
+DATA opaddrs+0xa78(SB)/8, $bcDfaT6(SB)
+DATA opaddrs+0xa80(SB)/8, $bcDfaT7(SB)
+DATA opaddrs+0xa88(SB)/8, $bcDfaT8(SB)
+DATA opaddrs+0xa90(SB)/8, $bcDfaT6Z(SB)
+DATA opaddrs+0xa98(SB)/8, $bcDfaT7Z(SB)
+DATA opaddrs+0xaa0(SB)/8, $bcDfaT8Z(SB)
+DATA opaddrs+0xaa8(SB)/8, $bcDfaLZ(SB)
+DATA opaddrs+0xab0(SB)/8, $bcAggTDigest(SB)
+DATA opaddrs+0xab8(SB)/8, $bcslower(SB)
+DATA opaddrs+0xac0(SB)/8, $bcsupper(SB)
+DATA opaddrs+0xac8(SB)/8, $bcaggapproxcount(SB)
+DATA opaddrs+0xad0(SB)/8, $bcaggslotapproxcount(SB)
+DATA opaddrs+0xad8(SB)/8, $bcpowuintf64(SB)
 DATA opaddrs+0xae0(SB)/8, $bctrap(SB)
 DATA opaddrs+0xae8(SB)/8, $bctrap(SB)
 DATA opaddrs+0xaf0(SB)/8, $bctrap(SB)
@@ -0,0 +1,152 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// ConcatTable is a Table implementation
+// that presents the concatenation of
+// several Tables as a single scan.
+//
+// The tables are written in sequence
+// into the same set of output streams,
+// so an output stream may carry rows from
+// more than one table. Each table has its own
+// symbol table, so the first chunk that a table
+// writes into a stream must begin with an ion BVM,
+// and the stream is told (via HintEndSegment) that
+// the preceding table's symbols no longer apply.
+type ConcatTable struct {
+	tables []Table
+}
+
+// NewConcatTable constructs a ConcatTable
+// that writes the rows of each of tables in order.
+func NewConcatTable(tables ...Table) *ConcatTable {
+	return &ConcatTable{tables: tables}
+}
+
+// statTable is implemented by tables
+// that report cache statistics
+type statTable interface {
+	Hits() int64
+	Misses() int64
+	Bytes() int64
+}
+
+func (c *ConcatTable) sum(get func(statTable) int64) int64 {
+	n := int64(0)
+	for i := range c.tables {
+		if st, ok := c.tables[i].(statTable); ok {
+			n += get(st)
+		}
+	}
+	return n
+}
+
+func (c *ConcatTable) Hits() int64   { return c.sum(statTable.Hits) }
+func (c *ConcatTable) Misses() int64 { return c.sum(statTable.Misses) }
+func (c *ConcatTable) Bytes() int64  { return c.sum(statTable.Bytes) }
+
+// WriteChunks implements Table.WriteChunks
+func (c *ConcatTable) WriteChunks(dst QuerySink, parallel int) error {
+	if parallel <= 0 {
+		parallel = 1
+	}
+	var out []io.WriteCloser
+	for len(out) < parallel {
+		w, err := dst.Open()
+		if err != nil {
+			if len(out) == 0 {
+				return err
+			}
+			// just use fewer streams
+			break
+		}
+		out = append(out, w)
+	}
+	cs := &concatSink{out: make([]concatWriter, len(out))}
+	for i := range out {
+		cs.out[i].dst = out[i]
+	}
+	var err error
+	for i := range c.tables {
+		cs.next = 0
+		for j := range cs.out {
+			cs.out[j].fresh = true
+		}
+		err = c.tables[i].WriteChunks(cs, len(out))
+		if err != nil {
+			break
+		}
+	}
+	for i := range out {
+		err2 := out[i].Close()
+		if err == nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+// concatSink hands out the output streams
+// of a ConcatTable to one of its tables
+type concatSink struct {
+	out  []concatWriter
+	next int
+}
+
+func (c *concatSink) Open() (io.WriteCloser, error) {
+	if c.next >= len(c.out) {
+		return nil, fmt.Errorf("ConcatTable: more than %d streams opened", len(c.out))
+	}
+	w := &c.out[c.next]
+	c.next++
+	return w, nil
+}
+
+func (c *concatSink) Close() error { return nil }
+
+// concatWriter is an output stream of a
+// ConcatTable; Close is deferred until
+// every table has been written
+type concatWriter struct {
+	dst     io.WriteCloser
+	fresh   bool // no writes from the current table yet
+	written bool // any writes from a preceding table
+}
+
+func (c *concatWriter) Write(p []byte) (int, error) {
+	if c.fresh {
+		if !ion.IsBVM(p) {
+			return 0, fmt.Errorf("ConcatTable: first chunk of a table does not begin with a BVM")
+		}
+		if c.written {
+			HintEndSegment(c.dst)
+		}
+		c.fresh = false
+		c.written = true
+	}
+	return c.dst.Write(p)
+}
+
+func (c *concatWriter) EndSegment() { HintEndSegment(c.dst) }
+
+func (c *concatWriter) Close() error { return nil }
@@ -180,7 +180,7 @@ func (d *deduper) writeRows(delims []vmref, rp *rowParams) error {
 	}
 	d.bc.prepare(rp)
 	var count int
-	if globalOptimizationLevel >= OptimizationLevelAVX512V1 {
+	if d.bc.useAsm() {
 		count = evaldedup(&d.bc, delims, d.hashes, d.local, d.hashslot)
 	} else {
 		count = evaldedupgo(&d.bc, delims, d.hashes, d.local, d.hashslot)
@@ -262,6 +262,7 @@ func (d *deduper) writeRows(delims []vmref, rp *rowParams) error {
 		aux[j] = aux[j][:outpos]
 	}
 	d.params.auxbound = aux
+	d.params.pos = rp.pos
 	return d.dst.writeRows(delims, &d.params)
 }
 
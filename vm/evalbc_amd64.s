@@ -59,6 +59,18 @@
   STC \
   RET
 
+// BC_CALL_PORTABLE() implements an instruction by
+// calling its portable implementation (see callportable)
+// and then continues with the next instruction, or
+// aborts the program if the portable implementation failed
+#define BC_CALL_PORTABLE()              \
+  CALL callportable(SB)                 \
+  CMPL bytecode_err(VIRT_BCPTR), $0     \
+  JNE  portable_error                   \
+  NEXT()                                \
+portable_error:                         \
+  RET_ABORT()
+
 // use FAIL() when you encounter
 // an unrecoverable error
 #define FAIL()                                       \
@@ -108,6 +120,55 @@ TEXT ·bcenter(SB), NOSPLIT, $0
   BC_ENTER_WITH_SCRATCH()
   RET
 
+// callportable runs the portable implementation of the
+// instruction whose arguments VIRT_PCREG points to and then
+// points VIRT_PCREG at the next instruction. It is used by
+// the instructions that do not have an assembly implementation.
+//
+// The portable implementation is ordinary Go code, so
+// every general-purpose register other than VIRT_PCREG
+// is preserved across the call, as is K7. VIRT_BCPTR is
+// reloaded from the argument slot, which the runtime
+// updates if the goroutine stack (and the bytecode
+// along with it) moves during the call.
+TEXT callportable(SB), NOSPLIT, $120-0
+  NO_LOCAL_POINTERS
+  MOVQ  BX, 32(SP)
+  MOVQ  CX, 40(SP)
+  MOVQ  DX, 48(SP)
+  MOVQ  SI, 56(SP)
+  MOVQ  R8, 64(SP)
+  MOVQ  R9, 72(SP)
+  MOVQ  R10, 80(SP)
+  MOVQ  R11, 88(SP)
+  MOVQ  R13, 96(SP)
+  MOVQ  R14, 104(SP)
+  MOVQ  R15, 112(SP)
+  KMOVW K7, BX
+  MOVQ  BX, 16(SP)                                // k7 = K7
+  MOVQ  VIRT_BCPTR, 0(SP)                         // bc = VIRT_BCPTR
+  SUBQ  bytecode_compiled(VIRT_BCPTR), VIRT_PCREG
+  MOVQ  VIRT_PCREG, 8(SP)                         // pc = VIRT_PCREG - &bc.compiled[0]
+  CALL  ·runportable(SB)
+  MOVQ  0(SP), VIRT_BCPTR
+  MOVQ  24(SP), VIRT_PCREG
+  ADDQ  bytecode_compiled(VIRT_BCPTR), VIRT_PCREG // VIRT_PCREG = &bc.compiled[ret]
+  MOVQ  bytecode_vstack(VIRT_BCPTR), VIRT_VALUES
+  MOVQ  16(SP), BX
+  KMOVW BX, K7
+  MOVQ  32(SP), BX
+  MOVQ  40(SP), CX
+  MOVQ  48(SP), DX
+  MOVQ  56(SP), SI
+  MOVQ  64(SP), R8
+  MOVQ  72(SP), R9
+  MOVQ  80(SP), R10
+  MOVQ  88(SP), R11
+  MOVQ  96(SP), R13
+  MOVQ  104(SP), R14
+  MOVQ  112(SP), R15
+  RET
+
 // the 'return' instruction
 //
 // _ = ret()
@@ -3635,6 +3696,111 @@ abort:
   MOVL $const_bcerrMoreScratch, bytecode_err(VIRT_BCPTR)
   RET_ABORT()
 
+// Random Instructions
+// -------------------
+
+// f64[0] = random(b[1], i64@imm[2]).k[3]
+//
+// Generates a uniformly distributed number in [0, 1) for each row. The number
+// is a function of the seed, the position of the row (bytecode.random + lane,
+// where bytecode.random is derived from the chunk the rows come from, see
+// chunkpos() in sfw.go) and the content of the row:
+//
+//   h = len(row)
+//   for each 4-byte word w of the row (zero padded): h = (h ^ w) * 0x9E3779B1; h ^= h >> 15
+//   z = splitmix64((seed ^ h) + (bytecode.random + lane + 1) * 0x9E3779B97F4A7C15)
+//   out = float64(z >> 12 | 0x3FF0000000000000) - 1.0
+//
+// bytecode.random is then advanced by 16, whether or not every lane is active.
+//
+// See randomRow() in interpfloat.go for the reference implementation.
+TEXT bcrandom(SB), NOSPLIT|NOFRAME, $0
+  BC_UNPACK_SLOT(BC_SLOT_SIZE*1, OUT(BX))
+  BC_UNPACK_SLOT(BC_SLOT_SIZE*2 + BC_IMM64_SIZE, OUT(R8))
+  BC_LOAD_SLICE_FROM_SLOT(OUT(Z2), OUT(Z3), IN(BX))    // Z2 <- row offsets, Z3 <- row lengths
+  BC_LOAD_K1_FROM_SLOT(OUT(K1), IN(R8))
+
+  VMOVDQA32.Z Z3, K1, Z4                               // Z4 <- row hash, initially the length
+  VPTESTMD Z3, Z3, K1, K2                              // K2 <- lanes having bytes to hash
+  KTESTW K2, K2
+  JZ mix
+
+  VPBROADCASTD CONSTD_4(), Z10
+  VPBROADCASTD CONSTD_0x9E3779B1(), Z11
+  VPTERNLOGD $0xFF, Z12, Z12, Z12
+
+hash_loop:
+  KMOVW K2, K3
+  VPXORD X5, X5, X5
+  VPGATHERDD (VIRT_BASE)(Z2*1), K3, Z5                 // Z5 <- next 4 bytes of each row
+  VPMINUD Z10, Z3, Z6                                  // Z6 <- min(remaining length, 4)
+  VPSLLD $3, Z6, Z7
+  VPSLLVD Z7, Z12, Z7
+  VPANDND Z5, Z7, Z5                                   // Z5 <- the word without bytes past the end of the row
+
+  VPXORD Z5, Z4, K2, Z4
+  VPMULLD Z11, Z4, K2, Z4                              // h = (h ^ w) * 0x9E3779B1
+  VPSRLD $15, Z4, Z8
+  VPXORD Z8, Z4, K2, Z4                                // h ^= h >> 15
+
+  VPADDD Z10, Z2, K2, Z2                               // Z2 <- advance row offsets
+  VPSUBD Z6, Z3, K2, Z3                                // Z3 <- decrease remaining lengths
+  VPTESTMD Z3, Z3, K2, K2
+  KTESTW K2, K2
+  JNZ hash_loop
+
+mix:
+  VEXTRACTI32X8 $1, Z4, Y5
+  VPMOVZXDQ Y4, Z2
+  VPMOVZXDQ Y5, Z3
+  BC_UNPACK_ZI64(BC_SLOT_SIZE*2, OUT(Z8))              // Z8 <- seed
+  VPXORQ Z8, Z2, Z2
+  VPXORQ Z8, Z3, Z3
+
+  // advance the splitmix64 state by the position of each row
+  VPBROADCASTQ bytecode_random(VIRT_BCPTR), Z8         // Z8 <- position of lane 0
+  ADDQ $16, bytecode_random(VIRT_BCPTR)
+  VPADDQ.BCST CONSTQ_1(), Z8, Z8
+  VPMOVZXBQ byteidx<>+0(SB), Z4
+  VPMOVZXBQ byteidx<>+8(SB), Z5
+  VPADDQ Z8, Z4, Z4                                    // Z4 <- position + 1 of lanes 0-7
+  VPADDQ Z8, Z5, Z5                                    // Z5 <- position + 1 of lanes 8-15
+  VPMULLQ.BCST CONSTQ_0x9E3779B97F4A7C15(), Z4, Z4
+  VPMULLQ.BCST CONSTQ_0x9E3779B97F4A7C15(), Z5, Z5
+  VPADDQ Z4, Z2, Z2
+  VPADDQ Z5, Z3, Z3
+
+  // splitmix64 finalizer
+  VPSRLQ $30, Z2, Z4
+  VPSRLQ $30, Z3, Z5
+  VPXORQ Z4, Z2, Z2
+  VPXORQ Z5, Z3, Z3
+  VPMULLQ.BCST CONSTQ_0xBF58476D1CE4E5B9(), Z2, Z2
+  VPMULLQ.BCST CONSTQ_0xBF58476D1CE4E5B9(), Z3, Z3
+  VPSRLQ $27, Z2, Z4
+  VPSRLQ $27, Z3, Z5
+  VPXORQ Z4, Z2, Z2
+  VPXORQ Z5, Z3, Z3
+  VPMULLQ.BCST CONSTQ_0x94D049BB133111EB(), Z2, Z2
+  VPMULLQ.BCST CONSTQ_0x94D049BB133111EB(), Z3, Z3
+  VPSRLQ $31, Z2, Z4
+  VPSRLQ $31, Z3, Z5
+  VPXORQ Z4, Z2, Z2
+  VPXORQ Z5, Z3, Z3
+
+  // use the upper 52 bits as the mantissa of a number in [1, 2)
+  KSHIFTRW $8, K1, K2
+  VPSRLQ $12, Z2, Z2
+  VPSRLQ $12, Z3, Z3
+  VPORQ.BCST CONSTQ_0x3FF0000000000000(), Z2, Z2
+  VPORQ.BCST CONSTQ_0x3FF0000000000000(), Z3, Z3
+  VSUBPD.BCST.Z CONSTF64_1(), Z2, K1, Z2
+  VSUBPD.BCST.Z CONSTF64_1(), Z3, K2, Z3
+
+  BC_UNPACK_SLOT(0, OUT(DX))
+  BC_STORE_F64_TO_SLOT(IN(Z2), IN(Z3), IN(DX))
+  NEXT_ADVANCE(BC_SLOT_SIZE*3 + BC_IMM64_SIZE)
+
 // Bucket Instructions
 // -------------------
 
@@ -5700,6 +5866,43 @@ TEXT bcboxlist(SB), NOSPLIT|NOFRAME, $0
   VPSLLD.BCST $4, CONSTD_0x0B(), Z20 // ION type of a boxed list is 0xB
   JMP boxslice_tail(SB)
 
+// v[0].k[1] = jsonextract(slice[2], imm16[3]).k[4]
+//
+// scratch: PageSize
+//
+// Parses the JSON document in each string and boxes
+// the value found at the JSONPath jsonpaths[imm16[3]].
+//
+// Implementation notes:
+//   - JSON parsing is done by the portable implementation
+TEXT bcjsonextract(SB), NOSPLIT|NOFRAME, $0
+  BC_CALL_PORTABLE()
+  NEXT_ADVANCE(BC_SLOT_SIZE*4 + BC_IMM16_SIZE) // unreachable; documents the instruction width
+
+// ts[0].k[1] = parsets(slice[2], dict[3]).k[4]
+//
+// Parses each string as a timestamp using
+// the time.Parse layout in the dictionary.
+//
+// Implementation notes:
+//   - timestamps are parsed by the portable implementation
+TEXT bcparsets(SB), NOSPLIT|NOFRAME, $0
+  BC_CALL_PORTABLE()
+  NEXT_ADVANCE(BC_SLOT_SIZE*4 + BC_DICT_SIZE) // unreachable; documents the instruction width
+
+// slice[0].k[1] = formatts(ts[2], dict[3]).k[4]
+//
+// scratch: PageSize
+//
+// Formats each timestamp as a string using
+// the time.Time.Format layout in the dictionary.
+//
+// Implementation notes:
+//   - timestamps are formatted by the portable implementation
+TEXT bcformatts(SB), NOSPLIT|NOFRAME, $0
+  BC_CALL_PORTABLE()
+  NEXT_ADVANCE(BC_SLOT_SIZE*4 + BC_DICT_SIZE) // unreachable; documents the instruction width
+
 // Boxes a string or list slice
 //
 // Implementation notes:
@@ -6501,6 +6704,23 @@ next:
 
   NEXT_ADVANCE(BC_SLOT_SIZE*4 + BC_IMM16_SIZE)
 
+// i64[0] = hashbucket(h[1], i64@imm[2]).k[3]
+//
+// compute the hash bucket of hash[1] as the
+// low bits of the low 64 bits of the hash selected
+// by the mask in i64@imm[2]; the low halves of
+// the hashes are laid out like an i64 slot
+TEXT bchashbucket(SB), NOSPLIT|NOFRAME, $0
+  BC_UNPACK_2xSLOT_ZI64_SLOT(0, OUT(DX), OUT(BX), OUT(Z4), OUT(R8))
+  BC_LOAD_K1_K2_FROM_SLOT(OUT(K1), OUT(K2), IN(R8))
+  BC_LOAD_I64_FROM_SLOT(OUT(Z2), OUT(Z3), IN(BX))
+
+  VPANDQ.Z Z4, Z2, K1, Z2
+  VPANDQ.Z Z4, Z3, K2, Z3
+
+  BC_STORE_I64_TO_SLOT(IN(Z2), IN(Z3), IN(DX))
+  NEXT_ADVANCE(BC_SLOT_SIZE*3 + BC_IMM64_SIZE)
+
 
 // Simple Aggregation Instructions
 // -------------------------------
@@ -6562,36 +6782,79 @@ TEXT bcaggsumf(SB), NOSPLIT|NOFRAME, $0
   NEXT_ADVANCE(BC_SLOT_SIZE*2 + BC_AGGSLOT_SIZE)
 
 // _ = aggsum.i64(a[0], s[1]).k[2]
+//
+// The sum is computed exactly: the high (signed) and low (unsigned)
+// 32-bit halves of the sixteen inputs are summed separately, so the
+// horizontal sums cannot overflow, and then the halves are added to
+// the accumulator as a 128-bit quantity. If the result doesn't fit
+// into int64, the bytecode fails with bcerrIntOverflow.
 TEXT bcaggsumi(SB), NOSPLIT|NOFRAME, $0
   BC_UNPACK_2xSLOT(BC_AGGSLOT_SIZE, OUT(BX), OUT(R8))
   BC_LOAD_K1_K2_FROM_SLOT(OUT(K1), OUT(K2), IN(R8))
   BC_LOAD_I64_FROM_SLOT_MASKED(OUT(Z4), OUT(Z5), IN(BX), IN(K1), IN(K2))
 
   KMOVW K1, R15
+  VPSRAQ $32, Z4, Z6                   // Z6 <- hi32(low 8 lanes)
+  VPSRAQ $32, Z5, Z7                   // Z7 <- hi32(high 8 lanes)
+  VPSLLQ $32, Z4, Z4
+  VPSLLQ $32, Z5, Z5
+  VPSRLQ $32, Z4, Z4                   // Z4 <- lo32(low 8 lanes)
+  VPSRLQ $32, Z5, Z5                   // Z5 <- lo32(high 8 lanes)
+  VPADDQ Z6, Z7, Z6
   VPADDQ Z4, Z5, Z5
+
   VEXTRACTI64X4 $1, Z5, Y4
+  VEXTRACTI64X4 $1, Z6, Y7
   VPADDQ Y4, Y5, Y5
+  VPADDQ Y7, Y6, Y6
   VEXTRACTI64X2 $1, Y5, X4
+  VEXTRACTI64X2 $1, Y6, X7
   VPADDQ X4, X5, X5
+  VPADDQ X7, X6, X6
+  VPSHUFD $SHUFFLE_IMM_4x2b(1, 0, 3, 2), X5, X4
+  VPSHUFD $SHUFFLE_IMM_4x2b(1, 0, 3, 2), X6, X7
+  VPADDQ X4, X5, X5                    // X5 <- sum of lo32 halves
+  VPADDQ X7, X6, X6                    // X6 <- sum of hi32 halves
 
   BC_UNPACK_RU32(0, OUT(DX))
-  VPSHUFD $SHUFFLE_IMM_4x2b(1, 0, 3, 2), X5, X4
-  VMOVQ 0(VIRT_AGG_BUFFER)(DX*1), X6
-  VPADDQ X4, X5, X5
+  VMOVQ X6, BX
+  VMOVQ X5, R8
+  MOVQ BX, CX
+  SHLQ $32, BX
+  SARQ $32, CX                         // CX:BX <- (sum of hi32 halves) << 32
+  ADDQ R8, BX
+  ADCQ $0, CX                          // CX:BX <- sum of inputs
+
+  MOVQ 0(VIRT_AGG_BUFFER)(DX*1), R8
+  MOVQ R8, R11
+  SARQ $63, R11
+  ADDQ R8, BX
+  ADCQ R11, CX                         // CX:BX <- accumulator + sum of inputs
+
+  MOVQ BX, R11
+  SARQ $63, R11
+  CMPQ R11, CX
+  JNE  overflow                        // the result doesn't fit into int64
 
   POPCNTL R15, R15
-  VPADDQ X6, X5, X5
   ADDQ R15, 8(VIRT_AGG_BUFFER)(DX*1)
-  VMOVQ X5, 0(VIRT_AGG_BUFFER)(DX*1)
+  MOVQ BX, 0(VIRT_AGG_BUFFER)(DX*1)
 
   NEXT_ADVANCE(BC_SLOT_SIZE*2 + BC_AGGSLOT_SIZE)
 
+overflow:
+  MOVL $const_bcerrIntOverflow, bytecode_err(VIRT_BCPTR)
+  RET_ABORT()
+
 // _ = aggmin.f64(a[0], s[1]).k[2]
 TEXT bcaggminf(SB), NOSPLIT|NOFRAME, $0
   BC_UNPACK_2xSLOT(BC_AGGSLOT_SIZE, OUT(BX), OUT(R8))
   VBROADCASTSD CONSTF64_POSITIVE_INF(), Z5
   BC_LOAD_K1_K2_FROM_SLOT(OUT(K1), OUT(K2), IN(R8))
 
+  // K3/K4 = lanes with NaN inputs (Z5 is not NaN)
+  VCMPPD $VCMP_IMM_UNORD_Q, 0(VIRT_VALUES)(BX*1), Z5, K1, K3
+  VCMPPD $VCMP_IMM_UNORD_Q, 64(VIRT_VALUES)(BX*1), Z5, K2, K4
   VMINPD 0(VIRT_VALUES)(BX*1), Z5, K1, Z5
   VMINPD 64(VIRT_VALUES)(BX*1), Z5, K2, Z5
 
@@ -6606,12 +6869,23 @@ TEXT bcaggminf(SB), NOSPLIT|NOFRAME, $0
   VSHUFPD $1, X5, X5, X4
   VMINSD X4, X5, X5
 
+  // a NaN input makes the result NaN; otherwise the
+  // aggregated value is the second source, so a NaN
+  // that is already there is kept
+  KORTESTB K3, K4
+  JNZ nan
   VMINSD 0(VIRT_AGG_BUFFER)(DX*1), X5, X5
+
+store:
   ADDQ R15, 8(VIRT_AGG_BUFFER)(DX*1)
   VMOVSD X5, 0(VIRT_AGG_BUFFER)(DX*1)
 
   NEXT_ADVANCE(BC_SLOT_SIZE*2 + BC_AGGSLOT_SIZE)
 
+nan:
+  VMOVSD CONSTF64_NAN(), X5
+  JMP store
+
 // _ = aggmin.i64(a[0], s[1]).k[2]
 TEXT bcaggmini(SB), NOSPLIT|NOFRAME, $0
   BC_UNPACK_2xSLOT(BC_AGGSLOT_SIZE, OUT(BX), OUT(R8))
@@ -6645,6 +6919,9 @@ TEXT bcaggmaxf(SB), NOSPLIT|NOFRAME, $0
   VBROADCASTSD CONSTF64_NEGATIVE_INF(), Z5
   BC_LOAD_K1_K2_FROM_SLOT(OUT(K1), OUT(K2), IN(R8))
 
+  // K3/K4 = lanes with NaN inputs (Z5 is not NaN)
+  VCMPPD $VCMP_IMM_UNORD_Q, 0(VIRT_VALUES)(BX*1), Z5, K1, K3
+  VCMPPD $VCMP_IMM_UNORD_Q, 64(VIRT_VALUES)(BX*1), Z5, K2, K4
   VMAXPD 0(VIRT_VALUES)(BX*1), Z5, K1, Z5
   VMAXPD 64(VIRT_VALUES)(BX*1), Z5, K2, Z5
 
@@ -6659,12 +6936,23 @@ TEXT bcaggmaxf(SB), NOSPLIT|NOFRAME, $0
   VSHUFPD $1, X5, X5, X4
   VMAXSD X4, X5, X5
 
+  // a NaN input makes the result NaN; otherwise the
+  // aggregated value is the second source, so a NaN
+  // that is already there is kept
+  KORTESTB K3, K4
+  JNZ nan
   VMAXSD 0(VIRT_AGG_BUFFER)(DX*1), X5, X5
+
+store:
   ADDQ R15, 8(VIRT_AGG_BUFFER)(DX*1)
   VMOVSD X5, 0(VIRT_AGG_BUFFER)(DX*1)
 
   NEXT_ADVANCE(BC_SLOT_SIZE*2 + BC_AGGSLOT_SIZE)
 
+nan:
+  VMOVSD CONSTF64_NAN(), X5
+  JMP store
+
 // _ = aggmax.i64(a[0], s[1]).k[2]
 TEXT bcaggmaxi(SB), NOSPLIT|NOFRAME, $0
   BC_UNPACK_2xSLOT(BC_AGGSLOT_SIZE, OUT(BX), OUT(R8))
@@ -6960,13 +7248,81 @@ bad_radix_bucket:
   MOVL    VIRT_PCREG, bytecode_errpc(VIRT_BCPTR)
   RET_ABORT()
 
+// select the hash aggregate entry for each
+// lane directly from the boolean key in k[1];
+// the entries for FALSE and TRUE are held in
+// radixTree64.direct rather than looked up by hash
+//
+// returns early if either entry is needed but
+// has not been created yet
+//
+// l[0] = aggbucket.bool(k[1]).k[2]
+TEXT bcaggbucketbool(SB), NOSPLIT|NOFRAME, $0
+  BC_UNPACK_2xSLOT(BC_SLOT_SIZE*1, OUT(BX), OUT(R8))
+  BC_LOAD_K1_FROM_SLOT(OUT(K2), IN(BX))                                 // K2 <- key
+  BC_LOAD_K1_FROM_SLOT(OUT(K1), IN(R8))                                 // K1 <- active lanes
+  BC_UNPACK_SLOT(0, OUT(DX))
+
+  VPBROADCASTD  radixTree64_direct+0(VIRT_AGG_BUFFER), Z13              // Z13 <- FALSE entry
+  VPBROADCASTD  radixTree64_direct+4(VIRT_AGG_BUFFER), K2, Z13          // Z13 <- TRUE entry where key is set
+  VPXORD        Z14, Z14, Z14
+  VPCMPD        $VPCMP_IMM_LT, Z14, Z13, K1, K2                         // K2 <- lanes without an entry
+  KTESTW        K2, K2
+  JNZ           early_ret
+
+  VMOVDQU32     Z13, 0(VIRT_VALUES)(DX*1)
+  NEXT_ADVANCE(BC_SLOT_SIZE*3)
+
+early_ret:
+  MOVL $const_bcerrNeedRadix, bytecode_err(VIRT_BCPTR)
+  KMOVW K2, bytecode_missingBucketMask(VIRT_BCPTR)
+  RET_ABORT()
+
+// Overflow checks for BC_AGGREGATE_SLOT_MARK_OP and BC_AGGREGATE_SLOT_COUNT_OP;
+// Check(A, R, K) is expanded after each `Instruction A, B, K, R`, where B may
+// alias R. BC_AGGREGATE_CHECK_ADDQ recovers B as R - A and accumulates the
+// signed-overflow condition (A ^ R) & (B ^ R) of the active lanes into Z19,
+// which must be zeroed before the aggregation and tested afterwards.
+#define BC_AGGREGATE_NO_CHECK(A, R, K)
+
+#define BC_AGGREGATE_CHECK_ADDQ(A, R, K)                                      \
+  VPSUBQ A, R, Z17                                                            \
+  VPXORQ R, Z17, Z17                                                          \
+  VPXORQ R, A, Z18                                                            \
+  VPANDQ Z17, Z18, Z17                                                        \
+  VPORQ Z17, Z19, K, Z19
+
+// MIN and MAX of float64 values propagate NaN: VMINPD and VMAXPD return their
+// second source if either source is NaN, which keeps a NaN that is already in
+// a bucket, but may drop a NaN input. BC_AGGREGATE_SLOT_NAN_F64_BEGIN saves
+// the lanes with NaN inputs in R11 and R13 and BC_AGGREGATE_SLOT_NAN_F64_END
+// stores NaN to their buckets once BC_AGGREGATE_SLOT_MARK_OP has finished.
+#define BC_AGGREGATE_SLOT_NAN_F64_BEGIN()                                     \
+  VCMPPD $VCMP_IMM_UNORD_Q, Z4, Z4, K1, K3                                    \
+  KMOVB K3, R11                                                               \
+  VCMPPD $VCMP_IMM_UNORD_Q, Z5, Z5, K6, K3                                    \
+  KMOVB K3, R13
+
+#define BC_AGGREGATE_SLOT_NAN_F64_END()                                       \
+  MOVL R11, R14                                                               \
+  ORL R13, R14                                                                \
+  JZ nonan                                                                    \
+  VBROADCASTSD CONSTF64_NAN(), Z14                                            \
+  KMOVB R11, K3                                                               \
+  VSCATTERDPD Z14, K3, 0(R15)(Y6*1)                                           \
+  KMOVB R13, K3                                                               \
+  VSCATTERDPD Z14, K3, 0(R15)(Y7*1)                                           \
+nonan:
+
 // All aggregate operations except AVG aggregate the value and then mark
 // slot+1, so we can decide whether the result of the aggregation should
 // be the aggregated value or NULL - in other words it basically describes
 // whether there was at least one aggregation.
 //
-// Expects 64-bit sources in Z4 and Z5, buckets in Z6
-#define BC_AGGREGATE_SLOT_MARK_OP(SlotOffset, Instruction)                    \
+// Expects 64-bit sources in Z4 and Z5, buckets in Z6. The aggregated value
+// is the second source of the final Instruction, so VMINPD and VMAXPD keep
+// a NaN that is already in the bucket (see BC_AGGREGATE_SLOT_NAN_F64_BEGIN).
+#define BC_AGGREGATE_SLOT_MARK_OP(SlotOffset, Instruction, Check)             \
   VPCONFLICTD.Z Z6, K1, Z11                                                   \
   VEXTRACTI32X8 $1, Z6, Y7                                                    \
                                                                               \
@@ -7016,7 +7372,9 @@ loop:                                                                         \
                                                                               \
   /* Aggregate conflicting lanes and mask out lanes we have resolved. */      \
   Instruction Z8, Z4, K4, Z4                                                  \
+  Check(Z8, Z4, K4)                                                           \
   Instruction Z9, Z5, K5, Z5                                                  \
+  Check(Z9, Z5, K5)                                                           \
                                                                               \
   /* Continue looping if there are still conflicts. */                        \
   KTESTW K2, K2                                                               \
@@ -7024,14 +7382,16 @@ loop:                                                                         \
                                                                               \
 resolved:                                                                     \
   /* Finally, aggregate non-conflicting sources into buckets. */              \
-  Instruction Z4, Z14, K1, Z14                                                \
+  Instruction Z14, Z4, K1, Z14                                                \
+  Check(Z4, Z14, K1)                                                          \
   KMOVB K1, K2                                                                \
   VSCATTERDPD Z14, K2, 0(R15)(Y6*1)                                           \
                                                                               \
   KMOVB K6, K2                                                                \
   VPXORQ X14, X14, X14                                                        \
   VGATHERDPD 0(R15)(Y7*1), K2, Z14                                            \
-  Instruction Z5, Z14, K6, Z14                                                \
+  Instruction Z14, Z5, K6, Z14                                                \
+  Check(Z5, Z14, K6)                                                          \
   VSCATTERDPD Z14, K6, 0(R15)(Y7*1)                                           \
                                                                               \
 next:
@@ -7044,7 +7404,7 @@ next:
 // COUNT is zero, the result of the aggregation is NULL.
 //
 // Expects 64-bit sources in Z4 and Z5, buckets in Z6
-#define BC_AGGREGATE_SLOT_COUNT_OP(SlotOffset, Instruction)                   \
+#define BC_AGGREGATE_SLOT_COUNT_OP(SlotOffset, Instruction, Check)            \
   VPCONFLICTD.Z Z6, K1, Z11                                                   \
   VEXTRACTI32X8 $1, Z6, Y7                                                    \
                                                                               \
@@ -7098,7 +7458,9 @@ loop:                                                                         \
                                                                               \
   /* Aggregate conflicting lanes and mask out lanes we have resolved. */      \
   Instruction Z8, Z4, K4, Z4                                                  \
+  Check(Z8, Z4, K4)                                                           \
   Instruction Z9, Z5, K5, Z5                                                  \
+  Check(Z9, Z5, K5)                                                           \
                                                                               \
   /* Continue looping if there are still conflicts. */                        \
   KTESTW K2, K2                                                               \
@@ -7117,6 +7479,7 @@ resolved:                                                                     \
                                                                               \
   /* Aggregate non-conflicting values and COUNTs into buckets (low). */       \
   Instruction Z4, Z14, K1, Z14                                                \
+  Check(Z4, Z14, K1)                                                          \
   VPADDQ Z15, Z13, K1, Z13                                                    \
   KMOVB K1, K2                                                                \
   VSCATTERDPD Z14, K2, 0(R15)(Y6*1)                                           \
@@ -7132,6 +7495,7 @@ resolved:                                                                     \
   VPGATHERDQ 8(R15)(Y7*1), K3, Z13                                            \
   KMOVB K6, K2                                                                \
   Instruction Z5, Z14, K2, Z14                                                \
+  Check(Z5, Z14, K2)                                                          \
   VPADDQ Z16, Z13, K2, Z13                                                    \
   VSCATTERDPD Z14, K2, 0(R15)(Y7*1)                                           \
   VPSCATTERDQ Z13, K6, 8(R15)(Y7*1)                                           \
@@ -7146,7 +7510,7 @@ TEXT bcaggslotandk(SB), NOSPLIT|NOFRAME, $0
   BC_LOAD_BUCKET_FROM_SLOT(OUT(Z6), IN(DX), IN(K1))
   VPMOVM2Q K4, Z4
   VPMOVM2Q K5, Z5
-  BC_AGGREGATE_SLOT_MARK_OP(0, VPANDQ)
+  BC_AGGREGATE_SLOT_MARK_OP(0, VPANDQ, BC_AGGREGATE_NO_CHECK)
   NEXT_ADVANCE(BC_SLOT_SIZE*3 + BC_AGGSLOT_SIZE)
 
 // _ = aggslotor.k(a[0], l[1], k[2], k[3])
@@ -7157,7 +7521,7 @@ TEXT bcaggslotork(SB), NOSPLIT|NOFRAME, $0
   BC_LOAD_BUCKET_FROM_SLOT(OUT(Z6), IN(DX), IN(K1))
   VPMOVM2Q K4, Z4
   VPMOVM2Q K5, Z5
-  BC_AGGREGATE_SLOT_MARK_OP(0, VPORQ)
+  BC_AGGREGATE_SLOT_MARK_OP(0, VPORQ, BC_AGGREGATE_NO_CHECK)
   NEXT_ADVANCE(BC_SLOT_SIZE*3 + BC_AGGSLOT_SIZE)
 
 // _ = aggslotsum.i64(a[0], l[1], s[2], k[3])
@@ -7166,9 +7530,17 @@ TEXT bcaggslotsumi(SB), NOSPLIT|NOFRAME, $0
   BC_LOAD_K1_K2_FROM_SLOT(OUT(K1), OUT(K6), IN(R8))
   BC_LOAD_I64_FROM_SLOT_MASKED(OUT(Z4), OUT(Z5), IN(BX), IN(K1), IN(K6))
   BC_LOAD_BUCKET_FROM_SLOT(OUT(Z6), IN(DX), IN(K1))
-  BC_AGGREGATE_SLOT_MARK_OP(0, VPADDQ)
+  VPXORQ Z19, Z19, Z19
+  BC_AGGREGATE_SLOT_MARK_OP(0, VPADDQ, BC_AGGREGATE_CHECK_ADDQ)
+  VPMOVQ2M Z19, K2
+  KTESTB K2, K2
+  JNZ overflow
   NEXT_ADVANCE(BC_SLOT_SIZE*3 + BC_AGGSLOT_SIZE)
 
+overflow:
+  MOVL $const_bcerrIntOverflow, bytecode_err(VIRT_BCPTR)
+  RET_ABORT()
+
 // _ = aggslotavg.f64(a[0], l[1], s[2], k[3])
 TEXT bcaggslotavgf(SB), NOSPLIT|NOFRAME, $0
   JMP bcaggslotsumf(SB)
@@ -7180,16 +7552,26 @@ TEXT bcaggslotavgi(SB), NOSPLIT|NOFRAME, $0
   BC_LOAD_K1_K2_FROM_SLOT(OUT(K1), OUT(K6), IN(R8))
   BC_LOAD_I64_FROM_SLOT_MASKED(OUT(Z4), OUT(Z5), IN(BX), IN(K1), IN(K6))
   BC_LOAD_BUCKET_FROM_SLOT(OUT(Z6), IN(DX), IN(K1))
-  BC_AGGREGATE_SLOT_COUNT_OP(0, VPADDQ)
+  VPXORQ Z19, Z19, Z19
+  BC_AGGREGATE_SLOT_COUNT_OP(0, VPADDQ, BC_AGGREGATE_CHECK_ADDQ)
+  VPMOVQ2M Z19, K2
+  KTESTB K2, K2
+  JNZ overflow
   NEXT_ADVANCE(BC_SLOT_SIZE*3 + BC_AGGSLOT_SIZE)
 
+overflow:
+  MOVL $const_bcerrIntOverflow, bytecode_err(VIRT_BCPTR)
+  RET_ABORT()
+
 // _ = aggslotmin.f64(a[0], l[1], s[2], k[3])
 TEXT bcaggslotminf(SB), NOSPLIT|NOFRAME, $0
   BC_UNPACK_3xSLOT(BC_AGGSLOT_SIZE, OUT(DX), OUT(BX), OUT(R8))
   BC_LOAD_K1_K2_FROM_SLOT(OUT(K1), OUT(K6), IN(R8))
   BC_LOAD_F64_FROM_SLOT_MASKED(OUT(Z4), OUT(Z5), IN(BX), IN(K1), IN(K6))
   BC_LOAD_BUCKET_FROM_SLOT(OUT(Z6), IN(DX), IN(K1))
-  BC_AGGREGATE_SLOT_MARK_OP(0, VMINPD)
+  BC_AGGREGATE_SLOT_NAN_F64_BEGIN()
+  BC_AGGREGATE_SLOT_MARK_OP(0, VMINPD, BC_AGGREGATE_NO_CHECK)
+  BC_AGGREGATE_SLOT_NAN_F64_END()
   NEXT_ADVANCE(BC_SLOT_SIZE*3 + BC_AGGSLOT_SIZE)
 
 // _ = aggslotmin.i64(a[0], l[1], s[2], k[3])
@@ -7198,7 +7580,7 @@ TEXT bcaggslotmini(SB), NOSPLIT|NOFRAME, $0
   BC_LOAD_K1_K2_FROM_SLOT(OUT(K1), OUT(K6), IN(R8))
   BC_LOAD_I64_FROM_SLOT_MASKED(OUT(Z4), OUT(Z5), IN(BX), IN(K1), IN(K6))
   BC_LOAD_BUCKET_FROM_SLOT(OUT(Z6), IN(DX), IN(K1))
-  BC_AGGREGATE_SLOT_MARK_OP(0, VPMINSQ)
+  BC_AGGREGATE_SLOT_MARK_OP(0, VPMINSQ, BC_AGGREGATE_NO_CHECK)
   NEXT_ADVANCE(BC_SLOT_SIZE*3 + BC_AGGSLOT_SIZE)
 
 // _ = aggslotmax.f64(a[0], l[1], s[2], k[3])
@@ -7207,7 +7589,9 @@ TEXT bcaggslotmaxf(SB), NOSPLIT|NOFRAME, $0
   BC_LOAD_K1_K2_FROM_SLOT(OUT(K1), OUT(K6), IN(R8))
   BC_LOAD_F64_FROM_SLOT_MASKED(OUT(Z4), OUT(Z5), IN(BX), IN(K1), IN(K6))
   BC_LOAD_BUCKET_FROM_SLOT(OUT(Z6), IN(DX), IN(K1))
-  BC_AGGREGATE_SLOT_MARK_OP(0, VMAXPD)
+  BC_AGGREGATE_SLOT_NAN_F64_BEGIN()
+  BC_AGGREGATE_SLOT_MARK_OP(0, VMAXPD, BC_AGGREGATE_NO_CHECK)
+  BC_AGGREGATE_SLOT_NAN_F64_END()
   NEXT_ADVANCE(BC_SLOT_SIZE*3 + BC_AGGSLOT_SIZE)
 
 // _ = aggslotmax.i64(a[0], l[1], s[2], k[3])
@@ -7216,7 +7600,7 @@ TEXT bcaggslotmaxi(SB), NOSPLIT|NOFRAME, $0
   BC_LOAD_K1_K2_FROM_SLOT(OUT(K1), OUT(K6), IN(R8))
   BC_LOAD_I64_FROM_SLOT_MASKED(OUT(Z4), OUT(Z5), IN(BX), IN(K1), IN(K6))
   BC_LOAD_BUCKET_FROM_SLOT(OUT(Z6), IN(DX), IN(K1))
-  BC_AGGREGATE_SLOT_MARK_OP(0, VPMAXSQ)
+  BC_AGGREGATE_SLOT_MARK_OP(0, VPMAXSQ, BC_AGGREGATE_NO_CHECK)
   NEXT_ADVANCE(BC_SLOT_SIZE*3 + BC_AGGSLOT_SIZE)
 
 // _ = aggslotand.i64(a[0], l[1], s[2], k[3])
@@ -7225,7 +7609,7 @@ TEXT bcaggslotandi(SB), NOSPLIT|NOFRAME, $0
   BC_LOAD_K1_K2_FROM_SLOT(OUT(K1), OUT(K6), IN(R8))
   BC_LOAD_I64_FROM_SLOT_MASKED(OUT(Z4), OUT(Z5), IN(BX), IN(K1), IN(K6))
   BC_LOAD_BUCKET_FROM_SLOT(OUT(Z6), IN(DX), IN(K1))
-  BC_AGGREGATE_SLOT_MARK_OP(0, VPANDQ)
+  BC_AGGREGATE_SLOT_MARK_OP(0, VPANDQ, BC_AGGREGATE_NO_CHECK)
   NEXT_ADVANCE(BC_SLOT_SIZE*3 + BC_AGGSLOT_SIZE)
 
 // _ = aggslotor.i64(a[0], l[1], s[2], k[3])
@@ -7234,7 +7618,7 @@ TEXT bcaggslotori(SB), NOSPLIT|NOFRAME, $0
   BC_LOAD_K1_K2_FROM_SLOT(OUT(K1), OUT(K6), IN(R8))
   BC_LOAD_I64_FROM_SLOT_MASKED(OUT(Z4), OUT(Z5), IN(BX), IN(K1), IN(K6))
   BC_LOAD_BUCKET_FROM_SLOT(OUT(Z6), IN(DX), IN(K1))
-  BC_AGGREGATE_SLOT_MARK_OP(0, VPORQ)
+  BC_AGGREGATE_SLOT_MARK_OP(0, VPORQ, BC_AGGREGATE_NO_CHECK)
   NEXT_ADVANCE(BC_SLOT_SIZE*3 + BC_AGGSLOT_SIZE)
 
 // _ = aggslotxor.i64(a[0], l[1], s[2], k[3])
@@ -7243,7 +7627,7 @@ TEXT bcaggslotxori(SB), NOSPLIT|NOFRAME, $0
   BC_LOAD_K1_K2_FROM_SLOT(OUT(K1), OUT(K6), IN(R8))
   BC_LOAD_I64_FROM_SLOT_MASKED(OUT(Z4), OUT(Z5), IN(BX), IN(K1), IN(K6))
   BC_LOAD_BUCKET_FROM_SLOT(OUT(Z6), IN(DX), IN(K1))
-  BC_AGGREGATE_SLOT_MARK_OP(0, VPXORQ)
+  BC_AGGREGATE_SLOT_MARK_OP(0, VPXORQ, BC_AGGREGATE_NO_CHECK)
   NEXT_ADVANCE(BC_SLOT_SIZE*3 + BC_AGGSLOT_SIZE)
 
 // COUNT is a special aggregation function that just counts active lanes stored
@@ -7363,6 +7747,32 @@ TEXT bcaggslotmergestate(SB), NOSPLIT|NOFRAME, $0
 
     NEXT_ADVANCE(BC_AGGSLOT_SIZE + BC_SLOT_SIZE*3)
 
+// _ = aggmode(a[0], v[1], k[2])
+//
+// Counts the occurrences of each numeric value
+// for MODE (see aggmode.go).
+//
+// Implementation notes:
+//   - this op is only implemented by the portable interpreter,
+//     so programs that use it never reach this code
+TEXT bcaggmode(SB), NOSPLIT|NOFRAME, $0
+  MOVL $const_bcerrNotSupported, bytecode_err(VIRT_BCPTR)
+  RET_ABORT()
+  NEXT_ADVANCE(BC_AGGSLOT_SIZE + BC_SLOT_SIZE*2) // unreachable; documents the instruction width
+
+// _ = aggslotmode(a[0], l[1], v[2], k[3])
+//
+// Counts the occurrences of each numeric value
+// for MODE in GROUP BY (see aggmode.go).
+//
+// Implementation notes:
+//   - this op is only implemented by the portable interpreter,
+//     so programs that use it never reach this code
+TEXT bcaggslotmode(SB), NOSPLIT|NOFRAME, $0
+  MOVL $const_bcerrNotSupported, bytecode_err(VIRT_BCPTR)
+  RET_ABORT()
+  NEXT_ADVANCE(BC_AGGSLOT_SIZE + BC_SLOT_SIZE*3) // unreachable; documents the instruction width
+
 
 // Uncategorized Instructions
 // --------------------------
@@ -7847,228 +8257,62 @@ varuint_length:
 //   - This function requires A to be already unsymbolized.
 //   - It's guaranteed that if the first 4 bytes match both A and B have the same length.
 TEXT bcarrayposition(SB), NOSPLIT|NOFRAME, $0
-  BC_UNPACK_3xSLOT(BC_SLOT_SIZE*2, OUT(BX), OUT(CX), OUT(R8))
-  BC_LOAD_SLICE_FROM_SLOT(OUT(Z0), OUT(Z1), IN(BX))
-  BC_LOAD_K1_FROM_SLOT(OUT(K1), IN(R8))
-
-  VPTESTMD Z1, Z1, K1, K1                              // K1 <- items to match (empty arrays discarded)
-  KTESTW K1, K1
-
-  MOVQ bytecode_symtab+0(VIRT_BCPTR), R8               // R8 <- symbol table base
-  BC_LOAD_VALUE_SLICE_FROM_SLOT(OUT(Z2), OUT(Z3), IN(CX)) // Z2:Z3 <- value to match
-
-  VPXORD X20, X20, X20                                 // Z20 <- current position in array (counted from 1)
-  VPXORD X21, X21, X21                                 // Z21 <- matched positions
-  VPBROADCASTD CONSTD_1(), Z22                         // Z22 <- dword(1)
-  VPBROADCASTD CONSTD_14(), Z23                        // Z23 <- dword(14)
-  VPBROADCASTD CONSTD_0x0F(), Z24                      // Z24 <- dword(0xF)
-  VPBROADCASTD CONSTD_32(), Z25                        // Z25 <- dword(32)
-  VPBROADCASTD CONSTD_0x7F(), Z26                      // Z26 <- dword(0x7F)
-  VPBROADCASTD CONSTD_0x00808080(), Z27                // Z27 <- dword(0x808080)
-  VPBROADCASTD CONSTD_7(), Z29                         // Z29 <- dword(7)
-  VBROADCASTI32X4 CONST_GET_PTR(bswap32, 0), Z30       // Z30 <- bswap32 predicate for VPSHUFB
-  VMOVDQU64 CONST_GET_PTR(consts_byte_mask_q, 0), Z31  // Z31 <- consts_byte_mask_q
-  JZ done
-
-  VEXTRACTI32X8 $1, Z2, Y14
-
-  KMOVW K1, K3
-  VPXORD X10, X10, X10
-  VPGATHERDQ 0(VIRT_BASE)(Y2*1), K3, Z10               // Z10 <- first 8 bytes of A (low)
-
-  KSHIFTRW $8, K1, K4
-  VPXORD X11, X11, X11
-  VPGATHERDQ 0(VIRT_BASE)(Y14*1), K4, Z11              // Z11 <- first 8 bytes of A (high)
-
-  VPADDD Z29, Z22, Z28                                 // Z28 <- dword(8)
-
-  VPMOVQD Z10, Y12
-  VPMOVQD Z11, Y13
-  VINSERTI32X8 $1, Y13, Z12, Z12                       // Z12 <- first 4 bytes of A
-  VPSRLD $4, Z12, Z13
-  VPANDD Z24, Z13, Z13                                 // Z13 <- type of A
-
-  VPMINUD Z28, Z3, Z8
-  VEXTRACTI32X8 $1, Z8, Y9
-  VPADDD.Z Z0, Z1, K1, Z1                              // Z1  <- end of the list
-  VPADDD.Z Z2, Z3, K1, Z3                              // Z3  <- end of the value
-
-  VPMOVZXDQ Y8, Z8
-  VPMOVZXDQ Y9, Z9
-  VPERMQ Z31, Z8, Z8                                   // Z8  <- leading byte mask
-  VPERMQ Z31, Z9, Z9                                   // Z9  <- leading byte mask
-
-  VPANDQ Z8, Z10, Z10
-  VPANDQ Z9, Z11, Z11
-
-  // K6 <- lanes in A that don't contain strings - this is important as we do
-  // not want to unsymbolize B values that would not compare against strings.
-  VPCMPD $VPCMP_IMM_NE, Z28, Z13, K1, K6
-
-  // Z29 <- comparisong predicate for unsymbolize - it either contains `7` for lanes that
-  // need to unsymbolize in case that they contain symbol, or a non-compatible type value
-  // for lanes that don't need unsymbolize (not comparing agains a string of A value).
-  // We can keep this predicate in K6, but there is much less K registers than ZMM registers
-  // so it's just better to keep this in ZMM register and have one more K register available.
-  VMOVDQA32 Z26, K6, Z29
-
-array_loop:
-  KSHIFTRW $8, K1, K2
-  VEXTRACTI32X8 $1, Z0, Y13
-
-  KMOVB K1, K3
-  VPXORD X18, X18, X18
-  VPGATHERDQ 0(VIRT_BASE)(Y0*1), K3, Z18               // Z18 <- first 8 bytes of B (low)
-
-  KMOVB K2, K4
-  VPADDD.Z Z22, Z20, K1, Z20                           // Z20 <- advance current position
-
-  VPXORD X19, X19, X19
-  VPGATHERDQ 0(VIRT_BASE)(Y13*1), K4, Z19              // Z19 <- first 8 bytes of B (high)
-
-  VPMOVQD Z18, Y14
-  VPMOVQD Z19, Y13
-  VINSERTI32X8 $1, Y13, Z14, Z13                       // Z13 <- first 4 bytes of B (B.hdr32)
-
-  VPSRLD $4, Z13, Z14                                  // Z14 <- B.hdr32 >> 4
-  VPSHUFB Z30, Z13, Z17                                // Z17 <- bswap32(B.hdr32)
-  VPANDD Z24, Z14, Z14                                 // Z14 <- B.type
-  VPANDD Z27, Z17, Z16                                 // Z16 <- bswap32(B.hdr32) & 0x00808080
-  VPCMPUD $VPCMP_IMM_GT, Z22, Z14, K1, K3              // K3  <- B.type != NULL|BOOL
-  VPANDND Z17, Z27, Z6                                 // Z6  <- bswap32(B.hdr32) & 0xFF7F7F7F
-
-  VPLZCNTD.Z Z16, K3, Z16                              // Z16 <- lzcnt(bswap32(B.hdr32) & 0x00808080)
-  VPCMPEQD Z29, Z14, K1, K4                            // K4  <- B.type == SYMBOL that needs to be unsymbolized
-  VPANDD.Z Z24, Z13, K3, Z15                           // Z15 <- B.L or zero if B.type == NULL|BOOL
-  KTESTW K4, K4
-
-  VPSUBD Z16, Z25, Z14                                 // Z14 <- 32 - lzcnt(bswap32(B.hdr32) & 0x00808080) (number of bits to discard)
-  VPCMPEQD Z23, Z15, K1, K3                            // K3  <- B.L == 14 (required to decode Length field)
-  VPSLLD $8, Z6, Z13                                   // Z13 <- (bswap32(B.hdr32) & 0xFF7F7F7F) << 8
-  VPSRLVD Z14, Z13, K3, Z15                            // Z15 <- B.L or B.optLen [00000000|0CCCCCCC|0BBBBBBB|0AAAAAAA]
-  VPSRLD.Z $3, Z16, K3, Z16                            // Z16 <- B.hLen - 1
-
-  VPSRLD $1, Z15, Z13                                  // Z13 <- B.dataLen >> 1  [00000000|00CCCCCC|C0BBBBBB|B0AAAAAA]
-  VPSRLD $2, Z15, Z14                                  // Z14 <- B.dataLen >> 2  [00000000|000CCCCC|CC0BBBBB|BB0AAAAA]
-  VPTERNLOGD $TLOG_BLEND_AB, Z26, Z13, Z15             // Z15 <- B.dataLen as    [00000000|00CCCCCC|C0BBBBBB|BAAAAAAA]
-  VPADDD Z22, Z16, Z16                                 // Z16 <- B.hLen
-  VPTERNLOGD.BCST $TLOG_BLEND_AB, CONSTD_0x3FFF(), Z14, Z15 // Z15 <- B.dataLen
-
-  VPADDD Z28, Z2, Z6                                   // Z6  <- A.offset + 8
-  VPADDD Z16, Z15, Z14                                 // Z14 <- B.valLen
-  VPADDD Z28, Z0, Z7                                   // Z7  <- B.offset + 8
-  VPADDD Z14, Z0, Z0                                   // Z0  <- advance array by the current value length
-  JZ skip_unsymbolize
-
-  VPSLLD $8, Z17, Z17                                  // Z17 <- bswap32(B.hdr32) << 8 (symbol data)
-  VPSLLD $3, Z15, Z14                                  // Z14 <- B.dataLen << 3 (data length in bits)
-  VPSUBD Z14, Z25, Z14                                 // Z14 <- 32 - B.dataLen << 3 (number of bits to discard in Z13)
-  VPSRLVD.Z Z14, Z17, K4, Z17                          // Z17 <- extracted SymbolIDs from B
-  VPCMPUD.BCST $VPCMP_IMM_LT, bytecode_symtab+8(VIRT_BCPTR), Z17, K4, K4 // K4 <- only unsymbolize symbols present in symtab
-
-  KMOVW K4, K5
-  VPGATHERDD 0(R8)(Z17*8), K5, Z7                      // gather 16 symbol offsets (we don't care of lengths in this case)
-
-  KMOVB K4, K5
-  VPGATHERDQ 0(VIRT_BASE)(Y7*1), K5, Z18               // Z18 <- merge first 8 bytes of B to the existing vector (low)
-
-  VEXTRACTI32X8 $1, Z7, Y13
-  KSHIFTRW $8, K4, K5
-  VPGATHERDQ 0(VIRT_BASE)(Y13*1), K5, Z19              // Z19 <- merge first 8 bytes of B to the existing vector (high)
-
-  VPADDD Z28, Z7, K4, Z7                               // Z7  <- B.offset += 8 (where symbols)
-
-skip_unsymbolize:
-  // first 8 bytes of A in (Z10:Z11) and first 8 bytes of B in (Z18:Z19)
-  VPANDQ Z8, Z18, Z18                                  // Z18 <- B.hdr64 & lead_mask (low)
-  VPANDQ Z9, Z19, Z19                                  // Z19 <- B.hdr64 & lead_mask (high)
-  VPCMPEQQ Z10, Z18, K1, K3                            // K3  <- A.hdr64 == B.hdr64 (low)
-  VPCMPEQQ Z11, Z19, K2, K4                            // K4  <- A.hdr64 == B.hdr64 (high)
-  KORTESTB K3, K4
-
-  KUNPCKBW K3, K4, K3                                  // K3  <- A.hdr64 == B.hdr64
-  JZ array_advance                                     // bail early if hdr64 values don't match
-
-  // at least one lane matched all leading bytes - this means that both A and B have a compatible
-  // header, which implies that they have the same length as well (as length is part of the header).
-
-  VPCMPUD $VPCMP_IMM_GE, Z3, Z6, K3, K4                // K4  <- lanes that matched (offset >= end)
-  KANDNW K3, K4, K3                                    // K3  <- remaining lanes where to continue value comparison
-  KTESTW K3, K3
+#define BC_GENERATE_ARRAY_POSITION
+#include "evalbc_arrayposition_impl.h"
+#undef BC_GENERATE_ARRAY_POSITION
 
-  VMOVDQA32 Z20, K4, Z21                               // Z21 <- update matched positions
-  KANDNW K1, K4, K1
-  JZ array_advance
-
-value_loop:
-  KSHIFTRW $8, K3, K4
-  VEXTRACTI32X8 $1, Z6, Y15
-
-  KMOVB K3, K5
-  VPXORD X16, X16, X16
-  VPGATHERDQ 0(VIRT_BASE)(Y6*1), K5, Z16               // Z16 <- next 8 bytes of A (low)
-
-  VPSUBD Z6, Z3, Z13                                   // Z13 <- remaining_length
-  VPADDD Z28, Z6, Z6                                   // Z6  <- A.offset += 8
-
-  KMOVB K4, K5
-  VPXORD X17, X17, X17
-  VPGATHERDQ 0(VIRT_BASE)(Y15*1), K5, Z17              // Z17 <- next 8 bytes of A (high)
-
-  VPMINUD.Z Z28, Z13, K3, Z13                          // Z13 <- min(remaining_length, 8)
-  VEXTRACTI32X8 $1, Z7, Y15
-
-  KMOVB K3, K5
-  VPXORD X18, X18, X18
-  VPGATHERDQ 0(VIRT_BASE)(Y7*1), K5, Z18               // Z18 <- next 8 bytes of B (low)
-
-  VPADDD Z28, Z7, Z7                                   // Z7  <- B.offset += 8
-  VEXTRACTI32X8 $1, Z13, Y14
-  VPMOVZXDQ Y13, Z13                                   // Z13 <- min(remaining_length, 8) (low)
-
-  KMOVB K4, K5
-  VPXORD X19, X19, X19
-  VPGATHERDQ 0(VIRT_BASE)(Y15*1), K5, Z19              // Z19 <- next 8 bytes of B (high)
-
-  VPMOVZXDQ Y14, Z14                                   // Z14 <- min(remaining_length, 8) (high)
-  VPERMQ Z31, Z13, Z13                                 // Z14 <- compare byte mask (low)
-  VPERMQ Z31, Z14, Z14                                 // Z15 <- compare byte mask (high)
-
-  // 0x28 == (A ^ B) & C
-  VPTERNLOGQ $0x28, Z13, Z16, Z18                      // Z18 <- each QWORD lane contains zero if equal (low)
-  VPTERNLOGQ $0x28, Z14, Z17, Z19                      // Z19 <- each QWORD lane contains zero if equal (high)
-
-  VPTESTNMQ Z18, Z18, K3, K3                           // K3  <- lanes where 64-bit data or tail bytes are equal (low)
-  VPTESTNMQ Z19, Z19, K4, K4                           // K4  <- lanes where 64-bit data or tail bytes are equal (high)
-  KUNPCKBW K3, K4, K3                                  // K3  <- lanes where 64-bit data or tail bytes are equal
-
-  VPCMPUD $VPCMP_IMM_GE, Z3, Z6, K3, K4                // K4  <- lanes that matched (offset >= end)
-  KANDNW K3, K4, K3                                    // K3  <- remaining lanes where to continue value comparison
-  KANDNW K1, K4, K1                                    // K1  <- remaining lanes where to match the next value
-
-  VPCMPUD $VPCMP_IMM_LT, Z3, Z6, K3, K3                // K3  <- remaining lanes where to continue value comparison (and have data)
-  VMOVDQA32 Z20, K4, Z21                               // Z21 <- update matched positions
-
-  KTESTW K3, K3
-  JNZ value_loop                                       // continue if we don't have a match yet and there are more bytes to compare
-
-array_advance:
-  VPCMPUD $VPCMP_IMM_LT, Z1, Z0, K1, K1                // K1 <- remaining lanes to compare
-  KTESTW K1, K1
-  JNZ array_loop
+// k[0] = arraycontains(s[1], v[2]).k[3]
+//
+// Legend:
+//   - 'A' - refers to v[2] (the item to match)
+//   - 'B' - refers to values stored in s[1]
+//
+// NOTES:
+//   - This function requires A to be already unsymbolized.
+//   - The scan of each list stops at the first match.
+TEXT bcarraycontains(SB), NOSPLIT|NOFRAME, $0
+#define BC_GENERATE_ARRAY_CONTAINS
+#include "evalbc_arrayposition_impl.h"
+#undef BC_GENERATE_ARRAY_CONTAINS
 
-done:
-  // extend UINT32 positions to INT64
-  VEXTRACTI32X8 $1, Z21, Y22
-  VPTESTMD Z21, Z21, K1
-  VPMOVZXDQ Y21, Z21
-  VPMOVZXDQ Y22, Z22
+// v[0].k[1] = arrayindex(s[2], i64[3]).k[4]
+//
+// Selects the value at the zero-based position i64[3]
+// of each list; lanes where the position is out of range
+// (including negative positions) are MISSING.
+//
+// Implementation notes:
+//   - the list is walked by the portable implementation
+TEXT bcarrayindex(SB), NOSPLIT|NOFRAME, $0
+  BC_CALL_PORTABLE()
+  NEXT_ADVANCE(BC_SLOT_SIZE*5) // unreachable; documents the instruction width
 
-  BC_UNPACK_2xSLOT(0, OUT(DX), OUT(R8))
-  BC_STORE_I64_TO_SLOT(IN(Z21), IN(Z22), IN(DX))
-  BC_STORE_K_TO_SLOT(IN(K1), IN(R8))
+// s[0].k[1] = arrayslice(s[2], i64[3], i64[4]).k[5]
+//
+// Selects the elements of each list in the half-open
+// range [i64[3], i64[4]); negative bounds count backwards
+// from the end of the list and out-of-range bounds are
+// clamped to the list.
+//
+// Implementation notes:
+//   - the list is walked by the portable implementation
+TEXT bcarrayslice(SB), NOSPLIT|NOFRAME, $0
+  BC_CALL_PORTABLE()
+  NEXT_ADVANCE(BC_SLOT_SIZE*6) // unreachable; documents the instruction width
 
-  NEXT_ADVANCE(BC_SLOT_SIZE*5)
+// s[0].k[1] = transform(s[2], imm16[3]).k[4]
+//
+// scratch: PageSize
+//
+// Evaluates the program lambdas[imm16[3]] for each
+// element of each list and collects the results
+// into new lists.
+//
+// Implementation notes:
+//   - the lambda is evaluated by the portable implementation
+TEXT bctransform(SB), NOSPLIT|NOFRAME, $0
+  BC_CALL_PORTABLE()
+  NEXT_ADVANCE(BC_SLOT_SIZE*4 + BC_IMM16_SIZE) // unreachable; documents the instruction width
 
 // Array iterator is a construct that can be used to iterate arrays where
 // numeric values are expected. It iterates over all items, and masks out
@@ -9805,6 +10049,96 @@ next:
   NEXT_ADVANCE(BC_SLOT_SIZE*3)
 //; #endregion bcTrimWsRight
 
+//; #region bcTrimWsBoth
+//
+// slice[0] = trim_ws_both(slice[1]).k[2]
+//
+// a fused trim_ws_left + trim_ws_right that keeps
+// the slice in registers between the two loops
+TEXT bcTrimWsBoth(SB), NOSPLIT|NOFRAME, $0
+  BC_UNPACK_2xSLOT(BC_SLOT_SIZE*1, OUT(BX), OUT(R8))
+  BC_LOAD_SLICE_FROM_SLOT(OUT(Z2), OUT(Z3), IN(BX))
+  BC_LOAD_K1_FROM_SLOT(OUT(K1), IN(R8))
+
+  VMOVDQU32     bswap32<>(SB),Z22         //;2510A88F load constant_bswap32           ;Z22=constant_bswap32;
+  VPBROADCASTD  CONSTD_4(),Z20            //;C8AFBE50 load constant 4                 ;Z20=4;
+  VPXORD        Z11, Z11, Z11             //;F4B92302 constd_0 := 0                   ;Z11=0;
+  MOVL          $0xD0920,R14              //;4AB6FA4E                                 ;R14=scratch;
+  VPBROADCASTB  R14, Z15                  //;7D467BFE load whitespace                 ;Z15=c_char_space; R14=scratch;
+  SHRL          $8,  R14                  //;69731820 scratch >>= 8                   ;R14=scratch;
+  VPBROADCASTB  R14, Z16                  //;1FD6A756 load tab                        ;Z16=c_char_tab; R14=scratch;
+  SHRL          $8,  R14                  //;FA1E61C9 scratch >>= 8                   ;R14=scratch;
+  VPBROADCASTB  R14, Z17                  //;14E0AB16 load cr                         ;Z17=c_char_cr; R14=scratch;
+loop_left:
+  KMOVW         K1,  K3                   //;723D04C9 copy eligible lanes             ;K3=tmp_mask; K1=lane_active;
+  VPGATHERDD    (VIRT_BASE)(Z2*1),K3,  Z8 //;68B7D88C gather data                     ;Z8=data; K3=tmp_mask; SI=data_ptr; Z2=data_off;
+//; trim left/right whitespace comparison
+  VPCMPB        $0,  Z15, Z8,  K3         //;529F46B9 K3 := (data==c_char_space); test if equal to SPACE char;K3=tmp_mask; Z8=data; Z15=c_char_space; 0=Eq;
+  VPCMPB        $2,  Z8,  Z16, K2         //;AD553F19 K2 := (c_char_tab<=data); is TAB (0x09) <= char;K2=scratch2_mask; Z16=c_char_tab; Z8=data; 2=LessEq;
+  VPCMPB        $2,  Z17, Z8,  K2,  K2    //;6BC60637 K2 &= (data<=c_char_cr); and is char <= CR (0x0D);K2=scratch2_mask; Z8=data; Z17=c_char_cr; 2=LessEq;
+  KORQ          K3,  K2,  K3              //;B7E1D9EF tmp_mask |= scratch2_mask       ;K3=tmp_mask; K2=scratch2_mask;
+  KTESTQ        K3,  K3                   //;A522D4C2 1 for every whitespace          ;K3=tmp_mask;
+  JZ            right                     //;DC07C307 no matching chars found: no need to update string_start_position; jump if zero (ZF = 1);
+//; trim comparison done: K3 contains matched characters
+
+//; convert mask K3 to selected byte count zmm8
+  VPMOVM2B      K3,  Z8                   //;B0C4D1C5 promote 64x bit to 64x byte     ;Z8=data; K3=tmp_mask;
+  VPTERNLOGQ    $0b00001111,Z8,  Z8,  Z8  //;249B4036 negate                          ;Z8=data;
+  VPSHUFB       Z22, Z8,  Z8              //;8CF1488E reverse byte order              ;Z8=data; Z22=constant_bswap32;
+  VPLZCNTD      Z8,  K1,  Z8              //;90920F43 count leading zeros             ;Z8=data; K1=lane_active;
+  VPSRLD        $3,  Z8,  K1,  Z8         //;68276EFE divide by 8 yields byte_count   ;Z8=data; K1=lane_active;
+  VPMINSD       Z3,  Z8,  K1,  Z8         //;6616691F take minimun of length          ;Z8=data; K1=lane_active; Z3=data_len;
+//; done convert mask: zmm8 = #bytes
+
+  VPADDD        Z8,  Z2,  K1,  Z2         //;40C40F7D data_off += data                ;Z2=data_off; K1=lane_active; Z8=data;
+  VPSUBD        Z8,  Z3,  K1,  Z3         //;63A2C77B data_len -= data                ;Z3=data_len; K1=lane_active; Z8=data;
+  VPCMPD        $2,  Z3,  Z11, K1,  K2    //;94B55922 K2 := K1 & (0<=data_len)        ;K2=scratch_mask1; K1=lane_active; Z11=0; Z3=data_len; 2=LessEq;
+  VPCMPD        $0,  Z20, Z8,  K2,  K2    //;D3BA3C05 K2 &= (data==4)                 ;K2=scratch_mask1; Z8=data; Z20=4; 0=Eq;
+  KTESTW        K2,  K2                   //;7CB2A200 ZF := (K2==0); CF := 1          ;K2=scratch_mask1;
+  JNZ           loop_left                 //;7E49CD56 jump if not zero (ZF = 0)       ;
+
+right:
+  VPADDD        Z3,  Z2,  Z4              //;813A5F04 data_end := data_off + data_len ;Z4=data_end; Z2=data_off; Z3=data_len;
+loop_right:
+  VPTESTMD      Z3,  Z3,  K1,  K3         //;6639548B K3 := K1 & (data_len != 0)      ;K3=tmp_mask; K1=lane_active; Z3=data_len;
+//; calculate offset that is always positive
+  VPMINUD       Z20, Z3,  Z23             //;B086F272 adjust := min(data_len, 4)      ;Z23=adjust; Z3=data_len; Z20=4;
+  VPSUBD        Z23, Z4,  Z5              //;998E9936 offset := data_end - adjust     ;Z5=offset; Z4=data_end; Z23=adjust;
+  VPXORD        Z8,  Z8,  Z8              //;1882D069 data := 0                       ;Z8=data;
+  VPGATHERDD    (VIRT_BASE)(Z5*1),K3,  Z8 //;30D04944 gather data from end            ;Z8=data; K3=tmp_mask; SI=data_ptr; Z5=offset;
+//; adjust data
+  VPSUBD        Z23, Z20, Z23             //;83BCC5BB adjust := 4 - adjust            ;Z23=adjust; Z20=4;
+  VPSLLD        $3,  Z23, Z23             //;D2F273B1 times 8 gives number of bytes   ;Z23=adjust;
+  VPSLLVD       Z23, Z8,  Z8              //;67300525 data <<= adjust                 ;Z8=data; Z23=adjust;
+//; trim left/right whitespace comparison
+  VPCMPB        $0,  Z15, Z8,  K3         //;529F46B9 K3 := (data==c_char_space); test if equal to SPACE char;K3=tmp_mask; Z8=data; Z15=c_char_space; 0=Eq;
+  VPCMPB        $2,  Z8,  Z16, K2         //;AD553F19 K2 := (c_char_tab<=data); is TAB (0x09) <= char;K2=scratch2_mask; Z16=c_char_tab; Z8=data; 2=LessEq;
+  VPCMPB        $2,  Z17, Z8,  K2,  K2    //;6BC60637 K2 &= (data<=c_char_cr); and is char <= CR (0x0D);K2=scratch2_mask; Z8=data; Z17=c_char_cr; 2=LessEq;
+  KORQ          K3,  K2,  K3              //;B7E1D9EF tmp_mask |= scratch2_mask       ;K3=tmp_mask; K2=scratch2_mask;
+  KTESTQ        K3,  K3                   //;A522D4C2 1 for every whitespace          ;K3=tmp_mask;
+  JZ            next                      //;DC07C307 no matching chars found: no need to update string_start_position; jump if zero (ZF = 1);
+//; trim comparison done: K3 contains matched characters
+
+//; convert mask K3 to selected byte count zmm7
+  VPMOVM2B      K3,  Z7                   //;B0C4D1C5 promote 64x bit to 64x byte     ;Z7=n_bytes_to_trim; K3=tmp_mask;
+  VPTERNLOGQ    $0b00001111,Z7,  Z7,  Z7  //;249B4036 negate                          ;Z7=n_bytes_to_trim;
+  VPLZCNTD      Z7,  K1,  Z7              //;90920F43 count leading zeros             ;Z7=n_bytes_to_trim; K1=lane_active;
+  VPSRLD        $3,  Z7,  K1,  Z7         //;68276EFE divide by 8 yields byte_count   ;Z7=n_bytes_to_trim; K1=lane_active;
+  VPMINSD       Z3,  Z7,  K1,  Z7         //;6616691F take minimun of length          ;Z7=n_bytes_to_trim; K1=lane_active; Z3=data_len;
+//; done convert mask: zmm7 = #bytes
+
+  VPSUBD        Z7,  Z4,  K1,  Z4         //;40C40F7D data_end -= n_bytes_to_trim     ;Z4=data_end; K1=lane_active; Z7=n_bytes_to_trim;
+  VPSUBD        Z7,  Z3,  K1,  Z3         //;63A2C77B data_len -= n_bytes_to_trim     ;Z3=data_len; K1=lane_active; Z7=n_bytes_to_trim;
+  VPCMPD        $0,  Z20, Z7,  K3         //;D3BA3C05 K3 := (n_bytes_to_trim==4)      ;K3=tmp_mask; Z7=n_bytes_to_trim; Z20=4; 0=Eq;
+  KTESTW        K1,  K3                   //;7CB2A200 more chars to trim?             ;K3=tmp_mask; K1=lane_active;
+  JNZ           loop_right                //;7E49CD56 yes, then loop; jump if not zero (ZF = 0);
+
+next:
+  BC_UNPACK_SLOT(0, OUT(DX))
+  BC_STORE_SLICE_TO_SLOT(IN(Z2), IN(Z3), IN(DX))
+  NEXT_ADVANCE(BC_SLOT_SIZE*3)
+//; #endregion bcTrimWsBoth
+
 //; #region bcTrim4charLeft
 //
 // slice[0] = trim_char_left(slice[1], dict[2]).k[3]
@@ -10183,6 +10517,257 @@ next:
   NEXT_ADVANCE(BC_SLOT_SIZE*5 + BC_DICT_SIZE)
 //; #endregion bcSplitPart
 
+// s[0].k[1] = translate(s[2], dict[3]).k[4]
+//
+// scratch: PageSize
+//
+// Replaces each character found in the lookup table built by translateTable(),
+// see interp_string.go for the table layout. Characters are processed one lane
+// at a time, the output is written to scratch with 4-byte stores.
+TEXT bcTranslate(SB), NOSPLIT|NOFRAME, $0
+  BC_UNPACK_SLOT(BC_SLOT_SIZE*2, OUT(BX))
+  BC_UNPACK_DICT(BC_SLOT_SIZE*3, OUT(R14))
+  BC_UNPACK_SLOT(BC_SLOT_SIZE*3+BC_DICT_SIZE, OUT(R8))
+  BC_LOAD_SLICE_FROM_SLOT(OUT(Z2), OUT(Z3), IN(BX))
+  BC_LOAD_K1_FROM_SLOT(OUT(K1), IN(R8))
+
+  MOVQ 0(R14), R14                                     // R14 <- lookup table
+  VPMULLD.BCST 4(R14), Z3, Z4                          // Z4 <- worst case output length
+
+  // R15 (DstSum), Z5 (DstOff), Z7 (DstLen), Z4 (DstEnd), K1 (DstMask)
+  BC_HORIZONTAL_LENGTH_SUM(OUT(R15), OUT(Z5), OUT(Z7), OUT(Z4), OUT(K1), IN(Z4), IN(K1), X10, K2)
+  ADDQ $3, R15                                         // R15 <- room for the last 4-byte store
+
+  BC_ALLOC_SLICE(OUT(Z6), IN(R15), CX, R8)             // Z6 <- Offset of the beginning of the allocated buffer
+  VPADDD.Z Z5, Z6, K1, Z6                              // Z6 <- Output offsets
+
+  VMOVDQU32 Z2, BC_SPILL_AREA(0)                       // [] <- Input offsets
+  VMOVDQU32 Z3, BC_SPILL_AREA(64)                      // [] <- Input lengths
+  VMOVDQU32 Z6, BC_SPILL_AREA(128)                     // [] <- Output offsets
+  VPXORD X7, X7, X7
+  VMOVDQU32 Z7, BC_SPILL_AREA(192)                     // [] <- Output lengths, initially zero
+
+  MOVL 0(R14), R13                                     // R13 <- number of table entries
+  ADDQ $8, R14                                         // R14 <- first table entry
+  LEAQ 0(R13)(R13*2), R13
+  LEAQ 0(R14)(R13*4), R13                              // R13 <- end of table entries
+
+  KMOVW K1, DX                                         // DX <- lanes to process
+  TESTL DX, DX
+  JZ done
+
+lane_iter:
+  TZCNTL DX, CX                                        // CX <- Index of the lane to process
+  BLSRL DX, DX                                         // DX <- Clear the index of the iterator
+  VMOVQ DX, X12                                        // X12 <- spilled lane iterator
+  VMOVQ CX, X13                                        // X13 <- spilled lane index
+
+  MOVL BC_SPILL_AREA_INDEX(0, CX*4), BX                // BX <- Input offset
+  MOVL BC_SPILL_AREA_INDEX(64, CX*4), R8               // R8 <- Remaining input length
+  MOVL BC_SPILL_AREA_INDEX(128, CX*4), R15             // R15 <- Output offset
+  ADDQ VIRT_BASE, BX                                   // BX <- Make input address from input offset
+  ADDQ VIRT_BASE, R15                                  // R15 <- Make output address from output offset
+  VMOVQ R15, X14                                       // X14 <- spilled output start
+
+  TESTL R8, R8
+  JZ lane_done
+
+char_iter:
+  MOVL 0(BX), DX                                       // DX <- next 4 bytes of input
+  MOVL DX, CX
+  NOTL CX
+  SHLL $24, CX
+  LZCNTL CX, CX                                        // CX <- Number of leading ones of the first byte
+
+  MOVL $1, R11
+  CMPL CX, $2
+  CMOVLLT R11, CX                                      // CX <- 1 for ASCII and continuation bytes
+  CMPL CX, $4
+  CMOVLGT R11, CX                                      // CX <- 1 for invalid leading bytes
+  CMPL CX, R8
+  CMOVLGT R8, CX                                       // CX <- min(CX, remaining length)
+
+  LEAL 0(CX*8), R11
+  BZHIL R11, DX, DX                                    // DX <- the current character, zero extended
+  ADDQ CX, BX
+  SUBL CX, R8
+
+  MOVQ R14, R11                                        // R11 <- table iterator
+
+lookup_iter:
+  CMPQ R11, R13
+  JEQ lookup_miss
+  CMPL 0(R11), DX
+  JEQ lookup_hit
+  ADDQ $12, R11
+  JMP lookup_iter
+
+lookup_hit:
+  MOVL 4(R11), DX                                      // DX <- replacement
+  MOVL 8(R11), CX                                      // CX <- replacement length
+
+lookup_miss:
+  MOVL DX, 0(R15)
+  ADDQ CX, R15
+
+  TESTL R8, R8
+  JNZ char_iter
+
+lane_done:
+  VMOVQ X13, CX                                        // CX <- Reload lane index
+  VMOVQ X14, BX                                        // BX <- Reload output start
+  SUBQ BX, R15                                         // R15 <- Output length
+  MOVL R15, BC_SPILL_AREA_INDEX(192, CX*4)
+
+  VMOVQ X12, DX                                        // DX <- Reload lane iterator
+  TESTL DX, DX
+  JNZ lane_iter
+
+done:
+  VMOVDQU32 BC_SPILL_AREA(192), Z3                     // Z3 <- Output lengths
+  BC_UNPACK_2xSLOT(0, OUT(DX), OUT(R8))
+  BC_STORE_SLICE_TO_SLOT(IN(Z6), IN(Z3), IN(DX))
+  BC_STORE_K_TO_SLOT(IN(K1), IN(R8))
+  NEXT_ADVANCE(BC_SLOT_SIZE*4 + BC_DICT_SIZE)
+
+  _BC_ERROR_HANDLER_MORE_SCRATCH()
+
+// i64[0].k[1] = codepoint(s[2]).k[3]
+//
+// Decodes the first UTF-8 character of each string. Empty strings and strings
+// that start with a malformed sequence (invalid leading byte, truncated sequence,
+// or a missing continuation byte) yield MISSING. Overlong encodings are accepted.
+TEXT bccodepoint(SB), NOSPLIT|NOFRAME, $0
+  BC_UNPACK_2xSLOT(BC_SLOT_SIZE*2, OUT(BX), OUT(R8))
+  BC_LOAD_SLICE_FROM_SLOT(OUT(Z2), OUT(Z3), IN(BX))
+  BC_LOAD_K1_FROM_SLOT(OUT(K1), IN(R8))
+
+  VPTESTMD Z3, Z3, K1, K1                              // K1 <- lanes having non-empty strings
+  KMOVW K1, K2
+  VPXORD X4, X4, X4
+  VPGATHERDD (VIRT_BASE)(Z2*1), K2, Z4                 // Z4 <- first 4 bytes of each string
+
+  VPBROADCASTD CONSTD_1(), Z10                         // Z10 <- dword(1)
+  VPSLLD $24, Z4, Z5
+  VPTERNLOGD $0x55, Z5, Z5, Z5
+  VPLZCNTD Z5, Z5                                      // Z5 <- number of leading ones of the first byte
+
+  VPCMPUD $VPCMP_IMM_NE, Z10, Z5, K1, K1               // K1 <- lanes not starting with a continuation byte
+  VPCMPUD.BCST $VPCMP_IMM_LE, CONSTD_4(), Z5, K1, K1   // K1 <- lanes starting with a valid leading byte
+  VPMAXUD Z10, Z5, Z6                                  // Z6 <- length of the sequence
+  VPCMPUD $VPCMP_IMM_LE, Z3, Z6, K1, K1                // K1 <- lanes having the whole sequence
+
+  // every byte following the leading byte must be 0b10xxxxxx
+  VPANDD.BCST CONSTD_0xC0C0C0C0(), Z4, Z7
+  VPXORD.BCST CONSTD_0x80808080(), Z7, Z7              // Z7 <- zero bytes where continuation bytes are
+  VPSLLD $3, Z6, Z8
+  VPTERNLOGD $0xFF, Z9, Z9, Z9
+  VPSLLVD Z8, Z9, Z8
+  VPANDND.BCST CONSTD_0xFFFFFF00(), Z8, Z8             // Z8 <- mask of continuation bytes of the sequence
+  VPTESTNMD Z8, Z7, K1, K1                             // K1 <- lanes having valid continuation bytes
+
+  // extract the payload bits and merge them into [b0:b1:b2:b3] 6-bit groups
+  VPBROADCASTD CONSTD_0x7F(), Z8
+  VPSRLVD Z5, Z8, Z8
+  VPORD.BCST CONSTD_0x3F3F3F00(), Z8, Z8               // Z8 <- payload bits of each byte
+  VPANDD Z8, Z4, Z4
+  VPBROADCASTD CONSTD_0x01400140(), Z8
+  VPMADDUBSW Z8, Z4, Z4                                // Z4 <- [b0 << 6 | b1, b2 << 6 | b3] words
+  VPBROADCASTD CONSTD_0x00011000(), Z8
+  VPMADDWD Z8, Z4, Z4                                  // Z4 <- b0 << 18 | b1 << 12 | b2 << 6 | b3
+
+  VPSLLD $1, Z6, Z7
+  VPSLLD $2, Z6, Z8
+  VPADDD Z7, Z8, Z7
+  VPBROADCASTD CONSTD_24(), Z8
+  VPSUBD Z7, Z8, Z7                                    // Z7 <- 24 - 6 * length
+  VPSRLVD Z7, Z4, Z4                                   // Z4 <- decoded code points
+
+  KSHIFTRW $8, K1, K2
+  VEXTRACTI32X8 $1, Z4, Y5
+  VPMOVZXDQ.Z Y4, K1, Z2
+  VPMOVZXDQ.Z Y5, K2, Z3
+
+  BC_UNPACK_2xSLOT(0, OUT(DX), OUT(R8))
+  BC_STORE_I64_TO_SLOT(IN(Z2), IN(Z3), IN(DX))
+  BC_STORE_K_TO_SLOT(IN(K1), IN(R8))
+  NEXT_ADVANCE(BC_SLOT_SIZE*4)
+
+// s[0].k[1] = chr(i64[2]).k[3]
+//
+// scratch: 4 * 16
+//
+// Encodes a code point as a single character UTF-8 string. Lanes that don't hold
+// a valid Unicode scalar value (negative, surrogates, or above U+10FFFF) yield MISSING.
+TEXT bcchr(SB), NOSPLIT|NOFRAME, $0
+  BC_UNPACK_2xSLOT(BC_SLOT_SIZE*2, OUT(BX), OUT(R8))
+  BC_LOAD_K1_FROM_SLOT(OUT(K1), IN(R8))
+  BC_LOAD_I64_FROM_SLOT(OUT(Z2), OUT(Z3), IN(BX))
+
+  KSHIFTRW $8, K1, K2
+  VPCMPUQ.BCST $VPCMP_IMM_LE, CONSTQ_0x10FFFF(), Z2, K1, K1
+  VPCMPUQ.BCST $VPCMP_IMM_LE, CONSTQ_0x10FFFF(), Z3, K2, K2
+  KUNPCKBW K1, K2, K1                                  // K1 <- lanes within the Unicode range
+
+  VPMOVQD Z2, Y4
+  VPMOVQD Z3, Y5
+  VINSERTI32X8 $1, Y5, Z4, Z4                          // Z4 <- code points as dwords
+
+  VPANDD.BCST CONSTD_0xFFFFF800(), Z4, Z5
+  VPCMPUD.BCST $VPCMP_IMM_NE, CONSTD_0xD800(), Z5, K1, K1 // K1 <- lanes that are not surrogates
+
+  // Z6 <- length of the encoded character
+  VPBROADCASTD CONSTD_1(), Z10
+  VMOVDQA32 Z10, Z6
+  VPCMPUD.BCST $VPCMP_IMM_GE, CONSTD_0x80(), Z4, K2    // K2 <- lanes that need more than 1 byte
+  VPADDD Z10, Z6, K2, Z6
+  VPCMPUD.BCST $VPCMP_IMM_GE, CONSTD_0x800(), Z4, K3
+  VPADDD Z10, Z6, K3, Z6
+  VPCMPUD.BCST $VPCMP_IMM_GE, CONSTD_0x10000(), Z4, K3
+  VPADDD Z10, Z6, K3, Z6
+
+  // Z7 <- [cp >> 18 : (cp >> 12) & 0x3F : (cp >> 6) & 0x3F : cp & 0x3F] bytes
+  VPSRLD $18, Z4, Z7
+  VPSRLD $4, Z4, Z8
+  VPTERNLOGD.BCST $0xF8, CONSTD_0x3F00(), Z8, Z7
+  VPSLLD $10, Z4, Z8
+  VPTERNLOGD.BCST $0xF8, CONSTD_0x3F0000(), Z8, Z7
+  VPSLLD $24, Z4, Z8
+  VPTERNLOGD.BCST $0xF8, CONSTD_0x3F000000(), Z8, Z7
+
+  // drop the unused leading groups and add the UTF-8 prefixes
+  VPBROADCASTD CONSTD_4(), Z8
+  VPSUBD Z6, Z8, Z8                                    // Z8 <- 4 - length
+  VPSLLD $3, Z8, Z9                                    // Z9 <- (4 - length) * 8
+  VPSRLVD Z9, Z7, Z7
+  VPBROADCASTD CONSTD_0xF0(), Z12
+  VPSLLVD Z8, Z12, Z12                                 // Z12 <- leading byte prefix
+  VPBROADCASTD CONSTD_0x80808000(), Z13
+  VPSRLVD Z9, Z13, Z13                                 // Z13 <- continuation byte prefixes
+  VPTERNLOGD.BCST $0xD8, CONSTD_0xFF(), Z12, Z13
+  VPORD Z13, Z7, Z7
+  KNOTW K2, K3
+  VMOVDQA32 Z4, K3, Z7                                 // Z7 <- ASCII characters are stored as is
+
+  BC_CHECK_SCRATCH_CAPACITY($(4 * 16), R8, abort)
+  BC_GET_SCRATCH_BASE_GP(R8)
+  ADDQ $(4 * 16), bytecode_scratch+8(VIRT_BCPTR)
+
+  VPBROADCASTD.Z R8, K1, Z2
+  ADDQ VIRT_BASE, R8
+  VPADDD.Z CONST_GET_PTR(consts_offsets_d_4, 0), Z2, K1, Z2
+  VMOVDQA32.Z Z6, K1, Z3
+  VMOVDQU32 Z7, 0(R8)
+
+  BC_UNPACK_2xSLOT(0, OUT(DX), OUT(R8))
+  BC_STORE_SLICE_TO_SLOT(IN(Z2), IN(Z3), IN(DX))
+  BC_STORE_K_TO_SLOT(IN(K1), IN(R8))
+  NEXT_ADVANCE(BC_SLOT_SIZE*4)
+
+abort:
+  MOVL $const_bcerrMoreScratch, bytecode_err(VIRT_BCPTR)
+  RET_ABORT()
+
 //; #region bcContainsPrefixCs
 //
 // s[0].k[0] = contains_prefix_cs(slice[2], dict[3]).k[4]
@@ -17,6 +17,7 @@ package vm
 import (
 	"encoding/binary"
 	"fmt"
+	"math"
 	"math/rand"
 	"net"
 	"regexp"
@@ -81,6 +82,8 @@ func prettyName(op bcop) string {
 		return "trim white-space from left (opTrimWsLeft)"
 	case opTrimWsRight:
 		return "trim white-space from right (opTrimWsRight)"
+	case opTrimWsBoth:
+		return "trim white-space from both sides (opTrimWsBoth)"
 
 	case opContainsPrefixCs:
 		return "contains prefix case-sensitive (opContainsPrefixCs)"
@@ -3217,7 +3220,7 @@ func runTrimWhiteSpace(t *testing.T, op bcop, inputK kRegData, data16 [16]Data,
 	return true
 }
 
-// TestTrimWhiteSpaceUT1 unit-tests for: opTrimWsLeft, opTrimWsRight
+// TestTrimWhiteSpaceUT1 unit-tests for: opTrimWsLeft, opTrimWsRight, opTrimWsBoth
 func TestTrimWhiteSpaceUT1(t *testing.T) {
 	t.Parallel()
 	type unitTest struct {
@@ -3253,6 +3256,17 @@ func TestTrimWhiteSpaceUT1(t *testing.T) {
 				{"€ ", "€"},
 			},
 		},
+		{
+			op: opTrimWsBoth,
+			unitTests: []unitTest{
+				{"a", "a"},
+				{" a ", "a"},
+				{" a a ", "a a"},
+				{"\t\r\na \v b\f ", "a \v b"},
+				{"     ", ""},
+				{" € ", "€"},
+			},
+		},
 	}
 
 	for _, ts := range testSuites {
@@ -3264,7 +3278,7 @@ func TestTrimWhiteSpaceUT1(t *testing.T) {
 	}
 }
 
-// TestTrimWhiteSpaceBF brute-force for: opTrimWsLeft, opTrimWsRight
+// TestTrimWhiteSpaceBF brute-force for: opTrimWsLeft, opTrimWsRight, opTrimWsBoth
 func TestTrimWhiteSpaceBF(t *testing.T) {
 	t.Parallel()
 	type testSuite struct {
@@ -3290,6 +3304,12 @@ func TestTrimWhiteSpaceBF(t *testing.T) {
 			dataLenSpace: []int{1, 2, 3, 4, 5},
 			dataMaxSize:  exhaustive,
 		},
+		{
+			op:           opTrimWsBoth,
+			dataAlphabet: []rune{'a', '¢', '\t', '\n', '\v', '\f', '\r', ' '},
+			dataLenSpace: []int{1, 2, 3, 4, 5},
+			dataMaxSize:  exhaustive,
+		},
 	}
 
 	dummyResults := make16("")
@@ -3309,13 +3329,14 @@ func TestTrimWhiteSpaceBF(t *testing.T) {
 	}
 }
 
-// FuzzTrimWhiteSpaceFT fuzz tests for: opTrimWsLeft, opTrimWsRight
+// FuzzTrimWhiteSpaceFT fuzz tests for: opTrimWsLeft, opTrimWsRight, opTrimWsBoth
 func FuzzTrimWhiteSpaceFT(f *testing.F) {
 	f.Add(uint16(0xFFFF), "a", "¢", "€", " 𐍈", "ab", "a¢ ", "a€", "a𐍈", "abb", " ab¢", "ab€", "ab𐍈\t", "\v$¢€𐍈", "\nab¢", "\fab¢", "\rab¢ ")
 
 	testSuites := []bcop{
 		opTrimWsLeft,
 		opTrimWsRight,
+		opTrimWsBoth,
 	}
 
 	dummyResults := make16("")
@@ -3325,9 +3346,58 @@ func FuzzTrimWhiteSpaceFT(f *testing.F) {
 		for _, op := range testSuites {
 			runTrimWhiteSpace(t, op, kRegData{mask: lanes}, data16, false, dummyResults)
 		}
+		runTrimWhiteSpaceBoth(t, kRegData{mask: lanes}, data16)
 	})
 }
 
+// TestTrimWhiteSpaceBoth checks that opTrimWsBoth
+// is equivalent to opTrimWsLeft followed by opTrimWsRight
+func TestTrimWhiteSpaceBoth(t *testing.T) {
+	t.Parallel()
+	alphabet := []rune{'a', '¢', '\t', '\n', ' '}
+	for _, data16 := range createSpace([]int{1, 2, 3, 4, 5, 6}, alphabet, exhaustive) {
+		if !runTrimWhiteSpaceBoth(t, fullMask, data16) {
+			return
+		}
+	}
+	data16 := [16]Data{
+		"", " ", "     ", "a", " a", "a ", " a ", "  a  b  ",
+		"\t\n\v\f\ra b\r\f\v\n\t", "        interior  space        ", " € ", "𐍈 𐍈",
+		"a                    ", "                    a", "x", "\r\n",
+	}
+	runTrimWhiteSpaceBoth(t, fullMask, data16)
+	runTrimWhiteSpaceBoth(t, kRegData{mask: 0x5a5a}, data16)
+}
+
+func runTrimWhiteSpaceBoth(t *testing.T, inputK kRegData, data16 [16]Data) bool {
+	if !validData(data16) {
+		return true
+	}
+
+	var ctx bctestContext
+	defer ctx.free()
+
+	inputS := ctx.sRegFromStrings(data16[:])
+	var leftS, expS, obsS sRegData
+	if err := ctx.executeOpcode(opTrimWsLeft, []any{&leftS, &inputS, &inputK}, inputK); err != nil {
+		t.Error(err)
+		return false
+	}
+	if err := ctx.executeOpcode(opTrimWsRight, []any{&expS, &leftS, &inputK}, inputK); err != nil {
+		t.Error(err)
+		return false
+	}
+	if err := ctx.executeOpcode(opTrimWsBoth, []any{&obsS, &inputS, &inputK}, inputK); err != nil {
+		t.Error(err)
+		return false
+	}
+	if err := reportIssueS(&inputK, &obsS, &expS); err != nil {
+		t.Errorf("%v\ndata=%v\n%v", prettyName(opTrimWsBoth), prettyPrint(data16), err)
+		return false
+	}
+	return true
+}
+
 func runContainsPreSufSub(t *testing.T, op bcop, inputK kRegData, data16 [16]Data, needle Needle, encNeedle string, hasMan bool, manK kRegData, manS sRegData) bool {
 	if !validData(data16) || !validNeedle(needle) {
 		return true
@@ -4640,6 +4710,113 @@ func TestBytecodeToInt(t *testing.T) {
 	verifyI64RegOutput(t, &outputS, &i64RegData{values: [16]int64{0, 255, 0x1133, -42, 12345678}})
 }
 
+func TestBytecodeCodePoint(t *testing.T) {
+	t.Parallel()
+	var ctx bctestContext
+	defer ctx.free()
+
+	data := []string{
+		"a", "\x7f", "ą", "€",
+		"😀", "日本", "\xc0\x80", "a\x80",
+		"", "\x80", "\xc4", "\xe2\x82",
+		"\xe2\x28\xa1", "\xf0\x9f\x98", "\xf8\x80\x80\x80", "\xff",
+	}
+	// the first 8 lanes have a code point, the rest are either empty or malformed
+	expected := i64RegData{values: [16]int64{'a', 0x7f, 'ą', '€', '😀', '日', 0, 'a'}}
+	expectedK := kRegData{mask: 0x00ff}
+
+	inputS := ctx.sRegFromStrings(data)
+	inputK := kRegData{mask: 0xffff}
+	for i := range data {
+		r, ok := stringext.DecodeCodePoint([]byte(data[i]))
+		if ok != expectedK.getBit(i) || int64(r) != expected.values[i] {
+			t.Errorf("DecodeCodePoint(%q) = %d, %v", data[i], r, ok)
+		}
+	}
+	if !isSupported(opcodepoint) {
+		return
+	}
+
+	outputS := i64RegData{}
+	outputK := kRegData{}
+	if err := ctx.executeOpcode(opcodepoint, []any{&outputS, &outputK, &inputS, &inputK}, inputK); err != nil {
+		t.Fatal(err)
+	}
+
+	verifyKRegOutput(t, &outputK, &expectedK)
+	verifyI64RegOutput(t, &outputS, &expected)
+}
+
+func TestBytecodeRandom(t *testing.T) {
+	t.Parallel()
+	var ctx bctestContext
+	defer ctx.free()
+
+	data := []string{
+		"", "a", "ab", "abc",
+		"abcd", "abcde", "abcdefgh", "abcdefghi",
+		"a longer row spanning several words", "a", "\x00", "\x00\x00\x00\x00",
+		"ą€😀", "\xff\xff\xff\xff\xff", "0123456789abcdef", "0123456789abcdeg",
+	}
+	const seed = 42
+
+	inputS := ctx.sRegFromStrings(data)
+	inputB := bRegData(inputS)
+	inputK := kRegData{mask: 0x7fff}
+	expected := f64RegData{}
+	for i := range data {
+		if inputK.getBit(i) {
+			expected.values[i] = randomRow(seed, uint64(i), []byte(data[i]))
+		}
+	}
+
+	outputS := f64RegData{}
+	if err := ctx.executeOpcode(oprandom, []any{&outputS, &inputB, int64(seed), &inputK}, inputK); err != nil {
+		t.Fatal(err)
+	}
+	verifyF64RegOutput(t, &outputS, &expected)
+	// "a" appears in lanes 1 and 9
+	if outputS.values[1] == outputS.values[9] {
+		t.Error("identical rows produced the same value")
+	}
+}
+
+func TestRandomRow(t *testing.T) {
+	const n = 100000
+	const buckets = 10
+
+	// every row is the same, so the values
+	// only depend on the position of each row
+	row := []byte("row")
+	check := func(seed uint64) (sum float64) {
+		var count [buckets]int
+		for i := 0; i < n; i++ {
+			pos := uint64(i)
+			f := randomRow(seed, pos, row)
+			if f < 0 || f >= 1 {
+				t.Fatalf("randomRow(%d, %d) = %g is not in [0, 1)", seed, pos, f)
+			}
+			if f != randomRow(seed, pos, row) {
+				t.Fatalf("randomRow(%d, %d) is not reproducible", seed, pos)
+			}
+			count[int(f*buckets)]++
+			sum += f
+		}
+		for i := range count {
+			if count[i] < n/buckets*9/10 || count[i] > n/buckets*11/10 {
+				t.Errorf("seed %d: bucket %d has %d values", seed, i, count[i])
+			}
+		}
+		if mean := sum / n; math.Abs(mean-0.5) > 0.01 {
+			t.Errorf("seed %d: mean is %g", seed, mean)
+		}
+		return sum
+	}
+	if check(0) == check(1) {
+		t.Error("different seeds produced the same values")
+	}
+}
+
 func TestBytecodeIsNull(t *testing.T) {
 	t.Parallel()
 	var ctx bctestContext
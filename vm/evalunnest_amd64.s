@@ -59,6 +59,7 @@ vmenter:
   TESTL   R15, R15              // no lanes are arrays?
   JZ      loop_tail             // then we are trivially done
 splat_lane:
+  MOVQ    R10, R11              // R11 = # delims output before this lane
   VMOVD   X2, R12               // R12 = base pointer
   VMOVD   X3, R13               // R13 = length
   VALIGND $1, Z2, Z2, Z2        // shift away dword in z2 and z3
@@ -127,8 +128,10 @@ genmask:
   KMOVW   R8, K1
   JMP     vmenter
 out_of_space:
+  // the current lane is only partially splatted,
+  // so don't report any of its outputs
   MOVQ    AX, ret+80(FP) // AX = # lanes processed
-  MOVQ    R10, ret1+88(FP)
+  MOVQ    R11, ret1+88(FP)
   RET
 bytecode_error:
   RET
@@ -268,6 +268,18 @@ func compile(p *prog, e expr.Node) (*value, error) {
 			return nil, err
 		}
 		return p.index(inner, n.Offset), nil
+	case *expr.Slice:
+		inner, err := compile(p, n.Inner)
+		if err != nil {
+			return nil, err
+		}
+		return p.slice(inner, n.Start, n.End), nil
+	case *expr.Transform:
+		list, err := compile(p, n.List)
+		if err != nil {
+			return nil, err
+		}
+		return p.transform(list, n.Param, n.Body)
 	case *expr.IsKey:
 		inner, err := compile(p, n.Expr)
 		if err != nil {
@@ -552,6 +564,19 @@ func compilefuncaux(p *prog, b *expr.Builtin, args []expr.Node) (*value, error)
 
 		return p.constant(pi), nil
 
+	case expr.Random:
+		// the seed of RANDOM() without one is chosen
+		// when the query is executed, so that every
+		// program compiled from the query uses the same seed
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected a seed")
+		}
+		seed, ok := args[0].(expr.Integer)
+		if !ok {
+			return nil, fmt.Errorf("seed must be an integer literal")
+		}
+		return p.random(int64(seed)), nil
+
 	case expr.Log:
 		count := len(args)
 
@@ -664,6 +689,13 @@ func compilefuncaux(p *prog, b *expr.Builtin, args []expr.Node) (*value, error)
 		}
 		return val, nil
 
+	case expr.IsNaN:
+		v, err := compileargs(p, args, compileNumber)
+		if err != nil {
+			return nil, err
+		}
+		return p.isnan(v[0]), nil
+
 	case expr.Hypot, expr.Pow, expr.Atan2:
 		v, err := compileargs(p, args, compileNumber, compileNumber)
 		if err != nil {
@@ -868,6 +900,20 @@ func compilefuncaux(p *prog, b *expr.Builtin, args []expr.Node) (*value, error)
 		}
 		return p.charLength(v[0]), nil
 
+	case expr.CodePoint:
+		v, err := compileargs(p, args, compileString)
+		if err != nil {
+			return nil, err
+		}
+		return p.codePoint(v[0]), nil
+
+	case expr.Chr:
+		v, err := compileargs(p, args, compileNumber)
+		if err != nil {
+			return nil, err
+		}
+		return p.chr(v[0]), nil
+
 	case expr.Substring:
 		val, err := compileargs(p, args, compileString, compileNumber, compileNumber)
 		if err != nil {
@@ -891,6 +937,16 @@ func compilefuncaux(p *prog, b *expr.Builtin, args []expr.Node) (*value, error)
 
 		return p.splitPart(lhs, delimiterStr[0], splitPartIndex), nil
 
+	case expr.Translate:
+		v, err := compileargs(p, args, compileString, literalString, literalString)
+		if err != nil {
+			return nil, err
+		}
+
+		from := args[1].(expr.String)
+		to := args[2].(expr.String)
+		return p.translate(v[0], string(from), string(to)), nil
+
 	case expr.Unspecified:
 		return nil, fmt.Errorf("unhandled builtin %q", b.Name())
 
@@ -910,6 +966,22 @@ func compilefuncaux(p *prog, b *expr.Builtin, args []expr.Node) (*value, error)
 
 		return p.dateToUnixMicro(v[0]), nil
 
+	case expr.ParseTimestamp:
+		v, err := compileargs(p, args, compileString, literalString)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.parseTimestamp(v[0], string(args[1].(expr.String))), nil
+
+	case expr.FormatTimestamp:
+		v, err := compileargs(p, args, compileTime, literalString)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.formatTimestamp(v[0], string(args[1].(expr.String))), nil
+
 	case expr.GeoHash, expr.GeoTileES:
 		v, err := compileargs(p, args, compileNumber, compileNumber, compileNumber)
 		if err != nil {
@@ -1015,6 +1087,17 @@ func compilefuncaux(p *prog, b *expr.Builtin, args []expr.Node) (*value, error)
 		}
 		return p.vectorCosineDistance(v[0], v[1]), nil
 
+	case expr.JSONExtract:
+		v, err := compileargs(p, args, compileString, literalString)
+		if err != nil {
+			return nil, err
+		}
+		path := string(args[1].(expr.String))
+		if _, err := expr.SplitJSONPath(path); err != nil {
+			return nil, err
+		}
+		return p.jsonExtract(v[0], path), nil
+
 	case expr.Lower, expr.Upper:
 		vals, err := compileargs(p, args, compileString)
 		if err != nil {
@@ -1105,6 +1188,17 @@ func compilefuncaux(p *prog, b *expr.Builtin, args []expr.Node) (*value, error)
 		}
 		return v, nil
 
+	case expr.HashBucket:
+		v, err := p.serialized(args[0])
+		if err != nil {
+			return nil, err
+		}
+		n, ok := args[1].(expr.Integer)
+		if !ok || n <= 0 || n&(n-1) != 0 {
+			return nil, fmt.Errorf("%s: bad bucket count %s", fn, expr.ToString(args[1]))
+		}
+		return p.hashbucket(p.hash(v), int(n)), nil
+
 	case expr.AssertIonType:
 		arg, err := compile(p, args[0])
 		if err != nil {
@@ -1837,10 +1931,13 @@ func (p *prog) compileCast(c *expr.Cast) (*value, error) {
 			return from, nil
 		case stInt:
 			return p.ssa2(scvti64tostr, from, p.mask(from)), nil
+		case stBool:
+			return p.boolToString(from, nil), nil
 		case stValue:
 			// we can encode strings as symbols,
 			// so include symbols in the bits we check
-			return p.checkTag(from, c.To|expr.SymbolType), nil
+			str := p.checkTag(from, c.To|expr.SymbolType)
+			return p.boolToString(from, str), nil
 		default:
 			return p.missing(), nil
 		}
@@ -1871,6 +1968,22 @@ func (p *prog) compileCast(c *expr.Cast) (*value, error) {
 	}
 }
 
+// boolToString produces the strings "true" and "false"
+// in the lanes where from is TRUE or FALSE, respectively;
+// if other is non-nil, it is merged into the lanes where
+// from is not a boolean
+func (p *prog) boolToString(from, other *value) *value {
+	t := p.isTrue(from)
+	f := p.isFalse(from)
+	out := p.ssa4(sblendv, p.constant("true"), t, p.constant("false"), f)
+	if other != nil {
+		// the blended result cannot be unsymbolized later
+		other = p.unsymbolized(other)
+		out = p.ssa4(sblendv, other, p.mask(other), out, out)
+	}
+	return out
+}
+
 func (p *prog) checkTag(from *value, typ expr.TypeSet) *value {
 	primary := from.primary()
 	switch primary {
@@ -27,6 +27,12 @@ import (
 type Filter struct {
 	prog *prog
 	rest QuerySink // rest of sub-query
+
+	// if non-nil, prog is owned by cache
+	// and is released on Close
+	cache  *ProgramCache
+	key    string
+	closed bool
 }
 
 // NewFilter constructs a Filter from a boolean expression.
@@ -40,6 +46,18 @@ func NewFilter(e expr.Node, rest QuerySink) (*Filter, error) {
 	return where(prog, rest), nil
 }
 
+// DeleteRows writes the rows of the ion stream
+// in src for which pred is not TRUE to dst,
+// preserving their order. DeleteRows can be used
+// as a blockfmt.DeleteFunc.
+func DeleteRows(dst io.Writer, src io.Reader, pred expr.Node) error {
+	f, err := NewFilter(expr.Is(pred, expr.IsNotTrue), LockedSink(dst))
+	if err != nil {
+		return err
+	}
+	return NewIonTable(src).WriteChunks(f, 1)
+}
+
 func where(p *prog, rest QuerySink) *Filter {
 	return &Filter{prog: p, rest: rest}
 }
@@ -56,9 +74,18 @@ func (r *Filter) Open() (io.WriteCloser, error) {
 	return splitter(&wherebc{parent: r, dst: asRowConsumer(q)}), nil
 }
 
-// Close implements io.Closer
+// Close implements io.Closer.
+// Calling Close more than once has no effect.
 func (r *Filter) Close() error {
-	r.prog.reset()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.cache != nil {
+		r.cache.release(r.key)
+	} else {
+		r.prog.reset()
+	}
 	return r.rest.Close()
 }
 
@@ -175,12 +202,13 @@ func (w *wherebc) writeRows(delims []vmref, rp *rowParams) error {
 		return nil
 
 	case constTrue:
+		w.params.pos = rp.pos
 		return w.dst.writeRows(delims, &w.params)
 	}
 
 	w.bc.prepare(rp)
 	var valid int
-	if globalOptimizationLevel >= OptimizationLevelAVX512V1 {
+	if w.bc.useAsm() {
 		valid = evalfilterbc(&w.bc, delims)
 	} else {
 		valid = evalfiltergo(&w.bc, delims)
@@ -194,6 +222,7 @@ func (w *wherebc) writeRows(delims []vmref, rp *rowParams) error {
 		for i := range w.params.auxbound {
 			w.params.auxbound[i] = sanitizeAux(rp.auxbound[i], valid) // ensure rp.auxbound[i][valid:] is zeroed up to the lane multiple
 		}
+		w.params.pos = rp.pos
 		return w.dst.writeRows(delims[:valid], &w.params)
 	}
 	return nil
@@ -37,6 +37,10 @@ type HashAggregate struct {
 	by        Selection
 	dst       QuerySink
 	skipEmpty bool
+	// boolkey is set when the only grouping
+	// column is a boolean, in which case buckets
+	// are selected directly rather than hashed
+	boolkey bool
 
 	aggregateOps []AggregateOp
 	initialData  []byte
@@ -184,6 +188,13 @@ func (h *HashAggregate) SetSkipEmpty(skip bool) {
 }
 
 func NewHashAggregate(agg, windows Aggregation, by Selection, dst QuerySink) (*HashAggregate, error) {
+	return newHashAggregate(agg, windows, by, dst, true)
+}
+
+// newHashAggregate implements NewHashAggregate;
+// if direct is false, the grouping columns are
+// always hashed, even if they have a small domain
+func newHashAggregate(agg, windows Aggregation, by Selection, dst QuerySink, direct bool) (*HashAggregate, error) {
 	if len(by) == 0 {
 		return nil, fmt.Errorf("cannot aggregate an empty selection")
 	}
@@ -203,6 +214,7 @@ func NewHashAggregate(agg, windows Aggregation, by Selection, dst QuerySink) (*H
 
 	var allColumnsMask *value
 	var allColumnsHash *value
+	var boolkey *value
 
 	for i, column := range by {
 		field := column.Expr
@@ -213,6 +225,12 @@ func NewHashAggregate(agg, windows Aggregation, by Selection, dst QuerySink) (*H
 		}
 		// we always want to hash the *unsymbolized* value
 		col = prog.unsymbolized(col)
+		if direct && len(by) == 1 && col.op == sboxmask {
+			// a boxed mask can only be TRUE or FALSE,
+			// so there are at most two groups and we
+			// can index them directly
+			boolkey = col.args[0]
+		}
 
 		if allColumnsHash == nil {
 			allColumnsHash = prog.hash(col)
@@ -237,7 +255,13 @@ func NewHashAggregate(agg, windows Aggregation, by Selection, dst QuerySink) (*H
 	mem := prog.mergeMem(colmem...)
 	out := make([]*value, len(h.agg))
 	ops := make([]AggregateOp, len(h.agg))
-	bucket := prog.aggbucket(mem, allColumnsHash, allColumnsMask)
+	var bucket *value
+	if boolkey != nil {
+		h.boolkey = true
+		bucket = prog.aggbucketbool(mem, boolkey, allColumnsMask)
+	} else {
+		bucket = prog.aggbucket(mem, allColumnsHash, allColumnsMask)
+	}
 	offset := aggregateslot(0)
 
 	for i := range h.agg {
@@ -274,6 +298,14 @@ func NewHashAggregate(agg, windows Aggregation, by Selection, dst QuerySink) (*H
 			out[i] = prog.aggregateSlotCount(mem, bucket, mask, offset)
 			ops[i].fn = AggregateOpCount
 
+		case expr.OpCountIf:
+			k, err := prog.compileAsBool(a.Inner)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = prog.aggregateSlotCount(mem, bucket, prog.and(k, mask), offset)
+			ops[i].fn = AggregateOpCount
+
 		case expr.OpApproxCountDistinct:
 			argv, err := compile(prog, a.Inner)
 			if err != nil {
@@ -291,6 +323,21 @@ func NewHashAggregate(agg, windows Aggregation, by Selection, dst QuerySink) (*H
 				out[i] = prog.aggregateSlotMergeState(bucket, argv, mask, offset+aggregateslot(ops[i].dataSize()))
 			}
 
+		case expr.OpMode:
+			argv, err := prog.serialized(a.Inner)
+			if err != nil {
+				return nil, fmt.Errorf("cannot compile %q: %w", a.Inner, err)
+			}
+
+			ops[i].fn = AggregateOpMode
+			ops[i].role = a.Role
+			switch a.Role {
+			case expr.AggregateRoleFinal, expr.AggregateRolePartial:
+				out[i] = prog.aggregateSlotMode(mem, bucket, prog.unsymbolized(argv), mask, offset)
+			case expr.AggregateRoleMerge:
+				out[i] = prog.aggregateSlotMergeState(bucket, argv, mask, offset+aggregateslot(ops[i].dataSize()))
+			}
+
 		case expr.OpBoolAnd, expr.OpBoolOr:
 			argv, err := compile(prog, h.agg[i].Expr.Inner)
 			if err != nil {
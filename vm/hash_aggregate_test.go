@@ -22,6 +22,7 @@ import (
 	"os"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 
@@ -183,6 +184,67 @@ func TestHashAggregate(t *testing.T) {
 	}
 }
 
+func TestHashAggregateBoolKey(t *testing.T) {
+	buf, err := os.ReadFile("../testdata/nyc-taxi.block")
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg := Aggregation{
+		{Expr: expr.Count(expr.Star{}), Result: "count"},
+		mkagg(expr.OpSum, "passenger_count", "total"),
+		mkagg(expr.OpMax, "trip_distance", "longest"),
+	}
+	group := Selection{expr.Bind(expr.Compare(expr.Greater, path(nil, "passenger_count"), expr.Integer(1)), "many")}
+
+	run := func(direct bool) []byte {
+		var qb QueryBuffer
+		ha, err := newHashAggregate(agg, nil, group, &qb, direct)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ha.boolkey != direct {
+			t.Fatalf("direct=%v: boolkey=%v", direct, ha.boolkey)
+		}
+		ha.OrderByGroup(0, defaultSortOrdering)
+		intable := &looptable{chunk: buf, count: 8}
+		err = CopyRows(ha, intable, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ha.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return qb.Bytes()
+	}
+	rows := func(buf []byte) []string {
+		var st ion.Symtab
+		var out []string
+		for len(buf) > 0 {
+			if ion.TypeOf(buf) == ion.NullType && ion.SizeOf(buf) > 1 {
+				buf = buf[ion.SizeOf(buf):] // nop pad
+				continue
+			}
+			var d ion.Datum
+			d, buf, err = ion.ReadDatum(&st, buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, toJSON(&st, d))
+		}
+		return out
+	}
+	want := rows(run(false))
+	got := rows(run(true))
+	if len(want) != 2 {
+		t.Fatalf("expected two groups; got %q", want)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got  %q", got)
+		t.Errorf("want %q", want)
+	}
+}
+
 type nopSink struct{}
 
 func (n nopSink) Open() (io.WriteCloser, error) {
@@ -0,0 +1,112 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// runHashBucket projects HASH_BUCKET(x, n) AS b
+// over rows {x: i%keys}, where every third key is
+// a string, and returns the buckets in row order
+func runHashBucket(t *testing.T, rows, keys, n int) []int64 {
+	var st ion.Symtab
+	var buf ion.Buffer
+	x := st.Intern("x")
+	st.Marshal(&buf, true)
+	for i := 0; i < rows; i++ {
+		buf.BeginStruct(-1)
+		buf.BeginField(x)
+		if k := i % keys; k%3 == 0 {
+			buf.WriteString(fmt.Sprintf("key%d", k))
+		} else {
+			buf.WriteInt(int64(k))
+		}
+		buf.EndStruct()
+	}
+	var qb QueryBuffer
+	bucket := expr.Call(expr.HashBucket, path(t, "x"), expr.Integer(n))
+	sink, err := NewProjection(Selection{expr.Bind(bucket, "b")}, &qb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CopyRows(sink, buftbl(buf.Bytes()), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sink.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []int64
+	var dst ion.Symtab
+	body := qb.Bytes()
+	for len(body) > 0 {
+		var d ion.Datum
+		d, body, err = ion.ReadDatum(&dst, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.IsEmpty() || d.Type() != ion.StructType {
+			continue
+		}
+		s, _ := d.Struct()
+		f, ok := s.FieldByName("b")
+		if !ok {
+			t.Fatalf("row %d: no bucket in %s", len(out), toJSON(&dst, d))
+		}
+		b, err := f.Int()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+func TestHashBucket(t *testing.T) {
+	const rows, keys, n = 1000, 37, 8
+	check := func(t *testing.T) []int64 {
+		out := runHashBucket(t, rows, keys, n)
+		if len(out) != rows {
+			t.Fatalf("got %d rows, wanted %d", len(out), rows)
+		}
+		seen := make(map[int64]bool)
+		for i := range out {
+			if out[i] < 0 || out[i] >= n {
+				t.Fatalf("row %d: bucket %d out of range", i, out[i])
+			}
+			if out[i] != out[i%keys] {
+				t.Fatalf("row %d: bucket %d for the key in bucket %d", i, out[i], out[i%keys])
+			}
+			seen[out[i]] = true
+		}
+		if len(seen) < 2 {
+			t.Fatalf("all keys hashed to %d bucket(s)", len(seen))
+		}
+		return out
+	}
+	asm := check(t)
+	defer SetOptimizationLevel(DetectOptimizationLevel())
+	SetOptimizationLevel(OptimizationLevelNone)
+	if portable := check(t); !slices.Equal(asm, portable) {
+		t.Fatal("portable buckets differ from assembly buckets")
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// runHashLookup projects CHAR_LENGTH(l) AS n over
+// rows {x: i%keys}, where l looks up x in a table
+// mapping each key k to a string of lens[k] characters,
+// and returns the lengths in row order
+func runHashLookup(t *testing.T, rows int, lens []int) []int64 {
+	var st ion.Symtab
+	var buf ion.Buffer
+	x := st.Intern("x")
+	st.Marshal(&buf, true)
+	for i := 0; i < rows; i++ {
+		buf.BeginStruct(-1)
+		buf.BeginField(x)
+		buf.WriteInt(int64(i % len(lens)))
+		buf.EndStruct()
+	}
+	lookup := &expr.Lookup{Expr: path(t, "x")}
+	for k, n := range lens {
+		lookup.Keys.AddDatum(ion.Int(int64(k)))
+		lookup.Values.AddDatum(ion.String(strings.Repeat("x", n)))
+	}
+	var qb QueryBuffer
+	sink, err := NewProjection(Selection{expr.Bind(expr.Call(expr.CharLength, lookup), "n")}, &qb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CopyRows(sink, buftbl(buf.Bytes()), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sink.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []int64
+	var dst ion.Symtab
+	body := qb.Bytes()
+	for len(body) > 0 {
+		var d ion.Datum
+		d, body, err = ion.ReadDatum(&dst, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.IsEmpty() || d.Type() != ion.StructType {
+			continue
+		}
+		s, _ := d.Struct()
+		f, ok := s.FieldByName("n")
+		if !ok {
+			t.Fatalf("row %d: no length in %s", len(out), toJSON(&dst, d))
+		}
+		n, err := f.Int()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func TestHashLookupHeaderSize(t *testing.T) {
+	// strings around the sizes at which the
+	// header of the encoded value grows, including
+	// those for which the header size computed from
+	// the size of the whole value would be too large
+	lens := []int{0, 1, 12, 13, 14, 125, 126, 127, 128, 16381, 16382, 16383}
+	const rows = 100
+	check := func(t *testing.T) {
+		out := runHashLookup(t, rows, lens)
+		if len(out) != rows {
+			t.Fatalf("got %d rows, wanted %d", len(out), rows)
+		}
+		for i := range out {
+			if want := lens[i%len(lens)]; out[i] != int64(want) {
+				t.Errorf("row %d: got length %d, wanted %d", i, out[i], want)
+			}
+		}
+	}
+	t.Run("default", check)
+	defer SetOptimizationLevel(DetectOptimizationLevel())
+	SetOptimizationLevel(OptimizationLevelNone)
+	t.Run("portable", check)
+}
@@ -175,32 +175,43 @@ func encodeTLVUnsafe(dst []byte, offset int, valueType ion.Type, length uint) in
 func evalfiltergo(bc *bytecode, delims []vmref) int {
 	i, j := 0, 0
 	for i < len(delims) {
-		next := delims[i:]
-		next = next[:min(len(next), bcLaneCount)]
+		n, _ := portableBatch(len(delims) - i)
+		next := delims[i : i+n]
 		apos := bc.auxpos
 		mask := evalfiltergolanes(bc, next)
 		if bc.err != 0 {
 			return j
 		}
 		// compress delims + auxvals
-		for k := range next {
-			if mask == 0 {
-				break
-			}
-			if (mask & 1) != 0 {
-				for l := range bc.auxvals {
-					bc.auxvals[l][j] = bc.auxvals[l][apos+k]
-				}
-				delims[j] = next[k]
-				j++
-			}
-			mask >>= 1
+		_, active := portableBatch(n)
+		mask &= active
+		compressaux(bc.auxvals, j, apos, mask)
+		for m := mask; m != 0; m &= m - 1 {
+			delims[j] = next[bits.TrailingZeros16(m)]
+			j++
 		}
 		i += len(next)
 	}
 	return j
 }
 
+// compressaux moves the auxiliary values of the
+// lanes set in mask from auxvals[*][src:] to
+// auxvals[*][dst:], preserving their order
+//
+// this is done one column at a time rather than
+// one lane at a time so that each column is
+// traversed contiguously; dst must be <= src
+func compressaux(auxvals [][]vmref, dst, src int, mask uint16) {
+	for _, col := range auxvals {
+		j := dst
+		for m := mask; m != 0; m &= m - 1 {
+			col[j] = col[src+bits.TrailingZeros16(m)]
+			j++
+		}
+	}
+}
+
 func bcauxvalgo(bc *bytecode, pc int) int {
 	dstv := argptr[vRegData](bc, pc)
 	dstk := argptr[kRegData](bc, pc+2)
@@ -432,6 +443,7 @@ func init() {
 
 	opinfo[oparraysize].portable = bcarraysizego
 	opinfo[oparrayposition].portable = bcarraypositiongo
+	opinfo[oparraycontains].portable = bcarraycontainsgo
 	opinfo[oparraysum].portable = bcarraysumgo
 	opinfo[opvectorinnerproduct].portable = bcvectorinnerproductgo
 	opinfo[opvectorinnerproductimm].portable = bcvectorinnerproductimmgo
@@ -442,6 +454,12 @@ func init() {
 	opinfo[opvectorcosinedistance].portable = bcvectorcosinedistancego
 	opinfo[opvectorcosinedistanceimm].portable = bcvectorcosinedistanceimmgo
 
+	opinfo[opjsonextract].portable = bcjsonextractgo
+
+	opinfo[oparrayindex].portable = bcarrayindexgo
+	opinfo[oparrayslice].portable = bcarrayslicego
+	opinfo[optransform].portable = bctransformgo
+
 	opinfo[oplitref].portable = bclitrefgo
 	opinfo[opisnullv].portable = bcisnullvgo
 	opinfo[opisnotnullv].portable = bcisnotnullvgo
@@ -470,6 +488,7 @@ func init() {
 
 	opinfo[opTrimWsLeft].portable = func(bc *bytecode, pc int) int { return bcTrimWsGo(bc, pc, opTrimWsLeft) }
 	opinfo[opTrimWsRight].portable = func(bc *bytecode, pc int) int { return bcTrimWsGo(bc, pc, opTrimWsRight) }
+	opinfo[opTrimWsBoth].portable = func(bc *bytecode, pc int) int { return bcTrimWsGo(bc, pc, opTrimWsBoth) }
 	opinfo[opTrim4charLeft].portable = func(bc *bytecode, pc int) int { return bcTrim4CharGo(bc, pc, opTrim4charLeft) }
 	opinfo[opTrim4charRight].portable = func(bc *bytecode, pc int) int { return bcTrim4CharGo(bc, pc, opTrim4charRight) }
 
@@ -477,6 +496,9 @@ func init() {
 	opinfo[opcharlength].portable = func(bc *bytecode, pc int) int { return bcLengthGo(bc, pc, opcharlength) }
 	opinfo[opSubstr].portable = bcSubstrGo
 	opinfo[opSplitPart].portable = bcSplitPartGo
+	opinfo[opTranslate].portable = bcTranslateGo
+	opinfo[opcodepoint].portable = bcCodePointGo
+	opinfo[opchr].portable = bcChrGo
 
 	opinfo[opContainsPrefixCs].portable = func(bc *bytecode, pc int) int { return bcContainsPreSufSubGo(bc, pc, opContainsPrefixCs) }
 	opinfo[opContainsPrefixCi].portable = func(bc *bytecode, pc int) int { return bcContainsPreSufSubGo(bc, pc, opContainsPrefixCi) }
@@ -546,6 +568,8 @@ func init() {
 	opinfo[opdatetruncmonth].portable = bcdatetruncmonthgo
 	opinfo[opdatetruncquarter].portable = bcdatetruncquartergo
 	opinfo[opdatetruncyear].portable = bcdatetruncyeargo
+	opinfo[opparsets].portable = bcparsetsgo
+	opinfo[opformatts].portable = bcformattsgo
 
 	opinfo[opaggminf].portable = bcaggminfgo
 	opinfo[opaggmaxf].portable = bcaggmaxfgo
@@ -565,6 +589,7 @@ func init() {
 	opinfo[ophashvalueplus].portable = bchashvalueplusgo
 	opinfo[ophashmember].portable = bchashmembergo
 	opinfo[ophashlookup].portable = bchashlookupgo
+	opinfo[ophashbucket].portable = bchashbucketgo
 
 	opinfo[opzerov].portable = bczerovgo
 	opinfo[opmovv].portable = bcmovvgo
@@ -576,6 +601,17 @@ func init() {
 	opinfo[opblendf64].portable = bcblendf64go
 }
 
+// portableBatch returns the number of rows
+// the portable interpreter evaluates in its next
+// batch when n rows remain, along with the mask
+// of the lanes that are active in that batch;
+// every portable evaluation loop should use
+// this rather than assuming a full batch
+func portableBatch(n int) (int, uint16) {
+	n = min(n, bcLaneCount)
+	return n, uint16(1<<n - 1)
+}
+
 func evalfindgo(bc *bytecode, delims []vmref, stride int) {
 	stack := bc.vstack
 	var alt bytecode
@@ -583,16 +619,11 @@ func evalfindgo(bc *bytecode, delims []vmref, stride int) {
 	// convert stride to 64-bit words:
 	stride = stride / int(unsafe.Sizeof(bc.vstack[0]))
 	for len(delims) > 0 {
-		mask := uint16(0xffff)
-		lanes := bcLaneCount
-		if len(delims) < lanes {
-			mask >>= bcLaneCount - len(delims)
-			lanes = len(delims)
-		}
+		lanes, mask := portableBatch(len(delims))
 		bc.err = 0
 		bc.vmState.validLanes.mask = mask
 		bc.vmState.outputLanes.mask = mask
-		setvmrefB(&bc.vmState.delims, delims)
+		setvmrefB(&bc.vmState.delims, delims[:lanes])
 		eval(bc, &alt, false)
 		if bc.err != 0 {
 			return
@@ -607,12 +638,8 @@ func evalsplatgo(bc *bytecode, indelims, outdelims []vmref, perm []int32) (int,
 	ipos, opos := 0, 0
 	var alt bytecode
 	for ipos < len(indelims) && opos < len(outdelims) {
-		next := indelims[ipos:]
-		mask := uint16(0xffff)
-		if len(next) < bcLaneCount {
-			mask >>= bcLaneCount - len(next)
-		}
-		setvmrefB(&bc.vmState.delims, indelims[ipos:])
+		lanes, mask := portableBatch(len(indelims) - ipos)
+		setvmrefB(&bc.vmState.delims, indelims[ipos:ipos+lanes])
 		bc.vmState.validLanes.mask = mask
 		bc.vmState.outputLanes.mask = 0
 		eval(bc, &alt, true)
@@ -621,7 +648,6 @@ func evalsplatgo(bc *bytecode, indelims, outdelims []vmref, perm []int32) (int,
 		}
 		retmask := bc.vmState.outputLanes.mask
 		output := opos
-		lanes := min(bcLaneCount, len(next))
 		for i := 0; i < lanes; i++ {
 			if (retmask & (1 << i)) == 0 {
 				continue
@@ -653,8 +679,7 @@ func evalfiltergolanes(bc *bytecode, delims []vmref) uint16 {
 	if len(delims) > bcLaneCount {
 		panic("invalid len(delims) for evalfiltergolanes")
 	}
-	mask := uint16(0xffff)
-	mask >>= bcLaneCount - len(delims)
+	_, mask := portableBatch(len(delims))
 	var alt bytecode
 	setvmrefB(&bc.vmState.delims, delims)
 	bc.vmState.validLanes.mask = mask
@@ -680,14 +705,8 @@ func evalprojectgo(bc *bytecode, delims []vmref, dst []byte, symbols []syminfo)
 
 	for rowsProcessed < len(delims) {
 		initialDstLength := offset
-		n := min(len(delims)-rowsProcessed, bcLaneCount)
-
-		for lane := 0; lane < n; lane++ {
-			bc.vmState.delims.offsets[lane] = uint32(delims[rowsProcessed+lane][0])
-			bc.vmState.delims.sizes[lane] = uint32(delims[rowsProcessed+lane][1])
-		}
-
-		mask := uint16((1 << n) - 1)
+		n, mask := portableBatch(len(delims) - rowsProcessed)
+		setvmrefB(&bc.vmState.delims, delims[rowsProcessed:rowsProcessed+n])
 		bc.err = 0
 		bc.vmState.validLanes.mask = mask
 
@@ -750,11 +769,7 @@ func evaldedupgo(bc *bytecode, delims []vmref, hashes []uint64, tree *radixTree6
 	indelims := delims
 	dout := 0
 	for len(indelims) > 0 {
-		n := min(len(indelims), bcLaneCount)
-		mask := uint16(0xffff)
-		if n < bcLaneCount {
-			mask >>= bcLaneCount - n
-		}
+		n, mask := portableBatch(len(indelims))
 		setvmrefB(&bc.vmState.delims, indelims[:n])
 		bc.vmState.validLanes.mask = mask
 		bc.vmState.outputLanes.mask = 0
@@ -765,18 +780,18 @@ func evaldedupgo(bc *bytecode, delims []vmref, hashes []uint64, tree *radixTree6
 		}
 		outhashes := slotcast[hRegData](bc, uint(slot))
 		outmask := bc.vmState.outputLanes.mask
+		keep := uint16(0)
+		start := dout
 		for i := 0; i < n; i++ {
 			if outmask&(1<<i) == 0 || tree.Offset(outhashes.lo[i]) >= 0 {
 				continue // lane not active or tree contains hash already
 			}
 			delims[dout] = indelims[i]
 			hashes[dout] = outhashes.lo[i]
-			// compress auxvals
-			for j, lst := range bc.auxvals {
-				bc.auxvals[j][dout] = lst[apos+i]
-			}
+			keep |= 1 << i
 			dout++
 		}
+		compressaux(bc.auxvals, start, apos, keep)
 		indelims = indelims[n:]
 	}
 	return dout
@@ -787,9 +802,8 @@ func evalaggregatego(bc *bytecode, delims []vmref, aggregateDataBuffer []byte) i
 	ret := 0
 	bc.vmState.aggPtr = unsafe.Pointer(&aggregateDataBuffer[0])
 	for len(delims) > 0 {
-		n := min(len(delims), bcLaneCount)
-		mask := uint16(0xffff) >> (bcLaneCount - n)
-		setvmrefB(&bc.vmState.delims, delims)
+		n, mask := portableBatch(len(delims))
+		setvmrefB(&bc.vmState.delims, delims[:n])
 		bc.vmState.validLanes.mask = mask
 		bc.vmState.outputLanes.mask = 0
 		eval(bc, &alt, true)
@@ -811,10 +825,8 @@ func evalhashagggo(bc *bytecode, delims []vmref, tree *radixTree64) int {
 	bc.vmState.aggPtr = unsafe.Pointer(tree)
 
 	for len(delims) > 0 {
-		n := min(len(delims), bcLaneCount)
-		mask := uint16(0xffff) >> (bcLaneCount - n)
-
-		setvmrefB(&bc.vmState.delims, delims)
+		n, mask := portableBatch(len(delims))
+		setvmrefB(&bc.vmState.delims, delims[:n])
 		bc.vmState.validLanes.mask = mask
 		bc.vmState.outputLanes.mask = 0
 
@@ -832,6 +844,17 @@ func evalhashagggo(bc *bytecode, delims []vmref, tree *radixTree64) int {
 //go:noescape
 func bcenter(bc *bytecode, k7 uint16)
 
+// runportable runs the portable implementation of
+// the op preceding pc on behalf of the assembly
+// interpreter (see callportable in evalbc_amd64.s)
+// and returns the position of the next op
+//
+//lint:ignore U1000 not unused; used in assembly
+func runportable(bc *bytecode, pc int, k7 uint16) int {
+	bc.vmState.validLanes.mask = k7
+	return opinfo[bcword(bc, pc-2)].portable(bc, pc)
+}
+
 // eval evaluates bc and uses alt as scratch space
 // for evaluating unimplemented opcodes via the assembly interpreter
 func eval(bc, alt *bytecode, resetScratch bool) {
@@ -16,6 +16,8 @@ package vm
 
 import (
 	"encoding/binary"
+	"math/bits"
+	"unicode/utf8"
 
 	"github.com/SnellerInc/sneller/internal/stringext"
 )
@@ -229,6 +231,156 @@ func bcSplitPartGo(bc *bytecode, pc int) int {
 	return pc + 12
 }
 
+// translateTable encodes the TRANSLATE(str, from, to) mapping
+// as the lookup table consumed by opTranslate:
+//
+//	uint32 number of entries
+//	uint32 worst case ratio of output to input length
+//	entries: uint32 key, uint32 replacement, uint32 replacement length
+//
+// Keys and replacements are UTF-8 sequences packed into
+// little-endian words; a zero replacement length deletes the key.
+func translateTable(from, to string) string {
+	fr := []rune(from)
+	tr := []rune(to)
+	ratio := uint32(1)
+	var entries []byte
+	seen := make(map[rune]struct{}, len(fr))
+	for i, c := range fr {
+		if _, ok := seen[c]; ok {
+			continue // the first occurrence wins
+		}
+		seen[c] = struct{}{}
+		var key, repl [4]byte
+		keylen := utf8.EncodeRune(key[:], c)
+		repllen := 0
+		if i < len(tr) {
+			repllen = utf8.EncodeRune(repl[:], tr[i])
+		}
+		ratio = max(ratio, uint32((repllen+keylen-1)/keylen))
+		entries = append(entries, key[:]...)
+		entries = append(entries, repl[:]...)
+		entries = binary.LittleEndian.AppendUint32(entries, uint32(repllen))
+	}
+	buf := make([]byte, 8, 8+len(entries))
+	binary.LittleEndian.PutUint32(buf, uint32(len(entries)/12))
+	binary.LittleEndian.PutUint32(buf[4:], ratio)
+	return string(append(buf, entries...))
+}
+
+// translateCharLength returns the number of bytes
+// of the character at the start of buf the same way
+// opTranslate does: invalid leading bytes occupy one byte
+func translateCharLength(buf []byte) int {
+	n := bits.LeadingZeros8(^buf[0])
+	if n < 2 || n > 4 {
+		n = 1
+	}
+	return min(n, len(buf))
+}
+
+func bcTranslateGo(bc *bytecode, pc int) int {
+	dstS := argptr[sRegData](bc, pc)
+	dstK := argptr[kRegData](bc, pc+2)
+	srcS := *argptr[sRegData](bc, pc+4)
+	table := bc.dict[bcword(bc, pc+6)]
+	srcK := argptr[kRegData](bc, pc+8).mask
+
+	count := int(binary.LittleEndian.Uint32([]byte(table)))
+	ratio := int(binary.LittleEndian.Uint32([]byte(table[4:])))
+	entries := []byte(table[8 : 8+count*12])
+
+	var out sRegData
+	for i := 0; i < bcLaneCount; i++ {
+		if srcK&(1<<i) == 0 {
+			continue
+		}
+		src := vmref{srcS.offsets[i], srcS.sizes[i]}.mem()
+		if cap(bc.scratch)-len(bc.scratch) < len(src)*ratio {
+			bc.err = bcerrMoreScratch
+			return pc + 10
+		}
+		start := len(bc.scratch)
+		for len(src) > 0 {
+			n := translateCharLength(src)
+			var key [4]byte
+			copy(key[:], src[:n])
+			src = src[n:]
+			repl, repllen := key[:], n
+			for j := 0; j < len(entries); j += 12 {
+				if [4]byte(entries[j:]) == key {
+					repl = entries[j+4 : j+8]
+					repllen = int(binary.LittleEndian.Uint32(entries[j+8:]))
+					break
+				}
+			}
+			bc.scratch = append(bc.scratch, repl[:repllen]...)
+		}
+		out.offsets[i] = bc.scratchoff + uint32(start)
+		out.sizes[i] = uint32(len(bc.scratch) - start)
+	}
+	*dstS = out
+	dstK.mask = srcK
+	return pc + 10
+}
+
+func bcCodePointGo(bc *bytecode, pc int) int {
+	dst := argptr[i64RegData](bc, pc)
+	dstK := argptr[kRegData](bc, pc+2)
+	srcS := argptr[sRegData](bc, pc+4)
+	srcK := argptr[kRegData](bc, pc+6).mask
+
+	var out i64RegData
+	retK := uint16(0)
+	for i := 0; i < bcLaneCount; i++ {
+		if srcK&(1<<i) == 0 {
+			continue
+		}
+		r, ok := stringext.DecodeCodePoint(vmref{srcS.offsets[i], srcS.sizes[i]}.mem())
+		if ok {
+			out.values[i] = int64(r)
+			retK |= 1 << i
+		}
+	}
+	*dst = out
+	dstK.mask = retK
+	return pc + 8
+}
+
+func bcChrGo(bc *bytecode, pc int) int {
+	dstS := argptr[sRegData](bc, pc)
+	dstK := argptr[kRegData](bc, pc+2)
+	src := argptr[i64RegData](bc, pc+4)
+	srcK := argptr[kRegData](bc, pc+6).mask
+
+	const size = 4 * bcLaneCount
+	if cap(bc.scratch)-len(bc.scratch) < size {
+		bc.err = bcerrMoreScratch
+		return pc + 8
+	}
+	base := len(bc.scratch)
+	bc.scratch = bc.scratch[:base+size]
+
+	var out sRegData
+	retK := uint16(0)
+	for i := 0; i < bcLaneCount; i++ {
+		if srcK&(1<<i) == 0 {
+			continue
+		}
+		v := src.values[i]
+		if v < 0 || v > utf8.MaxRune || !utf8.ValidRune(rune(v)) {
+			continue
+		}
+		n := utf8.EncodeRune(bc.scratch[base+4*i:], rune(v))
+		out.offsets[i] = bc.scratchoff + uint32(base+4*i)
+		out.sizes[i] = uint32(n)
+		retK |= 1 << i
+	}
+	*dstS = out
+	dstK.mask = retK
+	return pc + 8
+}
+
 func bcContainsPreSufSubGo(bc *bytecode, pc int, op bcop) int {
 	dstS := argptr[sRegData](bc, pc)
 	dstK := argptr[kRegData](bc, pc+2)
@@ -0,0 +1,228 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"math/bits"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/SnellerInc/sneller/expr"
+)
+
+func TestPortableBatch(t *testing.T) {
+	tcs := []struct {
+		n, lanes int
+		mask     uint16
+	}{
+		{0, 0, 0},
+		{1, 1, 0x0001},
+		{5, 5, 0x001f},
+		{15, 15, 0x7fff},
+		{16, 16, 0xffff},
+		{100, 16, 0xffff},
+	}
+	for i := range tcs {
+		lanes, mask := portableBatch(tcs[i].n)
+		if lanes != tcs[i].lanes || mask != tcs[i].mask {
+			t.Errorf("portableBatch(%d) = %d, %#x; want %d, %#x",
+				tcs[i].n, lanes, mask, tcs[i].lanes, tcs[i].mask)
+		}
+	}
+}
+
+// TestPortablePartialBatch runs the portable filter
+// over inputs whose final batch has fewer than
+// bcLaneCount rows and checks that it agrees with
+// evaluating every row on its own
+func TestPortablePartialBatch(t *testing.T) {
+	var st symtab
+	defer st.free()
+	rest, err := st.Unmarshal(unhex(parkingCitations1KLines))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem := Malloc()
+	defer Free(mem)
+	if len(rest) > len(mem) {
+		t.Fatalf("test data (%d bytes) doesn't fit in a page", len(rest))
+	}
+	mem = mem[:copy(mem, rest)]
+	all := make([]vmref, 1024)
+	rows, _ := scanvmm(mem, all)
+	all = all[:rows]
+
+	p, err := compileLogical(expr.Compare(expr.Equals, expr.Ident("Make"), expr.String("HOND")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sample prog
+	err = p.cloneSymbolize(&st, &sample, &auxbindings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bc bytecode
+	err = sample.compile(&bc, &st, "TestPortablePartialBatch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.reset()
+	bc.symtab = st.symrefs
+	bc.prepare(&rowParams{})
+
+	// evaluate each row in a batch of its own:
+	match := make([]bool, len(all))
+	matched := 0
+	for i := range all {
+		one := []vmref{all[i]}
+		match[i] = evalfiltergo(&bc, one) == 1
+		if bc.err != 0 {
+			t.Fatal(bytecodeerror("filter", &bc))
+		}
+		if match[i] {
+			matched++
+		}
+	}
+	if matched == 0 || matched == len(all) {
+		t.Fatalf("%d of %d rows matched", matched, len(all))
+	}
+
+	for _, n := range []int{1, 3, 15, 16, 17, 31, 33, 47, len(all)} {
+		delims := make([]vmref, n)
+		copy(delims, all)
+		got := evalfiltergo(&bc, delims)
+		if bc.err != 0 {
+			t.Fatal(bytecodeerror("filter", &bc))
+		}
+		var want []vmref
+		for i := 0; i < n; i++ {
+			if match[i] {
+				want = append(want, all[i])
+			}
+		}
+		if got != len(want) {
+			t.Errorf("%d rows: got %d matches, want %d", n, got, len(want))
+			continue
+		}
+		for i := range want {
+			if delims[i] != want[i] {
+				t.Errorf("%d rows: match %d is %v, want %v", n, i, delims[i], want[i])
+				break
+			}
+		}
+	}
+}
+
+// compressauxlanes is the lane-at-a-time
+// equivalent of compressaux
+func compressauxlanes(auxvals [][]vmref, dst, src int, mask uint16) {
+	for k := 0; k < bcLaneCount; k++ {
+		if mask&(1<<k) == 0 {
+			continue
+		}
+		for l := range auxvals {
+			auxvals[l][dst] = auxvals[l][src+k]
+		}
+		dst++
+	}
+}
+
+func TestCompressAux(t *testing.T) {
+	const rows = 16 * 10
+	rng := rand.New(rand.NewSource(1))
+	for cols := 0; cols < 10; cols++ {
+		want := make([][]vmref, cols)
+		for l := range want {
+			want[l] = make([]vmref, rows)
+			for i := range want[l] {
+				want[l][i] = vmref{uint32(l), uint32(i)}
+			}
+		}
+		got := make([][]vmref, cols)
+		for l := range got {
+			got[l] = slices.Clone(want[l])
+		}
+		dst := 0
+		for src := 0; src < rows; src += bcLaneCount {
+			mask := uint16(rng.Intn(1 << 16))
+			switch src / bcLaneCount {
+			case 0:
+				mask = 0xffff
+			case 1:
+				mask = 0
+			}
+			compressaux(got, dst, src, mask)
+			compressauxlanes(want, dst, src, mask)
+			for l := range want {
+				if !slices.Equal(got[l], want[l]) {
+					t.Fatalf("%d columns, mask %#x: got %v, want %v", cols, mask, got[l], want[l])
+				}
+			}
+			dst += bits.OnesCount16(mask)
+		}
+	}
+}
+
+// BenchmarkFilterAux measures evalfiltergo with a
+// filter that keeps about half of the rows and
+// 8 auxiliary columns that need to be compressed
+func BenchmarkFilterAux(b *testing.B) {
+	var st symtab
+	defer st.free()
+	rest, err := st.Unmarshal(unhex(parkingCitations1KLines))
+	if err != nil {
+		b.Fatal(err)
+	}
+	mem := Malloc()
+	defer Free(mem)
+	mem = mem[:copy(mem, rest)]
+	all := make([]vmref, 1024)
+	rows, _ := scanvmm(mem, all)
+	all = all[:rows]
+
+	p, err := compileLogical(expr.Compare(expr.Less, expr.Ident("Fine"), expr.Integer(70)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	var sample prog
+	err = p.cloneSymbolize(&st, &sample, &auxbindings{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	var bc bytecode
+	err = sample.compile(&bc, &st, "BenchmarkFilterAux")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer bc.reset()
+	bc.symtab = st.symrefs
+
+	aux := make([][]vmref, 8)
+	for i := range aux {
+		aux[i] = make([]vmref, rows)
+	}
+	delims := make([]vmref, rows)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(delims, all)
+		bc.prepare(&rowParams{auxbound: aux})
+		evalfiltergo(&bc, delims)
+		if bc.err != 0 {
+			b.Fatal(bytecodeerror("filter", &bc))
+		}
+	}
+}
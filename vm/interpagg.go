@@ -36,6 +36,8 @@ func init() {
 	opinfo[opaggcount].portable = bcaggcountgo
 	opinfo[opaggmergestate].portable = bcaggmergestatego
 
+	opinfo[opaggbucketbool].portable = bcaggbucketboolgo
+
 	opinfo[opaggslotandk].portable = bcaggslotandkgo
 	opinfo[opaggslotork].portable = bcaggslotorkgo
 
@@ -55,6 +57,11 @@ func init() {
 	opinfo[opaggslotmergestate].portable = bcaggslotmergestatego
 
 	opinfo[opaggapproxcount].portable = bcaggapproxcountgo
+
+	opinfo[opaggmode].portable = bcaggmodego
+	opinfo[opaggmode].goonly = true
+	opinfo[opaggslotmode].portable = bcaggslotmodego
+	opinfo[opaggslotmode].goonly = true
 }
 
 type f64AggState struct {
@@ -96,6 +103,34 @@ func approxAggValues(bc *bytecode) []byte {
 	return unsafe.Slice((*byte)(bc.vmState.aggPtr), 1<<precisionBytes)
 }
 
+func bcaggbucketboolgo(bc *bytecode, pc int) int {
+	dst := argptr[bRegData](bc, pc)
+	key := argptr[kRegData](bc, pc+2).mask
+	mask := argptr[kRegData](bc, pc+4).mask
+	direct := &(*radixTree64)(bc.vmState.aggPtr).direct
+
+	var out bRegData
+	missing := uint16(0)
+	for lane := 0; lane < bcLaneCount; lane++ {
+		if mask&(1<<lane) == 0 {
+			continue
+		}
+		off := direct[(key>>lane)&1]
+		if off < 0 {
+			missing |= 1 << lane
+			continue
+		}
+		out.offsets[lane] = uint32(off)
+	}
+	if missing != 0 {
+		bc.err = bcerrNeedRadix
+		bc.missingBucketMask = missing
+		return pc + 6
+	}
+	*dst = out
+	return pc + 6
+}
+
 func bcaggapproxcountgo(bc *bytecode, pc int) int {
 	imm0 := bcword32(bc, pc+0)
 	h := *argptr[hRegData](bc, pc+4)
@@ -279,15 +314,25 @@ func bcaggsumigo(bc *bytecode, pc int) int {
 	srcmask := argptr[kRegData](bc, pc+6).mask
 
 	s := refAggState[i64AggState](bc, imm)
-	m := s.value
 
+	// accumulate into a 128-bit sum so that
+	// only the final result has to fit into int64
+	lo := uint64(s.value)
+	hi := s.value >> 63
 	for lane := 0; lane < bcLaneCount; lane++ {
 		if srcmask&(1<<lane) != 0 {
-			m += arg0.values[lane]
+			x := arg0.values[lane]
+			var c uint64
+			lo, c = bits.Add64(lo, uint64(x), 0)
+			hi += (x >> 63) + int64(c)
 		}
 	}
+	if hi != int64(lo)>>63 {
+		bc.err = bcerrIntOverflow
+		return pc + 8
+	}
 
-	s.value = m
+	s.value = int64(lo)
 	s.count += int64(bits.OnesCount16(srcmask))
 	return pc + 8
 }
@@ -406,6 +451,42 @@ func bcaggslotmergestatego(bc *bytecode, pc int) int {
 	return pc + 10
 }
 
+func bcaggmodego(bc *bytecode, pc int) int {
+	imm := bcword32(bc, pc+0)
+	v := argptr[vRegData](bc, pc+4)
+	srcmask := argptr[kRegData](bc, pc+6).mask
+	s := modeState(refAggState[[modeDataSize]byte](bc, imm)[:])
+
+	for lane := 0; lane < bcLaneCount; lane++ {
+		if srcmask&(1<<lane) != 0 {
+			k, ok := modeKeyOf(vmref{v.offsets[lane], v.sizes[lane]}.mem())
+			if ok {
+				s.add(k, 1)
+			}
+		}
+	}
+	return pc + 8
+}
+
+func bcaggslotmodego(bc *bytecode, pc int) int {
+	imm := bcword32(bc, pc+0)
+	buckets := argptr[bRegData](bc, pc+4).offsets
+	v := argptr[vRegData](bc, pc+6)
+	srcmask := argptr[kRegData](bc, pc+8).mask
+	values := hashAggValues(bc)
+
+	for lane := 0; lane < bcLaneCount; lane++ {
+		if srcmask&(1<<lane) != 0 {
+			k, ok := modeKeyOf(vmref{v.offsets[lane], v.sizes[lane]}.mem())
+			if ok {
+				mem := values[imm+uint32(aggregateTagSize)+buckets[lane]:]
+				modeState(mem[:modeDataSize]).add(k, 1)
+			}
+		}
+	}
+	return pc + 10
+}
+
 func bcaggslotandkgo(bc *bytecode, pc int) int {
 	return aggregateSlotMarkOpK(bc, pc, func(a, b int64) int64 { return a & b })
 }
@@ -415,7 +496,25 @@ func bcaggslotorkgo(bc *bytecode, pc int) int {
 }
 
 func bcaggslotsumigo(bc *bytecode, pc int) int {
-	return aggregateSlotMarkOpI64(bc, pc, func(a, b int64) int64 { return a + b })
+	imm := bcword32(bc, pc+0)
+	buckets := argptr[bRegData](bc, pc+4).offsets
+	src0 := argptr[i64RegData](bc, pc+6).values
+	srcmask := argptr[kRegData](bc, pc+8).mask
+	values := hashAggValues(bc)
+
+	for lane := 0; lane < bcLaneCount; lane++ {
+		if srcmask&(1<<lane) != 0 {
+			mem := values[imm+uint32(aggregateTagSize)+buckets[lane]:]
+			sum, ok := addInt64(int64(binary.LittleEndian.Uint64(mem)), src0[lane])
+			if !ok {
+				bc.err = bcerrIntOverflow
+				return pc + 10
+			}
+			binary.LittleEndian.PutUint64(mem, uint64(sum))
+			binary.LittleEndian.PutUint64(mem[8:], 1)
+		}
+	}
+	return pc + 10
 }
 
 func bcaggslotsumfgo(bc *bytecode, pc int) int {
@@ -473,7 +572,12 @@ func bcaggslotavgigo(bc *bytecode, pc int) int {
 	for lane := 0; lane < bcLaneCount; lane++ {
 		if srcmask&(1<<lane) != 0 {
 			mem := values[imm+uint32(aggregateTagSize)+buckets[lane]:]
-			binary.LittleEndian.PutUint64(mem, uint64(src0[lane])+binary.LittleEndian.Uint64(mem))
+			sum, ok := addInt64(int64(binary.LittleEndian.Uint64(mem)), src0[lane])
+			if !ok {
+				bc.err = bcerrIntOverflow
+				return pc + 10
+			}
+			binary.LittleEndian.PutUint64(mem, uint64(sum))
 			binary.LittleEndian.PutUint64(mem[8:], binary.LittleEndian.Uint64(mem[8:])+1)
 		}
 	}
@@ -134,6 +134,134 @@ func bcarraypositiongo(bc *bytecode, pc int) int {
 	return pc + 10
 }
 
+func bcarraycontainsgo(bc *bytecode, pc int) int {
+	list := argptr[sRegData](bc, pc+2)
+	item := argptr[vRegData](bc, pc+4)
+	srcMask := argptr[kRegData](bc, pc+6).mask
+
+	dstMask := uint16(0)
+	for i := 0; i < bcLaneCount; i++ {
+		if (srcMask & (1 << i)) != 0 {
+			list := vmref{list.offsets[i], list.sizes[i]}.mem()
+			for len(list) != 0 {
+				aSize := ion.SizeOf(list)
+				if aSize <= 0 || aSize > len(list) {
+					break
+				}
+				if valueEquals(bc, list[0:aSize], vmref{item.offsets[i], item.sizes[i]}.mem()) {
+					dstMask |= 1 << i
+					break
+				}
+				list = list[aSize:]
+			}
+		}
+	}
+
+	*argptr[kRegData](bc, pc+0) = kRegData{dstMask}
+	return pc + 8
+}
+
+func bcarrayindexgo(bc *bytecode, pc int) int {
+	list := argptr[sRegData](bc, pc+4)
+	index := argptr[i64RegData](bc, pc+6)
+
+	dst := vRegData{}
+	dstMask := uint16(0)
+	srcMask := argptr[kRegData](bc, pc+8).mask
+
+	for i := 0; i < bcLaneCount; i++ {
+		if (srcMask&(1<<i)) == 0 || index.values[i] < 0 {
+			continue
+		}
+		mem := vmref{list.offsets[i], list.sizes[i]}.mem()
+		pos := uint32(0)
+		for n := index.values[i]; n > 0 && len(mem) != 0; n-- {
+			size := ion.SizeOf(mem)
+			if size <= 0 || size > len(mem) {
+				mem = nil
+				break
+			}
+			mem = mem[size:]
+			pos += uint32(size)
+		}
+		if len(mem) == 0 {
+			continue
+		}
+		size := ion.SizeOf(mem)
+		if size <= 0 || size > len(mem) {
+			continue
+		}
+		dst.offsets[i] = list.offsets[i] + pos
+		dst.sizes[i] = uint32(size)
+		dst.typeL[i] = mem[0]
+		dst.headerSize[i] = byte(ion.HeaderSizeOf(mem))
+		dstMask |= 1 << i
+	}
+
+	*argptr[vRegData](bc, pc+0) = dst
+	*argptr[kRegData](bc, pc+2) = kRegData{dstMask}
+
+	return pc + 10
+}
+
+// sliceBounds returns the half-open range of
+// the elements of a list of length n selected by
+// list[start:end]; negative bounds count backwards
+// from the end of the list and out-of-range bounds
+// are clamped to the list
+func sliceBounds(start, end int64, n int) (int, int) {
+	clamp := func(off int64) int {
+		if off < 0 {
+			off += int64(n)
+		}
+		return int(min(max(off, 0), int64(n)))
+	}
+	lo, hi := clamp(start), clamp(end)
+	return lo, max(lo, hi)
+}
+
+// listOffset returns the position of the n-th
+// element of the (valid) list contents in mem
+func listOffset(mem []byte, n int) int {
+	pos := 0
+	for ; n > 0; n-- {
+		pos += ion.SizeOf(mem[pos:])
+	}
+	return pos
+}
+
+func bcarrayslicego(bc *bytecode, pc int) int {
+	list := argptr[sRegData](bc, pc+4)
+	start := argptr[i64RegData](bc, pc+6)
+	end := argptr[i64RegData](bc, pc+8)
+
+	dst := sRegData{}
+	dstMask := uint16(0)
+	srcMask := argptr[kRegData](bc, pc+10).mask
+
+	for i := 0; i < bcLaneCount; i++ {
+		if (srcMask & (1 << i)) == 0 {
+			continue
+		}
+		mem := vmref{list.offsets[i], list.sizes[i]}.mem()
+		count := countValuesInList(mem)
+		if count < 0 {
+			continue
+		}
+		lo, hi := sliceBounds(start.values[i], end.values[i], count)
+		from := listOffset(mem, lo)
+		to := from + listOffset(mem[from:], hi-lo)
+		dst.offsets[i] = list.offsets[i] + uint32(from)
+		dst.sizes[i] = uint32(to - from)
+		dstMask |= 1 << i
+	}
+
+	*argptr[sRegData](bc, pc+0) = dst
+	*argptr[kRegData](bc, pc+2) = kRegData{dstMask}
+
+	return pc + 12
+}
+
 func bcarraysumgo(bc *bytecode, pc int) int {
 	src := argptr[sRegData](bc, pc+4)
 	dst := f64RegData{}
@@ -15,6 +15,7 @@
 package vm
 
 import (
+	"github.com/SnellerInc/sneller/date"
 	"github.com/SnellerInc/sneller/fastdate"
 )
 
@@ -589,3 +590,62 @@ func bcdatetruncyeargo(bc *bytecode, pc int) int {
 	*argptr[tsRegData](bc, pc) = dst
 	return pc + 6
 }
+
+func bcparsetsgo(bc *bytecode, pc int) int {
+	src := argptr[sRegData](bc, pc+4)
+	layout := bc.dict[bcword(bc, pc+6)]
+	msk := argptr[kRegData](bc, pc+8).mask
+
+	dst := tsRegData{}
+	retmask := uint16(0)
+
+	for i := 0; i < bcLaneCount; i++ {
+		if (msk & (1 << i)) == 0 {
+			continue
+		}
+
+		str := vmref{src.offsets[i], src.sizes[i]}.mem()
+		result, ok := date.ParseLayout(layout, str)
+		if ok {
+			dst.values[i] = result.UnixMicro()
+			retmask |= 1 << i
+		}
+	}
+
+	*argptr[tsRegData](bc, pc) = dst
+	*argptr[kRegData](bc, pc+2) = kRegData{retmask}
+
+	return pc + 10
+}
+
+func bcformattsgo(bc *bytecode, pc int) int {
+	src := argptr[tsRegData](bc, pc+4)
+	layout := bc.dict[bcword(bc, pc+6)]
+	msk := argptr[kRegData](bc, pc+8).mask
+
+	dst := sRegData{}
+	for i := 0; i < bcLaneCount; i++ {
+		if (msk & (1 << i)) == 0 {
+			continue
+		}
+
+		p := len(bc.scratch)
+		mem := date.UnixMicro(src.values[i]).AppendFormat(bc.scratch, layout)
+		if cap(mem) != cap(bc.scratch) {
+			// had to realloc the buffer for space;
+			// means the scratch buffer didn't have enough capacity:
+			bc.err = bcerrMoreScratch
+			return pc + 10
+		}
+		if len(mem) > p {
+			dst.offsets[i], _ = vmdispl(mem[p:])
+			dst.sizes[i] = uint32(len(mem) - p)
+		}
+		bc.scratch = mem
+	}
+
+	*argptr[sRegData](bc, pc) = dst
+	*argptr[kRegData](bc, pc+2) = kRegData{msk}
+
+	return pc + 10
+}
@@ -15,6 +15,7 @@
 package vm
 
 import (
+	"encoding/binary"
 	"math"
 )
 
@@ -50,6 +51,7 @@ func init() {
 	opinfo[oprpmodf64imm].portable = bcrpmodf64immgo
 	opinfo[opsignf64].portable = bcsignf64go
 	opinfo[opbroadcastf64].portable = bcbroadcastf64go
+	opinfo[oprandom].portable = bcrandomgo
 }
 
 func bcabsf64go(bc *bytecode, pc int) int {
@@ -181,6 +183,47 @@ func bcbroadcastf64go(bc *bytecode, pc int) int {
 	return pc + 10
 }
 
+// randomRow returns a pseudo-random number in [0, 1)
+// derived from seed, the position of the row and the
+// content of row; this is the reference implementation
+// of the random opcode
+func randomRow(seed, pos uint64, row []byte) float64 {
+	h := uint32(len(row))
+	for len(row) > 0 {
+		var word [4]byte
+		n := copy(word[:], row)
+		h = (h ^ binary.LittleEndian.Uint32(word[:])) * 0x9e3779b1
+		h ^= h >> 15
+		row = row[n:]
+	}
+
+	// splitmix64, with pos+1 steps from seed^h
+	z := (seed ^ uint64(h)) + (pos+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return math.Float64frombits((z>>12)|0x3ff0000000000000) - 1
+}
+
+func bcrandomgo(bc *bytecode, pc int) int {
+	dest := argptr[f64RegData](bc, pc+0)
+	rows := argptr[bRegData](bc, pc+2)
+	seed := bcword64(bc, pc+4)
+	argmask := argptr[kRegData](bc, pc+12).mask
+	r := f64RegData{}
+
+	for lane := 0; lane < bcLaneCount; lane++ {
+		if argmask&(1<<lane) != 0 {
+			row := vmref{rows.offsets[lane], rows.sizes[lane]}.mem()
+			r.values[lane] = randomRow(seed, bc.random+uint64(lane), row)
+		}
+	}
+	bc.random += bcLaneCount
+
+	*dest = r
+	return pc + 14
+}
+
 func bcsquaref64go(bc *bytecode, pc int) int {
 	dest := argptr[f64RegData](bc, pc+0)
 	arg0 := argptr[f64RegData](bc, pc+2)
@@ -17,6 +17,8 @@ package vm
 import (
 	"encoding/binary"
 
+	"github.com/SnellerInc/sneller/ion"
+
 	"github.com/dchest/siphash"
 )
 
@@ -116,7 +118,7 @@ func bchashlookupgo(bc *bytecode, pc int) int {
 					destv.sizes[lane] = size
 					mem := vmref{offs, size}.mem()
 					destv.typeL[lane] = mem[0]
-					destv.headerSize[lane] = byte(getTLVSize(uint(size)))
+					destv.headerSize[lane] = byte(ion.HeaderSizeOf(mem))
 				}
 			}
 		}
@@ -133,3 +135,19 @@ func bchashlookupgo(bc *bytecode, pc int) int {
 	destk.mask = retmask
 	return pc + 10
 }
+
+func bchashbucketgo(bc *bytecode, pc int) int {
+	hashes := argptr[hRegData](bc, pc+2).lo
+	mask := bcword64(bc, pc+4)
+	msk := argptr[kRegData](bc, pc+12).mask
+	dst := i64RegData{}
+
+	for i := 0; i < bcLaneCount; i++ {
+		if (msk & (1 << i)) != 0 {
+			dst.values[i] = int64(hashes[i] & mask)
+		}
+	}
+
+	*argptr[i64RegData](bc, pc) = dst
+	return pc + 14
+}
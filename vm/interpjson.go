@@ -0,0 +1,133 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+func bcjsonextractgo(bc *bytecode, pc int) int {
+	dst := argptr[vRegData](bc, pc)
+	retk := argptr[kRegData](bc, pc+2)
+	src := argptr[sRegData](bc, pc+4)
+	steps := bc.jsonpaths[bcword(bc, pc+6)]
+	mask := argptr[kRegData](bc, pc+8).mask
+
+	var out vRegData
+	var outk uint16
+	var buf ion.Buffer
+	buf.Set(bc.scratch)
+	p := len(bc.scratch)
+	for i := 0; i < bcLaneCount; i++ {
+		if mask&(1<<i) == 0 {
+			continue
+		}
+		doc := vmref{src.offsets[i], src.sizes[i]}.mem()
+		if !json.Valid(doc) {
+			continue
+		}
+		val, ok := jsonextract(doc, steps)
+		if !ok || !jsonbox(&buf, val) {
+			continue
+		}
+		result := buf.Bytes()[p:]
+		start, ok := vmdispl(result)
+		if !ok {
+			// had to realloc the buffer for space;
+			// means the scratch buffer didn't have enough capacity:
+			bc.err = bcerrMoreScratch
+			return pc + 10
+		}
+		out.offsets[i] = start
+		out.sizes[i] = uint32(len(result))
+		out.typeL[i] = result[0]
+		out.headerSize[i] = byte(ion.HeaderSizeOf(result))
+		outk |= 1 << i
+		p = buf.Size()
+	}
+	bc.scratch = buf.Bytes()
+	*dst = out
+	retk.mask = outk
+	return pc + 10
+}
+
+// jsonextract navigates to the value in the
+// (valid) JSON document doc that is selected by steps
+func jsonextract(doc []byte, steps []expr.JSONPathStep) ([]byte, bool) {
+	for i := range steps {
+		if steps[i].Field != "" {
+			var obj map[string]json.RawMessage
+			if json.Unmarshal(doc, &obj) != nil {
+				return nil, false
+			}
+			val, ok := obj[steps[i].Field]
+			if !ok {
+				return nil, false
+			}
+			doc = val
+		} else {
+			var arr []json.RawMessage
+			if json.Unmarshal(doc, &arr) != nil || steps[i].Index >= len(arr) {
+				return nil, false
+			}
+			doc = arr[steps[i].Index]
+		}
+	}
+	return bytes.TrimSpace(doc), true
+}
+
+// jsonbox writes the JSON value val into dst;
+// objects and arrays are written as their compact
+// JSON text since field names cannot be interned
+// while the query is running
+func jsonbox(dst *ion.Buffer, val []byte) bool {
+	if len(val) == 0 {
+		return false
+	}
+	switch val[0] {
+	case 'n':
+		dst.WriteNull()
+	case 't', 'f':
+		dst.WriteBool(val[0] == 't')
+	case '"':
+		var s string
+		if json.Unmarshal(val, &s) != nil {
+			return false
+		}
+		dst.WriteString(s)
+	case '{', '[':
+		var compact bytes.Buffer
+		if json.Compact(&compact, val) != nil {
+			return false
+		}
+		dst.WriteStringBytes(compact.Bytes())
+	default:
+		if i, err := strconv.ParseInt(string(val), 10, 64); err == nil {
+			dst.WriteInt(i)
+			return true
+		}
+		f, err := strconv.ParseFloat(string(val), 64)
+		if err != nil {
+			return false
+		}
+		dst.WriteFloat64(f)
+	}
+	return true
+}
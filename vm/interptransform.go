@@ -0,0 +1,96 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"github.com/SnellerInc/sneller/ion"
+)
+
+func bctransformgo(bc *bytecode, pc int) int {
+	dst := argptr[sRegData](bc, pc+0)
+	retk := argptr[kRegData](bc, pc+2)
+	src := argptr[sRegData](bc, pc+4)
+	fn := bc.lambdas[bcword(bc, pc+6)]
+	mask := argptr[kRegData](bc, pc+8).mask
+
+	var out sRegData
+	var outk uint16
+	var elems []vmref
+	for i := 0; i < bcLaneCount; i++ {
+		if mask&(1<<i) == 0 {
+			continue
+		}
+		elems = elems[:0]
+		pos := src.offsets[i]
+		list := vmref{src.offsets[i], src.sizes[i]}.mem()
+		for len(list) > 0 {
+			size := ion.SizeOf(list)
+			if size <= 0 || size > len(list) {
+				break
+			}
+			elems = append(elems, vmref{pos, uint32(size)})
+			list = list[size:]
+			pos += uint32(size)
+		}
+		start := len(bc.scratch)
+		var err bcerr
+		bc.scratch, err = evallambda(fn, elems, bc.scratch)
+		if err != 0 {
+			bc.err = err
+			return pc + 10
+		}
+		out.offsets[i] = bc.scratchoff + uint32(start)
+		out.sizes[i] = uint32(len(bc.scratch) - start)
+		outk |= 1 << i
+	}
+	*dst = out
+	retk.mask = outk
+	return pc + 10
+}
+
+// evallambda evaluates fn with each of args
+// bound to its parameter and appends the results
+// that are not MISSING to dst; dst is never
+// reallocated, since it must remain addressable
+// by the VM
+func evallambda(fn *bytecode, args []vmref, dst []byte) ([]byte, bcerr) {
+	var alt bytecode
+	fn.auxvals = append(fn.auxvals[:0], args)
+	fn.auxpos = 0
+	for len(args) > 0 {
+		lanes, mask := portableBatch(len(args))
+		fn.err = 0
+		fn.vmState.validLanes.mask = mask
+		fn.vmState.outputLanes.mask = mask
+		setvmrefB(&fn.vmState.delims, args[:lanes])
+		eval(fn, &alt, true)
+		if fn.err != 0 {
+			return dst, fn.err
+		}
+		ret := &vRegDataFromVStackCast(&fn.vstack, 1)[0]
+		for j := 0; j < lanes; j++ {
+			if ret.sizes[j] == 0 {
+				continue // MISSING
+			}
+			mem := vmref{ret.offsets[j], ret.sizes[j]}.mem()
+			if len(dst)+len(mem) > cap(dst) {
+				return dst, bcerrMoreScratch
+			}
+			dst = append(dst, mem...)
+		}
+		args = args[lanes:]
+	}
+	return dst, 0
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"io"
+	"sync/atomic"
+
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// IonTable is a Table implementation that
+// reads a stream of binary ion from an io.Reader.
+//
+// Unlike a table read from a blockfmt trailer,
+// an IonTable requires neither random access to
+// its input nor knowledge of its size, so it can
+// be used with pipes and other streaming sources.
+// The trade-off is that no part of the input can
+// be skipped: every row is read and re-chunked by
+// a single goroutine, and the resulting chunks are
+// distributed among the parallel outputs of WriteChunks.
+//
+// Since the input is consumed as it is read,
+// WriteChunks can only be called once.
+type IonTable struct {
+	src   io.Reader
+	bytes int64
+}
+
+// NewIonTable constructs an IonTable that
+// reads ion values from src.
+func NewIonTable(src io.Reader) *IonTable {
+	return &IonTable{src: src}
+}
+
+// Bytes returns the number of bytes of
+// ion data that have been produced so far.
+func (t *IonTable) Bytes() int64 { return atomic.LoadInt64(&t.bytes) }
+
+func (t *IonTable) convert(cn *ion.Chunker) error {
+	_, err := cn.ReadFrom(t.src, nil)
+	return err
+}
+
+// WriteChunks implements Table.WriteChunks
+func (t *IonTable) WriteChunks(dst QuerySink, parallel int) error {
+	return writeConverted(dst, parallel, &t.bytes, t.convert)
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+func TestIonTable(t *testing.T) {
+	const rows = 10000
+	// write two streams with different symbol tables
+	// into a pipe so that the table cannot seek
+	r, w := io.Pipe()
+	go func() {
+		for part := 0; part < 2; part++ {
+			var st ion.Symtab
+			if part == 1 {
+				st.Intern("padding") // shift the symbol IDs
+			}
+			var body ion.Buffer
+			for i := part * rows / 2; i < (part+1)*rows/2; i++ {
+				body.BeginStruct(-1)
+				body.BeginField(st.Intern("name"))
+				body.WriteString(fmt.Sprintf("row%d", i))
+				body.BeginField(st.Intern("age"))
+				body.WriteInt(int64(i))
+				body.EndStruct()
+			}
+			var out ion.Buffer
+			st.Marshal(&out, true)
+			out.UnsafeAppend(body.Bytes())
+			if _, err := w.Write(out.Bytes()); err != nil {
+				w.CloseWithError(err)
+				return
+			}
+		}
+		w.Close()
+	}()
+	tbl := NewIonTable(r)
+	names, err := queryJSON(t, tbl, "age >= 9990 OR age < 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"row0", "row1"}
+	for i := 9990; i < rows; i++ {
+		want = append(want, fmt.Sprintf("row%d", i))
+	}
+	slices.Sort(want)
+	if !slices.Equal(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+	if tbl.Bytes() == 0 {
+		t.Error("no bytes produced")
+	}
+}
+
+func TestDeleteRows(t *testing.T) {
+	var st ion.Symtab
+	var body ion.Buffer
+	for i := 0; i < 1000; i++ {
+		body.BeginStruct(-1)
+		body.BeginField(st.Intern("age"))
+		body.WriteInt(int64(i))
+		if i%2 == 0 {
+			body.BeginField(st.Intern("name"))
+			body.WriteString(fmt.Sprintf("row%d", i))
+		}
+		body.EndStruct()
+	}
+	var in ion.Buffer
+	st.Marshal(&in, true)
+	in.UnsafeAppend(body.Bytes())
+
+	// rows for which the predicate is MISSING are kept
+	pred := expr.Or(
+		expr.Compare(expr.Less, expr.Ident("age"), expr.Integer(10)),
+		expr.Compare(expr.Equals, expr.Ident("name"), expr.String("row500")))
+	var out bytes.Buffer
+	err := DeleteRows(&out, bytes.NewReader(in.Bytes()), pred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ages []int64
+	st.Reset()
+	rest := out.Bytes()
+	for len(rest) > 0 {
+		if ion.TypeOf(rest) == ion.NullType && ion.SizeOf(rest) > 1 {
+			rest = rest[ion.SizeOf(rest):]
+			continue
+		}
+		var d ion.Datum
+		d, rest, err = ion.ReadDatum(&st, rest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.IsEmpty() {
+			continue
+		}
+		age, err := d.Field("age").Int()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ages = append(ages, age)
+	}
+	var want []int64
+	for i := int64(10); i < 1000; i++ {
+		if i != 500 {
+			want = append(want, i)
+		}
+	}
+	if !slices.Equal(ages, want) {
+		t.Errorf("got %d rows %v...", len(ages), ages[:min(len(ages), 10)])
+	}
+}
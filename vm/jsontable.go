@@ -0,0 +1,243 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/SnellerInc/sneller/ion"
+	"github.com/SnellerInc/sneller/jsonrl"
+)
+
+// JSONPolicy determines how a JSONTable
+// handles malformed input records.
+type JSONPolicy int
+
+const (
+	// JSONFail causes WriteChunks to stop
+	// at the first malformed record and
+	// return an error that describes it.
+	JSONFail JSONPolicy = iota
+	// JSONSkip causes malformed lines of
+	// NDJSON input to be skipped. The number of
+	// skipped lines is reported by JSONTable.Skipped.
+	JSONSkip
+)
+
+// jsonBatchSize is the amount of NDJSON
+// that a JSONTable converts at once when
+// it has to validate each line
+const jsonBatchSize = 256 * 1024
+
+// errStopped is returned to the converter of
+// a JSONTable or IonTable once there are no
+// more readers of its output
+var errStopped = errors.New("vm: table output closed")
+
+// JSONTable is a Table implementation that
+// converts JSON records to ion as they are read.
+//
+// The input can be NDJSON (or any sequence of
+// JSON records) or a JSON array of records.
+// The conversion itself is performed by a single
+// goroutine; the resulting chunks are distributed
+// among the parallel outputs of WriteChunks.
+//
+// Since the input is consumed as it is converted,
+// WriteChunks can only be called once.
+type JSONTable struct {
+	src    io.Reader
+	policy JSONPolicy
+	hints  *jsonrl.Hint
+
+	skipped int64
+	bytes   int64
+}
+
+// NewJSONTable constructs a JSONTable that reads
+// JSON records from src. The policy only applies
+// to NDJSON input: a malformed JSON array always
+// causes WriteChunks to fail.
+func NewJSONTable(src io.Reader, policy JSONPolicy) *JSONTable {
+	return &JSONTable{src: src, policy: policy}
+}
+
+// UseHints sets the hints that determine how
+// certain fields are interpreted (see jsonrl.Hint).
+func (j *JSONTable) UseHints(h *jsonrl.Hint) { j.hints = h }
+
+// Skipped returns the number of lines
+// that were skipped because they were
+// not valid JSON records.
+func (j *JSONTable) Skipped() int64 { return atomic.LoadInt64(&j.skipped) }
+
+// Bytes returns the number of bytes of
+// ion data that have been produced so far.
+func (j *JSONTable) Bytes() int64 { return atomic.LoadInt64(&j.bytes) }
+
+// chunkWriter forwards the chunks written
+// by an ion.Chunker to out; each chunk is
+// copied into a buffer from Malloc
+type chunkWriter struct {
+	out  chan<- []byte
+	stop <-chan struct{}
+	n    *int64
+}
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	if len(p) > PageSize {
+		return 0, fmt.Errorf("vm: chunk of %d bytes exceeds PageSize", len(p))
+	}
+	buf := Malloc()[:len(p)]
+	copy(buf, p)
+	select {
+	case c.out <- buf:
+		atomic.AddInt64(c.n, int64(len(p)))
+		return len(p), nil
+	case <-c.stop:
+		Free(buf)
+		return 0, errStopped
+	}
+}
+
+// convert writes the contents of j.src to cn
+func (j *JSONTable) convert(cn *ion.Chunker) error {
+	in := bufio.NewReader(j.src)
+	if j.policy == JSONSkip && !startsArray(in) {
+		return j.convertLines(in, cn)
+	}
+	err := jsonrl.Convert(in, cn, j.hints, nil)
+	if err != nil {
+		return err
+	}
+	return cn.Flush()
+}
+
+// startsArray determines if the first
+// non-whitespace character of in is '['
+func startsArray(in *bufio.Reader) bool {
+	for i := 1; ; i++ {
+		buf, err := in.Peek(i)
+		if len(buf) < i {
+			return false
+		}
+		switch buf[i-1] {
+		case ' ', '\t', '\r', '\n':
+			if err != nil {
+				return false
+			}
+			continue
+		case '[':
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// convertLines converts NDJSON from in,
+// skipping the lines that are not records
+func (j *JSONTable) convertLines(in *bufio.Reader, cn *ion.Chunker) error {
+	var batch []byte
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := jsonrl.Convert(bytes.NewReader(batch), cn, j.hints, nil)
+		batch = batch[:0]
+		return err
+	}
+	for {
+		line, err := in.ReadBytes('\n')
+		if len(line) > 0 {
+			rec := bytes.TrimSpace(line)
+			if len(rec) > 0 {
+				if rec[0] != '{' || !json.Valid(rec) {
+					atomic.AddInt64(&j.skipped, 1)
+				} else {
+					batch = append(append(batch, rec...), '\n')
+				}
+			}
+		}
+		if len(batch) >= jsonBatchSize || err != nil {
+			if ferr := flush(); ferr != nil {
+				return ferr
+			}
+		}
+		if err == io.EOF {
+			return cn.Flush()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// WriteChunks implements Table.WriteChunks
+func (j *JSONTable) WriteChunks(dst QuerySink, parallel int) error {
+	return writeConverted(dst, parallel, &j.bytes, j.convert)
+}
+
+// writeConverted runs convert in a single goroutine
+// and distributes the chunks that it produces among
+// the parallel outputs opened from dst; n is updated
+// with the number of bytes of ion produced
+func writeConverted(dst QuerySink, parallel int, n *int64, convert func(cn *ion.Chunker) error) error {
+	chunks := make(chan []byte, parallel)
+	stop := make(chan struct{})
+	var converr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(chunks)
+		cn := ion.Chunker{
+			W:     &chunkWriter{out: chunks, stop: stop, n: n},
+			Align: PageSize,
+		}
+		converr = convert(&cn)
+	}()
+	err := SplitInput(dst, parallel, func(w io.Writer) error {
+		for buf := range chunks {
+			_, err := w.Write(buf)
+			Free(buf)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	close(stop)
+	// release the chunks that were
+	// converted but never consumed
+	for buf := range chunks {
+		Free(buf)
+	}
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+	if converr != nil && !errors.Is(converr, errStopped) {
+		return converr
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/SnellerInc/sneller/expr"
+	"github.com/SnellerInc/sneller/expr/partiql"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// queryJSON evaluates 'SELECT name FROM tbl WHERE where'
+// and returns the names in sorted order
+func queryJSON(t *testing.T, tbl Table, where string) ([]string, error) {
+	t.Helper()
+	q, err := partiql.Parse([]byte("SELECT name FROM tbl WHERE " + where))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out QueryBuffer
+	proj, err := NewProjection(Selection([]expr.Binding{expr.Bind(expr.Ident("name"), "name")}), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filt, err := NewFilter(q.Body.(*expr.Select).Where, proj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tbl.WriteChunks(filt, 4)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	var st ion.Symtab
+	body := out.Bytes()
+	for len(body) > 0 {
+		if ion.TypeOf(body) == ion.NullType && ion.SizeOf(body) > 1 {
+			// nop pad
+			body = body[ion.SizeOf(body):]
+			continue
+		}
+		var d ion.Datum
+		d, body, err = ion.ReadDatum(&st, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := d.Struct()
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, ok := s.FieldByName("name")
+		if !ok {
+			t.Fatalf("row %s has no name", d)
+		}
+		name, err := f.String()
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func TestJSONTable(t *testing.T) {
+	open := func(t *testing.T) *os.File {
+		f, err := os.Open("testdata/jsontable.ndjson")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+		return f
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		tbl := NewJSONTable(open(t), JSONSkip)
+		names, err := queryJSON(t, tbl, "age > 20")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"alice", "bob", "carol"}
+		if !slices.Equal(names, want) {
+			t.Errorf("got %v, want %v", names, want)
+		}
+		if n := tbl.Skipped(); n != 3 {
+			t.Errorf("skipped %d lines, want 3", n)
+		}
+	})
+	t.Run("fail", func(t *testing.T) {
+		tbl := NewJSONTable(open(t), JSONFail)
+		_, err := queryJSON(t, tbl, "age > 20")
+		if err == nil || !strings.Contains(err.Error(), "object 3") {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+	t.Run("array", func(t *testing.T) {
+		src := `[{"name": "x", "age": 1}, {"name": "y", "age": 2}, {"name": "z", "age": 3}]`
+		tbl := NewJSONTable(strings.NewReader(src), JSONSkip)
+		names, err := queryJSON(t, tbl, "age >= 2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"y", "z"}
+		if !slices.Equal(names, want) {
+			t.Errorf("got %v, want %v", names, want)
+		}
+	})
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"sync"
+
+	"github.com/SnellerInc/sneller/expr"
+)
+
+// ProgramCache is a cache of compiled programs
+// keyed on the text of the (already simplified)
+// expression that produced them.
+//
+// A cached program is only ever used as a template:
+// every consumer clones and symbolizes it into its
+// own prog and bytecode (see recompile), so the
+// template itself is never written to while it is
+// in the cache and may be shared by any number of
+// goroutines. The per-consumer copy is recompiled
+// whenever the symbol table or auxiliary bindings
+// change shape, so a cached program remains valid
+// across symbol tables.
+//
+// The zero value of ProgramCache is ready to use.
+type ProgramCache struct {
+	// Limit is the maximum number of programs
+	// retained by the cache. If Limit is zero,
+	// the number of programs is unbounded.
+	Limit int
+
+	lock     sync.Mutex
+	progs    map[string]*cacheEntry
+	compiles int
+}
+
+type cacheEntry struct {
+	prog *prog
+	refs int // number of outstanding users
+}
+
+// get returns the cached program for key, if present.
+// A program returned from get must be handed back
+// with release once the caller is done with it.
+// The caller must hold c.lock.
+func (c *ProgramCache) get(key string) (*prog, bool) {
+	ent, ok := c.progs[key]
+	if !ok {
+		return nil, false
+	}
+	ent.refs++
+	return ent.prog, true
+}
+
+// put adds the program p compiled from key to the cache.
+// On success the cache takes ownership of p and the
+// caller holds one reference to it, which must be
+// handed back with release. put returns false if a
+// program for key is already present, in which case
+// the caller retains ownership of p.
+// The caller must hold c.lock.
+func (c *ProgramCache) put(key string, p *prog) bool {
+	if _, ok := c.progs[key]; ok {
+		return false
+	}
+	if c.progs == nil {
+		c.progs = make(map[string]*cacheEntry)
+	}
+	c.progs[key] = &cacheEntry{prog: p, refs: 1}
+	c.evict()
+	return true
+}
+
+// release drops a reference to the cached
+// program for key obtained from get or put.
+func (c *ProgramCache) release(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ent, ok := c.progs[key]
+	if !ok || ent.refs == 0 {
+		panic("vm.ProgramCache: release of program not held")
+	}
+	ent.refs--
+	c.evict()
+}
+
+// evict drops unreferenced programs
+// until the cache is within its limit
+func (c *ProgramCache) evict() {
+	if c.Limit <= 0 {
+		return
+	}
+	for key, ent := range c.progs {
+		if len(c.progs) <= c.Limit {
+			return
+		}
+		if ent.refs == 0 {
+			ent.prog.reset()
+			delete(c.progs, key)
+		}
+	}
+}
+
+// Len returns the number of programs in the cache.
+func (c *ProgramCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.progs)
+}
+
+// NewFilter is equivalent to NewFilter,
+// but the compiled program for e is taken
+// from the cache when possible. The program
+// is released back to the cache when the
+// returned Filter is closed.
+//
+// A nil *ProgramCache is valid and
+// simply calls NewFilter.
+func (c *ProgramCache) NewFilter(e expr.Node, rest QuerySink) (*Filter, error) {
+	if c == nil {
+		return NewFilter(e, rest)
+	}
+	key := expr.ToString(e)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	p, ok := c.get(key)
+	if !ok {
+		var err error
+		p, err = compileLogical(e)
+		if err != nil {
+			return nil, err
+		}
+		c.compiles++
+		c.put(key, p)
+	}
+	f := where(p, rest)
+	f.cache = c
+	f.key = key
+	return f, nil
+}
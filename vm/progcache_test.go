@@ -0,0 +1,124 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/SnellerInc/sneller/expr"
+)
+
+func TestProgramCache(t *testing.T) {
+	buf := unhex(parkingCitations1KLines)
+	hondaBlack := func() expr.Node {
+		return expr.And(
+			expr.Compare(expr.Equals, expr.Ident("Make"), expr.String("HOND")),
+			expr.Compare(expr.Equals, expr.Ident("Color"), expr.String("BK")))
+	}
+
+	var c ProgramCache
+	run := func(e expr.Node) (*prog, int64) {
+		var out Count
+		f, err := c.NewFilter(e, &out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := f.prog
+		err = CopyRows(f, buftbl(buf), 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+		// closing twice must not release the program twice
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return p, out.Value()
+	}
+
+	p0, n0 := run(hondaBlack())
+	p1, n1 := run(hondaBlack())
+	if n0 != 24 || n1 != 24 {
+		t.Errorf("got %d and %d rows, want 24", n0, n1)
+	}
+	if c.compiles != 1 {
+		t.Errorf("identical query compiled %d times", c.compiles)
+	}
+	if p0 != p1 {
+		t.Error("second query did not reuse the cached program")
+	}
+
+	// a different query is compiled separately
+	_, n2 := run(expr.Compare(expr.Equals, expr.Ident("Make"), expr.String("HOND")))
+	if n2 == 0 || n2 == n0 {
+		t.Errorf("unexpected row count %d", n2)
+	}
+	if c.compiles != 2 || c.Len() != 2 {
+		t.Errorf("got %d compiles and %d entries, want 2", c.compiles, c.Len())
+	}
+
+	// unreferenced entries are evicted down to the limit
+	c.Limit = 1
+	key := expr.ToString(hondaBlack())
+	c.lock.Lock()
+	_, ok := c.get(key)
+	c.lock.Unlock()
+	if !ok {
+		t.Fatal("program missing from cache")
+	}
+	c.release(key)
+	if c.Len() != 1 {
+		t.Errorf("%d entries after eviction", c.Len())
+	}
+}
+
+func TestProgramCacheConcurrent(t *testing.T) {
+	buf := unhex(parkingCitations1KLines)
+	e := expr.Compare(expr.Equals, expr.Ident("Make"), expr.String("HOND"))
+	var c ProgramCache
+	errc := make(chan error, 8)
+	counts := make(chan int64, 8)
+	for i := 0; i < 8; i++ {
+		go func() {
+			var out Count
+			f, err := c.NewFilter(e, &out)
+			if err == nil {
+				err = CopyRows(f, buftbl(buf), 2)
+				if err == nil {
+					err = f.Close()
+				}
+			}
+			errc <- err
+			counts <- out.Value()
+		}()
+	}
+	var want int64 = -1
+	for i := 0; i < 8; i++ {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+		n := <-counts
+		if want == -1 {
+			want = n
+		} else if n != want {
+			t.Errorf("got %d rows, want %d", n, want)
+		}
+	}
+	if c.compiles != 1 {
+		t.Errorf("compiled %d times", c.compiles)
+	}
+}
@@ -42,6 +42,11 @@ type radixTree64 struct {
 	index  [][tabsize]int32
 	values []byte
 
+	// direct holds the value offsets of the
+	// FALSE and TRUE entries used by aggbucket.bool,
+	// or -1 if the entry has not been created yet
+	direct [2]int32
+
 	vsize int
 }
 
@@ -49,6 +54,7 @@ func (t *radixTree64) clone() *radixTree64 {
 	return &radixTree64{
 		index:  slices.Clone(t.index),
 		values: slices.Clone(t.values),
+		direct: t.direct,
 		vsize:  t.vsize,
 	}
 }
@@ -58,6 +64,7 @@ func newRadixTree(datasize int) *radixTree64 {
 		index:  make([][tabsize]int32, 1),
 		vsize:  aggregateTagSize + datasize,
 		values: make([]byte, 0, (aggregateTagSize+datasize)*16),
+		direct: [2]int32{-1, -1},
 	}
 	return rt
 }
@@ -497,6 +504,7 @@ var agg2cmp = [...](func([]byte, []byte) int){
 	AggregateOpMinTS: cmpInt64,
 	AggregateOpMaxTS: cmpInt64,
 	AggregateOpCount: cmpCount,
+	AggregateOpMode:  cmpMode,
 }
 
 // return an integer that can be used to sort
@@ -518,7 +526,7 @@ func (a *aggtable) fasteval(delims []vmref) int {
 		panic("aggtable.bc.compiled == nil")
 	}
 
-	if globalOptimizationLevel >= OptimizationLevelAVX512V1 {
+	if a.bc.useAsm() {
 		return evalhashaggbc(&a.bc, delims, a.tree)
 	}
 
@@ -546,6 +554,24 @@ func (b *bytecode) getVRegOffsetAndSize(base, index int) (uint32, uint32) {
 	return lo, hi
 }
 
+// boolkey returns 0 or 1 for a FALSE or TRUE
+// grouping key stored in the given lane
+func (a *aggtable) boolkey(lane int) (int, error) {
+	lo, hi := a.bc.getVRegOffsetAndSize(0, lane)
+	ref := vmref{lo, hi}
+	if hi != 1 || !ref.valid() {
+		errorf("bad boolean key ref {%#x, %d} from bytecode:\n%s\n", lo, hi, a.bc.String())
+		return 0, bcerrCorrupt
+	}
+	switch b := ref.mem()[0]; b {
+	case 0x10, 0x11:
+		return int(b & 1), nil
+	default:
+		errorf("boolean key has ion type byte %#x", b)
+		return 0, bcerrCorrupt
+	}
+}
+
 func (a *aggtable) next() rowConsumer { return nil }
 
 func (a *aggtable) writeRows(delims []vmref, rp *rowParams) error {
@@ -572,8 +598,22 @@ func (a *aggtable) writeRows(delims []vmref, rp *rowParams) error {
 				continue
 			}
 
-			h := hashmem[i] // first 64 bits of 128-bit hash in lane i
+			var h uint64
+			if a.parent.boolkey {
+				// there is no hash; the key is
+				// the boxed boolean itself
+				key, err := a.boolkey(i)
+				if err != nil {
+					return err
+				}
+				h = uint64(0x10 | key)
+			} else {
+				h = hashmem[i] // first 64 bits of 128-bit hash in lane i
+			}
 			off, ok := a.tree.insertSlow(h)
+			if a.parent.boolkey {
+				a.tree.direct[h&1] = off
+			}
 			if !ok {
 				continue
 			}
@@ -701,11 +741,14 @@ func (a *aggtable) Close() error {
 	// than doing a single merge, but it is
 	// faster since we are potentially performing
 	// multiple merges simultaneously
+	var err error
 	for parent.final != nil {
 		tmp := parent.final
 		parent.final = nil
 		parent.lock.Unlock()
-		a.merge(tmp)
+		if e := a.merge(tmp); err == nil {
+			err = e
+		}
 		parent.lock.Lock()
 	}
 
@@ -714,14 +757,14 @@ func (a *aggtable) Close() error {
 		panic("duplicate aggtable.Close()")
 	}
 	parent.lock.Unlock()
-	return nil
+	return err
 }
 
 // merge the right-hand-side table into
 // the left-hand-side table by walking
 // all of the right-hand-side entries
 // and inserting/merging them via the slow path
-func (a *aggtable) merge(r *aggtable) {
+func (a *aggtable) merge(r *aggtable) error {
 	for i := range r.pairs {
 		p := &r.pairs[i]
 		// get value from rhs
@@ -741,6 +784,9 @@ func (a *aggtable) merge(r *aggtable) {
 			a.initentry(a.tree.values[off+8:])
 		}
 
-		mergeAggregatedValues(a.tree.values[off+8:], value, a.aggregateOps)
+		if err := mergeAggregatedValues(a.tree.values[off+8:], value, a.aggregateOps); err != nil {
+			return err
+		}
 	}
+	return nil
 }
@@ -107,6 +107,14 @@ func refFunc(op bcop) any {
 			result := strings.TrimRight(data, whiteSpace)
 			return OffsetZ2(0), LengthZ3(len(result))
 		}
+	case opTrimWsBoth:
+		return func(data Data) (OffsetZ2, LengthZ3) {
+			// TODO: currently only ASCII whitespace chars are supported, not U+0085 (NEL), U+00A0 (NBSP)
+			whiteSpace := string([]byte{'\t', '\n', '\v', '\f', '\r', ' '})
+			left := strings.TrimLeft(data, whiteSpace)
+			result := strings.TrimRight(left, whiteSpace)
+			return OffsetZ2(len(data) - len(left)), LengthZ3(len(result))
+		}
 
 	case opContainsPrefixCs:
 		return func(data Data, needle Needle) (bool, OffsetZ2, LengthZ3) {
@@ -61,7 +61,7 @@ type syminfo struct {
 func evalfindbc(w *bytecode, delims []vmref, stride int)
 
 func evalfind(w *bytecode, delims []vmref, stride int) error {
-	if globalOptimizationLevel >= OptimizationLevelAVX512V1 {
+	if w.useAsm() {
 		evalfindbc(w, delims, stride*vRegSize)
 	} else {
 		evalfindgo(w, delims, stride*vRegSize)
@@ -220,7 +220,7 @@ func (p *projector) bcproject(delims []vmref, dst []byte, out []syminfo) (int, i
 	p.bc.ensureVStackSize(len(p.parent.sel) * int(vRegSize))
 	p.bc.allocStacks()
 
-	if globalOptimizationLevel >= OptimizationLevelAVX512V1 {
+	if p.bc.useAsm() {
 		return evalproject(&p.bc, delims, dst, out)
 	}
 
@@ -272,6 +272,7 @@ func (p *projector) writeRows(delims []vmref, rp *rowParams) error {
 	lc := 0
 
 	p.bc.prepare(rp)
+	p.params.pos = rp.pos
 	for len(delims) > 0 {
 		auxpos := p.bc.auxpos
 		off, rewrote := p.bcproject(delims, p.aw.buf[p.aw.off:], p.outsel)
@@ -292,6 +293,8 @@ func (p *projector) writeRows(delims []vmref, rp *rowParams) error {
 		// for *only* the lanes that were actually projected,
 		// and not necessarily those that were passed to the bytecode
 		p.bc.auxpos = auxpos + rewrote
+		// likewise for the row positions
+		p.bc.random = p.params.pos + uint64(rewrote)
 		if p.dstrc != nil && rewrote > 0 {
 			err := p.dstrc.writeRows(delims[:rewrote], &p.params)
 			if err != nil {
@@ -300,6 +303,7 @@ func (p *projector) writeRows(delims []vmref, rp *rowParams) error {
 		} else {
 			p.aw.off += off
 		}
+		p.params.pos += uint64(rewrote)
 		delims = delims[rewrote:]
 		// if we didn't flush via writeRows(), then flush directly:
 		if p.dstrc == nil {
@@ -24,6 +24,8 @@ import (
 
 	"github.com/SnellerInc/sneller/ion"
 	"github.com/SnellerInc/sneller/ion/zion/zll"
+
+	"github.com/dchest/siphash"
 )
 
 // QuerySink represents a sink for query outputs.
@@ -85,6 +87,36 @@ type rowParams struct {
 	// in symbolize(); the length of each vmref slice
 	// will be the same as the number of rows passed to writeRows()
 	auxbound [][]vmref
+	// pos is the position of the first row passed
+	// to writeRows(); the following rows have the
+	// positions pos+1, pos+2, etc. (see chunkpos)
+	pos uint64
+}
+
+// chunkpos returns the position of the first
+// row in a chunk of data. Positions are derived
+// from the content of the chunk rather than from
+// the order in which chunks arrive, so a row has
+// the same position no matter which thread of
+// execution processes it. Only a sample of the chunk
+// is hashed, since this is done for every chunk.
+func chunkpos(buf []byte) uint64 {
+	const window = 64
+	h := uint64(len(buf))
+	for _, off := range [...]int{0, len(buf) / 2, len(buf) - window} {
+		off = max(off, 0)
+		h = siphash.Hash(h, uint64(off), buf[off:min(off+window, len(buf))])
+	}
+	return h
+}
+
+// derivepos returns the position of the first
+// of the rows produced from the row at pos
+// by a rowConsumer that produces more than one
+// output row per input row, so that the outputs
+// of neighboring input rows don't overlap
+func derivepos(pos uint64) uint64 {
+	return pos * 0x9e3779b97f4a7c15
 }
 
 // RowConsumer represents part of a QuerySink
@@ -198,6 +230,7 @@ func (q *rowSplitter) writeVM(src []byte, delims []vmref) error {
 			if err != nil {
 				return err
 			}
+			q.params.pos += uint64(n)
 		}
 		src = src[nb:]
 	}
@@ -259,6 +292,7 @@ func (q *rowSplitter) writeVMCopy(src []byte, delims []vmref) error {
 		if err != nil {
 			return err
 		}
+		q.params.pos += uint64(nd)
 	}
 	return nil
 }
@@ -395,6 +429,7 @@ func (q *rowSplitter) writeZion(src []byte) (int, error) {
 	if q.pos != nil {
 		*q.pos += q.zstate.blocksize
 	}
+	q.zstate.pos = chunkpos(src)
 	err = q.zout.writeZion(q.zstate)
 	if err != nil {
 		return 0, err
@@ -449,6 +484,7 @@ func (q *rowSplitter) Write(buf []byte) (int, error) {
 	if len(q.delims) < q.delimhint {
 		q.delims = make([]vmref, q.delimhint)
 	}
+	q.params.pos = chunkpos(buf[boff:])
 	var err error
 	if Allocated(buf) {
 		err = q.writeVM(buf[boff:], q.delims)
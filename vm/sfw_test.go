@@ -18,9 +18,12 @@ import (
 	"bufio"
 	"bytes"
 	"io"
+	"maps"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/SnellerInc/sneller/expr"
 	"github.com/SnellerInc/sneller/ion"
 )
 
@@ -129,3 +132,123 @@ func TestRematerializeIssue561(t *testing.T) {
 		t.Errorf("found %d symbol tables; expected 2", stcount)
 	}
 }
+
+type chunkList [][]byte
+
+func (c *chunkList) Write(p []byte) (int, error) {
+	*c = append(*c, slices.Clone(p))
+	return len(p), nil
+}
+
+// TestRandomParallel checks that the values
+// produced by RANDOM(seed) don't depend on the
+// parallelism of the query or on the order in
+// which the input chunks are processed
+func TestRandomParallel(t *testing.T) {
+	const align = 4096
+	var chunks chunkList
+	cn := ion.Chunker{Align: align, W: &chunks}
+	x := cn.Symbols.Intern("x")
+	y := cn.Symbols.Intern("y")
+	for i := 0; i < 20000; i++ {
+		cn.Buffer.BeginStruct(-1)
+		cn.Buffer.BeginField(x)
+		cn.Buffer.WriteInt(int64(i))
+		cn.Buffer.BeginField(y)
+		cn.Buffer.WriteString("the same for every row")
+		cn.Buffer.EndStruct()
+		if err := cn.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cn.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) < 8 {
+		t.Fatalf("only %d chunks", len(chunks))
+	}
+	var forward, reverse []byte
+	for i := range chunks {
+		forward = append(forward, chunks[i]...)
+		reverse = append(reverse, chunks[len(chunks)-1-i]...)
+	}
+
+	random := expr.Call(expr.Random, expr.Integer(7))
+	// project x and r = RANDOM(7) for the rows where
+	// RANDOM(7) < 0.5 and return r for each x
+	// (the filter and the projection are different
+	// clauses, so r is not necessarily < 0.5)
+	run := func(t *testing.T, buf []byte, parallel int) map[int64]float64 {
+		var out QueryBuffer
+		proj, err := NewProjection(Selection{
+			expr.Bind(expr.Ident("x"), "x"),
+			expr.Bind(random, "r"),
+		}, &out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filt, err := NewFilter(expr.Compare(expr.Less, random, expr.Float(0.5)), proj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := CopyRows(filt, BufferTable(buf, align), parallel); err != nil {
+			t.Fatal(err)
+		}
+		if err := filt.Close(); err != nil {
+			t.Fatal(err)
+		}
+		ret := make(map[int64]float64)
+		var st ion.Symtab
+		rows := out.Bytes()
+		for len(rows) > 0 {
+			var d ion.Datum
+			d, rows, err = ion.ReadDatum(&st, rows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Type() != ion.StructType {
+				continue // symbol table or padding
+			}
+			s, err := d.Struct()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var n int64
+			var r float64
+			s.Each(func(f ion.Field) error {
+				switch f.Label {
+				case "x":
+					n, _ = f.Int()
+				case "r":
+					r, _ = f.Float()
+				}
+				return nil
+			})
+			if _, ok := ret[n]; ok {
+				t.Fatalf("duplicate row %d", n)
+			}
+			ret[n] = r
+		}
+		return ret
+	}
+	check := func(t *testing.T) {
+		want := run(t, forward, 1)
+		if len(want) < 9000 || len(want) > 11000 {
+			t.Fatalf("%d of 20000 rows selected", len(want))
+		}
+		for _, got := range []map[int64]float64{
+			run(t, forward, 8),
+			run(t, reverse, 1),
+			run(t, reverse, 8),
+		} {
+			if !maps.Equal(got, want) {
+				t.Fatalf("got %d rows that differ from the %d sequential ones", len(got), len(want))
+			}
+		}
+	}
+	level := globalOptimizationLevel
+	defer SetOptimizationLevel(level)
+	t.Run("default", check)
+	SetOptimizationLevel(OptimizationLevelNone)
+	t.Run("portable", check)
+}
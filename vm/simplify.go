@@ -17,6 +17,11 @@ package vm
 //go:generate go run -tags genrewrite genrewrite_main.go -o simplify1.go simplify.rules
 //go:generate gofmt -w simplify1.go
 
+import (
+	"encoding/binary"
+	"reflect"
+)
+
 func dependsOn(a, b *value) bool {
 	for _, arg := range a.args {
 		if arg == b || dependsOn(arg, b) {
@@ -26,6 +31,36 @@ func dependsOn(a, b *value) bool {
 	return false
 }
 
+// selectivity is a rough static estimate
+// of the fraction of active lanes for which
+// the predicate v yields TRUE
+func selectivity(v *value) float64 {
+	switch v.op {
+	case skfalse:
+		return 0
+	case sinit:
+		return 1
+	case scmpeqstr, scmpeqf, scmpeqimmf, scmpeqi, scmpeqimmi, scmpeqts, scmpeqv,
+		sStrCmpEqCs, sStrCmpEqCi, sStrCmpEqUTF8Ci,
+		sEqPatternCs, sEqPatternCi, sEqPatternUTF8Ci, sequalconst:
+		return 0.1
+	}
+	return 0.5
+}
+
+// evalFirst returns whether evaluating a and
+// then evaluating b only on the lanes that
+// passed a is expected to be cheaper than
+// the opposite order
+func evalFirst(a, b *value) bool {
+	// each step in the cost table
+	// roughly doubles the cost of an op
+	weight := func(v *value) float64 {
+		return float64(int(1) << (ssainfo[v.op].cost - costNop))
+	}
+	return weight(a)+selectivity(a)*weight(b) < weight(b)+selectivity(b)*weight(a)
+}
+
 // given a, b, produce (a AND b, true) or (nil, false)
 // by setting the mask of one of the ops to the result
 // of the other op
@@ -33,11 +68,12 @@ func conjoin(p *prog, a, b *value) (*value, bool) {
 	if a == b {
 		return nil, false
 	}
-	// if b is more expensive than a,
 	// *try* to perform short-circuiting by
-	// evaluating the less-expensive op first
-	// (the opposite is still profitable, but less so)
-	if ssainfo[b.op].cost > ssainfo[a.op].cost {
+	// evaluating the op that is expected to
+	// be cheaper (given its cost and selectivity)
+	// first; the opposite is still profitable,
+	// but less so
+	if evalFirst(a, b) {
 		a, b = b, a
 	}
 	try := func(p *prog, a, b *value) (*value, bool) {
@@ -56,9 +92,30 @@ func conjoin(p *prog, a, b *value) (*value, bool) {
 	// try both orderings:
 	v, ok := try(p, a, b)
 	if !ok {
-		return try(p, b, a)
+		v, ok = try(p, b, a)
+	}
+	if ok {
+		return v, true
 	}
-	return v, true
+	// the masks are different, but an expensive
+	// op can still be evaluated under
+	// (and.k mask b) rather than mask
+	// (note that this is only correct because
+	// a is conjunctive: it can only yield TRUE
+	// for lanes that are set in its mask)
+	if ssainfo[a.op].cost < costHeavy || ssainfo[a.op].disjunctive ||
+		!evalFirst(b, a) || dependsOn(b, a) {
+		return nil, false
+	}
+	mask := a.maskarg()
+	if mask == nil || mask.op == skfalse || mask == b {
+		return nil, false
+	}
+	k := b
+	if mask.op != sinit {
+		k = p.setssa(p.val(), sand, nil, mask, b)
+	}
+	return p.dup(a).setmask(k), true
 }
 
 func isfalse(v *value) (*value, bool) {
@@ -98,6 +155,55 @@ func eliminateMovs(p *prog, v *value) bool {
 	return changed
 }
 
+// cseTable finds values that compute the same result;
+// values are hash-consed as they are constructed,
+// but rewriting can produce duplicates that
+// the construction-time table never sees
+type cseTable struct {
+	key    []byte
+	values map[string][]*value
+}
+
+func (c *cseTable) reset() {
+	if c.values == nil {
+		c.values = make(map[string][]*value)
+	} else {
+		clear(c.values)
+	}
+}
+
+// lookup returns an equivalent value previously
+// passed to lookup, or records v and returns v
+// if there isn't one
+//
+// (two values are equivalent if they have the same
+// op, arguments (including the mask), immediate
+// and not-missing-ness)
+func (c *cseTable) lookup(v *value) *value {
+	info := &ssainfo[v.op]
+	if v.op == sinvalid || len(v.args) == 0 ||
+		info.returnOp || info.rettype&stMem != 0 {
+		return v
+	}
+	c.key = binary.AppendUvarint(c.key[:0], uint64(v.op))
+	nm := -1
+	if v.notMissing != nil {
+		nm = v.notMissing.id
+	}
+	c.key = binary.AppendVarint(c.key, int64(nm))
+	for _, arg := range v.args {
+		c.key = binary.AppendUvarint(c.key, uint64(arg.id))
+	}
+	bucket := c.values[string(c.key)]
+	for _, w := range bucket {
+		if w == v || reflect.DeepEqual(w.imm, v.imm) {
+			return w
+		}
+	}
+	c.values[string(c.key)] = append(bucket, v)
+	return v
+}
+
 func rewrite(p *prog, v *value) (*value, bool) {
 	info := &ssainfo[v.op]
 
@@ -134,9 +240,14 @@ func rewrite(p *prog, v *value) (*value, bool) {
 // a fixed point
 //
 // see simplify.rules
+//
+// each pass also eliminates common subexpressions
+// that appear as a consequence of rewriting
 func (p *prog) simplify(pi *proginfo) {
 	var rewrote []*value
+	var cse cseTable
 	for {
+		cse.reset()
 		changed := false
 		values := p.values
 		rewrote = shrink(rewrote, len(values))
@@ -162,6 +273,15 @@ func (p *prog) simplify(pi *proginfo) {
 					rewrote[v.id] = out
 				}
 			}
+			if out != v {
+				// either a value we've already
+				// visited or one we don't visit
+				continue
+			}
+			if same := cse.lookup(v); same != v {
+				changed = true
+				rewrote[v.id] = same
+			}
 		}
 		if !changed {
 			return
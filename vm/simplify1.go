@@ -17,22 +17,14 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 		}
 	case 8: /* and.k */
 		if len(v.args) == 2 {
-			// (and.k a b), "p.mask(b) == a && !ssainfo[b.op].disjunctive" -> b
-			if a := v.args[0]; true {
-				if b := v.args[1]; true {
-					if p.mask(b) == a && !ssainfo[b.op].disjunctive {
-						return b, true
-					}
-				}
+			// (and.k f:(false) _) -> f
+			if f := v.args[0]; f.op == 7 {
+				return f, true
 			}
 			// (and.k _ f:(false)) -> f
 			if f := v.args[1]; f.op == 7 {
 				return f, true
 			}
-			// (and.k f:(false) _) -> f
-			if f := v.args[0]; f.op == 7 {
-				return f, true
-			}
 			// (and.k x x) -> x
 			if x := v.args[0]; true {
 				if x == v.args[1] {
@@ -47,10 +39,12 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 					}
 				}
 			}
-			// (and.k x (init)) -> x
-			if x := v.args[0]; true {
-				if _tmp12 := v.args[1]; _tmp12.op == 1 {
-					return x, true
+			// (and.k a b), "p.mask(b) == a && !ssainfo[b.op].disjunctive" -> b
+			if a := v.args[0]; true {
+				if b := v.args[1]; true {
+					if p.mask(b) == a && !ssainfo[b.op].disjunctive {
+						return b, true
+					}
 				}
 			}
 			// (and.k a b), "res, ok := conjoin(p, a, b); ok" -> res
@@ -62,11 +56,17 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 			// (and.k (init) x) -> x
-			if _tmp13 := v.args[0]; _tmp13.op == 1 {
+			if _tmp14 := v.args[0]; _tmp14.op == 1 {
 				if x := v.args[1]; true {
 					return x, true
 				}
 			}
+			// (and.k x (init)) -> x
+			if x := v.args[0]; true {
+				if _tmp15 := v.args[1]; _tmp15.op == 1 {
+					return x, true
+				}
+			}
 		}
 	case 9: /* andn.k */
 		if len(v.args) == 2 {
@@ -76,49 +76,49 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 					return /* clobber v */ p.setssa(v, 7, nil), true
 				}
 			}
-			// (andn.k _ f:(false)) -> f
-			if f := v.args[1]; f.op == 7 {
-				return f, true
+			// (andn.k (false) x) -> x
+			if _tmp16 := v.args[0]; _tmp16.op == 7 {
+				if x := v.args[1]; true {
+					return x, true
+				}
 			}
 			// (andn.k t:(init) _) -> (false)
 			if t := v.args[0]; t.op == 1 {
 				return /* clobber v */ p.setssa(v, 7, nil), true
 			}
-			// (andn.k (false) x) -> x
-			if _tmp14 := v.args[0]; _tmp14.op == 7 {
-				if x := v.args[1]; true {
-					return x, true
-				}
+			// (andn.k _ f:(false)) -> f
+			if f := v.args[1]; f.op == 7 {
+				return f, true
 			}
 		}
 	case 10: /* or.k */
 		if len(v.args) == 2 {
+			// (or.k t:(init) _) -> t
+			if t := v.args[0]; t.op == 1 {
+				return t, true
+			}
 			// (or.k x x) -> x
 			if x := v.args[0]; true {
 				if x == v.args[1] {
 					return x, true
 				}
 			}
-			// (or.k _ t:(init)) -> t
-			if t := v.args[1]; t.op == 1 {
-				return t, true
-			}
 			// (or.k x (false)) -> x
 			if x := v.args[0]; true {
-				if _tmp15 := v.args[1]; _tmp15.op == 7 {
+				if _tmp17 := v.args[1]; _tmp17.op == 7 {
 					return x, true
 				}
 			}
-			// (or.k t:(init) _) -> t
-			if t := v.args[0]; t.op == 1 {
-				return t, true
-			}
 			// (or.k (false) x) -> x
-			if _tmp16 := v.args[0]; _tmp16.op == 7 {
+			if _tmp18 := v.args[0]; _tmp18.op == 7 {
 				if x := v.args[1]; true {
 					return x, true
 				}
 			}
+			// (or.k _ t:(init)) -> t
+			if t := v.args[1]; t.op == 1 {
+				return t, true
+			}
 		}
 	case 11: /* xor.k */
 		if len(v.args) == 2 {
@@ -142,12 +142,12 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 			}
 			// (xor.k x (false)) -> x
 			if x := v.args[0]; true {
-				if _tmp17 := v.args[1]; _tmp17.op == 7 {
+				if _tmp19 := v.args[1]; _tmp19.op == 7 {
 					return x, true
 				}
 			}
 			// (xor.k (false) x) -> x
-			if _tmp18 := v.args[0]; _tmp18.op == 7 {
+			if _tmp20 := v.args[0]; _tmp20.op == 7 {
 				if x := v.args[1]; true {
 					return x, true
 				}
@@ -155,34 +155,34 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 		}
 	case 12: /* xnor.k */
 		if len(v.args) == 2 {
-			// (xnor.k x x) -> (init)
-			if x := v.args[0]; true {
-				if x == v.args[1] {
-					return p.values[0], true
-				}
-			}
 			// (xnor.k (init) f) -> f
-			if _tmp19 := v.args[0]; _tmp19.op == 1 {
+			if _tmp21 := v.args[0]; _tmp21.op == 1 {
 				if f := v.args[1]; true {
 					return f, true
 				}
 			}
+			// (xnor.k f (init)) -> f
+			if f := v.args[0]; true {
+				if _tmp22 := v.args[1]; _tmp22.op == 1 {
+					return f, true
+				}
+			}
 			// (xnor.k f (false)) -> (andn.k f (init))
 			if f := v.args[0]; true {
-				if _tmp20 := v.args[1]; _tmp20.op == 7 {
+				if _tmp23 := v.args[1]; _tmp23.op == 7 {
 					return /* clobber v */ p.setssa(v, 9, nil, f, p.values[0]), true
 				}
 			}
 			// (xnor.k (false) f) -> (andn.k f (init))
-			if _tmp21 := v.args[0]; _tmp21.op == 7 {
+			if _tmp24 := v.args[0]; _tmp24.op == 7 {
 				if f := v.args[1]; true {
 					return /* clobber v */ p.setssa(v, 9, nil, f, p.values[0]), true
 				}
 			}
-			// (xnor.k f (init)) -> f
-			if f := v.args[0]; true {
-				if _tmp22 := v.args[1]; _tmp22.op == 1 {
-					return f, true
+			// (xnor.k x x) -> (init)
+			if x := v.args[0]; true {
+				if x == v.args[1] {
+					return p.values[0], true
 				}
 			}
 		}
@@ -208,6 +208,25 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
+	case 48: /* cmpgt.i64@imm */
+		if len(v.args) == 2 {
+			// (cmpgt.i64@imm pos:(arrayposition l x k) m 0), "p.mask(pos) == m" -> (arraycontains l x k)
+			if pos := v.args[0]; pos.op == 332 {
+				if m := v.args[1]; true {
+					if toi64(v.imm) == 0 {
+						if l := pos.args[0]; true {
+							if x := pos.args[1]; true {
+								if k := pos.args[2]; true {
+									if p.mask(pos) == m {
+										return /* clobber v */ p.setssa(v, 333, nil, l, x, k), true
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
 	case 49: /* cmple.f64 */
 		if len(v.args) == 3 {
 			// (cmple.f64 x x k) -> k
@@ -252,32 +271,51 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 72: /* cvt.k@i64 */
+	case 56: /* cmpge.i64@imm */
 		if len(v.args) == 2 {
-			// (cvt.k@i64 (init) _) -> (broadcast.i 1)
-			if _tmp23 := v.args[0]; _tmp23.op == 1 {
-				return /* clobber v */ p.setssa(v, 148, 1), true
+			// (cmpge.i64@imm pos:(arrayposition l x k) m 1), "p.mask(pos) == m" -> (arraycontains l x k)
+			if pos := v.args[0]; pos.op == 332 {
+				if m := v.args[1]; true {
+					if toi64(v.imm) == 1 {
+						if l := pos.args[0]; true {
+							if x := pos.args[1]; true {
+								if k := pos.args[2]; true {
+									if p.mask(pos) == m {
+										return /* clobber v */ p.setssa(v, 333, nil, l, x, k), true
+									}
+								}
+							}
+						}
+					}
+				}
 			}
+		}
+	case 73: /* cvt.k@i64 */
+		if len(v.args) == 2 {
 			// (cvt.k@i64 (false) _) -> (broadcast.i 0)
-			if _tmp24 := v.args[0]; _tmp24.op == 7 {
-				return /* clobber v */ p.setssa(v, 148, 0), true
+			if _tmp25 := v.args[0]; _tmp25.op == 7 {
+				return /* clobber v */ p.setssa(v, 154, 0), true
+			}
+			// (cvt.k@i64 (init) _) -> (broadcast.i 1)
+			if _tmp26 := v.args[0]; _tmp26.op == 1 {
+				return /* clobber v */ p.setssa(v, 154, 1), true
 			}
 		}
-	case 73: /* cvt.k@f64 */
+	case 74: /* cvt.k@f64 */
 		if len(v.args) == 2 {
-			// (cvt.k@f64 (init) _) -> (broadcast.f 1)
-			if _tmp25 := v.args[0]; _tmp25.op == 1 {
-				return /* clobber v */ p.setssa(v, 147, 1), true
-			}
 			// (cvt.k@f64 (false) _) -> (broadcast.f 0)
-			if _tmp26 := v.args[0]; _tmp26.op == 7 {
-				return /* clobber v */ p.setssa(v, 147, 0), true
+			if _tmp27 := v.args[0]; _tmp27.op == 7 {
+				return /* clobber v */ p.setssa(v, 153, 0), true
+			}
+			// (cvt.k@f64 (init) _) -> (broadcast.f 1)
+			if _tmp28 := v.args[0]; _tmp28.op == 1 {
+				return /* clobber v */ p.setssa(v, 153, 1), true
 			}
 		}
-	case 74: /* cvt.i64@k */
+	case 75: /* cvt.i64@k */
 		if len(v.args) == 2 {
 			// (cvt.i64@k _tmp0:(broadcast.i imm) k) -> (and.k "p.choose(imm != 0)" k)
-			if _tmp0 := v.args[0]; _tmp0.op == 148 {
+			if _tmp0 := v.args[0]; _tmp0.op == 154 {
 				if k := v.args[1]; true {
 					if imm := toi64(_tmp0.imm); true {
 						return /* clobber v */ p.setssa(v, 8, nil, p.choose(imm != 0), k), true
@@ -285,7 +323,33 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 135: /* store.v */
+	case 95: /* trim_ws_left */
+		if len(v.args) == 2 {
+			// (trim_ws_left _tmp1:(trim_ws_right s k) k) -> (trim_ws_both s k)
+			if _tmp1 := v.args[0]; _tmp1.op == 96 {
+				if k := v.args[1]; true {
+					if s := _tmp1.args[0]; true {
+						if k == _tmp1.args[1] {
+							return /* clobber v */ p.setssa(v, 97, nil, s, k), true
+						}
+					}
+				}
+			}
+		}
+	case 96: /* trim_ws_right */
+		if len(v.args) == 2 {
+			// (trim_ws_right _tmp2:(trim_ws_left s k) k) -> (trim_ws_both s k)
+			if _tmp2 := v.args[0]; _tmp2.op == 95 {
+				if k := v.args[1]; true {
+					if s := _tmp2.args[0]; true {
+						if k == _tmp2.args[1] {
+							return /* clobber v */ p.setssa(v, 97, nil, s, k), true
+						}
+					}
+				}
+			}
+		}
+	case 141: /* store.v */
 		if len(v.args) == 3 {
 			// (store.v mem ov k:(false) slot), "ov != k" -> (store.v mem k k slot)
 			if mem := v.args[0]; true {
@@ -293,14 +357,14 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 					if k := v.args[2]; k.op == 7 {
 						if slot := v.imm; true {
 							if ov != k {
-								return /* clobber v */ p.setssa(v, 135, slot, mem, k, k), true
+								return /* clobber v */ p.setssa(v, 141, slot, mem, k, k), true
 							}
 						}
 					}
 				}
 			}
 		}
-	case 142: /* make.vk */
+	case 148: /* make.vk */
 		if len(v.args) == 2 {
 			// (make.vk val k), "p.mask(val) == k" -> val
 			if val := v.args[0]; true {
@@ -311,7 +375,7 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 143: /* floatk */
+	case 149: /* floatk */
 		if len(v.args) == 2 {
 			// (floatk f k), "p.mask(f) == k" -> f
 			if f := v.args[0]; true {
@@ -322,62 +386,62 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 144: /* notmissing */
+	case 150: /* notmissing */
 		if len(v.args) == 1 {
 			// (notmissing k) -> k
 			if k := v.args[0]; true {
 				return k, true
 			}
 		}
-	case 145: /* blend.v */
+	case 151: /* blend.v */
 		if len(v.args) == 4 {
-			// (blend.v x k _ (false)) -> (make.vk x k)
-			if x := v.args[0]; true {
-				if k := v.args[1]; true {
-					if _tmp27 := v.args[3]; _tmp27.op == 7 {
-						return /* clobber v */ p.setssa(v, 142, nil, x, k), true
-					}
-				}
-			}
 			// (blend.v _ (false) y k) -> (make.vk y k)
-			if _tmp28 := v.args[1]; _tmp28.op == 7 {
+			if _tmp29 := v.args[1]; _tmp29.op == 7 {
 				if y := v.args[2]; true {
 					if k := v.args[3]; true {
-						return /* clobber v */ p.setssa(v, 142, nil, y, k), true
+						return /* clobber v */ p.setssa(v, 148, nil, y, k), true
 					}
 				}
 			}
 			// (blend.v _ _ y (init)) -> (make.vk y (init))
 			if y := v.args[2]; true {
-				if _tmp29 := v.args[3]; _tmp29.op == 1 {
-					return /* clobber v */ p.setssa(v, 142, nil, y, p.values[0]), true
+				if _tmp30 := v.args[3]; _tmp30.op == 1 {
+					return /* clobber v */ p.setssa(v, 148, nil, y, p.values[0]), true
+				}
+			}
+			// (blend.v x k _ (false)) -> (make.vk x k)
+			if x := v.args[0]; true {
+				if k := v.args[1]; true {
+					if _tmp31 := v.args[3]; _tmp31.op == 7 {
+						return /* clobber v */ p.setssa(v, 148, nil, x, k), true
+					}
 				}
 			}
 		}
-	case 181: /* add.f */
+	case 187: /* add.f */
 		if len(v.args) == 3 {
-			// (add.f _tmp1:(broadcast.f imm) f k) -> (add.imm.f f k imm)
-			if _tmp1 := v.args[0]; _tmp1.op == 147 {
-				if f := v.args[1]; true {
+			// (add.f f _tmp3:(broadcast.f imm) k) -> (add.imm.f f k imm)
+			if f := v.args[0]; true {
+				if _tmp3 := v.args[1]; _tmp3.op == 153 {
 					if k := v.args[2]; true {
-						if imm := tof64(_tmp1.imm); true {
-							return /* clobber v */ p.setssa(v, 183, imm, f, k), true
+						if imm := tof64(_tmp3.imm); true {
+							return /* clobber v */ p.setssa(v, 189, imm, f, k), true
 						}
 					}
 				}
 			}
-			// (add.f f _tmp2:(broadcast.f imm) k) -> (add.imm.f f k imm)
-			if f := v.args[0]; true {
-				if _tmp2 := v.args[1]; _tmp2.op == 147 {
+			// (add.f _tmp4:(broadcast.f imm) f k) -> (add.imm.f f k imm)
+			if _tmp4 := v.args[0]; _tmp4.op == 153 {
+				if f := v.args[1]; true {
 					if k := v.args[2]; true {
-						if imm := tof64(_tmp2.imm); true {
-							return /* clobber v */ p.setssa(v, 183, imm, f, k), true
+						if imm := tof64(_tmp4.imm); true {
+							return /* clobber v */ p.setssa(v, 189, imm, f, k), true
 						}
 					}
 				}
 			}
 		}
-	case 183: /* add.imm.f */
+	case 189: /* add.imm.f */
 		if len(v.args) == 2 {
 			// (add.imm.f f _ 0) -> f
 			if f := v.args[0]; true {
@@ -386,7 +450,7 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 184: /* add.imm.i */
+	case 190: /* add.imm.i */
 		if len(v.args) == 2 {
 			// (add.imm.i i _ 0) -> i
 			if i := v.args[0]; true {
@@ -395,30 +459,30 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 185: /* sub.f */
+	case 191: /* sub.f */
 		if len(v.args) == 3 {
-			// (sub.f _tmp3:(broadcast.f imm) f k) -> (rsub.imm.f f k imm)
-			if _tmp3 := v.args[0]; _tmp3.op == 147 {
+			// (sub.f _tmp5:(broadcast.f imm) f k) -> (rsub.imm.f f k imm)
+			if _tmp5 := v.args[0]; _tmp5.op == 153 {
 				if f := v.args[1]; true {
 					if k := v.args[2]; true {
-						if imm := tof64(_tmp3.imm); true {
-							return /* clobber v */ p.setssa(v, 191, imm, f, k), true
+						if imm := tof64(_tmp5.imm); true {
+							return /* clobber v */ p.setssa(v, 197, imm, f, k), true
 						}
 					}
 				}
 			}
-			// (sub.f f _tmp4:(broadcast.f imm) k) -> (sub.imm.f f k imm)
+			// (sub.f f _tmp6:(broadcast.f imm) k) -> (sub.imm.f f k imm)
 			if f := v.args[0]; true {
-				if _tmp4 := v.args[1]; _tmp4.op == 147 {
+				if _tmp6 := v.args[1]; _tmp6.op == 153 {
 					if k := v.args[2]; true {
-						if imm := tof64(_tmp4.imm); true {
-							return /* clobber v */ p.setssa(v, 187, imm, f, k), true
+						if imm := tof64(_tmp6.imm); true {
+							return /* clobber v */ p.setssa(v, 193, imm, f, k), true
 						}
 					}
 				}
 			}
 		}
-	case 187: /* sub.imm.f */
+	case 193: /* sub.imm.f */
 		if len(v.args) == 2 {
 			// (sub.imm.f f _ 0) -> f
 			if f := v.args[0]; true {
@@ -427,7 +491,7 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 188: /* sub.imm.i */
+	case 194: /* sub.imm.i */
 		if len(v.args) == 2 {
 			// (sub.imm.i i _ 0) -> i
 			if i := v.args[0]; true {
@@ -436,52 +500,52 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 191: /* rsub.imm.f */
+	case 197: /* rsub.imm.f */
 		if len(v.args) == 2 {
 			// (rsub.imm.f f k 0) -> (neg.f f k)
 			if f := v.args[0]; true {
 				if k := v.args[1]; true {
 					if tof64(v.imm) == 0 {
-						return /* clobber v */ p.setssa(v, 151, nil, f, k), true
+						return /* clobber v */ p.setssa(v, 157, nil, f, k), true
 					}
 				}
 			}
 		}
-	case 192: /* rsub.imm.i */
+	case 198: /* rsub.imm.i */
 		if len(v.args) == 2 {
 			// (rsub.imm.i i k 0) -> (neg.i i k)
 			if i := v.args[0]; true {
 				if k := v.args[1]; true {
 					if toi64(v.imm) == 0 {
-						return /* clobber v */ p.setssa(v, 152, nil, i, k), true
+						return /* clobber v */ p.setssa(v, 158, nil, i, k), true
 					}
 				}
 			}
 		}
-	case 193: /* mul.f */
+	case 199: /* mul.f */
 		if len(v.args) == 3 {
-			// (mul.f f _tmp5:(broadcast.f imm) k) -> (mul.imm.f f k imm)
+			// (mul.f f _tmp7:(broadcast.f imm) k) -> (mul.imm.f f k imm)
 			if f := v.args[0]; true {
-				if _tmp5 := v.args[1]; _tmp5.op == 147 {
+				if _tmp7 := v.args[1]; _tmp7.op == 153 {
 					if k := v.args[2]; true {
-						if imm := tof64(_tmp5.imm); true {
-							return /* clobber v */ p.setssa(v, 195, imm, f, k), true
+						if imm := tof64(_tmp7.imm); true {
+							return /* clobber v */ p.setssa(v, 201, imm, f, k), true
 						}
 					}
 				}
 			}
-			// (mul.f _tmp6:(broadcast.f imm) f k) -> (mul.imm.f f k imm)
-			if _tmp6 := v.args[0]; _tmp6.op == 147 {
+			// (mul.f _tmp8:(broadcast.f imm) f k) -> (mul.imm.f f k imm)
+			if _tmp8 := v.args[0]; _tmp8.op == 153 {
 				if f := v.args[1]; true {
 					if k := v.args[2]; true {
-						if imm := tof64(_tmp6.imm); true {
-							return /* clobber v */ p.setssa(v, 195, imm, f, k), true
+						if imm := tof64(_tmp8.imm); true {
+							return /* clobber v */ p.setssa(v, 201, imm, f, k), true
 						}
 					}
 				}
 			}
 		}
-	case 195: /* mul.imm.f */
+	case 201: /* mul.imm.f */
 		if len(v.args) == 2 {
 			// (mul.imm.f f _ 1) -> f
 			if f := v.args[0]; true {
@@ -490,7 +554,7 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 196: /* mul.imm.i */
+	case 202: /* mul.imm.i */
 		if len(v.args) == 2 {
 			// (mul.imm.i i _ 1) -> i
 			if i := v.args[0]; true {
@@ -499,30 +563,30 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 197: /* div.f */
+	case 203: /* div.f */
 		if len(v.args) == 3 {
-			// (div.f f _tmp7:(broadcast.f imm) k) -> (div.imm.f f k imm)
+			// (div.f f _tmp9:(broadcast.f imm) k) -> (div.imm.f f k imm)
 			if f := v.args[0]; true {
-				if _tmp7 := v.args[1]; _tmp7.op == 147 {
+				if _tmp9 := v.args[1]; _tmp9.op == 153 {
 					if k := v.args[2]; true {
-						if imm := tof64(_tmp7.imm); true {
-							return /* clobber v */ p.setssa(v, 199, imm, f, k), true
+						if imm := tof64(_tmp9.imm); true {
+							return /* clobber v */ p.setssa(v, 205, imm, f, k), true
 						}
 					}
 				}
 			}
-			// (div.f _tmp8:(broadcast.f imm) f k) -> (rdiv.imm.f f k imm)
-			if _tmp8 := v.args[0]; _tmp8.op == 147 {
+			// (div.f _tmp10:(broadcast.f imm) f k) -> (rdiv.imm.f f k imm)
+			if _tmp10 := v.args[0]; _tmp10.op == 153 {
 				if f := v.args[1]; true {
 					if k := v.args[2]; true {
-						if imm := tof64(_tmp8.imm); true {
-							return /* clobber v */ p.setssa(v, 201, imm, f, k), true
+						if imm := tof64(_tmp10.imm); true {
+							return /* clobber v */ p.setssa(v, 207, imm, f, k), true
 						}
 					}
 				}
 			}
 		}
-	case 226: /* or.imm.i */
+	case 232: /* or.imm.i */
 		if len(v.args) == 2 {
 			// (or.imm.i i _ 0) -> i
 			if i := v.args[0]; true {
@@ -531,7 +595,7 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 230: /* sll.imm.i */
+	case 236: /* sll.imm.i */
 		if len(v.args) == 2 {
 			// (sll.imm.i i _ 0) -> i
 			if i := v.args[0]; true {
@@ -540,7 +604,7 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 232: /* sra.imm.i */
+	case 238: /* sra.imm.i */
 		if len(v.args) == 2 {
 			// (sra.imm.i i _ 0) -> i
 			if i := v.args[0]; true {
@@ -549,7 +613,7 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 234: /* srl.imm.i */
+	case 240: /* srl.imm.i */
 		if len(v.args) == 2 {
 			// (srl.imm.i i _ 0) -> i
 			if i := v.args[0]; true {
@@ -558,301 +622,301 @@ func rewrite1(p *prog, v *value) (*value, bool) {
 				}
 			}
 		}
-	case 242: /* aggand.k */
+	case 249: /* aggand.k */
 		if len(v.args) == 3 {
 			// (aggand.k mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp30 := v.args[2]; _tmp30.op == 7 {
+				if _tmp32 := v.args[2]; _tmp32.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 243: /* aggor.k */
+	case 250: /* aggor.k */
 		if len(v.args) == 3 {
 			// (aggor.k mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp31 := v.args[2]; _tmp31.op == 7 {
+				if _tmp33 := v.args[2]; _tmp33.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 244: /* aggsum.f */
+	case 251: /* aggsum.f */
 		if len(v.args) == 3 {
 			// (aggsum.f mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp32 := v.args[2]; _tmp32.op == 7 {
+				if _tmp34 := v.args[2]; _tmp34.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 245: /* aggsum.i */
+	case 252: /* aggsum.i */
 		if len(v.args) == 3 {
 			// (aggsum.i mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp33 := v.args[2]; _tmp33.op == 7 {
+				if _tmp35 := v.args[2]; _tmp35.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 248: /* aggmin.f */
+	case 255: /* aggmin.f */
 		if len(v.args) == 3 {
 			// (aggmin.f mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp34 := v.args[2]; _tmp34.op == 7 {
+				if _tmp36 := v.args[2]; _tmp36.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 249: /* aggmin.i */
+	case 256: /* aggmin.i */
 		if len(v.args) == 3 {
 			// (aggmin.i mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp35 := v.args[2]; _tmp35.op == 7 {
+				if _tmp37 := v.args[2]; _tmp37.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 250: /* aggmax.f */
+	case 257: /* aggmax.f */
 		if len(v.args) == 3 {
 			// (aggmax.f mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp36 := v.args[2]; _tmp36.op == 7 {
+				if _tmp38 := v.args[2]; _tmp38.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 251: /* aggmax.i */
+	case 258: /* aggmax.i */
 		if len(v.args) == 3 {
 			// (aggmax.i mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp37 := v.args[2]; _tmp37.op == 7 {
+				if _tmp39 := v.args[2]; _tmp39.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 252: /* aggmin.ts */
+	case 259: /* aggmin.ts */
 		if len(v.args) == 3 {
 			// (aggmin.ts mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp38 := v.args[2]; _tmp38.op == 7 {
+				if _tmp40 := v.args[2]; _tmp40.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 253: /* aggmax.ts */
+	case 260: /* aggmax.ts */
 		if len(v.args) == 3 {
 			// (aggmax.ts mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp39 := v.args[2]; _tmp39.op == 7 {
+				if _tmp41 := v.args[2]; _tmp41.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 254: /* aggand.i */
+	case 261: /* aggand.i */
 		if len(v.args) == 3 {
 			// (aggand.i mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp40 := v.args[2]; _tmp40.op == 7 {
+				if _tmp42 := v.args[2]; _tmp42.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 255: /* aggor.i */
+	case 262: /* aggor.i */
 		if len(v.args) == 3 {
 			// (aggor.i mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp41 := v.args[2]; _tmp41.op == 7 {
+				if _tmp43 := v.args[2]; _tmp43.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 256: /* aggxor.i */
+	case 263: /* aggxor.i */
 		if len(v.args) == 3 {
 			// (aggxor.i mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp42 := v.args[2]; _tmp42.op == 7 {
+				if _tmp44 := v.args[2]; _tmp44.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 257: /* aggcount */
+	case 264: /* aggcount */
 		if len(v.args) == 2 {
 			// (aggcount mem (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp43 := v.args[1]; _tmp43.op == 7 {
+				if _tmp45 := v.args[1]; _tmp45.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 260: /* aggslotand.k */
+	case 268: /* aggslotand.k */
 		if len(v.args) == 4 {
 			// (aggslotand.k mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp44 := v.args[3]; _tmp44.op == 7 {
+				if _tmp46 := v.args[3]; _tmp46.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 261: /* aggslotor.k */
+	case 269: /* aggslotor.k */
 		if len(v.args) == 4 {
 			// (aggslotor.k mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp45 := v.args[3]; _tmp45.op == 7 {
+				if _tmp47 := v.args[3]; _tmp47.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 262: /* aggslotsum.f */
+	case 270: /* aggslotsum.f */
 		if len(v.args) == 4 {
 			// (aggslotsum.f mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp46 := v.args[3]; _tmp46.op == 7 {
+				if _tmp48 := v.args[3]; _tmp48.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 263: /* aggslotsum.i */
+	case 271: /* aggslotsum.i */
 		if len(v.args) == 4 {
 			// (aggslotsum.i mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp47 := v.args[3]; _tmp47.op == 7 {
+				if _tmp49 := v.args[3]; _tmp49.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 266: /* aggslotmin.f */
+	case 274: /* aggslotmin.f */
 		if len(v.args) == 4 {
 			// (aggslotmin.f mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp48 := v.args[3]; _tmp48.op == 7 {
+				if _tmp50 := v.args[3]; _tmp50.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 267: /* aggslotmin.i */
+	case 275: /* aggslotmin.i */
 		if len(v.args) == 4 {
 			// (aggslotmin.i mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp49 := v.args[3]; _tmp49.op == 7 {
+				if _tmp51 := v.args[3]; _tmp51.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 268: /* aggslotmax.f */
+	case 276: /* aggslotmax.f */
 		if len(v.args) == 4 {
 			// (aggslotmax.f mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp50 := v.args[3]; _tmp50.op == 7 {
+				if _tmp52 := v.args[3]; _tmp52.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 269: /* aggslotmax.i */
+	case 277: /* aggslotmax.i */
 		if len(v.args) == 4 {
 			// (aggslotmax.i mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp51 := v.args[3]; _tmp51.op == 7 {
+				if _tmp53 := v.args[3]; _tmp53.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 270: /* aggslotmin.ts */
+	case 278: /* aggslotmin.ts */
 		if len(v.args) == 4 {
 			// (aggslotmin.ts mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp52 := v.args[3]; _tmp52.op == 7 {
+				if _tmp54 := v.args[3]; _tmp54.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 271: /* aggslotmax.ts */
+	case 279: /* aggslotmax.ts */
 		if len(v.args) == 4 {
 			// (aggslotmax.ts mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp53 := v.args[3]; _tmp53.op == 7 {
+				if _tmp55 := v.args[3]; _tmp55.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 272: /* aggslotand.i */
+	case 280: /* aggslotand.i */
 		if len(v.args) == 4 {
 			// (aggslotand.i mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp54 := v.args[3]; _tmp54.op == 7 {
+				if _tmp56 := v.args[3]; _tmp56.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 273: /* aggslotor.i */
+	case 281: /* aggslotor.i */
 		if len(v.args) == 4 {
 			// (aggslotor.i mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp55 := v.args[3]; _tmp55.op == 7 {
+				if _tmp57 := v.args[3]; _tmp57.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 274: /* aggslotxor.i */
+	case 282: /* aggslotxor.i */
 		if len(v.args) == 4 {
 			// (aggslotxor.i mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp56 := v.args[3]; _tmp56.op == 7 {
+				if _tmp58 := v.args[3]; _tmp58.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 275: /* aggslotcount */
+	case 283: /* aggslotcount */
 		if len(v.args) == 3 {
 			// (aggslotcount mem _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp57 := v.args[2]; _tmp57.op == 7 {
+				if _tmp59 := v.args[2]; _tmp59.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 335: /* boxint */
+	case 350: /* boxint */
 		if len(v.args) == 2 {
-			// (boxint _tmp9:(broadcast.i lit) _) -> (literal lit)
-			if _tmp9 := v.args[0]; _tmp9.op == 148 {
-				if lit := toi64(_tmp9.imm); true {
-					return /* clobber v */ p.setssa(v, 129, lit), true
+			// (boxint _tmp11:(broadcast.i lit) _) -> (literal lit)
+			if _tmp11 := v.args[0]; _tmp11.op == 154 {
+				if lit := toi64(_tmp11.imm); true {
+					return /* clobber v */ p.setssa(v, 134, lit), true
 				}
 			}
 		}
-	case 336: /* boxfloat */
+	case 351: /* boxfloat */
 		if len(v.args) == 2 {
-			// (boxfloat _tmp10:(broadcast.f lit) _) -> (literal lit)
-			if _tmp10 := v.args[0]; _tmp10.op == 147 {
-				if lit := tof64(_tmp10.imm); true {
-					return /* clobber v */ p.setssa(v, 129, lit), true
+			// (boxfloat _tmp12:(broadcast.f lit) _) -> (literal lit)
+			if _tmp12 := v.args[0]; _tmp12.op == 153 {
+				if lit := tof64(_tmp12.imm); true {
+					return /* clobber v */ p.setssa(v, 134, lit), true
 				}
 			}
 		}
-	case 338: /* boxts */
+	case 353: /* boxts */
 		if len(v.args) == 2 {
-			// (boxts _tmp11:(broadcast.ts lit) _), "ts := date.UnixMicro(int64(lit)); true" -> (literal ts)
-			if _tmp11 := v.args[0]; _tmp11.op == 276 {
-				if lit := toi64(_tmp11.imm); true {
+			// (boxts _tmp13:(broadcast.ts lit) _), "ts := date.UnixMicro(int64(lit)); true" -> (literal ts)
+			if _tmp13 := v.args[0]; _tmp13.op == 284 {
+				if lit := toi64(_tmp13.imm); true {
 					if ts := date.UnixMicro(int64(lit)); true {
-						return /* clobber v */ p.setssa(v, 129, ts), true
+						return /* clobber v */ p.setssa(v, 134, ts), true
 					}
 				}
 			}
 		}
-	case 345: /* aggapproxcount */
+	case 360: /* aggapproxcount */
 		if len(v.args) == 2 {
 			// (aggapproxcount mem (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp58 := v.args[1]; _tmp58.op == 7 {
+				if _tmp60 := v.args[1]; _tmp60.op == 7 {
 					return mem, true
 				}
 			}
 		}
-	case 346: /* aggslotapproxcount */
+	case 361: /* aggslotapproxcount */
 		if len(v.args) == 4 {
 			// (aggslotapproxcount mem _ _ (false) _) -> mem
 			if mem := v.args[0]; true {
-				if _tmp59 := v.args[3]; _tmp59.op == 7 {
+				if _tmp61 := v.args[3]; _tmp61.op == 7 {
 					return mem, true
 				}
 			}
@@ -804,6 +804,12 @@ func (p *prog) isNotFalse(v *value) *value {
 // can be very slow.
 func (p *prog) index(v *value, i int) *value {
 	l := p.tolist(v)
+	if i < 0 {
+		// list[-n] is list[arraysize(list)-n]
+		mask := p.mask(l)
+		pos := p.ssa2imm(saddimmi, p.ssa2(sarraysize, l, mask), mask, i)
+		return p.ssa3(sarrayindex, l, pos, mask)
+	}
 	for i >= 0 {
 		// NOTE: CSE will take care of
 		// ensuring that the access of
@@ -816,6 +822,70 @@ func (p *prog) index(v *value, i int) *value {
 	return l
 }
 
+// slice returns v[start:end] (or v[start:]
+// if end is nil) as a boxed list
+func (p *prog) slice(v *value, start int, end *int) *value {
+	l := p.tolist(v)
+	last := int64(math.MaxInt64)
+	if end != nil {
+		last = int64(*end)
+	}
+	lo := p.ssa0imm(sbroadcasti, int64(start))
+	hi := p.ssa0imm(sbroadcasti, last)
+	sub := p.ssa4(sarrayslice, l, lo, hi, p.mask(l))
+	return p.ssa2(sboxlist, sub, p.mask(sub))
+}
+
+// lambdaImm is the immediate of stransform
+// before symbolization: body is the program
+// evaluated for each list element, which is
+// bound to param (as an auxiliary value)
+type lambdaImm struct {
+	param string
+	body  prog
+}
+
+// transform returns TRANSFORM(v, param -> body)
+// as a boxed list
+func (p *prog) transform(v *value, param string, body expr.Node) (*value, error) {
+	imm := &lambdaImm{param: param}
+	fn := &imm.body
+	fn.begin()
+	mem, err := fn.compileStore(fn.initMem(), body, stackSlotFromIndex(regV, 0), true)
+	if err != nil {
+		return nil, err
+	}
+	fn.returnValue(mem)
+	fn.Renumber()
+
+	l := p.tolist(v)
+	// we're using ssaimm here because we don't
+	// want the CSE code to look at the immediate field
+	out := p.ssaimm(stransform, imm, l, p.mask(l))
+	return p.ssa2(sboxlist, out, p.mask(out)), nil
+}
+
+// compileLambda symbolizes and compiles the
+// body of a lambda; the symbols it resolves
+// are recorded in p so that p becomes stale
+// whenever the lambda does
+func (p *prog) compileLambda(st *symtab, imm *lambdaImm) (*bytecode, error) {
+	var body prog
+	aux := auxbindings{bound: []string{imm.param}}
+	err := imm.body.cloneSymbolize(st, &body, &aux)
+	if err != nil {
+		return nil, err
+	}
+	fn := new(bytecode)
+	err = body.compile(fn, st, "lambda")
+	if err != nil {
+		return nil, err
+	}
+	p.resolved = append(p.resolved, body.resolved...)
+	p.literals = p.literals || body.literals
+	return fn, nil
+}
+
 func (s ssatype) ordnum() int {
 	switch s {
 	case stBool:
@@ -963,6 +1033,28 @@ func (p *prog) charLength(v *value) *value {
 	return p.ssa2(scharacterlength, v, p.mask(v))
 }
 
+// codePoint returns the code point of the first character in v
+func (p *prog) codePoint(v *value) *value {
+	v = p.coerceStr(v)
+	return p.ssa2(sCodePoint, v, p.mask(v))
+}
+
+// chr returns a string holding the single character
+// with the code point v
+func (p *prog) chr(v *value) *value {
+	// floats are not truncated to code points;
+	// only integer arguments produce a character
+	switch v.primary() {
+	case stValue:
+		v = p.checkTag(v, expr.IntegerType)
+	case stFloat:
+		i, _ := p.coerceI64(v)
+		return p.ssa2(sChr, i, p.missing())
+	}
+	i, m := p.coerceI64(v)
+	return p.ssa2(sChr, i, m)
+}
+
 // Substring returns a substring at the provided startIndex with length
 func (p *prog) substring(v, substrOffset, substrLength *value) *value {
 	offsetInt, offsetMask := p.coerceI64(substrOffset)
@@ -979,6 +1071,17 @@ func (p *prog) splitPart(v *value, delimiter byte, index *value) *value {
 	return p.ssa3imm(sSplitPart, v, indexInt, mask, delimiterStr)
 }
 
+// Translate replaces each character of str found in from
+// with the corresponding character of to; characters of
+// from without a counterpart in to are deleted
+func (p *prog) translate(str *value, from, to string) *value {
+	str = p.coerceStr(str)
+	if from == "" {
+		return str
+	}
+	return p.ssa2imm(sStrTranslate, str, p.mask(str), translateTable(from, to))
+}
+
 // is v an ion null value?
 func (p *prog) isnull(v *value) *value {
 	if v.primary() != stValue {
@@ -1278,6 +1381,9 @@ func trimtype(op expr.BuiltinOp) trimType {
 // TrimWhitespace trim chars: ' ', '\t', '\n', '\v', '\f', '\r'
 func (p *prog) trimWhitespace(str *value, trimtype trimType) *value {
 	str = p.coerceStr(str)
+	if trimtype == trimBoth {
+		return p.ssa2(sStrTrimWsBoth, str, p.mask(str))
+	}
 	if trimtype&trimLeading != 0 {
 		str = p.ssa2(sStrTrimWsLeft, str, p.mask(str))
 	}
@@ -1787,8 +1893,11 @@ func (p *prog) compare(left, right *value, op compareOp) *value {
 		}
 	}
 
-	// compare non-value (scalar/immediate) vs value
-	if rType == stValue {
+	// compare non-value (scalar/immediate) vs value;
+	// numbers are compared with numeric immediates
+	// directly below
+	numImm := rLiteral && (isIntImmediate(right.imm) || isFloatImmediate(right.imm))
+	if rType == stValue && !(numImm && (lType == stInt || lType == stFloat)) {
 		v := p.compareValueWith(right, left, compareOpReverseTable[op])
 		if v != nil {
 			return v
@@ -2086,6 +2195,13 @@ func (p *prog) sign(child *value) *value {
 	return p.makeUnaryArithmeticOp(ssignf, ssigni, child)
 }
 
+// isnan returns the lanes in which child
+// is a floating-point NaN; integers are never NaN
+func (p *prog) isnan(child *value) *value {
+	f, k := p.coerceF64(child)
+	return p.ssa2(sisnanf, f, k)
+}
+
 func (p *prog) bitNot(child *value) *value {
 	return p.makeUnaryArithmeticOpInt(sbitnoti, child)
 }
@@ -2330,6 +2446,13 @@ func (p *prog) powuint(arg *value, exp int64) *value {
 	return p.ssa2imm(spowuintf, x, m, exp)
 }
 
+// random returns a pseudo-random number in [0, 1)
+// computed from the given seed and the position
+// and content of each row
+func (p *prog) random(seed int64) *value {
+	return p.ssa2imm(srandomf, p.values[0], p.validLanes(), seed)
+}
+
 func (p *prog) atan2(left, right *value) *value {
 	return p.makeBinaryArithmeticOpFp(satan2f, left, right)
 }
@@ -2478,6 +2601,28 @@ func (p *prog) dateToUnixMicro(val *value) *value {
 	return p.ssa2(sdatetounixmicro, v, m)
 }
 
+// parseTimestamp parses str as a timestamp
+// using a format accepted by date.Layout
+func (p *prog) parseTimestamp(str *value, format string) *value {
+	layout, err := date.Layout(format)
+	if err != nil {
+		return p.errorf("PARSE_TIMESTAMP: %s", err)
+	}
+	str = p.coerceStr(str)
+	return p.ssa2imm(sparsets, str, p.mask(str), layout)
+}
+
+// formatTimestamp formats val as a string
+// using a format accepted by date.Layout
+func (p *prog) formatTimestamp(val *value, format string) *value {
+	layout, err := date.Layout(format)
+	if err != nil {
+		return p.errorf("FORMAT_TIMESTAMP: %s", err)
+	}
+	v, m := p.coerceTimestamp(val)
+	return p.ssa2imm(sformatts, v, m, layout)
+}
+
 func (p *prog) dateTrunc(part expr.Timepart, val *value) *value {
 	if part == expr.Microsecond {
 		return val
@@ -2591,7 +2736,7 @@ func (p *prog) arrayContains(array, item *value) *value {
 	item = p.unsymbolized(item)
 	mask := p.and(p.mask(array), p.mask(item))
 
-	out := p.ssa1(snotmissing, p.ssa3(sarrayposition, array, item, mask))
+	out := p.ssa3(sarraycontains, array, item, mask)
 	out.notMissing = mask
 	return out
 }
@@ -2668,6 +2813,28 @@ func (p *prog) vectorCosineDistance(a *value, b *value) *value {
 	return p.vectorProduct(svectorcosinedistance, svectorcosinedistanceimm, a, b)
 }
 
+// jsonExtract returns JSON_EXTRACT(str, path);
+// the path must already have been validated
+// with expr.SplitJSONPath
+func (p *prog) jsonExtract(str *value, path string) *value {
+	str = p.coerceStr(str)
+	return p.ssa2imm(sjsonextract, str, p.mask(str), path)
+}
+
+func emitjsonextract(v *value, c *compilestate) {
+	steps, err := expr.SplitJSONPath(v.imm.(string))
+	if err != nil {
+		panic("emitjsonextract: " + err.Error())
+	}
+	slot := uint16(len(c.jsonpaths))
+	c.jsonpaths = append(c.jsonpaths, steps)
+	c.emit(v, ssainfo[v.op].bc,
+		c.slotOf(v.args[0], regS),
+		uint64(slot),
+		c.slotOf(v.args[1], regK),
+	)
+}
+
 func emitNone(v *value, c *compilestate) {
 	// does nothing...
 }
@@ -2784,6 +2951,14 @@ func (p *prog) aggregateTDigest(child, filter *value, slot aggregateslot) *value
 	return p.ssa3imm(sAggTDigest, p.initMem(), v, m, slot)
 }
 
+func (p *prog) aggregateMode(child, filter *value, slot aggregateslot) *value {
+	mask := p.mask(child)
+	if filter != nil {
+		mask = p.and(mask, filter)
+	}
+	return p.ssa3imm(saggmode, p.initMem(), child, mask, slot)
+}
+
 func (p *prog) aggregateAvg(child, filter *value, slot aggregateslot) (v *value, fp bool) {
 	return p.makeAggregateOp(saggavgf, saggavgi, child, filter, slot)
 }
@@ -2943,6 +3118,14 @@ func (p *prog) aggregateSlotApproxCountDistinct(mem, bucket, argv, mask *value,
 	return p.ssa4imm(saggslotapproxcount, mem, bucket, h, k, (uint64(offset)<<8)|uint64(precision))
 }
 
+func (p *prog) aggregateSlotMode(mem, bucket, argv, mask *value, offset aggregateslot) *value {
+	k := p.mask(argv)
+	if mask != nil {
+		k = p.and(k, mask)
+	}
+	return p.ssa4imm(saggslotmode, mem, bucket, argv, k, offset)
+}
+
 func (p *prog) aggregateSlotMergeState(bucket, argv, mask *value, offset aggregateslot) *value {
 	blob := p.ssa2(stoblob, argv, mask)
 	return p.ssa3imm(saggslotmergestate, bucket, blob, p.mask(blob), offset)
@@ -2958,6 +3141,14 @@ func (p *prog) aggbucket(mem, h, k *value) *value {
 	return p.ssa3(saggbucket, mem, h, k)
 }
 
+// aggbucketbool is equivalent to aggbucket
+// for a single boolean grouping key, but the
+// bucket is selected directly by the value of
+// key rather than by a hash lookup
+func (p *prog) aggbucketbool(mem, key, k *value) *value {
+	return p.ssa3(saggbucketbool, mem, key, k)
+}
+
 func (p *prog) hash(v *value) *value {
 	v = p.unsymbolized(v)
 	switch v.primary() {
@@ -2968,6 +3159,12 @@ func (p *prog) hash(v *value) *value {
 	}
 }
 
+// hashbucket computes the bucket of h
+// among n buckets, where n is a power of two
+func (p *prog) hashbucket(h *value, n int) *value {
+	return p.ssa2imm(shashbucket, h, p.mask(h), n-1)
+}
+
 func (p *prog) hashplus(h *value, v *value) *value {
 	v = p.unsymbolized(v)
 	switch v.primary() {
@@ -3463,8 +3660,11 @@ type compilestate struct {
 	dict   []string
 	litbuf []byte // output datum literals
 
-	symtab *ion.Symtab // current symtab
-	buf    ion.Buffer  // temporary buffer
+	symtab  *ion.Symtab // current symtab
+	buf     ion.Buffer  // temporary buffer
+	lambdas []*bytecode // programs referenced by optransform
+
+	jsonpaths [][]expr.JSONPathStep // paths referenced by opjsonextract
 }
 
 func (c *compilestate) emit(v *value, op bcop, args ...any) {
@@ -3708,6 +3908,31 @@ func emithashmember(v *value, c *compilestate) {
 	)
 }
 
+func emithashbucket(v *value, c *compilestate) {
+	h := v.args[0]
+	k := v.args[1]
+
+	c.emit(v, ssainfo[v.op].bc,
+		c.slotOf(h, regH),
+		toi64(v.imm),
+		c.slotOf(k, regK),
+	)
+}
+
+func emittransform(v *value, c *compilestate) {
+	fn, ok := v.imm.(*bytecode)
+	if !ok {
+		panic("transform emitted before symbolize()")
+	}
+	slot := uint16(len(c.lambdas))
+	c.lambdas = append(c.lambdas, fn)
+	c.emit(v, ssainfo[v.op].bc,
+		c.slotOf(v.args[0], regS),
+		uint64(slot),
+		c.slotOf(v.args[1], regK),
+	)
+}
+
 func emitslice(v *value, c *compilestate) {
 	info := &ssainfo[v.op]
 	bc := info.bc
@@ -4144,9 +4369,12 @@ func (p *prog) compile(dst *bytecode, st *symtab, callerName string) error {
 	}
 
 	dst.vstacksize = c.stack.stackSize()
+	dst.goonly = c.asm.goonly
 	dst.allocStacks()
 	dst.trees = c.trees
 	dst.dict = c.dict
+	dst.lambdas = c.lambdas
+	dst.jsonpaths = c.jsonpaths
 	dst.compiled = c.asm.grabCode()
 
 	reserve := c.asm.scratchuse + len(c.litbuf)
@@ -4405,6 +4633,12 @@ func (p *prog) symbolize(st *symtab, aux *auxbindings) error {
 			}
 			v.imm = sym
 			p.record(str, sym)
+		case stransform:
+			fn, err := p.compileLambda(st, v.imm.(*lambdaImm))
+			if err != nil {
+				return err
+			}
+			v.imm = fn
 		case smakestructkey:
 			str := v.imm.(string)
 			sym := st.Intern(str)
@@ -996,3 +996,279 @@ func FuzzEncodeDecodePattern(f *testing.F) {
 		}
 	})
 }
+
+func TestSimplifyCSE(t *testing.T) {
+	countOps := func(p *prog, op ssaop) int {
+		n := 0
+		for _, v := range p.values {
+			if v.op == op {
+				n++
+			}
+		}
+		return n
+	}
+	var st symtab
+	defer st.free()
+	buf := unhex(parkingCitations1KLines)
+	_, err := st.Unmarshal(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := new(prog)
+	p.begin()
+	str := p.coerceStr(p.dot("Make", p.validLanes()))
+	k := p.mask(str)
+	// the two UPPER() calls only become identical
+	// once (and.k k k) has been rewritten to k
+	upper0 := p.ssa2(supperstr, str, k)
+	upper1 := p.ssa2(supperstr, str, p.ssa2(sand, k, k))
+	if upper0 == upper1 {
+		t.Fatal("values deduplicated before simplification")
+	}
+	p.returnBK(p.validLanes(), p.and(
+		p.equals(upper0, p.constant("HOND")),
+		p.equals(upper1, p.constant("HOND"))))
+
+	var sample prog
+	err = p.cloneSymbolize(&st, &sample, &auxbindings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bc bytecode
+	err = sample.compile(&bc, &st, "TestSimplifyCSE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.reset()
+	if n := countOps(&sample, supperstr); n != 1 {
+		var out strings.Builder
+		sample.writeTo(&out)
+		t.Errorf("%d upper.str ops in program:\n%s", n, out.String())
+	}
+
+	var out QueryBuffer
+	err = CopyRows(where(p, &out), buftbl(buf), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c Count
+	err = CopyRows(&c, out.Table(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Value() != 122 {
+		t.Errorf("got %d rows", c.Value())
+	}
+}
+
+func TestOptimizeDeadCode(t *testing.T) {
+	var st symtab
+	defer st.free()
+	buf := unhex(parkingCitations1KLines)
+	_, err := st.Unmarshal(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hond := func(p *prog, extra bool) {
+		p.begin()
+		str := p.coerceStr(p.dot("Make", p.validLanes()))
+		k := p.mask(str)
+		if extra {
+			// never used:
+			p.upper(str)
+			// used only until (and.k k k) is rewritten to k:
+			k = p.ssa2(sand, k, k)
+		}
+		p.returnBK(p.validLanes(), p.ssa3(scmpeqstr, p.coerceStr(p.constant("HOND")), str, k))
+	}
+	count := func(p *prog) int64 {
+		var out QueryBuffer
+		err := CopyRows(where(p, &out), buftbl(buf), 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c Count
+		err = CopyRows(&c, out.Table(), 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c.Value()
+	}
+
+	p := new(prog)
+	hond(p, true)
+	var sample prog
+	err = p.cloneSymbolize(&st, &sample, &auxbindings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// optimize keeps only the values in the
+	// execution ordering, so the values that
+	// simplify leaves unreferenced are dropped
+	sample.optimize()
+	for i, v := range sample.values {
+		if v.id != i {
+			t.Errorf("value %d has id %d", i, v.id)
+		}
+		if v.op == supperstr || v.op == sand {
+			t.Errorf("unreferenced value %s retained", v.String())
+		}
+	}
+	var pi proginfo
+	if len(sample.values) != len(sample.order(&pi)) {
+		t.Errorf("%d values but %d are reachable", len(sample.values), len(sample.order(&pi)))
+	}
+
+	ref := new(prog)
+	hond(ref, false)
+	if got, want := count(p), count(ref); got != want {
+		t.Errorf("got %d rows, want %d", got, want)
+	}
+}
+
+func TestConjunctionOrder(t *testing.T) {
+	var st symtab
+	defer st.free()
+	buf := unhex(parkingCitations1KLines)
+	_, err := st.Unmarshal(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	regex := &expr.StringMatch{Op: expr.RegexpMatch, Expr: expr.Ident("Color"), Pattern: "B.*K$"}
+	equal := expr.Compare(expr.Equals, expr.Ident("Make"), expr.String("HOND"))
+
+	rows := int64(-1)
+	for _, e := range []expr.Node{expr.And(regex, equal), expr.And(equal, regex)} {
+		p, err := compileLogical(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sample prog
+		err = p.cloneSymbolize(&st, &sample, &auxbindings{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var bc bytecode
+		err = sample.compile(&bc, &st, "TestConjunctionOrder")
+		if err != nil {
+			t.Fatal(err)
+		}
+		bc.reset()
+
+		// the equality should be evaluated first,
+		// and the regex only on the lanes that passed it
+		var eq, dfa *value
+		for _, v := range sample.values {
+			switch v.op {
+			case sequalconst:
+				eq = v
+			case sDfaT6Z:
+				dfa = v
+			}
+		}
+		if eq == nil || dfa == nil {
+			var out strings.Builder
+			sample.writeTo(&out)
+			t.Fatalf("%s: unexpected program:\n%s", expr.ToString(e), out.String())
+		}
+		if eq.id > dfa.id || !dependsOn(dfa, eq) {
+			var out strings.Builder
+			sample.writeTo(&out)
+			t.Errorf("%s: regex not short-circuited by equality:\n%s", expr.ToString(e), out.String())
+		}
+
+		var c Count
+		err = CopyRows(where(p, &c), buftbl(buf), 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rows == -1 {
+			rows = c.Value()
+		} else if c.Value() != rows {
+			t.Errorf("%s: got %d rows, want %d", expr.ToString(e), c.Value(), rows)
+		}
+	}
+	// Make = 'HOND' AND Color = 'BK' matches 24 rows
+	if rows < 24 {
+		t.Errorf("got %d rows, want at least 24", rows)
+	}
+}
+
+func TestCompareNumberImmediate(t *testing.T) {
+	var st symtab
+	defer st.free()
+	st.Intern("x")
+
+	length := expr.Call(expr.CharLength, expr.Ident("x"))
+	for _, tc := range []struct {
+		e  expr.Node
+		op ssaop
+	}{
+		{expr.Compare(expr.Greater, length, expr.Integer(3)), scmpgtimmi},
+		{expr.Compare(expr.Less, length, expr.Float(2.5)), scmpltimmf},
+	} {
+		p, err := compileLogical(tc.e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sample prog
+		err = p.cloneSymbolize(&st, &sample, &auxbindings{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, v := range sample.values {
+			switch v.op {
+			case scmpvi64, scmpvf64:
+				t.Errorf("%s: unexpected boxed compare %s", expr.ToString(tc.e), v.String())
+			case tc.op:
+				found = true
+			}
+		}
+		if !found {
+			var out strings.Builder
+			sample.writeTo(&out)
+			t.Errorf("%s: no %s op in program:\n%s", expr.ToString(tc.e), ssainfo[tc.op].text, out.String())
+		}
+	}
+}
+
+func TestSimplifyArrayPosition(t *testing.T) {
+	var st symtab
+	defer st.free()
+	st.Intern("x")
+
+	for _, op := range []compareOp{comparege, comparegt} {
+		imm := int64(1)
+		if op == comparegt {
+			imm = 0
+		}
+		p := new(prog)
+		p.begin()
+		pos := p.arrayPosition(p.dot("x", p.validLanes()), p.constant("b"))
+		p.returnBK(p.validLanes(), p.compare(pos, p.constant(imm), op))
+
+		var sample prog
+		err := p.cloneSymbolize(&st, &sample, &auxbindings{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pi proginfo
+		sample.simplify(&pi)
+		contains := 0
+		for _, v := range sample.order(&pi) {
+			switch v.op {
+			case sarrayposition:
+				t.Errorf("arrayposition retained: %s", v.String())
+			case sarraycontains:
+				contains++
+			}
+		}
+		if contains != 1 {
+			var out strings.Builder
+			sample.writeTo(&out)
+			t.Errorf("expected one arraycontains op in program:\n%s", out.String())
+		}
+	}
+}
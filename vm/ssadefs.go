@@ -87,6 +87,8 @@ const (
 	scmpgei
 	scmpgeimmi
 
+	sisnanf // out = isnan(x)
+
 	scmpeqts
 	scmpltts
 	scmplets
@@ -140,6 +142,7 @@ const (
 	sStrTrimCharRight // String trim specific chars right
 	sStrTrimWsLeft    // String trim whitespace left
 	sStrTrimWsRight   // String trim whitespace right
+	sStrTrimWsBoth    // String trim whitespace left and right
 
 	sStrContainsPrefixCs      // String contains prefix case-sensitive
 	sStrContainsPrefixCi      // String contains prefix case-insensitive
@@ -165,6 +168,9 @@ const (
 	scharacterlength // count number of character in a string
 	sSubStr          // select a substring
 	sSplitPart       // Presto split_part
+	sStrTranslate    // translate characters with a lookup table
+	sCodePoint       // code point of the first character of a string
+	sChr             // code point to a single character string
 
 	sDfaT6  // DFA tiny 6-bit
 	sDfaT7  // DFA tiny 7-bit
@@ -188,6 +194,7 @@ const (
 	shashvaluep // hash a value and add it to the current hash
 	shashmember // look up a hash in a tree for existence; returns predicate
 	shashlookup // look up a hash in a tree for a value; returns boxed
+	shashbucket // compute the bucket of a hash
 
 	sstorev // copy a value from one slot to another
 
@@ -303,6 +310,7 @@ const (
 	shypotf       // out = hypot(x, y)
 	spowf         // out = pow(x, y)
 	spowuintf     // out = powuint(x, uint_y)
+	srandomf      // out = random(row, seed)
 
 	swidthbucketf // out = width_bucket(val, min, max, bucket_count)
 	swidthbucketi // out = width_bucket(val, min, max, bucket_count)
@@ -327,6 +335,7 @@ const (
 	saggmergestate
 
 	saggbucket
+	saggbucketbool
 	saggslotandk
 	saggslotork
 	saggslotsumf
@@ -395,6 +404,10 @@ const (
 	sobjectsize // built-in function SIZE()
 	sarraysize
 	sarrayposition
+	sarraycontains
+	sarrayindex
+	sarrayslice
+	stransform
 	sarraysum
 
 	svectorinnerproduct
@@ -406,6 +419,10 @@ const (
 	svectorcosinedistance
 	svectorcosinedistanceimm
 
+	sjsonextract // built-in function JSON_EXTRACT()
+	sparsets     // built-in function PARSE_TIMESTAMP()
+	sformatts    // built-in function FORMAT_TIMESTAMP()
+
 	sboxmask  // box a mask
 	sboxint   // box an integer
 	sboxfloat // box a float
@@ -425,6 +442,9 @@ const (
 	sAggTDigest // tDigest aggregator used for percentile, median approximation
 	sAggSlotTDigest
 
+	saggmode     // MODE
+	saggslotmode // MODE aggregate in GROUP BY
+
 	saggslotmergestate
 
 	_ssamax
@@ -738,6 +758,8 @@ var _ssainfo = [_ssamax]ssaopinfo{
 	scmpgei:    {text: "cmpge.i64", argtypes: argsIntIntBool, rettype: stBool, bc: opcmpgei64},
 	scmpgeimmi: {text: "cmpge.i64@imm", argtypes: int1Args, rettype: stBool, immfmt: fmti64, bc: opcmpgei64imm},
 
+	sisnanf: {text: "isnan.f", argtypes: fp1Args, rettype: stBool, bc: opisnanf},
+
 	scmpeqts: {text: "cmpeq.ts", rettype: stBool, argtypes: []ssatype{stTime, stTime, stBool}, bc: opcmpeqi64},
 	scmpltts: {text: "cmplt.ts", rettype: stBool, argtypes: []ssatype{stTime, stTime, stBool}, bc: opcmplti64},
 	scmplets: {text: "cmple.ts", rettype: stBool, argtypes: []ssatype{stTime, stTime, stBool}, bc: opcmplei64},
@@ -796,6 +818,7 @@ var _ssainfo = [_ssamax]ssaopinfo{
 
 	sStrTrimWsLeft:    {text: "trim_ws_left", argtypes: str1Args, rettype: stString, bc: opTrimWsLeft},
 	sStrTrimWsRight:   {text: "trim_ws_right", argtypes: str1Args, rettype: stString, bc: opTrimWsRight},
+	sStrTrimWsBoth:    {text: "trim_ws_both", argtypes: str1Args, rettype: stString, bc: opTrimWsBoth},
 	sStrTrimCharLeft:  {text: "trim_char_left", argtypes: str1Args, rettype: stString, immfmt: fmtdict, bc: opTrim4charLeft},
 	sStrTrimCharRight: {text: "trim_char_right", argtypes: str1Args, rettype: stString, immfmt: fmtdict, bc: opTrim4charRight},
 
@@ -835,6 +858,9 @@ var _ssainfo = [_ssamax]ssaopinfo{
 	scharacterlength: {text: "characterlength", argtypes: str1Args, rettype: stInt, bc: opcharlength},
 	sSubStr:          {text: "substr", argtypes: []ssatype{stString, stInt, stInt, stBool}, rettype: stString, bc: opSubstr},
 	sSplitPart:       {text: "split_part", argtypes: []ssatype{stString, stInt, stBool}, rettype: stStringMasked, immfmt: fmtdict, bc: opSplitPart},
+	sStrTranslate:    {text: "translate", argtypes: str1Args, rettype: stStringMasked, immfmt: fmtdict, bc: opTranslate, cost: costHeavy},
+	sCodePoint:       {text: "codepoint", argtypes: str1Args, rettype: stIntMasked, bc: opcodepoint},
+	sChr:             {text: "chr", argtypes: int1Args, rettype: stStringMasked, bc: opchr},
 
 	sDfaT6:  {text: "dfa_tiny6", cost: costXHeavy, argtypes: str1Args, rettype: stBool, immfmt: fmtdict, bc: opDfaT6},
 	sDfaT7:  {text: "dfa_tiny7", cost: costXHeavy, argtypes: str1Args, rettype: stBool, immfmt: fmtdict, bc: opDfaT7},
@@ -868,6 +894,7 @@ var _ssainfo = [_ssamax]ssaopinfo{
 
 	shashmember: {text: "hashmember", argtypes: []ssatype{stHash, stBool}, rettype: stBool, immfmt: fmtother, bc: ophashmember, emit: emithashmember},
 	shashlookup: {text: "hashlookup", argtypes: []ssatype{stHash, stBool}, rettype: stValueMasked, immfmt: fmtother, bc: ophashlookup, emit: emithashlookup},
+	shashbucket: {text: "hashbucket", argtypes: []ssatype{stHash, stBool}, rettype: stInt, immfmt: fmti64, bc: ophashbucket, emit: emithashbucket},
 
 	sliteral: {text: "literal", rettype: stValue, immfmt: fmtother, bc: oplitref, safeValueMask: true}, // yields <value>.kinit
 
@@ -985,6 +1012,7 @@ var _ssainfo = [_ssamax]ssaopinfo{
 	shypotf:   {text: "hypot.f", rettype: stFloatMasked, argtypes: []ssatype{stFloat, stFloat, stBool}, bc: ophypotf64},
 	spowf:     {text: "pow.f", rettype: stFloatMasked, argtypes: []ssatype{stFloat, stFloat, stBool}, bc: oppowf64},
 	spowuintf: {text: "powuint.f", rettype: stFloat, argtypes: []ssatype{stFloat, stBool}, immfmt: fmti64, bc: oppowuintf64},
+	srandomf:  {text: "random.f", rettype: stFloat, argtypes: []ssatype{stBase, stBool}, immfmt: fmti64, bc: oprandom},
 
 	swidthbucketf: {text: "widthbucket.f", rettype: stFloat, argtypes: []ssatype{stFloat, stFloat, stFloat, stFloat, stBool}, bc: opwidthbucketf64},
 	swidthbucketi: {text: "widthbucket.i", rettype: stInt, argtypes: []ssatype{stInt, stInt, stInt, stInt, stBool}, bc: opwidthbucketi64},
@@ -1007,9 +1035,12 @@ var _ssainfo = [_ssamax]ssaopinfo{
 	saggcount: {text: "aggcount", rettype: stMem, argtypes: []ssatype{stMem, stBool}, immfmt: fmtaggslot, bc: opaggcount, priority: prioMem + 1},
 
 	sAggTDigest: {text: "agg.tdigest", rettype: stMem, argtypes: []ssatype{stMem, stFloat, stBool}, immfmt: fmtaggslot, bc: opAggTDigest, priority: prioMem},
+	saggmode:    {text: "aggmode", rettype: stMem, argtypes: []ssatype{stMem, stValue, stBool}, immfmt: fmtaggslot, bc: opaggmode, priority: prioMem},
 
 	// compute hash aggregate bucket location; encoded immediate will be input hash slot to use
 	saggbucket: {text: "aggbucket", argtypes: []ssatype{stMem, stHash, stBool}, rettype: stBucket, immfmt: fmtslot, bc: opaggbucket},
+	// compute hash aggregate bucket location directly from a boolean key
+	saggbucketbool: {text: "aggbucket.bool", argtypes: []ssatype{stMem, stBool, stBool}, rettype: stBucket, bc: opaggbucketbool},
 
 	// hash aggregate bucket ops (count, min, max, sum, ...)
 	saggslotandk:  {text: "aggslotand.k", argtypes: []ssatype{stMem, stBucket, stBool, stBool}, rettype: stMem, immfmt: fmtslot, bc: opaggslotandk, priority: prioMem},
@@ -1028,6 +1059,7 @@ var _ssainfo = [_ssamax]ssaopinfo{
 	saggslotori:   {text: "aggslotor.i", argtypes: []ssatype{stMem, stBucket, stInt, stBool}, rettype: stMem, immfmt: fmtslot, bc: opaggslotori, priority: prioMem},
 	saggslotxori:  {text: "aggslotxor.i", argtypes: []ssatype{stMem, stBucket, stInt, stBool}, rettype: stMem, immfmt: fmtslot, bc: opaggslotxori, priority: prioMem},
 	saggslotcount: {text: "aggslotcount", argtypes: []ssatype{stMem, stBucket, stBool}, rettype: stMem, immfmt: fmtslot, bc: opaggslotcount, priority: prioMem},
+	saggslotmode:  {text: "aggslotmode", argtypes: []ssatype{stMem, stBucket, stValue, stBool}, rettype: stMem, immfmt: fmtslot, bc: opaggslotmode, priority: prioMem},
 
 	// boxing ops
 	//
@@ -1098,6 +1130,10 @@ var _ssainfo = [_ssamax]ssaopinfo{
 	sobjectsize:    {text: "objectsize", argtypes: []ssatype{stValue, stBool}, rettype: stIntMasked, bc: opobjectsize},
 	sarraysize:     {text: "arraysize", argtypes: []ssatype{stList, stBool}, rettype: stInt, bc: oparraysize},
 	sarrayposition: {text: "arrayposition", argtypes: []ssatype{stList, stValue, stBool}, rettype: stIntMasked, bc: oparrayposition},
+	sarraycontains: {text: "arraycontains", argtypes: []ssatype{stList, stValue, stBool}, rettype: stBool, bc: oparraycontains},
+	sarrayindex:    {text: "arrayindex", argtypes: []ssatype{stList, stInt, stBool}, rettype: stValueMasked, bc: oparrayindex},
+	sarrayslice:    {text: "arrayslice", argtypes: []ssatype{stList, stInt, stInt, stBool}, rettype: stListMasked, bc: oparrayslice},
+	stransform:     {text: "transform", argtypes: []ssatype{stList, stBool}, rettype: stListMasked, immfmt: fmtother, bc: optransform, emit: emittransform},
 	sarraysum:      {text: "arraysum", argtypes: []ssatype{stList, stBool}, rettype: stFloatMasked, bc: oparraysum},
 
 	svectorinnerproduct:   {text: "vectorinnerproduct", cost: costHeavy, argtypes: []ssatype{stList, stList, stBool}, rettype: stFloatMasked, bc: opvectorinnerproduct},
@@ -1110,6 +1146,10 @@ var _ssainfo = [_ssamax]ssaopinfo{
 	svectorl2distanceimm:     {text: "vectorl2distance@imm", cost: costHeavy, argtypes: []ssatype{stList, stBool}, rettype: stFloatMasked, immfmt: fmtdict, bc: opvectorl2distanceimm},
 	svectorcosinedistanceimm: {text: "vectorcosinedistance@imm", cost: costHeavy, argtypes: []ssatype{stList, stBool}, rettype: stFloatMasked, immfmt: fmtdict, bc: opvectorcosinedistanceimm},
 
+	sjsonextract: {text: "jsonextract", cost: costXHeavy, argtypes: []ssatype{stString, stBool}, rettype: stValueMasked, immfmt: fmtother, bc: opjsonextract, emit: emitjsonextract},
+	sparsets:     {text: "parsets", cost: costXHeavy, argtypes: []ssatype{stString, stBool}, rettype: stTimeMasked, immfmt: fmtdict, bc: opparsets},
+	sformatts:    {text: "formatts", cost: costXHeavy, argtypes: []ssatype{stTime, stBool}, rettype: stStringMasked, immfmt: fmtdict, bc: opformatts},
+
 	saggmergestate: {
 		text:     "aggmergestate",
 		argtypes: []ssatype{stBlob, stBool},
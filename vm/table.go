@@ -15,6 +15,7 @@
 package vm
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -50,6 +51,32 @@ type Table interface {
 // care of closing the outputs returned from dst.Open()
 // and waits for each goroutine to return.
 func SplitInput(dst QuerySink, parallel int, into func(io.Writer) error) error {
+	return SplitInputContext(context.Background(), dst, parallel, into)
+}
+
+// SplitInputContext is like SplitInput, but it
+// stops opening new outputs once ctx is canceled
+// and returns ctx.Err() if ctx was canceled before
+// all of the calls to into() returned.
+// Callers are expected to have into() observe
+// ctx as well so that it returns promptly.
+func SplitInputContext(ctx context.Context, dst QuerySink, parallel int, into func(io.Writer) error) error {
+	return SplitInputDone(ctx, dst, parallel, func(w io.Writer, _ <-chan struct{}) error {
+		return into(w)
+	})
+}
+
+// SplitInputDone is like SplitInputContext, but
+// into() is also passed a channel that is closed
+// once any call to into() has returned io.EOF,
+// which indicates that dst will not accept any
+// more rows (for example, because a LIMIT has been
+// satisfied). Callers can check the channel between
+// blocks of input in order to stop reading early.
+func SplitInputDone(ctx context.Context, dst QuerySink, parallel int, into func(w io.Writer, done <-chan struct{}) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	merge := func(first, second error) error {
 		ret := first
 		if ret == nil || errors.Is(ret, io.EOF) {
@@ -57,6 +84,15 @@ func SplitInput(dst QuerySink, parallel int, into func(io.Writer) error) error {
 		}
 		return ret
 	}
+	done := make(chan struct{})
+	var once sync.Once
+	run := func(w io.Writer) error {
+		err := into(w, done)
+		if errors.Is(err, io.EOF) {
+			once.Do(func() { close(done) })
+		}
+		return err
+	}
 	if parallel <= 1 {
 		// Don't use goroutines if there is no parallelism - this makes debugging a bit easier.
 		w, err := dst.Open()
@@ -64,13 +100,20 @@ func SplitInput(dst QuerySink, parallel int, into func(io.Writer) error) error {
 			return err
 		}
 
-		err = into(w)
-		return merge(err, w.Close())
+		err = run(w)
+		err = merge(err, w.Close())
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
 	}
 	var wg sync.WaitGroup
 	errlist := make([]error, parallel)
 	opendone := make(chan struct{}, 1)
 	for i := 0; i < parallel; i++ {
+		if i > 0 && ctx.Err() != nil {
+			break
+		}
 		w, err := dst.Open()
 		if err != nil {
 			if i == 0 {
@@ -83,7 +126,7 @@ func SplitInput(dst QuerySink, parallel int, into func(io.Writer) error) error {
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			err := into(w)
+			err := run(w)
 			// make sure w.Close() is safe to call
 			<-opendone
 			errlist[i] = merge(err, w.Close())
@@ -97,6 +140,9 @@ func SplitInput(dst QuerySink, parallel int, into func(io.Writer) error) error {
 	close(opendone)
 	wg.Wait()
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	for i := range errlist {
 		if errlist[i] != nil {
 			return errlist[i]
@@ -15,8 +15,11 @@
 package vm
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"slices"
+	"sync/atomic"
 
 	"github.com/SnellerInc/sneller/expr"
 )
@@ -29,6 +32,12 @@ type Unnest struct {
 	field  expr.Node
 	prog   prog
 	result string
+	outer  bool
+
+	// max, if positive, is the maximum number
+	// of rows that may be produced; rows is
+	// the (atomically updated) number produced so far
+	max, rows int64
 }
 
 // NewUnnest creates an Unnest QuerySink that cross-joins
@@ -54,6 +63,29 @@ func NewUnnest(dst QuerySink, field expr.Node, result string) (*Unnest, error) {
 	return u, nil
 }
 
+// SetOuter configures whether or not rows for which
+// the field is not a list with at least one element
+// are preserved. If they are, each such row is written
+// to the output once with the auxiliary binding MISSING.
+// The default behavior is to drop these rows.
+func (u *Unnest) SetOuter(outer bool) {
+	u.outer = outer
+}
+
+// SetMaxRows configures the maximum number of rows
+// that may be produced across all of the outputs of u.
+// Once more than max rows have been produced,
+// writes fail with an error wrapping ErrUnnestLimit.
+// A max of zero or less means there is no limit.
+func (u *Unnest) SetMaxRows(max int64) {
+	u.max = max
+}
+
+// ErrUnnestLimit is returned (wrapped) from writes
+// to an Unnest that has produced more rows than
+// the limit set with Unnest.SetMaxRows.
+var ErrUnnestLimit = errors.New("vm: unnest exceeded its row limit")
+
 func (u *Unnest) Open() (io.WriteCloser, error) {
 	dst, err := u.dst.Open()
 	if err != nil {
@@ -79,6 +111,10 @@ type unnesting struct {
 
 	// cached buffers for inner and outer refs
 	inner, outer []vmref
+
+	// cached buffers for keepEmpty
+	keptOuter []vmref
+	keptPerms []int32
 }
 
 func (u *unnesting) next() rowConsumer { return u.dstrc }
@@ -97,7 +133,7 @@ func (u *unnesting) symbolize(st *symtab, aux *auxbindings) error {
 }
 
 func splat(bc *bytecode, indelims, outdelims []vmref, perm []int32) (int, int) {
-	if globalOptimizationLevel >= OptimizationLevelAVX512V1 {
+	if bc.useAsm() {
 		return evalsplat(bc, indelims, outdelims, perm)
 	}
 	return evalsplatgo(bc, indelims, outdelims, perm)
@@ -151,6 +187,7 @@ func (u *unnesting) splatParams(in *rowParams, consumed int, perm []int32, inner
 	}
 	// add new bindings
 	u.params.auxbound[u.auxnum] = inner
+	u.params.pos = derivepos(in.pos + uint64(consumed))
 	return &u.params
 }
 
@@ -179,6 +216,7 @@ func (u *unnesting) writeRows(delims []vmref, rp *rowParams) error {
 		// adjust this to take into account the fact
 		// that we may not have actually handled all the lanes
 		u.splat.auxpos = consumed + in
+		u.splat.random = rp.pos + uint64(consumed+in)
 		if in == 0 {
 			// there wasn't enough room to splat a single
 			// lane's array members! we need more space,
@@ -187,15 +225,23 @@ func (u *unnesting) writeRows(delims []vmref, rp *rowParams) error {
 			u.perms = slices.Grow(u.perms, len(u.perms))
 			continue
 		}
-		if out == 0 {
+		outer := u.outer[:out]
+		innerperm := u.perms[:out]
+		if u.parent.outer {
+			outer, innerperm = u.keepEmpty(in, outer, innerperm)
+		}
+		if len(outer) == 0 {
 			consumed += in
 			continue
 		}
+		if max := u.parent.max; max > 0 {
+			if n := atomic.AddInt64(&u.parent.rows, int64(len(outer))); n > max {
+				return fmt.Errorf("%w of %d rows", ErrUnnestLimit, max)
+			}
+		}
 		// incorporate inner and outer values
 		// in two slices adjacent to one another:
-		outer := u.outer[:out]
 		u.inner = shrink(u.inner, len(outer))
-		innerperm := u.perms[:out]
 		// permute delimiters into unrolled delimiters:
 		for i, n := range innerperm {
 			u.inner[i] = delims[consumed+int(n)]
@@ -209,6 +255,31 @@ func (u *unnesting) writeRows(delims []vmref, rp *rowParams) error {
 	return nil
 }
 
+// keepEmpty adds an output row with a MISSING value
+// for each of the first 'lanes' input rows that did
+// not produce any of the output rows in outer and perm
+func (u *unnesting) keepEmpty(lanes int, outer []vmref, perm []int32) ([]vmref, []int32) {
+	u.keptOuter = u.keptOuter[:0]
+	u.keptPerms = u.keptPerms[:0]
+	j := 0
+	for i := 0; i < lanes; i++ {
+		if j == len(perm) || int(perm[j]) != i {
+			u.keptOuter = append(u.keptOuter, vmref{})
+			u.keptPerms = append(u.keptPerms, int32(i))
+			continue
+		}
+		// perm is sorted, so the output rows
+		// of each input row are adjacent
+		for j < len(perm) && int(perm[j]) == i {
+			u.keptOuter = append(u.keptOuter, outer[j])
+			u.keptPerms = append(u.keptPerms, perm[j])
+			j++
+		}
+	}
+	u.keptOuter = sanitizeAux(u.keptOuter, len(u.keptOuter))
+	return u.keptOuter, u.keptPerms
+}
+
 func (u *unnesting) Close() error {
 	u.splat.reset()
 	return u.dstrc.Close()
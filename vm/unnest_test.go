@@ -142,6 +142,21 @@ func TestSplat(t *testing.T) {
 		}
 	}
 
+	// test providing one less than enough space
+	// for entries [0, ...]; the partially-splatted
+	// entry must not be reported in the output
+	want = 0
+	for j, elen := range entrylengths {
+		want += elen
+		in, out = evalsplat(&bc, delims[:n], outdelims[:want-1], outperm[:want-1])
+		if in != j {
+			t.Errorf("with %d outdelims available expected %d in; got %d", want-1, j, in)
+		}
+		if out != want-elen {
+			t.Errorf("with %d outdelims available expected %d out; got %d", want-1, want-elen, out)
+		}
+	}
+
 	// test that delimiter object sizes
 	// are sane
 	for i := range outdelims[:out] {
@@ -148,6 +148,7 @@ func (u *kernelUnpivotAsAt) flush() error {
 	if err := u.out.writeRows(u.dummy, &u.params); err != nil {
 		return err
 	}
+	u.params.pos += uint64(len(u.dummy))
 	u.dummy = u.dummy[:0]
 	u.params.auxbound[0] = u.params.auxbound[0][:0]
 	u.params.auxbound[1] = u.params.auxbound[1][:0]
@@ -155,6 +156,7 @@ func (u *kernelUnpivotAsAt) flush() error {
 }
 
 func (u *kernelUnpivotAsAt) writeZion(state *zionState) error {
+	u.params.pos = derivepos(state.pos)
 	err := state.buckets.SelectAll()
 	if err != nil {
 		return err
@@ -204,6 +206,7 @@ func (u *kernelUnpivotAsAt) symbolize(st *symtab, aux *auxbindings) error {
 }
 
 func (u *kernelUnpivotAsAt) writeRows(rows []vmref, params *rowParams) error {
+	u.params.pos = derivepos(params.pos)
 	// Process the auxilliary bindings first, if provided
 	for i, v := range params.auxbound {
 		symref := u.resolver.auxrefs[i]
@@ -214,6 +217,7 @@ func (u *kernelUnpivotAsAt) writeRows(rows []vmref, params *rowParams) error {
 				if err := u.out.writeRows(u.dummy, &u.params); err != nil {
 					return err
 				}
+				u.params.pos += uint64(len(u.dummy))
 				u.dummy = u.dummy[:0]
 				u.params.auxbound[0] = u.params.auxbound[0][:0]
 				u.params.auxbound[1] = u.params.auxbound[1][:0]
@@ -281,6 +285,7 @@ func (u *kernelUnpivotAs) symbolize(st *symtab, aux *auxbindings) error {
 }
 
 func (u *kernelUnpivotAs) writeRows(rows []vmref, params *rowParams) error {
+	u.params.pos = derivepos(params.pos)
 	// Process the auxilliary bindings first, if provided
 	for _, v := range params.auxbound {
 		for len(v) > 0 {
@@ -290,6 +295,7 @@ func (u *kernelUnpivotAs) writeRows(rows []vmref, params *rowParams) error {
 				if err := u.out.writeRows(u.dummy, &u.params); err != nil {
 					return err
 				}
+				u.params.pos += uint64(len(u.dummy))
 				u.dummy = u.dummy[:0]
 				u.params.auxbound[0] = u.params.auxbound[0][:0]
 				k = cap(u.dummy)
@@ -325,6 +331,7 @@ func (u *kernelUnpivotAs) writeRows(rows []vmref, params *rowParams) error {
 				if err := u.out.writeRows(u.dummy, &u.params); err != nil {
 					return err
 				}
+				u.params.pos += uint64(len(u.dummy))
 				u.dummy = u.dummy[:0]
 				u.params.auxbound[0] = u.params.auxbound[0][:0]
 			}
@@ -335,6 +342,7 @@ func (u *kernelUnpivotAs) writeRows(rows []vmref, params *rowParams) error {
 		if err := u.out.writeRows(u.dummy, &u.params); err != nil {
 			return err
 		}
+		u.params.pos += uint64(len(u.dummy))
 		u.dummy = u.dummy[:0]
 		u.params.auxbound[0] = u.params.auxbound[0][:0]
 	}
@@ -359,6 +367,7 @@ func (u *kernelUnpivotAt) symbolize(st *symtab, aux *auxbindings) error {
 }
 
 func (u *kernelUnpivotAt) writeRows(rows []vmref, params *rowParams) error {
+	u.params.pos = derivepos(params.pos)
 	// Process the auxilliary bindings first, if provided
 	for i, v := range params.auxbound {
 		symref := u.resolver.auxrefs[i]
@@ -369,6 +378,7 @@ func (u *kernelUnpivotAt) writeRows(rows []vmref, params *rowParams) error {
 				if err := u.out.writeRows(u.dummy, &u.params); err != nil {
 					return err
 				}
+				u.params.pos += uint64(len(u.dummy))
 				u.dummy = u.dummy[:0]
 				u.params.auxbound[0] = u.params.auxbound[0][:0]
 				k = cap(u.dummy)
@@ -404,6 +414,7 @@ func (u *kernelUnpivotAt) writeRows(rows []vmref, params *rowParams) error {
 				if err := u.out.writeRows(u.dummy, &u.params); err != nil {
 					return err
 				}
+				u.params.pos += uint64(len(u.dummy))
 				u.dummy = u.dummy[:0]
 				u.params.auxbound[0] = u.params.auxbound[0][:0]
 			}
@@ -414,6 +425,7 @@ func (u *kernelUnpivotAt) writeRows(rows []vmref, params *rowParams) error {
 		if err := u.out.writeRows(u.dummy, &u.params); err != nil {
 			return err
 		}
+		u.params.pos += uint64(len(u.dummy))
 		u.dummy = u.dummy[:0]
 		u.params.auxbound[0] = u.params.auxbound[0][:0]
 	}
@@ -568,6 +580,7 @@ func (u *kernelUnpivotAtDistinct) next() rowConsumer {
 }
 
 func (u *kernelUnpivotAtDistinct) writeRows(rows []vmref, params *rowParams) error {
+	u.params.pos = derivepos(params.pos)
 	// Mark the auxilliary binding fields first. Duplication with regards
 	// to the content of rows is fine, as the next step is deduplication.
 	for i, v := range params.auxbound {
@@ -600,6 +613,7 @@ func (u *kernelUnpivotAtDistinct) writeRows(rows []vmref, params *rowParams) err
 					if err := u.out.writeRows(u.dummy, &u.params); err != nil {
 						return err
 					}
+					u.params.pos += uint64(len(u.dummy))
 					u.dummy = u.dummy[:0]
 					u.params.auxbound[0] = u.params.auxbound[0][:0]
 				}
@@ -613,6 +627,7 @@ func (u *kernelUnpivotAtDistinct) writeRows(rows []vmref, params *rowParams) err
 		if err := u.out.writeRows(u.dummy, &u.params); err != nil {
 			return err
 		}
+		u.params.pos += uint64(len(u.dummy))
 		u.dummy = u.dummy[:0]
 		u.params.auxbound[0] = u.params.auxbound[0][:0]
 	}
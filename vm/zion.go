@@ -30,6 +30,7 @@ type zionState struct {
 	shape     zll.Shape
 	buckets   zll.Buckets
 	blocksize int64
+	pos       uint64 // position of the first row (see chunkpos)
 }
 
 type zionConsumer interface {
@@ -121,6 +122,7 @@ func (z *zionFlattener) writeZion(state *zionState) error {
 			return err
 		}
 		z.params.auxbound = z.params.auxbound[:0]
+		z.params.pos = state.pos
 		z.empty = empty(z.empty, n)
 		return z.writeRows(z.empty, &z.params)
 	}
@@ -138,6 +140,7 @@ func (z *zionFlattener) writeZion(state *zionState) error {
 	posn := state.buckets.Pos
 	// set slice sizes for all the fields
 	z.params.auxbound = shrink(z.params.auxbound, len(z.tape))
+	z.params.pos = state.pos
 	pos := state.shape.Start
 	for pos < len(state.shape.Bits) {
 
@@ -164,6 +167,7 @@ func (z *zionFlattener) writeZion(state *zionState) error {
 		if err != nil {
 			break
 		}
+		z.params.pos += uint64(out)
 	}
 	state.buckets.Pos = posn // restore bucket positions
 	return err
@@ -16,9 +16,11 @@ package vm
 
 import (
 	"bytes"
+	"fmt"
 	"slices"
 	"testing"
 
+	"github.com/SnellerInc/sneller/expr"
 	"github.com/SnellerInc/sneller/ion"
 	"github.com/SnellerInc/sneller/ion/zion"
 	"github.com/SnellerInc/sneller/ion/zion/zll"
@@ -127,3 +129,106 @@ func TestZionFlatten(t *testing.T) {
 	cmp(flat[1].mem(), []byte{0x83, 'b', 'a', 'r'})
 	cmp(flat[2].mem(), []byte{})
 }
+
+// TestZionProjectBuckets tests that a query over
+// zion data decompresses only the buckets holding
+// the fields referenced by the query
+func TestZionProjectBuckets(t *testing.T) {
+	const columns = 10
+	var st ion.Symtab
+	var buf ion.Buffer
+	for i := 0; i < 10; i++ {
+		fields := make([]ion.Field, columns)
+		for j := range fields {
+			fields[j] = ion.Field{
+				Label: fmt.Sprintf("a%d", j),
+				Datum: ion.Int(int64(i*columns + j)),
+			}
+		}
+		ion.NewStruct(nil, fields).Encode(&buf, &st)
+	}
+	pos := buf.Size()
+	st.Marshal(&buf, true)
+	body := append(buf.Bytes()[pos:], buf.Bytes()[:pos]...)
+
+	var enc zion.Encoder
+	encoded, err := enc.Encode(body, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// determine which buckets ought to be touched
+	var shape zll.Shape
+	shape.Symtab = &ion.Symtab{}
+	_, err = shape.Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	touched := make(map[int]struct{})
+	all := make(map[int]struct{})
+	for j := 0; j < columns; j++ {
+		sym, ok := st.Symbolize(fmt.Sprintf("a%d", j))
+		if !ok {
+			t.Fatal("missing symbol")
+		}
+		bucket := shape.SymbolBucket(sym)
+		all[bucket] = struct{}{}
+		if j == 3 || j == 7 {
+			touched[bucket] = struct{}{}
+		}
+	}
+	if len(all) <= len(touched) {
+		t.Fatal("test needs more distinct buckets")
+	}
+
+	// SELECT a7 FROM input WHERE a3 < 5
+	var qb QueryBuffer
+	proj, err := NewProjection(Selection{{Expr: path(t, "a7")}}, &qb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filt, err := NewFilter(expr.Compare(expr.Less, path(t, "a3"), expr.Integer(5)), proj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := filt.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, ok := w.(*rowSplitter)
+	if !ok {
+		t.Fatalf("unexpected writer %T", w)
+	}
+	if !rs.ConfigureZion(int64(len(encoded)), []string{"a3", "a7"}) {
+		t.Fatal("ConfigureZion failed")
+	}
+	_, err = w.Write(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decomps := rs.zstate.buckets.Decomps
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decomps != len(touched) {
+		t.Errorf("%d buckets decompressed; wanted %d of %d", decomps, len(touched), len(all))
+	}
+
+	var out []string
+	rest := qb.Bytes()
+	var outst ion.Symtab
+	for len(rest) > 0 {
+		var d ion.Datum
+		d, rest, err = ion.ReadDatum(&outst, rest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.IsNull() {
+			continue // padding
+		}
+		out = append(out, d.JSON())
+	}
+	if len(out) != 1 || out[0] != `{"a7": 7}` {
+		t.Errorf("unexpected output %q", out)
+	}
+}
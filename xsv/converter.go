@@ -13,10 +13,12 @@
 //  limitations under the License.
 
 // Package xsv implements parsing/converting CSV (RFC 4180) and
-// TSV (tab separated values) files to binary ION format.
+// TSV (tab separated values) files to binary ION format,
+// and writing binary ION data as CSV.
 package xsv
 
 import (
+	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
@@ -47,9 +49,47 @@ type RowChopper interface {
 // to determine the individual fields and
 // writes it to the ION chunker
 func Convert(r io.Reader, dst *ion.Chunker, ch RowChopper, hint *Hint, cons []ion.Field) error {
+	_, err := ConvertWithReject(r, dst, ch, hint, cons, nil)
+	return err
+}
+
+// A RejectFunc is called by ConvertWithReject
+// with the index and the conversion error of each
+// record that cannot be converted. If it returns nil,
+// the record is discarded and conversion continues
+// with the next record. Otherwise, conversion stops
+// and the returned error is passed on to the caller.
+type RejectFunc func(rec int, err error) error
+
+// ConvertWithReject is like Convert, but it calls
+// reject (if it is non-nil) for each record that
+// cannot be parsed (see csv.ParseError) or that
+// does not fit in dst instead of failing immediately.
+// It returns the number of records written to dst.
+func ConvertWithReject(r io.Reader, dst *ion.Chunker, ch RowChopper, hint *Hint, cons []ion.Field, reject RejectFunc) (int, error) {
 	// cannot convert without hints
-	if hint == nil || len(hint.Fields) == 0 {
-		return ErrNoHints
+	if hint == nil || (len(hint.Fields) == 0 && !hint.Header) {
+		return 0, ErrNoHints
+	}
+
+	// resolve the header and inferred types;
+	// the records that were read in order to
+	// infer types are converted first
+	var pending []pendingRecord
+	if hint.Header || hint.needsInference() {
+		var err error
+		hint, pending, err = hint.resolve(r, ch)
+		if err != nil {
+			return 0, err
+		}
+	}
+	next := func() ([]string, error) {
+		if len(pending) > 0 {
+			rec := pending[0]
+			pending = pending[1:]
+			return rec.fields, rec.err
+		}
+		return ch.GetNext(r)
 	}
 
 	// make sure constant field IDs are interned
@@ -57,7 +97,7 @@ func Convert(r io.Reader, dst *ion.Chunker, ch RowChopper, hint *Hint, cons []io
 	for i := range cons {
 		cons[i].Sym = dst.Symbols.Intern(cons[i].Label)
 		if cons[i].Sym < prev {
-			return fmt.Errorf("xsv: internal error: constant interned symbols out-of-order")
+			return 0, fmt.Errorf("xsv: internal error: constant interned symbols out-of-order")
 		}
 		prev = cons[i].Sym
 	}
@@ -79,36 +119,40 @@ func Convert(r io.Reader, dst *ion.Chunker, ch RowChopper, hint *Hint, cons []io
 
 	fm := newFieldMapFromHint(hint, symbufs)
 
-	eof := false
-	recordNr := 0
+	records, rejected := 0, 0
+	// skip is called when the current
+	// record cannot be converted
+	skip := func(err error) error {
+		if reject == nil {
+			return err
+		}
+		if err := reject(records+rejected, err); err != nil {
+			return err
+		}
+		rejected++
+		dst.Abort()
+		return nil
+	}
 	for {
-		fields, err := ch.GetNext(r)
+		fields, err := next()
 		if err != nil {
-			if !errors.Is(err, io.EOF) {
-				return err
+			if errors.Is(err, io.EOF) {
+				return records, nil
 			}
-			eof = true
-		}
-
-		// terminate the previous record
-		if recordNr > 0 {
-			if err := fm.writeMap(dst); err != nil {
-				return err
+			var pe *csv.ParseError
+			if !errors.As(err, &pe) {
+				return records, err
 			}
-			dst.EndStruct()
-			if err := dst.Commit(); err != nil {
-				return err
+			if err := skip(err); err != nil {
+				return records, err
 			}
-		}
-		if eof {
-			return nil
+			continue
 		}
 
 		dst.BeginStruct(-1)
 		for i := range cons {
 			cons[i].Encode(&dst.Buffer, &dst.Symbols)
 		}
-		recordNr++
 
 		for fieldNr := range fields {
 			if fieldNr >= len(hint.Fields) {
@@ -141,6 +185,19 @@ func Convert(r io.Reader, dst *ion.Chunker, ch RowChopper, hint *Hint, cons []io
 				fm.addToMap(&field, text)
 			}
 		}
+
+		err = fm.writeMap(dst)
+		if err == nil {
+			dst.EndStruct()
+			err = dst.Commit()
+		}
+		if err != nil {
+			if err := skip(err); err != nil {
+				return records, err
+			}
+			continue
+		}
+		records++
 	}
 }
 
@@ -154,7 +211,7 @@ func newFieldMapFromHint(hint *Hint, symbufs []ion.Symbuf) *subfieldNode {
 	fm := &subfieldNode{fields: make(map[ion.Symbol]any)}
 	for i := range hint.Fields {
 		f := hint.Fields[i]
-		if !f.isRootField() {
+		if f.Type != TypeIgnore && !f.isRootField() {
 			m := fm
 			lf := subfieldLeaf{
 				field:  &f,
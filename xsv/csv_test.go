@@ -16,6 +16,8 @@ package xsv
 
 import (
 	"bytes"
+	"encoding/csv"
+	"errors"
 	"io"
 	"io/fs"
 	"os"
@@ -96,3 +98,135 @@ func TestConvertCSV(t *testing.T) {
 		})
 	}
 }
+
+func TestInferCSV(t *testing.T) {
+	f, err := os.Open(testFolder + "/test3.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	h := &Hint{Header: true}
+	ch := CsvChopper{}
+	got, pending, err := h.resolve(f, &ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 4 {
+		t.Errorf("got %d pending records, want 4", len(pending))
+	}
+	want := []struct{ name, typ string }{
+		{"id", TypeInt},
+		{"name", TypeString},
+		{"score", TypeNumber},
+		{"active", TypeBool},
+		{"joined", TypeDateTime},
+		{"note", TypeString},
+		{"", TypeIgnore},
+	}
+	if len(got.Fields) != len(want) {
+		t.Fatalf("got %d fields, want %d", len(got.Fields), len(want))
+	}
+	for i := range want {
+		if got.Fields[i].Name != want[i].name || got.Fields[i].Type != want[i].typ {
+			t.Errorf("field %d: got %q (%s), want %q (%s)", i,
+				got.Fields[i].Name, got.Fields[i].Type, want[i].name, want[i].typ)
+		}
+	}
+	if len(h.Fields) != 0 {
+		t.Error("resolve modified the original hint")
+	}
+}
+
+func TestConvertWithReject(t *testing.T) {
+	input := "id,note\n1,a\n2," + strings.Repeat("x", 2000) + "\n3,c\n"
+	h := &Hint{Header: true}
+	var out bytes.Buffer
+	dst := ion.Chunker{Align: 1024, W: ion.NewJSONWriter(&out, '\n')}
+	var rejected []int
+	n, err := ConvertWithReject(strings.NewReader(input), &dst, &CsvChopper{}, h, nil, func(rec int, err error) error {
+		rejected = append(rejected, rec)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("got %d records, want 2", n)
+	}
+	if len(rejected) != 1 || rejected[0] != 1 {
+		t.Errorf("rejected records %v, want [1]", rejected)
+	}
+	want := "{\"id\": 1, \"note\": \"a\"}\n{\"id\": 3, \"note\": \"c\"}\n"
+	if out.String() != want {
+		t.Errorf("got output %q, want %q", out.String(), want)
+	}
+
+	// without a reject function, the record is fatal
+	dst = ion.Chunker{Align: 1024, W: io.Discard}
+	err = Convert(strings.NewReader(input), &dst, &CsvChopper{}, &Hint{Header: true}, nil)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+// errChopper wraps a RowChopper and returns
+// a csv.ParseError in place of record bad
+type errChopper struct {
+	RowChopper
+	rec, bad int
+}
+
+func (e *errChopper) GetNext(r io.Reader) ([]string, error) {
+	fields, err := e.RowChopper.GetNext(r)
+	if err != nil {
+		return fields, err
+	}
+	e.rec++
+	if e.rec == e.bad {
+		return nil, &csv.ParseError{Line: e.rec, Err: csv.ErrQuote}
+	}
+	return fields, nil
+}
+
+func TestConvertWithRejectInfer(t *testing.T) {
+	// the second record (after the header) is rejected
+	// while the column types are being inferred
+	input := "id,note\n1,a\nbad,b\n3,c\n"
+	var out bytes.Buffer
+	dst := ion.Chunker{Align: 1024, W: ion.NewJSONWriter(&out, '\n')}
+	var rejected []int
+	ch := &errChopper{RowChopper: &CsvChopper{}, bad: 3}
+	n, err := ConvertWithReject(strings.NewReader(input), &dst, ch, &Hint{Header: true}, nil, func(rec int, err error) error {
+		rejected = append(rejected, rec)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("got %d records, want 2", n)
+	}
+	if len(rejected) != 1 || rejected[0] != 1 {
+		t.Errorf("rejected records %v, want [1]", rejected)
+	}
+	// the rejected record doesn't make id a string
+	want := "{\"id\": 1, \"note\": \"a\"}\n{\"id\": 3, \"note\": \"c\"}\n"
+	if out.String() != want {
+		t.Errorf("got output %q, want %q", out.String(), want)
+	}
+
+	// without a reject function, the record is fatal
+	dst = ion.Chunker{Align: 1024, W: io.Discard}
+	ch = &errChopper{RowChopper: &CsvChopper{}, bad: 3}
+	err = Convert(strings.NewReader(input), &dst, ch, &Hint{Header: true}, nil)
+	var pe *csv.ParseError
+	if !errors.As(err, &pe) {
+		t.Errorf("expected a csv.ParseError, got %v", err)
+	}
+}
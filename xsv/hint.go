@@ -31,6 +31,7 @@ const (
 	TypeInt      = "int"    // integer only
 	TypeBool     = "bool"
 	TypeDateTime = "datetime"
+	TypeAuto     = "auto" // inferred from the data
 )
 
 const (
@@ -59,6 +60,12 @@ type Hint struct {
 	// Separator allows specifying a custom
 	// separator (only applicable for CSV)
 	Separator Delim `json:"separator,omitempty"`
+	// Header indicates that the first record
+	// (after SkipRecords) contains the field names.
+	// Columns that are not described by Fields
+	// are named after their header and their
+	// type is inferred from the data.
+	Header bool `json:"header,omitempty"`
 	// MissingValues is an optional list of
 	// strings which represent missing values.
 	// Entries in Fields may override this on a
@@ -132,7 +139,11 @@ func (fh *FieldHint) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, (*_fieldHint)(fh)); err != nil {
 		return err
 	}
+	return fh.init()
+}
 
+// init validates fh and prepares its internals
+func (fh *FieldHint) init() error {
 	// set type to "ignore" if no name is set
 	if fh.Name == "" || fh.Type == TypeIgnore {
 		fh.Name = ""
@@ -188,6 +199,9 @@ func (fh *FieldHint) UnmarshalJSON(data []byte) error {
 		} else {
 			fh.convertAndWrite = boolToION
 		}
+	case TypeAuto:
+		// determined by inferTypes
+		fh.convertAndWrite = stringToION
 	case TypeDateTime:
 		f := FormatDateTime
 		if fh.Format != "" {
@@ -260,6 +274,11 @@ func (fh *FieldHint) isRootField() bool {
 //   - int
 //   - bool -> can support custom true/false values
 //   - datetime -> formats: text (default), epoch, epoch_ms, epoch_us, epoch_ns
+//   - auto -> one of the types above, inferred from the first records
+//
+// If 'header' is set, then the first record names the columns that
+// are not listed in 'fields', and their type is inferred. With a header,
+// 'fields' may be omitted entirely.
 func ParseHint(hint []byte) (*Hint, error) {
 	var h Hint
 	err := json.Unmarshal(hint, &h)
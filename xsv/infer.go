@@ -0,0 +1,173 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package xsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/SnellerInc/sneller/date"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+// inferRecords is the number of records
+// that are used to infer the type of
+// a column with TypeAuto
+const inferRecords = 100
+
+// inferCandidates are the types that can be
+// inferred, in order of preference; a column
+// is given the first type that accepts all of
+// its values and TypeString otherwise
+var inferCandidates = []struct {
+	typ   string
+	valid func(string) bool
+	conv  func(string, *ion.Chunker, bool, ion.Symbuf)
+}{
+	{TypeInt, func(s string) bool {
+		_, err := strconv.ParseInt(s, 10, 64)
+		return err == nil
+	}, intToION},
+	{TypeNumber, func(s string) bool {
+		_, err := strconv.ParseFloat(s, 64)
+		return err == nil
+	}, floatToION},
+	{TypeBool, func(s string) bool {
+		_, err := strconv.ParseBool(s)
+		return err == nil
+	}, boolToION},
+	{TypeDateTime, func(s string) bool {
+		_, ok := date.Parse([]byte(s))
+		return ok
+	}, dateToION},
+}
+
+func (h *Hint) needsInference() bool {
+	for i := range h.Fields {
+		if h.Fields[i].Type == TypeAuto {
+			return true
+		}
+	}
+	return false
+}
+
+// pendingRecord is a record that was read
+// by Hint.resolve, or the csv.ParseError that
+// was returned in its place
+type pendingRecord struct {
+	fields []string
+	err    error
+}
+
+// resolve returns a copy of h where the fields
+// named by the header have been added and where
+// every TypeAuto field has been given a type,
+// along with the records that had to be read
+// in order to do so; records that cannot be
+// parsed are returned as errors so that they
+// can be rejected like any other record
+func (h *Hint) resolve(r io.Reader, ch RowChopper) (*Hint, []pendingRecord, error) {
+	out := *h
+	out.Fields = slices.Clone(h.Fields)
+	if h.Header {
+		names, err := ch.GetNext(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return &out, nil, nil
+			}
+			return nil, nil, err
+		}
+		for i := len(out.Fields); i < len(names); i++ {
+			f := FieldHint{Name: names[i], Type: TypeAuto}
+			if f.Name == "" {
+				f.Type = TypeIgnore
+			}
+			if err := f.init(); err != nil {
+				return nil, nil, err
+			}
+			out.Fields = append(out.Fields, f)
+		}
+	}
+	if !out.needsInference() {
+		return &out, nil, nil
+	}
+	var pending []pendingRecord
+	var recs [][]string
+	for len(pending) < inferRecords {
+		rec, err := ch.GetNext(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			var pe *csv.ParseError
+			if !errors.As(err, &pe) {
+				return nil, nil, err
+			}
+			pending = append(pending, pendingRecord{err: err})
+			continue
+		}
+		// choppers may reuse the record
+		rec = slices.Clone(rec)
+		pending = append(pending, pendingRecord{fields: rec})
+		recs = append(recs, rec)
+	}
+	for i := range out.Fields {
+		f := &out.Fields[i]
+		if f.Type != TypeAuto {
+			continue
+		}
+		missing := out.MissingValues
+		if f.MissingValues != nil {
+			missing = f.MissingValues
+		}
+		f.infer(recs, i, missing)
+	}
+	return &out, pending, nil
+}
+
+// infer determines the type of column col
+// of the records in recs; values that are
+// empty or missing are not taken into account
+func (fh *FieldHint) infer(recs [][]string, col int, missing []string) {
+	valid := make([]bool, len(inferCandidates))
+	for i := range valid {
+		valid[i] = true
+	}
+	seen := false
+	for _, rec := range recs {
+		if col >= len(rec) || rec[col] == "" || slices.Contains(missing, rec[col]) {
+			continue
+		}
+		seen = true
+		for i := range inferCandidates {
+			valid[i] = valid[i] && inferCandidates[i].valid(rec[col])
+		}
+	}
+	fh.Type = TypeString
+	fh.convertAndWrite = stringToION
+	if !seen {
+		return
+	}
+	for i := range inferCandidates {
+		if valid[i] {
+			fh.Type = inferCandidates[i].typ
+			fh.convertAndWrite = inferCandidates[i].conv
+			return
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package xsv
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/SnellerInc/sneller/ion"
+)
+
+var errNotStruct = errors.New("xsv: row is not a structure")
+
+// Writer is an io.Writer that renders chunks of
+// ion data (such as the output of a query) as CSV,
+// one record per structure, quoting fields
+// as described in RFC 4180. Each call to Write
+// must begin at a chunk boundary.
+//
+// The first record is a header with the
+// name of each column. Fields that are missing
+// from a row (or are null) are written as empty
+// cells, and fields that are not columns are ignored.
+// Timestamps are written in RFC 3339 format,
+// blobs are base64-encoded, and lists and
+// structures are written as JSON.
+//
+// A Writer can be used as a vm.QuerySink
+// by wrapping it with vm.LockedSink.
+type Writer struct {
+	// Columns, if non-nil, are the fields that
+	// are written for each row, in order.
+	// Otherwise, the columns are the fields
+	// of the first row.
+	Columns []string
+
+	w       *csv.Writer
+	st      ion.Symtab
+	index   map[string]int
+	record  []string
+	started bool
+}
+
+// NewWriter constructs a Writer that writes CSV to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: csv.NewWriter(w)}
+}
+
+// start writes the header
+func (w *Writer) start(first ion.Struct) error {
+	if w.Columns == nil {
+		first.Each(func(f ion.Field) error {
+			w.Columns = append(w.Columns, f.Label)
+			return nil
+		})
+	}
+	w.index = make(map[string]int, len(w.Columns))
+	for i := len(w.Columns) - 1; i >= 0; i-- {
+		w.index[w.Columns[i]] = i
+	}
+	w.record = make([]string, len(w.Columns))
+	w.started = true
+	return w.w.Write(w.Columns)
+}
+
+// Write implements io.Writer.Write
+func (w *Writer) Write(p []byte) (int, error) {
+	body := p
+	for len(body) > 0 {
+		if ion.TypeOf(body) == ion.NullType && ion.SizeOf(body) > 1 {
+			// nop pad
+			body = body[ion.SizeOf(body):]
+			continue
+		}
+		var d ion.Datum
+		var err error
+		d, body, err = ion.ReadDatum(&w.st, body)
+		if err != nil {
+			return len(p) - len(body), err
+		}
+		if d.IsEmpty() || d.IsNull() {
+			continue // symbol table or nop pad
+		}
+		s, err := d.Struct()
+		if err != nil {
+			return len(p) - len(body), errNotStruct
+		}
+		if err := w.row(s); err != nil {
+			return len(p) - len(body), err
+		}
+	}
+	w.w.Flush()
+	return len(p), w.w.Error()
+}
+
+func (w *Writer) row(s ion.Struct) error {
+	if !w.started {
+		if err := w.start(s); err != nil {
+			return err
+		}
+	}
+	for i := range w.record {
+		w.record[i] = ""
+	}
+	err := s.Each(func(f ion.Field) error {
+		i, ok := w.index[f.Label]
+		if !ok {
+			return nil
+		}
+		var err error
+		w.record[i], err = cell(f.Datum)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return w.w.Write(w.record)
+}
+
+// cell returns the text of a CSV field for d
+func cell(d ion.Datum) (string, error) {
+	t := d.Type()
+	if t < ion.AnnotationType && d.Raw()[0]&0x0f == 0x0f {
+		// untyped or typed null
+		return "", nil
+	}
+	switch t {
+	case ion.StringType, ion.SymbolType:
+		return d.String()
+	case ion.BoolType:
+		b, err := d.Bool()
+		return strconv.FormatBool(b), err
+	case ion.IntType:
+		i, err := d.Int()
+		return strconv.FormatInt(i, 10), err
+	case ion.UintType:
+		u, err := d.Uint()
+		return strconv.FormatUint(u, 10), err
+	case ion.FloatType:
+		f, err := d.Float()
+		return strconv.FormatFloat(f, 'g', -1, 64), err
+	case ion.TimestampType:
+		t, err := d.Timestamp()
+		return string(t.AppendRFC3339Nano(nil)), err
+	case ion.BlobType:
+		b, err := d.BlobShared()
+		return base64.StdEncoding.EncodeToString(b), err
+	default:
+		return d.JSON(), nil
+	}
+}
+
+// Close writes the header if no rows
+// have been written and Columns is set,
+// and flushes any buffered output.
+// It does not close the underlying io.Writer.
+func (w *Writer) Close() error {
+	if !w.started && w.Columns != nil {
+		w.started = true
+		w.w.Write(w.Columns)
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 Sneller, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package xsv
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/SnellerInc/sneller/date"
+	"github.com/SnellerInc/sneller/ion"
+)
+
+func chunk(st *ion.Symtab, rows ...[]ion.Field) []byte {
+	var body, buf ion.Buffer
+	for i := range rows {
+		ion.NewStruct(st, rows[i]).Encode(&body, st)
+	}
+	st.Marshal(&buf, true)
+	buf.UnsafeAppend(body.Bytes())
+	return buf.Bytes()
+}
+
+func TestWriter(t *testing.T) {
+	var st ion.Symtab
+	t0 := date.Date(2023, 1, 2, 3, 4, 5, 6000)
+	src := chunk(&st,
+		[]ion.Field{
+			{Label: "name", Datum: ion.String("a, \"b\"")},
+			{Label: "n", Datum: ion.Int(-1)},
+			{Label: "when", Datum: ion.Timestamp(t0)},
+			{Label: "tags", Datum: ion.NewList(&st, []ion.Datum{
+				ion.String("x"), ion.Float(1.5),
+			}).Datum()},
+			{Label: "inner", Datum: ion.NewStruct(&st, []ion.Field{
+				{Label: "ok", Datum: ion.Bool(true)},
+			}).Datum()},
+		},
+		// sparse: no "n" or "tags", a null "when" and an extra field
+		[]ion.Field{
+			{Label: "extra", Datum: ion.Int(3)},
+			{Label: "name", Datum: ion.String("line\nbreak")},
+			{Label: "when", Datum: ion.Null},
+			{Label: "inner", Datum: ion.Uint(5)},
+		},
+	)
+	second := chunk(&st, []ion.Field{
+		{Label: "n", Datum: ion.Float(0.25)},
+	})
+
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	for _, buf := range [][]byte{src, second} {
+		n, err := w.Write(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(buf) {
+			t.Fatalf("wrote %d of %d bytes", n, len(buf))
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := `name,n,when,tags,inner
+"a, ""b""",-1,2023-01-02T03:04:05.000006Z,"[""x"", 1.5]","{""ok"": true}"
+"line
+break",,,,5
+,0.25,,,
+`
+	if got := out.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// explicit columns, including one that never appears
+	out.Reset()
+	w = NewWriter(&out)
+	w.Columns = []string{"inner", "missing", "name"}
+	if _, err := w.Write(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want = `inner,missing,name
+"{""ok"": true}",,"a, ""b"""
+5,,"line
+break"
+`
+	if got := out.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// typed nulls are empty cells, too
+	var body, buf ion.Buffer
+	a, b := st.Intern("a"), st.Intern("b")
+	body.BeginStruct(-1)
+	body.BeginField(a)
+	body.UnsafeAppend([]byte{0x2f}) // null.int
+	body.BeginField(b)
+	body.WriteInt(1)
+	body.EndStruct()
+	body.BeginStruct(-1)
+	body.BeginField(a)
+	body.UnsafeAppend([]byte{0x1f}) // null.bool
+	body.BeginField(b)
+	body.UnsafeAppend([]byte{0xdf}) // null.struct
+	body.EndStruct()
+	body.BeginStruct(-1)
+	body.BeginField(a)
+	body.UnsafeAppend([]byte{0x4f}) // null.float
+	body.BeginField(b)
+	body.UnsafeAppend([]byte{0x6f}) // null.timestamp
+	body.EndStruct()
+	st.Marshal(&buf, true)
+	buf.UnsafeAppend(body.Bytes())
+	out.Reset()
+	w = NewWriter(&out)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "a,b\n,1\n,\n,\n" {
+		t.Errorf("got %q", got)
+	}
+
+	// no rows with explicit columns still yields a header
+	out.Reset()
+	w = NewWriter(&out)
+	w.Columns = []string{"a", "b"}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "a,b\n" {
+		t.Errorf("got %q", got)
+	}
+}